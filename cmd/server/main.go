@@ -1,45 +1,121 @@
 package main
 
 import (
-	"github.com/go-playground/validator/v10"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/database"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/jobs"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
 	"github.com/yourname/payslip-system/internal/routes"
 	"github.com/yourname/payslip-system/internal/seed"
 )
 
-// CustomValidator is a custom validator for Echo
-type CustomValidator struct {
-	validator *validator.Validate
-}
-
-// Validate validates structs
-func (cv *CustomValidator) Validate(i interface{}) error {
-	return cv.validator.Struct(i)
-}
-
 func main() {
+	seedOnly := flag.Bool("seed", false, "seed an empty database and exit")
+	flag.Parse()
+
 	config.LoadEnv()
 
+	// Structured logs, the standard log package included, JSON unless LOG_FORMAT=text
+	logger := config.LoadLoggingConfig().NewLogger(os.Stdout)
+	slog.SetDefault(logger)
+
 	db := database.Connect()
-	seed.Run(db)
 	db.Debug()
-	db.AutoMigrate(&model.Employee{}, &model.Attendance{}, &model.Overtime{}, &model.Reimbursement{}, &model.Payslip{})
+	if err := database.DropAttendanceStatusCheck(db); err != nil {
+		log.Fatalf("Failed to migrate attendance statuses: %v", err)
+	}
+	if err := database.DropEmployeeRoleCheck(db); err != nil {
+		log.Fatalf("Failed to migrate employee roles: %v", err)
+	}
+	db.AutoMigrate(
+		&model.PayGrade{},
+		&model.Employee{},
+		&model.Attendance{},
+		&model.Overtime{},
+		&model.Reimbursement{},
+		&model.Payslip{},
+		&model.EmployeeComponent{},
+		&model.RoleTemplate{},
+		&model.RoleTemplateComponent{},
+		&model.Timesheet{},
+		&model.PayrollRun{},
+		&model.PayrollRunResult{},
+		&model.TaxBracket{},
+		&model.Holiday{},
+		&model.RefreshToken{},
+		&model.APIKey{},
+		&model.PasswordResetToken{},
+		&model.AuditLog{},
+		&model.IdempotencyRecord{},
+	)
+	if err := database.BackfillCurrency(db, config.LoadPayrollConfig().Currency); err != nil {
+		log.Fatalf("Failed to migrate currencies: %v", err)
+	}
+	if payrollConfig := config.LoadPayrollConfig(); payrollConfig.MoneyMinorUnits {
+		err := database.BackfillMinorUnits(db, func(currency string) uint {
+			return helper.MoneyDecimals(currency, payrollConfig.MoneyDecimals)
+		})
+		if err != nil {
+			log.Fatalf("Failed to migrate amounts to minor units: %v", err)
+		}
+	}
 
 	defer database.Close(db)
 
+	// Seed after migrating so a fresh database has its tables, -seed seeds and exits without serving
+	if seedConfig := config.LoadSeedConfig(); *seedOnly || seedConfig.Enabled {
+		if err := seed.Run(db, seedConfig); err != nil {
+			log.Fatalf("Failed to seed the database: %v", err)
+		}
+		if *seedOnly {
+			return
+		}
+	}
+
+	// Hard-delete employees past the soft delete retention window
+	jobs.StartEmployeePurge(repository.NewEmployeeRepository(db), config.LoadEmployeeRetentionConfig())
+
+	// Cross-origin requests are only allowed from the configured origins
+	corsConfig := config.LoadCORSConfig()
+	if err := corsConfig.Validate(); err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+
 	e := echo.New()
 
+	// The client IP the rate limits and request logs use is the connection's address, or behind trusted
+	// proxies the last untrusted address of X-Forwarded-For. Headers sent by clients themselves are ignored.
+	e.IPExtractor = echo.ExtractIPDirect()
+	if proxies := config.LoadTrustedProxies(); len(proxies) > 0 {
+		trust := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+		for _, proxy := range proxies {
+			trust = append(trust, echo.TrustIPRange(proxy))
+		}
+		e.IPExtractor = echo.ExtractIPFromXFFHeader(trust...)
+	}
+
 	// Add middleware
-	e.Use(middleware.Logger())
+	e.Use(mymiddleware.RequestID())
+	e.Use(mymiddleware.RequestLogger(logger))
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: corsConfig.AllowOrigins,
+		AllowMethods: corsConfig.AllowMethods,
+		AllowHeaders: corsConfig.AllowHeaders,
+	}))
 
 	// Set custom validator
-	e.Validator = &CustomValidator{validator: validator.New()}
+	e.Validator = helper.NewValidator()
 
 	// Register routes
 	routes.SetupRoutes(e)
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// roleTiers ranks the roles allowed to approve requests, higher ranks can approve more
+var roleTiers = map[string]int{
+	"employee": 0,
+	"manager":  1,
+	"admin":    2,
+}
+
+// ApprovalRule requires the given role for amounts up to MaxAmount (0 means no upper bound)
+type ApprovalRule struct {
+	MaxAmount float64
+	Role      string
+}
+
+// ApprovalRouting maps amount ranges to the role required to approve them, ordered by MaxAmount
+type ApprovalRouting []ApprovalRule
+
+// ApprovalConfig holds the approval routing of each request type.
+// Reimbursements are routed by amount, overtime by hours.
+type ApprovalConfig struct {
+	Reimbursement ApprovalRouting
+	Overtime      ApprovalRouting
+	// DirectManagerOnly limits managers to deciding on requests of their direct reports, admins decide on any
+	DirectManagerOnly bool
+}
+
+// LoadApprovalConfig reads the approval routing from the environment.
+// The format is a comma separated list of max:role pairs, with * for no upper bound.
+func LoadApprovalConfig() ApprovalConfig {
+	return ApprovalConfig{
+		Reimbursement: parseApprovalRouting(GetEnv("APPROVAL_REIMBURSEMENT_ROUTING", "1000000:manager,*:admin")),
+		Overtime:      parseApprovalRouting(GetEnv("APPROVAL_OVERTIME_ROUTING", "2:manager,*:admin")),
+
+		DirectManagerOnly: GetEnv("APPROVAL_DIRECT_MANAGER_ONLY", "false") == "true",
+	}
+}
+
+// RequiredRole returns the role required to approve the given amount
+func (r ApprovalRouting) RequiredRole(amount float64) string {
+	for _, rule := range r {
+		if rule.MaxAmount == 0 || amount <= rule.MaxAmount {
+			return rule.Role
+		}
+	}
+	return "admin"
+}
+
+// CanApprove checks if the role meets the required tier for the amount
+func (r ApprovalRouting) CanApprove(role string, amount float64) bool {
+	tier, ok := roleTiers[role]
+	if !ok {
+		return false
+	}
+	return tier >= roleTiers[r.RequiredRole(amount)]
+}
+
+// parseApprovalRouting parses the routing, only admins can approve anything when it is invalid
+func parseApprovalRouting(value string) ApprovalRouting {
+	var routing ApprovalRouting
+	for _, part := range strings.Split(value, ",") {
+		pair := strings.SplitN(strings.TrimSpace(part), ":", 2)
+		if len(pair) != 2 {
+			log.Printf("Invalid approval routing %q, only admins can approve", value)
+			return ApprovalRouting{{Role: "admin"}}
+		}
+
+		rule := ApprovalRule{Role: strings.TrimSpace(pair[1])}
+		if _, ok := roleTiers[rule.Role]; !ok {
+			log.Printf("Invalid approval role %q, only admins can approve", rule.Role)
+			return ApprovalRouting{{Role: "admin"}}
+		}
+		if max := strings.TrimSpace(pair[0]); max != "*" {
+			maxAmount, err := strconv.ParseFloat(max, 64)
+			if err != nil || maxAmount <= 0 {
+				log.Printf("Invalid approval amount %q, only admins can approve", max)
+				return ApprovalRouting{{Role: "admin"}}
+			}
+			rule.MaxAmount = maxAmount
+		}
+		routing = append(routing, rule)
+	}
+	return routing
+}
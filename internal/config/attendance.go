@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultWorkStartTime is the time of day a check-in after which is late
+const defaultWorkStartTime = "09:00"
+
+// LateCheckinPolicy is the work start and the grace period after it, the one rule payroll's late penalty and
+// the late days of attendance stats, summaries and anomalies all count late check-ins by
+type LateCheckinPolicy struct {
+	// WorkStartMinutes is the time of day, in minutes after midnight, a check-in after which is late
+	WorkStartMinutes int
+	// GraceMinutes is how long after the work start a check-in is still on time, the end of the grace
+	// period included. Check-ins after it are late by the minutes since the work start.
+	GraceMinutes int
+}
+
+// LoadLateCheckinPolicy reads the work start and the grace period from the environment. The work start falls
+// back to the deprecated ATTENDANCE_ANOMALY_LATE_CHECKIN_HOUR when only that is set.
+func LoadLateCheckinPolicy() LateCheckinPolicy {
+	start := GetEnv("PAYROLL_WORK_START_TIME", "")
+	if start == "" {
+		start = defaultWorkStartTime
+		if legacy := GetEnv("ATTENDANCE_ANOMALY_LATE_CHECKIN_HOUR", ""); legacy != "" {
+			log.Printf("ATTENDANCE_ANOMALY_LATE_CHECKIN_HOUR is deprecated, set PAYROLL_WORK_START_TIME instead")
+			if hour, err := strconv.Atoi(legacy); err == nil && hour >= 0 && hour < 24 {
+				start = fmt.Sprintf("%02d:00", hour)
+			}
+		}
+	}
+	return LateCheckinPolicy{
+		WorkStartMinutes: parseWorkStartTime(start),
+		GraceMinutes:     getEnvInt("ATTENDANCE_LATE_GRACE_MINUTES", 0),
+	}
+}
+
+// WorkStart returns the work start on the day of the time, in the timezone it is given in
+func (p LateCheckinPolicy) WorkStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location()).
+		Add(time.Duration(p.WorkStartMinutes) * time.Minute)
+}
+
+// LateAfter returns the effective start of the day of the time, the work start plus the grace period,
+// a check-in after which is late
+func (p LateCheckinPolicy) LateAfter(day time.Time) time.Time {
+	return p.WorkStart(day).Add(time.Duration(p.GraceMinutes) * time.Minute)
+}
+
+// IsLate reports whether a check-in, in the timezone it is given in, is after the end of the grace period
+func (p LateCheckinPolicy) IsLate(checkin time.Time) bool {
+	return checkin.After(p.LateAfter(checkin))
+}
+
+// Limit formats the effective start, the work start plus the grace period, as HH:MM
+func (p LateCheckinPolicy) Limit() string {
+	minutes := p.WorkStartMinutes + p.GraceMinutes
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// AttendanceAnomalyConfig holds the thresholds an attendance record is flagged against
+type AttendanceAnomalyConfig struct {
+	// Late is the late check-in rule, shared with payroll
+	Late LateCheckinPolicy
+	// MinHours flags completed days with fewer hours worked
+	MinHours int
+	// MaxHours flags days with more hours worked
+	MaxHours int
+}
+
+// LoadAttendanceAnomalyConfig reads the attendance anomaly thresholds from the environment
+func LoadAttendanceAnomalyConfig() AttendanceAnomalyConfig {
+	return AttendanceAnomalyConfig{
+		Late:     LoadLateCheckinPolicy(),
+		MinHours: getEnvInt("ATTENDANCE_ANOMALY_MIN_HOURS", 4),
+		MaxHours: getEnvInt("ATTENDANCE_ANOMALY_MAX_HOURS", 12),
+	}
+}
+
+// IsLateCheckin reports whether a check-in, in the timezone it is given in, is late by the late check-in rule
+func (c AttendanceAnomalyConfig) IsLateCheckin(checkin time.Time) bool {
+	return c.Late.IsLate(checkin)
+}
+
+// LateCheckinLimit formats the effective late check-in time, the work start plus the grace period, as HH:MM
+func (c AttendanceAnomalyConfig) LateCheckinLimit() string {
+	return c.Late.Limit()
+}
+
+// Geofence is a circular area check-ins are accepted in
+type Geofence struct {
+	Name         string
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+}
+
+// GeofenceConfig restricts check-ins to the fences when enabled, so remote workers are not blocked by default
+type GeofenceConfig struct {
+	Enabled bool
+	Fences  []Geofence
+}
+
+// LoadGeofenceConfig reads the check-in geofences from the environment.
+// The fences are a semicolon separated list of name=latitude,longitude,radius_meters entries.
+func LoadGeofenceConfig() GeofenceConfig {
+	cfg := GeofenceConfig{
+		Enabled: GetEnv("ATTENDANCE_GEOFENCE_ENABLED", "false") == "true",
+		Fences:  parseGeofences(GetEnv("ATTENDANCE_GEOFENCES", "")),
+	}
+	if cfg.Enabled && len(cfg.Fences) == 0 {
+		log.Printf("Geofencing is enabled without valid ATTENDANCE_GEOFENCES, every check-in will be refused")
+	}
+	return cfg
+}
+
+// parseGeofences parses the fences, invalid entries are logged and left out
+func parseGeofences(value string) []Geofence {
+	var fences []Geofence
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, coordinates, found := strings.Cut(entry, "=")
+		if !found {
+			name, coordinates = "", entry
+		}
+		parts := strings.Split(coordinates, ",")
+		if len(parts) != 3 {
+			log.Printf("Invalid geofence %q, expected name=latitude,longitude,radius_meters", entry)
+			continue
+		}
+
+		var numbers [3]float64
+		valid := true
+		for i, part := range parts {
+			number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				valid = false
+				break
+			}
+			numbers[i] = number
+		}
+		fence := Geofence{Name: strings.TrimSpace(name), Latitude: numbers[0], Longitude: numbers[1], RadiusMeters: numbers[2]}
+		if !valid || fence.Latitude < -90 || fence.Latitude > 90 || fence.Longitude < -180 || fence.Longitude > 180 || fence.RadiusMeters <= 0 {
+			log.Printf("Invalid geofence %q, expected name=latitude,longitude,radius_meters", entry)
+			continue
+		}
+		fences = append(fences, fence)
+	}
+	return fences
+}
@@ -0,0 +1,56 @@
+// Package config contains unit tests for the attendance configuration.
+//
+// AttendanceAnomalyConfig tests cover:
+// 1. A check-in at the work start on time, late a second after it or after the end of the grace period
+// 2. The work start and grace period read once into the attendance and payroll configurations
+// 3. The deprecated late check-in hour used as the work start only when PAYROLL_WORK_START_TIME is unset
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttendanceAnomalyConfig_IsLateCheckin(t *testing.T) {
+	at := func(hour, minute, second int) time.Time {
+		return time.Date(2025, time.June, 2, hour, minute, second, 0, time.UTC)
+	}
+
+	cfg := AttendanceAnomalyConfig{Late: LateCheckinPolicy{WorkStartMinutes: 10 * 60}}
+	assert.False(t, cfg.IsLateCheckin(at(10, 0, 0)))
+	assert.True(t, cfg.IsLateCheckin(at(10, 0, 1)))
+	assert.Equal(t, "10:00", cfg.LateCheckinLimit())
+
+	cfg.Late.GraceMinutes = 5
+	assert.False(t, cfg.IsLateCheckin(at(10, 0, 0)))
+	assert.False(t, cfg.IsLateCheckin(at(10, 5, 0)))
+	assert.True(t, cfg.IsLateCheckin(at(10, 5, 1)))
+	assert.Equal(t, "10:05", cfg.LateCheckinLimit())
+}
+
+func TestLoadLateGraceMinutes(t *testing.T) {
+	t.Setenv("PAYROLL_WORK_START_TIME", "08:30")
+	t.Setenv("ATTENDANCE_LATE_GRACE_MINUTES", "15")
+	t.Setenv("SERVER_TIMEZONE", "UTC")
+
+	assert.Equal(t, LateCheckinPolicy{WorkStartMinutes: 8*60 + 30, GraceMinutes: 15}, LoadAttendanceAnomalyConfig().Late)
+	payroll := LoadPayrollConfig()
+	assert.Equal(t, LoadAttendanceAnomalyConfig().Late, payroll.Late)
+	day := time.Date(2025, time.June, 2, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.June, 2, 8, 30, 0, 0, time.UTC), payroll.WorkStart(day))
+	assert.Equal(t, time.Date(2025, time.June, 2, 8, 45, 0, 0, time.UTC), payroll.LateAfter(day))
+
+	t.Setenv("ATTENDANCE_LATE_GRACE_MINUTES", "-5")
+	assert.Equal(t, 0, LoadAttendanceAnomalyConfig().Late.GraceMinutes)
+}
+
+func TestLoadLateCheckinPolicy_DeprecatedHour(t *testing.T) {
+	t.Setenv("PAYROLL_WORK_START_TIME", "")
+	t.Setenv("ATTENDANCE_ANOMALY_LATE_CHECKIN_HOUR", "10")
+	assert.Equal(t, 10*60, LoadLateCheckinPolicy().WorkStartMinutes)
+
+	t.Setenv("PAYROLL_WORK_START_TIME", "08:30")
+	assert.Equal(t, 8*60+30, LoadLateCheckinPolicy().WorkStartMinutes)
+}
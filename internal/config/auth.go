@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+// AuthConfig holds the lifetime of the issued tokens
+type AuthConfig struct {
+	// AccessTokenTTL is how long an access token is valid, keep it short as it cannot be revoked
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is how long a refresh token can be exchanged for a new access token
+	RefreshTokenTTL time.Duration
+	// PasswordResetTTL is how long a password reset token can be used
+	PasswordResetTTL time.Duration
+	// RateLimitRequests is how many login or password reset requests one IP address, and separately one
+	// login name, may send per RateLimitWindow before getting 429 (0 disables the limit)
+	RateLimitRequests int
+	// RateLimitWindow is the time in which the allowed requests are refilled
+	RateLimitWindow time.Duration
+}
+
+// LoadAuthConfig reads the token lifetimes from the environment
+func LoadAuthConfig() AuthConfig {
+	return AuthConfig{
+		AccessTokenTTL:    time.Duration(getEnvInt("AUTH_ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL:   time.Duration(getEnvInt("AUTH_REFRESH_TOKEN_TTL_HOURS", 168)) * time.Hour,
+		PasswordResetTTL:  time.Duration(getEnvInt("AUTH_PASSWORD_RESET_TTL_MINUTES", 30)) * time.Minute,
+		RateLimitRequests: max(getEnvInt("AUTH_RATE_LIMIT_REQUESTS", 10), 0),
+		RateLimitWindow:   time.Duration(max(getEnvInt("AUTH_RATE_LIMIT_WINDOW_SECONDS", 60), 1)) * time.Second,
+	}
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"log"
+	"net"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -26,3 +28,36 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// LoadTimeLocation returns the server timezone from SERVER_TIMEZONE, calendar days such as
+// "today" for attendance are taken in it. Falls back to the process local time on an unknown zone.
+func LoadTimeLocation() *time.Location {
+	loc, err := time.LoadLocation(GetEnv("SERVER_TIMEZONE", "Local"))
+	if err != nil {
+		log.Printf("Invalid SERVER_TIMEZONE, using local time: %v", err)
+		return time.Local
+	}
+	return loc
+}
+
+// LoadTrustedProxies reads the proxies, a comma separated list of IP addresses or CIDR ranges in TRUSTED_PROXIES,
+// whose X-Forwarded-For header names the client IP. Invalid entries are logged and left out, none trusts no
+// proxy so the client IP is the address of the connection.
+func LoadTrustedProxies() []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, entry := range splitList(GetEnv("TRUSTED_PROXIES", "")) {
+		cidr := entry
+		if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+			cidr += "/32"
+		} else if ip != nil {
+			cidr += "/128"
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Printf("Invalid trusted proxy %q, expected an IP address or a CIDR range", entry)
+			continue
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies
+}
@@ -0,0 +1,28 @@
+// Package config contains unit tests for the server configuration.
+//
+// LoadTrustedProxies tests cover:
+// 1. IP addresses and CIDR ranges parsed into networks, invalid entries left out
+// 2. No proxy trusted when TRUSTED_PROXIES is unset
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTrustedProxies(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", " 10.0.0.0/8, 203.0.113.7 ,::1, not-an-ip, 10.0.0.0/99")
+
+	proxies := LoadTrustedProxies()
+	require.Len(t, proxies, 3)
+	assert.Equal(t, "10.0.0.0/8", proxies[0].String())
+	assert.Equal(t, "203.0.113.7/32", proxies[1].String())
+	assert.Equal(t, "::1/128", proxies[2].String())
+}
+
+func TestLoadTrustedProxies_Unset(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	assert.Empty(t, LoadTrustedProxies())
+}
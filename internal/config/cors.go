@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"log"
+	"strings"
+)
+
+// defaultCORSOrigins are the local frontends allowed outside production when CORS_ALLOWED_ORIGINS is unset
+var defaultCORSOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:5173",
+	"http://localhost:8080",
+	"http://127.0.0.1:3000",
+	"http://127.0.0.1:5173",
+	"http://127.0.0.1:8080",
+}
+
+// ErrCORSOriginsRequired is returned in production when no allowed origin is configured
+var ErrCORSOriginsRequired = errors.New("CORS_ALLOWED_ORIGINS must list at least one origin when APP_ENV=production")
+
+// CORSConfig holds the origins, methods and headers cross-origin requests are allowed with
+type CORSConfig struct {
+	AllowOrigins []string
+	AllowMethods []string
+	AllowHeaders []string
+	// Production is set by APP_ENV=production, where the localhost defaults do not apply
+	Production bool
+}
+
+// LoadCORSConfig reads the allowed origins, methods and headers from comma separated environment variables.
+// Without CORS_ALLOWED_ORIGINS only local frontends are allowed, and in production none.
+func LoadCORSConfig() CORSConfig {
+	production := strings.EqualFold(GetEnv("APP_ENV", "development"), "production")
+
+	origins := splitList(GetEnv("CORS_ALLOWED_ORIGINS", ""))
+	if origins == nil && !production {
+		origins = defaultCORSOrigins
+	}
+
+	return CORSConfig{
+		AllowOrigins: origins,
+		AllowMethods: splitList(GetEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS")),
+		AllowHeaders: splitList(GetEnv("CORS_ALLOWED_HEADERS", "Origin,Content-Type,Accept,Authorization,Idempotency-Key,X-Request-ID")),
+		Production:   production,
+	}
+}
+
+// Validate refuses a production configuration without allowed origins, and logs a wildcard origin there
+func (c CORSConfig) Validate() error {
+	if !c.Production {
+		return nil
+	}
+	if len(c.AllowOrigins) == 0 {
+		return ErrCORSOriginsRequired
+	}
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			log.Printf("CORS_ALLOWED_ORIGINS allows every origin in production")
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma separated value, dropping blank entries, nil when none is left
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,49 @@
+// Package config contains unit tests for the CORS configuration.
+//
+// LoadCORSConfig tests cover:
+// 1. Localhost origins outside production when CORS_ALLOWED_ORIGINS is unset, never a wildcard
+// 2. Comma separated origins, methods and headers trimmed with blank entries dropped
+// 3. Production without origins refused by Validate, with origins accepted
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCORSConfig_DefaultsToLocalhost(t *testing.T) {
+	t.Setenv("APP_ENV", "development")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+
+	cfg := LoadCORSConfig()
+	assert.Equal(t, defaultCORSOrigins, cfg.AllowOrigins)
+	assert.NotContains(t, cfg.AllowOrigins, "*")
+	assert.Contains(t, cfg.AllowHeaders, "Authorization")
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadCORSConfig_ParsesLists(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	t.Setenv("CORS_ALLOWED_ORIGINS", " https://app.example.com, ,https://admin.example.com ")
+	t.Setenv("CORS_ALLOWED_METHODS", "GET, POST")
+	t.Setenv("CORS_ALLOWED_HEADERS", "Authorization,Content-Type,")
+
+	cfg := LoadCORSConfig()
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, cfg.AllowOrigins)
+	assert.Equal(t, []string{"GET", "POST"}, cfg.AllowMethods)
+	assert.Equal(t, []string{"Authorization", "Content-Type"}, cfg.AllowHeaders)
+}
+
+func TestCORSConfig_Validate_Production(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("CORS_ALLOWED_ORIGINS", " , ")
+
+	cfg := LoadCORSConfig()
+	assert.True(t, cfg.Production)
+	assert.Empty(t, cfg.AllowOrigins)
+	assert.ErrorIs(t, cfg.Validate(), ErrCORSOriginsRequired)
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com")
+	assert.NoError(t, LoadCORSConfig().Validate())
+}
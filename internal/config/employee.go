@@ -0,0 +1,21 @@
+package config
+
+// EmployeeRetentionConfig holds the policy for soft-deleted employees
+type EmployeeRetentionConfig struct {
+	// AllowDeletedNameReuse lets a new employee take the name of a soft-deleted one right away,
+	// otherwise the name is free again once the deleted employee is purged
+	AllowDeletedNameReuse bool
+	// RetentionDays is how long a soft-deleted employee is kept before the purge job hard-deletes it (0 disables the purge)
+	RetentionDays int
+	// PurgeIntervalHours is how often the purge job runs
+	PurgeIntervalHours int
+}
+
+// LoadEmployeeRetentionConfig reads the soft-deleted employee policy from the environment
+func LoadEmployeeRetentionConfig() EmployeeRetentionConfig {
+	return EmployeeRetentionConfig{
+		AllowDeletedNameReuse: GetEnv("EMPLOYEE_ALLOW_DELETED_NAME_REUSE", "true") == "true",
+		RetentionDays:         getEnvInt("EMPLOYEE_DELETED_RETENTION_DAYS", 0),
+		PurgeIntervalHours:    getEnvInt("EMPLOYEE_PURGE_INTERVAL_HOURS", 24),
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// LogFormat is the output format of the application log
+type LogFormat string
+
+const (
+	// LogFormatJSON writes one JSON object per line, for log collectors
+	LogFormatJSON LogFormat = "json"
+	// LogFormatText writes key=value lines, easier to read in development
+	LogFormatText LogFormat = "text"
+)
+
+// LoggingConfig holds the format and level of the application log
+type LoggingConfig struct {
+	Format LogFormat
+	Level  slog.Level
+}
+
+// LoadLoggingConfig reads the log format and level from the environment
+func LoadLoggingConfig() LoggingConfig {
+	return LoggingConfig{
+		Format: parseLogFormat(GetEnv("LOG_FORMAT", string(LogFormatJSON))),
+		Level:  parseLogLevel(GetEnv("LOG_LEVEL", "info")),
+	}
+}
+
+// NewLogger returns a structured logger writing to w in the configured format
+func (c LoggingConfig) NewLogger(w io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: c.Level}
+	if c.Format == LogFormatText {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// parseLogFormat falls back to JSON on an unknown format
+func parseLogFormat(value string) LogFormat {
+	switch format := LogFormat(strings.ToLower(value)); format {
+	case LogFormatJSON, LogFormatText:
+		return format
+	default:
+		log.Printf("Invalid LOG_FORMAT %q, expected json or text, using json", value)
+		return LogFormatJSON
+	}
+}
+
+// parseLogLevel falls back to info on an unknown level
+func parseLogLevel(value string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		log.Printf("Invalid LOG_LEVEL %q, expected debug, info, warn or error, using info", value)
+		return slog.LevelInfo
+	}
+	return level
+}
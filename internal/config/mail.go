@@ -0,0 +1,35 @@
+package config
+
+import "fmt"
+
+// MailConfig is the SMTP server payslips are emailed through.
+// Mail is disabled while no host is set.
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender address of every email
+	From string
+}
+
+// LoadMailConfig reads the SMTP settings from the environment
+func LoadMailConfig() MailConfig {
+	return MailConfig{
+		Host:     GetEnv("SMTP_HOST", ""),
+		Port:     getEnvInt("SMTP_PORT", 587),
+		Username: GetEnv("SMTP_USERNAME", ""),
+		Password: GetEnv("SMTP_PASSWORD", ""),
+		From:     GetEnv("MAIL_FROM", "payroll@localhost"),
+	}
+}
+
+// Enabled checks if an SMTP server is configured
+func (c MailConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// Addr returns the host:port of the SMTP server
+func (c MailConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
@@ -0,0 +1,20 @@
+package config
+
+// OvertimeConfig holds the limits an overtime request is checked against and how overtime is derived from attendance
+type OvertimeConfig struct {
+	// MaxMonthlyHours caps the approved and pending overtime hours of an employee per calendar month (0 disables it)
+	MaxMonthlyHours int
+	// AutoDerive creates a pending overtime on checkout for the whole hours worked beyond StandardDayHours
+	AutoDerive bool
+	// StandardDayHours is the length of a working day, hours worked beyond it are overtime
+	StandardDayHours int
+}
+
+// LoadOvertimeConfig reads the overtime limits and derivation settings from the environment
+func LoadOvertimeConfig() OvertimeConfig {
+	return OvertimeConfig{
+		MaxMonthlyHours:  getEnvInt("OVERTIME_MAX_MONTHLY_HOURS", 72),
+		AutoDerive:       GetEnv("OVERTIME_AUTO_DERIVE", "false") == "true",
+		StandardDayHours: getEnvInt("OVERTIME_STANDARD_DAY_HOURS", 8),
+	}
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenCheckoutPolicy decides how an attendance day without a checkout counts for payroll
+type OpenCheckoutPolicy string
+
+const (
+	// OpenCheckoutPresent counts an open day as a full present day (default, matches the original behavior)
+	OpenCheckoutPresent OpenCheckoutPolicy = "present"
+	// OpenCheckoutHalfDay counts an open day as half a day
+	OpenCheckoutHalfDay OpenCheckoutPolicy = "half_day"
+	// OpenCheckoutExclude does not count an open day at all
+	OpenCheckoutExclude OpenCheckoutPolicy = "exclude"
+)
+
+// NegativeNetPolicy decides what happens when deductions exceed the gross pay
+type NegativeNetPolicy string
+
+const (
+	// NegativeNetClamp pays zero and carries the unrecovered deduction to the next payslip (default)
+	NegativeNetClamp NegativeNetPolicy = "clamp"
+	// NegativeNetReject refuses to create the payslip
+	NegativeNetReject NegativeNetPolicy = "reject"
+	// NegativeNetAllow keeps the negative net pay, e.g. for clawbacks
+	NegativeNetAllow NegativeNetPolicy = "allow"
+)
+
+// MoneyRounding decides how payroll amounts are rounded to their decimal places
+type MoneyRounding string
+
+const (
+	// MoneyRoundHalfUp rounds halves away from zero (default, matches the original behavior)
+	MoneyRoundHalfUp MoneyRounding = "half_up"
+	// MoneyRoundHalfEven rounds halves to the even digit (banker's rounding)
+	MoneyRoundHalfEven MoneyRounding = "half_even"
+)
+
+// LatePenaltyMode decides how check-ins after the work start time are docked from pay
+type LatePenaltyMode string
+
+const (
+	// LatePenaltyOff counts late arrivals without docking pay (default)
+	LatePenaltyOff LatePenaltyMode = "off"
+	// LatePenaltyPerIncident docks the penalty amount once for each late arrival
+	LatePenaltyPerIncident LatePenaltyMode = "per_incident"
+	// LatePenaltyPerMinute docks the penalty amount for each minute late
+	LatePenaltyPerMinute LatePenaltyMode = "per_minute"
+)
+
+// MinimumWageMode decides what happens when the net pay of a full period falls below the minimum wage
+type MinimumWageMode string
+
+const (
+	// MinimumWageWarn processes the payslip with a warning and the shortfall (default)
+	MinimumWageWarn MinimumWageMode = "warn"
+	// MinimumWageEnforce fails the employee's payroll
+	MinimumWageEnforce MinimumWageMode = "enforce"
+)
+
+// maxMoneyDecimals is the most decimal places payroll amounts can be rounded to
+const maxMoneyDecimals = 6
+
+// PayrollConfig holds the payroll calculation policies
+type PayrollConfig struct {
+	OpenCheckoutPolicy OpenCheckoutPolicy
+	// RequireApprovedTimesheet blocks payroll for a period until the employee's timesheet is approved
+	RequireApprovedTimesheet bool
+	// UnpaidOvertimeMinutes is subtracted from each overtime entry before computing pay (0 disables it)
+	UnpaidOvertimeMinutes int
+	// Currency is the ISO 4217 code used when a payroll run does not specify one
+	Currency string
+	// NegativeNetPolicy decides how a payslip with deductions above the gross pay is handled
+	NegativeNetPolicy NegativeNetPolicy
+	// MaxPeriodDays is the longest period, in days, payroll and summaries accept in one request (0 disables it)
+	MaxPeriodDays int
+	// ParallelDetailFetch loads the attendance, overtime and reimbursements of a detailed payslip concurrently
+	ParallelDetailFetch bool
+	// WeekendOvertimeMultiplier scales the overtime rate for overtime worked on a Saturday or Sunday
+	WeekendOvertimeMultiplier float64
+	// HolidayOvertimeMultiplier scales the overtime rate for overtime worked on a holiday
+	HolidayOvertimeMultiplier float64
+	// Workers is the number of employees a payroll run processes at the same time (1 processes them one by one)
+	Workers int
+	// WorkingDaysPerPeriod is the standard number of working days the basic salary pays for, giving the daily
+	// rate. 0 disables the daily rate and with it absence deductions.
+	WorkingDaysPerPeriod int
+	// OvertimeRateMultiplier scales the hourly rate of the basic salary into the overtime rate of runs that
+	// derive it instead of passing one
+	OvertimeRateMultiplier float64
+	// AbsenceDeduction deducts the daily rate for each standard working day without attendance
+	AbsenceDeduction bool
+	// MoneyRounding is how amounts are rounded at each computation step, empty rounds halves up
+	MoneyRounding MoneyRounding
+	// MoneyDecimals is the decimal places amounts are rounded to, nil uses the precision of the payslip currency
+	MoneyDecimals *uint
+	// MoneyMinorUnits stores the amounts of payslips as integer minor units too, which detailed payslips are
+	// then read from
+	MoneyMinorUnits bool
+	// Location is the server timezone the days of a pay period are taken in, nil uses the process local time
+	Location *time.Location
+	// Late is the work start, in the server timezone, and the grace period a check-in after which is late,
+	// shared with the attendance late flags
+	Late LateCheckinPolicy
+	// LatePenaltyMode is how late arrivals are docked from pay, empty docks nothing
+	LatePenaltyMode LatePenaltyMode
+	// LatePenaltyAmount is docked per late arrival or per minute late, depending on LatePenaltyMode
+	LatePenaltyAmount float64
+	// MinimumWage is the least net pay of a full period in the base currency (0 disables the check)
+	MinimumWage float64
+	// MinimumWageMode is how a net pay below MinimumWage is handled, empty warns
+	MinimumWageMode MinimumWageMode
+	// ReimbursementFloor is the least total of the reimbursements paid in one payslip, negative clawbacks
+	// included. It may be negative to let clawbacks reduce the pay beyond the period's reimbursements.
+	ReimbursementFloor float64
+}
+
+// LoadPayrollConfig reads the payroll policies from the environment
+func LoadPayrollConfig() PayrollConfig {
+	return PayrollConfig{
+		OpenCheckoutPolicy:        parseOpenCheckoutPolicy(GetEnv("PAYROLL_OPEN_CHECKOUT_POLICY", string(OpenCheckoutPresent))),
+		RequireApprovedTimesheet:  GetEnv("PAYROLL_REQUIRE_APPROVED_TIMESHEET", "false") == "true",
+		UnpaidOvertimeMinutes:     getEnvInt("PAYROLL_UNPAID_OVERTIME_MINUTES", 0),
+		Currency:                  strings.ToUpper(GetEnv("PAYROLL_CURRENCY", "IDR")),
+		NegativeNetPolicy:         parseNegativeNetPolicy(GetEnv("PAYROLL_NEGATIVE_NET_POLICY", string(NegativeNetClamp))),
+		MaxPeriodDays:             getEnvInt("PAYROLL_MAX_PERIOD_DAYS", 366),
+		ParallelDetailFetch:       GetEnv("PAYROLL_PARALLEL_DETAIL_FETCH", "true") == "true",
+		WeekendOvertimeMultiplier: getEnvFloat("PAYROLL_OVERTIME_WEEKEND_MULTIPLIER", 1.5),
+		HolidayOvertimeMultiplier: getEnvFloat("PAYROLL_OVERTIME_HOLIDAY_MULTIPLIER", 2),
+		Workers:                   max(getEnvInt("PAYROLL_WORKERS", 4), 1),
+		WorkingDaysPerPeriod:      parseWorkingDaysPerPeriod(getEnvInt("PAYROLL_WORKING_DAYS_PER_PERIOD", 22)),
+		OvertimeRateMultiplier:    max(getEnvFloat("PAYROLL_OVERTIME_RATE_MULTIPLIER", 1.5), 0),
+		AbsenceDeduction:          GetEnv("PAYROLL_ABSENCE_DEDUCTION", "false") == "true",
+		MoneyRounding:             parseMoneyRounding(GetEnv("PAYROLL_MONEY_ROUNDING", string(MoneyRoundHalfUp))),
+		MoneyDecimals:             parseMoneyDecimals(GetEnv("PAYROLL_MONEY_DECIMALS", "")),
+		MoneyMinorUnits:           GetEnv("PAYROLL_MONEY_MINOR_UNITS", "false") == "true",
+		Location:                  LoadTimeLocation(),
+		Late:                      LoadLateCheckinPolicy(),
+		LatePenaltyMode:           parseLatePenaltyMode(GetEnv("PAYROLL_LATE_PENALTY_MODE", string(LatePenaltyOff))),
+		LatePenaltyAmount:         max(getEnvFloat("PAYROLL_LATE_PENALTY_AMOUNT", 0), 0),
+		MinimumWage:               max(getEnvFloat("PAYROLL_MINIMUM_WAGE", 0), 0),
+		MinimumWageMode:           parseMinimumWageMode(GetEnv("PAYROLL_MINIMUM_WAGE_MODE", string(MinimumWageWarn))),
+		ReimbursementFloor:        parseReimbursementFloor(GetEnv("PAYROLL_REIMBURSEMENT_FLOOR", "0")),
+	}
+}
+
+// WorkStart returns the work start on the day of the time, in the server timezone
+func (c PayrollConfig) WorkStart(day time.Time) time.Time {
+	return c.Late.WorkStart(day.In(c.TimeLocation()))
+}
+
+// LateAfter returns the effective start of the day of the time in the server timezone, the work start plus
+// the grace period, a check-in after which is late
+func (c PayrollConfig) LateAfter(day time.Time) time.Time {
+	return c.Late.LateAfter(day.In(c.TimeLocation()))
+}
+
+// PeriodWithinLimit checks the period, both days included, against MaxPeriodDays
+func (c PayrollConfig) PeriodWithinLimit(start, end time.Time) bool {
+	if c.MaxPeriodDays == 0 {
+		return true
+	}
+	days := int(end.Sub(start).Hours()/24) + 1
+	return days <= c.MaxPeriodDays
+}
+
+// TimeLocation is the server timezone the days of a pay period are taken in, the process local time when unset
+func (c PayrollConfig) TimeLocation() *time.Location {
+	if c.Location != nil {
+		return c.Location
+	}
+	return time.Local
+}
+
+// PeriodRange is the span of whole days a pay period covers in the server timezone
+type PeriodRange struct {
+	Start     time.Time // Start of the first day
+	End       time.Time // Last instant of the last day
+	StartDate string    // First day as YYYY-MM-DD, as overtime and holidays are dated
+	EndDate   string    // Last day as YYYY-MM-DD
+}
+
+// PeriodRange takes the days the period boundaries fall on in the server timezone, from the start of the
+// first day to the end of the last one, so records dated anywhere on a boundary day are included whatever
+// zone the boundaries were given or stored in
+func (c PayrollConfig) PeriodRange(start, end time.Time) PeriodRange {
+	loc := c.TimeLocation()
+	start, end = start.In(loc), end.In(loc)
+	firstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	lastDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+	return PeriodRange{
+		Start: firstDay,
+		// Databases keep microseconds, a nanosecond before midnight could be rounded up to the next day
+		End:       lastDay.AddDate(0, 0, 1).Add(-time.Microsecond),
+		StartDate: firstDay.Format("2006-01-02"),
+		EndDate:   lastDay.Format("2006-01-02"),
+	}
+}
+
+// parseOpenCheckoutPolicy falls back to the default policy for unknown values
+func parseOpenCheckoutPolicy(value string) OpenCheckoutPolicy {
+	switch policy := OpenCheckoutPolicy(value); policy {
+	case OpenCheckoutPresent, OpenCheckoutHalfDay, OpenCheckoutExclude:
+		return policy
+	default:
+		log.Printf("Unknown open checkout policy %q, using %q", value, OpenCheckoutPresent)
+		return OpenCheckoutPresent
+	}
+}
+
+// parseNegativeNetPolicy falls back to clamping for unknown values
+func parseNegativeNetPolicy(value string) NegativeNetPolicy {
+	switch policy := NegativeNetPolicy(value); policy {
+	case NegativeNetClamp, NegativeNetReject, NegativeNetAllow:
+		return policy
+	default:
+		log.Printf("Unknown negative net policy %q, using %q", value, NegativeNetClamp)
+		return NegativeNetClamp
+	}
+}
+
+// parseMinimumWageMode falls back to warning for unknown values
+func parseMinimumWageMode(value string) MinimumWageMode {
+	switch mode := MinimumWageMode(value); mode {
+	case MinimumWageWarn, MinimumWageEnforce:
+		return mode
+	default:
+		log.Printf("Unknown minimum wage mode %q, using %q", value, MinimumWageWarn)
+		return MinimumWageWarn
+	}
+}
+
+// parseMoneyRounding falls back to rounding halves up for unknown values
+func parseMoneyRounding(value string) MoneyRounding {
+	switch rounding := MoneyRounding(value); rounding {
+	case MoneyRoundHalfUp, MoneyRoundHalfEven:
+		return rounding
+	default:
+		log.Printf("Unknown money rounding %q, using %q", value, MoneyRoundHalfUp)
+		return MoneyRoundHalfUp
+	}
+}
+
+// parseMoneyDecimals falls back to the currency precision when unset or invalid
+func parseMoneyDecimals(value string) *uint {
+	if value == "" {
+		return nil
+	}
+	decimals, err := strconv.Atoi(value)
+	if err != nil || decimals < 0 || decimals > maxMoneyDecimals {
+		log.Printf("Invalid money decimals %q, expected 0 to %d, using the currency precision", value, maxMoneyDecimals)
+		return nil
+	}
+	places := uint(decimals)
+	return &places
+}
+
+// parseReimbursementFloor reads the reimbursement floor, which unlike other amounts may be negative, falling back to 0
+func parseReimbursementFloor(value string) float64 {
+	floor, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(floor) || math.IsInf(floor, 0) {
+		log.Printf("Invalid reimbursement floor %q, using 0", value)
+		return 0
+	}
+	return floor
+}
+
+// parseWorkStartTime reads an HH:MM time of day as minutes after midnight, falling back to the default
+func parseWorkStartTime(value string) int {
+	start, err := time.Parse("15:04", value)
+	if err != nil {
+		log.Printf("Invalid work start time %q, expected HH:MM, using %s", value, defaultWorkStartTime)
+		start, _ = time.Parse("15:04", defaultWorkStartTime)
+	}
+	return start.Hour()*60 + start.Minute()
+}
+
+// parseLatePenaltyMode falls back to no penalty for unknown values
+func parseLatePenaltyMode(value string) LatePenaltyMode {
+	switch mode := LatePenaltyMode(value); mode {
+	case LatePenaltyOff, LatePenaltyPerIncident, LatePenaltyPerMinute:
+		return mode
+	default:
+		log.Printf("Unknown late penalty mode %q, using %q", value, LatePenaltyOff)
+		return LatePenaltyOff
+	}
+}
+
+// parseWorkingDaysPerPeriod disables the daily rate for a misconfigured zero or negative value
+func parseWorkingDaysPerPeriod(days int) int {
+	if days <= 0 {
+		log.Printf("Invalid working days per period %d, daily rate and absence deductions are disabled", days)
+		return 0
+	}
+	return days
+}
+
+// IdempotencyConfig holds how long the responses of requests sent with an Idempotency-Key are kept
+type IdempotencyConfig struct {
+	// KeyTTL is how long a key replays its stored response, after that the key can be used again
+	KeyTTL time.Duration
+}
+
+// LoadIdempotencyConfig reads the idempotency key lifetime from the environment
+func LoadIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		KeyTTL: time.Duration(getEnvInt("PAYROLL_IDEMPOTENCY_KEY_TTL_HOURS", 24)) * time.Hour,
+	}
+}
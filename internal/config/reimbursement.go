@@ -0,0 +1,74 @@
+package config
+
+import (
+	"log"
+	"strconv"
+)
+
+// DuplicateCheckConfig controls the near-duplicate detection on reimbursement creation.
+// Matches are only flagged, the reimbursement is still created.
+type DuplicateCheckConfig struct {
+	Enabled bool
+	// WindowDays is how many days apart two reimbursements may be to count as the same expense
+	WindowDays int
+	// AmountTolerance is the maximum amount difference to count as the same expense
+	AmountTolerance float64
+}
+
+// LoadDuplicateCheckConfig reads the reimbursement duplicate detection from the environment
+func LoadDuplicateCheckConfig() DuplicateCheckConfig {
+	return DuplicateCheckConfig{
+		Enabled:         GetEnv("REIMBURSEMENT_DUPLICATE_CHECK", "false") == "true",
+		WindowDays:      getEnvInt("REIMBURSEMENT_DUPLICATE_WINDOW_DAYS", 3),
+		AmountTolerance: getEnvFloat("REIMBURSEMENT_DUPLICATE_AMOUNT_TOLERANCE", 0),
+	}
+}
+
+// ReceiptStorageConfig controls where reimbursement receipts are stored and how large they may be
+type ReceiptStorageConfig struct {
+	// Dir is the local directory receipts are written to, created on first upload
+	Dir string
+	// MaxSizeBytes is the largest receipt accepted
+	MaxSizeBytes int64
+}
+
+// LoadReceiptStorageConfig reads the reimbursement receipt storage from the environment
+func LoadReceiptStorageConfig() ReceiptStorageConfig {
+	return ReceiptStorageConfig{
+		Dir:          GetEnv("REIMBURSEMENT_RECEIPT_DIR", "uploads/receipts"),
+		MaxSizeBytes: int64(getEnvInt("REIMBURSEMENT_RECEIPT_MAX_SIZE_MB", 5)) << 20,
+	}
+}
+
+// ReimbursementBatchConfig limits the reimbursements an employee submits at once
+type ReimbursementBatchConfig struct {
+	// MaxItems is the most reimbursements one batch may contain
+	MaxItems int
+}
+
+// LoadReimbursementBatchConfig reads the reimbursement batch size limit from the environment
+func LoadReimbursementBatchConfig() ReimbursementBatchConfig {
+	return ReimbursementBatchConfig{
+		MaxItems: max(getEnvInt("REIMBURSEMENT_BATCH_MAX_ITEMS", 20), 1),
+	}
+}
+
+// getEnvInt retrieves a non-negative integer environment variable or returns the default value
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(GetEnv(key, strconv.Itoa(defaultValue)))
+	if err != nil || value < 0 {
+		log.Printf("Invalid %s, using %d", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat retrieves a non-negative float environment variable or returns the default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(GetEnv(key, strconv.FormatFloat(defaultValue, 'f', -1, 64)), 64)
+	if err != nil || value < 0 {
+		log.Printf("Invalid %s, using %v", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
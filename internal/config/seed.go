@@ -0,0 +1,20 @@
+package config
+
+// SeedConfig controls the demo data seeded into an empty database
+type SeedConfig struct {
+	// Enabled seeds on every boot, otherwise seeding only runs when the server is started with -seed
+	Enabled bool
+	// Employees is how many employees are created next to the admin
+	Employees int
+	// WithActivity also generates attendance, overtime and reimbursements for the current month
+	WithActivity bool
+}
+
+// LoadSeedConfig reads the seeding options from the environment
+func LoadSeedConfig() SeedConfig {
+	return SeedConfig{
+		Enabled:      GetEnv("SEED_ENABLED", "false") == "true",
+		Employees:    getEnvInt("SEED_EMPLOYEES", 100),
+		WithActivity: GetEnv("SEED_WITH_ACTIVITY", "false") == "true",
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// WebhookConfig is the endpoint integrations receive payroll events on.
+// Webhooks are disabled while no URL is set.
+type WebhookConfig struct {
+	URL string
+	// Secret signs every payload with HMAC-SHA256, sent in the X-Webhook-Signature header
+	Secret string
+	// MaxAttempts is how often a delivery is tried before giving up
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled for every further retry
+	Backoff time.Duration
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration
+}
+
+// LoadWebhookConfig reads the webhook endpoint from the environment
+func LoadWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		URL:         GetEnv("PAYROLL_WEBHOOK_URL", ""),
+		Secret:      GetEnv("PAYROLL_WEBHOOK_SECRET", ""),
+		MaxAttempts: max(getEnvInt("PAYROLL_WEBHOOK_MAX_ATTEMPTS", 3), 1),
+		Backoff:     time.Duration(getEnvInt("PAYROLL_WEBHOOK_BACKOFF_MS", 500)) * time.Millisecond,
+		Timeout:     time.Duration(max(getEnvInt("PAYROLL_WEBHOOK_TIMEOUT_SECONDS", 10), 1)) * time.Second,
+	}
+}
+
+// Enabled checks if a webhook URL is configured
+func (c WebhookConfig) Enabled() bool {
+	return c.URL != ""
+}
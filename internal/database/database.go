@@ -1,10 +1,13 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/model"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -45,3 +48,60 @@ func Close(db *gorm.DB) {
 		log.Println("Database connection closed.")
 	}
 }
+
+// BackfillCurrency gives employees and payslips stored before currencies were tracked the base currency
+func BackfillCurrency(db *gorm.DB, currency string) error {
+	for _, table := range []string{"employees", "payslips"} {
+		err := db.Table(table).Where("currency IS NULL OR currency = ''").Update("currency", currency).Error
+		if err != nil {
+			return fmt.Errorf("failed to backfill the currency of %s: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// BackfillMinorUnits fills the integer minor unit columns of payslips stored without them, at the
+// decimal places returned for their currency. The decimal amounts stay as they are.
+func BackfillMinorUnits(db *gorm.DB, decimals func(currency string) uint) error {
+	var payslips []model.Payslip
+	err := db.Where("minor_unit_scale IS NULL").FindInBatches(&payslips, 500, func(tx *gorm.DB, batch int) error {
+		for i := range payslips {
+			helper.StorePayslipMinorUnits(&payslips[i], decimals(payslips[i].Currency))
+			columns := map[string]interface{}{"minor_unit_scale": *payslips[i].MinorUnitScale}
+			for _, field := range payslips[i].MoneyFields() {
+				columns[field.Column] = *field.Minor
+			}
+			if err := db.Model(&model.Payslip{}).Where("id = ?", payslips[i].ID).UpdateColumns(columns).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to backfill the minor units of payslips: %v", err)
+	}
+	return nil
+}
+
+// DropAttendanceStatusCheck drops the check constraint on the attendance status, AutoMigrate
+// only creates missing constraints and recreates it with the current list of statuses
+func DropAttendanceStatusCheck(db *gorm.DB) error {
+	if !db.Migrator().HasConstraint(&model.Attendance{}, "chk_attendances_status") {
+		return nil
+	}
+	return db.Migrator().DropConstraint(&model.Attendance{}, "chk_attendances_status")
+}
+
+// DropEmployeeRoleCheck drops the check constraint on the employee role, AutoMigrate only creates
+// missing constraints and recreates it with the current list of roles, managers included
+func DropEmployeeRoleCheck(db *gorm.DB) error {
+	if !db.Migrator().HasConstraint(&model.Employee{}, "chk_employees_role") {
+		return nil
+	}
+	return db.Migrator().DropConstraint(&model.Employee{}, "chk_employees_role")
+}
+
+// Ping checks the database answers a query, SELECT 1 through the pool
+func Ping(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("SELECT 1").Error
+}
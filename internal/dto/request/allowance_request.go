@@ -0,0 +1,9 @@
+package request
+
+// AllowanceRequest represents the request payload for creating or updating a fixed allowance of an employee.
+type AllowanceRequest struct {
+	EmployeeID uint    `json:"employee_id"` // Required on create, an allowance cannot move to another employee
+	Name       string  `json:"name" validate:"required,max=100"`
+	Amount     float64 `json:"amount" validate:"min=0"`
+	Active     *bool   `json:"active,omitempty"` // Optional, new allowances are active and updates keep the current state
+}
@@ -0,0 +1,12 @@
+package request
+
+import "time"
+
+// CreateAPIKeyRequest represents the request payload for creating an API key. The key belongs to the caller
+// unless an admin gives another employee_id, and never expires without expires_at.
+type CreateAPIKeyRequest struct {
+	EmployeeID uint       `json:"employee_id"`
+	Name       string     `json:"name" validate:"required,max=100"`
+	Scopes     []string   `json:"scopes" validate:"required,min=1,dive,oneof=read payslips:read attendance:read attendance:write"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
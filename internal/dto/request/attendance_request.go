@@ -6,5 +6,19 @@ type CreateAttendanceRequest struct {
 	Checkin     string `json:"checkin" validate:"required"`  // ISO 8601 format
 	Checkout    string `json:"checkout" validate:"required"` // ISO 8601 format
 	HoursWorked int    `json:"hours_worked" validate:"required"`
-	Status      string `json:"status" validate:"required,oneof=present absent leave"`
+	Status      string `json:"status" validate:"omitempty,oneof=present half_day late absent"` // Check-in status, present when empty
+
+	// Optional location of the check-in, required while geofencing is enabled
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// BackfillAttendanceRequest represents the request body an admin records the attendance of a past day with.
+// The times are taken on the date in the server timezone.
+type BackfillAttendanceRequest struct {
+	EmployeeID uint   `json:"employee_id" validate:"required,gt=0"`
+	Date       string `json:"date" validate:"required,datetime=2006-01-02"`
+	Checkin    string `json:"checkin" validate:"required,datetime=15:04"`
+	Checkout   string `json:"checkout" validate:"required,datetime=15:04"`
+	Status     string `json:"status" validate:"omitempty,oneof=present half_day late absent"` // present when empty
 }
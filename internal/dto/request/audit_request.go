@@ -0,0 +1,14 @@
+package request
+
+import "time"
+
+// AuditLogFilter represents the optional criteria of an audit log listing
+type AuditLogFilter struct {
+	ActorID   *uint
+	Table     string
+	RecordID  *uint
+	Action    string
+	Field     string     // Column the change must touch, e.g. basic_salary
+	StartDate *time.Time // Inclusive
+	EndDate   *time.Time // Inclusive, the whole day
+}
@@ -5,3 +5,19 @@ type LoginRequest struct {
 	Name     string `json:"name" validate:"required,min=2,max=255"`
 	Password string `json:"password" validate:"required,min=6"`
 }
+
+// RefreshTokenRequest represents the refresh and logout request payload
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// ForgotPasswordRequest represents the forgot password request payload, the name is the login name
+type ForgotPasswordRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+// ResetPasswordRequest represents the reset password request payload
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
@@ -1,14 +1,70 @@
 package request
 
+import "time"
+
 type CreateEmployeeRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Password string `json:"password" validate:"required"`
-	Role     string `json:"role" validate:"required,oneof=admin user"`
-	Active   bool   `json:"active" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=admin manager employee"`
+	Active   bool   `json:"active"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email,max=255"` // Where payslips are emailed
+
+	// Optional reporting line
+	ManagerID  *uint  `json:"manager_id,omitempty"`
+	Department string `json:"department,omitempty" validate:"omitempty,max=100"`
+
+	// Optional overrides of the role template defaults
+	StandardHours    *int               `json:"standard_hours,omitempty" validate:"omitempty,min=1,max=24"`
+	OvertimeEligible *bool              `json:"overtime_eligible,omitempty"`
+	Components       []ComponentRequest `json:"components,omitempty"`
+
+	// Optional contract details
+	BasicSalary     float64    `json:"basic_salary" validate:"omitempty,min=0"`
+	PayGradeID      *uint      `json:"pay_grade_id,omitempty"` // The basic salary must stay within the grade's band
+	Currency        string     `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	HireDate        *time.Time `json:"hire_date,omitempty"`
+	TerminationDate *time.Time `json:"termination_date,omitempty"`
 }
 type UpdateEmployeeRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Password string `json:"password" validate:"required"`
-	Role     string `json:"role" validate:"required,oneof=admin user"`
-	Active   bool   `json:"active" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=admin manager employee"`
+
+	// Optional fields, left out they keep the employee's current value
+	Active *bool   `json:"active,omitempty"`
+	Email  *string `json:"email,omitempty" validate:"omitempty,email,max=255"` // Where payslips are emailed
+
+	// Optional reporting line, the manager is removed with PUT /employee/:id/manager
+	ManagerID  *uint   `json:"manager_id,omitempty"`
+	Department *string `json:"department,omitempty" validate:"omitempty,max=100"`
+
+	// Optional contract details
+	BasicSalary     *float64   `json:"basic_salary,omitempty" validate:"omitempty,min=0"`
+	PayGradeID      *uint      `json:"pay_grade_id,omitempty"` // The basic salary must stay within the grade's band
+	Currency        *string    `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	HireDate        *time.Time `json:"hire_date,omitempty"`
+	TerminationDate *time.Time `json:"termination_date,omitempty"`
+
+	// Optional version of the employee the edit was made on, refused with 409 once the employee changed
+	Version *int `json:"version,omitempty"`
+}
+
+// AssignManagerRequest represents the manager an employee reports to, null removing it
+type AssignManagerRequest struct {
+	ManagerID *uint `json:"manager_id"`
+}
+
+// UpdateProfileRequest represents the fields an employee may change on their own record
+type UpdateProfileRequest struct {
+	Name     *string `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Password *string `json:"password,omitempty" validate:"omitempty,min=6"`
+	// Required with a new password, checked against the stored one
+	CurrentPassword *string `json:"current_password,omitempty"`
+}
+
+// EmployeeFilter represents the criteria of an employee listing, empty fields match every employee
+type EmployeeFilter struct {
+	Query  string // Case-insensitive substring of the name
+	Role   string
+	Active *bool
 }
@@ -1,7 +1,31 @@
 package request
 
+import "time"
+
 type CreateOvertimeRequest struct {
 	EmployeeID uint   `json:"employee_id" validate:"required"`
 	Reason     string `json:"reason" validate:"required"`
 	Hours      int    `json:"hours" validate:"required,min=1"`
 }
+
+// UpdateOvertimeRequest corrects a pending overtime, fields left out keep their current value
+type UpdateOvertimeRequest struct {
+	Hours        *int    `json:"hours" validate:"omitempty,min=1,max=3"`
+	Reason       *string `json:"reason"`
+	OvertimeDate *string `json:"overtime_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// OvertimeConsistencyRequest for checking approved overtime against attendance
+type OvertimeConsistencyRequest struct {
+	PeriodStart time.Time `json:"period_start" validate:"required"`
+	PeriodEnd   time.Time `json:"period_end" validate:"required"`
+	EmployeeID  uint      `json:"employee_id"` // Optional, checks every employee when empty
+}
+
+// OvertimeFilter represents the optional criteria of an overtime listing
+type OvertimeFilter struct {
+	EmployeeID *uint
+	Status     string
+	StartDate  string // YYYY-MM-DD, inclusive
+	EndDate    string // YYYY-MM-DD, inclusive
+}
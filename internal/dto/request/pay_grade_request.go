@@ -0,0 +1,9 @@
+package request
+
+// PayGradeRequest represents the request payload for creating or updating a pay grade.
+type PayGradeRequest struct {
+	Name          string  `json:"name" validate:"required,max=100"`
+	MinSalary     float64 `json:"min_salary" validate:"min=0"`
+	MaxSalary     float64 `json:"max_salary" validate:"required,gtefield=MinSalary"`
+	DefaultSalary float64 `json:"default_salary" validate:"required"` // Must be within the band
+}
@@ -5,22 +5,82 @@ import "time"
 // PayrollRequest represents the request to run payroll
 type PayrollRequest struct {
 	PayPeriodStart time.Time `json:"pay_period_start" validate:"required"`
-	PayPeriodEnd   time.Time `json:"pay_period_end" validate:"required"`
-	BasicSalary    float64   `json:"basic_salary" validate:"required,min=0"`
-	OvertimeRate   float64   `json:"overtime_rate" validate:"required,min=0"` // Rate per hour for overtime
+	PayPeriodEnd   time.Time `json:"pay_period_end" validate:"required,not_before=pay_period_start"`
+	BasicSalary    float64   `json:"basic_salary" validate:"min=0"`  // Optional, defaults to the pay grade of each employee
+	OvertimeRate   float64   `json:"overtime_rate" validate:"min=0"` // Rate per hour for overtime, zero pays no overtime
+	// Derive the overtime rate of each employee from their basic salary instead of using OvertimeRate
+	DeriveOvertimeRate bool   `json:"derive_overtime_rate"`
+	Currency           string `json:"currency"`   // Optional ISO 4217 code, defaults to PAYROLL_CURRENCY
+	SendEmail          bool   `json:"send_email"` // Email each processed payslip to its employee after the run
 }
 
 // PayrollEmployeeRequest for processing individual employee payroll
 type PayrollEmployeeRequest struct {
-	EmployeeID     uint      `json:"employee_id" validate:"required"`
+	EmployeeID     uint      `json:"employee_id" validate:"gt=0"`
+	PayPeriodStart time.Time `json:"pay_period_start" validate:"required"`
+	PayPeriodEnd   time.Time `json:"pay_period_end" validate:"required,not_before=pay_period_start"`
+	BasicSalary    float64   `json:"basic_salary" validate:"min=0"` // Optional, defaults to the employee's pay grade
+	OvertimeRate   float64   `json:"overtime_rate" validate:"min=0"`
+	// Derive the overtime rate from the employee's basic salary instead of using OvertimeRate
+	DeriveOvertimeRate bool   `json:"derive_overtime_rate"`
+	Currency           string `json:"currency"`
+}
+
+// ProjectedPayRequest for estimating the pay of an in-progress period
+type ProjectedPayRequest struct {
 	PayPeriodStart time.Time `json:"pay_period_start" validate:"required"`
 	PayPeriodEnd   time.Time `json:"pay_period_end" validate:"required"`
 	BasicSalary    float64   `json:"basic_salary" validate:"required,min=0"`
 	OvertimeRate   float64   `json:"overtime_rate" validate:"required,min=0"`
+	Currency       string    `json:"currency"`
+	IncludePending bool      `json:"include_pending"` // Also estimate the pay as if pending items were approved
 }
 
 // PayrollSummaryRequest for generating payroll summary reports
 type PayrollSummaryRequest struct {
+	PayPeriodStart time.Time `json:"pay_period_start" validate:"required"`
+	PayPeriodEnd   time.Time `json:"pay_period_end" validate:"required,not_before=pay_period_start"`
+}
+
+// PayrollCompareRequest for comparing the payroll summaries of two periods
+type PayrollCompareRequest struct {
+	Previous PayrollSummaryRequest `json:"previous"`
+	Current  PayrollSummaryRequest `json:"current"`
+}
+
+// PayrollForecastRequest for projecting monthly payroll cost
+type PayrollForecastRequest struct {
+	StartMonth time.Time `json:"start_month" validate:"required"`
+	Months     int       `json:"months" validate:"required,min=1,max=36"`
+}
+
+// Sort columns of a payslip listing
+const (
+	PayslipSortEmployeeID  = "employee_id"
+	PayslipSortTotalAmount = "total_amount"
+)
+
+// PayslipFilter represents the criteria and ordering of a payslip listing for a period
+type PayslipFilter struct {
+	PayPeriodStart time.Time
+	PayPeriodEnd   time.Time
+	EmployeeID     *uint
+	Status         string // Empty lists every status except void
+	Sort           string // PayslipSortEmployeeID (default) or PayslipSortTotalAmount
+	Descending     bool
+}
+
+// VoidPayslipRequest for voiding a single payslip so its period can be processed again
+type VoidPayslipRequest struct {
+	Reason  string `json:"reason"`  // Optional
+	Version *int   `json:"version"` // Optional, the payslip version read, refused with 409 once it changed
+}
+
+// BulkVoidPayslipsRequest for voiding all (or selected) payslips of a period
+type BulkVoidPayslipsRequest struct {
 	PayPeriodStart time.Time `json:"pay_period_start" validate:"required"`
 	PayPeriodEnd   time.Time `json:"pay_period_end" validate:"required"`
+	EmployeeIDs    []uint    `json:"employee_ids"` // Optional, voids every employee when empty
+	Reason         string    `json:"reason" validate:"required"`
+	Confirm        bool      `json:"confirm" validate:"required"` // Must be true to guard against accidental use
 }
@@ -1,8 +1,31 @@
 package request
 
+import "time"
+
 // CreateReimbusementRequest represents the request payload for creating a reimbusement.
 type CreateReimbusementRequest struct {
 	EmployeeID  uint    `json:"employee_id" validate:"required"`
-	Amount      float64 `json:"amount" validate:"required,min=0"`
+	Amount      float64 `json:"amount" validate:"required"` // Negative for a clawback, admins only
+	Description string  `json:"description" validate:"required"`
+}
+
+// ApproveReimbursementRequest represents the optional payload of a reimbursement approval,
+// ApprovedAmount approves less than requested
+type ApproveReimbursementRequest struct {
+	ApprovedAmount *float64 `json:"approved_amount"`
+}
+
+// ReimbursementBatchItem represents one reimbursement of a batch submitted by the authenticated employee
+type ReimbursementBatchItem struct {
+	Amount      float64 `json:"amount" validate:"gt=0"`
 	Description string  `json:"description" validate:"required"`
+	Date        string  `json:"date" validate:"required,datetime=2006-01-02"` // Day of the expense in the server timezone
+}
+
+// ReimbursementFilter represents the optional criteria of a reimbursement listing
+type ReimbursementFilter struct {
+	EmployeeID *uint
+	Status     string
+	StartDate  *time.Time // Inclusive
+	EndDate    *time.Time // Inclusive, the whole day
 }
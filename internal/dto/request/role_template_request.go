@@ -0,0 +1,23 @@
+package request
+
+// ComponentRequest represents a recurring allowance or deduction in a request payload.
+type ComponentRequest struct {
+	Name   string  `json:"name" validate:"required"`
+	Kind   string  `json:"kind" validate:"required,oneof=allowance deduction"`
+	Amount float64 `json:"amount" validate:"min=0"`
+}
+
+// CreateRoleTemplateRequest represents the request payload for creating a role template.
+type CreateRoleTemplateRequest struct {
+	Role             string             `json:"role" validate:"required"`
+	StandardHours    int                `json:"standard_hours" validate:"required,min=1,max=24"`
+	OvertimeEligible bool               `json:"overtime_eligible"`
+	Components       []ComponentRequest `json:"components"`
+}
+
+// UpdateRoleTemplateRequest represents the request payload for updating a role template.
+type UpdateRoleTemplateRequest struct {
+	StandardHours    int                `json:"standard_hours" validate:"required,min=1,max=24"`
+	OvertimeEligible bool               `json:"overtime_eligible"`
+	Components       []ComponentRequest `json:"components"`
+}
@@ -0,0 +1,13 @@
+package request
+
+// TaxBracketRequest represents a single income tax bracket in a request payload.
+type TaxBracketRequest struct {
+	Floor   float64 `json:"floor" validate:"min=0"`
+	Ceiling float64 `json:"ceiling" validate:"min=0"` // 0 means no upper bound
+	Rate    float64 `json:"rate" validate:"min=0,max=1"`
+}
+
+// ReplaceTaxBracketsRequest represents the request payload for replacing every tax bracket.
+type ReplaceTaxBracketsRequest struct {
+	Brackets []TaxBracketRequest `json:"brackets" validate:"required"`
+}
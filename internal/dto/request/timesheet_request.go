@@ -0,0 +1,10 @@
+package request
+
+import "time"
+
+// SubmitTimesheetRequest represents the request payload for submitting a timesheet.
+type SubmitTimesheetRequest struct {
+	EmployeeID  uint      `json:"employee_id" validate:"required"`
+	PeriodStart time.Time `json:"period_start" validate:"required"`
+	PeriodEnd   time.Time `json:"period_end" validate:"required"`
+}
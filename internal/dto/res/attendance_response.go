@@ -0,0 +1,125 @@
+package res
+
+import "time"
+
+// Attendance anomaly reasons
+const (
+	AnomalyLateCheckin     = "late_checkin"
+	AnomalyMissingCheckout = "missing_checkout"
+	AnomalyShortDay        = "short_day"
+	AnomalyLongDay         = "long_day"
+)
+
+// AttendanceAnomaly represents an attendance record flagged for review with the reason and the offending value
+type AttendanceAnomaly struct {
+	AttendanceID uint   `json:"attendance_id"`
+	EmployeeID   uint   `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Date         string `json:"date"`
+	Reason       string `json:"reason"`
+	Value        string `json:"value"`
+	Threshold    string `json:"threshold"`
+}
+
+// AttendanceOvertime represents an overtime entry logged for the date of an attendance
+type AttendanceOvertime struct {
+	ID         uint       `json:"id"`
+	Hours      int        `json:"hours"`
+	StartTime  string     `json:"start_time"`
+	EndTime    string     `json:"end_time"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"` // pending, approved or rejected
+	ApprovedBy *uint      `json:"approved_by"`
+	ApprovedAt *time.Time `json:"approved_at"`
+}
+
+// AttendanceWithOvertime represents an attendance record with the overtime logged for its date
+type AttendanceWithOvertime struct {
+	ID          uint                 `json:"id"`
+	EmployeeID  uint                 `json:"employee_id"`
+	Date        string               `json:"date"`
+	Checkin     time.Time            `json:"checkin"`
+	Checkout    *time.Time           `json:"checkout"`
+	HoursWorked int                  `json:"hours_worked"`
+	Status      string               `json:"status"`
+	Open        bool                 `json:"open"` // No checkout recorded
+	Overtimes   []AttendanceOvertime `json:"overtimes"`
+}
+
+// Statuses of an employee in the daily attendance summary
+const (
+	DailyStatusPresent = "present"
+	DailyStatusLate    = "late"
+	DailyStatusAbsent  = "absent"
+)
+
+// DailyAttendanceEntry represents the attendance of one employee on the summary date,
+// the attendance fields are empty for employees without a record
+type DailyAttendanceEntry struct {
+	EmployeeID     uint       `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name"`
+	Status         string     `json:"status"`                    // present, late or absent
+	RecordedStatus string     `json:"recorded_status,omitempty"` // Status stored on the attendance
+	AttendanceID   *uint      `json:"attendance_id"`
+	Checkin        *time.Time `json:"checkin"`
+	Checkout       *time.Time `json:"checkout"`
+	HoursWorked    int        `json:"hours_worked"`
+	Open           bool       `json:"open"` // Checked in without a checkout
+}
+
+// DailyAttendanceSummary represents the attendance of the active employees on a date with the counts per status
+type DailyAttendanceSummary struct {
+	Date      string                 `json:"date"`
+	Total     int                    `json:"total"`
+	Present   int                    `json:"present"`
+	Late      int                    `json:"late"`
+	Absent    int                    `json:"absent"`
+	Employees []DailyAttendanceEntry `json:"employees"`
+}
+
+// AttendanceStats represents the attendance of an employee over a range of days. Weekends and holidays
+// without attendance neither count as absent nor break the present streak, days after today are not counted.
+type AttendanceStats struct {
+	EmployeeID           uint    `json:"employee_id"`
+	StartDate            string  `json:"start_date"`
+	EndDate              string  `json:"end_date"`
+	WorkingDays          int     `json:"working_days"` // Weekdays that are not holidays
+	PresentDays          int     `json:"present_days"` // Present, late and half days
+	AbsentDays           int     `json:"absent_days"`  // Working days recorded absent or without attendance
+	LateDays             int     `json:"late_days"`
+	TotalHoursWorked     int     `json:"total_hours_worked"`
+	LongestPresentStreak int     `json:"longest_present_streak"`
+	LongestStreakStart   *string `json:"longest_streak_start"`
+	LongestStreakEnd     *string `json:"longest_streak_end"`
+}
+
+// Statuses of a row in an attendance import
+const (
+	AttendanceImportRowImported = "imported" // Part of an attendance created by the import
+	AttendanceImportRowSkipped  = "skipped"
+	AttendanceImportRowFailed   = "failed"
+)
+
+// AttendanceImportRow represents the outcome of one CSV row of an attendance import, one event of the device.
+// An unmatched event is reported with a warning rather than failing its row.
+type AttendanceImportRow struct {
+	Row          int        `json:"row"` // Line number in the file, the header is line 1
+	EmployeeID   uint       `json:"employee_id,omitempty"`
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+	Direction    string     `json:"direction,omitempty"`
+	Status       string     `json:"status"`
+	Reason       string     `json:"reason,omitempty"`
+	Warning      string     `json:"warning,omitempty"`
+	AttendanceID uint       `json:"attendance_id,omitempty"` // Attendance created from the event, or the existing one of its day
+}
+
+// AttendanceImportResult represents the per-row outcome of an attendance import with the attendances it created
+type AttendanceImportResult struct {
+	Total              int                   `json:"total"`
+	Imported           int                   `json:"imported"`
+	Skipped            int                   `json:"skipped"`
+	Failed             int                   `json:"failed"`
+	Warnings           int                   `json:"warnings"`
+	AttendancesCreated int                   `json:"attendances_created"`
+	Rows               []AttendanceImportRow `json:"rows"`
+}
@@ -0,0 +1,17 @@
+package res
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLogEntry represents a change recorded in the audit log
+type AuditLogEntry struct {
+	ID        uint            `json:"id"`
+	Table     string          `json:"table_name"`
+	RecordID  uint            `json:"record_id"`
+	Action    string          `json:"action"`
+	ActorID   uint            `json:"actor_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Changes   json.RawMessage `json:"changes"` // Column to {"old", "new"} values
+}
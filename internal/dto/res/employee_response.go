@@ -0,0 +1,63 @@
+package res
+
+// EmployeeProfile represents the record an employee sees of themselves
+type EmployeeProfile struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Role        string  `json:"role"`
+	Active      bool    `json:"active"`
+	BasicSalary float64 `json:"basic_salary"`
+	Currency    string  `json:"currency"`
+}
+
+// ManagedEmployee represents an employee in a manager's reporting subtree
+type ManagedEmployee struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Active    bool   `json:"active"`
+	ManagerID *uint  `json:"manager_id"`
+	Depth     int    `json:"depth"` // 0 for the manager, 1 for direct reports
+}
+
+// ManagedEmployeesResponse represents the full reporting subtree of a manager
+type ManagedEmployeesResponse struct {
+	ManagerID uint              `json:"manager_id"`
+	Count     int               `json:"count"`
+	Employees []ManagedEmployee `json:"employees"`
+}
+
+// UnassignedDepartment groups employees without a department
+const UnassignedDepartment = "unassigned"
+
+// DepartmentHeadcount represents a department with its number of active employees
+type DepartmentHeadcount struct {
+	Department string `json:"department"`
+	Headcount  int    `json:"headcount"`
+}
+
+// Statuses of a row in an employee import
+const (
+	ImportRowCreated = "created"
+	ImportRowValid   = "valid" // Passed validation in a dry run
+	ImportRowFailed  = "failed"
+)
+
+// EmployeeImportRow represents the outcome of one CSV row of an employee import
+type EmployeeImportRow struct {
+	Row        int    `json:"row"` // Line number in the file, the header is line 1
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Reason     string `json:"reason,omitempty"`
+	EmployeeID uint   `json:"employee_id,omitempty"`
+	Password   string `json:"password,omitempty"` // Generated password, only returned once on creation
+}
+
+// EmployeeImportResult represents the per-row outcome of an employee import
+type EmployeeImportResult struct {
+	DryRun    bool                `json:"dry_run"`
+	Total     int                 `json:"total"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Rows      []EmployeeImportRow `json:"rows"`
+}
@@ -0,0 +1,9 @@
+package res
+
+// BuildInfo represents the build the server runs, the git fields are empty outside a git checkout
+type BuildInfo struct {
+	GitBranch  string `json:"git_branch"`
+	GitHash    string `json:"git_hash"`
+	GitUpdated string `json:"git_updated"`
+	Hostname   string `json:"hostname"`
+}
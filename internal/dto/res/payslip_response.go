@@ -1,6 +1,10 @@
 package res
 
-import "time"
+import (
+	"time"
+
+	"github.com/yourname/payslip-system/internal/model"
+)
 
 // AttendanceDetail represents attendance breakdown in payslip
 type AttendanceDetail struct {
@@ -9,48 +13,125 @@ type AttendanceDetail struct {
 	CheckOut    string `json:"check_out"`
 	HoursWorked int    `json:"hours_worked"`
 	Status      string `json:"status"`
+	Open        bool   `json:"open"` // No checkout recorded
+}
+
+// PayslipAttendanceDetail represents an attendance day in the breakdown of a detailed payslip
+type PayslipAttendanceDetail struct {
+	Date        time.Time  `json:"date"`
+	CheckIn     time.Time  `json:"check_in"`
+	CheckOut    *time.Time `json:"check_out"`
+	HoursWorked int        `json:"hours_worked"`
+	Status      string     `json:"status"`
+	Open        bool       `json:"open"`   // No checkout recorded
+	Weight      float64    `json:"weight"` // Share of a working day paid
 }
 
 // OvertimeDetail represents overtime breakdown in payslip
 type OvertimeDetail struct {
-	Date   string  `json:"date"`
-	Hours  int     `json:"hours"`
-	Rate   float64 `json:"rate"`
-	Amount float64 `json:"amount"`
-	Reason string  `json:"reason"`
+	Date        string  `json:"date"`
+	Hours       int     `json:"hours"`
+	PaidHours   float64 `json:"paid_hours"`
+	UnpaidHours float64 `json:"unpaid_hours"`
+	Rate        float64 `json:"rate"`
+	Multiplier  float64 `json:"multiplier"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason"`
 }
 
 // ReimbursementDetail represents reimbursement breakdown in payslip
 type ReimbursementDetail struct {
-	Date   string  `json:"date"`
+	Type            string    `json:"type"` // reimbursement, or clawback for a negative amount
+	Date            time.Time `json:"date"`
+	Amount          float64   `json:"amount"` // Amount paid
+	RequestedAmount float64   `json:"requested_amount"`
+	ApprovedAmount  *float64  `json:"approved_amount"` // Null unless approved for less than requested
+	Reason          string    `json:"reason"`
+	Status          string    `json:"status"`
+}
+
+// PayslipDeductionLine represents one total subtracted in the deduction breakdown of a detailed payslip
+type PayslipDeductionLine struct {
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
+}
+
+// PayslipAllowanceLine represents a fixed allowance paid on a payslip
+type PayslipAllowanceLine struct {
+	Name   string  `json:"name"`
 	Amount float64 `json:"amount"`
-	Reason string  `json:"reason"`
-	Status string  `json:"status"`
+}
+
+// PayslipSummaryDisplay represents the amounts of a payslip summary formatted in its currency
+type PayslipSummaryDisplay struct {
+	BasicSalary         string `json:"basic_salary"`
+	DailyRate           string `json:"daily_rate"`
+	OvertimeAmount      string `json:"overtime_amount"`
+	ReimbursementAmount string `json:"reimbursement_amount"`
+	AllowanceAmount     string `json:"allowance_amount"`
+	DeductionAmount     string `json:"deduction_amount"`
+	AbsenceDeduction    string `json:"absence_deduction"`
+	LatePenaltyAmount   string `json:"late_penalty_amount"`
+	GrossAmount         string `json:"gross_amount"`
+	TaxAmount           string `json:"tax_amount"`
+	NetAmount           string `json:"net_amount"`
+	BroughtForward      string `json:"brought_forward"`
+	CarriedForward      string `json:"carried_forward"`
+	TotalTakeHomePay    string `json:"total_take_home_pay"`
 }
 
 // PayslipSummary represents the salary calculation summary
 type PayslipSummary struct {
-	BasicSalary         float64 `json:"basic_salary"`
-	TotalAttendanceDays int     `json:"total_attendance_days"`
-	TotalOvertimeHours  int     `json:"total_overtime_hours"`
-	OvertimeAmount      float64 `json:"overtime_amount"`
-	ReimbursementAmount float64 `json:"reimbursement_amount"`
-	TotalTakeHomePay    float64 `json:"total_take_home_pay"`
+	BasicSalary         float64               `json:"basic_salary"`
+	DailyRate           float64               `json:"daily_rate"`
+	ProratedDays        int                   `json:"prorated_days"`
+	ProrationFactor     float64               `json:"proration_factor"`
+	TotalAttendanceDays float64               `json:"total_attendance_days"`
+	OpenAttendanceDays  int                   `json:"open_attendance_days"`
+	TotalOvertimeHours  int                   `json:"total_overtime_hours"`
+	OvertimeRate        float64               `json:"overtime_rate"`
+	OvertimeAmount      float64               `json:"overtime_amount"`
+	ReimbursementAmount float64               `json:"reimbursement_amount"`
+	AllowanceAmount     float64               `json:"allowance_amount"`
+	DeductionAmount     float64               `json:"deduction_amount"`
+	AbsenceDeduction    float64               `json:"absence_deduction"`
+	LateCount           int                   `json:"late_count"`
+	LatePenaltyAmount   float64               `json:"late_penalty_amount"`
+	GrossAmount         float64               `json:"gross_amount"`
+	TaxAmount           float64               `json:"tax_amount"`
+	NetAmount           float64               `json:"net_amount"`
+	BroughtForward      float64               `json:"brought_forward"`
+	CarriedForward      float64               `json:"carried_forward"`
+	TotalTakeHomePay    float64               `json:"total_take_home_pay"`
+	Currency            string                `json:"currency"`
+	Display             PayslipSummaryDisplay `json:"display"`
 }
 
-// DetailedPayslipResponse represents the complete payslip with breakdowns
+// DetailedPayslipResponse represents the complete payslip with breakdowns. The breakdowns are null without records.
 type DetailedPayslipResponse struct {
-	PayslipID              uint                  `json:"payslip_id"`
-	EmployeeID             uint                  `json:"employee_id"`
-	EmployeeName           string                `json:"employee_name"`
-	PayPeriodStart         time.Time             `json:"pay_period_start"`
-	PayPeriodEnd           time.Time             `json:"pay_period_end"`
-	ProcessedAt            time.Time             `json:"processed_at"`
-	Status                 string                `json:"status"`
-	Summary                PayslipSummary        `json:"summary"`
-	AttendanceBreakdown    []AttendanceDetail    `json:"attendance_breakdown"`
-	OvertimeBreakdown      []OvertimeDetail      `json:"overtime_breakdown"`
-	ReimbursementBreakdown []ReimbursementDetail `json:"reimbursement_breakdown"`
+	PayslipID              uint                      `json:"payslip_id"`
+	EmployeeID             uint                      `json:"employee_id"`
+	EmployeeName           string                    `json:"employee_name"`
+	PayPeriodStart         time.Time                 `json:"pay_period_start"`
+	PayPeriodEnd           time.Time                 `json:"pay_period_end"`
+	ProcessedAt            time.Time                 `json:"processed_at"`
+	Status                 string                    `json:"status"`
+	Currency               string                    `json:"currency"`
+	Summary                PayslipSummary            `json:"summary"`
+	AttendanceBreakdown    []PayslipAttendanceDetail `json:"attendance_breakdown"`
+	OvertimeBreakdown      []OvertimeDetail          `json:"overtime_breakdown"`
+	ReimbursementBreakdown []ReimbursementDetail     `json:"reimbursement_breakdown"`
+	DeductionBreakdown     []PayslipDeductionLine    `json:"deduction_breakdown"`
+	Allowances             []PayslipAllowanceLine    `json:"allowances"` // Set from the allowance components by the caller
+}
+
+// PayrollPreviewResponse represents a dry run of an employee's payroll, the detailed payslip it would store
+// along with the warnings a run would report
+type PayrollPreviewResponse struct {
+	DetailedPayslipResponse
+	Preview              bool                        `json:"preview"`
+	Warnings             []string                    `json:"warnings"`
+	MinimumWageShortfall *model.MinimumWageShortfall `json:"minimum_wage_shortfall,omitempty"`
 }
 
 // PayslipListResponse represents a list of payslips for an employee
@@ -62,3 +143,213 @@ type PayslipListResponse struct {
 	Status         string    `json:"status"`
 	ProcessedAt    time.Time `json:"processed_at"`
 }
+
+// BulkVoidFailure describes a payslip that could not be voided
+type BulkVoidFailure struct {
+	PayslipID  uint   `json:"payslip_id"`
+	EmployeeID uint   `json:"employee_id"`
+	Reason     string `json:"reason"`
+}
+
+// BulkVoidResult represents the outcome of voiding payslips for a period
+type BulkVoidResult struct {
+	VoidedCount  int               `json:"voided_count"`
+	SkippedCount int               `json:"skipped_count"`
+	FailedCount  int               `json:"failed_count"`
+	VoidedIDs    []uint            `json:"voided_ids"`
+	Failures     []BulkVoidFailure `json:"failures,omitempty"`
+}
+
+// PayrollForecastMonth represents the projected headcount and cost of a single month
+type PayrollForecastMonth struct {
+	Month           string  `json:"month"`
+	Headcount       int     `json:"headcount"`
+	BasicSalary     float64 `json:"basic_salary"`
+	AllowanceAmount float64 `json:"allowance_amount"`
+	DeductionAmount float64 `json:"deduction_amount"`
+	TotalCost       float64 `json:"total_cost"`
+}
+
+// PayrollCurrencyForecast represents the projected cost of the employees paid in one currency
+type PayrollCurrencyForecast struct {
+	Currency  string                 `json:"currency"`
+	Forecast  []PayrollForecastMonth `json:"forecast"`
+	TotalCost float64                `json:"total_cost"`
+}
+
+// PayrollForecastResponse represents the projected payroll cost over a horizon, per currency
+type PayrollForecastResponse struct {
+	StartMonth string                    `json:"start_month"`
+	Months     int                       `json:"months"`
+	Currencies []PayrollCurrencyForecast `json:"currencies"`
+}
+
+// ProjectedPayFigures represents the estimated amounts of a period
+type ProjectedPayFigures struct {
+	PaidOvertimeHours   float64 `json:"paid_overtime_hours"`
+	OvertimeAmount      float64 `json:"overtime_amount"`
+	ReimbursementAmount float64 `json:"reimbursement_amount"`
+	GrossAmount         float64 `json:"gross_amount"`
+	TaxAmount           float64 `json:"tax_amount"`
+	NetAmount           float64 `json:"net_amount"`
+}
+
+// PendingProjectionItems represents the overtime and reimbursements of a period still awaiting approval
+type PendingProjectionItems struct {
+	OvertimeCount       int     `json:"overtime_count"`
+	OvertimeHours       int     `json:"overtime_hours"`
+	ReimbursementCount  int     `json:"reimbursement_count"`
+	ReimbursementAmount float64 `json:"reimbursement_amount"`
+}
+
+// ProjectedPayResponse represents the pay estimate of an in-progress period.
+// ApprovedOnly matches what payroll would pay, IfApproved is a best-case estimate that also counts the pending items.
+type ProjectedPayResponse struct {
+	EmployeeID     uint                    `json:"employee_id"`
+	PayPeriodStart time.Time               `json:"pay_period_start"`
+	PayPeriodEnd   time.Time               `json:"pay_period_end"`
+	Currency       string                  `json:"currency"`
+	ApprovedOnly   ProjectedPayFigures     `json:"approved_only"`
+	IncludePending bool                    `json:"include_pending"`
+	Pending        *PendingProjectionItems `json:"pending,omitempty"`
+	IfApproved     *ProjectedPayFigures    `json:"if_approved,omitempty"`
+	Note           string                  `json:"note,omitempty"`
+}
+
+// Deduction types of a payslip
+const (
+	DeductionIncomeTax      = "income_tax"
+	DeductionRecurring      = "recurring_deduction"
+	DeductionBroughtForward = "brought_forward"
+	DeductionCarriedForward = "carried_forward"
+	DeductionRecurringTotal = "recurring_deductions"
+	DeductionAbsence        = "absence"
+	DeductionLatePenalty    = "late_penalty"
+)
+
+// DeductionItem represents a single amount subtracted from the gross pay and how it was computed
+type DeductionItem struct {
+	Type   string  `json:"type"`
+	Name   string  `json:"name,omitempty"`
+	Amount float64 `json:"amount"` // Negative for a deduction deferred to the next payslip
+	Basis  string  `json:"basis"`
+}
+
+// DeductionBreakdown represents every deduction of a payslip, summing to the gross minus the net pay
+type DeductionBreakdown struct {
+	PayslipID     uint            `json:"payslip_id"`
+	EmployeeID    uint            `json:"employee_id"`
+	Currency      string          `json:"currency"`
+	GrossAmount   float64         `json:"gross_amount"`
+	NetAmount     float64         `json:"net_amount"`
+	TotalDeducted float64         `json:"total_deducted"`
+	Deductions    []DeductionItem `json:"deductions"`
+}
+
+// ApprovalChainItem represents an approved overtime entry or reimbursement included in a payslip
+type ApprovalChainItem struct {
+	Type         string     `json:"type"`
+	ItemID       uint       `json:"item_id"`
+	Date         string     `json:"date"`
+	Hours        int        `json:"hours,omitempty"`
+	Amount       float64    `json:"amount,omitempty"`
+	Reason       string     `json:"reason"`
+	ApprovedBy   *uint      `json:"approved_by"`
+	ApproverName string     `json:"approver_name"`
+	ApprovedAt   *time.Time `json:"approved_at"`
+}
+
+// PayslipApprovalChain represents every approval that fed a payslip
+type PayslipApprovalChain struct {
+	PayslipID      uint                `json:"payslip_id"`
+	EmployeeID     uint                `json:"employee_id"`
+	PayPeriodStart time.Time           `json:"pay_period_start"`
+	PayPeriodEnd   time.Time           `json:"pay_period_end"`
+	ApprovalCount  int                 `json:"approval_count"`
+	Overtimes      []ApprovalChainItem `json:"overtimes"`
+	Reimbursements []ApprovalChainItem `json:"reimbursements"`
+}
+
+// TaxSummaryMonth represents the payslip figures of one month of a tax summary
+type TaxSummaryMonth struct {
+	Month        string  `json:"month"`
+	PayslipCount int     `json:"payslip_count"`
+	GrossAmount  float64 `json:"gross_amount"`
+	TaxWithheld  float64 `json:"tax_withheld"`
+	NetAmount    float64 `json:"net_amount"`
+}
+
+// TaxSummary represents the year-end tax statement of an employee, with a row for every month of the year
+type TaxSummary struct {
+	EmployeeID       uint              `json:"employee_id"`
+	EmployeeName     string            `json:"employee_name"`
+	Year             int               `json:"year"`
+	Currency         string            `json:"currency"`
+	PayslipCount     int               `json:"payslip_count"`
+	TotalGross       float64           `json:"total_gross"`
+	TotalTaxWithheld float64           `json:"total_tax_withheld"`
+	TotalNet         float64           `json:"total_net"`
+	Months           []TaxSummaryMonth `json:"months"`
+}
+
+// PayslipExportSchemaVersion is the version of the payslip JSON export, bumped on every breaking change
+// to its layout so downstream parsers can tell exports apart
+const PayslipExportSchemaVersion = 1
+
+// PayslipExport represents the downloadable JSON file of a detailed payslip
+type PayslipExport struct {
+	SchemaVersion int                     `json:"schema_version"`
+	ExportedAt    time.Time               `json:"exported_at"`
+	Payslip       DetailedPayslipResponse `json:"payslip"` // Same payload as the detailed payslip endpoint
+}
+
+// YTDEarnings represents the year-to-date totals of an employee's non-void payslips for a calendar year
+type YTDEarnings struct {
+	EmployeeID   uint   `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Year         int    `json:"year"`
+	PayslipTotals
+}
+
+// EmployeePayslipSummary represents the totals of an employee's non-void payslips whose pay period starts
+// between two dates, both included
+type EmployeePayslipSummary struct {
+	EmployeeID   uint   `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	PayslipTotals
+}
+
+// PayslipTotals represents the summed amounts of a set of payslips, all in one currency
+type PayslipTotals struct {
+	Currency            string  `json:"currency"`
+	PayslipCount        int     `json:"payslip_count"`
+	GrossAmount         float64 `json:"gross_amount"`
+	TaxAmount           float64 `json:"tax_amount"`
+	NetAmount           float64 `json:"net_amount"`
+	BasicSalary         float64 `json:"basic_salary"`
+	OvertimeAmount      float64 `json:"overtime_amount"`
+	OvertimeHours       int     `json:"overtime_hours"`
+	ReimbursementAmount float64 `json:"reimbursement_amount"`
+	AttendanceDays      float64 `json:"attendance_days"`
+}
+
+// PayslipEmailResult is the outcome of emailing one payslip of a payroll run
+type PayslipEmailResult struct {
+	EmployeeID uint   `json:"employee_id"`
+	PayslipID  uint   `json:"payslip_id"`
+	Status     string `json:"status"` // sent, skipped, failed
+	Reason     string `json:"reason,omitempty"`
+}
+
+// PayrollCompletedEvent represents the summary of a payroll run sent to webhooks once it finished
+type PayrollCompletedEvent struct {
+	RunID          *uint              `json:"run_id"` // Null when storing the run failed
+	PayPeriodStart string             `json:"pay_period_start"`
+	PayPeriodEnd   string             `json:"pay_period_end"`
+	ProcessedCount int                `json:"processed_count"`
+	ErrorCount     int                `json:"error_count"`
+	NetTotals      map[string]float64 `json:"net_totals"` // Net pay of the processed payslips by currency
+	Errors         []string           `json:"errors"`
+}
@@ -0,0 +1,49 @@
+package res
+
+import "time"
+
+// ReimbursementResponse represents a reimbursement with the URL its receipt can be downloaded from
+type ReimbursementResponse struct {
+	ID                 uint       `json:"id"`
+	EmployeeID         uint       `json:"employee_id"`
+	ReimbursementDate  time.Time  `json:"reimbursement_date"`
+	Amount             float64    `json:"amount"`
+	Clawback           bool       `json:"clawback"` // Negative amount recovering an overpayment
+	ApprovedAmount     *float64   `json:"approved_amount"`
+	Category           string     `json:"category"`
+	Reason             string     `json:"reason"`
+	Status             string     `json:"status"`
+	ApprovedBy         *uint      `json:"approved_by"`
+	ApprovedAt         *time.Time `json:"approved_at"`
+	ReceiptURL         string     `json:"receipt_url,omitempty"`
+	ReceiptContentType string     `json:"receipt_content_type,omitempty"`
+}
+
+// CreatedReimbursementResponse represents a created reimbursement, with a warning when it looks like a duplicate
+type CreatedReimbursementResponse struct {
+	Reimbursement        ReimbursementResponse `json:"reimbursement"`
+	Warning              string                `json:"warning,omitempty"`
+	PotentialDuplicateID uint                  `json:"potential_duplicate_id,omitempty"`
+}
+
+// Statuses of an item in a reimbursement batch
+const (
+	BatchItemCreated = "created"
+	BatchItemFailed  = "failed"
+)
+
+// ReimbursementBatchItem represents the outcome of one reimbursement of a batch
+type ReimbursementBatchItem struct {
+	Index           int    `json:"index"` // Position in the submitted array, from 0
+	Status          string `json:"status"`
+	Reason          string `json:"reason,omitempty"`
+	ReimbursementID uint   `json:"reimbursement_id,omitempty"`
+}
+
+// ReimbursementBatchResult represents the per-item outcome of a reimbursement batch
+type ReimbursementBatchResult struct {
+	Total     int                      `json:"total"`
+	Succeeded int                      `json:"succeeded"`
+	Failed    int                      `json:"failed"`
+	Items     []ReimbursementBatchItem `json:"items"`
+}
@@ -0,0 +1,101 @@
+package res
+
+import "time"
+
+// OvertimeInconsistency represents an approved overtime entry without a present attendance that day
+type OvertimeInconsistency struct {
+	OvertimeID   uint   `json:"overtime_id"`
+	EmployeeID   uint   `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	OvertimeDate string `json:"overtime_date"`
+	Hours        int    `json:"hours"`
+	Reason       string `json:"reason"`
+}
+
+// OvertimeConsistencyReport represents the overtime vs. attendance check for a period
+type OvertimeConsistencyReport struct {
+	PeriodStart  time.Time               `json:"period_start"`
+	PeriodEnd    time.Time               `json:"period_end"`
+	CheckedCount int                     `json:"checked_count"`
+	FlaggedCount int                     `json:"flagged_count"`
+	Flagged      []OvertimeInconsistency `json:"flagged"`
+}
+
+// PayrollTotals represents aggregated payslip figures of a period in one currency
+type PayrollTotals struct {
+	Currency       string  `json:"currency"`
+	PayslipCount   int     `json:"payslip_count"`
+	Headcount      int     `json:"headcount"`
+	TotalAmount    float64 `json:"total_amount"`
+	OvertimeAmount float64 `json:"overtime_amount"`
+}
+
+// PayPeriodSummary represents a processed pay period with its payslip count and total payout,
+// void payslips left out
+type PayPeriodSummary struct {
+	PayPeriodStart time.Time `json:"pay_period_start"`
+	PayPeriodEnd   time.Time `json:"pay_period_end"`
+	Currency       string    `json:"currency"` // The payout is the total of the period's payslips in this currency
+	PayslipCount   int       `json:"payslip_count"`
+	TotalPayout    float64   `json:"total_payout"`
+}
+
+// PayrollRunMetric represents the processing statistics of one stored payroll run
+type PayrollRunMetric struct {
+	RunID              uint       `json:"run_id"`
+	PayPeriodStart     time.Time  `json:"pay_period_start"`
+	PayPeriodEnd       time.Time  `json:"pay_period_end"`
+	EmployeesProcessed int        `json:"employees_processed"`
+	SucceededCount     int        `json:"succeeded_count"`
+	ErrorCount         int        `json:"error_count"`
+	DurationMs         int64      `json:"duration_ms"`
+	StartedAt          *time.Time `json:"started_at"`
+	FinishedAt         *time.Time `json:"finished_at"`
+	CreatedAt          *time.Time `json:"created_at"`
+}
+
+// PayrollKPIs represents the executive dashboard figures of a month, per currency paid in the month or the one before
+type PayrollKPIs struct {
+	Month      string                `json:"month"`
+	Currencies []PayrollCurrencyKPIs `json:"currencies"`
+}
+
+// PayrollCurrencyKPIs represents the dashboard figures of the payslips in one currency.
+// MonthOverMonthChange is the percentage change of the total cost, null when there is no prior month.
+type PayrollCurrencyKPIs struct {
+	Currency             string   `json:"currency"`
+	TotalCost            float64  `json:"total_cost"`
+	Headcount            int      `json:"headcount"`
+	AverageNet           float64  `json:"average_net"`
+	OvertimePercent      float64  `json:"overtime_percent"`
+	PreviousTotalCost    float64  `json:"previous_total_cost"`
+	MonthOverMonthChange *float64 `json:"month_over_month_change"`
+}
+
+// PayrollComparisonMetric represents one figure of two compared periods.
+// PercentChange is the change relative to the previous period, null when the previous figure is zero.
+type PayrollComparisonMetric struct {
+	Previous      float64  `json:"previous"`
+	Current       float64  `json:"current"`
+	Delta         float64  `json:"delta"`
+	PercentChange *float64 `json:"percent_change"`
+}
+
+// PayrollCurrencyComparison represents the compared payroll totals of one currency
+type PayrollCurrencyComparison struct {
+	Currency       string                  `json:"currency"`
+	Headcount      PayrollComparisonMetric `json:"headcount"`
+	TotalPayout    PayrollComparisonMetric `json:"total_payout"`
+	AveragePayout  PayrollComparisonMetric `json:"average_payout"`
+	OvertimeAmount PayrollComparisonMetric `json:"overtime_amount"`
+	OvertimeHours  PayrollComparisonMetric `json:"overtime_hours"`
+}
+
+// PayrollComparison represents the payroll totals of two periods side by side, per currency
+type PayrollComparison struct {
+	PreviousPeriodStart time.Time                   `json:"previous_period_start"`
+	PreviousPeriodEnd   time.Time                   `json:"previous_period_end"`
+	CurrentPeriodStart  time.Time                   `json:"current_period_start"`
+	CurrentPeriodEnd    time.Time                   `json:"current_period_end"`
+	Currencies          []PayrollCurrencyComparison `json:"currencies"`
+}
@@ -0,0 +1,26 @@
+package res
+
+import "time"
+
+// TimesheetOvertime represents an approved overtime entry in a timesheet
+type TimesheetOvertime struct {
+	Date   string `json:"date"`
+	Hours  int    `json:"hours"`
+	Reason string `json:"reason"`
+}
+
+// TimesheetResponse represents an employee's attendance and overtime for a period.
+// Status is "open" until the timesheet is submitted.
+type TimesheetResponse struct {
+	TimesheetID    uint                `json:"timesheet_id,omitempty"`
+	EmployeeID     uint                `json:"employee_id"`
+	EmployeeName   string              `json:"employee_name"`
+	PeriodStart    time.Time           `json:"period_start"`
+	PeriodEnd      time.Time           `json:"period_end"`
+	Status         string              `json:"status"`
+	AttendanceDays int                 `json:"attendance_days"`
+	HoursWorked    int                 `json:"hours_worked"`
+	OvertimeHours  int                 `json:"overtime_hours"`
+	Attendances    []AttendanceDetail  `json:"attendances"`
+	Overtimes      []TimesheetOvertime `json:"overtimes"`
+}
@@ -4,10 +4,12 @@ import "time"
 
 // LoginResponse represents the login response payload
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	TokenType string    `json:"token_type"`
-	ExpiresAt time.Time `json:"expires_at"`
-	User      UserInfo  `json:"user"`
+	Token                 string    `json:"token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	User                  UserInfo  `json:"user"`
 }
 
 // UserInfo represents user information in the token response
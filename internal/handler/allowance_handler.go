@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
+)
+
+// AllowanceHandler handles the fixed allowances (transport, housing, ...) of employees. Unlike
+// reimbursements they need no approval, payroll pays every allowance active when it runs.
+type AllowanceHandler struct {
+	Helper   helper.NewHelper
+	DB       *gorm.DB
+	Response response.Interface
+
+	AllowanceRepo repository.AllowanceRepository
+}
+
+// CreateAllowance creates a fixed allowance for an employee
+func (h *AllowanceHandler) CreateAllowance(c echo.Context) error {
+	req := request.AllowanceRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+	if err := c.Validate(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			return h.Response.SendValidationError(c, validationErrors)
+		}
+		return h.Response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.AllowanceRepo.GetDB())
+
+	allowance, err := h.AllowanceRepo.CreateAllowanceWithAudit(req, auditDB)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.Response.SendNotFound(c, "Employee not found", err.Error())
+	}
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to create allowance", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Allowance created successfully", allowance)
+}
+
+// GetAllowances lists the active and inactive allowances of the employee given by employee_id
+func (h *AllowanceHandler) GetAllowances(c echo.Context) error {
+	employeeID, err := strconv.ParseUint(c.QueryParam("employee_id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid employee_id", err.Error())
+	}
+
+	allowances, err := h.AllowanceRepo.GetAllowancesByEmployee(uint(employeeID))
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve allowances", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Allowances retrieved successfully", allowances)
+}
+
+// GetAllowance retrieves an allowance
+func (h *AllowanceHandler) GetAllowance(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid allowance ID", err.Error())
+	}
+
+	allowance, err := h.AllowanceRepo.GetAllowanceByID(uint(id))
+	if err != nil {
+		return h.Response.SendNotFound(c, "Allowance not found", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Allowance retrieved successfully", allowance)
+}
+
+// EditAllowance updates an allowance, or (de)activates it with active. Processed payslips are not changed.
+func (h *AllowanceHandler) EditAllowance(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid allowance ID", err.Error())
+	}
+
+	req := request.AllowanceRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+	if err := c.Validate(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			return h.Response.SendValidationError(c, validationErrors)
+		}
+		return h.Response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.AllowanceRepo.GetDB())
+
+	allowance, err := h.AllowanceRepo.UpdateAllowanceWithAudit(uint(id), req, auditDB)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.Response.SendNotFound(c, "Allowance not found", err.Error())
+	}
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to update allowance", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Allowance updated successfully", allowance)
+}
+
+// DeleteAllowance deletes an allowance. Processed payslips are not changed.
+func (h *AllowanceHandler) DeleteAllowance(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid allowance ID", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.AllowanceRepo.GetDB())
+
+	err = h.AllowanceRepo.DeleteAllowanceWithAudit(uint(id), auditDB)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.Response.SendNotFound(c, "Allowance not found", err.Error())
+	}
+	if err != nil {
+		return h.Response.SendError(c, "Failed to delete allowance", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Allowance deleted successfully", nil)
+}
@@ -0,0 +1,93 @@
+// Package handler contains unit tests for the allowance handler functionality.
+//
+// CreateAllowance and EditAllowance tests cover (real handler on an in-memory database):
+// 1. An allowance created and updated for an employee
+// 2. Missing names and negative amounts rejected with 400 per field, nothing stored
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupAllowanceHandler stores active employee 1
+func setupAllowanceHandler(t *testing.T) (*gorm.DB, *AllowanceHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.PayGrade{}, &model.Employee{}, &model.EmployeeComponent{}, &model.AuditLog{}))
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+
+	return db, &AllowanceHandler{
+		Response:      response.NewResponse(),
+		AllowanceRepo: repository.NewAllowanceRepository(db),
+	}
+}
+
+// allowanceRequest runs the handler with the body as admin 9, with the id param when given
+func allowanceRequest(t *testing.T, run func(echo.Context) error, method, body, id string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(method, "/allowance", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	c.Set("authenticated_user_id", uint(9))
+	if id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+	require.NoError(t, run(c))
+	return rec
+}
+
+func TestAllowanceHandler_CreateAndEditAllowance(t *testing.T) {
+	db, h := setupAllowanceHandler(t)
+
+	rec := allowanceRequest(t, h.CreateAllowance, http.MethodPost, `{"employee_id":1,"name":"Transport","amount":500000}`, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var stored model.EmployeeComponent
+	require.NoError(t, db.First(&stored).Error)
+	assert.Equal(t, "Transport", stored.Name)
+
+	rec = allowanceRequest(t, h.EditAllowance, http.MethodPut, `{"name":"Transport","amount":750000}`, "1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, db.First(&stored, stored.ID).Error)
+	assert.Equal(t, 750000.0, stored.Amount)
+}
+
+func TestAllowanceHandler_ValidationFailed(t *testing.T) {
+	db, h := setupAllowanceHandler(t)
+
+	for _, body := range []string{
+		`{"employee_id":1,"amount":500000}`,
+		`{"employee_id":1,"name":"Transport","amount":-1}`,
+	} {
+		rec := allowanceRequest(t, h.CreateAllowance, http.MethodPost, body, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		assert.Contains(t, rec.Body.String(), `"error_code":"`+response.ErrCodeValidationFailed+`"`, body)
+	}
+
+	var count int64
+	require.NoError(t, db.Model(&model.EmployeeComponent{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	require.NoError(t, db.Create(&model.EmployeeComponent{EmployeeID: 1, Name: "Transport", Kind: model.ComponentAllowance, Amount: 500000, Active: true}).Error)
+	rec := allowanceRequest(t, h.EditAllowance, http.MethodPut, `{"name":"","amount":500000}`, "1")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"name is required"`)
+}
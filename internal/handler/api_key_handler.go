@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefixLength is how many leading characters of a key are stored in clear to tell keys apart
+const apiKeyPrefixLength = 12
+
+// APIKeyHandler handles the API keys integrations authenticate with instead of logging in.
+// Employees manage their own keys, admins anyone's.
+type APIKeyHandler struct {
+	Helper   helper.NewHelper
+	Response response.Interface
+
+	APIKeyRepo   repository.APIKeyRepository
+	EmployeeRepo repository.EmployeeRepository
+}
+
+// CreateAPIKey creates a scoped API key for the caller, or for the employee_id given by an admin.
+// The key is returned once, only its hash is stored.
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	req := request.CreateAPIKeyRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if err := c.Validate(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			return h.Response.SendValidationError(c, validationErrors)
+		}
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeValidationFailed, "Validation failed", err.Error())
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeValidationFailed, "expires_at must be in the future", nil)
+	}
+
+	if req.EmployeeID == 0 {
+		req.EmployeeID, _ = c.Get("authenticated_user_id").(uint)
+	}
+	if !helper.ValidateEmployeeAccess(c, req.EmployeeID) {
+		return h.Response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only create API keys for yourself.", nil)
+	}
+	employee, err := h.EmployeeRepo.GetEmployeeByID(req.EmployeeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.Response.SendNotFoundWithCode(c, response.ErrCodeEmployeeNotFound, "Employee not found", err.Error())
+	}
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve employee", err.Error())
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to generate API key", err.Error())
+	}
+	secret := "psk_" + hex.EncodeToString(raw)
+
+	// Scopes are stored once each, in the order given
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	createdBy, _ := c.Get("authenticated_user_id").(uint)
+	key, err := h.APIKeyRepo.CreateAPIKey(&model.APIKey{
+		DefaultAttribute: model.DefaultAttribute{CreatedBy: &createdBy},
+		EmployeeID:       employee.ID,
+		Name:             strings.TrimSpace(req.Name),
+		Prefix:           secret[:apiKeyPrefixLength],
+		KeyHash:          middleware.HashAPIKey(secret),
+		Scopes:           strings.Join(scopes, ","),
+		ExpiresAt:        req.ExpiresAt,
+	})
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to create API key", err.Error())
+	}
+	middleware.LoggerFrom(c).Info("api key created", "api_key_id", key.ID, "employee_id", key.EmployeeID, "scopes", key.Scopes, "created_by", createdBy)
+
+	return h.Response.SendSuccess(c, "API key created successfully, store it now as it cannot be shown again", res.CreatedAPIKeyResponse{
+		APIKeyResponse: apiKeyResponse(key),
+		Key:            secret,
+	})
+}
+
+// ListAPIKeys lists the API keys of the caller, or of the employee_id query param for admins, revoked ones included
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	employeeID, _ := c.Get("authenticated_user_id").(uint)
+	if value := c.QueryParam("employee_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee_id", err.Error())
+		}
+		employeeID = uint(parsed)
+	}
+	if !helper.ValidateEmployeeAccess(c, employeeID) {
+		return h.Response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only view your own API keys.", nil)
+	}
+
+	keys, err := h.APIKeyRepo.ListAPIKeys(employeeID)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve API keys", err.Error())
+	}
+
+	items := make([]res.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		items = append(items, apiKeyResponse(&keys[i]))
+	}
+	return h.Response.SendSuccess(c, "API keys retrieved successfully", items)
+}
+
+// RevokeAPIKey revokes an API key of the caller, or any key for admins. Requests with it are refused from then on.
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid API key ID", err.Error())
+	}
+
+	key, err := h.APIKeyRepo.GetAPIKeyByID(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.Response.SendNotFoundWithCode(c, response.ErrCodeAPIKeyNotFound, "API key not found", err.Error())
+	}
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve API key", err.Error())
+	}
+	// Someone else's key is reported as missing rather than revealed
+	if !helper.ValidateEmployeeAccess(c, key.EmployeeID) {
+		return h.Response.SendNotFoundWithCode(c, response.ErrCodeAPIKeyNotFound, "API key not found", nil)
+	}
+
+	if err := h.APIKeyRepo.RevokeAPIKey(key.ID); err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to revoke API key", err.Error())
+	}
+	revokedBy, _ := c.Get("authenticated_user_id").(uint)
+	middleware.LoggerFrom(c).Info("api key revoked", "api_key_id", key.ID, "employee_id", key.EmployeeID, "revoked_by", revokedBy)
+
+	key, err = h.APIKeyRepo.GetAPIKeyByID(key.ID)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve API key", err.Error())
+	}
+	return h.Response.SendSuccess(c, "API key revoked successfully", apiKeyResponse(key))
+}
+
+// apiKeyResponse converts an API key into its response, without the hash
+func apiKeyResponse(key *model.APIKey) res.APIKeyResponse {
+	return res.APIKeyResponse{
+		ID:         key.ID,
+		EmployeeID: key.EmployeeID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
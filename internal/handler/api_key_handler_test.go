@@ -0,0 +1,204 @@
+// Package handler contains unit tests for the API key handler functionality.
+//
+// CreateAPIKey tests cover (real handler on an in-memory database):
+// 1. A key created for the caller returned once, only its hash and prefix stored, duplicate scopes dropped
+// 2. Keys for another employee created by admins, refused with 403 for employees
+// 3. Unknown scopes, missing names and past expiries rejected with 400
+//
+// ListAPIKeys and RevokeAPIKey tests cover (real handler on an in-memory database):
+// 1. Only the caller's keys listed, without their hashes
+// 2. A revoked key refused by the middleware from then on
+// 3. Another employee's key reported as missing to employees, revoked by admins
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupAPIKeyHandler stores active employees 1 and 2 and admin 9
+func setupAPIKeyHandler(t *testing.T) (*gorm.DB, *APIKeyHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.PayGrade{}, &model.Employee{}, &model.APIKey{}))
+
+	for _, employee := range []model.Employee{
+		{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true},
+		{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Roe", Password: "hashed", Role: "employee", Active: true},
+		{DefaultAttribute: model.DefaultAttribute{ID: 9}, Name: "Admin", Password: "hashed", Role: "admin", Active: true},
+	} {
+		require.NoError(t, db.Create(&employee).Error)
+	}
+
+	return db, &APIKeyHandler{
+		Response:     response.NewResponse(),
+		APIKeyRepo:   repository.NewAPIKeyRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+	}
+}
+
+// apiKeyContext builds a context for the request as the given caller
+func apiKeyContext(method, target, body, role string, userID uint) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+	return c, rec
+}
+
+// createAPIKey runs CreateAPIKey with the body as the given caller
+func createAPIKey(t *testing.T, h *APIKeyHandler, body, role string, userID uint) *httptest.ResponseRecorder {
+	c, rec := apiKeyContext(http.MethodPost, "/api-key", body, role, userID)
+	require.NoError(t, h.CreateAPIKey(c))
+	return rec
+}
+
+// revokeAPIKey runs RevokeAPIKey for the key id as the given caller
+func revokeAPIKey(t *testing.T, h *APIKeyHandler, id, role string, userID uint) *httptest.ResponseRecorder {
+	c, rec := apiKeyContext(http.MethodDelete, "/api-key/"+id, "", role, userID)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	require.NoError(t, h.RevokeAPIKey(c))
+	return rec
+}
+
+// createdAPIKey decodes the key a successful CreateAPIKey returned
+func createdAPIKey(t *testing.T, rec *httptest.ResponseRecorder) res.CreatedAPIKeyResponse {
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body struct {
+		Data res.CreatedAPIKeyResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Data
+}
+
+func TestAPIKeyHandler_CreateAPIKey_ForSelf(t *testing.T) {
+	db, h := setupAPIKeyHandler(t)
+
+	created := createdAPIKey(t, createAPIKey(t, h, `{"name":" payslip sync ","scopes":["payslips:read","payslips:read"]}`, "employee", 1))
+	assert.True(t, strings.HasPrefix(created.Key, "psk_"))
+	assert.Len(t, created.Key, 68)
+	assert.Equal(t, uint(1), created.EmployeeID)
+	assert.Equal(t, "payslip sync", created.Name)
+	assert.Equal(t, created.Key[:12], created.Prefix)
+	assert.Equal(t, []string{"payslips:read"}, created.Scopes)
+	assert.Nil(t, created.ExpiresAt)
+
+	var stored model.APIKey
+	require.NoError(t, db.First(&stored, created.ID).Error)
+	assert.Equal(t, middleware.HashAPIKey(created.Key), stored.KeyHash)
+	require.NotNil(t, stored.CreatedBy)
+	assert.Equal(t, uint(1), *stored.CreatedBy)
+
+	// The stored hash resolves the key with its employee
+	key, err := h.APIKeyRepo.GetActiveAPIKeyByHash(middleware.HashAPIKey(created.Key))
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", key.Employee.Name)
+}
+
+func TestAPIKeyHandler_CreateAPIKey_ForOtherEmployee(t *testing.T) {
+	_, h := setupAPIKeyHandler(t)
+
+	rec := createAPIKey(t, h, `{"employee_id":2,"name":"sync","scopes":["read"]}`, "employee", 1)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	created := createdAPIKey(t, createAPIKey(t, h, `{"employee_id":2,"name":"sync","scopes":["read"],"expires_at":"2999-01-01T00:00:00Z"}`, "admin", 9))
+	assert.Equal(t, uint(2), created.EmployeeID)
+	require.NotNil(t, created.ExpiresAt)
+
+	rec = createAPIKey(t, h, `{"employee_id":404,"name":"sync","scopes":["read"]}`, "admin", 9)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPIKeyHandler_CreateAPIKey_Invalid(t *testing.T) {
+	db, h := setupAPIKeyHandler(t)
+
+	for _, body := range []string{
+		`{"name":"sync","scopes":["payroll:write"]}`,
+		`{"name":"sync","scopes":[]}`,
+		`{"scopes":["read"]}`,
+		`{"name":"sync","scopes":["read"],"expires_at":"2020-01-01T00:00:00Z"}`,
+	} {
+		rec := createAPIKey(t, h, body, "employee", 1)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+
+	var count int64
+	require.NoError(t, db.Model(&model.APIKey{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestAPIKeyHandler_ListAPIKeys(t *testing.T) {
+	_, h := setupAPIKeyHandler(t)
+	createdAPIKey(t, createAPIKey(t, h, `{"name":"mine","scopes":["read"]}`, "employee", 1))
+	createdAPIKey(t, createAPIKey(t, h, `{"name":"theirs","scopes":["read"]}`, "employee", 2))
+
+	c, rec := apiKeyContext(http.MethodGet, "/api-key", "", "employee", 1)
+	require.NoError(t, h.ListAPIKeys(c))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "key_hash")
+
+	var body struct {
+		Data []res.APIKeyResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "mine", body.Data[0].Name)
+
+	c, rec = apiKeyContext(http.MethodGet, "/api-key?employee_id=2", "", "employee", 1)
+	require.NoError(t, h.ListAPIKeys(c))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAPIKeyHandler_RevokeAPIKey(t *testing.T) {
+	_, h := setupAPIKeyHandler(t)
+	created := createdAPIKey(t, createAPIKey(t, h, `{"name":"sync","scopes":["read"]}`, "employee", 1))
+
+	rec := revokeAPIKey(t, h, "1", "employee", 1)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"revoked_at":"`)
+
+	// Requests with the revoked key are refused
+	e := echo.New()
+	e.Use(middleware.Authenticate(response.NewResponse(), func(uint) (int, error) { return 0, nil }, h.APIKeyRepo))
+	e.GET("/api/v1/payroll/payslips", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/payroll/payslips", nil)
+	req.Header.Set(middleware.APIKeyHeader, created.Key)
+	authRec := httptest.NewRecorder()
+	e.ServeHTTP(authRec, req)
+	assert.Equal(t, http.StatusUnauthorized, authRec.Code)
+	assert.Contains(t, authRec.Body.String(), `"error_code":"API_KEY_INVALID"`)
+}
+
+func TestAPIKeyHandler_RevokeAPIKey_OtherEmployee(t *testing.T) {
+	_, h := setupAPIKeyHandler(t)
+	createdAPIKey(t, createAPIKey(t, h, `{"name":"sync","scopes":["read"]}`, "employee", 2))
+
+	rec := revokeAPIKey(t, h, "1", "employee", 1)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "API_KEY_NOT_FOUND")
+
+	assert.Equal(t, http.StatusBadRequest, revokeAPIKey(t, h, "abc", "admin", 9).Code)
+	assert.Equal(t, http.StatusNotFound, revokeAPIKey(t, h, "404", "admin", 9).Code)
+	assert.Equal(t, http.StatusOK, revokeAPIKey(t, h, "1", "admin", 9).Code)
+}
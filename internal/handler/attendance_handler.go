@@ -1,10 +1,26 @@
 package handler
 
 import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/helper"
 	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
 	"github.com/yourname/payslip-system/internal/repository"
 
 	"gorm.io/gorm"
@@ -17,6 +33,23 @@ type AttendanceHandler struct {
 
 	BaseRepo       repository.BaseRepositoryInterface
 	AttendanceRepo repository.AttendanceRepository
+	EmployeeRepo   repository.EmployeeRepository
+	OvertimeRepo   repository.OvertimeRepository
+
+	// Anomaly holds the thresholds attendance records are flagged against
+	Anomaly config.AttendanceAnomalyConfig
+	// Overtime holds whether checkouts derive overtime and the standard day length
+	Overtime config.OvertimeConfig
+	// Geofence restricts where check-ins are accepted from when enabled
+	Geofence config.GeofenceConfig
+}
+
+// checkinStatuses are the statuses an attendance can be created with
+var checkinStatuses = []string{
+	model.AttendanceStatusPresent,
+	model.AttendanceStatusHalfDay,
+	model.AttendanceStatusLate,
+	model.AttendanceStatusAbsent,
 }
 
 func (h *AttendanceHandler) CheckinAttendancePeriod(c echo.Context) error {
@@ -25,16 +58,561 @@ func (h *AttendanceHandler) CheckinAttendancePeriod(c echo.Context) error {
 		return h.Response.SendError(c, err.Error(), "Invalid request data")
 	}
 
+	// The status decides how much of a working day the attendance is paid for
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if status == "" {
+		status = model.AttendanceStatusPresent
+	}
+	if !slices.Contains(checkinStatuses, status) {
+		return h.Response.SendBadRequest(c, fmt.Sprintf("Invalid status %q, must be one of %s", req.Status, strings.Join(checkinStatuses, ", ")), nil)
+	}
+
+	// The location is stored whenever it is sent, checked against the fences only while geofencing is enabled
+	if message := h.checkinLocationViolation(req.Latitude, req.Longitude); message != "" {
+		return h.Response.SendBadRequest(c, message, nil)
+	}
+
+	// One attendance per employee per calendar day in the server timezone
+	existing, found, err := h.AttendanceRepo.HasCheckinForDate(req.EmployeeID, time.Now().In(h.timeLocation()))
+	if err != nil {
+		return h.Response.SendError(c, err.Error(), "Failed to create attendance period")
+	}
+	if found {
+		return h.alreadyCheckedIn(c, existing)
+	}
+
 	// Get auditable database instance
 	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
 
-	_, err := h.AttendanceRepo.CheckinAttendancePeriodWithAudit(req.EmployeeID, auditDB)
+	_, err = h.AttendanceRepo.CheckinAttendancePeriodWithAudit(req.EmployeeID, status, req.Latitude, req.Longitude, h.timeLocation(), auditDB)
 	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyCheckedIn) {
+			return h.alreadyCheckedIn(c, nil)
+		}
 		return h.Response.SendError(c, err.Error(), "Failed to create attendance period")
 	}
 	return h.Response.SendSuccess(c, "Attendance period created successfully", nil)
 }
 
+// checkinLocationViolation returns why a check-in location is refused, empty when it is accepted.
+// Coordinates come as a valid pair or not at all, and must fall inside a geofence while geofencing is enabled.
+func (h *AttendanceHandler) checkinLocationViolation(latitude, longitude *float64) string {
+	if (latitude == nil) != (longitude == nil) {
+		return "Latitude and longitude must be sent together"
+	}
+	if latitude != nil && (*latitude < -90 || *latitude > 90 || *longitude < -180 || *longitude > 180) {
+		return "Invalid location, latitude must be within -90 and 90 and longitude within -180 and 180"
+	}
+	if !h.Geofence.Enabled {
+		return ""
+	}
+
+	if latitude == nil {
+		return "Location is required to check in"
+	}
+	fence, distance := helper.NearestGeofence(h.Geofence.Fences, *latitude, *longitude)
+	if fence == nil {
+		return "Check-in location is outside every allowed area"
+	}
+	if distance > fence.RadiusMeters {
+		return fmt.Sprintf("Check-in location is outside every allowed area, %.0f m from %s", distance-fence.RadiusMeters, geofenceName(fence))
+	}
+	return ""
+}
+
+// geofenceName names a fence in messages, unnamed fences by their center
+func geofenceName(fence *config.Geofence) string {
+	if fence.Name != "" {
+		return fence.Name
+	}
+	return fmt.Sprintf("the area around %.5f,%.5f", fence.Latitude, fence.Longitude)
+}
+
+// GetAttendance returns an attendance record with the overtime logged by the same employee for its date.
+// Employees see their own records, admins any.
+func (h *AttendanceHandler) GetAttendance(c echo.Context) error {
+	attendanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid attendance ID", err.Error())
+	}
+
+	attendance, overtimes, err := h.AttendanceRepo.GetAttendanceWithOvertimes(uint(attendanceID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return h.Response.SendNotFound(c, "Attendance not found", nil)
+		}
+		return h.Response.SendError(c, "Failed to retrieve attendance", err.Error())
+	}
+
+	if !helper.ValidateEmployeeAccess(c, attendance.EmployeeID) {
+		return h.Response.SendCustomResponse(c, http.StatusForbidden, "Access denied. You can only access your own attendance.", nil)
+	}
+
+	detail := res.AttendanceWithOvertime{
+		ID:          attendance.ID,
+		EmployeeID:  attendance.EmployeeID,
+		Date:        attendance.Date.Format("2006-01-02"),
+		Checkin:     attendance.Checkin,
+		Checkout:    attendance.Checkout,
+		HoursWorked: attendance.HoursWorked,
+		Status:      attendance.Status,
+		Open:        !attendance.IsComplete(),
+		Overtimes:   make([]res.AttendanceOvertime, 0, len(overtimes)),
+	}
+	for _, overtime := range overtimes {
+		detail.Overtimes = append(detail.Overtimes, res.AttendanceOvertime{
+			ID:         overtime.ID,
+			Hours:      overtime.Hours,
+			StartTime:  overtime.StartTime,
+			EndTime:    overtime.EndTime,
+			Reason:     overtime.Reason,
+			Status:     string(overtime.Status),
+			ApprovedBy: overtime.ApprovedBy,
+			ApprovedAt: overtime.ApprovedAt,
+		})
+	}
+	return h.Response.SendSuccess(c, "Attendance retrieved successfully", detail)
+}
+
+// BackfillAttendance records the attendance of a past day for an employee, e.g. after a forgotten check-in.
+// The day must not have an attendance yet, the same rule as check-in.
+func (h *AttendanceHandler) BackfillAttendance(c echo.Context) error {
+	var req request.BackfillAttendanceRequest
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request body", err.Error())
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	loc := h.timeLocation()
+	date, err := time.ParseInLocation("2006-01-02", req.Date, loc)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid date, expected YYYY-MM-DD", err.Error())
+	}
+	checkin, err := timeOnDate(date, req.Checkin)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid checkin, expected HH:MM", err.Error())
+	}
+	checkout, err := timeOnDate(date, req.Checkout)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid checkout, expected HH:MM", err.Error())
+	}
+	if !checkout.After(checkin) {
+		return h.Response.SendBadRequest(c, "Checkout must be after checkin", nil)
+	}
+	if checkout.After(time.Now()) {
+		return h.Response.SendBadRequest(c, "Cannot backfill attendance in the future", nil)
+	}
+
+	status := req.Status
+	if status == "" {
+		status = model.AttendanceStatusPresent
+	}
+
+	existing, found, err := h.AttendanceRepo.HasCheckinForDate(req.EmployeeID, date)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to check existing attendance", err.Error())
+	}
+	if found {
+		return h.attendanceExists(c, date, existing)
+	}
+
+	attendance := &model.Attendance{
+		EmployeeID: req.EmployeeID,
+		Date:       date,
+		Checkin:    checkin,
+		Checkout:   &checkout,
+		Status:     status,
+	}
+	attendance.CalculateHours()
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+	attendance, err = h.AttendanceRepo.CreateAttendanceWithAudit(attendance, auditDB)
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyCheckedIn) {
+			return h.attendanceExists(c, date, nil)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.Response.SendNotFound(c, "Employee not found", nil)
+		}
+		return h.Response.SendError(c, "Failed to backfill attendance", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Attendance backfilled successfully", attendance)
+}
+
+// attendanceImportColumns are the CSV columns of a biometric device export an attendance import needs, in any order
+var attendanceImportColumns = []string{"employee_id", "timestamp", "direction"}
+
+// attendanceImportLayouts are the timestamp layouts read in the server timezone, RFC 3339 is accepted too
+var attendanceImportLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// attendanceImportMaxSession is how long an in stays open for an out to close it, night shifts included
+const attendanceImportMaxSession = 24 * time.Hour
+
+// attendanceEvent is one in or out recorded by a biometric device, row is its index in the import report
+type attendanceEvent struct {
+	row        int
+	employeeID uint
+	at         time.Time
+	in         bool
+}
+
+// importedAttendanceDay is the attendance rebuilt from the events of an employee on the calendar day of its
+// check-in, from the first in to the last out, with the report rows of the events it was built from
+type importedAttendanceDay struct {
+	employeeID uint
+	date       time.Time
+	checkin    time.Time
+	checkout   *time.Time // Nil when no in of the day was matched by an out
+	rows       []int
+}
+
+// ImportAttendance rebuilds attendance from the biometric device CSV in the multipart "file" field, one
+// employee_id, timestamp and in or out direction per row. Each in is paired with the next out of the employee
+// within a day and the pairs of a calendar day make one attendance. Unmatched events are reported as warnings:
+// an in without an out still records the day, left without checkout when nothing else closes it, while an out
+// without an in is skipped. Days that already have an attendance are skipped, so a file can be imported again.
+func (h *AttendanceHandler) ImportAttendance(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return h.Response.SendBadRequest(c, "CSV file is required in the file form field", err.Error())
+	}
+	src, err := file.Open()
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to read CSV file", err.Error())
+	}
+	defer src.Close()
+
+	reader := csv.NewReader(src)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return h.Response.SendBadRequest(c, "CSV file must start with a header row", err.Error())
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range attendanceImportColumns {
+		if _, ok := columns[column]; !ok {
+			return h.Response.SendBadRequest(c, fmt.Sprintf("CSV header is missing the %s column", column), map[string][]string{"required_columns": attendanceImportColumns})
+		}
+	}
+
+	loc := h.timeLocation()
+	now := time.Now()
+	result := res.AttendanceImportResult{Rows: []res.AttendanceImportRow{}}
+	var events []attendanceEvent
+	known := make(map[uint]bool) // Whether each employee of the file exists
+	seen := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row := res.AttendanceImportRow{Row: line}
+		if err != nil {
+			// A malformed line does not stop the rest of the file from being read
+			row.Status, row.Reason = res.AttendanceImportRowFailed, err.Error()
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		event, err := attendanceImportEvent(record, columns, loc)
+		if err == nil && event.at.After(now) {
+			err = errors.New("timestamp is in the future")
+		}
+		if err == nil {
+			err = h.importEmployeeExists(event.employeeID, known)
+		}
+		if err != nil {
+			row.Status, row.Reason = res.AttendanceImportRowFailed, err.Error()
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+		at := event.at.In(loc)
+		row.EmployeeID, row.Timestamp, row.Direction = event.employeeID, &at, "out"
+		if event.in {
+			row.Direction = "in"
+		}
+
+		// Devices export the same event again when their logs overlap
+		key := fmt.Sprintf("%d/%s/%s", event.employeeID, event.at.UTC().Format(time.RFC3339Nano), row.Direction)
+		if first, ok := seen[key]; ok {
+			row.Status, row.Reason = res.AttendanceImportRowSkipped, fmt.Sprintf("duplicate of row %d", first)
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+		seen[key] = line
+
+		event.row = len(result.Rows)
+		result.Rows = append(result.Rows, row)
+		events = append(events, event)
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+	for _, day := range pairAttendanceEvents(events, result.Rows, loc) {
+		status, reason, attendanceID := res.AttendanceImportRowImported, "", uint(0)
+		existing, found, err := h.AttendanceRepo.HasCheckinForDate(day.employeeID, day.date)
+		switch {
+		case err != nil:
+			status, reason = res.AttendanceImportRowFailed, err.Error()
+		case found:
+			status, reason, attendanceID = res.AttendanceImportRowSkipped, fmt.Sprintf("attendance already recorded for %s", day.date.Format("2006-01-02")), existing.ID
+		default:
+			attendance := &model.Attendance{
+				EmployeeID: day.employeeID,
+				Date:       day.date,
+				Checkin:    day.checkin,
+				Checkout:   day.checkout,
+				Status:     model.AttendanceStatusPresent,
+			}
+			attendance.CalculateHours()
+
+			attendance, err = h.AttendanceRepo.CreateAttendanceWithAudit(attendance, auditDB)
+			switch {
+			case errors.Is(err, repository.ErrAlreadyCheckedIn):
+				status, reason = res.AttendanceImportRowSkipped, fmt.Sprintf("attendance already recorded for %s", day.date.Format("2006-01-02"))
+			case err != nil:
+				status, reason = res.AttendanceImportRowFailed, err.Error()
+			default:
+				attendanceID = attendance.ID
+				result.AttendancesCreated++
+			}
+		}
+
+		for _, index := range day.rows {
+			row := &result.Rows[index]
+			row.Status, row.Reason, row.AttendanceID = status, reason, attendanceID
+		}
+	}
+
+	result.Total = len(result.Rows)
+	for _, row := range result.Rows {
+		switch row.Status {
+		case res.AttendanceImportRowImported:
+			result.Imported++
+		case res.AttendanceImportRowSkipped:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+		if row.Warning != "" {
+			result.Warnings++
+		}
+	}
+	return h.Response.SendSuccess(c, "Attendance imported", result)
+}
+
+// attendanceImportEvent reads the event of a CSV row of an attendance import, timestamps without an offset
+// taken in loc
+func attendanceImportEvent(record []string, columns map[string]int, loc *time.Location) (attendanceEvent, error) {
+	value := func(column string) string {
+		i := columns[column]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var event attendanceEvent
+	employeeID, err := strconv.ParseUint(value("employee_id"), 10, 32)
+	if err != nil || employeeID == 0 {
+		return event, fmt.Errorf("invalid employee_id %q", value("employee_id"))
+	}
+	event.employeeID = uint(employeeID)
+
+	timestamp := value("timestamp")
+	event.at, err = time.Parse(time.RFC3339, timestamp)
+	for _, layout := range attendanceImportLayouts {
+		if err == nil {
+			break
+		}
+		event.at, err = time.ParseInLocation(layout, timestamp, loc)
+	}
+	if err != nil {
+		return event, fmt.Errorf("invalid timestamp %q, expected RFC 3339 or YYYY-MM-DD HH:MM:SS", timestamp)
+	}
+
+	switch direction := strings.ToLower(value("direction")); direction {
+	case "in":
+		event.in = true
+	case "out":
+	default:
+		return event, fmt.Errorf("invalid direction %q, expected in or out", value("direction"))
+	}
+	return event, nil
+}
+
+// importEmployeeExists checks the employee of an imported event exists, remembering the answer in known
+func (h *AttendanceHandler) importEmployeeExists(employeeID uint, known map[uint]bool) error {
+	exists, ok := known[employeeID]
+	if !ok {
+		_, err := h.EmployeeRepo.GetEmployeeByID(employeeID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		exists = err == nil
+		known[employeeID] = exists
+	}
+	if !exists {
+		return fmt.Errorf("employee %d not found", employeeID)
+	}
+	return nil
+}
+
+// pairAttendanceEvents pairs each in with the next out of the same employee within attendanceImportMaxSession and
+// merges the pairs by the calendar day of their in, in loc. A repeated in of a day that is already open is a
+// repeated scan and skipped. An in left open when the next in falls on a later day, or nothing closes it, is kept
+// with a warning, an out without an open in is skipped with one. The outcome of skipped events is set on rows.
+func pairAttendanceEvents(events []attendanceEvent, rows []res.AttendanceImportRow, loc *time.Location) []*importedAttendanceDay {
+	sorted := slices.Clone(events)
+	slices.SortStableFunc(sorted, func(a, b attendanceEvent) int {
+		if a.employeeID != b.employeeID {
+			return cmp.Compare(a.employeeID, b.employeeID)
+		}
+		if !a.at.Equal(b.at) {
+			return a.at.Compare(b.at)
+		}
+		// An out and an in at the same instant close the open session before starting the next one
+		if a.in == b.in {
+			return 0
+		}
+		if a.in {
+			return 1
+		}
+		return -1
+	})
+
+	var days []*importedAttendanceDay
+	byDate := make(map[string]*importedAttendanceDay)
+	addSession := func(in attendanceEvent, out *attendanceEvent) {
+		checkin := in.at.In(loc)
+		date := time.Date(checkin.Year(), checkin.Month(), checkin.Day(), 0, 0, 0, 0, loc)
+		key := fmt.Sprintf("%d/%s", in.employeeID, date.Format("2006-01-02"))
+		day, ok := byDate[key]
+		if !ok {
+			day = &importedAttendanceDay{employeeID: in.employeeID, date: date, checkin: checkin}
+			byDate[key] = day
+			days = append(days, day)
+		}
+		day.rows = append(day.rows, in.row)
+		if out != nil {
+			checkout := out.at.In(loc)
+			if day.checkout == nil || checkout.After(*day.checkout) {
+				day.checkout = &checkout
+			}
+			day.rows = append(day.rows, out.row)
+		}
+	}
+
+	var open *attendanceEvent
+	closeUnmatched := func() {
+		rows[open.row].Warning = "in without a matching out"
+		addSession(*open, nil)
+		open = nil
+	}
+	for _, event := range sorted {
+		if open != nil && open.employeeID != event.employeeID {
+			closeUnmatched()
+		}
+
+		if event.in {
+			if open != nil {
+				if open.at.In(loc).Format("2006-01-02") == event.at.In(loc).Format("2006-01-02") {
+					rows[event.row].Status = res.AttendanceImportRowSkipped
+					rows[event.row].Reason = fmt.Sprintf("repeated in, already in since row %d", rows[open.row].Row)
+					continue
+				}
+				closeUnmatched()
+			}
+			open = &event
+			continue
+		}
+
+		if open != nil && event.at.Sub(open.at) > attendanceImportMaxSession {
+			closeUnmatched()
+		}
+		if open == nil {
+			rows[event.row].Status = res.AttendanceImportRowSkipped
+			rows[event.row].Warning = "out without a matching in"
+			continue
+		}
+		addSession(*open, &event)
+		open = nil
+	}
+	if open != nil {
+		closeUnmatched()
+	}
+	return days
+}
+
+// DeleteAttendance soft deletes a mistaken attendance record. An attendance in a pay period that already has a
+// payslip is refused with 409, deleting it would leave the paid payroll out of step with the records.
+func (h *AttendanceHandler) DeleteAttendance(c echo.Context) error {
+	attendanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid attendance ID", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+	attendance, err := h.AttendanceRepo.DeleteAttendanceWithAudit(uint(attendanceID), auditDB)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return h.Response.SendNotFound(c, "Attendance not found", nil)
+	case errors.Is(err, repository.ErrAttendancePeriodProcessed):
+		return h.Response.SendCustomResponse(c, http.StatusConflict, err.Error()+", void the payslip first", nil)
+	case err != nil:
+		return h.Response.SendError(c, "Failed to delete attendance", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Attendance deleted successfully", map[string]interface{}{"id": attendance.ID})
+}
+
+// timeOnDate returns the HH:MM clock time on the day of date, in its location
+func timeOnDate(date time.Time, clock string) (time.Time, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), parsed.Hour(), parsed.Minute(), 0, 0, date.Location()), nil
+}
+
+// attendanceExists answers 409 for a backfill of a day that already has an attendance, with it when known
+func (h *AttendanceHandler) attendanceExists(c echo.Context, date time.Time, existing *model.Attendance) error {
+	message := fmt.Sprintf("Attendance already recorded for %s", date.Format("2006-01-02"))
+	if existing == nil {
+		return h.Response.SendCustomResponse(c, http.StatusConflict, message, nil)
+	}
+	return h.Response.SendCustomResponse(c, http.StatusConflict, message,
+		map[string]interface{}{"attendance_id": existing.ID, "checkin": existing.Checkin.In(h.timeLocation())})
+}
+
+// alreadyCheckedIn answers 409 with the check-in time of today's existing attendance when known
+func (h *AttendanceHandler) alreadyCheckedIn(c echo.Context, existing *model.Attendance) error {
+	if existing == nil {
+		return h.Response.SendCustomResponse(c, http.StatusConflict, repository.ErrAlreadyCheckedIn.Error(), nil)
+	}
+	checkin := existing.Checkin.In(h.timeLocation())
+	return h.Response.SendCustomResponse(c, http.StatusConflict,
+		fmt.Sprintf("Already checked in today at %s", checkin.Format("15:04")),
+		map[string]interface{}{"attendance_id": existing.ID, "checkin": checkin})
+}
+
+// timeLocation is the server timezone calendar days are taken in, the process local time when unset
+func (h *AttendanceHandler) timeLocation() *time.Location {
+	if h.Helper.TimeLocation != nil {
+		return h.Helper.TimeLocation
+	}
+	return time.Local
+}
+
 func (h *AttendanceHandler) CheckOutAttendancePeriod(c echo.Context) error {
 	req := request.CreateAttendanceRequest{}
 	if err := c.Bind(&req); err != nil {
@@ -44,9 +622,305 @@ func (h *AttendanceHandler) CheckOutAttendancePeriod(c echo.Context) error {
 	// Get auditable database instance
 	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
 
-	_, err := h.AttendanceRepo.CheckOutAttendancePeriodWithAudit(req.EmployeeID, auditDB)
+	attendance, err := h.AttendanceRepo.CheckOutAttendancePeriodWithAudit(req.EmployeeID, auditDB)
 	if err != nil {
 		return h.Response.SendError(c, err.Error(), "Failed to update attendance period")
 	}
-	return h.Response.SendSuccess(c, "Attendance checkout successful", nil)
+	return h.Response.SendSuccess(c, h.checkoutMessage(attendance, auditDB), nil)
+}
+
+// CheckOutAttendance closes today's open attendance of the authenticated employee and stores the hours worked
+func (h *AttendanceHandler) CheckOutAttendance(c echo.Context) error {
+	employeeID, ok := c.Get("authenticated_user_id").(uint)
+	if !ok {
+		return h.Response.SendUnauthorized(c, "Authentication required", nil)
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+	attendance, err := h.AttendanceRepo.CheckOutAttendancePeriodWithAudit(employeeID, auditDB)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoOpenCheckin) || errors.Is(err, repository.ErrAlreadyCheckedOut) {
+			return h.Response.SendBadRequest(c, err.Error(), nil)
+		}
+		return h.Response.SendError(c, "Failed to update attendance period", err.Error())
+	}
+	return h.Response.SendSuccess(c, h.checkoutMessage(attendance, auditDB), attendance)
+}
+
+// checkoutMessage derives the overtime of a checkout when enabled and reports it in the success message.
+// The checkout is already stored, so a failure to store the overtime is only logged.
+func (h *AttendanceHandler) checkoutMessage(attendance *model.Attendance, auditDB *middleware.AuditableDB) string {
+	const message = "Attendance checkout successful"
+	if !h.Overtime.AutoDerive {
+		return message
+	}
+
+	overtime, ok := deriveOvertime(*attendance, h.Overtime.StandardDayHours, h.timeLocation())
+	if !ok {
+		return message
+	}
+	overtime, ok, err := h.capDerivedOvertime(overtime)
+	if err != nil {
+		log.Printf("Failed to derive overtime of attendance %d: %v", attendance.ID, err)
+		return message
+	}
+	if !ok {
+		return message
+	}
+	overtime, created, err := h.OvertimeRepo.CreateOvertimeIfNoneForDateWithAudit(overtime, auditDB)
+	if err != nil {
+		log.Printf("Failed to derive overtime of attendance %d: %v", attendance.ID, err)
+		return message
+	}
+	if !created {
+		return message
+	}
+	return fmt.Sprintf("%s, %dh overtime pending approval", message, overtime.Hours)
+}
+
+// capDerivedOvertime trims a derived overtime to the hours left under the monthly overtime cap of its month,
+// so pay is never computed for hours beyond it. Returns false when the cap is already used up.
+func (h *AttendanceHandler) capDerivedOvertime(overtime *model.Overtime) (*model.Overtime, bool, error) {
+	if h.Overtime.MaxMonthlyHours <= 0 {
+		return overtime, true, nil
+	}
+
+	day, err := time.Parse("2006-01-02", overtime.OvertimeDate)
+	if err != nil {
+		return nil, false, err
+	}
+	used, err := h.OvertimeRepo.SumOvertimeHoursForMonth(overtime.EmployeeID, day.Year(), day.Month())
+	if err != nil {
+		return nil, false, err
+	}
+
+	remaining := h.Overtime.MaxMonthlyHours - used
+	if remaining < 1 {
+		return nil, false, nil
+	}
+	if overtime.Hours > remaining {
+		start, err := time.Parse("15:04", overtime.StartTime)
+		if err != nil {
+			return nil, false, err
+		}
+		overtime.Hours = remaining
+		overtime.EndTime = start.Add(time.Duration(remaining) * time.Hour).Format("15:04")
+	}
+	return overtime, true, nil
+}
+
+// maxDerivedOvertimeHours is the most overtime a single checkout derives, the limit of an overtime entry
+const maxDerivedOvertimeHours = 12
+
+// deriveOvertime returns a pending overtime for the whole hours an attendance ran beyond the standard day,
+// false when it did not. The hours come from the check-in and checkout instants, so a checkout past midnight
+// counts fully, and the overtime is dated on the check-in day in loc. The start and end times span the whole
+// hours only, so pay computed from them matches the hours.
+func deriveOvertime(attendance model.Attendance, standardDayHours int, loc *time.Location) (*model.Overtime, bool) {
+	if attendance.Checkout == nil || !attendance.IsPresent() {
+		return nil, false
+	}
+
+	checkin := attendance.Checkin.In(loc)
+	checkout := attendance.Checkout.In(loc)
+	standardEnd := checkin.Add(time.Duration(standardDayHours) * time.Hour)
+	hours := int(checkout.Sub(standardEnd).Hours())
+	if hours < 1 {
+		return nil, false
+	}
+	hours = min(hours, maxDerivedOvertimeHours)
+
+	return &model.Overtime{
+		EmployeeID:   attendance.EmployeeID,
+		OvertimeDate: checkin.Format("2006-01-02"),
+		StartTime:    standardEnd.Format("15:04"),
+		EndTime:      standardEnd.Add(time.Duration(hours) * time.Hour).Format("15:04"),
+		Hours:        hours,
+		Reason:       fmt.Sprintf("Derived from attendance %d, worked beyond the %dh standard day", attendance.ID, standardDayHours),
+		Status:       model.OvertimePending,
+	}, true
+}
+
+// GetDailyAttendanceSummary reports every active employee as present, late or absent on a date,
+// with the check-in, check-out and hours worked. Admins see every employee, managers their reporting subtree and themselves.
+func (h *AttendanceHandler) GetDailyAttendanceSummary(c echo.Context) error {
+	dateParam := c.QueryParam("date")
+	if dateParam == "" {
+		return h.Response.SendBadRequest(c, "date is required, expected YYYY-MM-DD", nil)
+	}
+	date, err := time.ParseInLocation("2006-01-02", dateParam, h.timeLocation())
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid date, expected YYYY-MM-DD", err.Error())
+	}
+
+	employeeIDs, err := h.attendanceScope(c)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to resolve managed employees", err.Error())
+	}
+
+	employees, err := h.EmployeeRepo.GetAllActiveEmployees()
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve employees", err.Error())
+	}
+	attendances, err := h.AttendanceRepo.GetAttendanceForDate(date)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve attendance", err.Error())
+	}
+
+	// The earliest check-in of the day stands for the employee
+	byEmployee := make(map[uint]model.Attendance, len(attendances))
+	for _, attendance := range attendances {
+		if _, seen := byEmployee[attendance.EmployeeID]; !seen {
+			byEmployee[attendance.EmployeeID] = attendance
+		}
+	}
+
+	summary := res.DailyAttendanceSummary{
+		Date:      date.Format("2006-01-02"),
+		Employees: make([]res.DailyAttendanceEntry, 0, len(employees)),
+	}
+	for _, employee := range employees {
+		if employeeIDs != nil && !slices.Contains(employeeIDs, employee.ID) {
+			continue
+		}
+
+		entry := res.DailyAttendanceEntry{
+			EmployeeID:   employee.ID,
+			EmployeeName: employee.Name,
+			Status:       res.DailyStatusAbsent,
+		}
+		if attendance, ok := byEmployee[employee.ID]; ok {
+			entry = h.dailyAttendanceEntry(employee, attendance)
+		}
+
+		switch entry.Status {
+		case res.DailyStatusPresent:
+			summary.Present++
+		case res.DailyStatusLate:
+			summary.Late++
+		default:
+			summary.Absent++
+		}
+		summary.Employees = append(summary.Employees, entry)
+	}
+	summary.Total = len(summary.Employees)
+
+	return h.Response.SendSuccess(c, "Daily attendance summary retrieved successfully", summary)
+}
+
+// dailyAttendanceEntry reports an attendance in the server timezone, a present check-in at or after the
+// late check-in hour, or after its grace period when one is set, counts as late, statuses other than present, half day and late count as absent
+func (h *AttendanceHandler) dailyAttendanceEntry(employee model.Employee, attendance model.Attendance) res.DailyAttendanceEntry {
+	loc := h.timeLocation()
+	checkin := attendance.Checkin.In(loc)
+	entry := res.DailyAttendanceEntry{
+		EmployeeID:     employee.ID,
+		EmployeeName:   employee.Name,
+		Status:         res.DailyStatusAbsent,
+		RecordedStatus: attendance.Status,
+		AttendanceID:   &attendance.ID,
+		Checkin:        &checkin,
+		HoursWorked:    attendance.HoursWorked,
+		Open:           !attendance.IsComplete(),
+	}
+	if attendance.Checkout != nil {
+		checkout := attendance.Checkout.In(loc)
+		entry.Checkout = &checkout
+	}
+
+	if attendance.IsPresent() {
+		entry.Status = res.DailyStatusPresent
+		if attendance.Status == model.AttendanceStatusLate || h.Anomaly.IsLateCheckin(checkin) {
+			entry.Status = res.DailyStatusLate
+		}
+	}
+	return entry
+}
+
+// ExportAttendanceAnomaliesCSV streams the flagged attendance records of a period as CSV, the days taken in the
+// server location. Admins export every employee, managers their reporting subtree and themselves, employees only themselves.
+func (h *AttendanceHandler) ExportAttendanceAnomaliesCSV(c echo.Context) error {
+	loc := h.timeLocation()
+	startDate, err := time.ParseInLocation("2006-01-02", c.QueryParam("start_date"), loc)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid start_date, expected YYYY-MM-DD", err.Error())
+	}
+	endDate, err := time.ParseInLocation("2006-01-02", c.QueryParam("end_date"), loc)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid end_date, expected YYYY-MM-DD", err.Error())
+	}
+	if endDate.Before(startDate) {
+		return h.Response.SendBadRequest(c, "End date must be after start date", nil)
+	}
+
+	employeeIDs, err := h.attendanceScope(c)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to resolve managed employees", err.Error())
+	}
+
+	// Stream the rows, the status can no longer change once writing starts
+	filename := fmt.Sprintf("attendance-anomalies-%s-%s.csv", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(c.Response())
+	if err := writer.Write([]string{"employee_id", "employee_name", "date", "reason", "value", "threshold"}); err != nil {
+		return err
+	}
+
+	today := time.Now()
+	// The end date is included up to its last moment
+	err = h.AttendanceRepo.EachAttendanceInPeriod(employeeIDs, startDate, endDate.AddDate(0, 0, 1).Add(-time.Microsecond), func(attendances []model.Attendance) error {
+		for _, attendance := range attendances {
+			for _, anomaly := range helper.DetectAttendanceAnomalies(attendance, h.Anomaly, today) {
+				err := writer.Write([]string{
+					strconv.FormatUint(uint64(anomaly.EmployeeID), 10),
+					anomaly.EmployeeName,
+					anomaly.Date,
+					anomaly.Reason,
+					anomaly.Value,
+					anomaly.Threshold,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		writer.Flush()
+		c.Response().Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	c.Response().Flush()
+	return writer.Error()
+}
+
+// attendanceScope returns the employees the caller may see the attendance of, nil for every employee
+func (h *AttendanceHandler) attendanceScope(c echo.Context) ([]uint, error) {
+	role, _ := c.Get("authenticated_role").(string)
+	userID, _ := c.Get("authenticated_user_id").(uint)
+
+	switch role {
+	case "admin":
+		return nil, nil
+	case "manager":
+		managed, err := h.EmployeeRepo.GetManagedEmployees(userID)
+		if err != nil {
+			return nil, err
+		}
+		employeeIDs := []uint{userID}
+		for _, employee := range managed {
+			employeeIDs = append(employeeIDs, employee.ID)
+		}
+		return employeeIDs, nil
+	default:
+		return []uint{userID}, nil
+	}
 }
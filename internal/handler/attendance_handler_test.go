@@ -1,17 +1,31 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // Test using a simplified approach - testing the handler logic without complex mocking
@@ -85,8 +99,8 @@ type MockAttendanceRepo struct {
 	mock.Mock
 }
 
-func (m *MockAttendanceRepo) CheckinAttendancePeriodWithAudit(employeeID uint, auditDB *middleware.AuditableDB) (*model.Attendance, error) {
-	args := m.Called(employeeID, auditDB)
+func (m *MockAttendanceRepo) CheckinAttendancePeriodWithAudit(employeeID uint, status string, latitude, longitude *float64, loc *time.Location, auditDB *middleware.AuditableDB) (*model.Attendance, error) {
+	args := m.Called(employeeID, status, auditDB)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -110,9 +124,9 @@ func TestMockAttendanceRepo_CheckinSuccess(t *testing.T) {
 		Status:           "present",
 	}
 
-	mockRepo.On("CheckinAttendancePeriodWithAudit", uint(1), mock.AnythingOfType("*middleware.AuditableDB")).Return(expectedAttendance, nil)
+	mockRepo.On("CheckinAttendancePeriodWithAudit", uint(1), "present", mock.AnythingOfType("*middleware.AuditableDB")).Return(expectedAttendance, nil)
 
-	result, err := mockRepo.CheckinAttendancePeriodWithAudit(1, &middleware.AuditableDB{})
+	result, err := mockRepo.CheckinAttendancePeriodWithAudit(1, "present", nil, nil, time.UTC, &middleware.AuditableDB{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -124,9 +138,9 @@ func TestMockAttendanceRepo_CheckinSuccess(t *testing.T) {
 func TestMockAttendanceRepo_CheckinError(t *testing.T) {
 	mockRepo := new(MockAttendanceRepo)
 
-	mockRepo.On("CheckinAttendancePeriodWithAudit", uint(999), mock.AnythingOfType("*middleware.AuditableDB")).Return((*model.Attendance)(nil), errors.New("employee not found"))
+	mockRepo.On("CheckinAttendancePeriodWithAudit", uint(999), "present", mock.AnythingOfType("*middleware.AuditableDB")).Return((*model.Attendance)(nil), errors.New("employee not found"))
 
-	result, err := mockRepo.CheckinAttendancePeriodWithAudit(999, &middleware.AuditableDB{})
+	result, err := mockRepo.CheckinAttendancePeriodWithAudit(999, "present", nil, nil, time.UTC, &middleware.AuditableDB{})
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -208,3 +222,926 @@ func TestJSONStructure(t *testing.T) {
 	assert.Equal(t, "database error", errorResponse["error"])
 	assert.Equal(t, "Failed to create attendance period", errorResponse["message"])
 }
+
+// Tests for ExportAttendanceAnomaliesCSV
+
+// Tests for DeleteAttendance
+
+// deleteAttendance runs DeleteAttendance for the attendance ID as admin 9
+func deleteAttendance(t *testing.T, h *AttendanceHandler, id string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/attendance/"+id, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	c.Set("user_id", 9)
+
+	require.NoError(t, h.DeleteAttendance(c))
+	return rec
+}
+
+func TestDeleteAttendance(t *testing.T) {
+	db, h := setupCheckOutHandler(t, 0)
+	require.NoError(t, db.AutoMigrate(&model.Payslip{}))
+	june := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	paid := &model.Attendance{EmployeeID: 1, Date: june, Checkin: june.Add(9 * time.Hour), Status: "present"}
+	open := &model.Attendance{EmployeeID: 1, Date: july, Checkin: july.Add(9 * time.Hour), Status: "present"}
+	require.NoError(t, db.Create(paid).Error)
+	require.NoError(t, db.Create(open).Error)
+	require.NoError(t, db.Create(&model.Payslip{EmployeeID: 1, PayPeriodStart: june.AddDate(0, 0, -1), PayPeriodEnd: july.AddDate(0, 0, -1), ProcessedAt: june, Status: "processed"}).Error)
+
+	rec := deleteAttendance(t, h, strconv.FormatUint(uint64(open.ID), 10))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var stored model.Attendance
+	require.NoError(t, db.Unscoped().First(&stored, open.ID).Error)
+	assert.True(t, stored.DeletedAt.Valid)
+	require.NotNil(t, stored.DeletedBy)
+	assert.Equal(t, uint(9), *stored.DeletedBy)
+
+	// A processed period keeps its attendance
+	rec = deleteAttendance(t, h, strconv.FormatUint(uint64(paid.ID), 10))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "2025-06-01 to 2025-06-30")
+	require.NoError(t, db.First(&model.Attendance{}, paid.ID).Error)
+
+	assert.Equal(t, http.StatusNotFound, deleteAttendance(t, h, strconv.FormatUint(uint64(open.ID), 10)).Code)
+	assert.Equal(t, http.StatusBadRequest, deleteAttendance(t, h, "abc").Code)
+}
+
+// setupAnomalyHandler seeds manager 1 with report 2 and an unrelated employee 3 with June 2025 attendance
+func setupAnomalyHandler(t *testing.T) (*gorm.DB, *AttendanceHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Employee{}, &model.Attendance{}, &model.AuditLog{}))
+
+	managerID := uint(1)
+	for _, employee := range []model.Employee{
+		{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Maria Manager", Role: "manager"},
+		{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "Eddie Employee", Role: "employee", ManagerID: &managerID},
+		{DefaultAttribute: model.DefaultAttribute{ID: 3}, Name: "Olga Other", Role: "employee"},
+	} {
+		employee.Password = "hashed"
+		employee.Active = true
+		require.NoError(t, db.Create(&employee).Error)
+	}
+
+	attend := func(employeeID uint, day int, checkin, checkout int, status string) {
+		date := time.Date(2025, time.June, day, 0, 0, 0, 0, time.UTC)
+		attendance := model.Attendance{EmployeeID: employeeID, Date: date, Checkin: date.Add(time.Duration(checkin) * time.Minute), Status: status}
+		if checkout > 0 {
+			checkoutAt := date.Add(time.Duration(checkout) * time.Minute)
+			attendance.Checkout = &checkoutAt
+			attendance.CalculateHours()
+		}
+		require.NoError(t, db.Create(&attendance).Error)
+	}
+	attend(2, 2, 9*60, 17*60, "present")        // normal
+	attend(2, 3, 10*60+30, 18*60+30, "present") // late check-in
+	attend(2, 4, 9*60, 0, "present")            // missing checkout
+	attend(2, 5, 9*60, 11*60, "present")        // short day
+	attend(2, 6, 11*60, 0, "absent")            // not a present day
+	attend(3, 5, 7*60, 21*60, "present")        // long day
+
+	return db, &AttendanceHandler{
+		Response:       response.NewResponse(),
+		AttendanceRepo: repository.NewAttendanceRepository(db),
+		EmployeeRepo:   repository.NewEmployeeRepository(db),
+		Anomaly:        config.AttendanceAnomalyConfig{Late: config.LateCheckinPolicy{WorkStartMinutes: 10 * 60}, MinHours: 4, MaxHours: 12},
+	}
+}
+
+// exportAnomalies runs ExportAttendanceAnomaliesCSV for the period as the given caller
+func exportAnomalies(t *testing.T, h *AttendanceHandler, query string, role string, userID uint) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/attendance/anomalies/csv?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, h.ExportAttendanceAnomaliesCSV(c))
+	return rec
+}
+
+func TestExportAttendanceAnomaliesCSV_Admin(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+
+	rec := exportAnomalies(t, h, "start_date=2025-06-01&end_date=2025-06-30", "admin", 99)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	body := rec.Body.String()
+	assert.True(t, strings.HasPrefix(body, "employee_id,employee_name,date,reason,value,threshold\n"))
+	assert.Contains(t, body, "2,Eddie Employee,2025-06-03,late_checkin,10:30,10:00\n")
+	assert.Contains(t, body, "2,Eddie Employee,2025-06-04,missing_checkout,,\n")
+	assert.Contains(t, body, "2,Eddie Employee,2025-06-05,short_day,2h,4h\n")
+	assert.Contains(t, body, "3,Olga Other,2025-06-05,long_day,14h,12h\n")
+	assert.NotContains(t, body, "2025-06-02")
+	assert.NotContains(t, body, "2025-06-06")
+	assert.Len(t, strings.Split(strings.TrimSpace(body), "\n"), 5)
+}
+
+func TestExportAttendanceAnomaliesCSV_RoleScoping(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+
+	// The manager sees their report but not the unrelated employee
+	body := exportAnomalies(t, h, "start_date=2025-06-01&end_date=2025-06-30", "manager", 1).Body.String()
+	assert.Len(t, strings.Split(strings.TrimSpace(body), "\n"), 4)
+	assert.NotContains(t, body, "Olga Other")
+
+	// An employee only sees their own records
+	body = exportAnomalies(t, h, "start_date=2025-06-01&end_date=2025-06-30", "employee", 3).Body.String()
+	assert.Len(t, strings.Split(strings.TrimSpace(body), "\n"), 2)
+	assert.Contains(t, body, "3,Olga Other,2025-06-05,long_day,14h,12h\n")
+}
+
+func TestExportAttendanceAnomaliesCSV_CleanPeriod(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+
+	rec := exportAnomalies(t, h, "start_date=2025-06-02&end_date=2025-06-02", "admin", 99)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "employee_id,employee_name,date,reason,value,threshold\n", rec.Body.String())
+}
+
+func TestExportAttendanceAnomaliesCSV_InvalidPeriod(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+
+	rec := exportAnomalies(t, h, "start_date=2025-06-30&end_date=2025-06-01", "admin", 99)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExportAttendanceAnomaliesCSV_ServerLocation(t *testing.T) {
+	db, h := setupAnomalyHandler(t)
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	h.Helper.TimeLocation = loc
+
+	// Missing checkouts early on 1 July in the server location, still 30 June in UTC, late on 1 July and on 2 July
+	for _, checkin := range []time.Time{
+		time.Date(2025, time.July, 1, 1, 0, 0, 0, loc),
+		time.Date(2025, time.July, 1, 23, 0, 0, 0, loc),
+		time.Date(2025, time.July, 2, 1, 0, 0, 0, loc),
+	} {
+		require.NoError(t, db.Create(&model.Attendance{EmployeeID: 3, Date: checkin, Checkin: checkin, Status: "present"}).Error)
+	}
+
+	body := exportAnomalies(t, h, "start_date=2025-07-01&end_date=2025-07-01", "admin", 99).Body.String()
+	assert.Equal(t, "employee_id,employee_name,date,reason,value,threshold\n"+
+		"3,Olga Other,2025-07-01,missing_checkout,,\n"+
+		"3,Olga Other,2025-07-01,late_checkin,23:00,10:00\n"+
+		"3,Olga Other,2025-07-01,missing_checkout,,\n", body)
+}
+
+// Tests for CheckOutAttendance
+
+// checkOut runs CheckOutAttendance as employee 1
+func checkOut(t *testing.T, h *AttendanceHandler) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/attendance/checkout", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "employee")
+	c.Set("authenticated_user_id", uint(1))
+
+	require.NoError(t, h.CheckOutAttendance(c))
+	return rec
+}
+
+// setupCheckOutHandler stores employee 1, checked in today the given time ago when checkedInAgo is positive
+func setupCheckOutHandler(t *testing.T, checkedInAgo time.Duration) (*gorm.DB, *AttendanceHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Employee{}, &model.Attendance{}, &model.AuditLog{}))
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Eddie Employee", Password: "hashed", Role: "employee", Active: true}).Error)
+
+	if checkedInAgo > 0 {
+		now := time.Now()
+		require.NoError(t, db.Create(&model.Attendance{EmployeeID: 1, Date: now, Checkin: now.Add(-checkedInAgo), Status: "present"}).Error)
+	}
+
+	return db, &AttendanceHandler{
+		Response:       response.NewResponse(),
+		BaseRepo:       repository.NewBaseRepository(db),
+		AttendanceRepo: repository.NewAttendanceRepository(db),
+	}
+}
+
+func TestCheckOutAttendance_StoresRoundedHours(t *testing.T) {
+	db, h := setupCheckOutHandler(t, 7*time.Hour+40*time.Minute)
+
+	rec := checkOut(t, h)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stored model.Attendance
+	require.NoError(t, db.First(&stored).Error)
+	require.NotNil(t, stored.Checkout)
+	assert.Equal(t, 8, stored.HoursWorked)
+
+	// A second checkout is refused and keeps the first one
+	rec = checkOut(t, h)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "already checked out for today")
+
+	var again model.Attendance
+	require.NoError(t, db.First(&again).Error)
+	assert.True(t, stored.Checkout.Equal(*again.Checkout))
+}
+
+func TestCheckOutAttendance_NoCheckin(t *testing.T) {
+	_, h := setupCheckOutHandler(t, 0)
+
+	rec := checkOut(t, h)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "no check-in record found for today")
+}
+
+// Tests for overtime derived on checkout
+
+// setupDerivingCheckOutHandler is setupCheckOutHandler with overtime derived beyond an 8 hour day
+func setupDerivingCheckOutHandler(t *testing.T, checkedInAgo time.Duration) (*gorm.DB, *AttendanceHandler) {
+	db, h := setupCheckOutHandler(t, checkedInAgo)
+	require.NoError(t, db.AutoMigrate(&model.Overtime{}))
+	h.OvertimeRepo = repository.NewOvertimeRepository(db)
+	h.Overtime = config.OvertimeConfig{AutoDerive: true, StandardDayHours: 8}
+	return db, h
+}
+
+func TestCheckOutAttendance_DerivesPendingOvertime(t *testing.T) {
+	checkedInAgo := 10*time.Hour + 40*time.Minute
+	db, h := setupDerivingCheckOutHandler(t, checkedInAgo)
+
+	rec := checkOut(t, h)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Attendance checkout successful, 2h overtime pending approval")
+
+	var overtimes []model.Overtime
+	require.NoError(t, db.Find(&overtimes).Error)
+	require.Len(t, overtimes, 1)
+	assert.Equal(t, uint(1), overtimes[0].EmployeeID)
+	assert.Equal(t, 2, overtimes[0].Hours)
+	assert.Equal(t, model.OvertimePending, overtimes[0].Status)
+	assert.Equal(t, time.Now().Add(-checkedInAgo).Format("2006-01-02"), overtimes[0].OvertimeDate)
+}
+
+func TestCheckOutAttendance_DerivedOvertimeNotDuplicated(t *testing.T) {
+	checkedInAgo := 10*time.Hour + 40*time.Minute
+	db, h := setupDerivingCheckOutHandler(t, checkedInAgo)
+	date := time.Now().Add(-checkedInAgo).Format("2006-01-02")
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: date, Hours: 1, Reason: "Logged by hand", Status: model.OvertimeRejected}).Error)
+
+	rec := checkOut(t, h)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "overtime pending approval")
+
+	var count int64
+	require.NoError(t, db.Model(&model.Overtime{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCheckOutAttendance_NoOvertimeWhenDisabledOrShort(t *testing.T) {
+	// Disabled
+	db, h := setupDerivingCheckOutHandler(t, 11*time.Hour)
+	h.Overtime.AutoDerive = false
+	require.Equal(t, http.StatusOK, checkOut(t, h).Code)
+	var count int64
+	require.NoError(t, db.Model(&model.Overtime{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// Less than an hour beyond the standard day
+	db, h = setupDerivingCheckOutHandler(t, 8*time.Hour+50*time.Minute)
+	require.Equal(t, http.StatusOK, checkOut(t, h).Code)
+	require.NoError(t, db.Model(&model.Overtime{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestCheckOutAttendance_DerivedOvertimeCappedMonthly(t *testing.T) {
+	checkedInAgo := 11*time.Hour + 40*time.Minute
+	db, h := setupDerivingCheckOutHandler(t, checkedInAgo)
+	h.Overtime.MaxMonthlyHours = 10
+	checkin := time.Now().Add(-checkedInAgo)
+	// Another day of the same month, 8 of the 10 hours are used
+	other := checkin.AddDate(0, 0, 1)
+	if other.Month() != checkin.Month() {
+		other = checkin.AddDate(0, 0, -1)
+	}
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: other.Format("2006-01-02"), Hours: 8, Reason: "Logged by hand", Status: model.OvertimePending}).Error)
+
+	rec := checkOut(t, h)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Attendance checkout successful, 2h overtime pending approval")
+
+	var derived model.Overtime
+	require.NoError(t, db.Where("overtime_date = ?", checkin.Format("2006-01-02")).First(&derived).Error)
+	assert.Equal(t, 2, derived.Hours)
+	assert.Equal(t, 120, derived.DurationMinutes())
+}
+
+func TestCheckOutAttendance_NoDerivedOvertimeWhenCapUsedUp(t *testing.T) {
+	checkedInAgo := 11*time.Hour + 40*time.Minute
+	db, h := setupDerivingCheckOutHandler(t, checkedInAgo)
+	h.Overtime.MaxMonthlyHours = 8
+	checkin := time.Now().Add(-checkedInAgo)
+	other := checkin.AddDate(0, 0, 1)
+	if other.Month() != checkin.Month() {
+		other = checkin.AddDate(0, 0, -1)
+	}
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: other.Format("2006-01-02"), Hours: 8, Reason: "Logged by hand", Status: model.OvertimeApproved}).Error)
+
+	rec := checkOut(t, h)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "overtime pending approval")
+
+	var count int64
+	require.NoError(t, db.Model(&model.Overtime{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestDeriveOvertime_CheckoutPastMidnight(t *testing.T) {
+	jakarta := time.FixedZone("UTC+7", 7*60*60)
+	checkin := time.Date(2025, time.June, 2, 15, 0, 0, 0, jakarta)
+	checkout := time.Date(2025, time.June, 3, 2, 30, 0, 0, jakarta)
+	attendance := model.Attendance{EmployeeID: 2, Date: checkin, Checkin: checkin.UTC(), Checkout: &checkout, Status: "present"}
+
+	overtime, ok := deriveOvertime(attendance, 8, jakarta)
+
+	require.True(t, ok)
+	assert.Equal(t, "2025-06-02", overtime.OvertimeDate)
+	assert.Equal(t, "23:00", overtime.StartTime)
+	assert.Equal(t, "02:00", overtime.EndTime)
+	assert.Equal(t, 3, overtime.Hours)
+	// The times wrap past midnight, the hours are kept
+	assert.Equal(t, 180, overtime.DurationMinutes())
+
+	// Absent days and open attendance derive nothing
+	attendance.Status = "absent"
+	_, ok = deriveOvertime(attendance, 8, jakarta)
+	assert.False(t, ok)
+	attendance.Status = "present"
+	attendance.Checkout = nil
+	_, ok = deriveOvertime(attendance, 8, jakarta)
+	assert.False(t, ok)
+}
+
+// Tests for duplicate check-ins
+
+// checkIn runs CheckinAttendancePeriod for employee 1
+func checkIn(t *testing.T, h *AttendanceHandler) *httptest.ResponseRecorder {
+	return checkInWithBody(t, h, `{"employee_id": 1}`)
+}
+
+// checkInWithBody runs CheckinAttendancePeriod with the given request body
+func checkInWithBody(t *testing.T, h *AttendanceHandler, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/attendance/check-in", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	require.NoError(t, h.CheckinAttendancePeriod(c))
+	return rec
+}
+
+func TestCheckinAttendancePeriod_SecondCheckinConflicts(t *testing.T) {
+	db, h := setupCheckOutHandler(t, 0)
+	h.Helper.TimeLocation = time.FixedZone("UTC+7", 7*60*60)
+
+	rec := checkIn(t, h)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var first model.Attendance
+	require.NoError(t, db.First(&first).Error)
+
+	// The second check-in of the day is refused with the time of the first
+	rec = checkIn(t, h)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Already checked in today at "+first.Checkin.In(h.Helper.TimeLocation).Format("15:04"))
+
+	var count int64
+	require.NoError(t, db.Model(&model.Attendance{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCheckinAttendancePeriod_Status(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantStored string
+	}{
+		{name: "present when empty", body: `{"employee_id": 1}`, wantStatus: http.StatusOK, wantStored: model.AttendanceStatusPresent},
+		{name: "half day", body: `{"employee_id": 1, "status": "half_day"}`, wantStatus: http.StatusOK, wantStored: model.AttendanceStatusHalfDay},
+		{name: "late", body: `{"employee_id": 1, "status": "LATE"}`, wantStatus: http.StatusOK, wantStored: model.AttendanceStatusLate},
+		{name: "unknown status refused", body: `{"employee_id": 1, "status": "sick"}`, wantStatus: http.StatusBadRequest},
+		{name: "holiday is not a check-in status", body: `{"employee_id": 1, "status": "holiday"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, h := setupCheckOutHandler(t, 0)
+
+			rec := checkInWithBody(t, h, tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var attendances []model.Attendance
+			require.NoError(t, db.Find(&attendances).Error)
+			if tt.wantStatus != http.StatusOK {
+				assert.Empty(t, attendances)
+				return
+			}
+			require.Len(t, attendances, 1)
+			assert.Equal(t, tt.wantStored, attendances[0].Status)
+		})
+	}
+}
+
+func TestCheckinAttendancePeriod_Geofence(t *testing.T) {
+	office := config.GeofenceConfig{Enabled: true, Fences: []config.Geofence{
+		{Name: "warehouse", Latitude: -6.3000, Longitude: 106.9000, RadiusMeters: 300},
+		{Name: "office", Latitude: -6.2000, Longitude: 106.8166, RadiusMeters: 200},
+	}}
+
+	tests := []struct {
+		name        string
+		geofence    config.GeofenceConfig
+		body        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "disabled accepts anywhere", body: `{"employee_id": 1, "latitude": 51.5074, "longitude": -0.1278}`, wantStatus: http.StatusOK},
+		{name: "disabled accepts no location", body: `{"employee_id": 1}`, wantStatus: http.StatusOK},
+		{name: "inside a fence", geofence: office, body: `{"employee_id": 1, "latitude": -6.2005, "longitude": 106.8170}`, wantStatus: http.StatusOK},
+		{name: "outside every fence", geofence: office, body: `{"employee_id": 1, "latitude": -6.2100, "longitude": 106.8166}`, wantStatus: http.StatusBadRequest, wantMessage: "m from office"},
+		{name: "location required", geofence: office, body: `{"employee_id": 1}`, wantStatus: http.StatusBadRequest, wantMessage: "Location is required"},
+		{name: "no fences configured", geofence: config.GeofenceConfig{Enabled: true}, body: `{"employee_id": 1, "latitude": -6.2, "longitude": 106.8166}`, wantStatus: http.StatusBadRequest},
+		{name: "latitude without longitude", body: `{"employee_id": 1, "latitude": -6.2}`, wantStatus: http.StatusBadRequest, wantMessage: "sent together"},
+		{name: "latitude out of range", body: `{"employee_id": 1, "latitude": 91, "longitude": 106.8}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, h := setupCheckOutHandler(t, 0)
+			h.Geofence = tt.geofence
+
+			rec := checkInWithBody(t, h, tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Contains(t, rec.Body.String(), tt.wantMessage)
+
+			var attendances []model.Attendance
+			require.NoError(t, db.Find(&attendances).Error)
+			if tt.wantStatus != http.StatusOK {
+				assert.Empty(t, attendances)
+				return
+			}
+			// The location is stored for auditing whether or not it was checked
+			require.Len(t, attendances, 1)
+			var body struct {
+				Latitude *float64 `json:"latitude"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &body))
+			assert.Equal(t, body.Latitude, attendances[0].Latitude)
+		})
+	}
+}
+
+func TestHaversineMeters(t *testing.T) {
+	// Jakarta to Bandung is about 116 km as the crow flies
+	assert.InDelta(t, 116000, helper.HaversineMeters(-6.2088, 106.8456, -6.9175, 107.6191), 2000)
+	assert.Zero(t, helper.HaversineMeters(-6.2, 106.8, -6.2, 106.8))
+	// A thousandth of a degree of latitude is about 111 m
+	assert.InDelta(t, 111.2, helper.HaversineMeters(0, 0, 0.001, 0), 0.5)
+}
+
+// getAttendance runs GetAttendance for the attendance ID as the given caller
+func getAttendance(t *testing.T, h *AttendanceHandler, id string, role string, userID uint) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/attendance/"+id, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, h.GetAttendance(c))
+	return rec
+}
+
+func TestGetAttendance_WithOvertimeLinkage(t *testing.T) {
+	db, h := setupCheckOutHandler(t, 0)
+	require.NoError(t, db.AutoMigrate(&model.Overtime{}))
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	attendance := &model.Attendance{EmployeeID: 1, Date: day, Checkin: day.Add(9 * time.Hour), Status: "present"}
+	require.NoError(t, db.Create(attendance).Error)
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: "2025-06-02", Hours: 2, Reason: "Release support", Status: model.OvertimePending}).Error)
+	id := strconv.FormatUint(uint64(attendance.ID), 10)
+
+	rec := getAttendance(t, h, id, "employee", 1)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data res.AttendanceWithOvertime `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "2025-06-02", body.Data.Date)
+	assert.True(t, body.Data.Open)
+	require.Len(t, body.Data.Overtimes, 1)
+	assert.Equal(t, "pending", body.Data.Overtimes[0].Status)
+	assert.Equal(t, 2, body.Data.Overtimes[0].Hours)
+
+	// Admins see any attendance, other employees are refused
+	assert.Equal(t, http.StatusOK, getAttendance(t, h, id, "admin", 9).Code)
+	assert.Equal(t, http.StatusForbidden, getAttendance(t, h, id, "employee", 2).Code)
+
+	assert.Equal(t, http.StatusNotFound, getAttendance(t, h, "999", "admin", 9).Code)
+	assert.Equal(t, http.StatusBadRequest, getAttendance(t, h, "abc", "admin", 9).Code)
+}
+
+// Tests for GetDailyAttendanceSummary
+
+// dailySummary runs GetDailyAttendanceSummary for the query as the given caller
+func dailySummary(t *testing.T, h *AttendanceHandler, query string, role string, userID uint) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/attendance/summary?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, h.GetDailyAttendanceSummary(c))
+	return rec
+}
+
+// decodeDailySummary decodes the summary in the data of a success response
+func decodeDailySummary(t *testing.T, rec *httptest.ResponseRecorder) res.DailyAttendanceSummary {
+	var body struct {
+		Data res.DailyAttendanceSummary `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Data
+}
+
+func TestGetDailyAttendanceSummary_Admin(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+	h.Helper.TimeLocation = time.UTC
+
+	// Eddie checked in late on June 3rd, the manager and Olga have no attendance
+	rec := dailySummary(t, h, "date=2025-06-03", "admin", 99)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	summary := decodeDailySummary(t, rec)
+	assert.Equal(t, "2025-06-03", summary.Date)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 0, summary.Present)
+	assert.Equal(t, 1, summary.Late)
+	assert.Equal(t, 2, summary.Absent)
+	require.Len(t, summary.Employees, 3)
+
+	assert.Equal(t, "Maria Manager", summary.Employees[0].EmployeeName)
+	assert.Equal(t, res.DailyStatusAbsent, summary.Employees[0].Status)
+	assert.Nil(t, summary.Employees[0].Checkin)
+
+	eddie := summary.Employees[1]
+	assert.Equal(t, uint(2), eddie.EmployeeID)
+	assert.Equal(t, res.DailyStatusLate, eddie.Status)
+	assert.Equal(t, model.AttendanceStatusPresent, eddie.RecordedStatus)
+	require.NotNil(t, eddie.Checkin)
+	assert.Equal(t, "10:30", eddie.Checkin.Format("15:04"))
+	require.NotNil(t, eddie.Checkout)
+	assert.Equal(t, "18:30", eddie.Checkout.Format("15:04"))
+	assert.Equal(t, 8, eddie.HoursWorked)
+	assert.False(t, eddie.Open)
+
+	// June 5th Eddie and Olga are present, an absent record counts as absent on June 6th
+	summary = decodeDailySummary(t, dailySummary(t, h, "date=2025-06-05", "admin", 99))
+	assert.Equal(t, 2, summary.Present)
+	assert.Equal(t, 1, summary.Absent)
+	summary = decodeDailySummary(t, dailySummary(t, h, "date=2025-06-06", "admin", 99))
+	assert.Equal(t, 3, summary.Absent)
+	assert.Equal(t, model.AttendanceStatusAbsent, summary.Employees[1].RecordedStatus)
+}
+
+func TestGetDailyAttendanceSummary_ManagerScope(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+	h.Helper.TimeLocation = time.UTC
+
+	summary := decodeDailySummary(t, dailySummary(t, h, "date=2025-06-05", "manager", 1))
+
+	assert.Equal(t, 2, summary.Total)
+	assert.Equal(t, 1, summary.Present)
+	assert.Equal(t, 1, summary.Absent)
+	for _, entry := range summary.Employees {
+		assert.NotEqual(t, "Olga Other", entry.EmployeeName)
+	}
+}
+
+func TestGetDailyAttendanceSummary_ManagerScopeThroughRoute(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+	h.Helper.TimeLocation = time.UTC
+
+	// The route's ManagerOrAdmin after only the role and user ID HeaderMiddleware's JWT claims provide
+	e := echo.New()
+	e.GET("/attendance/summary", h.GetDailyAttendanceSummary, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("role", "manager")
+			c.Set("user_id", 1)
+			return next(c)
+		}
+	}, middleware.ManagerOrAdmin(h.Response))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/attendance/summary?date=2025-06-05", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	summary := decodeDailySummary(t, rec)
+	assert.Equal(t, 2, summary.Total, "the manager and their report, not an empty scope")
+	assert.Equal(t, 1, summary.Present)
+}
+
+func TestGetDailyAttendanceSummary_InvalidDate(t *testing.T) {
+	_, h := setupAnomalyHandler(t)
+
+	for _, query := range []string{"", "date=2025-13-01", "date=03-06-2025"} {
+		rec := dailySummary(t, h, query, "admin", 99)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+// Tests for BackfillAttendance
+
+// backfill runs BackfillAttendance with the body as admin 9
+func backfill(t *testing.T, h *AttendanceHandler, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/attendance/backfill", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", 9)
+
+	require.NoError(t, h.BackfillAttendance(c))
+	return rec
+}
+
+// setupBackfillHandler stores employee 1 on a handler taking days in UTC+7
+func setupBackfillHandler(t *testing.T) (*gorm.DB, *AttendanceHandler) {
+	db, h := setupCheckOutHandler(t, 0)
+	h.Helper.TimeLocation = time.FixedZone("UTC+7", 7*60*60)
+	return db, h
+}
+
+func TestBackfillAttendance_CreatesPastDay(t *testing.T) {
+	db, h := setupBackfillHandler(t)
+
+	rec := backfill(t, h, `{"employee_id":1,"date":"2025-06-02","checkin":"08:50","checkout":"17:20","status":"late"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var stored model.Attendance
+	require.NoError(t, db.First(&stored).Error)
+	assert.Equal(t, "late", stored.Status)
+	assert.Equal(t, 9, stored.HoursWorked) // 8h30 rounded
+	assert.True(t, time.Date(2025, time.June, 2, 1, 50, 0, 0, time.UTC).Equal(stored.Checkin))
+	require.NotNil(t, stored.Checkout)
+	assert.True(t, time.Date(2025, time.June, 2, 10, 20, 0, 0, time.UTC).Equal(*stored.Checkout))
+	require.NotNil(t, stored.CreatedBy)
+	assert.Equal(t, uint(9), *stored.CreatedBy)
+
+	// The day now has an attendance, for a backfill as for a check-in
+	rec = backfill(t, h, `{"employee_id":1,"date":"2025-06-02","checkin":"09:00","checkout":"17:00"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Attendance already recorded for 2025-06-02")
+	assert.Contains(t, rec.Body.String(), `"attendance_id":1`)
+}
+
+func TestBackfillAttendance_Rejected(t *testing.T) {
+	db, h := setupBackfillHandler(t)
+	tomorrow := time.Now().In(h.Helper.TimeLocation).AddDate(0, 0, 1).Format("2006-01-02")
+
+	cases := map[string]struct {
+		body string
+		code int
+	}{
+		"future date":      {`{"employee_id":1,"date":"` + tomorrow + `","checkin":"09:00","checkout":"17:00"}`, http.StatusBadRequest},
+		"checkout first":   {`{"employee_id":1,"date":"2025-06-02","checkin":"17:00","checkout":"09:00"}`, http.StatusBadRequest},
+		"malformed date":   {`{"employee_id":1,"date":"02-06-2025","checkin":"09:00","checkout":"17:00"}`, http.StatusBadRequest},
+		"malformed time":   {`{"employee_id":1,"date":"2025-06-02","checkin":"9am","checkout":"17:00"}`, http.StatusBadRequest},
+		"invalid status":   {`{"employee_id":1,"date":"2025-06-02","checkin":"09:00","checkout":"17:00","status":"holiday"}`, http.StatusBadRequest},
+		"missing employee": {`{"date":"2025-06-02","checkin":"09:00","checkout":"17:00"}`, http.StatusBadRequest},
+		"unknown employee": {`{"employee_id":42,"date":"2025-06-02","checkin":"09:00","checkout":"17:00"}`, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		rec := backfill(t, h, tc.body)
+		assert.Equal(t, tc.code, rec.Code, name)
+	}
+
+	var count int64
+	require.NoError(t, db.Model(&model.Attendance{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// Tests for ImportAttendance
+
+// setupImportHandler stores employees 1 and 2 on a handler taking days in UTC+7
+func setupImportHandler(t *testing.T) (*gorm.DB, *AttendanceHandler) {
+	db, h := setupBackfillHandler(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "Olga Other", Password: "hashed", Role: "employee", Active: true}).Error)
+	h.EmployeeRepo = repository.NewEmployeeRepository(db)
+	return db, h
+}
+
+// importAttendance runs ImportAttendance with the CSV as admin 9 and decodes the report of a success
+func importAttendance(t *testing.T, h *AttendanceHandler, csv string) res.AttendanceImportResult {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "device.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(csv))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/attendance/import", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", 9)
+	require.NoError(t, h.ImportAttendance(c))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var decoded struct {
+		Data res.AttendanceImportResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	return decoded.Data
+}
+
+// importedAttendances returns the stored attendance in employee and check-in order
+func importedAttendances(t *testing.T, db *gorm.DB) []model.Attendance {
+	var attendances []model.Attendance
+	require.NoError(t, db.Order("employee_id, checkin").Find(&attendances).Error)
+	return attendances
+}
+
+func TestImportAttendance_PairsEvents(t *testing.T) {
+	db, h := setupImportHandler(t)
+
+	// Employee 1 takes a lunch break on June 2nd and works a night shift from June 3rd, the rows out of order
+	result := importAttendance(t, h, `employee_id,timestamp,direction
+1,2025-06-02 12:00,out
+1,2025-06-02 08:00,IN
+2,2025-06-02 09:00:00,in
+1,2025-06-02 13:00,in
+1,2025-06-02 17:30,out
+2,2025-06-02T10:00:00Z,out
+1,2025-06-03T22:00:00+07:00,in
+1,2025-06-04 06:10,out
+`)
+
+	assert.Equal(t, 8, result.Total)
+	assert.Equal(t, 8, result.Imported)
+	assert.Zero(t, result.Warnings)
+	assert.Equal(t, 3, result.AttendancesCreated)
+	assert.Equal(t, "in", result.Rows[1].Direction)
+
+	attendances := importedAttendances(t, db)
+	require.Len(t, attendances, 3)
+	june2 := attendances[0]
+	assert.True(t, time.Date(2025, time.June, 2, 1, 0, 0, 0, time.UTC).Equal(june2.Checkin))
+	require.NotNil(t, june2.Checkout)
+	assert.True(t, time.Date(2025, time.June, 2, 10, 30, 0, 0, time.UTC).Equal(*june2.Checkout))
+	assert.Equal(t, 10, june2.HoursWorked) // 9h30 rounded, the break included as for a check-in
+	assert.Equal(t, model.AttendanceStatusPresent, june2.Status)
+	require.NotNil(t, june2.CreatedBy)
+	assert.Equal(t, uint(9), *june2.CreatedBy)
+	for _, index := range []int{0, 1, 3, 4} {
+		assert.Equal(t, june2.ID, result.Rows[index].AttendanceID, result.Rows[index].Row)
+	}
+
+	// The night shift belongs to the day it started
+	night := attendances[1]
+	assert.Equal(t, 8, night.HoursWorked)
+	assert.True(t, time.Date(2025, time.June, 3, 15, 0, 0, 0, time.UTC).Equal(night.Checkin))
+
+	assert.Equal(t, uint(2), attendances[2].EmployeeID)
+	assert.Equal(t, 8, attendances[2].HoursWorked)
+}
+
+func TestImportAttendance_UnmatchedEvents(t *testing.T) {
+	db, h := setupImportHandler(t)
+
+	result := importAttendance(t, h, `employee_id,timestamp,direction
+1,2025-06-02 08:00,in
+1,2025-06-03 08:00,in
+1,2025-06-03 08:02,in
+1,2025-06-03 17:00,out
+1,2025-06-04 17:00,out
+`)
+
+	assert.Equal(t, 3, result.Imported)
+	assert.Equal(t, 2, result.Skipped)
+	assert.Zero(t, result.Failed)
+	assert.Equal(t, 2, result.Warnings)
+	assert.Equal(t, 2, result.AttendancesCreated)
+
+	// An in without an out still records its day, without checkout
+	assert.Equal(t, res.AttendanceImportRowImported, result.Rows[0].Status)
+	assert.Equal(t, "in without a matching out", result.Rows[0].Warning)
+	assert.Equal(t, res.AttendanceImportRowSkipped, result.Rows[2].Status)
+	assert.Equal(t, "repeated in, already in since row 3", result.Rows[2].Reason)
+	assert.Equal(t, res.AttendanceImportRowSkipped, result.Rows[4].Status)
+	assert.Equal(t, "out without a matching in", result.Rows[4].Warning)
+	assert.Zero(t, result.Rows[4].AttendanceID)
+
+	attendances := importedAttendances(t, db)
+	require.Len(t, attendances, 2)
+	assert.Nil(t, attendances[0].Checkout)
+	assert.Zero(t, attendances[0].HoursWorked)
+	require.NotNil(t, attendances[1].Checkout)
+	assert.Equal(t, 9, attendances[1].HoursWorked)
+}
+
+func TestImportAttendance_DeduplicatesExistingDays(t *testing.T) {
+	db, h := setupImportHandler(t)
+	loc := h.Helper.TimeLocation
+	existing := &model.Attendance{EmployeeID: 1, Date: time.Date(2025, time.June, 2, 0, 0, 0, 0, loc), Checkin: time.Date(2025, time.June, 2, 9, 0, 0, 0, loc), Status: "present"}
+	require.NoError(t, db.Create(existing).Error)
+	file := `employee_id,timestamp,direction
+1,2025-06-02 08:00,in
+1,2025-06-02 17:00,out
+1,2025-06-03 08:00,in
+1,2025-06-03 08:00,in
+1,2025-06-03 16:00,out
+`
+
+	result := importAttendance(t, h, file)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, 3, result.Skipped)
+	assert.Equal(t, 1, result.AttendancesCreated)
+	assert.Equal(t, "attendance already recorded for 2025-06-02", result.Rows[0].Reason)
+	assert.Equal(t, existing.ID, result.Rows[1].AttendanceID)
+	assert.Equal(t, "duplicate of row 4", result.Rows[3].Reason)
+	assert.Len(t, importedAttendances(t, db), 2)
+
+	// The same file imported again creates nothing
+	result = importAttendance(t, h, file)
+	assert.Zero(t, result.Imported)
+	assert.Equal(t, 5, result.Skipped)
+	assert.Zero(t, result.AttendancesCreated)
+	assert.Len(t, importedAttendances(t, db), 2)
+}
+
+func TestImportAttendance_FailedRows(t *testing.T) {
+	db, h := setupImportHandler(t)
+	tomorrow := time.Now().In(h.Helper.TimeLocation).AddDate(0, 0, 1).Format("2006-01-02")
+
+	result := importAttendance(t, h, `employee_id,timestamp,direction
+abc,2025-06-02 08:00,in
+42,2025-06-02 08:00,in
+1,02/06/2025 08:00,in
+1,2025-06-02 08:00,enter
+1,`+tomorrow+` 08:00,in
+1,2025-06-02 08:00,in,extra
+1,2025-06-02 08:00,in
+1,2025-06-02 17:00,out
+`)
+
+	assert.Equal(t, 8, result.Total)
+	assert.Equal(t, 6, result.Failed)
+	assert.Equal(t, 2, result.Imported)
+	for i, reason := range []string{
+		`invalid employee_id "abc"`,
+		"employee 42 not found",
+		`invalid timestamp "02/06/2025 08:00", expected RFC 3339 or YYYY-MM-DD HH:MM:SS`,
+		`invalid direction "enter", expected in or out`,
+		"timestamp is in the future",
+		"record on line 7: wrong number of fields",
+	} {
+		assert.Equal(t, res.AttendanceImportRowFailed, result.Rows[i].Status, i)
+		assert.Equal(t, reason, result.Rows[i].Reason, i)
+	}
+	assert.Len(t, importedAttendances(t, db), 1)
+}
+
+func TestImportAttendance_InvalidFile(t *testing.T) {
+	_, h := setupImportHandler(t)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/attendance/import", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ImportAttendance(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "device.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("employee_id,time,direction\n1,2025-06-02 08:00,in\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	req = httptest.NewRequest(http.MethodPost, "/attendance/import", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.ImportAttendance(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "CSV header is missing the timestamp column")
+}
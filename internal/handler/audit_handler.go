@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
+)
+
+// AuditHandler handles audit log requests.
+type AuditHandler struct {
+	Helper   helper.NewHelper
+	DB       *gorm.DB
+	Response response.Interface
+
+	AuditLogRepo repository.AuditLogRepository
+}
+
+// ListAuditLogs lists the audit trail, newest first, filtered by the optional actor_id, table, action,
+// start_date and end_date (YYYY-MM-DD) query params and paginated with page and limit.
+func (h *AuditHandler) ListAuditLogs(c echo.Context) error {
+	filter, message, err := parseAuditLogFilter(c)
+	if message != "" {
+		return h.Response.SendBadRequest(c, message, errorDetail(err))
+	}
+	filter.Table = c.QueryParam("table")
+
+	page, limit, message, err := auditLogPage(c)
+	if message != "" {
+		return h.Response.SendBadRequest(c, message, errorDetail(err))
+	}
+
+	logs, total, err := h.AuditLogRepo.ListAuditLogs(filter, (page-1)*limit, limit)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve audit logs", err.Error())
+	}
+	return h.sendAuditLogPage(c, logs, total, page, limit, "Audit logs retrieved successfully")
+}
+
+// GetRecordHistory lists the changes of one record, e.g. /audit/employees/1 for the salary changes of an employee,
+// oldest first with who made each change and when. Filtered by the optional actor_id, action, field (a column the
+// change touches), start_date and end_date (YYYY-MM-DD) query params and paginated with page and limit.
+func (h *AuditHandler) GetRecordHistory(c echo.Context) error {
+	table := c.Param("table")
+	if !auditIdentifier.MatchString(table) {
+		return h.Response.SendBadRequest(c, "Invalid table name", nil)
+	}
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid record ID", err.Error())
+	}
+
+	filter, message, err := parseAuditLogFilter(c)
+	if message != "" {
+		return h.Response.SendBadRequest(c, message, errorDetail(err))
+	}
+	filter.Field = c.QueryParam("field")
+	if filter.Field != "" && !auditIdentifier.MatchString(filter.Field) {
+		return h.Response.SendBadRequest(c, "Invalid field, expected a column name", nil)
+	}
+
+	page, limit, message, err := auditLogPage(c)
+	if message != "" {
+		return h.Response.SendBadRequest(c, message, errorDetail(err))
+	}
+
+	logs, total, err := h.AuditLogRepo.ListRecordHistory(table, uint(recordID), filter, (page-1)*limit, limit)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve record history", err.Error())
+	}
+	return h.sendAuditLogPage(c, logs, total, page, limit, "Record history retrieved successfully")
+}
+
+// auditIdentifier matches table and column names
+var auditIdentifier = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// parseAuditLogFilter reads the actor_id, action, start_date and end_date query params,
+// returning the message of the first invalid one with its cause
+func parseAuditLogFilter(c echo.Context) (request.AuditLogFilter, string, error) {
+	var filter request.AuditLogFilter
+	if value := c.QueryParam("actor_id"); value != "" {
+		actorID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return filter, "Invalid actor_id", err
+		}
+		id := uint(actorID)
+		filter.ActorID = &id
+	}
+
+	filter.Action = c.QueryParam("action")
+	switch filter.Action {
+	case "", model.AuditActionCreate, model.AuditActionUpdate, model.AuditActionDelete:
+	default:
+		return filter, "Invalid action, expected create, update or delete", nil
+	}
+
+	if value := c.QueryParam("start_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, "Invalid start_date, expected YYYY-MM-DD", err
+		}
+		filter.StartDate = &parsed
+	}
+	if value := c.QueryParam("end_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, "Invalid end_date, expected YYYY-MM-DD", err
+		}
+		filter.EndDate = &parsed
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return filter, "end_date must not be before start_date", nil
+	}
+	return filter, "", nil
+}
+
+// auditLogPage reads the page (default 1) and limit (default 20, at most 100) query params,
+// returning the message of an invalid one with its cause
+func auditLogPage(c echo.Context) (int, int, string, error) {
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return 0, 0, "Invalid page, expected a positive number", err
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return 0, 0, "Invalid limit, expected a positive number", err
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit, "", nil
+}
+
+// errorDetail returns the message of err for a response, nil without an error
+func errorDetail(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return err.Error()
+}
+
+// sendAuditLogPage answers with a page of audit log entries and their changes
+func (h *AuditHandler) sendAuditLogPage(c echo.Context, logs []model.AuditLog, total int64, page int, limit int, message string) error {
+	records := make([]res.AuditLogEntry, 0, len(logs))
+	for _, log := range logs {
+		changes := json.RawMessage(log.Changes)
+		if !json.Valid(changes) {
+			changes = json.RawMessage("{}")
+		}
+		records = append(records, res.AuditLogEntry{
+			ID:        log.ID,
+			Table:     log.Table,
+			RecordID:  log.RecordID,
+			Action:    log.Action,
+			ActorID:   log.ActorID,
+			CreatedAt: log.CreatedAt,
+			Changes:   changes,
+		})
+	}
+
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.Response.SendPaginationResponse(c, records, message, total, int64(limit), int64(len(records)), totalPage, page)
+}
@@ -0,0 +1,139 @@
+// Package handler contains unit tests for the audit handler functionality.
+//
+// ListAuditLogs tests cover (real handler on an in-memory database):
+// 1. Salary changes made through the auditable write path listed for an actor
+// 2. Malformed ids, actions and dates rejected with 400
+//
+// GetRecordHistory tests cover (real handler on an in-memory database):
+// 1. The salary changes of one employee listed oldest first with their actor
+// 2. Malformed table names, record ids, fields and filters rejected with 400
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// listAuditLogs runs the real ListAuditLogs with the query after admin 9 raised the salary of employee 1
+func listAuditLogs(t *testing.T, query string) *httptest.ResponseRecorder {
+	db, _ := setupPayrollHandlerDB(t)
+	employee := &model.Employee{Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, BasicSalary: 5000000}
+	require.NoError(t, middleware.NewAuditableDB(db, 9).Create(employee).Error)
+	employee.BasicSalary = 6500000
+	require.NoError(t, middleware.NewAuditableDB(db, 9).Save(employee).Error)
+
+	h := &AuditHandler{
+		Response:     response.NewResponse(),
+		AuditLogRepo: repository.NewAuditLogRepository(db),
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/audit?"+query, nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ListAuditLogs(e.NewContext(req, rec)))
+	return rec
+}
+
+func TestAuditHandler_ListAuditLogs_SalaryChange(t *testing.T) {
+	rec := listAuditLogs(t, "actor_id=9&table=employees&action=update")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data struct {
+			Records     []res.AuditLogEntry `json:"records"`
+			TotalRecord int64               `json:"total_record"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data.Records, 1)
+	assert.Equal(t, int64(1), body.Data.TotalRecord)
+
+	entry := body.Data.Records[0]
+	assert.Equal(t, uint(9), entry.ActorID)
+	assert.Equal(t, uint(1), entry.RecordID)
+	assert.JSONEq(t, `{"basic_salary":{"old":5000000,"new":6500000}}`, string(entry.Changes))
+}
+
+func TestAuditHandler_ListAuditLogs_InvalidFilters(t *testing.T) {
+	for _, query := range []string{"actor_id=abc", "action=purge", "start_date=2025-06-31", "start_date=2025-06-10&end_date=2025-06-01"} {
+		rec := listAuditLogs(t, query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+// getRecordHistory runs the real GetRecordHistory for a table and record with the query after admin 9
+// created employee 1 and raised its salary twice, and renamed employee 2
+func getRecordHistory(t *testing.T, table string, id string, query string) *httptest.ResponseRecorder {
+	db, _ := setupPayrollHandlerDB(t)
+	auditDB := middleware.NewAuditableDB(db, 9)
+	employee := &model.Employee{Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, BasicSalary: 5000000}
+	require.NoError(t, auditDB.Create(employee).Error)
+	other := &model.Employee{Name: "John Doe", Password: "hashed", Role: "employee", Active: true, BasicSalary: 4000000}
+	require.NoError(t, auditDB.Create(other).Error)
+	for _, salary := range []float64{6000000, 6500000} {
+		employee.BasicSalary = salary
+		require.NoError(t, auditDB.Save(employee).Error)
+	}
+	other.Name = "John Smith"
+	require.NoError(t, auditDB.Save(other).Error)
+
+	h := &AuditHandler{
+		Response:     response.NewResponse(),
+		AuditLogRepo: repository.NewAuditLogRepository(db),
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/audit/"+table+"/"+id+"?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("table", "id")
+	c.SetParamValues(table, id)
+	require.NoError(t, h.GetRecordHistory(c))
+	return rec
+}
+
+func TestAuditHandler_GetRecordHistory_SalaryChanges(t *testing.T) {
+	rec := getRecordHistory(t, "employees", "1", "field=basic_salary&action=update")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data struct {
+			Records     []res.AuditLogEntry `json:"records"`
+			TotalRecord int64               `json:"total_record"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data.Records, 2)
+	assert.Equal(t, int64(2), body.Data.TotalRecord)
+
+	for _, entry := range body.Data.Records {
+		assert.Equal(t, uint(1), entry.RecordID)
+		assert.Equal(t, uint(9), entry.ActorID)
+	}
+	assert.JSONEq(t, `{"basic_salary":{"old":5000000,"new":6000000}}`, string(body.Data.Records[0].Changes))
+	assert.JSONEq(t, `{"basic_salary":{"old":6000000,"new":6500000}}`, string(body.Data.Records[1].Changes))
+}
+
+func TestAuditHandler_GetRecordHistory_InvalidParams(t *testing.T) {
+	for _, params := range [][3]string{
+		{"Employees;", "1", ""},
+		{"employees", "abc", ""},
+		{"employees", "1", "field=basic%25"},
+		{"employees", "1", "action=purge"},
+		{"employees", "1", "limit=0"},
+	} {
+		rec := getRecordHistory(t, params[0], params[1], params[2])
+		assert.Equal(t, http.StatusBadRequest, rec.Code, params)
+	}
+}
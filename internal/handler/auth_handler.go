@@ -1,33 +1,54 @@
 package handler
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
 	dto_response "github.com/yourname/payslip-system/internal/dto/response"
+	"github.com/yourname/payslip-system/internal/helper"
 	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/mailer"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
 	"github.com/yourname/payslip-system/internal/repository"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
-	employeeRepo repository.EmployeeRepository
-	response     response.Interface
+	employeeRepo     repository.EmployeeRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	config           config.AuthConfig
+	response         response.Interface
+	// mailer delivers password reset tokens
+	mailer mailer.Mailer
 }
 
 // NewAuthHandler creates a new authentication handler
-func NewAuthHandler(employeeRepo repository.EmployeeRepository, response response.Interface) *AuthHandler {
+func NewAuthHandler(employeeRepo repository.EmployeeRepository, refreshTokenRepo repository.RefreshTokenRepository, authConfig config.AuthConfig, response response.Interface, mail mailer.Mailer) *AuthHandler {
 	return &AuthHandler{
-		employeeRepo: employeeRepo,
-		response:     response,
+		employeeRepo:     employeeRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		config:           authConfig,
+		response:         response,
+		mailer:           mail,
 	}
 }
 
-// Login authenticates a user and returns a JWT token
+// forgotPasswordMessage is answered to every forgot password request, so it does not reveal which accounts exist
+const forgotPasswordMessage = "If the account exists and has an email address, a password reset token has been sent"
+
+// Login authenticates a user and returns a short-lived access token and a refresh token
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req request.LoginRequest
 	if err := c.Bind(&req); err != nil {
@@ -39,17 +60,17 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return h.response.SendBadRequest(c, "Validation failed", err.Error())
 	}
 
-	// Find employee by name
-	employee, err := h.employeeRepo.GetEmployeeByName(req.Name)
+	// Find employee by name, deactivated and deleted employees cannot log in
+	employee, err := h.employeeRepo.GetActiveEmployeeByName(req.Name)
 	if err != nil {
+		// A deactivated employee is told why once the password matched, so guessing reveals nothing
+		inactive, lookupErr := h.employeeRepo.GetEmployeeByName(req.Name, false)
+		if lookupErr == nil && !inactive.Active && bcrypt.CompareHashAndPassword([]byte(inactive.Password), []byte(req.Password)) == nil {
+			return h.response.SendCustomResponse(c, http.StatusForbidden, "Account is deactivated, contact an administrator", nil)
+		}
 		return h.response.SendUnauthorized(c, "Invalid credentials", nil)
 	}
 
-	// Check if employee is active
-	if !employee.Active {
-		return h.response.SendUnauthorized(c, "Account is deactivated", nil)
-	}
-
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(employee.Password), []byte(req.Password)); err != nil {
 		return h.response.SendUnauthorized(c, "Invalid credentials", nil)
@@ -61,11 +82,27 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return h.response.SendError(c, "Failed to generate token", err.Error())
 	}
 
-	// Prepare response
-	loginResponse := dto_response.LoginResponse{
-		Token:     token,
-		TokenType: "Bearer",
-		ExpiresAt: expiresAt,
+	// Start a new refresh token family for this login
+	refreshToken, stored, err := h.newRefreshToken(employee.ID)
+	if err != nil {
+		return h.response.SendError(c, "Failed to generate refresh token", err.Error())
+	}
+	stored.FamilyID = helper.GenerateUUID().String()
+	if _, err := h.refreshTokenRepo.CreateRefreshToken(stored); err != nil {
+		return h.response.SendError(c, "Failed to store refresh token", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Login successful", h.buildLoginResponse(employee, token, expiresAt, refreshToken, stored.ExpiresAt))
+}
+
+// buildLoginResponse prepares the token response of a login or refresh
+func (h *AuthHandler) buildLoginResponse(employee *model.Employee, token string, expiresAt time.Time, refreshToken string, refreshExpiresAt time.Time) dto_response.LoginResponse {
+	return dto_response.LoginResponse{
+		Token:                 token,
+		TokenType:             "Bearer",
+		ExpiresAt:             expiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt,
 		User: dto_response.UserInfo{
 			ID:     employee.ID,
 			Name:   employee.Name,
@@ -73,23 +110,51 @@ func (h *AuthHandler) Login(c echo.Context) error {
 			Active: employee.Active,
 		},
 	}
+}
+
+// newRefreshToken generates a random refresh token, returning it with the (unsaved) record holding its hash
+func (h *AuthHandler) newRefreshToken(employeeID uint) (string, *model.RefreshToken, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
 
-	return h.response.SendSuccess(c, "Login successful", loginResponse)
+	return token, &model.RefreshToken{
+		EmployeeID: employeeID,
+		TokenHash:  hashToken(token),
+		ExpiresAt:  time.Now().Add(h.config.RefreshTokenTTL),
+	}, nil
+}
+
+// randomToken generates 32 random bytes, hex encoded
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// hashToken returns the hex SHA-256 hash refresh and password reset tokens are stored under
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // generateJWTToken creates a new JWT token for the authenticated user
 func (h *AuthHandler) generateJWTToken(employee *model.Employee) (string, time.Time, error) {
-	// Set token expiration time (24 hours)
-	expiresAt := time.Now().Add(24 * time.Hour)
+	// Access tokens are short-lived, clients renew them with the refresh token
+	expiresAt := time.Now().Add(h.config.AccessTokenTTL)
 
-	// Create token claims
+	// Create token claims, token_version is compared with the employee's current version on every request
 	claims := &jwt.MapClaims{
-		"user_id": employee.ID,
-		"name":    employee.Name,
-		"role":    employee.Role,
-		"active":  employee.Active,
-		"exp":     expiresAt.Unix(),
-		"iat":     time.Now().Unix(),
+		"user_id":       employee.ID,
+		"name":          employee.Name,
+		"role":          employee.Role,
+		"active":        employee.Active,
+		"exp":           expiresAt.Unix(),
+		"iat":           time.Now().Unix(),
+		"token_version": employee.TokenVersion,
 	}
 
 	// Create token with claims
@@ -122,22 +187,43 @@ func (h *AuthHandler) GetProfile(c echo.Context) error {
 	return h.response.SendSuccess(c, "Profile retrieved successfully", employee.ToSafe())
 }
 
-// RefreshToken generates a new token for the authenticated user
+// RefreshToken exchanges a refresh token for a new access token and rotates the refresh token.
+// A revoked token presented again was likely stolen, so its whole family is revoked.
 func (h *AuthHandler) RefreshToken(c echo.Context) error {
-	// Get user information from context
-	userID, ok := c.Get("user_id").(int)
-	if !ok {
-		return h.response.SendUnauthorized(c, "Invalid token", nil)
+	var req request.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequest(c, "Invalid request body", err.Error())
+	}
+	if req.RefreshToken == "" {
+		return h.response.SendBadRequest(c, "Refresh token is required", nil)
+	}
+
+	stored, err := h.refreshTokenRepo.GetRefreshTokenByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return h.response.SendUnauthorized(c, "Invalid refresh token", nil)
+		}
+		return h.response.SendError(c, "Failed to retrieve refresh token", err.Error())
+	}
+
+	if stored.IsRevoked() {
+		return h.rejectReusedRefreshToken(c, stored)
+	}
+	if stored.IsExpired(time.Now()) {
+		return h.response.SendUnauthorized(c, "Refresh token expired", nil)
 	}
 
 	// Get employee details
-	employee, err := h.employeeRepo.GetEmployeeByID(uint(userID))
+	employee, err := h.employeeRepo.GetEmployeeByID(stored.EmployeeID)
 	if err != nil {
 		return h.response.SendError(c, "User not found", err.Error())
 	}
 
 	// Check if employee is still active
 	if !employee.Active {
+		if err := h.refreshTokenRepo.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+			return h.response.SendError(c, "Failed to revoke refresh token", err.Error())
+		}
 		return h.response.SendUnauthorized(c, "Account is deactivated", nil)
 	}
 
@@ -147,18 +233,116 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		return h.response.SendError(c, "Failed to refresh token", err.Error())
 	}
 
-	// Prepare response
-	refreshResponse := dto_response.LoginResponse{
-		Token:     token,
-		TokenType: "Bearer",
-		ExpiresAt: expiresAt,
-		User: dto_response.UserInfo{
-			ID:     employee.ID,
-			Name:   employee.Name,
-			Role:   employee.Role,
-			Active: employee.Active,
-		},
+	refreshToken, next, err := h.newRefreshToken(employee.ID)
+	if err != nil {
+		return h.response.SendError(c, "Failed to generate refresh token", err.Error())
+	}
+	if _, err := h.refreshTokenRepo.RotateRefreshToken(stored, next); err != nil {
+		// A concurrent request rotated the token first
+		if err == repository.ErrRefreshTokenRevoked {
+			return h.rejectReusedRefreshToken(c, stored)
+		}
+		return h.response.SendError(c, "Failed to rotate refresh token", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Token refreshed successfully", h.buildLoginResponse(employee, token, expiresAt, refreshToken, next.ExpiresAt))
+}
+
+// rejectReusedRefreshToken revokes the family of a reused refresh token and denies the request
+func (h *AuthHandler) rejectReusedRefreshToken(c echo.Context, stored *model.RefreshToken) error {
+	if err := h.refreshTokenRepo.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+		return h.response.SendError(c, "Failed to revoke refresh token", err.Error())
+	}
+	return h.response.SendUnauthorized(c, "Refresh token has been revoked, please log in again", nil)
+}
+
+// Logout revokes the refresh token together with every token rotated from the same login
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req request.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequest(c, "Invalid request body", err.Error())
+	}
+	if req.RefreshToken == "" {
+		return h.response.SendBadRequest(c, "Refresh token is required", nil)
+	}
+
+	stored, err := h.refreshTokenRepo.GetRefreshTokenByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return h.response.SendUnauthorized(c, "Invalid refresh token", nil)
+		}
+		return h.response.SendError(c, "Failed to retrieve refresh token", err.Error())
+	}
+
+	if err := h.refreshTokenRepo.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+		return h.response.SendError(c, "Failed to revoke refresh token", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Logged out successfully", nil)
+}
+
+// ForgotPassword emails a single-use password reset token to an active employee with an email address.
+// The response is the same whether or not the account exists, failures are only logged.
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req request.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequest(c, "Invalid request body", err.Error())
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	employee, err := h.employeeRepo.GetActiveEmployeeByName(req.Name)
+	if err == nil && employee.HasEmail() {
+		if err := h.sendPasswordResetToken(employee); err != nil {
+			log.Printf("Failed to send password reset token to employee %d: %v", employee.ID, err)
+		}
+	}
+
+	return h.response.SendSuccess(c, forgotPasswordMessage, nil)
+}
+
+// sendPasswordResetToken stores a new password reset token for the employee and emails it
+func (h *AuthHandler) sendPasswordResetToken(employee *model.Employee) error {
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	stored := &model.PasswordResetToken{
+		EmployeeID: employee.ID,
+		TokenHash:  hashToken(token),
+		ExpiresAt:  time.Now().Add(h.config.PasswordResetTTL),
+	}
+	if err := h.employeeRepo.CreatePasswordResetToken(stored); err != nil {
+		return err
+	}
+
+	return h.mailer.Send(mailer.Message{
+		To:      employee.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Hello %s,\n\nUse this token to reset your password, it expires at %s and works once:\n\n%s\n\n"+
+			"If you did not ask for a password reset, ignore this email.\n",
+			employee.Name, stored.ExpiresAt.Format("2006-01-02 15:04 MST"), token),
+	})
+}
+
+// ResetPassword sets a new password with a password reset token, the token cannot be used again
+// and the refresh tokens of the employee are revoked
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req request.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequest(c, "Invalid request body", err.Error())
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	if _, err := h.employeeRepo.ResetPasswordWithToken(hashToken(req.Token), req.Password, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrPasswordResetTokenInvalid) {
+			return h.response.SendBadRequest(c, "Invalid or expired reset token", nil)
+		}
+		return h.response.SendError(c, "Failed to reset password", err.Error())
 	}
 
-	return h.response.SendSuccess(c, "Token refreshed successfully", refreshResponse)
+	return h.response.SendSuccess(c, "Password has been reset, please log in again", nil)
 }
@@ -1,19 +1,29 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	dto_response "github.com/yourname/payslip-system/internal/dto/response"
+	"github.com/yourname/payslip-system/internal/helper/response"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // Helper function to create a test employee
@@ -384,3 +394,330 @@ func TestAuthHandler_PasswordHashing(t *testing.T) {
 	err = bcrypt.CompareHashAndPassword(hashedPassword, []byte("wrongpassword"))
 	assert.Error(t, err)
 }
+
+// Tests for the refresh token flow (real handler on an in-memory database)
+
+// authTestValidator validates login requests like the server does
+type authTestValidator struct {
+	validator *validator.Validate
+}
+
+func (v *authTestValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+// setupAuthHandler creates the real auth handler with the active test employee stored
+func setupAuthHandler(t *testing.T) (*gorm.DB, *AuthHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Employee{}, &model.RefreshToken{}, &model.PasswordResetToken{}))
+	require.NoError(t, db.Create(createTestEmployee()).Error)
+
+	handler := NewAuthHandler(
+		repository.NewEmployeeRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		config.AuthConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour, PasswordResetTTL: 30 * time.Minute},
+		response.NewResponse(),
+		&recordingMailer{},
+	)
+	return db, handler
+}
+
+// postAuthJSON runs an auth handler with a JSON body
+func postAuthJSON(t *testing.T, handlerFunc echo.HandlerFunc, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = &authTestValidator{validator: validator.New()}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handlerFunc(e.NewContext(req, rec)))
+	return rec
+}
+
+// decodeTokens reads the token response of a login or refresh
+func decodeTokens(t *testing.T, rec *httptest.ResponseRecorder) dto_response.LoginResponse {
+	var body struct {
+		Data dto_response.LoginResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Data
+}
+
+// refreshRequest builds the refresh and logout request body
+func refreshRequest(refreshToken string) string {
+	return `{"refresh_token":"` + refreshToken + `"}`
+}
+
+// loginTestEmployee logs the test employee in and returns the issued tokens
+func loginTestEmployee(t *testing.T, handler *AuthHandler) dto_response.LoginResponse {
+	rec := postAuthJSON(t, handler.Login, `{"name":"testuser","password":"password123"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	return decodeTokens(t, rec)
+}
+
+func TestAuthHandler_Login_ReturnsAccessAndRefreshTokens(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+
+	tokens := loginTestEmployee(t, handler)
+
+	assert.NotEmpty(t, tokens.Token)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), tokens.ExpiresAt, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tokens.RefreshTokenExpiresAt, time.Minute)
+
+	// Only the hash is stored
+	var stored model.RefreshToken
+	require.NoError(t, db.First(&stored).Error)
+	assert.Equal(t, hashToken(tokens.RefreshToken), stored.TokenHash)
+	assert.NotEqual(t, tokens.RefreshToken, stored.TokenHash)
+}
+
+func TestAuthHandler_RefreshToken_RotatesToken(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+	login := loginTestEmployee(t, handler)
+
+	rec := postAuthJSON(t, handler.RefreshToken, refreshRequest(login.RefreshToken))
+	require.Equal(t, http.StatusOK, rec.Code)
+	refreshed := decodeTokens(t, rec)
+
+	assert.NotEmpty(t, refreshed.Token)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, uint(1), refreshed.User.ID)
+
+	// The old token is revoked and the new one belongs to the same family
+	old, err := handler.refreshTokenRepo.GetRefreshTokenByHash(hashToken(login.RefreshToken))
+	require.NoError(t, err)
+	assert.True(t, old.IsRevoked())
+	next, err := handler.refreshTokenRepo.GetRefreshTokenByHash(hashToken(refreshed.RefreshToken))
+	require.NoError(t, err)
+	assert.False(t, next.IsRevoked())
+	assert.Equal(t, old.FamilyID, next.FamilyID)
+
+	var count int64
+	require.NoError(t, db.Model(&model.RefreshToken{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestAuthHandler_RefreshToken_Expired(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+	login := loginTestEmployee(t, handler)
+	require.NoError(t, db.Model(&model.RefreshToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	rec := postAuthJSON(t, handler.RefreshToken, refreshRequest(login.RefreshToken))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Refresh token expired")
+}
+
+func TestAuthHandler_RefreshToken_ReuseRevokesFamily(t *testing.T) {
+	_, handler := setupAuthHandler(t)
+	login := loginTestEmployee(t, handler)
+	other := loginTestEmployee(t, handler)
+
+	rec := postAuthJSON(t, handler.RefreshToken, refreshRequest(login.RefreshToken))
+	require.Equal(t, http.StatusOK, rec.Code)
+	refreshed := decodeTokens(t, rec)
+
+	// Replaying the rotated token is rejected and revokes the token issued from it
+	rec = postAuthJSON(t, handler.RefreshToken, refreshRequest(login.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = postAuthJSON(t, handler.RefreshToken, refreshRequest(refreshed.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Other logins are not affected
+	rec = postAuthJSON(t, handler.RefreshToken, refreshRequest(other.RefreshToken))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuthHandler_RefreshToken_Unknown(t *testing.T) {
+	_, handler := setupAuthHandler(t)
+
+	rec := postAuthJSON(t, handler.RefreshToken, refreshRequest("not-a-token"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = postAuthJSON(t, handler.RefreshToken, `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAuthHandler_Logout_RevokesRefreshToken(t *testing.T) {
+	_, handler := setupAuthHandler(t)
+	login := loginTestEmployee(t, handler)
+
+	rec := postAuthJSON(t, handler.Logout, refreshRequest(login.RefreshToken))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = postAuthJSON(t, handler.RefreshToken, refreshRequest(login.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuthHandler_RefreshToken_RevokedByPasswordChange(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+	require.NoError(t, db.AutoMigrate(&model.AuditLog{}))
+	login := loginTestEmployee(t, handler)
+
+	rec := postAuthJSON(t, handler.RefreshToken, refreshRequest(login.RefreshToken))
+	require.Equal(t, http.StatusOK, rec.Code)
+	refreshed := decodeTokens(t, rec)
+
+	// A refresh token taken before the password change no longer issues access tokens
+	password := "newpassword456"
+	_, err := repository.NewEmployeeRepository(db).UpdateProfileWithAudit(1, request.UpdateProfileRequest{Password: &password}, middleware.NewAuditableDB(db, 1))
+	require.NoError(t, err)
+
+	rec = postAuthJSON(t, handler.RefreshToken, refreshRequest(refreshed.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Logging in with the new password works
+	rec = postAuthJSON(t, handler.Login, `{"name":"testuser","password":"newpassword456"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuthHandler_Login_DeactivatedEmployeeRejected(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+	require.NoError(t, db.Model(&model.Employee{}).Where("name = ?", "testuser").Update("active", false).Error)
+
+	rec := postAuthJSON(t, handler.Login, `{"name":"testuser","password":"password123"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Account is deactivated")
+
+	// A wrong password gets the usual answer, the account state is not revealed
+	rec = postAuthJSON(t, handler.Login, `{"name":"testuser","password":"wrongpassword"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "deactivated")
+
+	var count int64
+	require.NoError(t, db.Model(&model.RefreshToken{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// Tests for the password reset flow
+
+// requestPasswordReset asks for a reset token of the test employee with an email address and returns the mailed token
+func requestPasswordReset(t *testing.T, db *gorm.DB, handler *AuthHandler) string {
+	require.NoError(t, db.Model(&model.Employee{}).Where("name = ?", "testuser").Update("email", "test@example.com").Error)
+	sent := handler.mailer.(*recordingMailer)
+	before := len(sent.sent)
+
+	rec := postAuthJSON(t, handler.ForgotPassword, `{"name":"testuser"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, sent.sent, before+1)
+	assert.Equal(t, "test@example.com", sent.sent[before].To)
+
+	lines := strings.Split(strings.TrimSpace(sent.sent[before].Body), "\n\n")
+	require.GreaterOrEqual(t, len(lines), 3)
+	return lines[2]
+}
+
+// resetPasswordRequest builds the reset password request body
+func resetPasswordRequest(token string, password string) string {
+	return `{"token":"` + token + `","password":"` + password + `"}`
+}
+
+func TestAuthHandler_ForgotPassword_SameResponseForUnknownAccount(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+	require.NoError(t, db.Model(&model.Employee{}).Where("name = ?", "testuser").Update("email", "test@example.com").Error)
+
+	known := postAuthJSON(t, handler.ForgotPassword, `{"name":"testuser"}`)
+	unknown := postAuthJSON(t, handler.ForgotPassword, `{"name":"nobody"}`)
+
+	assert.Equal(t, http.StatusOK, known.Code)
+	assert.Equal(t, known.Code, unknown.Code)
+	assert.JSONEq(t, known.Body.String(), unknown.Body.String())
+	assert.Len(t, handler.mailer.(*recordingMailer).sent, 1)
+
+	// Only the hash of the token is stored
+	var stored model.PasswordResetToken
+	require.NoError(t, db.First(&stored).Error)
+	assert.Equal(t, uint(1), stored.EmployeeID)
+	assert.NotContains(t, handler.mailer.(*recordingMailer).sent[0].Body, stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), stored.ExpiresAt, time.Minute)
+}
+
+func TestAuthHandler_ResetPassword_SetsPasswordOnce(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+	login := loginTestEmployee(t, handler)
+	token := requestPasswordReset(t, db, handler)
+
+	rec := postAuthJSON(t, handler.ResetPassword, resetPasswordRequest(token, "newsecret"))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The new password logs in, the old one and the sessions opened with it do not
+	rec = postAuthJSON(t, handler.Login, `{"name":"testuser","password":"newsecret"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = postAuthJSON(t, handler.Login, `{"name":"testuser","password":"password123"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = postAuthJSON(t, handler.RefreshToken, refreshRequest(login.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// The token is single-use
+	rec = postAuthJSON(t, handler.ResetPassword, resetPasswordRequest(token, "othersecret"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid or expired reset token")
+}
+
+func TestAuthHandler_ResetPassword_RejectedTokens(t *testing.T) {
+	db, handler := setupAuthHandler(t)
+
+	// An expired token
+	expired := requestPasswordReset(t, db, handler)
+	require.NoError(t, db.Model(&model.PasswordResetToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	rec := postAuthJSON(t, handler.ResetPassword, resetPasswordRequest(expired, "newsecret"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// A token replaced by a newer request
+	replaced := requestPasswordReset(t, db, handler)
+	latest := requestPasswordReset(t, db, handler)
+	rec = postAuthJSON(t, handler.ResetPassword, resetPasswordRequest(replaced, "newsecret"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// An unknown token and a too short password
+	rec = postAuthJSON(t, handler.ResetPassword, resetPasswordRequest("not-a-token", "newsecret"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = postAuthJSON(t, handler.ResetPassword, resetPasswordRequest(latest, "short"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// The password is unchanged
+	rec = postAuthJSON(t, handler.Login, `{"name":"testuser","password":"password123"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// loginFrom runs Login behind the rate limit as a client with the given IP address
+func loginFrom(t *testing.T, handlerFunc echo.HandlerFunc, ip string, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = &authTestValidator{validator: validator.New()}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRealIP, ip)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handlerFunc(e.NewContext(req, rec)))
+	return rec
+}
+
+func TestAuthHandler_Login_RateLimited(t *testing.T) {
+	_, handler := setupAuthHandler(t)
+	login := middleware.RateLimit(middleware.NewRateLimiter(3, time.Minute), response.NewResponse(),
+		middleware.RateLimitByIP, middleware.RateLimitByJSONField("name"))(handler.Login)
+	wrongPassword := `{"name":"testuser","password":"wrongpassword"}`
+
+	for i := 0; i < 3; i++ {
+		rec := loginFrom(t, login, "203.0.113.7", wrongPassword)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+
+	// The fourth attempt is refused before the password is checked, the right one included
+	rec := loginFrom(t, login, "203.0.113.7", `{"name":"testuser","password":"password123"}`)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"RATE_LIMITED"`)
+	assert.Equal(t, "20", rec.Header().Get("Retry-After"))
+
+	// Another address guessing the same login name is limited by the name
+	rec = loginFrom(t, login, "198.51.100.4", wrongPassword)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Another login name from another address is not affected
+	rec = loginFrom(t, login, "198.51.100.4", `{"name":"someoneelse","password":"wrongpassword"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
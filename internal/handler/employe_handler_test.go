@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -8,10 +12,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // Helper function to create a test employee
@@ -130,7 +144,8 @@ func TestEmployeeHandler_UpdateEmployeeRequestBinding_Success(t *testing.T) {
 	assert.Equal(t, "updateduser", updateReq.Name)
 	assert.Equal(t, "newpassword123", updateReq.Password)
 	assert.Equal(t, "user", updateReq.Role)
-	assert.False(t, updateReq.Active)
+	require.NotNil(t, updateReq.Active)
+	assert.False(t, *updateReq.Active)
 
 	// Test parameter extraction
 	employeeID := c.Param("id")
@@ -466,23 +481,24 @@ func TestEmployeeHandler_CreateEmployeeRequest_Structure(t *testing.T) {
 // Test UpdateEmployeeRequest structure
 func TestEmployeeHandler_UpdateEmployeeRequest_Structure(t *testing.T) {
 	// Create request
+	inactive := false
 	req := request.UpdateEmployeeRequest{
 		Name:     "updateduser",
 		Password: "newpassword",
-		Role:     "user",
-		Active:   false,
+		Role:     "employee",
+		Active:   &inactive,
 	}
 
 	// Assert structure
 	assert.IsType(t, "", req.Name)
 	assert.IsType(t, "", req.Password)
 	assert.IsType(t, "", req.Role)
-	assert.IsType(t, true, req.Active)
+	assert.IsType(t, &inactive, req.Active)
 
 	assert.Equal(t, "updateduser", req.Name)
 	assert.Equal(t, "newpassword", req.Password)
-	assert.Equal(t, "user", req.Role)
-	assert.False(t, req.Active)
+	assert.Equal(t, "employee", req.Role)
+	assert.False(t, *req.Active)
 }
 
 // Test HTTP parameter validation
@@ -543,3 +559,768 @@ func TestEmployeeHandler_ContextValues_Extraction(t *testing.T) {
 	nonExistent := c.Get("non_existent")
 	assert.Nil(t, nonExistent)
 }
+
+// createEmployeeWithRetention deletes an employee named Jane Doe, then creates a new one with that name
+// under the given reuse policy and returns the response with the number of employees named Jane Doe
+func createEmployeeWithRetention(t *testing.T, allowReuse bool) (*httptest.ResponseRecorder, int64) {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.RoleTemplate{}, &model.RoleTemplateComponent{}, &model.EmployeeComponent{}))
+	deleted := &model.Employee{Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}
+	require.NoError(t, db.Create(deleted).Error)
+	require.NoError(t, db.Delete(deleted).Error)
+
+	h := &EmployeeHandler{
+		Response:     response.NewResponse(),
+		BaseRepo:     repository.NewBaseRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+		Retention:    config.EmployeeRetentionConfig{AllowDeletedNameReuse: allowReuse},
+	}
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/employee/create", strings.NewReader(`{"name":"Jane Doe","password":"secret123","role":"employee","active":true}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	require.NoError(t, h.CreateEmployee(c))
+
+	var count int64
+	require.NoError(t, db.Unscoped().Model(&model.Employee{}).Where("name = ?", "Jane Doe").Count(&count).Error)
+	return rec, count
+}
+
+func TestEmployeeHandler_CreateEmployee_DeletedNameReuseBlocked(t *testing.T) {
+	rec, count := createEmployeeWithRetention(t, false)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "belongs to a deleted employee")
+	assert.Equal(t, int64(1), count)
+}
+
+func TestEmployeeHandler_CreateEmployee_DeletedNameReuseAllowed(t *testing.T) {
+	rec, count := createEmployeeWithRetention(t, true)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestEmployeeHandler_CreateEmployee_Currency(t *testing.T) {
+	tests := []struct {
+		name         string
+		currency     string
+		wantStatus   int
+		wantCurrency string
+	}{
+		{name: "defaults to the base currency", currency: "", wantStatus: http.StatusOK, wantCurrency: "IDR"},
+		{name: "normalised to upper case", currency: "usd", wantStatus: http.StatusOK, wantCurrency: "USD"},
+		{name: "unknown code refused", currency: "XYZ", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := setupPayrollHandlerDB(t)
+			require.NoError(t, db.AutoMigrate(&model.RoleTemplate{}, &model.RoleTemplateComponent{}, &model.EmployeeComponent{}))
+			h := &EmployeeHandler{
+				Response:     response.NewResponse(),
+				BaseRepo:     repository.NewBaseRepository(db),
+				EmployeeRepo: repository.NewEmployeeRepository(db),
+				BaseCurrency: "IDR",
+			}
+
+			e := echo.New()
+			e.Validator = helper.NewValidator()
+			body := fmt.Sprintf(`{"name":"Jane Doe","password":"secret123","role":"employee","active":true,"currency":%q}`, tt.currency)
+			req := httptest.NewRequest(http.MethodPost, "/employee/create", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			require.NoError(t, h.CreateEmployee(e.NewContext(req, rec)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var employees []model.Employee
+			require.NoError(t, db.Find(&employees).Error)
+			if tt.wantStatus != http.StatusOK {
+				assert.Empty(t, employees)
+				return
+			}
+			require.Len(t, employees, 1)
+			assert.Equal(t, tt.wantCurrency, employees[0].Currency)
+		})
+	}
+}
+
+func TestEmployeeHandler_CreateEmployee_Validation(t *testing.T) {
+	for _, tt := range []struct {
+		body       string
+		wantStatus int
+	}{
+		{`{"name":"Jane Doe","password":"secret123","role":"employee","active":false}`, http.StatusOK},
+		{`{"name":"Jane Doe","password":"secret123","role":"user","active":true}`, http.StatusBadRequest},
+		{`{"name":"Jane Doe","role":"employee","active":true}`, http.StatusBadRequest},
+	} {
+		db, _ := setupPayrollHandlerDB(t)
+		require.NoError(t, db.AutoMigrate(&model.RoleTemplate{}, &model.RoleTemplateComponent{}, &model.EmployeeComponent{}))
+		h := &EmployeeHandler{
+			Response:     response.NewResponse(),
+			BaseRepo:     repository.NewBaseRepository(db),
+			EmployeeRepo: repository.NewEmployeeRepository(db),
+		}
+
+		e := echo.New()
+		e.Validator = helper.NewValidator()
+		req := httptest.NewRequest(http.MethodPost, "/employee/create", strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.CreateEmployee(e.NewContext(req, rec)))
+
+		assert.Equal(t, tt.wantStatus, rec.Code, tt.body)
+		var count int64
+		require.NoError(t, db.Model(&model.Employee{}).Count(&count).Error)
+		if tt.wantStatus == http.StatusOK {
+			assert.Equal(t, int64(1), count, tt.body)
+		} else {
+			assert.Contains(t, rec.Body.String(), `"error_code":"`+response.ErrCodeValidationFailed+`"`, tt.body)
+			assert.Zero(t, count, tt.body)
+		}
+	}
+}
+
+// importEmployees uploads the CSV to the real ImportEmployees as admin 9, with Jane Doe already employed
+func importEmployees(t *testing.T, csv string, dryRun bool) (*gorm.DB, *httptest.ResponseRecorder) {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.RoleTemplate{}, &model.RoleTemplateComponent{}, &model.EmployeeComponent{}))
+	require.NoError(t, db.Create(&model.Employee{Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+
+	h := &EmployeeHandler{
+		Response:     response.NewResponse(),
+		BaseRepo:     repository.NewBaseRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "employees.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(csv))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	target := "/employee/import"
+	if dryRun {
+		target += "?dry_run=true"
+	}
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, target, body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("role", "admin")
+	c.Set("user_id", 9)
+	require.NoError(t, h.ImportEmployees(c))
+	return db, rec
+}
+
+// decodeEmployeeImport decodes the import result from the response body
+func decodeEmployeeImport(t *testing.T, rec *httptest.ResponseRecorder) res.EmployeeImportResult {
+	var body struct {
+		Data res.EmployeeImportResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Data
+}
+
+const employeeImportCSV = `name,role,active,basic_salary
+John Roe,employee,true,4000000
+Jane Doe,employee,true,1000000
+Ann Lee,boss,true,1000000
+John Roe,manager,false,0
+Mary Major,Manager,false,
+`
+
+func TestEmployeeHandler_ImportEmployees_CreatesValidRowsOnly(t *testing.T) {
+	db, rec := importEmployees(t, employeeImportCSV, false)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	result := decodeEmployeeImport(t, rec)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, 5, result.Total)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 3, result.Failed)
+
+	statuses := make([]string, len(result.Rows))
+	for i, row := range result.Rows {
+		statuses[i] = row.Status
+		assert.Equal(t, i+2, row.Row)
+	}
+	assert.Equal(t, []string{"created", "failed", "failed", "failed", "created"}, statuses)
+	assert.Contains(t, result.Rows[1].Reason, "duplicate name")
+	assert.Contains(t, result.Rows[2].Reason, "invalid role")
+	assert.Contains(t, result.Rows[3].Reason, "already used on row 2")
+
+	var john model.Employee
+	require.NoError(t, db.Where("name = ?", "John Roe").First(&john).Error)
+	assert.Equal(t, result.Rows[0].EmployeeID, john.ID)
+	assert.Equal(t, 4000000.0, john.BasicSalary)
+	require.NotNil(t, john.CreatedBy)
+	assert.Equal(t, uint(9), *john.CreatedBy)
+	require.NotEmpty(t, result.Rows[0].Password)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(john.Password), []byte(result.Rows[0].Password)))
+
+	var mary model.Employee
+	require.NoError(t, db.Where("name = ?", "Mary Major").First(&mary).Error)
+	assert.Equal(t, "manager", mary.Role)
+	assert.False(t, mary.Active)
+	assert.NotEqual(t, result.Rows[0].Password, result.Rows[4].Password)
+}
+
+func TestEmployeeHandler_ImportEmployees_DryRunSavesNothing(t *testing.T) {
+	db, rec := importEmployees(t, employeeImportCSV, true)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	result := decodeEmployeeImport(t, rec)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 3, result.Failed)
+	assert.Equal(t, "valid", result.Rows[0].Status)
+	assert.Empty(t, result.Rows[0].Password)
+	assert.Zero(t, result.Rows[0].EmployeeID)
+
+	var count int64
+	require.NoError(t, db.Model(&model.Employee{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestEmployeeHandler_ImportEmployees_MissingColumn(t *testing.T) {
+	_, rec := importEmployees(t, "name,role,active\nJohn Roe,employee,true\n", false)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "missing the basic_salary column")
+}
+
+// meRequest runs the real self-service handler as employee 1 (Jane Doe, password "oldsecret"), with John Roe also employed
+func meRequest(t *testing.T, handle func(*EmployeeHandler) echo.HandlerFunc, method string, body string) (*gorm.DB, *httptest.ResponseRecorder) {
+	db, _ := setupPayrollHandlerDB(t)
+	hashed, err := bcrypt.GenerateFromPassword([]byte("oldsecret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: string(hashed), Role: "employee", Active: true, BasicSalary: 5000000, Currency: "IDR"}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Roe", Password: "hashed", Role: "employee", Active: true}).Error)
+
+	h := &EmployeeHandler{
+		Response:     response.NewResponse(),
+		BaseRepo:     repository.NewBaseRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+	}
+
+	e := echo.New()
+	e.Validator = &authTestValidator{validator: validator.New()}
+	req := httptest.NewRequest(method, "/me", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("role", "employee")
+	c.Set("user_id", 1)
+	c.Set("authenticated_role", "employee")
+	c.Set("authenticated_user_id", uint(1))
+	require.NoError(t, handle(h)(c))
+	return db, rec
+}
+
+func TestEmployeeHandler_GetMe_ReturnsOwnProfile(t *testing.T) {
+	_, rec := meRequest(t, func(h *EmployeeHandler) echo.HandlerFunc { return h.GetMe }, http.MethodGet, "")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, map[string]interface{}{
+		"id": 1.0, "name": "Jane Doe", "role": "employee", "active": true, "basic_salary": 5000000.0, "currency": "IDR",
+	}, body.Data)
+	assert.NotContains(t, rec.Body.String(), "$2a$")
+}
+
+func TestEmployeeHandler_UpdateMe_ChangesNameAndPassword(t *testing.T) {
+	db, rec := meRequest(t, func(h *EmployeeHandler) echo.HandlerFunc { return h.UpdateMe }, http.MethodPatch, `{"name":" Jane Smith ","password":"newsecret","current_password":"oldsecret"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, "Jane Smith", stored.Name)
+	assert.Equal(t, "employee", stored.Role)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("newsecret")))
+	require.NotNil(t, stored.UpdatedBy)
+	assert.Equal(t, uint(1), *stored.UpdatedBy)
+}
+
+func TestEmployeeHandler_UpdateMe_PasswordNeedsCurrentPassword(t *testing.T) {
+	for _, tt := range []struct {
+		body string
+		code int
+	}{
+		{`{"password":"newsecret"}`, http.StatusBadRequest},
+		{`{"password":"newsecret","current_password":""}`, http.StatusBadRequest},
+		{`{"password":"newsecret","current_password":"wrongsecret"}`, http.StatusForbidden},
+	} {
+		db, rec := meRequest(t, func(h *EmployeeHandler) echo.HandlerFunc { return h.UpdateMe }, http.MethodPatch, tt.body)
+		assert.Equal(t, tt.code, rec.Code, tt.body)
+
+		var stored model.Employee
+		require.NoError(t, db.First(&stored, 1).Error)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("oldsecret")), tt.body)
+	}
+
+	// A name change alone needs no password
+	_, rec := meRequest(t, func(h *EmployeeHandler) echo.HandlerFunc { return h.UpdateMe }, http.MethodPatch, `{"name":"Jane Smith"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestEmployeeHandler_UpdateMe_ProtectedFieldsForbidden(t *testing.T) {
+	db, rec := meRequest(t, func(h *EmployeeHandler) echo.HandlerFunc { return h.UpdateMe }, http.MethodPatch, `{"name":"Jane Smith","role":"admin","active":true}`)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"protected_fields":["active","role"]`)
+
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, "Jane Doe", stored.Name)
+	assert.Equal(t, "employee", stored.Role)
+}
+
+func TestEmployeeHandler_UpdateMe_NameTaken(t *testing.T) {
+	_, rec := meRequest(t, func(h *EmployeeHandler) echo.HandlerFunc { return h.UpdateMe }, http.MethodPatch, `{"name":"John Roe"}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "already exists")
+}
+
+// editEmployeeWithPayGrade edits employee 1 through the real EditEmployee, assigning pay grade 1 (4M to 8M)
+func editEmployeeWithPayGrade(t *testing.T, h *EmployeeHandler, basicSalary float64) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	body := fmt.Sprintf(`{"name":"Jane Doe","password":"secret123","role":"employee","active":true,"pay_grade_id":1,"basic_salary":%v}`, basicSalary)
+	req := httptest.NewRequest(http.MethodPut, "/employee/edit/1", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	require.NoError(t, h.EditEmployee(c))
+	return rec
+}
+
+func TestEmployeeHandler_EditEmployee_StaleVersion(t *testing.T) {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, Version: 4}).Error)
+	h := &EmployeeHandler{
+		Response:     response.NewResponse(),
+		BaseRepo:     repository.NewBaseRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+	}
+
+	edit := func(version int) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = helper.NewValidator()
+		body := fmt.Sprintf(`{"name":"Jane Doe","password":"secret123","role":"employee","active":true,"department":"Sales","version":%d}`, version)
+		req := httptest.NewRequest(http.MethodPut, "/employee/edit/1", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		require.NoError(t, h.EditEmployee(c))
+		return rec
+	}
+
+	rec := edit(3)
+	assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Empty(t, stored.Department)
+
+	rec = edit(4)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, "Sales", stored.Department)
+	assert.Equal(t, 5, stored.Version)
+	assert.Contains(t, rec.Body.String(), `"version":5`)
+}
+
+func TestEmployeeHandler_EditEmployee_PayGradeBand(t *testing.T) {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.PayGrade{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "G2", MinSalary: 4000000, MaxSalary: 8000000, DefaultSalary: 6000000}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	h := &EmployeeHandler{
+		Response:     response.NewResponse(),
+		BaseRepo:     repository.NewBaseRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+		PayGradeRepo: repository.NewPayGradeRepository(db),
+	}
+
+	rec := editEmployeeWithPayGrade(t, h, 9000000)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Basic salary 9000000.00 is outside pay grade G2 (4000000.00 to 8000000.00)")
+
+	rec = editEmployeeWithPayGrade(t, h, 7000000)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The employee detail exposes the assigned grade
+	e := echo.New()
+	rec = httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/employee/1", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("authenticated_role", "admin")
+	c.Set("authenticated_user_id", uint(9))
+	require.NoError(t, h.GetEmployeeByID(c))
+
+	var detail struct {
+		Data model.SafeEmployee `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &detail))
+	require.NotNil(t, detail.Data.PayGrade)
+	assert.Equal(t, "G2", detail.Data.PayGrade.Name)
+	assert.Equal(t, 6000000.0, detail.Data.PayGrade.DefaultSalary)
+}
+
+// listEmployees runs the real employee listing against Jane Doe, the manager John Roe and the deactivated Janet Moe
+func listEmployees(t *testing.T, query string) *httptest.ResponseRecorder {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&[]model.Employee{
+		{Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true},
+		{Name: "John Roe", Password: "hashed", Role: "manager", Active: true},
+		{Name: "Janet Moe", Password: "hashed", Role: "employee"},
+	}).Error)
+	require.NoError(t, db.Model(&model.Employee{}).Where("name = ?", "Janet Moe").Update("active", false).Error)
+
+	h := &EmployeeHandler{Response: response.NewResponse(), EmployeeRepo: repository.NewEmployeeRepository(db)}
+	req := httptest.NewRequest(http.MethodGet, "/employee?"+query, nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ListEmployees(echo.New().NewContext(req, rec)))
+	return rec
+}
+
+func TestEmployeeHandler_ListEmployees_Paginated(t *testing.T) {
+	rec := listEmployees(t, "q=JAN&role=employee&active=false")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body struct {
+		Data struct {
+			Records     []map[string]interface{} `json:"records"`
+			TotalRecord int64                    `json:"total_record"`
+			TotalPage   int64                    `json:"total_page"`
+			CurrentPage int                      `json:"current_page"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data.Records, 1)
+	assert.Equal(t, "Janet Moe", body.Data.Records[0]["name"])
+	assert.Equal(t, int64(1), body.Data.TotalRecord)
+
+	rec = listEmployees(t, "q=JAN&limit=1&page=2")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data.Records, 1)
+	assert.Equal(t, "Janet Moe", body.Data.Records[0]["name"])
+	assert.Equal(t, int64(2), body.Data.TotalRecord)
+	assert.Equal(t, int64(2), body.Data.TotalPage)
+	assert.Equal(t, 2, body.Data.CurrentPage)
+	assert.NotContains(t, rec.Body.String(), "$2a$")
+}
+
+func TestEmployeeHandler_ListEmployees_InvalidFilters(t *testing.T) {
+	for _, query := range []string{"active=yes", "role=owner", "page=0", "limit=-5"} {
+		rec := listEmployees(t, query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+// setEmployeeActive runs DeactivateEmployee or ActivateEmployee for the employee ID as admin 9
+func setEmployeeActive(t *testing.T, h *EmployeeHandler, id string, active bool) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/employee/"+id+"/deactivate", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	c.Set("user_id", 9)
+
+	handle := h.DeactivateEmployee
+	if active {
+		handle = h.ActivateEmployee
+	}
+	require.NoError(t, handle(c))
+	return rec
+}
+
+func TestEmployeeHandler_DeactivateEmployee_SkippedByPayroll(t *testing.T) {
+	db, payrollHandler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.EmployeeComponent{}, &model.Timesheet{}, &model.TaxBracket{}))
+	hired := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&[]model.Employee{
+		{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, HireDate: &hired},
+		{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Roe", Password: "hashed", Role: "employee", Active: true, HireDate: &hired},
+	}).Error)
+	h := &EmployeeHandler{
+		Response:     response.NewResponse(),
+		BaseRepo:     repository.NewBaseRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+	}
+
+	rec := setEmployeeActive(t, h, "2", false)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, 2).Error)
+	assert.False(t, stored.Active)
+	assert.Equal(t, 1, stored.TokenVersion)
+	require.NotNil(t, stored.UpdatedBy)
+	assert.Equal(t, uint(9), *stored.UpdatedBy)
+
+	rec = postPayrollJSON(t, payrollHandler.RunPayrollForAllEmployees, `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z","basic_salary":5000000,"overtime_rate":50000}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var payslips []model.Payslip
+	require.NoError(t, db.Find(&payslips).Error)
+	require.Len(t, payslips, 1)
+	assert.Equal(t, uint(1), payslips[0].EmployeeID)
+
+	// Toggling to the current state conflicts, reactivating works once
+	assert.Equal(t, http.StatusConflict, setEmployeeActive(t, h, "2", false).Code)
+	assert.Equal(t, http.StatusOK, setEmployeeActive(t, h, "2", true).Code)
+	assert.Equal(t, http.StatusConflict, setEmployeeActive(t, h, "2", true).Code)
+	assert.Equal(t, http.StatusNotFound, setEmployeeActive(t, h, "42", false).Code)
+	assert.Equal(t, http.StatusBadRequest, setEmployeeActive(t, h, "abc", false).Code)
+}
+
+// attendanceStats runs GetAttendanceStats for the employee ID and query as the given caller, in UTC with late
+// check-ins from 10:00
+func attendanceStats(t *testing.T, db *gorm.DB, id string, query string, role string, userID uint) *httptest.ResponseRecorder {
+	h := &EmployeeHandler{
+		Helper:         helper.NewHelper{TimeLocation: time.UTC},
+		Response:       response.NewResponse(),
+		EmployeeRepo:   repository.NewEmployeeRepository(db),
+		AttendanceRepo: repository.NewAttendanceRepository(db),
+		PayslipRepo:    repository.NewPayslipRepository(db),
+		Anomaly:        config.AttendanceAnomalyConfig{Late: config.LateCheckinPolicy{WorkStartMinutes: 9 * 60}},
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/employee/"+id+"/attendance-stats?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, h.GetAttendanceStats(c))
+	return rec
+}
+
+// setupAttendanceStats stores employee 1, hired on Monday 2025-06-02, with two weeks of attendance around
+// a holiday on Friday 2025-06-06
+func setupAttendanceStats(t *testing.T) *gorm.DB {
+	db, _ := setupPayrollHandlerDB(t)
+	hired := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, HireDate: &hired}).Error)
+	require.NoError(t, db.Create(&model.Holiday{Date: "2025-06-06", Name: "Founders Day"}).Error)
+
+	for _, day := range []struct {
+		day    int
+		hour   int
+		hours  int
+		status string
+	}{
+		{2, 9, 8, model.AttendanceStatusPresent},
+		{3, 9, 8, model.AttendanceStatusLate},
+		{4, 10, 7, model.AttendanceStatusPresent}, // Late by the work start
+		{5, 9, 4, model.AttendanceStatusHalfDay},
+		{9, 9, 8, model.AttendanceStatusPresent}, // The holiday and weekend before do not break the streak
+		{11, 9, 8, model.AttendanceStatusPresent},
+		{12, 9, 0, model.AttendanceStatusLeave},
+		{13, 9, 0, model.AttendanceStatusAbsent},
+		{14, 9, 3, model.AttendanceStatusPresent}, // Saturday
+	} {
+		date := time.Date(2025, time.June, day.day, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, db.Create(&model.Attendance{
+			EmployeeID:  1,
+			Date:        date,
+			Checkin:     date.Add(time.Duration(day.hour) * time.Hour),
+			HoursWorked: day.hours,
+			Status:      day.status,
+		}).Error)
+	}
+	return db
+}
+
+func TestEmployeeHandler_GetAttendanceStats(t *testing.T) {
+	db := setupAttendanceStats(t)
+
+	rec := attendanceStats(t, db, "1", "start=2025-06-01&end=2025-06-15", "employee", 1)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data res.AttendanceStats `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	stats := body.Data
+	assert.Equal(t, uint(1), stats.EmployeeID)
+	assert.Equal(t, "2025-06-01", stats.StartDate)
+	assert.Equal(t, "2025-06-15", stats.EndDate)
+	// Sunday 1 June is before the hire date, the holiday and weekends are no working days
+	assert.Equal(t, 9, stats.WorkingDays)
+	assert.Equal(t, 7, stats.PresentDays)
+	// 10 June without attendance and 13 June recorded absent, the leave day is not an absence
+	assert.Equal(t, 2, stats.AbsentDays)
+	assert.Equal(t, 2, stats.LateDays)
+	assert.Equal(t, 46, stats.TotalHoursWorked)
+	assert.Equal(t, 5, stats.LongestPresentStreak)
+	require.NotNil(t, stats.LongestStreakStart)
+	require.NotNil(t, stats.LongestStreakEnd)
+	assert.Equal(t, "2025-06-02", *stats.LongestStreakStart)
+	assert.Equal(t, "2025-06-09", *stats.LongestStreakEnd)
+
+	// Days after today are not counted as absent
+	start := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	end := time.Now().UTC().AddDate(0, 0, 14).Format("2006-01-02")
+	rec = attendanceStats(t, db, "1", "start="+start+"&end="+end, "admin", 9)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 0, body.Data.AbsentDays)
+	assert.Equal(t, 0, body.Data.LongestPresentStreak)
+	assert.Nil(t, body.Data.LongestStreakStart)
+	assert.Equal(t, end, body.Data.EndDate)
+}
+
+func TestEmployeeHandler_GetAttendanceStats_Refused(t *testing.T) {
+	db := setupAttendanceStats(t)
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		role   string
+		userID uint
+		want   int
+	}{
+		{"another employee's statistics", "1", "start=2025-06-01&end=2025-06-15", "employee", 2, http.StatusForbidden},
+		{"manager asking for someone else", "1", "start=2025-06-01&end=2025-06-15", "manager", 2, http.StatusForbidden},
+		{"missing start", "1", "end=2025-06-15", "admin", 9, http.StatusBadRequest},
+		{"malformed end", "1", "start=2025-06-01&end=15-06-2025", "admin", 9, http.StatusBadRequest},
+		{"end before start", "1", "start=2025-06-15&end=2025-06-01", "admin", 9, http.StatusBadRequest},
+		{"unknown employee", "42", "start=2025-06-01&end=2025-06-15", "admin", 9, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := attendanceStats(t, db, tt.id, tt.query, tt.role, tt.userID)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestEmployeeHandler_AssignManager_AndDirectReports(t *testing.T) {
+	db, _ := setupPayrollHandlerDB(t)
+	for id, name := range map[uint]string{1: "Head", 2: "Lead", 3: "Member"} {
+		require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: id}, Name: name, Password: "hashed", Role: "manager", Active: true}).Error)
+	}
+	h := &EmployeeHandler{
+		Response:     response.NewResponse(),
+		BaseRepo:     repository.NewBaseRepository(db),
+		EmployeeRepo: repository.NewEmployeeRepository(db),
+	}
+
+	assign := func(id string, body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/employee/"+id+"/manager", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, h.AssignManager(c))
+		return rec
+	}
+	reports := func(id string, role string, userID uint) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/employee/"+id+"/reports", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.Set("authenticated_role", role)
+		c.Set("authenticated_user_id", userID)
+		require.NoError(t, h.GetDirectReports(c))
+		return rec
+	}
+
+	// 1 manages 2, 2 manages 3
+	require.Equal(t, http.StatusOK, assign("2", `{"manager_id":1}`).Code)
+	rec := assign("3", `{"manager_id":2}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"manager_id":2`)
+
+	rec = assign("1", `{"manager_id":3}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "cannot report to themselves")
+	assert.Equal(t, http.StatusBadRequest, assign("1", `{"manager_id":42}`).Code)
+	assert.Equal(t, http.StatusNotFound, assign("42", `{"manager_id":1}`).Code)
+
+	rec = reports("1", "manager", 1)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body struct {
+		Data res.ManagedEmployeesResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Data.Count)
+	assert.Equal(t, uint(2), body.Data.Employees[0].ID)
+
+	assert.Equal(t, http.StatusForbidden, reports("1", "manager", 2).Code)
+	assert.Equal(t, http.StatusNotFound, reports("42", "admin", 9).Code)
+
+	// A null manager_id removes the manager
+	require.Equal(t, http.StatusOK, assign("2", `{"manager_id":null}`).Code)
+	rec = reports("1", "admin", 9)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 0, body.Data.Count)
+}
+
+func TestEmployeeHandler_GetManagedEmployees_ThroughManagerOrAdmin(t *testing.T) {
+	db, _ := setupPayrollHandlerDB(t)
+	one, two := uint(1), uint(2)
+	for _, employee := range []model.Employee{
+		{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Head", Password: "hashed", Role: "manager", Active: true},
+		{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "Lead", Password: "hashed", Role: "manager", Active: true, ManagerID: &one},
+		{DefaultAttribute: model.DefaultAttribute{ID: 3}, Name: "Member", Password: "hashed", Role: "employee", Active: true, ManagerID: &two},
+	} {
+		require.NoError(t, db.Create(&employee).Error)
+	}
+	h := &EmployeeHandler{Response: response.NewResponse(), EmployeeRepo: repository.NewEmployeeRepository(db)}
+
+	// The route's middleware with only the role and user ID HeaderMiddleware's JWT claims provide
+	authenticated := func(role string, userID int) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set("role", role)
+				c.Set("user_id", userID)
+				return next(c)
+			}
+		}
+	}
+	managed := func(id string, role string, userID int) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.GET("/employee/:id/managed", h.GetManagedEmployees, authenticated(role, userID), middleware.ManagerOrAdmin(h.Response))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/employee/"+id+"/managed", nil))
+		return rec
+	}
+
+	rec := managed("1", "manager", 1)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body struct {
+		Data res.ManagedEmployeesResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 2, body.Data.Count)
+
+	assert.Equal(t, http.StatusForbidden, managed("1", "manager", 2).Code, "another manager's subtree")
+	assert.Equal(t, http.StatusForbidden, managed("2", "employee", 3).Code)
+	assert.Equal(t, http.StatusOK, managed("2", "admin", 9).Code)
+}
@@ -1,13 +1,30 @@
 package handler
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/helper"
 	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
 	"github.com/yourname/payslip-system/internal/repository"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +36,17 @@ type EmployeeHandler struct {
 
 	BaseRepo     repository.BaseRepositoryInterface
 	EmployeeRepo repository.EmployeeRepository
+	PayGradeRepo repository.PayGradeRepository
+	// AttendanceRepo and PayslipRepo, for its holidays, back the attendance statistics
+	AttendanceRepo repository.AttendanceRepository
+	PayslipRepo    repository.PayslipRepository
+
+	// Retention decides if the name of a soft-deleted employee can be reused
+	Retention config.EmployeeRetentionConfig
+	// BaseCurrency is given to employees created without a currency
+	BaseCurrency string
+	// Anomaly holds the late check-in hour of the attendance statistics
+	Anomaly config.AttendanceAnomalyConfig
 }
 
 // NewEmployeeHandler creates a new instance of EmployeeHandler.
@@ -28,6 +56,38 @@ func (h *EmployeeHandler) CreateEmployee(c echo.Context) error {
 		return h.Response.SendError(c, err.Error(), "Invalid request data")
 	}
 
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		req.Currency = h.BaseCurrency
+	}
+	if req.Currency != "" && !helper.IsCurrencyCode(req.Currency) {
+		return h.Response.SendBadRequest(c, fmt.Sprintf("Invalid currency %q, expected an ISO 4217 code", req.Currency), nil)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			return h.Response.SendValidationError(c, validationErrors)
+		}
+		return h.Response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	if reason, err := h.payGradeViolation(req.PayGradeID, req.BasicSalary); err != nil {
+		return h.Response.SendError(c, "Failed to check pay grade", err.Error())
+	} else if reason != "" {
+		return h.Response.SendBadRequest(c, reason, nil)
+	}
+
+	// The name is the login, keep it reserved while a deleted employee still holds it
+	if !h.Retention.AllowDeletedNameReuse {
+		_, err := h.EmployeeRepo.GetDeletedEmployeeByName(req.Name)
+		if err == nil {
+			return h.Response.SendBadRequest(c, fmt.Sprintf("Name %q belongs to a deleted employee and cannot be reused until it is purged", req.Name), nil)
+		}
+		if err != gorm.ErrRecordNotFound {
+			return h.Response.SendError(c, "Failed to check deleted employees", err.Error())
+		}
+	}
+
 	// Get auditable database instance
 	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
 
@@ -47,6 +107,50 @@ func (h *EmployeeHandler) GetAllEmployees(c echo.Context) error {
 	return h.Response.SendSuccess(c, "Employees retrieved successfully", employees)
 }
 
+// ListEmployees lists the employees one page at a time, optionally filtered by a name search, role and active flag
+func (h *EmployeeHandler) ListEmployees(c echo.Context) error {
+	filter := request.EmployeeFilter{Query: strings.TrimSpace(c.QueryParam("q"))}
+
+	filter.Role = c.QueryParam("role")
+	switch filter.Role {
+	case "", "admin", "manager", "employee":
+	default:
+		return h.Response.SendBadRequest(c, "Invalid role, expected admin, manager or employee", nil)
+	}
+
+	switch c.QueryParam("active") {
+	case "":
+	case "true":
+		active := true
+		filter.Active = &active
+	case "false":
+		active := false
+		filter.Active = &active
+	default:
+		return h.Response.SendBadRequest(c, "Invalid active, expected true or false", nil)
+	}
+
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid page, expected a positive number", err.Error())
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid limit, expected a positive number", err.Error())
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	employees, total, err := h.EmployeeRepo.ListEmployees(filter, (page-1)*limit, limit)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve employees", err.Error())
+	}
+
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.Response.SendPaginationResponse(c, employees, "Employees retrieved successfully", total, int64(limit), int64(len(employees)), totalPage, page)
+}
+
 // EditEmployee updates an employee with audit tracking
 func (h *EmployeeHandler) EditEmployee(c echo.Context) error {
 	// Implementation for editing an employee
@@ -55,16 +159,85 @@ func (h *EmployeeHandler) EditEmployee(c echo.Context) error {
 		return h.Response.SendError(c, err.Error(), "Invalid request data")
 	}
 
+	// An empty currency keeps the current one
+	if req.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*req.Currency))
+		if currency != "" && !helper.IsCurrencyCode(currency) {
+			return h.Response.SendBadRequest(c, fmt.Sprintf("Invalid currency %q, expected an ISO 4217 code", currency), nil)
+		}
+		req.Currency = &currency
+	}
+
+	if err := c.Validate(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			return h.Response.SendValidationError(c, validationErrors)
+		}
+		return h.Response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
 	employeeID := c.Param("id")
 
+	// The band is checked on the pay grade and basic salary the employee ends up with
+	if req.PayGradeID != nil || req.BasicSalary != nil {
+		id, err := strconv.ParseUint(employeeID, 10, 32)
+		if err != nil {
+			return h.Response.SendBadRequest(c, "Invalid employee ID", err.Error())
+		}
+		current, err := h.EmployeeRepo.GetEmployeeByID(uint(id))
+		if err != nil {
+			return h.Response.SendError(c, err.Error(), "Failed to update employee")
+		}
+		payGradeID, basicSalary := current.PayGradeID, current.BasicSalary
+		if req.PayGradeID != nil {
+			payGradeID = req.PayGradeID
+		}
+		if req.BasicSalary != nil {
+			basicSalary = *req.BasicSalary
+		}
+		if reason, err := h.payGradeViolation(payGradeID, basicSalary); err != nil {
+			return h.Response.SendError(c, "Failed to check pay grade", err.Error())
+		} else if reason != "" {
+			return h.Response.SendBadRequest(c, reason, nil)
+		}
+	}
+
 	// Get auditable database instance
 	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
 
-	_, err := h.EmployeeRepo.UpdateEmployeeWithAudit(employeeID, req, auditDB)
+	employee, err := h.EmployeeRepo.UpdateEmployeeWithAudit(employeeID, req, auditDB)
+	if errors.Is(err, middleware.ErrStaleVersion) {
+		return h.Response.SendCustomResponse(c, http.StatusConflict, err.Error(), nil)
+	}
+	if errors.Is(err, repository.ErrManagerNotFound) || errors.Is(err, repository.ErrManagerCycle) {
+		return h.Response.SendBadRequest(c, err.Error(), nil)
+	}
 	if err != nil {
 		return h.Response.SendError(c, err.Error(), "Failed to update employee")
 	}
-	return h.Response.SendSuccess(c, "Employee updated successfully", nil)
+	// With the new version for the next edit
+	return h.Response.SendSuccess(c, "Employee updated successfully", employee.ToSafe())
+}
+
+// payGradeViolation returns why the basic salary cannot be assigned with the pay grade, empty when it can.
+// A zero salary is not checked, payroll then pays the grade's default salary.
+func (h *EmployeeHandler) payGradeViolation(payGradeID *uint, basicSalary float64) (string, error) {
+	if payGradeID == nil {
+		return "", nil
+	}
+
+	grade, err := h.PayGradeRepo.GetPayGradeByID(*payGradeID)
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Sprintf("Pay grade %d not found", *payGradeID), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if basicSalary != 0 && !grade.Contains(basicSalary) {
+		return fmt.Sprintf("Basic salary %.2f is outside pay grade %s (%.2f to %.2f)",
+			basicSalary, grade.Name, grade.MinSalary, grade.MaxSalary), nil
+	}
+	return "", nil
 }
 
 // DeleteEmployee deletes an employee with audit tracking
@@ -82,6 +255,47 @@ func (h *EmployeeHandler) DeleteEmployee(c echo.Context) error {
 	return h.Response.SendSuccess(c, "Employee deleted successfully", nil)
 }
 
+// ActivateEmployee lets a deactivated employee log in and be paid again
+func (h *EmployeeHandler) ActivateEmployee(c echo.Context) error {
+	return h.setEmployeeActive(c, true)
+}
+
+// DeactivateEmployee keeps the employee record but blocks logging in and leaves it out of payroll runs
+func (h *EmployeeHandler) DeactivateEmployee(c echo.Context) error {
+	return h.setEmployeeActive(c, false)
+}
+
+// setEmployeeActive changes the active flag of the employee of the path, 409 when it already has the value
+func (h *EmployeeHandler) setEmployeeActive(c echo.Context, active bool) error {
+	employeeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid employee ID", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+	employee, err := h.EmployeeRepo.SetEmployeeActiveWithAudit(uint(employeeID), active, auditDB)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return h.Response.SendNotFound(c, "Employee not found", nil)
+	case errors.Is(err, repository.ErrEmployeeActiveUnchanged):
+		state := "inactive"
+		if active {
+			state = "active"
+		}
+		return h.Response.SendCustomResponse(c, http.StatusConflict, fmt.Sprintf("Employee is already %s", state), nil)
+	case err != nil:
+		return h.Response.SendError(c, "Failed to update employee", err.Error())
+	}
+
+	message := "Employee deactivated successfully"
+	if active {
+		message = "Employee activated successfully"
+	}
+	return h.Response.SendSuccess(c, message, employee.ToSafe())
+}
+
 // GetEmployeeByID retrieves an employee by ID with access control
 func (h *EmployeeHandler) GetEmployeeByID(c echo.Context) error {
 	employeeIDStr := c.Param("id")
@@ -103,3 +317,511 @@ func (h *EmployeeHandler) GetEmployeeByID(c echo.Context) error {
 	// Return safe employee data (without password)
 	return h.Response.SendSuccess(c, "Employee retrieved successfully", employee.ToSafe())
 }
+
+// GetAttendanceStats reports the present, absent and late days, hours worked and longest present streak of an
+// employee between the start and end query params (YYYY-MM-DD, both included) in the server timezone.
+// Employees and managers can only see their own statistics, admins anyone's.
+func (h *EmployeeHandler) GetAttendanceStats(c echo.Context) error {
+	employeeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid employee ID", err.Error())
+	}
+	if !helper.ValidateEmployeeAccess(c, uint(employeeID)) {
+		return h.Response.SendCustomResponse(c, http.StatusForbidden, "Access denied. You can only view your own attendance.", nil)
+	}
+
+	loc := h.timeLocation()
+	start, err := time.ParseInLocation("2006-01-02", c.QueryParam("start"), loc)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid start, expected YYYY-MM-DD", err.Error())
+	}
+	end, err := time.ParseInLocation("2006-01-02", c.QueryParam("end"), loc)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid end, expected YYYY-MM-DD", err.Error())
+	}
+	if end.Before(start) {
+		return h.Response.SendBadRequest(c, "End date must be after start date", nil)
+	}
+
+	employee, err := h.EmployeeRepo.GetEmployeeByID(uint(employeeID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.Response.SendNotFound(c, "Employee not found", nil)
+		}
+		return h.Response.SendError(c, "Failed to retrieve employee", err.Error())
+	}
+
+	// Days before the hire date or after today cannot have been attended, so they are not counted as absent
+	from, to := start, end
+	if employee.HireDate != nil {
+		hired := employee.HireDate.In(loc)
+		if hireDay := time.Date(hired.Year(), hired.Month(), hired.Day(), 0, 0, 0, 0, loc); hireDay.After(from) {
+			from = hireDay
+		}
+	}
+	now := time.Now().In(loc)
+	if today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc); today.Before(to) {
+		to = today
+	}
+
+	var attendances []model.Attendance
+	holidays := map[string]bool{}
+	if !to.Before(from) {
+		// Databases keep microseconds, a nanosecond before midnight could be rounded up to the next day
+		attendances, err = h.AttendanceRepo.GetEmployeeAttendanceInPeriod(employee.ID, from, to.AddDate(0, 0, 1).Add(-time.Microsecond))
+		if err != nil {
+			return h.Response.SendError(c, "Failed to retrieve attendance", err.Error())
+		}
+		holidayList, err := h.PayslipRepo.GetHolidaysForPeriod(from.Format("2006-01-02"), to.Format("2006-01-02"))
+		if err != nil {
+			return h.Response.SendError(c, "Failed to retrieve holidays", err.Error())
+		}
+		for _, holiday := range holidayList {
+			holidays[holiday.Date] = true
+		}
+	}
+
+	stats := helper.SummarizeAttendance(attendances, from, to, holidays, h.Anomaly)
+	stats.EmployeeID = employee.ID
+	stats.StartDate, stats.EndDate = start.Format("2006-01-02"), end.Format("2006-01-02")
+	return h.Response.SendSuccess(c, "Attendance statistics retrieved successfully", stats)
+}
+
+// timeLocation is the server timezone calendar days are taken in, the process local time when unset
+func (h *EmployeeHandler) timeLocation() *time.Location {
+	if h.Helper.TimeLocation != nil {
+		return h.Helper.TimeLocation
+	}
+	return time.Local
+}
+
+// GetManagedEmployees lists every employee a manager directly or indirectly manages.
+// Set include_self=true to list the manager at depth 0 as well.
+func (h *EmployeeHandler) GetManagedEmployees(c echo.Context) error {
+	managerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid employee ID", err.Error())
+	}
+
+	// Managers can only view their own subtree
+	if !helper.ValidateEmployeeAccess(c, uint(managerID)) {
+		return h.Response.SendCustomResponse(c, 403, "Access denied. You can only view your own reports.", nil)
+	}
+
+	manager, err := h.EmployeeRepo.GetEmployeeByID(uint(managerID))
+	if err != nil {
+		return h.Response.SendError(c, "Employee not found", err.Error())
+	}
+
+	managed, err := h.EmployeeRepo.GetManagedEmployees(manager.ID)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve managed employees", err.Error())
+	}
+
+	if c.QueryParam("include_self") == "true" {
+		self := res.ManagedEmployee{
+			ID:        manager.ID,
+			Name:      manager.Name,
+			Role:      manager.Role,
+			Active:    manager.Active,
+			ManagerID: manager.ManagerID,
+		}
+		managed = append([]res.ManagedEmployee{self}, managed...)
+	}
+
+	return h.Response.SendSuccess(c, "Managed employees retrieved successfully", res.ManagedEmployeesResponse{
+		ManagerID: manager.ID,
+		Count:     len(managed),
+		Employees: managed,
+	})
+}
+
+// GetDirectReports lists the employees reporting directly to the employee of the path
+func (h *EmployeeHandler) GetDirectReports(c echo.Context) error {
+	managerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid employee ID", err.Error())
+	}
+
+	// Managers can only view their own reports
+	if !helper.ValidateEmployeeAccess(c, uint(managerID)) {
+		return h.Response.SendCustomResponse(c, 403, "Access denied. You can only view your own reports.", nil)
+	}
+
+	manager, err := h.EmployeeRepo.GetEmployeeByID(uint(managerID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return h.Response.SendNotFound(c, "Employee not found", nil)
+	}
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve employee", err.Error())
+	}
+
+	reports, err := h.EmployeeRepo.GetDirectReports(manager.ID)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve direct reports", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Direct reports retrieved successfully", res.ManagedEmployeesResponse{
+		ManagerID: manager.ID,
+		Count:     len(reports),
+		Employees: reports,
+	})
+}
+
+// AssignManager sets or, with a null manager_id, removes the manager of the employee of the path.
+// A manager that does not exist, the employee themselves or one of their reports is refused with 400.
+func (h *EmployeeHandler) AssignManager(c echo.Context) error {
+	employeeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid employee ID", err.Error())
+	}
+
+	req := request.AssignManagerRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+	employee, err := h.EmployeeRepo.AssignManagerWithAudit(uint(employeeID), req.ManagerID, auditDB)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return h.Response.SendNotFound(c, "Employee not found", nil)
+	case errors.Is(err, repository.ErrManagerNotFound), errors.Is(err, repository.ErrManagerCycle):
+		return h.Response.SendBadRequest(c, err.Error(), nil)
+	case errors.Is(err, middleware.ErrStaleVersion):
+		return h.Response.SendCustomResponse(c, http.StatusConflict, err.Error(), nil)
+	case err != nil:
+		return h.Response.SendError(c, "Failed to assign manager", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Manager assigned successfully", employee.ToSafe())
+}
+
+// GetDepartments lists the distinct departments with their active headcount
+func (h *EmployeeHandler) GetDepartments(c echo.Context) error {
+	departments, err := h.EmployeeRepo.GetDepartmentHeadcounts()
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve departments", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Departments retrieved successfully", departments)
+}
+
+// profileFields are the fields an employee may send to change their own record
+var profileFields = map[string]bool{"name": true, "password": true, "current_password": true}
+
+// GetMe returns the profile of the authenticated employee
+func (h *EmployeeHandler) GetMe(c echo.Context) error {
+	employee, ok, err := h.authenticatedEmployee(c)
+	if !ok {
+		return err
+	}
+	return h.Response.SendSuccess(c, "Profile retrieved successfully", employeeProfile(employee))
+}
+
+// UpdateMe lets the authenticated employee change their own name and password. Any other
+// field, such as the role or active status, is rejected with 403. A new password needs the
+// current_password, so a stolen session cannot take over the account.
+func (h *EmployeeHandler) UpdateMe(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+	var protected []string
+	for field := range fields {
+		if !profileFields[field] {
+			protected = append(protected, field)
+		}
+	}
+	if len(protected) > 0 {
+		sort.Strings(protected)
+		return h.Response.SendCustomResponse(c, http.StatusForbidden, "Only your name and password can be changed", map[string][]string{"protected_fields": protected})
+	}
+
+	var req request.UpdateProfileRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+	if req.Name == nil && req.Password == nil {
+		return h.Response.SendBadRequest(c, "Nothing to update, provide a name or password", nil)
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		req.Name = &name
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	if req.Password != nil && (req.CurrentPassword == nil || *req.CurrentPassword == "") {
+		return h.Response.SendBadRequest(c, "current_password is required to change the password", nil)
+	}
+
+	employee, ok, err := h.authenticatedEmployee(c)
+	if !ok {
+		return err
+	}
+
+	if req.Password != nil && bcrypt.CompareHashAndPassword([]byte(employee.Password), []byte(*req.CurrentPassword)) != nil {
+		return h.Response.SendCustomResponse(c, http.StatusForbidden, "Current password is incorrect", nil)
+	}
+
+	if req.Name != nil && *req.Name != employee.Name {
+		reason, err := h.nameUnavailable(*req.Name)
+		if err != nil {
+			return h.Response.SendError(c, "Failed to check the name", err.Error())
+		}
+		if reason != "" {
+			return h.Response.SendBadRequest(c, fmt.Sprintf("Name %q cannot be used: %s", *req.Name, reason), nil)
+		}
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+	updated, err := h.EmployeeRepo.UpdateProfileWithAudit(employee.ID, req, auditDB)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to update profile", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Profile updated successfully", employeeProfile(updated))
+}
+
+// authenticatedEmployee loads the employee of the JWT. When ok is false the response has been sent.
+func (h *EmployeeHandler) authenticatedEmployee(c echo.Context) (*model.Employee, bool, error) {
+	userID, ok := c.Get("authenticated_user_id").(uint)
+	if !ok {
+		return nil, false, h.Response.SendUnauthorized(c, "Authentication required", nil)
+	}
+
+	employee, err := h.EmployeeRepo.GetEmployeeByID(userID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, false, h.Response.SendNotFound(c, "Employee not found", nil)
+	}
+	if err != nil {
+		return nil, false, h.Response.SendError(c, "Failed to retrieve profile", err.Error())
+	}
+	return employee, true, nil
+}
+
+// employeeProfile converts an employee to the profile shown to themselves
+func employeeProfile(employee *model.Employee) res.EmployeeProfile {
+	return res.EmployeeProfile{
+		ID:          employee.ID,
+		Name:        employee.Name,
+		Role:        employee.Role,
+		Active:      employee.Active,
+		BasicSalary: employee.BasicSalary,
+		Currency:    employee.Currency,
+	}
+}
+
+// employeeImportColumns are the CSV columns an employee import needs, in any order
+var employeeImportColumns = []string{"name", "role", "active", "basic_salary"}
+
+// ImportEmployees creates employees from the CSV in the multipart "file" field. Every row gets a
+// generated password and is created with the importer recorded; rows that fail are reported and
+// skipped while the other rows are kept. Set dry_run=true to only validate the file.
+func (h *EmployeeHandler) ImportEmployees(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return h.Response.SendBadRequest(c, "CSV file is required in the file form field", err.Error())
+	}
+	src, err := file.Open()
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to read CSV file", err.Error())
+	}
+	defer src.Close()
+
+	reader := csv.NewReader(src)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return h.Response.SendBadRequest(c, "CSV file must start with a header row", err.Error())
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range employeeImportColumns {
+		if _, ok := columns[column]; !ok {
+			return h.Response.SendBadRequest(c, fmt.Sprintf("CSV header is missing the %s column", column), map[string][]string{"required_columns": employeeImportColumns})
+		}
+	}
+
+	result := res.EmployeeImportResult{DryRun: c.QueryParam("dry_run") == "true", Rows: []res.EmployeeImportRow{}}
+	var reqs []request.CreateEmployeeRequest
+	var pending []int // Index in result.Rows of each request
+	seen := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row := res.EmployeeImportRow{Row: line}
+		if err != nil {
+			// A malformed line does not stop the rest of the file from being read
+			row.Status, row.Reason = res.ImportRowFailed, err.Error()
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		req, err := employeeImportRequest(record, columns)
+		row.Name = req.Name
+		if req.Currency == "" {
+			req.Currency = h.BaseCurrency
+		}
+		if err == nil {
+			err = h.validateImportName(req.Name, seen)
+		}
+		if err != nil {
+			row.Status, row.Reason = res.ImportRowFailed, err.Error()
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+		seen[req.Name] = line
+
+		row.Status = res.ImportRowValid
+		result.Rows = append(result.Rows, row)
+		reqs = append(reqs, req)
+		pending = append(pending, len(result.Rows)-1)
+	}
+
+	if !result.DryRun && len(reqs) > 0 {
+		for i := range reqs {
+			if reqs[i].Password, err = generatePassword(); err != nil {
+				return h.Response.SendError(c, "Failed to generate passwords", err.Error())
+			}
+		}
+
+		// Get auditable database instance
+		auditDB := helper.GetAuditableDB(c, h.BaseRepo.GetDB())
+
+		created, failed, err := h.EmployeeRepo.ImportEmployeesWithAudit(reqs, auditDB)
+		if err != nil {
+			return h.Response.SendError(c, "Failed to import employees", err.Error())
+		}
+		for i, index := range pending {
+			row := &result.Rows[index]
+			if failed[i] != nil {
+				row.Status, row.Reason = res.ImportRowFailed, failed[i].Error()
+				continue
+			}
+			row.Status = res.ImportRowCreated
+			row.EmployeeID = created[i].ID
+			row.Password = reqs[i].Password
+		}
+	}
+
+	result.Total = len(result.Rows)
+	for _, row := range result.Rows {
+		if row.Status == res.ImportRowFailed {
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+	}
+
+	message := "Employees imported"
+	if result.DryRun {
+		message = "Employee import validated, nothing was saved"
+	}
+	return h.Response.SendSuccess(c, message, result)
+}
+
+// validateImportName checks the name is not used earlier in the file, by an active employee, or
+// by a deleted employee that still holds it under the retention policy
+func (h *EmployeeHandler) validateImportName(name string, seen map[string]int) error {
+	if line, ok := seen[name]; ok {
+		return fmt.Errorf("duplicate name, already used on row %d", line)
+	}
+
+	reason, err := h.nameUnavailable(name)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return errors.New(reason)
+	}
+	return nil
+}
+
+// nameUnavailable explains why a name cannot be given to an employee, or returns an empty reason
+// when the name is free. The name is the login, so it must be unique among active employees and
+// stays reserved for a deleted employee under the retention policy.
+func (h *EmployeeHandler) nameUnavailable(name string) (string, error) {
+	_, err := h.EmployeeRepo.GetEmployeeByName(name, false)
+	if err == nil {
+		return "duplicate name, an employee with this name already exists", nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+
+	if !h.Retention.AllowDeletedNameReuse {
+		_, err := h.EmployeeRepo.GetDeletedEmployeeByName(name)
+		if err == nil {
+			return "name belongs to a deleted employee and cannot be reused until it is purged", nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// employeeImportRequest builds the create request of one CSV row
+func employeeImportRequest(record []string, columns map[string]int) (request.CreateEmployeeRequest, error) {
+	value := func(column string) string {
+		i := columns[column]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	req := request.CreateEmployeeRequest{Name: value("name"), Role: strings.ToLower(value("role"))}
+	if len(req.Name) < 2 || len(req.Name) > 255 {
+		return req, errors.New("name must be between 2 and 255 characters")
+	}
+	switch req.Role {
+	case "admin", "manager", "employee":
+	default:
+		return req, fmt.Errorf("invalid role %q, must be admin, manager or employee", req.Role)
+	}
+
+	active, err := strconv.ParseBool(value("active"))
+	if err != nil {
+		return req, fmt.Errorf("invalid active value %q, must be true or false", value("active"))
+	}
+	req.Active = active
+
+	if salary := value("basic_salary"); salary != "" {
+		req.BasicSalary, err = strconv.ParseFloat(salary, 64)
+		if err != nil || req.BasicSalary < 0 {
+			return req, fmt.Errorf("invalid basic_salary %q, must be a non-negative number", salary)
+		}
+	}
+
+	// The currency column is optional
+	if _, ok := columns["currency"]; ok {
+		req.Currency = strings.ToUpper(value("currency"))
+		if req.Currency != "" && !helper.IsCurrencyCode(req.Currency) {
+			return req, fmt.Errorf("invalid currency %q, expected an ISO 4217 code", req.Currency)
+		}
+	}
+	return req, nil
+}
+
+// generatePassword returns a random password for an imported employee
+func generatePassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/database"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds the database ping of a readiness check
+const readinessTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	Response response.Interface
+	DB       *gorm.DB
+
+	// Build is reported by the health check, read once when the routes are set up
+	Build res.BuildInfo
+}
+
+// NewBuildInfo reads the build metadata of the running server
+func NewBuildInfo(r response.Interface) res.BuildInfo {
+	branch := r.GetBranch()
+	return res.BuildInfo{
+		GitBranch:  branch,
+		GitHash:    r.GetHash(branch),
+		GitUpdated: r.GetUpdated(),
+		Hostname:   r.GetHostname(),
+	}
+}
+
+// Health answers 200 while the process is up, with the build metadata. It does not touch the database.
+func (h *HealthHandler) Health(c echo.Context) error {
+	return h.Response.SendSuccess(c, "Server is up and running", h.Build)
+}
+
+// Ready answers 200 when the database answers a ping and 503 otherwise, so load balancers stop routing to the server
+func (h *HealthHandler) Ready(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readinessTimeout)
+	defer cancel()
+
+	if err := database.Ping(ctx, h.DB); err != nil {
+		return h.Response.SendCustomResponse(c, http.StatusServiceUnavailable, "Database is unreachable", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Server is ready", nil)
+}
@@ -0,0 +1,76 @@
+// Package handler contains unit tests for the health handler functionality.
+//
+// Health tests cover:
+// 1. A 200 with the build metadata, without touching the database
+//
+// Ready tests cover (real handler on an in-memory database):
+// 1. A 200 while the database answers
+// 2. A 503 once the database is unreachable
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupHealthHandler creates the health handler on an in-memory database
+func setupHealthHandler(t *testing.T) (*gorm.DB, *HealthHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+
+	return db, &HealthHandler{
+		Response: response.NewResponse(),
+		DB:       db,
+		Build:    res.BuildInfo{GitBranch: "main", GitHash: "4cda414", GitUpdated: "2025-06-02 09:00:00", Hostname: "api-1"},
+	}
+}
+
+// getHealth runs a health handler
+func getHealth(t *testing.T, handlerFunc echo.HandlerFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	require.NoError(t, handlerFunc(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)))
+	return rec
+}
+
+func TestHealthHandler_Health_ReportsBuild(t *testing.T) {
+	db, h := setupHealthHandler(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	rec := getHealth(t, h.Health)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data res.BuildInfo `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, h.Build, body.Data)
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	db, h := setupHealthHandler(t)
+
+	rec := getHealth(t, h.Ready)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	rec = getHealth(t, h.Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Database is unreachable")
+}
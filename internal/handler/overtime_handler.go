@@ -1,10 +1,19 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
 	"github.com/yourname/payslip-system/internal/helper"
 	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
 	"github.com/yourname/payslip-system/internal/repository"
 
 	"gorm.io/gorm"
@@ -17,6 +26,14 @@ type OvertimeHandler struct {
 
 	BaseRepo     repository.BaseRepositoryInterface
 	OvertimeRepo repository.OvertimeRepository
+
+	// Approval maps overtime hours to the role required to approve them
+	Approval config.ApprovalRouting
+	// DirectManagerOnly limits managers to the overtime of their direct reports, looked up in EmployeeRepo
+	DirectManagerOnly bool
+	EmployeeRepo      repository.EmployeeRepository
+	// Limits caps the overtime hours an employee can request
+	Limits config.OvertimeConfig
 }
 
 func (h *OvertimeHandler) CreateOvertime(c echo.Context) error {
@@ -25,13 +42,291 @@ func (h *OvertimeHandler) CreateOvertime(c echo.Context) error {
 		return h.Response.SendError(c, err.Error(), "Invalid request data")
 	}
 
+	// Overtime is recorded for today, so the new hours count towards the current month
+	message, err := h.monthlyCapExceeded(req.EmployeeID, time.Now(), req.Hours, 0)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to check monthly overtime", err.Error())
+	}
+	if message != "" {
+		return h.Response.SendBadRequest(c, message, nil)
+	}
+
 	// Get auditable DB instance
 	auditDB := helper.GetAuditableDB(c, h.OvertimeRepo.GetDB())
 
-	_, err := h.OvertimeRepo.CreateOvertimePeriodWithAudit(req.EmployeeID, req.Hours, req.Reason, auditDB)
+	_, err = h.OvertimeRepo.CreateOvertimePeriodWithAudit(req.EmployeeID, req.Hours, req.Reason, auditDB)
 	if err != nil {
 		return h.Response.SendError(c, err.Error(), "Failed to create overtime period")
 	}
 
 	return h.Response.SendSuccess(c, "Overtime period created successfully", nil)
 }
+
+// UpdateOvertime lets the owning employee correct the hours, reason or date of an overtime that is still pending.
+// The monthly cap is checked again with the corrected hours, replacing the ones already counted.
+func (h *OvertimeHandler) UpdateOvertime(c echo.Context) error {
+	employeeID, ok := c.Get("authenticated_user_id").(uint)
+	if !ok {
+		return h.Response.SendUnauthorized(c, "Authentication required", nil)
+	}
+
+	overtimeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid overtime ID", err.Error())
+	}
+
+	req := request.UpdateOvertimeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Validation failed", err.Error())
+	}
+
+	overtime, err := h.OvertimeRepo.GetOvertimeByID(uint(overtimeID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return h.Response.SendNotFound(c, "Overtime not found", nil)
+		}
+		return h.Response.SendError(c, "Failed to retrieve overtime", err.Error())
+	}
+	if overtime.EmployeeID != employeeID {
+		return h.Response.SendCustomResponse(c, http.StatusForbidden, "Access denied. Only your own overtime can be corrected.", nil)
+	}
+	if overtime.Status != model.OvertimePending {
+		message := fmt.Sprintf("Overtime is already %s, only pending overtime can be corrected", overtime.Status)
+		return h.Response.SendCustomResponse(c, http.StatusConflict, message, nil)
+	}
+
+	previousDate, previousHours := overtime.OvertimeDate, overtime.Hours
+	if req.Hours != nil {
+		overtime.Hours = *req.Hours
+	}
+	if req.Reason != nil {
+		reason := strings.TrimSpace(*req.Reason)
+		if reason == "" {
+			return h.Response.SendBadRequest(c, "Reason cannot be empty", nil)
+		}
+		overtime.Reason = reason
+	}
+	if req.OvertimeDate != nil {
+		overtime.OvertimeDate = *req.OvertimeDate
+	}
+
+	loc := h.timeLocation()
+	day, err := time.ParseInLocation("2006-01-02", overtime.OvertimeDate, loc)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid overtime date, expected YYYY-MM-DD", err.Error())
+	}
+	if day.After(time.Now().In(loc)) {
+		return h.Response.SendBadRequest(c, "Overtime cannot be dated in the future", nil)
+	}
+
+	// The stored hours already count towards the cap of their month, unless the overtime moves to another month
+	counted := 0
+	if strings.HasPrefix(previousDate, day.Format("2006-01")) {
+		counted = previousHours
+	}
+	message, err := h.monthlyCapExceeded(employeeID, day, overtime.Hours, counted)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to check monthly overtime", err.Error())
+	}
+	if message != "" {
+		return h.Response.SendBadRequest(c, message, nil)
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.OvertimeRepo.GetDB())
+
+	updated, err := h.OvertimeRepo.UpdatePendingOvertimeWithAudit(overtime, auditDB)
+	if err != nil {
+		if errors.Is(err, repository.ErrOvertimeNotPending) || errors.Is(err, repository.ErrOvertimeDateTaken) {
+			return h.Response.SendCustomResponse(c, http.StatusConflict, err.Error(), nil)
+		}
+		return h.Response.SendError(c, "Failed to update overtime", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Overtime updated successfully", updated)
+}
+
+// monthlyCapExceeded checks the hours against the monthly overtime cap of the month of day, where counted is
+// the part of them already stored for that month. Returns the message to answer with when over the cap.
+func (h *OvertimeHandler) monthlyCapExceeded(employeeID uint, day time.Time, hours int, counted int) (string, error) {
+	if h.Limits.MaxMonthlyHours <= 0 {
+		return "", nil
+	}
+
+	used, err := h.OvertimeRepo.SumOvertimeHoursForMonth(employeeID, day.Year(), day.Month())
+	if err != nil {
+		return "", err
+	}
+	used -= counted
+	if used+hours <= h.Limits.MaxMonthlyHours {
+		return "", nil
+	}
+
+	remaining := h.Limits.MaxMonthlyHours - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return fmt.Sprintf("Monthly overtime cap of %d hours exceeded, %d hours remaining this month", h.Limits.MaxMonthlyHours, remaining), nil
+}
+
+// timeLocation is the server timezone overtime dates are taken in, the process local time when unset
+func (h *OvertimeHandler) timeLocation() *time.Location {
+	if h.Helper.TimeLocation != nil {
+		return h.Helper.TimeLocation
+	}
+	return time.Local
+}
+
+// ApproveOvertime approves a pending overtime if the approver's role meets the required tier for its hours
+func (h *OvertimeHandler) ApproveOvertime(c echo.Context) error {
+	overtime, ok, err := h.pendingOvertimeForDecision(c)
+	if !ok {
+		return err
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.OvertimeRepo.GetDB())
+
+	approverID, _ := c.Get("user_id").(int)
+	approved, err := h.OvertimeRepo.ApproveOvertimeWithAudit(overtime, uint(approverID), auditDB)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to approve overtime", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Overtime approved successfully", approved)
+}
+
+// RejectOvertime rejects a pending overtime, with the same role tier as approving it.
+// Rejected overtime is never paid.
+func (h *OvertimeHandler) RejectOvertime(c echo.Context) error {
+	overtime, ok, err := h.pendingOvertimeForDecision(c)
+	if !ok {
+		return err
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.OvertimeRepo.GetDB())
+
+	approverID, _ := c.Get("user_id").(int)
+	rejected, err := h.OvertimeRepo.RejectOvertimeWithAudit(overtime, uint(approverID), auditDB)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to reject overtime", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Overtime rejected successfully", rejected)
+}
+
+// pendingOvertimeForDecision loads the overtime of the :id param and checks it is still pending, is not the
+// caller's own and the caller's role may decide on its hours, managers only for their direct reports when
+// DirectManagerOnly is set.
+// When ok is false the error response has already been sent.
+func (h *OvertimeHandler) pendingOvertimeForDecision(c echo.Context) (*model.Overtime, bool, error) {
+	overtimeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, false, h.Response.SendBadRequest(c, "Invalid overtime ID", err.Error())
+	}
+
+	overtime, err := h.OvertimeRepo.GetOvertimeByID(uint(overtimeID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, false, h.Response.SendNotFound(c, "Overtime not found", nil)
+		}
+		return nil, false, h.Response.SendError(c, "Failed to retrieve overtime", err.Error())
+	}
+
+	if overtime.Status != model.OvertimePending {
+		message := fmt.Sprintf("Overtime is already %s, only pending overtime can be approved or rejected", overtime.Status)
+		return nil, false, h.Response.SendBadRequest(c, message, nil)
+	}
+
+	approverID, _ := c.Get("user_id").(int)
+	if uint(approverID) == overtime.EmployeeID {
+		return nil, false, h.Response.SendCustomResponse(c, 403, "Access denied. You cannot decide on your own overtime.", nil)
+	}
+
+	role, _ := c.Get("role").(string)
+	if !h.Approval.CanApprove(role, float64(overtime.Hours)) {
+		message := fmt.Sprintf("Access denied. Deciding on these hours requires the %s role.", h.Approval.RequiredRole(float64(overtime.Hours)))
+		return nil, false, h.Response.SendCustomResponse(c, 403, message, nil)
+	}
+
+	if role == "manager" && h.DirectManagerOnly {
+		employee, err := h.EmployeeRepo.GetEmployeeByID(overtime.EmployeeID)
+		if err != nil {
+			return nil, false, h.Response.SendError(c, "Failed to retrieve employee", err.Error())
+		}
+		if employee.ManagerID == nil || *employee.ManagerID != uint(approverID) {
+			return nil, false, h.Response.SendCustomResponse(c, 403, "Access denied. Only the employee's direct manager or an admin can decide on this overtime.", nil)
+		}
+	}
+
+	return overtime, true, nil
+}
+
+// ListOvertimes lists overtimes filtered by the optional employee_id, status, start_date and end_date
+// (YYYY-MM-DD) query params, paginated with page and limit. Admins can list any employee, everyone else
+// only sees their own overtime whatever employee_id says.
+func (h *OvertimeHandler) ListOvertimes(c echo.Context) error {
+	var filter request.OvertimeFilter
+	role, _ := c.Get("authenticated_role").(string)
+	userID, _ := c.Get("authenticated_user_id").(uint)
+	if role == "admin" {
+		if value := c.QueryParam("employee_id"); value != "" {
+			employeeID, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee_id", err.Error())
+			}
+			id := uint(employeeID)
+			filter.EmployeeID = &id
+		}
+	} else {
+		filter.EmployeeID = &userID
+	}
+
+	filter.Status = c.QueryParam("status")
+	switch model.OvertimeStatus(filter.Status) {
+	case "", model.OvertimePending, model.OvertimeApproved, model.OvertimeRejected:
+	default:
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid status, expected pending, approved or rejected", nil)
+	}
+
+	// Parsed only to validate them, the repository compares the YYYY-MM-DD strings as stored
+	if value := c.QueryParam("start_date"); value != "" {
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return h.Response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid start_date, expected YYYY-MM-DD", err.Error())
+		}
+		filter.StartDate = value
+	}
+	if value := c.QueryParam("end_date"); value != "" {
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return h.Response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid end_date, expected YYYY-MM-DD", err.Error())
+		}
+		filter.EndDate = value
+	}
+	if filter.StartDate != "" && filter.EndDate != "" && filter.EndDate < filter.StartDate {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "end_date must not be before start_date", nil)
+	}
+
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid page, expected a positive number", err.Error())
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid limit, expected a positive number", err.Error())
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	overtimes, total, err := h.OvertimeRepo.ListOvertimes(filter, (page-1)*limit, limit)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve overtimes", err.Error())
+	}
+
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.Response.SendPaginationResponse(c, overtimes, "Overtimes retrieved successfully", total, int64(limit), int64(len(overtimes)), totalPage, page)
+}
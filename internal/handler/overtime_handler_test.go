@@ -9,6 +9,12 @@
 // 4. Repository errors during creation
 // 5. Edge cases (zero amounts, large amounts, special characters)
 // 6. Performance benchmarks
+// 7. Approving and rejecting pending overtime, refusing a second decision and the approval tier
+// 8. Requests over the monthly overtime cap rejected with the remaining hours
+// 9. Correcting a pending overtime, refused once decided, for another employee's overtime or over the monthly cap
+// 10. Listing overtime, non-admins limited to their own whatever employee_id says, malformed filters rejected with 400
+// 11. With direct manager approval, only the employee's manager or an admin deciding on their overtime
+// 12. Managers and admins refused with 403 when deciding on their own overtime
 //
 // The tests use mocks to isolate the handler logic and ensure fast, reliable test execution.
 // The TestReimbursementHandler struct and related interfaces are created specifically for testing
@@ -17,6 +23,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -25,8 +32,14 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
 	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
 )
 
 // Helper function to create a test overtime
@@ -500,3 +513,319 @@ func TestOvertimeHandler_OvertimeJSON_Marshaling(t *testing.T) {
 		})
 	}
 }
+
+// setupOvertimeDecision stores a pending overtime of employee 1 with the given hours and a handler routing
+// up to 2 hours to managers and anything longer to admins
+func setupOvertimeDecision(t *testing.T, hours int) (*gorm.DB, *OvertimeHandler) {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Overtime{
+		DefaultAttribute: model.DefaultAttribute{ID: 1},
+		EmployeeID:       1,
+		OvertimeDate:     "2025-06-05",
+		Hours:            hours,
+		Reason:           "Release support",
+		Status:           model.OvertimePending,
+	}).Error)
+
+	handler := &OvertimeHandler{
+		Response:     response.NewResponse(),
+		OvertimeRepo: repository.NewOvertimeRepository(db),
+		Approval:     config.ApprovalRouting{{MaxAmount: 2, Role: "manager"}, {Role: "admin"}},
+	}
+	return db, handler
+}
+
+// decideOvertime runs the approve or reject handler for overtime 1 as the given caller
+func decideOvertime(t *testing.T, decide func(echo.Context) error, role string, userID int) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/overtime/1/decision", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("role", role)
+	c.Set("user_id", userID)
+
+	require.NoError(t, decide(c))
+	return rec
+}
+
+func TestOvertimeHandler_RejectOvertime_RecordsApprover(t *testing.T) {
+	db, handler := setupOvertimeDecision(t, 2)
+
+	rec := decideOvertime(t, handler.RejectOvertime, "manager", 7)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stored model.Overtime
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.OvertimeRejected, stored.Status)
+	require.NotNil(t, stored.ApprovedBy)
+	assert.Equal(t, uint(7), *stored.ApprovedBy)
+	assert.NotNil(t, stored.ApprovedAt)
+
+	// Rejected overtime is not paid
+	paid, err := repository.NewPayslipRepository(db).GetOvertimeForPeriod(1, "2025-06-01", "2025-06-30")
+	require.NoError(t, err)
+	assert.Empty(t, paid)
+}
+
+func TestOvertimeHandler_DecisionOnTerminalOvertime(t *testing.T) {
+	db, handler := setupOvertimeDecision(t, 2)
+
+	rec := decideOvertime(t, handler.ApproveOvertime, "admin", 9)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	for _, decide := range []func(echo.Context) error{handler.ApproveOvertime, handler.RejectOvertime} {
+		rec = decideOvertime(t, decide, "admin", 9)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Contains(t, body["message"], "already approved")
+	}
+
+	var stored model.Overtime
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.OvertimeApproved, stored.Status)
+}
+
+func TestOvertimeHandler_RejectOvertime_RequiresApprovalTier(t *testing.T) {
+	db, handler := setupOvertimeDecision(t, 3)
+
+	rec := decideOvertime(t, handler.RejectOvertime, "manager", 7)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	var stored model.Overtime
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.OvertimePending, stored.Status)
+}
+
+func TestOvertimeHandler_DecisionOnOwnOvertime(t *testing.T) {
+	db, handler := setupOvertimeDecision(t, 2)
+
+	for _, role := range []string{"manager", "admin"} {
+		for _, decide := range []func(echo.Context) error{handler.ApproveOvertime, handler.RejectOvertime} {
+			rec := decideOvertime(t, decide, role, 1)
+			assert.Equal(t, http.StatusForbidden, rec.Code, role)
+			assert.Contains(t, rec.Body.String(), "your own overtime")
+		}
+	}
+
+	var stored model.Overtime
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.OvertimePending, stored.Status)
+}
+
+func TestOvertimeHandler_ApproveOvertime_DirectManagerOnly(t *testing.T) {
+	db, handler := setupOvertimeDecision(t, 2)
+	handler.DirectManagerOnly = true
+	handler.EmployeeRepo = repository.NewEmployeeRepository(db)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 7}, Name: "Line Manager", Password: "hashed", Role: "manager", Active: true}).Error)
+	require.NoError(t, db.Model(&model.Employee{}).Where("id = ?", 1).Update("manager_id", 7).Error)
+
+	rec := decideOvertime(t, handler.ApproveOvertime, "manager", 8)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "direct manager")
+
+	var stored model.Overtime
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.OvertimePending, stored.Status)
+
+	rec = decideOvertime(t, handler.ApproveOvertime, "manager", 7)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.OvertimeApproved, stored.Status)
+}
+
+func TestOvertimeHandler_CreateOvertime_MonthlyCapExceeded(t *testing.T) {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+
+	// 7 of 10 hours used this month, rejected hours do not count
+	today := time.Now().Format("2006-01-02")
+	for _, overtime := range []model.Overtime{
+		{EmployeeID: 1, OvertimeDate: today, Hours: 4, Reason: "Release support", Status: model.OvertimeApproved},
+		{EmployeeID: 1, OvertimeDate: today, Hours: 3, Reason: "Release support", Status: model.OvertimePending},
+		{EmployeeID: 1, OvertimeDate: today, Hours: 3, Reason: "Release support", Status: model.OvertimeRejected},
+	} {
+		require.NoError(t, db.Create(&overtime).Error)
+	}
+
+	handler := &OvertimeHandler{
+		Response:     response.NewResponse(),
+		OvertimeRepo: repository.NewOvertimeRepository(db),
+		Limits:       config.OvertimeConfig{MaxMonthlyHours: 10},
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/overtime/create", strings.NewReader(`{"employee_id":1,"reason":"Incident follow-up","hours":4}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	require.NoError(t, handler.CreateOvertime(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "Monthly overtime cap of 10 hours exceeded, 3 hours remaining this month", body["message"])
+
+	var count int64
+	require.NoError(t, db.Model(&model.Overtime{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+// updateOvertime runs the update handler for overtime 1 as employee userID with the given JSON body
+func updateOvertime(t *testing.T, handler *OvertimeHandler, userID uint, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodPatch, "/overtime/1", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, handler.UpdateOvertime(c))
+	return rec
+}
+
+func TestOvertimeHandler_UpdateOvertime_CorrectsPendingOvertime(t *testing.T) {
+	db, handler := setupOvertimeDecision(t, 2)
+
+	rec := updateOvertime(t, handler, 1, `{"hours":3,"reason":"  Release support and rollback  ","overtime_date":"2025-06-06"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Data model.Overtime `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 3, body.Data.Hours)
+	assert.Equal(t, "2025-06-06", body.Data.OvertimeDate)
+
+	var stored model.Overtime
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, 3, stored.Hours)
+	assert.Equal(t, "Release support and rollback", stored.Reason)
+	assert.Equal(t, "2025-06-06", stored.OvertimeDate)
+	assert.Equal(t, model.OvertimePending, stored.Status)
+}
+
+func TestOvertimeHandler_UpdateOvertime_Refused(t *testing.T) {
+	t.Run("decided overtime", func(t *testing.T) {
+		db, handler := setupOvertimeDecision(t, 2)
+		require.NoError(t, db.Model(&model.Overtime{}).Where("id = ?", 1).Update("status", model.OvertimeApproved).Error)
+
+		rec := updateOvertime(t, handler, 1, `{"hours":1}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "already approved")
+	})
+
+	t.Run("another employee's overtime", func(t *testing.T) {
+		_, handler := setupOvertimeDecision(t, 2)
+
+		rec := updateOvertime(t, handler, 2, `{"hours":1}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("future date", func(t *testing.T) {
+		_, handler := setupOvertimeDecision(t, 2)
+
+		rec := updateOvertime(t, handler, 1, `{"overtime_date":"`+time.Now().AddDate(0, 0, 2).Format("2006-01-02")+`"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("monthly cap counts the corrected hours once", func(t *testing.T) {
+		db, handler := setupOvertimeDecision(t, 2)
+		handler.Limits = config.OvertimeConfig{MaxMonthlyHours: 6}
+		require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: "2025-06-10", Hours: 3, Reason: "Release support", Status: model.OvertimeApproved}).Error)
+
+		// 3 + 3 fits the cap, the 2 stored hours are replaced rather than added
+		rec := updateOvertime(t, handler, 1, `{"hours":3}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: "2025-06-11", Hours: 1, Reason: "Release support", Status: model.OvertimePending}).Error)
+		rec = updateOvertime(t, handler, 1, `{"hours":3}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "Monthly overtime cap of 6 hours exceeded, 2 hours remaining this month", body["message"])
+	})
+}
+
+// listOvertimes runs ListOvertimes with the query as the given caller, with overtime 1 of employee 1
+// on 2025-06-05 and approved overtime 2 of employee 2 on 2025-06-12 stored
+func listOvertimes(t *testing.T, query string, role string, userID uint) *httptest.ResponseRecorder {
+	db, handler := setupOvertimeDecision(t, 2)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Roe", Password: "hashed", Role: "manager", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Overtime{
+		DefaultAttribute: model.DefaultAttribute{ID: 2},
+		EmployeeID:       2,
+		OvertimeDate:     "2025-06-12",
+		Hours:            1,
+		Reason:           "Incident follow-up",
+		Status:           model.OvertimeApproved,
+	}).Error)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/overtime?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, handler.ListOvertimes(c))
+	return rec
+}
+
+func TestOvertimeHandler_ListOvertimes_ScopedToCaller(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		role   string
+		userID uint
+		want   []uint
+	}{
+		{"employee asking for another employee", "employee_id=2", "employee", 1, []uint{1}},
+		{"manager sees only their own", "", "manager", 2, []uint{2}},
+		{"admin filters by employee", "employee_id=2", "admin", 9, []uint{2}},
+		{"admin lists everyone", "", "admin", 9, []uint{2, 1}},
+		{"admin filters by status and dates", "status=pending&start_date=2025-06-05&end_date=2025-06-11", "admin", 9, []uint{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := listOvertimes(t, tt.query, tt.role, tt.userID)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var body struct {
+				Data struct {
+					Records []model.Overtime `json:"records"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			ids := []uint{}
+			for _, record := range body.Data.Records {
+				ids = append(ids, record.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestOvertimeHandler_ListOvertimes_InvalidFilters(t *testing.T) {
+	for query, errorCode := range map[string]string{
+		"start_date=2025-13-01":                     "PERIOD_INVALID",
+		"end_date=2025-6-1":                         "PERIOD_INVALID",
+		"status=paid":                               "INVALID_QUERY",
+		"start_date=2025-06-10&end_date=2025-06-01": "PERIOD_INVALID",
+		"employee_id=abc":                           "INVALID_ID",
+		"page=0":                                    "INVALID_QUERY",
+	} {
+		rec := listOvertimes(t, query, "admin", 9)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), `"error_code":"`+errorCode+`"`, query)
+	}
+}
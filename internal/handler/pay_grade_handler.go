@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PayGradeHandler handles pay grade (salary band) requests.
+type PayGradeHandler struct {
+	Helper   helper.NewHelper
+	DB       *gorm.DB
+	Response response.Interface
+
+	PayGradeRepo repository.PayGradeRepository
+}
+
+// CreatePayGrade creates a pay grade
+func (h *PayGradeHandler) CreatePayGrade(c echo.Context) error {
+	req := request.PayGradeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.PayGradeRepo.GetDB())
+
+	grade, err := h.PayGradeRepo.CreatePayGradeWithAudit(req, auditDB)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to create pay grade", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Pay grade created successfully", grade)
+}
+
+// GetAllPayGrades lists every pay grade
+func (h *PayGradeHandler) GetAllPayGrades(c echo.Context) error {
+	grades, err := h.PayGradeRepo.GetAllPayGrades()
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve pay grades", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Pay grades retrieved successfully", grades)
+}
+
+// GetPayGrade retrieves a pay grade
+func (h *PayGradeHandler) GetPayGrade(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid pay grade ID", err.Error())
+	}
+
+	grade, err := h.PayGradeRepo.GetPayGradeByID(uint(id))
+	if err != nil {
+		return h.Response.SendNotFound(c, "Pay grade not found", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Pay grade retrieved successfully", grade)
+}
+
+// EditPayGrade updates a pay grade. Salaries already assigned to employees are not changed.
+func (h *PayGradeHandler) EditPayGrade(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid pay grade ID", err.Error())
+	}
+
+	req := request.PayGradeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.PayGradeRepo.GetDB())
+
+	grade, err := h.PayGradeRepo.UpdatePayGradeWithAudit(uint(id), req, auditDB)
+	if err == gorm.ErrRecordNotFound {
+		return h.Response.SendNotFound(c, "Pay grade not found", err.Error())
+	}
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to update pay grade", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Pay grade updated successfully", grade)
+}
+
+// DeletePayGrade deletes a pay grade no employee is assigned to
+func (h *PayGradeHandler) DeletePayGrade(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid pay grade ID", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.PayGradeRepo.GetDB())
+
+	err = h.PayGradeRepo.DeletePayGradeWithAudit(uint(id), auditDB)
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return h.Response.SendNotFound(c, "Pay grade not found", err.Error())
+	case err == repository.ErrPayGradeInUse:
+		return h.Response.SendCustomResponse(c, http.StatusConflict, err.Error(), nil)
+	case err != nil:
+		return h.Response.SendError(c, "Failed to delete pay grade", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Pay grade deleted successfully", nil)
+}
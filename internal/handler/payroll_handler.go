@@ -1,14 +1,29 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/helper"
 	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/mailer"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
 	"github.com/yourname/payslip-system/internal/repository"
 	"github.com/yourname/payslip-system/internal/usecases"
+	"github.com/yourname/payslip-system/internal/webhook"
+	"gorm.io/gorm"
 )
 
 type PayrollHandler struct {
@@ -25,23 +40,53 @@ func NewPayrollHandler(payslipRepo repository.PayslipRepository, payrollUsecase
 	}
 }
 
+// periodLimitMessage states the configured maximum period length
+func (h *PayrollHandler) periodLimitMessage() string {
+	return fmt.Sprintf("Pay period exceeds the maximum of %d days", h.payrollUsecase.Config.MaxPeriodDays)
+}
+
 // RunPayrollForAllEmployees processes payroll for all active employees
 func (h *PayrollHandler) RunPayrollForAllEmployees(c echo.Context) error {
 	var req request.PayrollRequest
 	if err := c.Bind(&req); err != nil {
-		return h.response.SendBadRequest(c, "Invalid request body", err.Error())
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
-	// Validate the request
-	if req.PayPeriodEnd.Before(req.PayPeriodStart) {
-		return h.response.SendBadRequest(c, "Pay period end must be after start date", nil)
+	if ok, err := h.validateRequest(c, &req); !ok {
+		return err
+	}
+
+	if !h.payrollUsecase.Config.PeriodWithinLimit(req.PayPeriodStart, req.PayPeriodEnd) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
 	}
 
 	// Get auditable DB instance
 	auditDB := helper.GetAuditableDB(c, h.payslipRepo.GetDB())
 
-	// Process payroll using usecase with audit trail
-	processedPayslips, errors := h.payrollUsecase.ProcessAllEmployeesPayrollWithAudit(req, auditDB)
+	// Process payroll using usecase with audit trail, storing the run for reprocessing
+	run, processedPayslips, errors := h.payrollUsecase.ProcessPayrollRunWithAudit(req, auditDB)
+
+	logger := middleware.LoggerFrom(c).With(
+		"pay_period_start", req.PayPeriodStart.Format("2006-01-02"),
+		"pay_period_end", req.PayPeriodEnd.Format("2006-01-02"),
+	)
+	if run != nil {
+		logger = logger.With("run_id", run.ID)
+	}
+	logger.Info("payroll run processed", "processed_count", len(processedPayslips), "error_count", len(errors))
+	for _, runError := range errors {
+		logger.Warn("payroll run employee failed", "error", runError)
+	}
+
+	// Integrations hear of the run in the background, a failed delivery is logged and leaves the run as it is
+	if h.payrollUsecase.Webhooks != nil {
+		event := h.payrollUsecase.PayrollCompletedEvent(req, run, processedPayslips, errors)
+		go func() {
+			if err := h.payrollUsecase.Webhooks.Notify(webhook.EventPayrollCompleted, event); err != nil {
+				logger.Error("payroll webhook delivery failed", "error", err.Error())
+			}
+		}()
+	}
 
 	result := map[string]interface{}{
 		"processed_count": len(processedPayslips),
@@ -49,10 +94,29 @@ func (h *PayrollHandler) RunPayrollForAllEmployees(c echo.Context) error {
 		"payslips":        processedPayslips,
 	}
 
+	if run != nil {
+		result["run_id"] = run.ID
+	}
+
 	if len(errors) > 0 {
 		result["errors"] = errors
 	}
 
+	// Warnings flag processed payslips worth a review, they do not fail the run
+	var warnings []string
+	for _, payslip := range processedPayslips {
+		for _, warning := range payslip.Warnings {
+			warnings = append(warnings, fmt.Sprintf("Employee %d: %s", payslip.EmployeeID, warning))
+		}
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	if req.SendEmail {
+		result["email_results"] = h.emailRunPayslips(run, processedPayslips, auditDB, logger)
+	}
+
 	return h.response.SendSuccess(c, "Payroll processed", result)
 }
 
@@ -60,66 +124,194 @@ func (h *PayrollHandler) RunPayrollForAllEmployees(c echo.Context) error {
 func (h *PayrollHandler) RunPayrollForEmployee(c echo.Context) error {
 	var req request.PayrollEmployeeRequest
 	if err := c.Bind(&req); err != nil {
-		return h.response.SendBadRequest(c, "Invalid request body", err.Error())
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
-	// Validate the request
-	if req.PayPeriodEnd.Before(req.PayPeriodStart) {
-		return h.response.SendBadRequest(c, "Pay period end must be after start date", nil)
+	if ok, err := h.validateRequest(c, &req); !ok {
+		return err
+	}
+
+	if !h.payrollUsecase.Config.PeriodWithinLimit(req.PayPeriodStart, req.PayPeriodEnd) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
 	}
 
 	payrollReq := request.PayrollRequest{
-		PayPeriodStart: req.PayPeriodStart,
-		PayPeriodEnd:   req.PayPeriodEnd,
-		BasicSalary:    req.BasicSalary,
-		OvertimeRate:   req.OvertimeRate,
+		PayPeriodStart:     req.PayPeriodStart,
+		PayPeriodEnd:       req.PayPeriodEnd,
+		BasicSalary:        req.BasicSalary,
+		OvertimeRate:       req.OvertimeRate,
+		DeriveOvertimeRate: req.DeriveOvertimeRate,
+		Currency:           req.Currency,
 	}
 
 	// Get auditable DB instance
 	auditDB := helper.GetAuditableDB(c, h.payslipRepo.GetDB())
 
+	logger := middleware.LoggerFrom(c).With(
+		"target_employee_id", req.EmployeeID,
+		"pay_period_start", req.PayPeriodStart.Format("2006-01-02"),
+		"pay_period_end", req.PayPeriodEnd.Format("2006-01-02"),
+	)
 	payslip, err := h.payrollUsecase.ProcessEmployeePayrollWithAudit(req.EmployeeID, payrollReq, auditDB)
 	if err != nil {
-		return h.response.SendError(c, "Failed to process payroll", err.Error())
+		logger.Warn("employee payroll failed", "error", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodePayrollFailed, "Failed to process payroll", err.Error())
 	}
+	logger.Info("employee payroll processed", "payslip_id", payslip.ID, "net_amount", payslip.NetAmount)
 
 	return h.response.SendSuccess(c, "Payroll processed for employee", payslip)
 }
 
-// GetPayslipsByEmployee retrieves payslips for a specific employee
+// ReprocessEmployeeInRun retries a failed employee of a stored payroll run
+func (h *PayrollHandler) ReprocessEmployeeInRun(c echo.Context) error {
+	var runID, employeeID uint
+	if _, err := fmt.Sscanf(c.Param("run_id"), "%d", &runID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid run ID format", err.Error())
+	}
+	if _, err := fmt.Sscanf(c.Param("employee_id"), "%d", &employeeID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee ID format", err.Error())
+	}
+
+	run, err := h.payslipRepo.GetPayrollRunByID(runID)
+	if err != nil {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodePayrollRunNotFound, "Payroll run not found", err.Error())
+	}
+
+	runResult := run.ResultForEmployee(employeeID)
+	if runResult == nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeEmployeeNotInRun, "Employee is not part of this payroll run", nil)
+	}
+	if runResult.IsSucceeded() {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeEmployeeAlreadySucceeded, "Employee already succeeded in this payroll run", runResult)
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.payslipRepo.GetDB())
+
+	logger := middleware.LoggerFrom(c).With("run_id", run.ID, "target_employee_id", employeeID)
+	result, err := h.payrollUsecase.ReprocessEmployeeInRunWithAudit(run, employeeID, auditDB)
+	if err != nil {
+		logger.Warn("payroll run employee reprocess failed", "error", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodePayrollFailed, "Failed to reprocess employee", err.Error())
+	}
+	logger.Info("payroll run employee reprocessed", "status", result.Status, "processed_count", run.ProcessedCount, "error_count", run.ErrorCount)
+
+	return h.response.SendSuccess(c, "Employee reprocessed", map[string]interface{}{
+		"run_id":          run.ID,
+		"processed_count": run.ProcessedCount,
+		"error_count":     run.ErrorCount,
+		"result":          result,
+	})
+}
+
+// PreviewPayroll is a dry run of an employee's payroll for a period, e.g. before a payroll run.
+// Takes employee_id, start and end (YYYY-MM-DD), optionally basic_salary, overtime_rate, currency and
+// derive_overtime_rate like a payroll run. Nothing is stored and an existing payslip does not block it.
+func (h *PayrollHandler) PreviewPayroll(c echo.Context) error {
+	employeeID, err := strconv.ParseUint(c.QueryParam("employee_id"), 10, 32)
+	if err != nil || employeeID == 0 {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee_id", nil)
+	}
+	if c.QueryParam("start") == "" || c.QueryParam("end") == "" {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodRequired, "Pay period start and end dates are required", nil)
+	}
+	start, err := time.Parse("2006-01-02", c.QueryParam("start"))
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid start, expected YYYY-MM-DD", err.Error())
+	}
+	end, err := time.Parse("2006-01-02", c.QueryParam("end"))
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid end, expected YYYY-MM-DD", err.Error())
+	}
+	if end.Before(start) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Pay period end must be after start date", nil)
+	}
+	if !h.payrollUsecase.Config.PeriodWithinLimit(start, end) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
+	}
+
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart:     start,
+		PayPeriodEnd:       end,
+		Currency:           c.QueryParam("currency"),
+		DeriveOvertimeRate: c.QueryParam("derive_overtime_rate") == "true",
+	}
+	for _, amount := range []struct {
+		name   string
+		target *float64
+	}{{"basic_salary", &payrollReq.BasicSalary}, {"overtime_rate", &payrollReq.OvertimeRate}} {
+		value := c.QueryParam(amount.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return h.response.SendBadRequestWithCode(c, response.ErrCodeAmountInvalid, fmt.Sprintf("Invalid %s, expected a non-negative number", amount.name), nil)
+		}
+		*amount.target = parsed
+	}
+
+	if _, err := h.payslipRepo.GetEmployeeByID(uint(employeeID)); err != nil {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodeEmployeeNotFound, "Employee not found", err.Error())
+	}
+
+	preview, err := h.payrollUsecase.PreviewEmployeePayroll(uint(employeeID), payrollReq)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodePayrollFailed, "Failed to preview payroll", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Payroll preview generated, nothing was stored", preview)
+}
+
+// GetPayslipsByEmployee retrieves a page of payslips for a specific employee, newest period first.
+// Paginated with page (default 1) and limit (default 20, max 100).
 func (h *PayrollHandler) GetPayslipsByEmployee(c echo.Context) error {
 	employeeID := c.Param("id")
 	if employeeID == "" {
-		return h.response.SendBadRequest(c, "Employee ID is required", nil)
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Employee ID is required", nil)
 	}
 
 	// Convert string to uint
 	var empID uint
 	if _, err := fmt.Sscanf(employeeID, "%d", &empID); err != nil {
-		return h.response.SendBadRequest(c, "Invalid employee ID format", err.Error())
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee ID format", err.Error())
 	}
 
 	// Check authorization - employees can only access their own payslips
 	if !helper.ValidateEmployeeAccess(c, empID) {
-		return h.response.SendCustomResponse(c, 403, "Access denied. You can only access your own payslips.", nil)
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own payslips.", nil)
+	}
+
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid page, expected a positive number", err.Error())
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid limit, expected a positive number", err.Error())
+	}
+	if limit > 100 {
+		limit = 100
 	}
 
 	// Get employee to verify existence
 	employee, err := h.payslipRepo.GetEmployeeByID(empID)
 	if err != nil {
-		return h.response.SendError(c, "Employee not found", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodeEmployeeNotFound, "Employee not found", err.Error())
 	}
 
-	// Get all payslips for the employee
-	payslips, err := h.payslipRepo.GetPayslipsByEmployee(empID)
+	// Get the requested page of payslips for the employee
+	payslips, total, err := h.payslipRepo.GetPayslipsByEmployeePaged(empID, (page-1)*limit, limit)
 	if err != nil {
-		return h.response.SendError(c, "Failed to retrieve payslips", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslips", err.Error())
 	}
 
 	// Convert to response format
-	var payslipList []map[string]interface{}
+	payslipList := make([]map[string]interface{}, 0, len(payslips))
 	for _, payslip := range payslips {
 		payslipList = append(payslipList, map[string]interface{}{
+			"employee_id":      employee.ID,
+			"employee_name":    employee.Name,
 			"payslip_id":       payslip.ID,
 			"pay_period_start": payslip.PayPeriodStart,
 			"pay_period_end":   payslip.PayPeriodEnd,
@@ -129,88 +321,487 @@ func (h *PayrollHandler) GetPayslipsByEmployee(c echo.Context) error {
 		})
 	}
 
-	result := map[string]interface{}{
-		"employee_id":   employee.ID,
-		"employee_name": employee.Name,
-		"payslips":      payslipList,
-		"total_count":   len(payslips),
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.response.SendPaginationResponse(c, payslipList, "Payslips retrieved successfully", total, int64(limit), int64(len(payslipList)), totalPage, page)
+}
+
+// GetPayslips lists the payslips of a period one page at a time, optionally filtered by status and
+// employee and ordered by employee ID or total amount
+func (h *PayrollHandler) GetPayslips(c echo.Context) error {
+	var filter request.PayslipFilter
+	if c.QueryParam("start") == "" || c.QueryParam("end") == "" {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodRequired, "Pay period start and end dates are required", nil)
+	}
+	start, err := time.Parse("2006-01-02", c.QueryParam("start"))
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid start, expected YYYY-MM-DD", err.Error())
+	}
+	end, err := time.Parse("2006-01-02", c.QueryParam("end"))
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid end, expected YYYY-MM-DD", err.Error())
+	}
+	filter.PayPeriodStart = start
+	filter.PayPeriodEnd = end
+	if filter.PayPeriodEnd.Before(filter.PayPeriodStart) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Pay period end must be after start date", nil)
+	}
+	if !h.payrollUsecase.Config.PeriodWithinLimit(filter.PayPeriodStart, filter.PayPeriodEnd) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
+	}
+
+	filter.Status = c.QueryParam("status")
+	switch filter.Status {
+	case "", model.PayslipStatusProcessed, model.PayslipStatusPaid, model.PayslipStatusVoid:
+	default:
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid status, expected processed, paid or void", nil)
+	}
+
+	if value := c.QueryParam("employee_id"); value != "" {
+		employeeID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee_id", err.Error())
+		}
+		id := uint(employeeID)
+		filter.EmployeeID = &id
+	}
+
+	// Only whitelisted columns reach the ORDER BY clause
+	filter.Sort = c.QueryParam("sort")
+	switch filter.Sort {
+	case "", request.PayslipSortEmployeeID, request.PayslipSortTotalAmount:
+	default:
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid sort, expected employee_id or total_amount", nil)
+	}
+	switch strings.ToLower(c.QueryParam("order")) {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid order, expected asc or desc", nil)
 	}
 
-	return h.response.SendSuccess(c, "Payslips retrieved successfully", result)
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid page, expected a positive number", err.Error())
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid limit, expected a positive number", err.Error())
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	payslips, total, err := h.payslipRepo.ListPayslips(filter, (page-1)*limit, limit)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslips", err.Error())
+	}
+
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.response.SendPaginationResponse(c, payslips, "Payslips retrieved successfully", total, int64(limit), int64(len(payslips)), totalPage, page)
+}
+
+// GetProjectedPay estimates the pay of an in-progress period, optionally including pending items
+func (h *PayrollHandler) GetProjectedPay(c echo.Context) error {
+	employeeID := c.Param("id")
+	if employeeID == "" {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Employee ID is required", nil)
+	}
+
+	var empID uint
+	if _, err := fmt.Sscanf(employeeID, "%d", &empID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee ID format", err.Error())
+	}
+
+	// Check authorization - employees can only access their own projection
+	if !helper.ValidateEmployeeAccess(c, empID) {
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own projected pay.", nil)
+	}
+
+	var req request.ProjectedPayRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	// Validate the request
+	if req.PayPeriodStart.IsZero() || req.PayPeriodEnd.IsZero() {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodRequired, "Pay period start and end dates are required", nil)
+	}
+	if req.PayPeriodStart.After(req.PayPeriodEnd) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Pay period start date must be before end date", nil)
+	}
+
+	if !h.payrollUsecase.Config.PeriodWithinLimit(req.PayPeriodStart, req.PayPeriodEnd) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
+	}
+	if req.BasicSalary < 0 || req.OvertimeRate < 0 {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeAmountInvalid, "Basic salary and overtime rate must not be negative", nil)
+	}
+
+	projection, err := h.payrollUsecase.GetProjectedPay(empID, req)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to project pay", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Projected pay generated successfully", projection)
 }
 
 // GetDetailedPayslip generates a detailed payslip with all breakdowns
 func (h *PayrollHandler) GetDetailedPayslip(c echo.Context) error {
 	payslipID := c.Param("payslip_id")
 	if payslipID == "" {
-		return h.response.SendBadRequest(c, "Payslip ID is required", nil)
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Payslip ID is required", nil)
 	}
 
 	// Convert string to uint
 	var pID uint
 	if _, err := fmt.Sscanf(payslipID, "%d", &pID); err != nil {
-		return h.response.SendBadRequest(c, "Invalid payslip ID format", err.Error())
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid payslip ID format", err.Error())
 	}
 
 	// Get payslip
 	payslip, err := h.payslipRepo.GetPayslipByID(pID)
 	if err != nil {
-		return h.response.SendError(c, "Payslip not found", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodePayslipNotFound, "Payslip not found", err.Error())
 	}
 
 	// Check authorization - employees can only access their own payslips
 	if !helper.ValidateEmployeeAccess(c, payslip.EmployeeID) {
-		return h.response.SendCustomResponse(c, 403, "Access denied. You can only access your own payslips.", nil)
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own payslips.", nil)
+	}
+
+	detailedPayslip, message, err := h.buildDetailedPayslip(payslip)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, message, err.Error())
 	}
 
+	return h.response.SendSuccess(c, "Detailed payslip generated successfully", detailedPayslip)
+}
+
+// buildDetailedPayslip gathers the period records of a payslip and builds the detailed response.
+// On failure it returns the message to send along with the error.
+func (h *PayrollHandler) buildDetailedPayslip(payslip *model.Payslip) (res.DetailedPayslipResponse, string, error) {
 	// Get employee details
 	employee, err := h.payslipRepo.GetEmployeeByID(payslip.EmployeeID)
 	if err != nil {
-		return h.response.SendError(c, "Employee not found", err.Error())
+		return res.DetailedPayslipResponse{}, "Employee not found", err
 	}
 
-	// Get attendance breakdown
-	attendances, err := h.payslipRepo.GetAttendanceForPeriod(payslip.EmployeeID, payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	var records payslipRecords
+	if h.payrollUsecase.Config.ParallelDetailFetch {
+		records = h.fetchPayslipRecordsParallel(payslip)
+	} else {
+		records = h.fetchPayslipRecords(payslip)
+	}
+
+	// Report the first failure in the order the records are listed, whichever finished first
+	if records.attendanceErr != nil {
+		return res.DetailedPayslipResponse{}, "Failed to get attendance records", records.attendanceErr
+	}
+	if records.overtimeErr != nil {
+		return res.DetailedPayslipResponse{}, "Failed to get overtime records", records.overtimeErr
+	}
+	if records.reimbursementErr != nil {
+		return res.DetailedPayslipResponse{}, "Failed to get reimbursement records", records.reimbursementErr
+	}
+
+	// The allowances of the period are the allowance components still active, checked against the stored amount
+	var components []model.EmployeeComponent
+	if payslip.AllowanceAmount != 0 {
+		components, err = h.payslipRepo.GetActiveComponentsForEmployee(payslip.EmployeeID)
+		if err != nil {
+			return res.DetailedPayslipResponse{}, "Failed to get allowances", err
+		}
+	}
+
+	// Build detailed response
+	detail := h.payrollUsecase.BuildDetailedPayslipResponse(payslip, employee, records.attendances, records.overtimes, records.reimbursements)
+	detail.Allowances = h.payrollUsecase.BuildAllowanceBreakdown(payslip, components)
+	return detail, "", nil
+}
+
+// payslipRecords holds the period records of a payslip, each with the error of its query
+type payslipRecords struct {
+	attendances      []model.Attendance
+	attendanceErr    error
+	overtimes        []model.Overtime
+	overtimeErr      error
+	reimbursements   []model.Reimbursement
+	reimbursementErr error
+}
+
+// fetchPayslipRecords runs the period queries one after another, stopping at the first failure
+func (h *PayrollHandler) fetchPayslipRecords(payslip *model.Payslip) payslipRecords {
+	var records payslipRecords
+	period := h.payrollUsecase.Config.PeriodRange(payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	if records.fetchAttendances(h.payslipRepo, payslip.EmployeeID, period); records.attendanceErr != nil {
+		return records
+	}
+	if records.fetchOvertimes(h.payslipRepo, payslip.EmployeeID, period); records.overtimeErr != nil {
+		return records
+	}
+	records.fetchReimbursements(h.payslipRepo, payslip.EmployeeID, period)
+	return records
+}
+
+// fetchPayslipRecordsParallel runs the independent period queries concurrently and waits for all of them
+func (h *PayrollHandler) fetchPayslipRecordsParallel(payslip *model.Payslip) payslipRecords {
+	var records payslipRecords
+	period := h.payrollUsecase.Config.PeriodRange(payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		records.fetchAttendances(h.payslipRepo, payslip.EmployeeID, period)
+	}()
+	go func() {
+		defer wg.Done()
+		records.fetchOvertimes(h.payslipRepo, payslip.EmployeeID, period)
+	}()
+	go func() {
+		defer wg.Done()
+		records.fetchReimbursements(h.payslipRepo, payslip.EmployeeID, period)
+	}()
+	wg.Wait()
+	return records
+}
+
+// fetchAttendances loads the attendance breakdown
+func (r *payslipRecords) fetchAttendances(repo repository.PayslipRepository, employeeID uint, period config.PeriodRange) {
+	r.attendances, r.attendanceErr = repo.GetAttendanceForPeriod(employeeID, period.Start, period.End)
+}
+
+// fetchOvertimes loads the overtime breakdown
+func (r *payslipRecords) fetchOvertimes(repo repository.PayslipRepository, employeeID uint, period config.PeriodRange) {
+	r.overtimes, r.overtimeErr = repo.GetOvertimeForPeriod(employeeID, period.StartDate, period.EndDate)
+}
+
+// fetchReimbursements loads the reimbursement breakdown
+func (r *payslipRecords) fetchReimbursements(repo repository.PayslipRepository, employeeID uint, period config.PeriodRange) {
+	r.reimbursements, r.reimbursementErr = repo.GetApprovedReimbursementsForPeriod(employeeID, period.Start, period.End)
+}
+
+// GetPayslipPDF renders the detailed payslip as a downloadable PDF
+func (h *PayrollHandler) GetPayslipPDF(c echo.Context) error {
+	var pID uint
+	if _, err := fmt.Sscanf(c.Param("payslip_id"), "%d", &pID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid payslip ID format", err.Error())
+	}
+
+	payslip, err := h.payslipRepo.GetPayslipByID(pID)
+	if err == gorm.ErrRecordNotFound {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodePayslipNotFound, "Payslip not found", err.Error())
+	}
 	if err != nil {
-		return h.response.SendError(c, "Failed to get attendance records", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslip", err.Error())
 	}
 
-	// Get overtime breakdown
-	dateStart := payslip.PayPeriodStart.Format("2006-01-02")
-	dateEnd := payslip.PayPeriodEnd.Format("2006-01-02")
-	overtimes, err := h.payslipRepo.GetOvertimeForPeriod(payslip.EmployeeID, dateStart, dateEnd)
+	// Check authorization - employees can only access their own payslips
+	if !helper.ValidateEmployeeAccess(c, payslip.EmployeeID) {
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own payslips.", nil)
+	}
+
+	detailedPayslip, message, err := h.buildDetailedPayslip(payslip)
 	if err != nil {
-		return h.response.SendError(c, "Failed to get overtime records", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, message, err.Error())
+	}
+
+	filename := usecases.PayslipPDFFilename(payslip)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, "application/pdf", h.payrollUsecase.BuildPayslipPDF(detailedPayslip))
+}
+
+// ExportPayslipJSON downloads the detailed payslip as a JSON file, wrapped with the export schema version
+func (h *PayrollHandler) ExportPayslipJSON(c echo.Context) error {
+	var pID uint
+	if _, err := fmt.Sscanf(c.Param("payslip_id"), "%d", &pID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid payslip ID format", err.Error())
 	}
 
-	// Get reimbursement breakdown
-	reimbursements, err := h.payslipRepo.GetApprovedReimbursementsForPeriod(payslip.EmployeeID, payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	payslip, err := h.payslipRepo.GetPayslipByID(pID)
+	if err == gorm.ErrRecordNotFound {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodePayslipNotFound, "Payslip not found", err.Error())
+	}
 	if err != nil {
-		return h.response.SendError(c, "Failed to get reimbursement records", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslip", err.Error())
 	}
 
-	// Build detailed response
-	detailedPayslip := h.payrollUsecase.BuildDetailedPayslipResponse(payslip, employee, attendances, overtimes, reimbursements)
+	// Check authorization - employees can only access their own payslips
+	if !helper.ValidateEmployeeAccess(c, payslip.EmployeeID) {
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own payslips.", nil)
+	}
 
-	return h.response.SendSuccess(c, "Detailed payslip generated successfully", detailedPayslip)
+	detailedPayslip, message, err := h.buildDetailedPayslip(payslip)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, message, err.Error())
+	}
+
+	filename := usecases.PayslipJSONFilename(payslip)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSONPretty(http.StatusOK, res.PayslipExport{
+		SchemaVersion: res.PayslipExportSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Payslip:       detailedPayslip,
+	}, "  ")
+}
+
+// EmailPayslip sends a payslip to its employee's email address with the PDF attached
+func (h *PayrollHandler) EmailPayslip(c echo.Context) error {
+	var pID uint
+	if _, err := fmt.Sscanf(c.Param("payslip_id"), "%d", &pID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid payslip ID format", err.Error())
+	}
+
+	payslip, err := h.payslipRepo.GetPayslipByID(pID)
+	if err == gorm.ErrRecordNotFound {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodePayslipNotFound, "Payslip not found", err.Error())
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslip", err.Error())
+	}
+
+	employee, err := h.sendPayslipEmail(payslip)
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return h.response.SendNotFoundWithCode(c, response.ErrCodeEmployeeNotFound, "Employee not found", err.Error())
+	case err == usecases.ErrEmployeeNoEmail:
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeEmployeeEmailMissing, "Employee has no email address", nil)
+	case err == mailer.ErrNotConfigured:
+		return h.response.SendCustomResponseWithCode(c, http.StatusServiceUnavailable, response.ErrCodeMailNotConfigured, err.Error(), nil)
+	case err != nil:
+		return h.response.SendErrorWithCode(c, response.ErrCodeEmailFailed, "Failed to email payslip", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Payslip emailed", map[string]interface{}{
+		"payslip_id": payslip.ID,
+		"email":      employee.Email,
+	})
+}
+
+// sendPayslipEmail emails a payslip to its employee, returning the employee it was sent to
+func (h *PayrollHandler) sendPayslipEmail(payslip *model.Payslip) (*model.Employee, error) {
+	employee, err := h.payslipRepo.GetEmployeeByID(payslip.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	// Checked before building the payslip, it would be built for nothing
+	if !employee.HasEmail() {
+		return nil, usecases.ErrEmployeeNoEmail
+	}
+
+	detail, message, err := h.buildDetailedPayslip(payslip)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", message, err)
+	}
+	if err := h.payrollUsecase.EmailPayslip(employee, payslip, detail); err != nil {
+		return nil, err
+	}
+	return employee, nil
+}
+
+// emailRunPayslips emails each processed payslip of a payroll run and records the outcome on the run's results.
+// Employees without an email address, or every employee while mail is not configured, are skipped;
+// a failed email never fails the run.
+func (h *PayrollHandler) emailRunPayslips(run *model.PayrollRun, payslips []model.Payslip, auditDB *middleware.AuditableDB, logger *slog.Logger) []res.PayslipEmailResult {
+	outcomes := make([]res.PayslipEmailResult, 0, len(payslips))
+	for i := range payslips {
+		outcome := res.PayslipEmailResult{EmployeeID: payslips[i].EmployeeID, PayslipID: payslips[i].ID, Status: model.PayslipEmailSent}
+		if _, err := h.sendPayslipEmail(&payslips[i]); err != nil {
+			outcome.Status = model.PayslipEmailFailed
+			if err == usecases.ErrEmployeeNoEmail || err == mailer.ErrNotConfigured {
+				outcome.Status = model.PayslipEmailSkipped
+			}
+			outcome.Reason = err.Error()
+		}
+		outcomes = append(outcomes, outcome)
+
+		// The run is only missing when storing it failed, the outcome is still reported
+		if run == nil {
+			continue
+		}
+		if result := run.ResultForEmployee(outcome.EmployeeID); result != nil {
+			result.RecordEmail(outcome.Status, outcome.Reason)
+			if err := h.payslipRepo.UpdatePayrollRunResultWithAudit(run, result, auditDB); err != nil {
+				logger.Error("failed to record the payslip email in the payroll run", "target_employee_id", outcome.EmployeeID, "error", err.Error())
+			}
+		}
+	}
+	return outcomes
+}
+
+// GetDeductionBreakdown lists each deduction of a payslip with its amount and basis
+func (h *PayrollHandler) GetDeductionBreakdown(c echo.Context) error {
+	var pID uint
+	if _, err := fmt.Sscanf(c.Param("payslip_id"), "%d", &pID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid payslip ID format", err.Error())
+	}
+
+	payslip, err := h.payslipRepo.GetPayslipByID(pID)
+	if err == gorm.ErrRecordNotFound {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodePayslipNotFound, "Payslip not found", err.Error())
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslip", err.Error())
+	}
+
+	// Check authorization - employees can only access their own payslips
+	if !helper.ValidateEmployeeAccess(c, payslip.EmployeeID) {
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own payslips.", nil)
+	}
+
+	breakdown, err := h.payrollUsecase.BuildDeductionBreakdown(payslip)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build deduction breakdown", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Deduction breakdown retrieved successfully", breakdown)
+}
+
+// GetPayslipApprovalChain lists every approval that fed a payslip, for handling disputes
+func (h *PayrollHandler) GetPayslipApprovalChain(c echo.Context) error {
+	payslipID := c.Param("payslip_id")
+	if payslipID == "" {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Payslip ID is required", nil)
+	}
+
+	// Convert string to uint
+	var pID uint
+	if _, err := fmt.Sscanf(payslipID, "%d", &pID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid payslip ID format", err.Error())
+	}
+
+	payslip, err := h.payslipRepo.GetPayslipByID(pID)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodePayslipNotFound, "Payslip not found", err.Error())
+	}
+
+	chain, err := h.payrollUsecase.BuildPayslipApprovalChain(payslip)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build approval chain", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Payslip approval chain retrieved successfully", chain)
 }
 
 // GetPayrollSummary generates a summary of all employee payslips for a period
 func (h *PayrollHandler) GetPayrollSummary(c echo.Context) error {
 	var req request.PayrollSummaryRequest
 	if err := c.Bind(&req); err != nil {
-		return h.response.SendBadRequest(c, "Invalid request body", err.Error())
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
-	// Validate the request
-	if req.PayPeriodEnd.Before(req.PayPeriodStart) {
-		return h.response.SendBadRequest(c, "Pay period end must be after start date", nil)
+	if ok, err := h.validateRequest(c, &req); !ok {
+		return err
+	}
+
+	if !h.payrollUsecase.Config.PeriodWithinLimit(req.PayPeriodStart, req.PayPeriodEnd) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
 	}
 
 	// Get all payslips for the period
 	payslips, err := h.payslipRepo.GetPayslipsByPeriod(req.PayPeriodStart, req.PayPeriodEnd)
 	if err != nil {
-		return h.response.SendError(c, "Failed to retrieve payslips", err.Error())
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslips", err.Error())
 	}
 
 	// Build summary data
@@ -218,3 +809,496 @@ func (h *PayrollHandler) GetPayrollSummary(c echo.Context) error {
 
 	return h.response.SendSuccess(c, "Payroll summary generated successfully", summary)
 }
+
+// ComparePayrollPeriods compares the payroll summaries of two periods side by side, e.g. month over month
+func (h *PayrollHandler) ComparePayrollPeriods(c echo.Context) error {
+	var req request.PayrollCompareRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if ok, err := h.validateRequest(c, &req); !ok {
+		return err
+	}
+
+	summaries := make([]map[string]interface{}, 0, 2)
+	for _, period := range []request.PayrollSummaryRequest{req.Previous, req.Current} {
+		if !h.payrollUsecase.Config.PeriodWithinLimit(period.PayPeriodStart, period.PayPeriodEnd) {
+			return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
+		}
+
+		payslips, err := h.payslipRepo.GetPayslipsByPeriod(period.PayPeriodStart, period.PayPeriodEnd)
+		if err != nil {
+			return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslips", err.Error())
+		}
+		summaries = append(summaries, h.payrollUsecase.BuildPayrollSummary(payslips))
+	}
+
+	comparison := res.PayrollComparison{
+		PreviousPeriodStart: req.Previous.PayPeriodStart,
+		PreviousPeriodEnd:   req.Previous.PayPeriodEnd,
+		CurrentPeriodStart:  req.Current.PayPeriodStart,
+		CurrentPeriodEnd:    req.Current.PayPeriodEnd,
+		Currencies:          h.payrollUsecase.ComparePayrollSummaries(summaries[0], summaries[1]),
+	}
+
+	return h.response.SendSuccess(c, "Payroll comparison generated successfully", comparison)
+}
+
+// GetPayrollSummaryCSV exports the payslips of a period as CSV for spreadsheet imports.
+// The period is read from the request body or from the pay_period_start and pay_period_end query params (YYYY-MM-DD).
+func (h *PayrollHandler) GetPayrollSummaryCSV(c echo.Context) error {
+	var req request.PayrollSummaryRequest
+	if ok, err := h.bindSummaryExportPeriod(c, &req); !ok {
+		return err
+	}
+
+	// Get all payslips for the period
+	payslips, err := h.payslipRepo.GetPayslipsByPeriod(req.PayPeriodStart, req.PayPeriodEnd)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslips", err.Error())
+	}
+
+	// Stream the rows, the status can no longer change once writing starts
+	filename := fmt.Sprintf("payroll-summary-%s-%s.csv", req.PayPeriodStart.Format("2006-01-02"), req.PayPeriodEnd.Format("2006-01-02"))
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	if err := h.payrollUsecase.WritePayrollSummaryCSV(c.Response(), payslips); err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
+
+// GetPayrollSummaryXLSX downloads the payroll summary of a period as an Excel workbook, with a summary sheet of
+// the totals and averages per currency and a detail sheet of the payslips. The period is read like the CSV export.
+func (h *PayrollHandler) GetPayrollSummaryXLSX(c echo.Context) error {
+	var req request.PayrollSummaryRequest
+	if ok, err := h.bindSummaryExportPeriod(c, &req); !ok {
+		return err
+	}
+
+	payslips, err := h.payslipRepo.GetPayslipsByPeriod(req.PayPeriodStart, req.PayPeriodEnd)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve payslips", err.Error())
+	}
+
+	workbook, err := h.payrollUsecase.BuildPayrollSummaryXLSX(payslips)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build payroll summary workbook", err.Error())
+	}
+	defer workbook.Close()
+
+	// Stream the workbook, the status can no longer change once writing starts
+	filename := fmt.Sprintf("payroll-summary-%s-%s.xlsx", req.PayPeriodStart.Format("2006-01-02"), req.PayPeriodEnd.Format("2006-01-02"))
+	c.Response().Header().Set(echo.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	if err := workbook.Write(c.Response()); err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
+
+// bindSummaryExportPeriod reads the period of a summary export from the JSON body or the pay_period_start and
+// pay_period_end query params (YYYY-MM-DD), the params taking precedence, and validates it. When the period is
+// refused the error response has been sent and false is returned.
+func (h *PayrollHandler) bindSummaryExportPeriod(c echo.Context, req *request.PayrollSummaryRequest) (bool, error) {
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(req); err != nil {
+			return false, h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+	if value := c.QueryParam("pay_period_start"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return false, h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid pay_period_start, expected YYYY-MM-DD", err.Error())
+		}
+		req.PayPeriodStart = parsed
+	}
+	if value := c.QueryParam("pay_period_end"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return false, h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid pay_period_end, expected YYYY-MM-DD", err.Error())
+		}
+		req.PayPeriodEnd = parsed
+	}
+
+	if ok, err := h.validateRequest(c, req); !ok {
+		return false, err
+	}
+
+	if !h.payrollUsecase.Config.PeriodWithinLimit(req.PayPeriodStart, req.PayPeriodEnd) {
+		return false, h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
+	}
+	return true, nil
+}
+
+// GetPayrollForecast projects the monthly headcount and payroll cost over a horizon
+func (h *PayrollHandler) GetPayrollForecast(c echo.Context) error {
+	var req request.PayrollForecastRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	// Validate the request
+	if req.StartMonth.IsZero() {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodRequired, "Start month is required", nil)
+	}
+	if req.Months < 1 || req.Months > 36 {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Months must be between 1 and 36", nil)
+	}
+
+	employees, err := h.payslipRepo.GetEmployeesWithComponents()
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve employees", err.Error())
+	}
+
+	forecast := h.payrollUsecase.BuildPayrollForecast(employees, req.StartMonth, req.Months)
+
+	return h.response.SendSuccess(c, "Payroll forecast generated successfully", forecast)
+}
+
+// GetOvertimeConsistencyReport lists approved overtime logged on days without a present attendance.
+// Admins check every employee, managers their reporting subtree and themselves.
+func (h *PayrollHandler) GetOvertimeConsistencyReport(c echo.Context) error {
+	var req request.OvertimeConsistencyRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	// Validate the request
+	if req.PeriodEnd.Before(req.PeriodStart) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Period end must be after start date", nil)
+	}
+
+	var managerID uint
+	if role, _ := c.Get("authenticated_role").(string); role == "manager" {
+		managerID, _ = c.Get("authenticated_user_id").(uint)
+	}
+
+	report, err := h.payrollUsecase.BuildOvertimeConsistencyReport(req, managerID)
+	if err != nil {
+		if err == usecases.ErrEmployeeNotManaged {
+			return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only check employees you manage.", nil)
+		}
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build overtime consistency report", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Overtime consistency report generated successfully", report)
+}
+
+// GetTaxSummary returns the year-end tax summary of an employee, for the year in the query (YYYY, defaults to the current year)
+func (h *PayrollHandler) GetTaxSummary(c echo.Context) error {
+	var empID uint
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &empID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee ID format", err.Error())
+	}
+
+	year := time.Now().Year()
+	if value := c.QueryParam("year"); value != "" {
+		parsed, err := time.Parse("2006", value)
+		if err != nil {
+			return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid year, expected YYYY", err.Error())
+		}
+		year = parsed.Year()
+	}
+
+	// Check authorization - employees can only access their own tax summary
+	if !helper.ValidateEmployeeAccess(c, empID) {
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own tax summary.", nil)
+	}
+
+	employee, err := h.payslipRepo.GetEmployeeByID(empID)
+	if err == gorm.ErrRecordNotFound {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodeEmployeeNotFound, "Employee not found", err.Error())
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve employee", err.Error())
+	}
+
+	summary, err := h.payrollUsecase.BuildTaxSummary(employee, year)
+	if errors.Is(err, usecases.ErrMixedCurrencies) {
+		return h.response.SendCustomResponseWithCode(c, http.StatusConflict, response.ErrCodeCurrencyMixed, fmt.Sprintf("Cannot total the payslips, %v", err), nil)
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build tax summary", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Tax summary generated successfully", summary)
+}
+
+// GetYTDEarnings returns the year-to-date earnings of an employee, for the year in the query (YYYY, defaults to the current year)
+func (h *PayrollHandler) GetYTDEarnings(c echo.Context) error {
+	var empID uint
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &empID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee ID format", err.Error())
+	}
+
+	year := time.Now().Year()
+	if value := c.QueryParam("year"); value != "" {
+		parsed, err := time.Parse("2006", value)
+		if err != nil {
+			return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid year, expected YYYY", err.Error())
+		}
+		year = parsed.Year()
+	}
+
+	// Check authorization - employees can only access their own earnings
+	if !helper.ValidateEmployeeAccess(c, empID) {
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own earnings.", nil)
+	}
+
+	employee, err := h.payslipRepo.GetEmployeeByID(empID)
+	if err == gorm.ErrRecordNotFound {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodeEmployeeNotFound, "Employee not found", err.Error())
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve employee", err.Error())
+	}
+
+	earnings, err := h.payrollUsecase.BuildYTDEarnings(employee, year)
+	if errors.Is(err, usecases.ErrMixedCurrencies) {
+		return h.response.SendCustomResponseWithCode(c, http.StatusConflict, response.ErrCodeCurrencyMixed, fmt.Sprintf("Cannot total the payslips, %v. Narrow the range to one currency", err), nil)
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build year-to-date earnings", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Year-to-date earnings retrieved successfully", earnings)
+}
+
+// GetEmployeePayslipSummary returns the totals of an employee's payslips whose pay period starts between the
+// start and end query params (YYYY-MM-DD, both included), zero when there are none
+func (h *PayrollHandler) GetEmployeePayslipSummary(c echo.Context) error {
+	var empID uint
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &empID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee ID format", err.Error())
+	}
+	if c.QueryParam("start") == "" || c.QueryParam("end") == "" {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodRequired, "Start and end dates are required", nil)
+	}
+	start, err := time.Parse("2006-01-02", c.QueryParam("start"))
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid start, expected YYYY-MM-DD", err.Error())
+	}
+	end, err := time.Parse("2006-01-02", c.QueryParam("end"))
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid end, expected YYYY-MM-DD", err.Error())
+	}
+	if end.Before(start) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "End date must be after start date", nil)
+	}
+	if !h.payrollUsecase.Config.PeriodWithinLimit(start, end) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
+	}
+
+	// Check authorization - employees can only access their own summary
+	if !helper.ValidateEmployeeAccess(c, empID) {
+		return h.response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own payslip summary.", nil)
+	}
+
+	employee, err := h.payslipRepo.GetEmployeeByID(empID)
+	if err == gorm.ErrRecordNotFound {
+		return h.response.SendNotFoundWithCode(c, response.ErrCodeEmployeeNotFound, "Employee not found", err.Error())
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve employee", err.Error())
+	}
+
+	summary, err := h.payrollUsecase.BuildEmployeePayslipSummary(employee, start, end)
+	if errors.Is(err, usecases.ErrMixedCurrencies) {
+		return h.response.SendCustomResponseWithCode(c, http.StatusConflict, response.ErrCodeCurrencyMixed, fmt.Sprintf("Cannot total the payslips, %v. Narrow the range to one currency", err), nil)
+	}
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build payslip summary", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Employee payslip summary retrieved successfully", summary)
+}
+
+// GetPayrollKPIs returns the executive dashboard KPIs of a month (current month by default)
+func (h *PayrollHandler) GetPayrollKPIs(c echo.Context) error {
+	month := time.Now()
+	if value := c.QueryParam("month"); value != "" {
+		parsed, err := time.Parse("2006-01", value)
+		if err != nil {
+			return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid month, expected YYYY-MM", err.Error())
+		}
+		month = parsed
+	}
+
+	kpis, err := h.payrollUsecase.BuildPayrollKPIs(month)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to build payroll KPIs", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Payroll KPIs generated successfully", kpis)
+}
+
+// GetPayrollRunMetrics returns the history of stored payroll runs, newest first, with the employees
+// processed, error count and duration of each. Paginated with page (default 1) and limit (default 20, max 100).
+func (h *PayrollHandler) GetPayrollRunMetrics(c echo.Context) error {
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid page, expected a positive number", err.Error())
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid limit, expected a positive number", err.Error())
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	metrics, total, err := h.payrollUsecase.GetPayrollRunMetrics(page, limit)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to get payroll run metrics", err.Error())
+	}
+
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.response.SendPaginationResponse(c, metrics, "Payroll run metrics retrieved successfully", total, int64(limit), int64(len(metrics)), totalPage, page)
+}
+
+// GetPayPeriods lists the processed pay periods, newest first, with the payslip count and total payout of
+// each, per currency, e.g. for a period picker before requesting a payroll summary. Paginated with page
+// (default 1) and limit (default 20, max 100).
+func (h *PayrollHandler) GetPayPeriods(c echo.Context) error {
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid page, expected a positive number", err.Error())
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid limit, expected a positive number", err.Error())
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	periods, total, err := h.payslipRepo.GetPayPeriodsPage(page, limit)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to get pay periods", err.Error())
+	}
+
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.response.SendPaginationResponse(c, periods, "Pay periods retrieved successfully", total, int64(limit), int64(len(periods)), totalPage, page)
+}
+
+// validateRequest checks the request against its validate tags, sending one message per failing field.
+// When ok is false the error response has already been sent.
+func (h *PayrollHandler) validateRequest(c echo.Context, req interface{}) (bool, error) {
+	err := c.Validate(req)
+	if err == nil {
+		return true, nil
+	}
+	if validationErrors, isValidation := err.(validator.ValidationErrors); isValidation {
+		return false, h.response.SendValidationError(c, validationErrors)
+	}
+	return false, h.response.SendBadRequestWithCode(c, response.ErrCodeValidationFailed, "Validation failed", err.Error())
+}
+
+// positiveQueryInt parses a positive integer query parameter, the default when it is missing
+func positiveQueryInt(c echo.Context, name string, defaultValue int) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if parsed < 1 {
+		return 0, fmt.Errorf("%s must be at least 1", name)
+	}
+	return parsed, nil
+}
+
+// VoidPayslip voids a single payslip, keeping the record, so payroll can run again for its employee and period
+func (h *PayrollHandler) VoidPayslip(c echo.Context) error {
+	payslipID := c.Param("payslip_id")
+	if payslipID == "" {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Payslip ID is required", nil)
+	}
+
+	// Convert string to uint
+	var pID uint
+	if _, err := fmt.Sscanf(payslipID, "%d", &pID); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid payslip ID format", err.Error())
+	}
+
+	var req request.VoidPayslipRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.payslipRepo.GetDB())
+
+	payslip, err := h.payslipRepo.VoidPayslipWithAudit(pID, req.Reason, req.Version, auditDB)
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return h.response.SendNotFoundWithCode(c, response.ErrCodePayslipNotFound, "Payslip not found", err.Error())
+	case err == repository.ErrPayslipPaid, err == repository.ErrPayslipVoid:
+		return h.response.SendCustomResponseWithCode(c, http.StatusConflict, response.ErrCodePayslipNotVoidable, err.Error(), nil)
+	case err == middleware.ErrStaleVersion:
+		return h.response.SendCustomResponseWithCode(c, http.StatusConflict, response.ErrCodeVersionConflict, err.Error(), nil)
+	case err != nil:
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to void payslip", err.Error())
+	}
+
+	return h.response.SendSuccess(c, "Payslip voided", payslip)
+}
+
+// BulkVoidPayslips voids all (or a filtered subset of) payslips for a period in a single transaction
+func (h *PayrollHandler) BulkVoidPayslips(c echo.Context) error {
+	var req request.BulkVoidPayslipsRequest
+	if err := c.Bind(&req); err != nil {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	// Guard against accidental use
+	if !req.Confirm {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodeConfirmationRequired, "Confirmation required. Set confirm to true to void payslips", nil)
+	}
+
+	// Validate the request
+	if req.PayPeriodEnd.Before(req.PayPeriodStart) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Pay period end must be after start date", nil)
+	}
+
+	if !h.payrollUsecase.Config.PeriodWithinLimit(req.PayPeriodStart, req.PayPeriodEnd) {
+		return h.response.SendBadRequestWithCode(c, response.ErrCodePeriodTooLong, h.periodLimitMessage(), nil)
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.payslipRepo.GetDB())
+
+	voided, skipped, paid, err := h.payslipRepo.BulkVoidPayslipsWithAudit(req.PayPeriodStart, req.PayPeriodEnd, req.EmployeeIDs, req.Reason, auditDB)
+	if err != nil {
+		return h.response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to void payslips", err.Error())
+	}
+
+	result := res.BulkVoidResult{
+		VoidedCount:  len(voided),
+		SkippedCount: skipped,
+		FailedCount:  len(paid),
+		VoidedIDs:    make([]uint, 0, len(voided)),
+	}
+	for _, payslip := range voided {
+		result.VoidedIDs = append(result.VoidedIDs, payslip.ID)
+	}
+	for _, payslip := range paid {
+		result.Failures = append(result.Failures, res.BulkVoidFailure{
+			PayslipID:  payslip.ID,
+			EmployeeID: payslip.EmployeeID,
+			Reason:     repository.ErrPayslipPaid.Error(),
+		})
+	}
+
+	return h.response.SendSuccess(c, "Payslips voided", result)
+}
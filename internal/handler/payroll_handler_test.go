@@ -51,6 +51,101 @@
 // 6. Edge cases (same start/end date, future dates, large datasets)
 // 7. Performance benchmark for regular and large datasets
 //
+// GetPayrollSummaryCSV tests cover (real handler on an in-memory database):
+// 1. Header row and one row per payslip with comma containing fields quoted
+// 2. Period read from query params
+// 3. Missing period rejected
+//
+// GetPayrollSummaryXLSX tests cover (real handler on an in-memory database):
+// 1. A workbook attachment with per-currency totals on the summary sheet and one currency formatted row per payslip, headers frozen
+// 2. Missing period rejected without a file
+//
+// Payroll run warnings tests cover (real handler on an in-memory database):
+// 1. An employee paid without attendance listed under warnings, apart from errors, with the run still succeeding
+// 2. The processed and error counts and each failure logged with the request ID of the run
+//
+// ComparePayrollPeriods tests cover (real handler on an in-memory database):
+// 1. An empty previous period compared against a paid one without a percentage change
+// 2. A reversed period rejected
+//
+// Maximum period length tests cover (real handler on an in-memory database):
+// 1. A full-year summary within the default limit succeeding
+// 2. An over-limit summary and payroll run rejected with the limit stated before any query runs
+//
+// Payroll request validation tests cover (real handler on an in-memory database):
+// 1. Reversed periods, missing dates, employee_id 0 and negative amounts rejected with one message per field
+// 2. A period starting and ending on the same day accepted
+//
+// Detailed payslip record fetching tests cover (real handler on an in-memory database):
+// 1. The concurrent fetch producing the same detailed payslip as the serial one
+// 2. The attendance, overtime, reimbursement error order kept when several queries fail
+// 3. Benchmark of both paths against a repository with per-query latency
+//
+// GetTaxSummary tests cover (real handler on an in-memory database):
+// 1. Owner receiving the summary of the requested year, another employee denied with 403
+// 2. A year with payslips in more than one currency refused with 409 CURRENCY_MIXED
+//
+// GetYTDEarnings tests cover (real handler on an in-memory database):
+// 1. Owner and admin receiving the year's totals, another employee denied with 403
+// 2. An invalid year rejected and an unknown employee answered with 404
+//
+// GetEmployeePayslipSummary tests cover (real handler on an in-memory database):
+// 1. Owner and admin receiving the range's totals, a range without payslips zeroed, another employee denied with 403
+// 2. Missing, malformed, reversed and too long ranges rejected, an unknown employee answered with 404
+// 3. A range with payslips in more than one currency refused with 409 CURRENCY_MIXED
+//
+// GetPayslipsByEmployee pagination tests cover (real handler on an in-memory database):
+// 1. Pages of the requested limit, newest period first, with the total count
+// 2. Default and capped limit, non-positive or non-numeric page and limit rejected
+//
+// GetPayrollRunMetrics tests cover (real handler on an in-memory database):
+// 1. Recorded runs returned newest first with their counts and durations, split over pages
+// 2. Invalid pagination parameters rejected
+//
+// GetPayPeriods tests cover (real handler on an in-memory database):
+// 1. Processed periods newest first with payslip counts and payouts, split over pages
+// 2. Invalid pagination parameters rejected
+//
+// GetPayslipPDF tests cover (real handler on an in-memory database):
+// 1. Owner downloading a PDF attachment with the employee name and totals
+// 2. Another employee denied with 403
+// 3. Unknown payslip returning 404
+//
+// ExportPayslipJSON tests cover (real handler on an in-memory database):
+// 1. Owner downloading a JSON attachment carrying the schema version and the detailed payslip
+// 2. Another employee denied with 403
+// 3. Unknown payslip returning 404, a malformed ID 400
+//
+// Idempotent payroll run tests cover (real handler on an in-memory database):
+// 1. A retry with the same Idempotency-Key replaying the first response without a second run
+// 2. A retry while the first request is in flight rejected with 409
+//
+// GetPayslips tests cover (real handler on an in-memory database):
+// 1. Payslips of a period sorted by total amount in descending order, split over pages
+// 2. Missing or reversed periods, unknown statuses, sort columns and orders rejected with their error codes
+//
+// Payslip email tests cover (real handler on an in-memory database):
+// 1. A payslip emailed to its employee with the PDF attached
+// 2. Employees without an email address, unknown payslips and an unconfigured mail server rejected
+// 3. A payroll run with send_email skipping employees without an address, the outcome recorded on the run results
+//
+// Payroll webhook tests cover (real handler on an in-memory database):
+// 1. A finished run posting its signed summary, an endpoint failing every attempt leaving the run successful
+//
+// VoidPayslip tests cover (real handler on an in-memory database):
+// 1. A voided payslip kept while payroll for its employee and period runs again
+// 2. Paid and already voided payslips rejected with 409, unknown payslips with 404, each with its error code
+//
+// BulkVoidPayslips tests cover (real handler on an in-memory database):
+// 1. Voided, skipped and paid payslips counted, the paid one listed as a failure
+//
+// PreviewPayroll tests cover (real handler on an in-memory database):
+// 1. The detailed payslip of a period computed and marked preview, nothing stored even with an existing payslip
+// 2. Missing or invalid employee IDs, periods and amounts rejected, unknown employees with 404
+//
+// GetOvertimeConsistencyReport tests cover (real handler on an in-memory database):
+// 1. Managers limited to their reporting subtree, other employees refused with 403
+//
 // The tests use mocks to isolate the handler logic and ensure fast, reliable test execution.
 // The TestPayrollHandler struct and related interfaces are created specifically for testing
 // to avoid tight coupling with concrete implementations.
@@ -58,8 +153,12 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -69,18 +168,29 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/mailer"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"github.com/yourname/payslip-system/internal/usecases"
+	"github.com/yourname/payslip-system/internal/webhook"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // PayrollUsecaseInterface defines the interface for payroll usecase
 type PayrollUsecaseInterface interface {
 	ProcessAllEmployeesPayrollWithAudit(req request.PayrollRequest, auditDB *middleware.AuditableDB) ([]model.Payslip, []string)
 	ProcessEmployeePayrollWithAudit(employeeID uint, req request.PayrollRequest, auditDB *middleware.AuditableDB) (*model.Payslip, error)
-	BuildDetailedPayslipResponse(payslip *model.Payslip, employee *model.Employee, attendances []model.Attendance, overtimes []model.Overtime, reimbursements []model.Reimbursement) map[string]interface{}
+	BuildDetailedPayslipResponse(payslip *model.Payslip, employee *model.Employee, attendances []model.Attendance, overtimes []model.Overtime, reimbursements []model.Reimbursement) res.DetailedPayslipResponse
 	BuildPayrollSummary(payslips []model.Payslip) map[string]interface{}
 }
 
@@ -389,9 +499,9 @@ func (m *MockPayrollUsecase) ProcessEmployeePayrollWithAudit(employeeID uint, re
 	return args.Get(0).(*model.Payslip), args.Error(1)
 }
 
-func (m *MockPayrollUsecase) BuildDetailedPayslipResponse(payslip *model.Payslip, employee *model.Employee, attendances []model.Attendance, overtimes []model.Overtime, reimbursements []model.Reimbursement) map[string]interface{} {
+func (m *MockPayrollUsecase) BuildDetailedPayslipResponse(payslip *model.Payslip, employee *model.Employee, attendances []model.Attendance, overtimes []model.Overtime, reimbursements []model.Reimbursement) res.DetailedPayslipResponse {
 	args := m.Called(payslip, employee, attendances, overtimes, reimbursements)
-	return args.Get(0).(map[string]interface{})
+	return args.Get(0).(res.DetailedPayslipResponse)
 }
 
 func (m *MockPayrollUsecase) BuildPayrollSummary(payslips []model.Payslip) map[string]interface{} {
@@ -1973,13 +2083,10 @@ func TestPayrollHandler_GetDetailedPayslip_ValidRequest(t *testing.T) {
 		},
 	}
 
-	detailedResponse := map[string]interface{}{
-		"payslip_id":     payslipID,
-		"employee_name":  "John Doe",
-		"total_amount":   5500000.0,
-		"attendances":    attendances,
-		"overtimes":      overtimes,
-		"reimbursements": reimbursements,
+	detailedResponse := res.DetailedPayslipResponse{
+		PayslipID:    payslipID,
+		EmployeeName: "John Doe",
+		Summary:      res.PayslipSummary{TotalTakeHomePay: 5500000.0},
 	}
 
 	// Set up expectations
@@ -2049,13 +2156,10 @@ func TestPayrollHandler_GetDetailedPayslip_EmployeeAccessOwnPayslip(t *testing.T
 	overtimes := []model.Overtime{}
 	reimbursements := []model.Reimbursement{}
 
-	detailedResponse := map[string]interface{}{
-		"payslip_id":     payslipID,
-		"employee_name":  "John Doe",
-		"total_amount":   5500000.0,
-		"attendances":    attendances,
-		"overtimes":      overtimes,
-		"reimbursements": reimbursements,
+	detailedResponse := res.DetailedPayslipResponse{
+		PayslipID:    payslipID,
+		EmployeeName: "John Doe",
+		Summary:      res.PayslipSummary{TotalTakeHomePay: 5500000.0},
 	}
 
 	// Set up expectations
@@ -2499,7 +2603,7 @@ func TestPayrollHandler_GetDetailedPayslip_EdgeCases(t *testing.T) {
 				attendances := []model.Attendance{}
 				overtimes := []model.Overtime{}
 				reimbursements := []model.Reimbursement{}
-				detailedResponse := map[string]interface{}{"payslip_id": uint(0)}
+				detailedResponse := res.DetailedPayslipResponse{PayslipID: 0}
 
 				mockRepo.On("GetPayslipByID", uint(0)).Return(payslip, nil)
 				mockRepo.On("GetEmployeeByID", uint(1)).Return(employee, nil)
@@ -2525,7 +2629,7 @@ func TestPayrollHandler_GetDetailedPayslip_EdgeCases(t *testing.T) {
 				attendances := []model.Attendance{}
 				overtimes := []model.Overtime{}
 				reimbursements := []model.Reimbursement{}
-				detailedResponse := map[string]interface{}{"payslip_id": uint(9999999)}
+				detailedResponse := res.DetailedPayslipResponse{PayslipID: 9999999}
 
 				mockRepo.On("GetPayslipByID", uint(9999999)).Return(payslip, nil)
 				mockRepo.On("GetEmployeeByID", uint(1)).Return(employee, nil)
@@ -2623,7 +2727,7 @@ func BenchmarkPayrollHandler_GetDetailedPayslip(b *testing.B) {
 	attendances := []model.Attendance{}
 	overtimes := []model.Overtime{}
 	reimbursements := []model.Reimbursement{}
-	detailedResponse := map[string]interface{}{"payslip_id": payslipID}
+	detailedResponse := res.DetailedPayslipResponse{PayslipID: payslipID}
 
 	// Mock expectations (only set up once for all iterations)
 	mockRepo.On("GetPayslipByID", payslipID).Return(payslip, nil)
@@ -3159,3 +3263,1582 @@ func BenchmarkPayrollHandler_GetPayrollSummary_LargeDataset(b *testing.B) {
 		_ = handler.GetPayrollSummary(c)
 	}
 }
+
+// Tests for GetPayslipPDF
+
+// setupPayrollHandlerDB creates the real payroll handler on an in-memory database
+func setupPayrollHandlerDB(t *testing.T) (*gorm.DB, *PayrollHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.PayGrade{}, &model.Employee{}, &model.Payslip{}, &model.Attendance{}, &model.Overtime{}, &model.Reimbursement{}, &model.Holiday{}, &model.PayrollRun{}, &model.PayrollRunResult{}, &model.AuditLog{}, &model.RefreshToken{}))
+
+	// Every connection to :memory: opens its own database, so concurrent queries must share one
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	payslipRepo := repository.NewPayslipRepository(db)
+	handler := NewPayrollHandler(payslipRepo, usecases.NewPayrollUsecase(payslipRepo, repository.NewEmployeeRepository(db)), response.NewResponse())
+	return db, handler
+}
+
+// payslipPDFRequest runs the real GetPayslipPDF for a stored payslip of employee 1 as the given caller
+func payslipPDFRequest(t *testing.T, payslipID string, role string, userID uint) *httptest.ResponseRecorder {
+	return payslipDownloadRequest(t, "pdf", (*PayrollHandler).GetPayslipPDF, payslipID, role, userID)
+}
+
+// payslipDownloadRequest runs a payslip download handler for a stored payslip of employee 1 as the given caller
+func payslipDownloadRequest(t *testing.T, suffix string, download func(*PayrollHandler, echo.Context) error, payslipID string, role string, userID uint) *httptest.ResponseRecorder {
+	db, handler := setupPayrollHandlerDB(t)
+
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Payslip{
+		DefaultAttribute: model.DefaultAttribute{ID: 1},
+		EmployeeID:       1,
+		PayPeriodStart:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:     time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		BasicSalary:      5000000,
+		GrossAmount:      5000000,
+		NetAmount:        5000000,
+		TotalAmount:      5000000,
+		Currency:         "IDR",
+		ProcessedAt:      time.Now(),
+		Status:           model.PayslipStatusProcessed,
+	}).Error)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/payslip/"+payslipID+"/"+suffix, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("payslip_id")
+	c.SetParamValues(payslipID)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, download(handler, c))
+	return rec
+}
+
+func TestPayrollHandler_GetPayslipPDF_Owner(t *testing.T) {
+	rec := payslipPDFRequest(t, "1", "employee", 1)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="payslip-1-2025-06.pdf"`, rec.Header().Get(echo.HeaderContentDisposition))
+	body := rec.Body.String()
+	assert.True(t, strings.HasPrefix(body, "%PDF-1.4"))
+	assert.Contains(t, body, `(Employee: Jane Doe \(ID 1\))`)
+	assert.Contains(t, body, "(Period: 2025-06-01 to 2025-06-30)")
+	assert.Contains(t, body, "(5000000.00)")
+	assert.True(t, strings.HasSuffix(body, "%%EOF\n"))
+}
+
+func TestPayrollHandler_GetPayslipPDF_OtherEmployeeForbidden(t *testing.T) {
+	rec := payslipPDFRequest(t, "1", "employee", 2)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestPayrollHandler_GetPayslipPDF_NotFound(t *testing.T) {
+	rec := payslipPDFRequest(t, "99", "admin", 5)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// Tests for ExportPayslipJSON
+
+// payslipJSONRequest runs the real ExportPayslipJSON for a stored payslip of employee 1 as the given caller
+func payslipJSONRequest(t *testing.T, payslipID string, role string, userID uint) *httptest.ResponseRecorder {
+	return payslipDownloadRequest(t, "export.json", (*PayrollHandler).ExportPayslipJSON, payslipID, role, userID)
+}
+
+func TestPayrollHandler_ExportPayslipJSON_Owner(t *testing.T) {
+	rec := payslipJSONRequest(t, "1", "employee", 1)
+
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="payslip-1-2025-06.json"`, rec.Header().Get(echo.HeaderContentDisposition))
+
+	var export struct {
+		SchemaVersion int                    `json:"schema_version"`
+		ExportedAt    time.Time              `json:"exported_at"`
+		Payslip       map[string]interface{} `json:"payslip"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &export))
+	assert.Equal(t, res.PayslipExportSchemaVersion, export.SchemaVersion)
+	assert.False(t, export.ExportedAt.IsZero())
+	assert.Equal(t, float64(1), export.Payslip["payslip_id"])
+	assert.Equal(t, "IDR", export.Payslip["currency"])
+	assert.Equal(t, "Jane Doe", export.Payslip["employee_name"])
+}
+
+func TestPayrollHandler_ExportPayslipJSON_OtherEmployeeForbidden(t *testing.T) {
+	rec := payslipJSONRequest(t, "1", "employee", 2)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+}
+
+func TestPayrollHandler_ExportPayslipJSON_NotFound(t *testing.T) {
+	rec := payslipJSONRequest(t, "99", "admin", 5)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = payslipJSONRequest(t, "abc", "admin", 5)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// Tests for GetPayrollSummaryCSV
+
+// createCSVTestPayslips creates two June 2025 payslips, one for an employee with a comma in the name
+func createCSVTestPayslips(t *testing.T, db *gorm.DB) {
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Doe, Jane", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Smith", Password: "hashed", Role: "employee", Active: true}).Error)
+	for _, payslip := range []model.Payslip{
+		{EmployeeID: 1, BasicSalary: 5000000, OvertimeAmount: 100000, ReimbursementAmount: 50000, TotalAmount: 5150000},
+		{EmployeeID: 2, BasicSalary: 4000000, TotalAmount: 4000000},
+	} {
+		payslip.PayPeriodStart = time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+		payslip.PayPeriodEnd = time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+		payslip.Currency = "IDR"
+		payslip.ProcessedAt = time.Now()
+		payslip.Status = model.PayslipStatusProcessed
+		require.NoError(t, db.Create(&payslip).Error)
+	}
+}
+
+func TestPayrollHandler_GetPayrollSummaryCSV_Body(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	createCSVTestPayslips(t, db)
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	body := `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/payroll/summary/csv", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler.GetPayrollSummaryCSV(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="payroll-summary-2025-06-01-2025-06-30.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+	assert.Equal(t, "employee_id,employee_name,basic_salary,overtime_amount,reimbursement_amount,total_amount,status\n"+
+		"1,\"Doe, Jane\",5000000.00,100000.00,50000.00,5150000.00,processed\n"+
+		"2,John Smith,4000000.00,0.00,0.00,4000000.00,processed\n", rec.Body.String())
+}
+
+func TestPayrollHandler_GetPayrollSummaryCSV_QueryParams(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	createCSVTestPayslips(t, db)
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/summary/csv?pay_period_start=2025-06-01&pay_period_end=2025-06-30", nil)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler.GetPayrollSummaryCSV(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	assert.Len(t, lines, 3)
+}
+
+func TestPayrollHandler_GetPayrollSummaryCSV_MissingPeriod(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/summary/csv", nil)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler.GetPayrollSummaryCSV(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// Tests for GetPayrollSummaryXLSX
+
+func TestPayrollHandler_GetPayrollSummaryXLSX_Workbook(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	createCSVTestPayslips(t, db)
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/summary.xlsx?pay_period_start=2025-06-01&pay_period_end=2025-06-30", nil)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler.GetPayrollSummaryXLSX(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="payroll-summary-2025-06-01-2025-06-30.xlsx"`, rec.Header().Get(echo.HeaderContentDisposition))
+
+	workbook, err := excelize.OpenReader(rec.Body)
+	require.NoError(t, err)
+	defer workbook.Close()
+	assert.Equal(t, []string{"Summary", "Payslips"}, workbook.GetSheetList())
+
+	summary, err := workbook.GetRows("Summary")
+	require.NoError(t, err)
+	require.Len(t, summary, 2)
+	assert.Equal(t, []string{"Currency", "Employees", "Payslips", "Total Take Home Pay"}, summary[0][:4])
+	assert.Equal(t, []string{"IDR", "2", "2", "9,150,000.00 IDR"}, summary[1][:4])
+
+	details, err := workbook.GetRows("Payslips")
+	require.NoError(t, err)
+	require.Len(t, details, 3)
+	assert.Equal(t, "Employee Name", details[0][2])
+	assert.Equal(t, []string{"Doe, Jane", "2025-06-01", "2025-06-30", "IDR", "5,000,000.00 IDR"}, details[1][2:7])
+	assert.Equal(t, "processed", details[2][13])
+
+	// The raw value stays a number for formulas
+	raw, err := workbook.GetCellValue("Payslips", "G2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "5000000", raw)
+
+	panes, err := workbook.GetPanes("Payslips")
+	require.NoError(t, err)
+	assert.True(t, panes.Freeze)
+	assert.Equal(t, 1, panes.YSplit)
+}
+
+func TestPayrollHandler_GetPayrollSummaryXLSX_MissingPeriod(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/summary.xlsx", nil)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler.GetPayrollSummaryXLSX(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+}
+
+// Tests for payroll run warnings
+
+func TestPayrollHandler_RunPayrollForAllEmployees_Warnings(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.EmployeeComponent{}, &model.Timesheet{}, &model.TaxBracket{}))
+	hired := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, HireDate: &hired}).Error)
+
+	rec := postPayrollJSON(t, handler.RunPayrollForAllEmployees, `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z","basic_salary":5000000,"overtime_rate":50000}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data struct {
+			ProcessedCount int             `json:"processed_count"`
+			ErrorCount     int             `json:"error_count"`
+			Errors         []string        `json:"errors"`
+			Warnings       []string        `json:"warnings"`
+			Payslips       []model.Payslip `json:"payslips"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 1, body.Data.ProcessedCount)
+	assert.Zero(t, body.Data.ErrorCount)
+	assert.Empty(t, body.Data.Errors)
+	assert.Equal(t, []string{"Employee 1: no attendance recorded but a basic salary of 5000000.00 is paid"}, body.Data.Warnings)
+	require.Len(t, body.Data.Payslips, 1)
+	assert.Len(t, body.Data.Payslips[0].Warnings, 1)
+}
+
+func TestPayrollHandler_RunPayrollForAllEmployees_Logged(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.EmployeeComponent{}, &model.Timesheet{}, &model.TaxBracket{}))
+	hired := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, HireDate: &hired}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Doe", Password: "hashed", Role: "employee", Active: true, HireDate: &hired, Currency: "USD"}).Error)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	run := middleware.RequestID()(middleware.RequestLogger(logger)(handler.RunPayrollForAllEmployees))
+
+	// Employee 2 is paid in USD, so the IDR run fails for them
+	rec := postPayrollJSON(t, run, `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z","basic_salary":5000000,"overtime_rate":50000,"currency":"IDR"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	requestID := rec.Header().Get(echo.HeaderXRequestID)
+	require.NotEmpty(t, requestID)
+
+	entries := map[string]map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		assert.Equal(t, requestID, entry["request_id"], line)
+		entries[entry["msg"].(string)] = entry
+	}
+
+	processed := entries["payroll run processed"]
+	require.NotNil(t, processed)
+	assert.Equal(t, float64(1), processed["processed_count"])
+	assert.Equal(t, float64(1), processed["error_count"])
+	assert.Equal(t, "2025-06-01", processed["pay_period_start"])
+	assert.NotNil(t, processed["run_id"])
+
+	failed := entries["payroll run employee failed"]
+	require.NotNil(t, failed)
+	assert.Contains(t, failed["error"], "Employee 2")
+	assert.Contains(t, entries, "request")
+}
+
+// Tests for ComparePayrollPeriods
+
+func TestPayrollHandler_ComparePayrollPeriods_EmptyPreviousPeriod(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	createCSVTestPayslips(t, db)
+
+	rec := postPayrollJSON(t, handler.ComparePayrollPeriods, `{
+		"previous": {"pay_period_start":"2025-05-01T00:00:00Z","pay_period_end":"2025-05-31T00:00:00Z"},
+		"current": {"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z"}
+	}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Data res.PayrollComparison `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data.Currencies, 1)
+
+	idr := body.Data.Currencies[0]
+	assert.Equal(t, "IDR", idr.Currency)
+	assert.Equal(t, 0.0, idr.Headcount.Previous)
+	assert.Equal(t, 2.0, idr.Headcount.Current)
+	assert.Equal(t, 9150000.0, idr.TotalPayout.Delta)
+	assert.Equal(t, 4575000.0, idr.AveragePayout.Current)
+	assert.Equal(t, 100000.0, idr.OvertimeAmount.Delta)
+	assert.Nil(t, idr.TotalPayout.PercentChange)
+	assert.Contains(t, rec.Body.String(), `"percent_change":null`)
+}
+
+func TestPayrollHandler_ComparePayrollPeriods_ReversedPeriod(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	rec := postPayrollJSON(t, handler.ComparePayrollPeriods, `{
+		"previous": {"pay_period_start":"2025-05-01T00:00:00Z","pay_period_end":"2025-05-31T00:00:00Z"},
+		"current": {"pay_period_start":"2025-06-30T00:00:00Z","pay_period_end":"2025-06-01T00:00:00Z"}
+	}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "pay_period_end must not be before pay_period_start")
+}
+
+// Tests for the maximum period length
+
+// countQueries counts the queries run on the database from now on
+func countQueries(t *testing.T, db *gorm.DB) *int {
+	count := 0
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:count_queries", func(*gorm.DB) { count++ }))
+	return &count
+}
+
+// postPayrollJSON runs a payroll handler with a JSON body as an admin
+func postPayrollJSON(t *testing.T, handlerFunc echo.HandlerFunc, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	c.Set("user_id", 1)
+
+	require.NoError(t, handlerFunc(c))
+	return rec
+}
+
+func TestPayrollHandler_MaxPeriod_WithinLimit(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+	handler.payrollUsecase.Config.MaxPeriodDays = 366
+
+	rec := postPayrollJSON(t, handler.GetPayrollSummary, `{"pay_period_start":"2025-01-01T00:00:00Z","pay_period_end":"2025-12-31T00:00:00Z"}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestPayrollHandler_MaxPeriod_OverLimitRejected(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	handler.payrollUsecase.Config.MaxPeriodDays = 366
+	queries := countQueries(t, db)
+
+	rec := postPayrollJSON(t, handler.GetPayrollSummary, `{"pay_period_start":"2020-01-01T00:00:00Z","pay_period_end":"2025-12-31T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Pay period exceeds the maximum of 366 days")
+
+	rec = postPayrollJSON(t, handler.RunPayrollForAllEmployees, `{"pay_period_start":"2020-01-01T00:00:00Z","pay_period_end":"2025-12-31T00:00:00Z","basic_salary":5000000,"overtime_rate":50000}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Pay period exceeds the maximum of 366 days")
+
+	assert.Zero(t, *queries)
+}
+
+// Tests for payroll request validation
+
+// validationFields decodes the field messages of a validation error response
+func validationFields(t *testing.T, rec *httptest.ResponseRecorder) map[string]string {
+	var body struct {
+		ErrorCode string            `json:"error_code"`
+		Data      map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "VALIDATION_FAILED", body.ErrorCode)
+	return body.Data
+}
+
+func TestPayrollHandler_Validation_FieldMessages(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	rec := postPayrollJSON(t, handler.RunPayrollForAllEmployees, `{"pay_period_start":"2025-06-30T00:00:00Z","pay_period_end":"2025-06-01T00:00:00Z"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, map[string]string{"pay_period_end": "pay_period_end must not be before pay_period_start"}, validationFields(t, rec))
+
+	rec = postPayrollJSON(t, handler.RunPayrollForEmployee, `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z","basic_salary":-1,"overtime_rate":-2}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, map[string]string{
+		"employee_id":   "employee_id must be greater than 0",
+		"basic_salary":  "basic_salary must be at least 0",
+		"overtime_rate": "overtime_rate must be at least 0",
+	}, validationFields(t, rec))
+
+	rec = postPayrollJSON(t, handler.GetPayrollSummary, `{"pay_period_end":"2025-06-30T00:00:00Z"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, map[string]string{"pay_period_start": "pay_period_start is required"}, validationFields(t, rec))
+	assert.Contains(t, rec.Body.String(), `"message":"pay_period_start is required."`)
+}
+
+func TestPayrollHandler_Validation_SameDayPeriodAccepted(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	rec := postPayrollJSON(t, handler.GetPayrollSummary, `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-01T00:00:00Z"}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+// slowPayslipRepository adds latency to the period record queries of a payslip and can fail them
+type slowPayslipRepository struct {
+	repository.PayslipRepository
+	latency          time.Duration
+	overtimeErr      error
+	reimbursementErr error
+}
+
+func (r *slowPayslipRepository) GetAttendanceForPeriod(employeeID uint, startDate, endDate time.Time) ([]model.Attendance, error) {
+	time.Sleep(r.latency)
+	return r.PayslipRepository.GetAttendanceForPeriod(employeeID, startDate, endDate)
+}
+
+func (r *slowPayslipRepository) GetOvertimeForPeriod(employeeID uint, startDate, endDate string) ([]model.Overtime, error) {
+	time.Sleep(r.latency)
+	if r.overtimeErr != nil {
+		return nil, r.overtimeErr
+	}
+	return r.PayslipRepository.GetOvertimeForPeriod(employeeID, startDate, endDate)
+}
+
+func (r *slowPayslipRepository) GetApprovedReimbursementsForPeriod(employeeID uint, startDate, endDate time.Time) ([]model.Reimbursement, error) {
+	time.Sleep(r.latency)
+	if r.reimbursementErr != nil {
+		return nil, r.reimbursementErr
+	}
+	return r.PayslipRepository.GetApprovedReimbursementsForPeriod(employeeID, startDate, endDate)
+}
+
+// setupDetailedPayslip stores a June payslip of employee 1 with attendance, overtime and a reimbursement,
+// and returns a handler reading through the given repository wrapper
+func setupDetailedPayslip(t testing.TB, repo *slowPayslipRepository) (*PayrollHandler, *model.Payslip) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.PayGrade{}, &model.Employee{}, &model.Payslip{}, &model.Attendance{}, &model.Overtime{}, &model.Reimbursement{}, &model.AuditLog{}))
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	checkout := time.Date(2025, time.June, 2, 17, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&model.Attendance{
+		EmployeeID: 1,
+		Date:       time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC),
+		Checkin:    time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC),
+		Checkout:   &checkout,
+		Status:     "present",
+	}).Error)
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: "2025-06-02", Hours: 2, Reason: "Release support", Status: model.OvertimeApproved}).Error)
+	require.NoError(t, db.Create(&model.Reimbursement{EmployeeID: 1, Amount: 150000, Category: model.ReimbursementTravel, Reason: "Taxi to client", ReimbursementDate: time.Date(2025, time.June, 3, 0, 0, 0, 0, time.UTC), Status: model.ReimbursementApproved}).Error)
+	payslip := &model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		BasicSalary:    5000000,
+		OvertimeAmount: 100000,
+		GrossAmount:    5100000,
+		NetAmount:      5100000,
+		TotalAmount:    5250000,
+		Currency:       "IDR",
+		ProcessedAt:    time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+		Status:         model.PayslipStatusProcessed,
+	}
+	require.NoError(t, db.Create(payslip).Error)
+
+	repo.PayslipRepository = repository.NewPayslipRepository(db)
+	handler := NewPayrollHandler(repo, usecases.NewPayrollUsecase(repo, repository.NewEmployeeRepository(db)), response.NewResponse())
+	return handler, payslip
+}
+
+func TestPayrollHandler_BuildDetailedPayslip_ParallelMatchesSerial(t *testing.T) {
+	handler, payslip := setupDetailedPayslip(t, &slowPayslipRepository{})
+
+	handler.payrollUsecase.Config.ParallelDetailFetch = false
+	serial, _, err := handler.buildDetailedPayslip(payslip)
+	require.NoError(t, err)
+
+	handler.payrollUsecase.Config.ParallelDetailFetch = true
+	parallel, _, err := handler.buildDetailedPayslip(payslip)
+	require.NoError(t, err)
+
+	assert.Equal(t, serial, parallel)
+	assert.NotEmpty(t, parallel)
+}
+
+func TestPayrollHandler_BuildDetailedPayslip_ParallelErrorOrder(t *testing.T) {
+	repo := &slowPayslipRepository{overtimeErr: errors.New("overtime unavailable"), reimbursementErr: errors.New("reimbursements unavailable")}
+	handler, payslip := setupDetailedPayslip(t, repo)
+
+	for _, parallel := range []bool{false, true} {
+		handler.payrollUsecase.Config.ParallelDetailFetch = parallel
+		_, message, err := handler.buildDetailedPayslip(payslip)
+		assert.Equal(t, "Failed to get overtime records", message)
+		assert.EqualError(t, err, "overtime unavailable")
+	}
+
+	repo.overtimeErr = nil
+	handler.payrollUsecase.Config.ParallelDetailFetch = true
+	_, message, err := handler.buildDetailedPayslip(payslip)
+	assert.Equal(t, "Failed to get reimbursement records", message)
+	assert.EqualError(t, err, "reimbursements unavailable")
+}
+
+// BenchmarkPayrollHandler_BuildDetailedPayslip compares the serial and concurrent record fetch
+// with 2ms of latency per query, the concurrent path should take about a third of the time
+func BenchmarkPayrollHandler_BuildDetailedPayslip(b *testing.B) {
+	for _, parallel := range []bool{false, true} {
+		name := "serial"
+		if parallel {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			handler, payslip := setupDetailedPayslip(b, &slowPayslipRepository{latency: 2 * time.Millisecond})
+			handler.payrollUsecase.Config.ParallelDetailFetch = parallel
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := handler.buildDetailedPayslip(payslip); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestPayrollHandler_GetTaxSummary_AccessScoped(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		BasicSalary:    6000000,
+		GrossAmount:    6000000,
+		TaxAmount:      350000,
+		NetAmount:      5650000,
+		TotalAmount:    5650000,
+		Currency:       "IDR",
+		ProcessedAt:    time.Now(),
+		Status:         model.PayslipStatusProcessed,
+	}).Error)
+
+	summaryFor := func(userID uint) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/payroll/employee/1/tax-summary?year=2025", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("authenticated_role", "employee")
+		c.Set("authenticated_user_id", userID)
+
+		require.NoError(t, handler.GetTaxSummary(c))
+		return rec
+	}
+
+	rec := summaryFor(1)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"total_tax_withheld":350000`)
+	assert.Contains(t, rec.Body.String(), `"year":2025`)
+
+	rec = summaryFor(2)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// A USD payslip later in the year cannot be totalled with the IDR one
+	require.NoError(t, db.Create(&model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.July, 31, 0, 0, 0, 0, time.UTC),
+		GrossAmount:    400,
+		NetAmount:      400,
+		TotalAmount:    400,
+		Currency:       "USD",
+		ProcessedAt:    time.Now(),
+		Status:         model.PayslipStatusProcessed,
+	}).Error)
+	rec = summaryFor(1)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"CURRENCY_MIXED"`)
+}
+
+// ytdEarningsFor runs GetYTDEarnings for an employee as the given user
+func ytdEarningsFor(t *testing.T, handler *PayrollHandler, employeeID string, role string, userID uint, query string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/employee/"+employeeID+"/ytd?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(employeeID)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, handler.GetYTDEarnings(c))
+	return rec
+}
+
+func TestPayrollHandler_GetYTDEarnings_AccessScoped(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		BasicSalary:    6000000,
+		GrossAmount:    6000000,
+		TaxAmount:      350000,
+		NetAmount:      5650000,
+		TotalAmount:    5650000,
+		AttendanceDays: 21,
+		Currency:       "IDR",
+		ProcessedAt:    time.Now(),
+		Status:         model.PayslipStatusProcessed,
+	}).Error)
+
+	rec := ytdEarningsFor(t, handler, "1", "employee", 1, "year=2025")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"gross_amount":6000000`)
+	assert.Contains(t, rec.Body.String(), `"tax_amount":350000`)
+	assert.Contains(t, rec.Body.String(), `"net_amount":5650000`)
+	assert.Contains(t, rec.Body.String(), `"attendance_days":21`)
+
+	// Another year has no payslips
+	rec = ytdEarningsFor(t, handler, "1", "admin", 99, "year=2024")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"payslip_count":0`)
+	assert.Contains(t, rec.Body.String(), `"net_amount":0`)
+
+	rec = ytdEarningsFor(t, handler, "1", "employee", 2, "year=2025")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestPayrollHandler_GetYTDEarnings_InvalidInput(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	rec := ytdEarningsFor(t, handler, "1", "admin", 99, "year=25")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = ytdEarningsFor(t, handler, "42", "admin", 99, "year=2025")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// employeePayslipSummaryFor runs GetEmployeePayslipSummary for an employee as the given user
+func employeePayslipSummaryFor(t *testing.T, handler *PayrollHandler, employeeID string, role string, userID uint, query string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/employee/"+employeeID+"/summary?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(employeeID)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, handler.GetEmployeePayslipSummary(c))
+	return rec
+}
+
+func TestPayrollHandler_GetEmployeePayslipSummary_AccessScoped(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		BasicSalary:    6000000,
+		GrossAmount:    6000000,
+		TaxAmount:      350000,
+		NetAmount:      5650000,
+		TotalAmount:    5650000,
+		AttendanceDays: 21,
+		Currency:       "IDR",
+		ProcessedAt:    time.Now(),
+		Status:         model.PayslipStatusProcessed,
+	}).Error)
+
+	rec := employeePayslipSummaryFor(t, handler, "1", "employee", 1, "start=2025-06-01&end=2025-06-30")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"start_date":"2025-06-01"`)
+	assert.Contains(t, rec.Body.String(), `"payslip_count":1`)
+	assert.Contains(t, rec.Body.String(), `"gross_amount":6000000`)
+	assert.Contains(t, rec.Body.String(), `"net_amount":5650000`)
+
+	// A range without payslips is zeroed rather than an error
+	rec = employeePayslipSummaryFor(t, handler, "1", "admin", 99, "start=2025-01-01&end=2025-05-31")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"payslip_count":0`)
+	assert.Contains(t, rec.Body.String(), `"net_amount":0`)
+
+	rec = employeePayslipSummaryFor(t, handler, "1", "employee", 2, "start=2025-06-01&end=2025-06-30")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestPayrollHandler_GetEmployeePayslipSummary_InvalidInput(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+	handler.payrollUsecase.Config.MaxPeriodDays = 366
+
+	for _, query := range []string{"", "start=2025-06-01", "start=2025-06-01&end=06/30/2025", "start=2025-06-30&end=2025-06-01", "start=2023-01-01&end=2025-06-30"} {
+		rec := employeePayslipSummaryFor(t, handler, "1", "admin", 99, query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	rec := employeePayslipSummaryFor(t, handler, "42", "admin", 99, "start=2025-06-01&end=2025-06-30")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPayrollHandler_GetEmployeePayslipSummary_MixedCurrencies(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	for month, currency := range map[time.Month]string{time.May: "IDR", time.June: "USD"} {
+		require.NoError(t, db.Create(&model.Payslip{
+			EmployeeID:     1,
+			PayPeriodStart: time.Date(2025, month, 1, 0, 0, 0, 0, time.UTC),
+			PayPeriodEnd:   time.Date(2025, month+1, 0, 0, 0, 0, 0, time.UTC),
+			NetAmount:      1000,
+			TotalAmount:    1000,
+			Currency:       currency,
+			ProcessedAt:    time.Now(),
+			Status:         model.PayslipStatusProcessed,
+		}).Error)
+	}
+
+	rec := employeePayslipSummaryFor(t, handler, "1", "admin", 99, "start=2025-05-01&end=2025-06-30")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"CURRENCY_MIXED"`)
+	assert.Contains(t, rec.Body.String(), "IDR, USD")
+}
+
+// payrollRunMetricsPage runs GetPayrollRunMetrics with the given query string
+func payrollRunMetricsPage(t *testing.T, handler *PayrollHandler, query string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/runs/metrics?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	c.Set("authenticated_user_id", uint(1))
+
+	require.NoError(t, handler.GetPayrollRunMetrics(c))
+	return rec
+}
+
+func TestPayrollHandler_GetPayrollRunMetrics_NewestFirst(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+
+	// Three monthly runs, each slower than the one before as headcount grew
+	base := time.Date(2025, time.April, 30, 18, 0, 0, 0, time.UTC)
+	for i, counts := range [][2]int{{10, 0}, {12, 1}, {15, 2}} {
+		startedAt := base.AddDate(0, i, 0)
+		createdAt := startedAt.Add(time.Duration(i+1) * time.Second)
+		run := &model.PayrollRun{
+			DefaultAttribute: model.DefaultAttribute{CreatedAt: &createdAt},
+			PayPeriodStart:   time.Date(2025, time.April+time.Month(i), 1, 0, 0, 0, 0, time.UTC),
+			PayPeriodEnd:     time.Date(2025, time.April+time.Month(i)+1, 0, 0, 0, 0, 0, time.UTC),
+			BasicSalary:      5000000,
+			Currency:         "IDR",
+			ProcessedCount:   counts[0],
+			ErrorCount:       counts[1],
+		}
+		run.RecordTiming(startedAt, startedAt.Add(time.Duration(i+1)*time.Second))
+		require.NoError(t, db.Create(run).Error)
+	}
+
+	type page struct {
+		Data struct {
+			Records []struct {
+				RunID              uint       `json:"run_id"`
+				PayPeriodStart     time.Time  `json:"pay_period_start"`
+				EmployeesProcessed int        `json:"employees_processed"`
+				SucceededCount     int        `json:"succeeded_count"`
+				ErrorCount         int        `json:"error_count"`
+				DurationMs         int64      `json:"duration_ms"`
+				StartedAt          *time.Time `json:"started_at"`
+				FinishedAt         *time.Time `json:"finished_at"`
+			} `json:"records"`
+			TotalRecord int64 `json:"total_record"`
+			TotalPage   int64 `json:"total_page"`
+			CurrentPage int   `json:"current_page"`
+		} `json:"data"`
+	}
+
+	rec := payrollRunMetricsPage(t, handler, "page=1&limit=2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var first page
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &first))
+	assert.Equal(t, int64(3), first.Data.TotalRecord)
+	assert.Equal(t, int64(2), first.Data.TotalPage)
+	require.Len(t, first.Data.Records, 2)
+
+	june := first.Data.Records[0]
+	assert.Equal(t, uint(3), june.RunID)
+	assert.Equal(t, time.June, june.PayPeriodStart.Month())
+	assert.Equal(t, 17, june.EmployeesProcessed)
+	assert.Equal(t, 15, june.SucceededCount)
+	assert.Equal(t, 2, june.ErrorCount)
+	assert.Equal(t, int64(3000), june.DurationMs)
+	require.NotNil(t, june.StartedAt)
+	require.NotNil(t, june.FinishedAt)
+	assert.Equal(t, int64(3000), june.FinishedAt.Sub(*june.StartedAt).Milliseconds())
+
+	may := first.Data.Records[1]
+	assert.Equal(t, uint(2), may.RunID)
+	assert.Equal(t, 13, may.EmployeesProcessed)
+	assert.Equal(t, int64(2000), may.DurationMs)
+
+	rec = payrollRunMetricsPage(t, handler, "page=2&limit=2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var second page
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &second))
+	assert.Equal(t, 2, second.Data.CurrentPage)
+	require.Len(t, second.Data.Records, 1)
+	assert.Equal(t, uint(1), second.Data.Records[0].RunID)
+	assert.Equal(t, 10, second.Data.Records[0].EmployeesProcessed)
+	assert.Equal(t, int64(1000), second.Data.Records[0].DurationMs)
+}
+
+func TestPayrollHandler_GetPayrollRunMetrics_InvalidPagination(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	for _, query := range []string{"page=0", "page=abc", "limit=-5"} {
+		rec := payrollRunMetricsPage(t, handler, query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestPayrollHandler_GetPayPeriods(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+
+	periodsPage := func(query string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/payroll/periods?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("authenticated_role", "admin")
+		c.Set("authenticated_user_id", uint(1))
+		require.NoError(t, handler.GetPayPeriods(c))
+		return rec
+	}
+
+	// May and June with two payslips each, one of June's voided
+	for i, month := range []time.Month{time.May, time.May, time.June, time.June} {
+		payslip := &model.Payslip{
+			EmployeeID:     uint(i%2 + 1),
+			PayPeriodStart: time.Date(2025, month, 1, 0, 0, 0, 0, time.UTC),
+			PayPeriodEnd:   time.Date(2025, month+1, 0, 0, 0, 0, 0, time.UTC),
+			TotalAmount:    float64(1000000 * (i + 1)),
+			Currency:       "IDR",
+			ProcessedAt:    time.Now(),
+			Status:         model.PayslipStatusProcessed,
+		}
+		if i == 3 {
+			payslip.Status = model.PayslipStatusVoid
+		}
+		require.NoError(t, db.Create(payslip).Error)
+	}
+
+	var page struct {
+		Data struct {
+			Records []struct {
+				PayPeriodStart time.Time `json:"pay_period_start"`
+				PayPeriodEnd   time.Time `json:"pay_period_end"`
+				PayslipCount   int       `json:"payslip_count"`
+				TotalPayout    float64   `json:"total_payout"`
+			} `json:"records"`
+			TotalRecord int64 `json:"total_record"`
+			TotalPage   int64 `json:"total_page"`
+		} `json:"data"`
+	}
+
+	rec := periodsPage("page=1&limit=1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, int64(2), page.Data.TotalRecord)
+	assert.Equal(t, int64(2), page.Data.TotalPage)
+	require.Len(t, page.Data.Records, 1)
+	assert.Equal(t, time.June, page.Data.Records[0].PayPeriodStart.Month())
+	assert.Equal(t, 30, page.Data.Records[0].PayPeriodEnd.Day())
+	assert.Equal(t, 1, page.Data.Records[0].PayslipCount)
+	assert.Equal(t, 3000000.0, page.Data.Records[0].TotalPayout)
+
+	rec = periodsPage("page=2&limit=1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Data.Records, 1)
+	assert.Equal(t, time.May, page.Data.Records[0].PayPeriodStart.Month())
+	assert.Equal(t, 2, page.Data.Records[0].PayslipCount)
+	assert.Equal(t, 3000000.0, page.Data.Records[0].TotalPayout)
+
+	for _, query := range []string{"page=0", "page=abc", "limit=-5"} {
+		rec := periodsPage(query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), `"error_code":"INVALID_QUERY"`, query)
+	}
+}
+
+// employeePayslipsPage runs the real GetPayslipsByEmployee for employee 1 as that employee
+func employeePayslipsPage(t *testing.T, handler *PayrollHandler, query string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/employee/1/payslips?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("authenticated_role", "employee")
+	c.Set("authenticated_user_id", uint(1))
+
+	require.NoError(t, handler.GetPayslipsByEmployee(c))
+	return rec
+}
+
+// payslipsPage is the paginated payslip list response
+type payslipsPage struct {
+	Data struct {
+		Records []struct {
+			PayslipID      uint      `json:"payslip_id"`
+			PayPeriodStart time.Time `json:"pay_period_start"`
+		} `json:"records"`
+		TotalRecord        int64 `json:"total_record"`
+		TotalRecordPerPage int64 `json:"total_record_per_page"`
+		TotalPage          int64 `json:"total_page"`
+		CurrentPage        int   `json:"current_page"`
+	} `json:"data"`
+}
+
+// setupEmployeePayslips stores employee 1 with a monthly payslip for each of the given number of months from January 2023
+func setupEmployeePayslips(t *testing.T, months int) *PayrollHandler {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	for i := 0; i < months; i++ {
+		start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, i, 0)
+		require.NoError(t, db.Create(&model.Payslip{
+			EmployeeID:     1,
+			PayPeriodStart: start,
+			PayPeriodEnd:   start.AddDate(0, 1, -1),
+			TotalAmount:    5000000,
+			ProcessedAt:    time.Now(),
+			Status:         model.PayslipStatusProcessed,
+		}).Error)
+	}
+	return handler
+}
+
+func TestPayrollHandler_GetPayslipsByEmployee_Paginated(t *testing.T) {
+	handler := setupEmployeePayslips(t, 25)
+
+	rec := employeePayslipsPage(t, handler, "page=2&limit=10")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page payslipsPage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, int64(25), page.Data.TotalRecord)
+	assert.Equal(t, int64(3), page.Data.TotalPage)
+	assert.Equal(t, 2, page.Data.CurrentPage)
+	require.Len(t, page.Data.Records, 10)
+
+	// 25 months from January 2023 end in January 2025, the second page starts ten months earlier
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), page.Data.Records[0].PayPeriodStart.UTC())
+	for i := 1; i < len(page.Data.Records); i++ {
+		assert.True(t, page.Data.Records[i].PayPeriodStart.Before(page.Data.Records[i-1].PayPeriodStart))
+	}
+
+	rec = employeePayslipsPage(t, handler, "page=3&limit=10")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Data.Records, 5)
+	assert.Equal(t, time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC), page.Data.Records[4].PayPeriodStart.UTC())
+}
+
+func TestPayrollHandler_GetPayslipsByEmployee_PaginationLimits(t *testing.T) {
+	handler := setupEmployeePayslips(t, 25)
+
+	var page payslipsPage
+	rec := employeePayslipsPage(t, handler, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, int64(20), page.Data.TotalRecordPerPage)
+	assert.Len(t, page.Data.Records, 20)
+
+	rec = employeePayslipsPage(t, handler, "limit=500")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, int64(100), page.Data.TotalRecordPerPage)
+	assert.Len(t, page.Data.Records, 25)
+
+	for _, query := range []string{"page=0", "page=-1", "limit=0", "limit=ten", "page=1.5"} {
+		rec := employeePayslipsPage(t, handler, query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+// runPayrollIdempotent runs payroll for all employees through the idempotency middleware with the given key
+func runPayrollIdempotent(t *testing.T, db *gorm.DB, handler *PayrollHandler, key string) *httptest.ResponseRecorder {
+	idempotent := middleware.Idempotency(repository.NewIdempotencyRepository(db), time.Hour, response.NewResponse())
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/payroll/run", strings.NewReader(`{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	c.Set("user_id", 1)
+
+	require.NoError(t, idempotent(handler.RunPayrollForAllEmployees)(c))
+	return rec
+}
+
+func TestPayrollHandler_RunPayroll_IdempotencyKeyReplaysResponse(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.IdempotencyRecord{}))
+	require.NoError(t, db.Create(&model.Employee{Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, BasicSalary: 5000000}).Error)
+
+	first := runPayrollIdempotent(t, db, handler, "run-june")
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	retry := runPayrollIdempotent(t, db, handler, "run-june")
+
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	var runs int64
+	require.NoError(t, db.Model(&model.PayrollRun{}).Count(&runs).Error)
+	assert.Equal(t, int64(1), runs)
+
+	// Another key runs the request again
+	other := runPayrollIdempotent(t, db, handler, "run-june-again")
+	assert.Empty(t, other.Header().Get("Idempotent-Replayed"))
+	require.NoError(t, db.Model(&model.PayrollRun{}).Count(&runs).Error)
+	assert.Equal(t, int64(2), runs)
+}
+
+func TestPayrollHandler_RunPayroll_IdempotencyKeyInFlight(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.IdempotencyRecord{}))
+	require.NoError(t, db.Create(&model.IdempotencyRecord{
+		Key: "run-june", ActorID: 1, Method: http.MethodPost, Path: "/payroll/run", ExpiresAt: time.Now().Add(time.Hour),
+	}).Error)
+
+	rec := runPayrollIdempotent(t, db, handler, "run-june")
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+	var runs int64
+	require.NoError(t, db.Model(&model.PayrollRun{}).Count(&runs).Error)
+	assert.Zero(t, runs)
+}
+
+// Tests for VoidPayslip
+
+// runEmployeeJunePayroll runs June 2025 payroll for employee 1 through the real handler
+func runEmployeeJunePayroll(t *testing.T, handler *PayrollHandler) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/payroll/run/employee", strings.NewReader(`{"employee_id":1,"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z","basic_salary":5000000,"overtime_rate":1.5}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	c.Set("user_id", 1)
+
+	require.NoError(t, handler.RunPayrollForEmployee(c))
+	return rec
+}
+
+// voidPayslipRequest voids the given payslip through the real handler as an admin
+func voidPayslipRequest(t *testing.T, handler *PayrollHandler, payslipID string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/payroll/payslip/"+payslipID+"/void", strings.NewReader(`{"reason":"wrong overtime rate"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("payslip_id")
+	c.SetParamValues(payslipID)
+	c.Set("authenticated_role", "admin")
+	c.Set("user_id", 1)
+
+	require.NoError(t, handler.VoidPayslip(c))
+	return rec
+}
+
+func TestPayrollHandler_VoidPayslip_AllowsRerun(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.EmployeeComponent{}, &model.Timesheet{}, &model.TaxBracket{}))
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, BasicSalary: 5000000}).Error)
+
+	first := runEmployeeJunePayroll(t, handler)
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+
+	// The period is blocked while the payslip stands
+	blocked := runEmployeeJunePayroll(t, handler)
+	require.NotEqual(t, http.StatusOK, blocked.Code)
+
+	var original model.Payslip
+	require.NoError(t, db.First(&original).Error)
+	rec := voidPayslipRequest(t, handler, fmt.Sprint(original.ID))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rerun := runEmployeeJunePayroll(t, handler)
+	assert.Equal(t, http.StatusOK, rerun.Code, rerun.Body.String())
+
+	var payslips []model.Payslip
+	require.NoError(t, db.Order("id ASC").Find(&payslips).Error)
+	require.Len(t, payslips, 2)
+	assert.Equal(t, model.PayslipStatusVoid, payslips[0].Status)
+	assert.Equal(t, "wrong overtime rate", payslips[0].VoidReason)
+	assert.Equal(t, model.PayslipStatusProcessed, payslips[1].Status)
+}
+
+func TestPayrollHandler_VoidPayslip_Rejected(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	for id, status := range map[uint]string{1: model.PayslipStatusPaid, 2: model.PayslipStatusVoid} {
+		require.NoError(t, db.Create(&model.Payslip{
+			DefaultAttribute: model.DefaultAttribute{ID: id},
+			EmployeeID:       1,
+			PayPeriodStart:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			PayPeriodEnd:     time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+			TotalAmount:      5000000,
+			ProcessedAt:      time.Now(),
+			Status:           status,
+		}).Error)
+	}
+
+	rec := voidPayslipRequest(t, handler, "1")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"PAYSLIP_NOT_VOIDABLE"`)
+	assert.Contains(t, rec.Body.String(), `"message":"payslip has already been paid out"`)
+	assert.Equal(t, http.StatusConflict, voidPayslipRequest(t, handler, "2").Code)
+	rec = voidPayslipRequest(t, handler, "99")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"PAYSLIP_NOT_FOUND"`)
+	assert.Equal(t, http.StatusBadRequest, voidPayslipRequest(t, handler, "abc").Code)
+
+	var paid model.Payslip
+	require.NoError(t, db.First(&paid, 1).Error)
+	assert.Equal(t, model.PayslipStatusPaid, paid.Status)
+}
+
+func TestPayrollHandler_BulkVoidPayslips_Result(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	for id, status := range map[uint]string{1: model.PayslipStatusProcessed, 2: model.PayslipStatusPaid, 3: model.PayslipStatusVoid} {
+		require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: id}, Name: fmt.Sprintf("Employee %d", id), Password: "hashed", Role: "employee", Active: true}).Error)
+		require.NoError(t, db.Create(&model.Payslip{
+			DefaultAttribute: model.DefaultAttribute{ID: id},
+			EmployeeID:       id,
+			PayPeriodStart:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			PayPeriodEnd:     time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+			TotalAmount:      5000000,
+			ProcessedAt:      time.Now(),
+			Status:           status,
+		}).Error)
+	}
+
+	e := echo.New()
+	body := `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z","reason":"rerun","confirm":true}`
+	req := httptest.NewRequest(http.MethodPost, "/payroll/payslip/void", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	require.NoError(t, handler.BulkVoidPayslips(c))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var got struct {
+		Data res.BulkVoidResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, res.BulkVoidResult{
+		VoidedCount:  1,
+		SkippedCount: 1,
+		FailedCount:  1,
+		VoidedIDs:    []uint{1},
+		Failures:     []res.BulkVoidFailure{{PayslipID: 2, EmployeeID: 2, Reason: repository.ErrPayslipPaid.Error()}},
+	}, got.Data)
+}
+
+// Tests for GetPayslips
+
+// periodPayslipsPage runs the real GetPayslips as an admin
+func periodPayslipsPage(t *testing.T, handler *PayrollHandler, query string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/payslips?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	c.Set("authenticated_user_id", uint(9))
+
+	require.NoError(t, handler.GetPayslips(c))
+	return rec
+}
+
+func TestPayrollHandler_GetPayslips_SortedPages(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	for id, total := range map[uint]float64{1: 3000000, 2: 1000000, 3: 2000000} {
+		require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: id}, Name: fmt.Sprintf("Employee %d", id), Password: "hashed", Role: "employee", Active: true}).Error)
+		require.NoError(t, db.Create(&model.Payslip{
+			EmployeeID:     id,
+			PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+			TotalAmount:    total,
+			ProcessedAt:    time.Now(),
+			Status:         model.PayslipStatusProcessed,
+		}).Error)
+	}
+
+	var page struct {
+		Data struct {
+			Records     []model.Payslip `json:"records"`
+			TotalRecord int64           `json:"total_record"`
+			TotalPage   int64           `json:"total_page"`
+		} `json:"data"`
+	}
+
+	rec := periodPayslipsPage(t, handler, "start=2025-06-01&end=2025-06-30&sort=total_amount&order=desc&limit=2")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, int64(3), page.Data.TotalRecord)
+	assert.Equal(t, int64(2), page.Data.TotalPage)
+	require.Len(t, page.Data.Records, 2)
+	assert.Equal(t, uint(1), page.Data.Records[0].EmployeeID)
+	assert.Equal(t, uint(3), page.Data.Records[1].EmployeeID)
+
+	rec = periodPayslipsPage(t, handler, "start=2025-06-01&end=2025-06-30&sort=total_amount&order=desc&limit=2&page=2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Data.Records, 1)
+	assert.Equal(t, uint(2), page.Data.Records[0].EmployeeID)
+}
+
+func TestPayrollHandler_GetPayslips_InvalidQuery(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	for query, errorCode := range map[string]string{
+		"end=2025-06-30":                                                              "PERIOD_REQUIRED",
+		"start=2025-06-30&end=2025-06-01":                                             "PERIOD_INVALID",
+		"start=2025-06-01&end=2025-06-30&status=draft":                                "INVALID_QUERY",
+		"start=2025-06-01&end=2025-06-30&sort=name":                                   "INVALID_QUERY",
+		"start=2025-06-01&end=2025-06-30&sort=total_amount%3BDROP%20TABLE%20payslips": "INVALID_QUERY",
+		"start=2025-06-01&end=2025-06-30&order=sideways":                              "INVALID_QUERY",
+		"start=2025-06-01&end=2025-06-30&employee_id=abc":                             "INVALID_ID",
+	} {
+		rec := periodPayslipsPage(t, handler, query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), `"error_code":"`+errorCode+`"`, query)
+	}
+}
+
+// Tests for payslip emails
+
+// recordingMailer keeps the emails sent instead of delivering them
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// emailPayslipRequest runs the real EmailPayslip as an admin
+func emailPayslipRequest(t *testing.T, handler *PayrollHandler, payslipID string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/payroll/payslip/"+payslipID+"/email", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("payslip_id")
+	c.SetParamValues(payslipID)
+	c.Set("authenticated_role", "admin")
+	c.Set("user_id", 1)
+
+	require.NoError(t, handler.EmailPayslip(c))
+	return rec
+}
+
+// createEmailTestPayslips stores a June 2025 payslip for Jane (1, with an email address) as payslip 1
+// and for John (2, without one) as payslip 2
+func createEmailTestPayslips(t *testing.T, db *gorm.DB) {
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Email: "jane@example.com", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Smith", Password: "hashed", Role: "employee", Active: true}).Error)
+	for id := uint(1); id <= 2; id++ {
+		require.NoError(t, db.Create(&model.Payslip{
+			DefaultAttribute: model.DefaultAttribute{ID: id},
+			EmployeeID:       id,
+			PayPeriodStart:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			PayPeriodEnd:     time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+			BasicSalary:      5000000,
+			TotalAmount:      5000000,
+			Currency:         "IDR",
+			ProcessedAt:      time.Now(),
+			Status:           model.PayslipStatusProcessed,
+		}).Error)
+	}
+}
+
+func TestPayrollHandler_EmailPayslip_SendsPDF(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	createEmailTestPayslips(t, db)
+	sent := &recordingMailer{}
+	handler.payrollUsecase.Mailer = sent
+
+	rec := emailPayslipRequest(t, handler, "1")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"email":"jane@example.com"`)
+	require.Len(t, sent.sent, 1)
+	assert.Equal(t, "jane@example.com", sent.sent[0].To)
+	assert.Equal(t, "Payslip for June 2025", sent.sent[0].Subject)
+	assert.Contains(t, sent.sent[0].Body, "Hello Jane Doe")
+	require.Len(t, sent.sent[0].Attachments, 1)
+	assert.Equal(t, "payslip-1-2025-06.pdf", sent.sent[0].Attachments[0].Filename)
+	assert.True(t, strings.HasPrefix(string(sent.sent[0].Attachments[0].Data), "%PDF-"))
+}
+
+func TestPayrollHandler_EmailPayslip_Rejected(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	createEmailTestPayslips(t, db)
+	sent := &recordingMailer{}
+	handler.payrollUsecase.Mailer = sent
+
+	rec := emailPayslipRequest(t, handler, "2")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"EMPLOYEE_EMAIL_MISSING"`)
+
+	rec = emailPayslipRequest(t, handler, "99")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, sent.sent)
+
+	handler.payrollUsecase.Mailer = mailer.NewSMTPMailer(config.MailConfig{})
+	rec = emailPayslipRequest(t, handler, "1")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"MAIL_NOT_CONFIGURED"`)
+}
+
+func TestPayrollHandler_RunPayroll_SendEmailSkipsMissingAddress(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.EmployeeComponent{}, &model.Timesheet{}, &model.TaxBracket{}))
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Email: "jane@example.com", Password: "hashed", Role: "employee", Active: true, BasicSalary: 5000000}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Smith", Password: "hashed", Role: "employee", Active: true, BasicSalary: 4000000}).Error)
+	sent := &recordingMailer{}
+	handler.payrollUsecase.Mailer = sent
+
+	rec := postPayrollJSON(t, handler.RunPayrollForAllEmployees, `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z","send_email":true}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data struct {
+			ProcessedCount int                      `json:"processed_count"`
+			EmailResults   []res.PayslipEmailResult `json:"email_results"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 2, body.Data.ProcessedCount)
+	statuses := map[uint]string{}
+	for _, result := range body.Data.EmailResults {
+		statuses[result.EmployeeID] = result.Status
+	}
+	assert.Equal(t, map[uint]string{1: model.PayslipEmailSent, 2: model.PayslipEmailSkipped}, statuses)
+	require.Len(t, sent.sent, 1)
+	assert.Equal(t, "jane@example.com", sent.sent[0].To)
+
+	var results []model.PayrollRunResult
+	require.NoError(t, db.Order("employee_id ASC").Find(&results).Error)
+	require.Len(t, results, 2)
+	assert.Equal(t, model.PayrollRunResultSucceeded, results[1].Status)
+	assert.Equal(t, model.PayslipEmailSent, results[0].EmailStatus)
+	assert.Equal(t, model.PayslipEmailSkipped, results[1].EmailStatus)
+	assert.Equal(t, usecases.ErrEmployeeNoEmail.Error(), results[1].EmailError)
+}
+
+func TestPayrollHandler_RunPayroll_WebhookFailureKeepsRun(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.EmployeeComponent{}, &model.Timesheet{}, &model.TaxBracket{}))
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, BasicSalary: 5000000, Currency: "IDR"}).Error)
+
+	type delivery struct {
+		body      []byte
+		signature string
+	}
+	deliveries := make(chan delivery, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{body: body, signature: r.Header.Get(webhook.SignatureHeader)}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	cfg := config.WebhookConfig{URL: server.URL, Secret: "s3cret", MaxAttempts: 2, Timeout: time.Second}
+	handler.payrollUsecase.Webhooks = webhook.NewNotifier(cfg, webhook.NewEnvEndpoints(cfg))
+
+	rec := postPayrollJSON(t, handler.RunPayrollForAllEmployees, `{"pay_period_start":"2025-06-01T00:00:00Z","pay_period_end":"2025-06-30T00:00:00Z"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"processed_count":1`)
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		select {
+		case got := <-deliveries:
+			assert.True(t, webhook.Verify("s3cret", got.body, got.signature))
+			var payload struct {
+				Event string                    `json:"event"`
+				Data  res.PayrollCompletedEvent `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(got.body, &payload))
+			assert.Equal(t, webhook.EventPayrollCompleted, payload.Event)
+			assert.Equal(t, 1, payload.Data.ProcessedCount)
+			assert.Equal(t, 0, payload.Data.ErrorCount)
+			assert.NotNil(t, payload.Data.RunID)
+			assert.Equal(t, "2025-06-01", payload.Data.PayPeriodStart)
+			assert.Contains(t, payload.Data.NetTotals, "IDR")
+		case <-time.After(5 * time.Second):
+			t.Fatalf("webhook attempt %d not delivered", attempt)
+		}
+	}
+
+	var payslips int64
+	require.NoError(t, db.Model(&model.Payslip{}).Count(&payslips).Error)
+	assert.Equal(t, int64(1), payslips)
+}
+
+// Tests for PreviewPayroll
+
+// previewPayrollRequest runs the real PreviewPayroll as an admin
+func previewPayrollRequest(t *testing.T, handler *PayrollHandler, query string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payroll/preview?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", "admin")
+	c.Set("authenticated_user_id", uint(9))
+
+	require.NoError(t, handler.PreviewPayroll(c))
+	return rec
+}
+
+func TestPayrollHandler_PreviewPayroll_NothingStored(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	require.NoError(t, db.AutoMigrate(&model.EmployeeComponent{}, &model.Timesheet{}, &model.TaxBracket{}))
+	hireDate := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true, HireDate: &hireDate}).Error)
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: 1, OvertimeDate: "2025-06-02", Hours: 2, Reason: "Release preparation", Status: model.OvertimeApproved}).Error)
+	// A payslip for the period already exists, a preview is not blocked by it
+	require.NoError(t, db.Create(&model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		TotalAmount:    1,
+		ProcessedAt:    time.Now(),
+		Status:         model.PayslipStatusProcessed,
+	}).Error)
+
+	rec := previewPayrollRequest(t, handler, "employee_id=1&start=2025-06-01&end=2025-06-30&basic_salary=5000000&overtime_rate=50000")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data struct {
+			Preview           bool                     `json:"preview"`
+			EmployeeName      string                   `json:"employee_name"`
+			Summary           map[string]interface{}   `json:"summary"`
+			OvertimeBreakdown []map[string]interface{} `json:"overtime_breakdown"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.True(t, body.Data.Preview)
+	assert.Equal(t, "Jane Doe", body.Data.EmployeeName)
+	assert.Equal(t, 5000000.0, body.Data.Summary["basic_salary"])
+	assert.Equal(t, 100000.0, body.Data.Summary["overtime_amount"])
+	require.Len(t, body.Data.OvertimeBreakdown, 1)
+
+	var count int64
+	require.NoError(t, db.Model(&model.Payslip{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestPayrollHandler_PreviewPayroll_InvalidQuery(t *testing.T) {
+	_, handler := setupPayrollHandlerDB(t)
+
+	for query, errorCode := range map[string]string{
+		"start=2025-06-01&end=2025-06-30":                                  "INVALID_ID",
+		"employee_id=abc&start=2025-06-01&end=2025-06-30":                  "INVALID_ID",
+		"employee_id=1&end=2025-06-30":                                     "PERIOD_REQUIRED",
+		"employee_id=1&start=2025-06-30&end=2025-06-01":                    "PERIOD_INVALID",
+		"employee_id=1&start=06/01/2025&end=2025-06-30":                    "PERIOD_INVALID",
+		"employee_id=1&start=2025-06-01&end=2025-06-30&basic_salary=-1":    "AMOUNT_INVALID",
+		"employee_id=1&start=2025-06-01&end=2025-06-30&overtime_rate=lots": "AMOUNT_INVALID",
+	} {
+		rec := previewPayrollRequest(t, handler, query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), `"error_code":"`+errorCode+`"`, query)
+	}
+
+	rec := previewPayrollRequest(t, handler, "employee_id=42&start=2025-06-01&end=2025-06-30")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"EMPLOYEE_NOT_FOUND"`)
+}
+
+func TestPayrollHandler_GetOvertimeConsistencyReport_ManagerScope(t *testing.T) {
+	db, handler := setupPayrollHandlerDB(t)
+	managerID := uint(1)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Mia Manager", Password: "hashed", Role: "manager", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "Rob Report", Password: "hashed", Role: "employee", Active: true, ManagerID: &managerID}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 3}, Name: "Olga Other", Password: "hashed", Role: "employee", Active: true}).Error)
+	for _, employeeID := range []uint{2, 3} {
+		require.NoError(t, db.Create(&model.Overtime{EmployeeID: employeeID, OvertimeDate: "2025-06-07", Hours: 2, Reason: "Weekend release", Status: model.OvertimeApproved}).Error)
+	}
+
+	consistencyReport := func(role string, body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/payroll/overtime-consistency", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("authenticated_role", role)
+		c.Set("authenticated_user_id", managerID)
+		require.NoError(t, handler.GetOvertimeConsistencyReport(c))
+		return rec
+	}
+	const period = `"period_start": "2025-06-01T00:00:00Z", "period_end": "2025-06-30T00:00:00Z"`
+
+	rec := consistencyReport("manager", `{`+period+`}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"flagged_count":1`)
+	assert.Contains(t, rec.Body.String(), "Rob Report")
+	assert.NotContains(t, rec.Body.String(), "Olga Other")
+
+	rec = consistencyReport("manager", `{`+period+`, "employee_id": 3}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"ACCESS_DENIED"`)
+
+	rec = consistencyReport("admin", `{`+period+`}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"flagged_count":2`)
+}
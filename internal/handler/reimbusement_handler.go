@@ -1,11 +1,25 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/helper"
 	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
 	"github.com/yourname/payslip-system/internal/repository"
+	"github.com/yourname/payslip-system/internal/usecases"
 	"gorm.io/gorm"
 )
 
@@ -16,20 +30,537 @@ type ReimbusementHandler struct {
 
 	BaseRepo         repository.BaseRepositoryInterface
 	ReimbusementRepo repository.ReimbusementRepository
+
+	// Approval maps reimbursement amounts to the role required to approve them
+	Approval config.ApprovalRouting
+	// DirectManagerOnly limits managers to the reimbursements of their direct reports, looked up in EmployeeRepo
+	DirectManagerOnly bool
+	EmployeeRepo      repository.EmployeeRepository
+	// DuplicateCheck flags submissions matching an existing reimbursement
+	DuplicateCheck config.DuplicateCheckConfig
+	// Receipts is where uploaded receipts are stored and their size limit
+	Receipts config.ReceiptStorageConfig
+	// Batch limits the reimbursements submitted at once
+	Batch config.ReimbursementBatchConfig
+	// Payroll rounds amounts in the employee's currency and holds the reimbursement floor clawbacks are checked against
+	Payroll *usecases.PayrollUsecase
+}
+
+// receiptExtensions maps the accepted receipt content types to the extension they are stored with
+var receiptExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
 }
 
+// receiptURLFormat is the download route of a reimbursement's receipt
+const receiptURLFormat = "/api/v1/reimbusement/%d/receipt"
+
+// CreateReimbusement creates a pending reimbursement dated today. A negative amount claws back an overpaid
+// reimbursement: only admins may record one, and it is approved on creation as the admin decided on it.
 func (h *ReimbusementHandler) CreateReimbusement(c echo.Context) error {
 	req := request.CreateReimbusementRequest{}
 	if err := c.Bind(&req); err != nil {
-		return h.Response.SendError(c, err.Error(), "Invalid request data")
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInvalidRequestBody, err.Error(), "Invalid request data")
+	}
+
+	if req.Amount == 0 {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeAmountInvalid, "Invalid amount, expected a non-zero amount", nil)
+	}
+	if req.Amount < 0 {
+		if role, _ := c.Get("authenticated_role").(string); role != "admin" {
+			return h.Response.SendCustomResponseWithCode(c, http.StatusForbidden, response.ErrCodeAccessDenied, "Access denied. Only admins can record a negative reimbursement (clawback).", nil)
+		}
+		return h.createClawback(c, req)
+	}
+
+	// Look for a near-duplicate before creating, so the new record is not matched
+	var duplicate *model.Reimbursement
+	if h.DuplicateCheck.Enabled {
+		var err error
+		duplicate, err = h.ReimbusementRepo.FindPotentialDuplicateReimbursement(req.EmployeeID, req.Amount, time.Now(), h.DuplicateCheck.WindowDays, h.DuplicateCheck.AmountTolerance)
+		if err != nil {
+			return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, err.Error(), "Failed to check for duplicate reimbusement")
+		}
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.ReimbusementRepo.GetDB())
+
+	// A possible duplicate is created with a warning rather than refused as a second claim of the day
+	var reimbursement *model.Reimbursement
+	var err error
+	if duplicate != nil {
+		reimbursement, err = h.ReimbusementRepo.CreatePossibleDuplicateReimbusementWithAudit(req.EmployeeID, req.Amount, req.Description, auditDB)
+	} else {
+		reimbursement, err = h.ReimbusementRepo.CreateReimbusementWithAudit(req.EmployeeID, req.Amount, req.Description, auditDB)
+	}
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, err.Error(), "Failed to create reimbusement")
+	}
+
+	created := res.CreatedReimbursementResponse{Reimbursement: reimbursementResponse(reimbursement)}
+	if duplicate != nil {
+		created.Warning = "This reimbursement looks like a duplicate of an existing one"
+		created.PotentialDuplicateID = duplicate.ID
+	}
+	return h.Response.SendSuccess(c, "Reimbusement created successfully", created)
+}
+
+// createClawback records an admin's negative reimbursement, refused with 409 when today's pay period
+// already has a payslip as it would never be deducted, and with 400 when it would take the month's
+// approved reimbursements below the floor
+func (h *ReimbusementHandler) createClawback(c echo.Context, req request.CreateReimbusementRequest) error {
+	reason := strings.TrimSpace(req.Description)
+	if reason == "" {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeValidationFailed, "A clawback needs a description of the overpayment it recovers", nil)
+	}
+
+	now := time.Now().In(h.timeLocation())
+	payslip, err := h.ReimbusementRepo.GetProcessedPayslipCoveringDate(req.EmployeeID, now)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to check the clawback's pay period", err.Error())
+	}
+	if payslip != nil {
+		message := fmt.Sprintf("Today falls in the pay period %s to %s already processed in payslip %d, the clawback would never be deducted",
+			payslip.PayPeriodStart.Format("2006-01-02"), payslip.PayPeriodEnd.Format("2006-01-02"), payslip.ID)
+		return h.Response.SendCustomResponseWithCode(c, http.StatusConflict, response.ErrCodeReimbursementPeriodClosed, message, map[string]interface{}{"payslip_id": payslip.ID})
+	}
+
+	amount, err := h.Payroll.CheckClawbackFloor(req.EmployeeID, req.Amount, now)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return h.Response.SendCustomResponseWithCode(c, http.StatusNotFound, response.ErrCodeEmployeeNotFound, "Employee not found", nil)
+	case errors.Is(err, usecases.ErrReimbursementBelowFloor):
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeReimbursementBelowFloor, err.Error(), nil)
+	case err != nil:
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to check the reimbursement floor", err.Error())
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.ReimbusementRepo.GetDB())
+
+	adminID, _ := c.Get("authenticated_user_id").(uint)
+	clawback, err := h.ReimbusementRepo.CreateClawbackWithAudit(req.EmployeeID, amount, reason, adminID, auditDB)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to record clawback", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Clawback recorded successfully", reimbursementResponse(clawback))
+}
+
+// CreateReimbursementBatch creates an array of reimbursements under the authenticated employee in one transaction.
+// Each item is validated and created on its own, a failing item is reported without aborting the others.
+// Items may share a day, only days claimed before the batch are refused.
+func (h *ReimbusementHandler) CreateReimbursementBatch(c echo.Context) error {
+	var items []request.ReimbursementBatchItem
+	if err := c.Bind(&items); err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request data, expected an array of reimbursements", err.Error())
+	}
+	if len(items) == 0 {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeReimbursementBatchEmpty, "At least one reimbursement is required", nil)
+	}
+	if len(items) > h.Batch.MaxItems {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeReimbursementBatchTooLarge,
+			fmt.Sprintf("A batch holds at most %d reimbursements", h.Batch.MaxItems), map[string]int{"max_items": h.Batch.MaxItems})
+	}
+
+	loc := h.timeLocation()
+	today := time.Now().In(loc)
+	result := res.ReimbursementBatchResult{Total: len(items), Items: make([]res.ReimbursementBatchItem, len(items))}
+	var drafts []model.Reimbursement
+	var pending []int // Index in result.Items of each draft
+	for i, item := range items {
+		result.Items[i] = res.ReimbursementBatchItem{Index: i}
+		item.Description = strings.TrimSpace(item.Description)
+		if err := c.Validate(item); err != nil {
+			result.Items[i].Status, result.Items[i].Reason = res.BatchItemFailed, response.ValidationMessage(err)
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", item.Date, loc)
+		if err == nil && date.After(today) {
+			err = fmt.Errorf("date %s is in the future", item.Date)
+		}
+		if err != nil {
+			result.Items[i].Status, result.Items[i].Reason = res.BatchItemFailed, err.Error()
+			continue
+		}
+		drafts = append(drafts, model.Reimbursement{Amount: item.Amount, Reason: item.Description, ReimbursementDate: date})
+		pending = append(pending, i)
+	}
+
+	if len(drafts) > 0 {
+		// Get auditable DB instance
+		auditDB := helper.GetAuditableDB(c, h.ReimbusementRepo.GetDB())
+
+		employeeID, _ := c.Get("authenticated_user_id").(uint)
+		created, failed, err := h.ReimbusementRepo.CreateReimbursementBatchWithAudit(employeeID, drafts, auditDB)
+		if err != nil {
+			return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to create reimbursements", err.Error())
+		}
+		for i, index := range pending {
+			if failed[i] != nil {
+				result.Items[index].Status, result.Items[index].Reason = res.BatchItemFailed, failed[i].Error()
+				continue
+			}
+			result.Items[index].Status = res.BatchItemCreated
+			result.Items[index].ReimbursementID = created[i].ID
+		}
+	}
+
+	for _, item := range result.Items {
+		if item.Status == res.BatchItemFailed {
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+	}
+	return h.Response.SendSuccess(c, "Reimbursements submitted", result)
+}
+
+// timeLocation is the server timezone reimbursement dates are taken in, the process local time when unset
+func (h *ReimbusementHandler) timeLocation() *time.Location {
+	if h.Helper.TimeLocation != nil {
+		return h.Helper.TimeLocation
+	}
+	return time.Local
+}
+
+// ApproveReimbursement approves a pending reimbursement if the approver's role meets the required tier for its amount.
+// An optional approved_amount approves less than requested, it must be positive and at most the requested amount.
+// A reimbursement dated in a pay period that already has a payslip is refused with 409, it would never be paid.
+func (h *ReimbusementHandler) ApproveReimbursement(c echo.Context) error {
+	req := request.ApproveReimbursementRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Invalid request data", err.Error())
+	}
+
+	reimbursement, ok, err := h.pendingReimbursementForDecision(c)
+	if !ok {
+		return err
+	}
+
+	if req.ApprovedAmount != nil {
+		currency, err := h.Payroll.EmployeeCurrency(reimbursement.EmployeeID)
+		if err != nil {
+			return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to get the reimbursement's currency", err.Error())
+		}
+		approvedAmount := h.Payroll.RoundMoney(*req.ApprovedAmount, currency)
+		if approvedAmount <= 0 || approvedAmount > reimbursement.Amount {
+			message := fmt.Sprintf("Invalid approved_amount, expected more than 0 and at most the requested %.2f", reimbursement.Amount)
+			return h.Response.SendBadRequestWithCode(c, response.ErrCodeAmountInvalid, message, nil)
+		}
+		reimbursement.ApprovedAmount = &approvedAmount
+	}
+
+	payslip, err := h.ReimbusementRepo.GetProcessedPayslipCoveringDate(reimbursement.EmployeeID, reimbursement.ReimbursementDate)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to check the reimbursement's pay period", err.Error())
+	}
+	if payslip != nil {
+		message := fmt.Sprintf("Reimbursement dated %s falls in the pay period %s to %s already processed in payslip %d, it can no longer be approved",
+			reimbursement.ReimbursementDate.Format("2006-01-02"), payslip.PayPeriodStart.Format("2006-01-02"), payslip.PayPeriodEnd.Format("2006-01-02"), payslip.ID)
+		return h.Response.SendCustomResponseWithCode(c, http.StatusConflict, response.ErrCodeReimbursementPeriodClosed, message, map[string]interface{}{"payslip_id": payslip.ID})
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.ReimbusementRepo.GetDB())
+
+	approverID, _ := c.Get("user_id").(int)
+	approved, err := h.ReimbusementRepo.ApproveReimbursementWithAudit(reimbursement, uint(approverID), auditDB)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to approve reimbursement", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Reimbursement approved successfully", approved)
+}
+
+// RejectReimbursement rejects a pending reimbursement, with the same role tier as approving it.
+// Rejected reimbursements are never paid.
+func (h *ReimbusementHandler) RejectReimbursement(c echo.Context) error {
+	reimbursement, ok, err := h.pendingReimbursementForDecision(c)
+	if !ok {
+		return err
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.ReimbusementRepo.GetDB())
+
+	approverID, _ := c.Get("user_id").(int)
+	rejected, err := h.ReimbusementRepo.RejectReimbursementWithAudit(reimbursement, uint(approverID), auditDB)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to reject reimbursement", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Reimbursement rejected successfully", rejected)
+}
+
+// pendingReimbursementForDecision loads the reimbursement of the :id param and checks it is still pending, is not
+// the caller's own and the caller's role may decide on its amount, managers only for their direct reports when
+// DirectManagerOnly is set.
+// When ok is false the error response has already been sent.
+func (h *ReimbusementHandler) pendingReimbursementForDecision(c echo.Context) (*model.Reimbursement, bool, error) {
+	reimbursementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, false, h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid reimbursement ID", err.Error())
+	}
+
+	reimbursement, err := h.ReimbusementRepo.GetReimbursementByID(uint(reimbursementID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, false, h.Response.SendNotFoundWithCode(c, response.ErrCodeReimbursementNotFound, "Reimbursement not found", nil)
+		}
+		return nil, false, h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve reimbursement", err.Error())
+	}
+
+	if reimbursement.Status != model.ReimbursementPending {
+		message := fmt.Sprintf("Reimbursement is already %s, only pending reimbursements can be approved or rejected", reimbursement.Status)
+		return nil, false, h.Response.SendBadRequestWithCode(c, response.ErrCodeReimbursementNotPending, message, nil)
+	}
+
+	approverID, _ := c.Get("user_id").(int)
+	if uint(approverID) == reimbursement.EmployeeID {
+		return nil, false, h.Response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You cannot decide on your own reimbursement.", nil)
+	}
+
+	role, _ := c.Get("role").(string)
+	if !h.Approval.CanApprove(role, reimbursement.Amount) {
+		message := fmt.Sprintf("Access denied. Approving this amount requires the %s role.", h.Approval.RequiredRole(reimbursement.Amount))
+		return nil, false, h.Response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, message, nil)
+	}
+
+	if role == "manager" && h.DirectManagerOnly {
+		employee, err := h.EmployeeRepo.GetEmployeeByID(reimbursement.EmployeeID)
+		if err != nil {
+			return nil, false, h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve employee", err.Error())
+		}
+		if employee.ManagerID == nil || *employee.ManagerID != uint(approverID) {
+			message := "Access denied. Only the employee's direct manager or an admin can decide on this reimbursement."
+			return nil, false, h.Response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, message, nil)
+		}
+	}
+
+	return reimbursement, true, nil
+}
+
+// ListReimbursements lists reimbursements filtered by the optional employee_id, status, start_date and
+// end_date (YYYY-MM-DD) query params, paginated with page and limit. Admins can list any employee,
+// everyone else only sees their own reimbursements whatever employee_id says.
+func (h *ReimbusementHandler) ListReimbursements(c echo.Context) error {
+	var filter request.ReimbursementFilter
+	role, _ := c.Get("authenticated_role").(string)
+	userID, _ := c.Get("authenticated_user_id").(uint)
+	if role == "admin" {
+		if value := c.QueryParam("employee_id"); value != "" {
+			employeeID, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid employee_id", err.Error())
+			}
+			id := uint(employeeID)
+			filter.EmployeeID = &id
+		}
+	} else {
+		filter.EmployeeID = &userID
+	}
+
+	filter.Status = c.QueryParam("status")
+	switch model.ReimbursementStatus(filter.Status) {
+	case "", model.ReimbursementPending, model.ReimbursementApproved, model.ReimbursementRejected, model.ReimbursementPaid:
+	default:
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid status, expected pending, approved, rejected or paid", nil)
+	}
+
+	if value := c.QueryParam("start_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return h.Response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid start_date, expected YYYY-MM-DD", err.Error())
+		}
+		filter.StartDate = &parsed
+	}
+	if value := c.QueryParam("end_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return h.Response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "Invalid end_date, expected YYYY-MM-DD", err.Error())
+		}
+		filter.EndDate = &parsed
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodePeriodInvalid, "end_date must not be before start_date", nil)
+	}
+
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid page, expected a positive number", err.Error())
+	}
+	limit, err := positiveQueryInt(c, "limit", 20)
+	if err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidQuery, "Invalid limit, expected a positive number", err.Error())
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	reimbursements, total, err := h.ReimbusementRepo.ListReimbursements(filter, (page-1)*limit, limit)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve reimbursements", err.Error())
+	}
+
+	records := make([]res.ReimbursementResponse, 0, len(reimbursements))
+	for i := range reimbursements {
+		records = append(records, reimbursementResponse(&reimbursements[i]))
+	}
+
+	totalPage := (total + int64(limit) - 1) / int64(limit)
+	return h.Response.SendPaginationResponse(c, records, "Reimbursements retrieved successfully", total, int64(limit), int64(len(records)), totalPage, page)
+}
+
+// GetReimbursement returns a reimbursement with its receipt URL. Employees see their own reimbursements,
+// managers and admins, who decide on them, see any.
+func (h *ReimbusementHandler) GetReimbursement(c echo.Context) error {
+	reimbursement, ok, err := h.reimbursementForCaller(c, true)
+	if !ok {
+		return err
+	}
+	return h.Response.SendSuccess(c, "Reimbursement retrieved successfully", reimbursementResponse(reimbursement))
+}
+
+// UploadReceipt stores the receipt file of the multipart "receipt" field for a reimbursement, replacing
+// an earlier one. Only the owning employee or an admin may upload, and only JPG, PNG or PDF files
+// up to the configured size are accepted.
+func (h *ReimbusementHandler) UploadReceipt(c echo.Context) error {
+	reimbursement, ok, err := h.reimbursementForCaller(c, false)
+	if !ok {
+		return err
+	}
+
+	file, err := c.FormFile("receipt")
+	if err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeReceiptRequired, "Receipt file is required in the receipt form field", err.Error())
+	}
+	if file.Size > h.Receipts.MaxSizeBytes {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeReceiptTooLarge, fmt.Sprintf("Receipt exceeds the maximum size of %d bytes", h.Receipts.MaxSizeBytes), nil)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Failed to read receipt file", err.Error())
+	}
+	defer src.Close()
+
+	// Trust the file content rather than the content type the client declared
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidRequestBody, "Failed to read receipt file", err.Error())
+	}
+	contentType := http.DetectContentType(head[:n])
+	extension, accepted := receiptExtensions[contentType]
+	if !accepted {
+		return h.Response.SendBadRequestWithCode(c, response.ErrCodeReceiptUnsupportedType, "Receipt must be a JPG, PNG or PDF file", map[string]string{"content_type": contentType})
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to read receipt file", err.Error())
+	}
+
+	path, err := h.storeReceipt(reimbursement.ID, extension, src)
+	if err != nil {
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to store receipt", err.Error())
 	}
 
 	// Get auditable DB instance
 	auditDB := helper.GetAuditableDB(c, h.ReimbusementRepo.GetDB())
 
-	_, err := h.ReimbusementRepo.CreateReimbusementWithAudit(req.EmployeeID, req.Amount, req.Description, auditDB)
+	previous := reimbursement.ReceiptPath
+	updated, err := h.ReimbusementRepo.AttachReceiptWithAudit(reimbursement, path, contentType, auditDB)
 	if err != nil {
-		return h.Response.SendError(c, err.Error(), "Failed to create reimbusement")
+		os.Remove(path)
+		return h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to save receipt", err.Error())
+	}
+	if previous != "" && previous != path {
+		os.Remove(previous)
+	}
+
+	return h.Response.SendSuccess(c, "Receipt uploaded successfully", reimbursementResponse(updated))
+}
+
+// DownloadReceipt serves the stored receipt of a reimbursement, with the same access as GetReimbursement
+func (h *ReimbusementHandler) DownloadReceipt(c echo.Context) error {
+	reimbursement, ok, err := h.reimbursementForCaller(c, true)
+	if !ok {
+		return err
+	}
+	if !reimbursement.HasReceipt() {
+		return h.Response.SendNotFoundWithCode(c, response.ErrCodeReceiptNotFound, "No receipt uploaded for this reimbursement", nil)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, reimbursement.ReceiptContentType)
+	return c.Inline(reimbursement.ReceiptPath, filepath.Base(reimbursement.ReceiptPath))
+}
+
+// storeReceipt writes the receipt to a new file in the storage directory and returns its path
+func (h *ReimbusementHandler) storeReceipt(reimbursementID uint, extension string, src io.Reader) (string, error) {
+	if err := os.MkdirAll(h.Receipts.Dir, 0o750); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(h.Receipts.Dir, fmt.Sprintf("reimbursement-%d-%d%s", reimbursementID, time.Now().UnixNano(), extension))
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, io.LimitReader(src, h.Receipts.MaxSizeBytes)); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// reimbursementForCaller loads the reimbursement of the :id param and checks the caller may access it: the owner
+// and admins always, managers too when approvers is set. When ok is false the error response has already been sent.
+func (h *ReimbusementHandler) reimbursementForCaller(c echo.Context, approvers bool) (*model.Reimbursement, bool, error) {
+	reimbursementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, false, h.Response.SendBadRequestWithCode(c, response.ErrCodeInvalidID, "Invalid reimbursement ID", err.Error())
+	}
+
+	reimbursement, err := h.ReimbusementRepo.GetReimbursementByID(uint(reimbursementID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, false, h.Response.SendNotFoundWithCode(c, response.ErrCodeReimbursementNotFound, "Reimbursement not found", nil)
+		}
+		return nil, false, h.Response.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to retrieve reimbursement", err.Error())
+	}
+
+	role, _ := c.Get("authenticated_role").(string)
+	if !helper.ValidateEmployeeAccess(c, reimbursement.EmployeeID) && !(approvers && role == "manager") {
+		return nil, false, h.Response.SendCustomResponseWithCode(c, 403, response.ErrCodeAccessDenied, "Access denied. You can only access your own reimbursements.", nil)
+	}
+
+	return reimbursement, true, nil
+}
+
+// reimbursementResponse converts a reimbursement to its detail response
+func reimbursementResponse(reimbursement *model.Reimbursement) res.ReimbursementResponse {
+	detail := res.ReimbursementResponse{
+		ID:                 reimbursement.ID,
+		EmployeeID:         reimbursement.EmployeeID,
+		ReimbursementDate:  reimbursement.ReimbursementDate,
+		Amount:             reimbursement.Amount,
+		Clawback:           reimbursement.IsClawback(),
+		ApprovedAmount:     reimbursement.ApprovedAmount,
+		Category:           string(reimbursement.Category),
+		Reason:             reimbursement.Reason,
+		Status:             string(reimbursement.Status),
+		ApprovedBy:         reimbursement.ApprovedBy,
+		ApprovedAt:         reimbursement.ApprovedAt,
+		ReceiptContentType: reimbursement.ReceiptContentType,
+	}
+	if reimbursement.HasReceipt() {
+		detail.ReceiptURL = fmt.Sprintf(receiptURLFormat, reimbursement.ID)
 	}
-	return h.Response.SendSuccess(c, "Reimbusement created successfully", nil)
+	return detail
 }
@@ -10,6 +10,40 @@
 // 5. Edge cases (zero amounts, large amounts, special characters)
 // 6. Performance benchmarks
 //
+// CreateReimbusement duplicate detection tests cover:
+// 1. Same-amount resubmission flagged with the potential duplicate's id
+// 2. A different submission created without a warning
+// 3. Detection disabled leaving the response unchanged
+// 4. A same-day resubmission created with the warning instead of refused (real handler on an in-memory database)
+//
+// ApproveReimbursement tests cover:
+// 1. Small claims approvable by managers
+// 2. Large claims requiring admin, rejecting a manager's attempt
+//
+// Reimbursement decision tests cover (real handler on an in-memory database):
+// 1. Rejection recording the decider, a second decision refused
+// 2. Approval refused with 409 once the pay period has a payslip, allowed after the payslip is voided
+// 3. A partial approval storing the approved amount, zero, negative or larger amounts refused with 400
+// 4. Managers and admins refused with 403 when deciding on their own reimbursement
+//
+// Clawback tests cover (real handler on an in-memory database):
+// 1. An admin's negative amount recorded approved by the admin and marked as a clawback
+// 2. Employees refused a negative amount with 403, a zero amount refused with 400
+// 3. A clawback refused with 409 once today's pay period has a payslip
+// 4. A clawback taking the month's approved reimbursements below the floor refused with 400, allowed by a lower floor
+//
+// CreateReimbursementBatch tests cover (real handler on an in-memory database):
+// 1. Valid items created under the caller, items sharing a day included, invalid, future and already claimed days failing on their own
+// 2. Empty, oversized and non-array batches rejected with 400 and their error codes
+//
+// ListReimbursements tests cover (real handler on an in-memory database):
+// 1. Non-admins limited to their own reimbursements whatever employee_id says, admins filtering any employee
+// 2. Malformed dates and statuses rejected with 400 and their error codes
+//
+// Receipt tests cover (real handler on an in-memory database):
+// 1. Owner uploading a PNG stored on disk, exposed as receipt_url and downloadable
+// 2. Another employee denied, unsupported and oversized files rejected with 400
+//
 // The tests use mocks to isolate the handler logic and ensure fast, reliable test execution.
 // The TestReimbursementHandler struct and related interfaces are created specifically for testing
 // to avoid tight coupling with concrete implementations.
@@ -17,9 +51,13 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -27,10 +65,16 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"github.com/yourname/payslip-system/internal/usecases"
 	"gorm.io/gorm"
 )
 
@@ -83,6 +127,91 @@ func (m *MockReimbursementRepository) CreateReimbusementWithAudit(employeeID uin
 	return args.Get(0).(*model.Reimbursement), args.Error(1)
 }
 
+func (m *MockReimbursementRepository) CreatePossibleDuplicateReimbusementWithAudit(employeeID uint, amount float64, description string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	args := m.Called(employeeID, amount, description, auditDB)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) CreateClawbackWithAudit(employeeID uint, amount float64, reason string, adminID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	args := m.Called(employeeID, amount, reason, adminID, auditDB)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) CreateReimbursementBatchWithAudit(employeeID uint, drafts []model.Reimbursement, auditDB *middleware.AuditableDB) ([]*model.Reimbursement, []error, error) {
+	args := m.Called(employeeID, drafts, auditDB)
+	return args.Get(0).([]*model.Reimbursement), args.Get(1).([]error), args.Error(2)
+}
+
+func (m *MockReimbursementRepository) ListReimbursements(filter request.ReimbursementFilter, offset int, limit int) ([]model.Reimbursement, int64, error) {
+	args := m.Called(filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]model.Reimbursement), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockReimbursementRepository) CreateReimbusement(employeeID uint, amount float64, description string) (*model.Reimbursement, error) {
+	args := m.Called(employeeID, amount, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) GetReimbursementByID(reimbursementID uint) (*model.Reimbursement, error) {
+	args := m.Called(reimbursementID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) ApproveReimbursementWithAudit(reimbursement *model.Reimbursement, approverID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	args := m.Called(reimbursement, approverID, auditDB)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) RejectReimbursementWithAudit(reimbursement *model.Reimbursement, approverID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	args := m.Called(reimbursement, approverID, auditDB)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) GetProcessedPayslipCoveringDate(employeeID uint, date time.Time) (*model.Payslip, error) {
+	args := m.Called(employeeID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Payslip), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) AttachReceiptWithAudit(reimbursement *model.Reimbursement, path string, contentType string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	args := m.Called(reimbursement, path, contentType, auditDB)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
+func (m *MockReimbursementRepository) FindPotentialDuplicateReimbursement(employeeID uint, amount float64, date time.Time, windowDays int, amountTolerance float64) (*model.Reimbursement, error) {
+	args := m.Called(employeeID, amount, date, windowDays, amountTolerance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reimbursement), args.Error(1)
+}
+
 // Tests for CreateReimbusement function
 
 func TestReimbursementHandler_CreateReimbusement_ValidRequest(t *testing.T) {
@@ -553,3 +682,714 @@ func BenchmarkReimbursementHandler_CreateReimbusement(b *testing.B) {
 		_ = handler.CreateReimbusement(c)
 	}
 }
+
+// Tests for ApproveReimbursement function
+
+// approveReimbursementRequest runs ApproveReimbursement as the given role for a pending reimbursement of the amount
+func approveReimbursementRequest(t *testing.T, role string, amount float64) (*httptest.ResponseRecorder, *MockReimbursementRepository) {
+	mockRepo := new(MockReimbursementRepository)
+	handler := &ReimbusementHandler{
+		Response:         response.NewResponse(),
+		ReimbusementRepo: mockRepo,
+		Approval: config.ApprovalRouting{
+			{MaxAmount: 1000000, Role: "manager"},
+			{Role: "admin"},
+		},
+	}
+
+	reimbursement := &model.Reimbursement{
+		DefaultAttribute: model.DefaultAttribute{ID: 1},
+		EmployeeID:       2,
+		Amount:           amount,
+		Status:           model.ReimbursementPending,
+	}
+	mockRepo.On("GetReimbursementByID", uint(1)).Return(reimbursement, nil)
+	mockRepo.On("GetProcessedPayslipCoveringDate", uint(2), mock.Anything).Return(nil, nil).Maybe()
+	mockRepo.On("GetDB").Return(&gorm.DB{}).Maybe()
+	mockRepo.On("ApproveReimbursementWithAudit", reimbursement, uint(7), mock.AnythingOfType("*middleware.AuditableDB")).Return(reimbursement, nil).Maybe()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/reimbursement/approve/1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("user_id", 7)
+	c.Set("role", role)
+
+	err := handler.ApproveReimbursement(c)
+	require.NoError(t, err)
+	return rec, mockRepo
+}
+
+func TestReimbursementHandler_ApproveReimbursement_ManagerApprovesSmallClaim(t *testing.T) {
+	rec, mockRepo := approveReimbursementRequest(t, "manager", 250000)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockRepo.AssertCalled(t, "ApproveReimbursementWithAudit", mock.Anything, uint(7), mock.Anything)
+}
+
+func TestReimbursementHandler_ApproveReimbursement_ManagerRejectedOnLargeClaim(t *testing.T) {
+	rec, mockRepo := approveReimbursementRequest(t, "manager", 5000000)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Contains(t, body["message"], "requires the admin role")
+	mockRepo.AssertNotCalled(t, "ApproveReimbursementWithAudit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReimbursementHandler_ApproveReimbursement_AdminApprovesLargeClaim(t *testing.T) {
+	rec, mockRepo := approveReimbursementRequest(t, "admin", 5000000)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockRepo.AssertCalled(t, "ApproveReimbursementWithAudit", mock.Anything, uint(7), mock.Anything)
+}
+
+// Tests for CreateReimbusement duplicate detection
+
+// createReimbursementWithDuplicateCheck runs the real CreateReimbusement with duplicate detection configured
+func createReimbursementWithDuplicateCheck(t *testing.T, duplicateCheck config.DuplicateCheckConfig, duplicate *model.Reimbursement) (map[string]interface{}, *MockReimbursementRepository) {
+	mockRepo := new(MockReimbursementRepository)
+	handler := &ReimbusementHandler{
+		Response:         response.NewResponse(),
+		ReimbusementRepo: mockRepo,
+		DuplicateCheck:   duplicateCheck,
+	}
+
+	created := &model.Reimbursement{
+		DefaultAttribute: model.DefaultAttribute{ID: 2},
+		EmployeeID:       1,
+		Amount:           150000,
+		Status:           model.ReimbursementPending,
+	}
+	mockRepo.On("GetDB").Return(&gorm.DB{})
+	mockRepo.On("FindPotentialDuplicateReimbursement", uint(1), 150000.0, mock.AnythingOfType("time.Time"), 3, 0.0).Return(duplicate, nil).Maybe()
+	mockRepo.On("CreateReimbusementWithAudit", uint(1), 150000.0, "Taxi to client office", mock.AnythingOfType("*middleware.AuditableDB")).Return(created, nil).Maybe()
+	mockRepo.On("CreatePossibleDuplicateReimbusementWithAudit", uint(1), 150000.0, "Taxi to client office", mock.AnythingOfType("*middleware.AuditableDB")).Return(created, nil).Maybe()
+
+	e := echo.New()
+	body := `{"employee_id":1,"amount":150000,"description":"Taxi to client office"}`
+	req := httptest.NewRequest(http.MethodPost, "/reimbusement/create", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", 1)
+
+	require.NoError(t, handler.CreateReimbusement(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	return result, mockRepo
+}
+
+func TestReimbursementHandler_CreateReimbusement_DuplicateFlagged(t *testing.T) {
+	existing := &model.Reimbursement{DefaultAttribute: model.DefaultAttribute{ID: 1}, EmployeeID: 1, Amount: 150000}
+
+	result, mockRepo := createReimbursementWithDuplicateCheck(t, config.DuplicateCheckConfig{Enabled: true, WindowDays: 3}, existing)
+
+	data, ok := result["data"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(1), data["potential_duplicate_id"])
+	assert.NotEmpty(t, data["warning"])
+	assert.Equal(t, float64(2), data["reimbursement"].(map[string]interface{})["id"])
+	mockRepo.AssertCalled(t, "CreatePossibleDuplicateReimbusementWithAudit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReimbursementHandler_CreateReimbusement_DifferentNotFlagged(t *testing.T) {
+	result, mockRepo := createReimbursementWithDuplicateCheck(t, config.DuplicateCheckConfig{Enabled: true, WindowDays: 3}, nil)
+
+	data, ok := result["data"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(2), data["reimbursement"].(map[string]interface{})["id"])
+	assert.NotContains(t, data, "warning")
+	assert.NotContains(t, data, "potential_duplicate_id")
+	mockRepo.AssertNotCalled(t, "CreatePossibleDuplicateReimbusementWithAudit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReimbursementHandler_CreateReimbusement_DuplicateCheckDisabled(t *testing.T) {
+	result, mockRepo := createReimbursementWithDuplicateCheck(t, config.DuplicateCheckConfig{}, nil)
+
+	data, ok := result["data"].(map[string]interface{})
+	require.True(t, ok)
+	assert.NotContains(t, data, "warning")
+	mockRepo.AssertNotCalled(t, "FindPotentialDuplicateReimbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReimbursementHandler_CreateReimbusement_SameDayResubmissionFlagged(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+	handler.DuplicateCheck = config.DuplicateCheckConfig{Enabled: true, WindowDays: 3}
+
+	create := func(amount string) *httptest.ResponseRecorder {
+		e := echo.New()
+		body := `{"employee_id":1,"amount":` + amount + `,"description":"Taxi to client office"}`
+		req := httptest.NewRequest(http.MethodPost, "/reimbusement/create", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", 1)
+		require.NoError(t, handler.CreateReimbusement(c))
+		return rec
+	}
+
+	rec := create("90000")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "warning")
+
+	// The same amount again on the same day is created with the warning, not refused as a second claim
+	rec = create("90000")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result struct {
+		Data res.CreatedReimbursementResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.NotEmpty(t, result.Data.Warning)
+	assert.NotZero(t, result.Data.PotentialDuplicateID)
+	assert.NotEqual(t, result.Data.PotentialDuplicateID, result.Data.Reimbursement.ID)
+
+	// A different claim on the same day is still refused
+	rec = create("45000")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	var count int64
+	require.NoError(t, db.Model(&model.Reimbursement{}).Where("employee_id = ? AND amount = ?", 1, 90000).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+// Tests for the reimbursement decision workflow
+
+// setupReimbursementDecision stores employee 1 with a pending reimbursement dated June 10th 2025
+func setupReimbursementDecision(t *testing.T) (*gorm.DB, *ReimbusementHandler) {
+	db, _ := setupPayrollHandlerDB(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Reimbursement{
+		DefaultAttribute:  model.DefaultAttribute{ID: 1},
+		EmployeeID:        1,
+		ReimbursementDate: time.Date(2025, time.June, 10, 0, 0, 0, 0, time.UTC),
+		Amount:            150000,
+		Category:          model.ReimbursementTravel,
+		Reason:            "Client visit taxi",
+		Status:            model.ReimbursementPending,
+	}).Error)
+
+	handler := &ReimbusementHandler{
+		Response:         response.NewResponse(),
+		ReimbusementRepo: repository.NewReimbusementRepository(db),
+		Approval:         config.ApprovalRouting{{MaxAmount: 1000000, Role: "manager"}, {Role: "admin"}},
+		Payroll:          usecases.NewPayrollUsecase(repository.NewPayslipRepository(db), repository.NewEmployeeRepository(db)),
+	}
+	return db, handler
+}
+
+// decideReimbursement runs the approve or reject handler for reimbursement 1 as the given caller
+func decideReimbursement(t *testing.T, decide func(echo.Context) error, role string, userID int) *httptest.ResponseRecorder {
+	return decideReimbursementWithBody(t, decide, role, userID, "")
+}
+
+// decideReimbursementWithBody runs the approve or reject handler for reimbursement 1 with a JSON body
+func decideReimbursementWithBody(t *testing.T, decide func(echo.Context) error, role string, userID int, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/reimbursement/1/decision", strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("role", role)
+	c.Set("user_id", userID)
+
+	require.NoError(t, decide(c))
+	return rec
+}
+
+func TestReimbursementHandler_RejectReimbursement_RecordsDecider(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+
+	rec := decideReimbursement(t, handler.RejectReimbursement, "manager", 7)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stored model.Reimbursement
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.ReimbursementRejected, stored.Status)
+	require.NotNil(t, stored.ApprovedBy)
+	assert.Equal(t, uint(7), *stored.ApprovedBy)
+	assert.NotNil(t, stored.ApprovedAt)
+
+	// A decided reimbursement cannot be decided again
+	rec = decideReimbursement(t, handler.ApproveReimbursement, "admin", 1)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Reimbursement is already rejected")
+}
+
+func TestReimbursementHandler_DecisionOnOwnReimbursement(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+
+	for _, role := range []string{"manager", "admin"} {
+		for _, decide := range []func(echo.Context) error{handler.ApproveReimbursement, handler.RejectReimbursement} {
+			rec := decideReimbursement(t, decide, role, 1)
+			assert.Equal(t, http.StatusForbidden, rec.Code, role)
+			assert.Contains(t, rec.Body.String(), "your own reimbursement")
+		}
+	}
+
+	var stored model.Reimbursement
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.ReimbursementPending, stored.Status)
+}
+
+func TestReimbursementHandler_ApproveReimbursement_PeriodAlreadyProcessed(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+	payslip := &model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		TotalAmount:    5000000,
+		ProcessedAt:    time.Now(),
+		Status:         model.PayslipStatusProcessed,
+	}
+	require.NoError(t, db.Create(payslip).Error)
+
+	rec := decideReimbursement(t, handler.ApproveReimbursement, "manager", 7)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "pay period 2025-06-01 to 2025-06-30 already processed")
+	assert.Contains(t, rec.Body.String(), `"error_code":"REIMBURSEMENT_PERIOD_CLOSED"`)
+
+	var stored model.Reimbursement
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.ReimbursementPending, stored.Status)
+
+	// Once the payslip is voided the period can be reprocessed, so approving is allowed again
+	require.NoError(t, db.Model(payslip).Update("status", model.PayslipStatusVoid).Error)
+	rec = decideReimbursement(t, handler.ApproveReimbursement, "manager", 7)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.ReimbursementApproved, stored.Status)
+	require.NotNil(t, stored.ApprovedBy)
+	assert.Equal(t, uint(7), *stored.ApprovedBy)
+}
+
+func TestReimbursementHandler_ApproveReimbursement_PartialAmount(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+
+	for _, body := range []string{`{"approved_amount": 0}`, `{"approved_amount": -100}`, `{"approved_amount": 150000.01}`} {
+		rec := decideReimbursementWithBody(t, handler.ApproveReimbursement, "manager", 7, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		assert.Contains(t, rec.Body.String(), `"error_code":"AMOUNT_INVALID"`, body)
+	}
+
+	var stored model.Reimbursement
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.ReimbursementPending, stored.Status)
+
+	rec := decideReimbursementWithBody(t, handler.ApproveReimbursement, "manager", 7, `{"approved_amount": 100000}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"approved_amount":100000`)
+
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, model.ReimbursementApproved, stored.Status)
+	assert.Equal(t, 150000.0, stored.Amount)
+	require.NotNil(t, stored.ApprovedAmount)
+	assert.Equal(t, 100000.0, *stored.ApprovedAmount)
+	assert.Equal(t, 100000.0, stored.PayableAmount())
+}
+
+func TestReimbursementHandler_ApproveReimbursement_PartialAmountRoundedInCurrency(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+	require.NoError(t, db.Model(&model.Employee{}).Where("id = ?", 1).Update("currency", "JPY").Error)
+
+	// Yen has no minor unit, so 0.4 rounds to nothing
+	rec := decideReimbursementWithBody(t, handler.ApproveReimbursement, "manager", 7, `{"approved_amount": 0.4}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"AMOUNT_INVALID"`)
+
+	rec = decideReimbursementWithBody(t, handler.ApproveReimbursement, "manager", 7, `{"approved_amount": 99999.5}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var stored model.Reimbursement
+	require.NoError(t, db.First(&stored, 1).Error)
+	require.NotNil(t, stored.ApprovedAmount)
+	assert.Equal(t, 100000.0, *stored.ApprovedAmount)
+}
+
+// Tests for reimbursement receipts
+
+// pngReceipt is the start of a PNG image, enough for content detection
+var pngReceipt = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+// setupReceiptHandler stores employee 1's pending reimbursement 1 and keeps receipts in a temporary directory
+func setupReceiptHandler(t *testing.T, maxSizeBytes int64) (*gorm.DB, *ReimbusementHandler) {
+	db, handler := setupReimbursementDecision(t)
+	handler.Receipts = config.ReceiptStorageConfig{Dir: t.TempDir(), MaxSizeBytes: maxSizeBytes}
+	return db, handler
+}
+
+// receiptRequest runs a receipt handler for reimbursement 1 as the given caller, with the file as the receipt field when set
+func receiptRequest(t *testing.T, handle func(echo.Context) error, role string, userID uint, filename string, content []byte) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	contentType := ""
+	if content != nil {
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("receipt", filename)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		contentType = writer.FormDataContentType()
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/reimbusement/1/receipt", body)
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set("role", role)
+	c.Set("user_id", int(userID))
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, handle(c))
+	return rec
+}
+
+func TestReimbursementHandler_UploadReceipt_OwnerUploadsPNG(t *testing.T) {
+	db, handler := setupReceiptHandler(t, 1<<20)
+
+	rec := receiptRequest(t, handler.UploadReceipt, "employee", 1, "taxi.png", pngReceipt)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"receipt_url":"/api/v1/reimbusement/1/receipt"`)
+	assert.Contains(t, rec.Body.String(), `"receipt_content_type":"image/png"`)
+
+	var stored model.Reimbursement
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.Equal(t, "image/png", stored.ReceiptContentType)
+	saved, err := os.ReadFile(stored.ReceiptPath)
+	require.NoError(t, err)
+	assert.Equal(t, pngReceipt, saved)
+
+	// A manager deciding on it can see the receipt
+	rec = receiptRequest(t, handler.GetReimbursement, "manager", 7, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"receipt_url":"/api/v1/reimbusement/1/receipt"`)
+
+	rec = receiptRequest(t, handler.DownloadReceipt, "manager", 7, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, pngReceipt, rec.Body.Bytes())
+}
+
+func TestReimbursementHandler_UploadReceipt_Rejected(t *testing.T) {
+	db, handler := setupReceiptHandler(t, 32)
+
+	// Another employee, even with a valid file
+	rec := receiptRequest(t, handler.UploadReceipt, "employee", 2, "taxi.png", pngReceipt[:16])
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"ACCESS_DENIED"`)
+
+	// Not an image or PDF, whatever the file name says
+	rec = receiptRequest(t, handler.UploadReceipt, "employee", 1, "taxi.png", []byte("just some text"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Receipt must be a JPG, PNG or PDF file")
+	assert.Contains(t, rec.Body.String(), `"error_code":"RECEIPT_UNSUPPORTED_TYPE"`)
+
+	// Over the configured size
+	rec = receiptRequest(t, handler.UploadReceipt, "admin", 1, "taxi.png", pngReceipt)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "maximum size")
+	assert.Contains(t, rec.Body.String(), `"error_code":"RECEIPT_TOO_LARGE"`)
+
+	// No file at all
+	rec = receiptRequest(t, handler.UploadReceipt, "employee", 1, "", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"RECEIPT_REQUIRED"`)
+
+	var stored model.Reimbursement
+	require.NoError(t, db.First(&stored, 1).Error)
+	assert.False(t, stored.HasReceipt())
+	entries, err := os.ReadDir(handler.Receipts.Dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// listReimbursements runs the real ListReimbursements with the query as the given caller, with
+// reimbursement 1 of employee 1 and reimbursement 2 of employee 2 stored
+func listReimbursements(t *testing.T, query string, role string, userID uint) *httptest.ResponseRecorder {
+	db, handler := setupReimbursementDecision(t)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "John Roe", Password: "hashed", Role: "manager", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Reimbursement{
+		DefaultAttribute:  model.DefaultAttribute{ID: 2},
+		EmployeeID:        2,
+		ReimbursementDate: time.Date(2025, time.June, 12, 0, 0, 0, 0, time.UTC),
+		Amount:            90000,
+		Category:          model.ReimbursementMeals,
+		Reason:            "Team lunch",
+		Status:            model.ReimbursementApproved,
+	}).Error)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/reimbusement?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, handler.ListReimbursements(c))
+	return rec
+}
+
+// listedReimbursementIDs decodes the ids of a reimbursement listing
+func listedReimbursementIDs(t *testing.T, rec *httptest.ResponseRecorder) []uint {
+	var body struct {
+		Data struct {
+			Records []res.ReimbursementResponse `json:"records"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	ids := []uint{}
+	for _, record := range body.Data.Records {
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
+
+func TestReimbursementHandler_ListReimbursements_ScopedToCaller(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		role   string
+		userID uint
+		want   []uint
+	}{
+		{"employee asking for another employee", "employee_id=2", "employee", 1, []uint{1}},
+		{"manager sees only their own", "", "manager", 2, []uint{2}},
+		{"admin filters by employee", "employee_id=2", "admin", 9, []uint{2}},
+		{"admin lists everyone", "", "admin", 9, []uint{2, 1}},
+		{"admin filters by status and dates", "status=pending&start_date=2025-06-01&end_date=2025-06-10", "admin", 9, []uint{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := listReimbursements(t, tt.query, tt.role, tt.userID)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.want, listedReimbursementIDs(t, rec))
+		})
+	}
+}
+
+func TestReimbursementHandler_ListReimbursements_InvalidFilters(t *testing.T) {
+	for query, errorCode := range map[string]string{
+		"start_date=2025-13-01":                     "PERIOD_INVALID",
+		"end_date=10/06/2025":                       "PERIOD_INVALID",
+		"status=archived":                           "INVALID_QUERY",
+		"start_date=2025-06-10&end_date=2025-06-01": "PERIOD_INVALID",
+	} {
+		rec := listReimbursements(t, query, "admin", 9)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), `"error_code":"`+errorCode+`"`, query)
+	}
+}
+
+// submitReimbursementBatch runs the batch handler with the body as employee 1 (Jane Doe), who has a reimbursement on 2025-06-10
+func submitReimbursementBatch(t *testing.T, body string, maxItems int) (*gorm.DB, *httptest.ResponseRecorder) {
+	db, handler := setupReimbursementDecision(t)
+	handler.Helper.TimeLocation = time.UTC
+	handler.Batch = config.ReimbursementBatchConfig{MaxItems: maxItems}
+
+	e := echo.New()
+	e.Validator = helper.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/reimbusement/batch", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("role", "employee")
+	c.Set("user_id", 1)
+	c.Set("authenticated_role", "employee")
+	c.Set("authenticated_user_id", uint(1))
+
+	require.NoError(t, handler.CreateReimbursementBatch(c))
+	return db, rec
+}
+
+func TestReimbursementHandler_CreateReimbursementBatch_PerItemResults(t *testing.T) {
+	db, rec := submitReimbursementBatch(t, `[
+		{"amount": 120000, "description": "Client visit taxi", "date": "2025-06-02"},
+		{"amount": 0, "description": "Nothing spent", "date": "2025-06-03"},
+		{"amount": 50000, "description": "   ", "date": "2025-06-04"},
+		{"amount": 75000, "description": "Team lunch", "date": "2025-06-10"},
+		{"amount": 75000, "description": "Conference ticket", "date": "2999-01-01"},
+		{"amount": 80000, "description": "Parking", "date": "2025-06-02"},
+		{"amount": 30500.5, "description": "Train ticket", "date": "2025-06-05"}
+	]`, 10)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data res.ReimbursementBatchResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 7, body.Data.Total)
+	assert.Equal(t, 3, body.Data.Succeeded)
+	assert.Equal(t, 4, body.Data.Failed)
+
+	statuses := make([]string, len(body.Data.Items))
+	for i, item := range body.Data.Items {
+		assert.Equal(t, i, item.Index)
+		statuses[i] = item.Status
+	}
+	assert.Equal(t, []string{"created", "failed", "failed", "failed", "failed", "created", "created"}, statuses)
+	assert.Equal(t, "amount must be greater than 0.", body.Data.Items[1].Reason)
+	assert.Equal(t, "description is required.", body.Data.Items[2].Reason)
+	assert.Contains(t, body.Data.Items[3].Reason, "already claim")
+	assert.Equal(t, "date 2999-01-01 is in the future", body.Data.Items[4].Reason)
+
+	// Items of one batch may share a day, only days claimed before the batch are refused
+	var created []model.Reimbursement
+	require.NoError(t, db.Where("id <> ?", 1).Order("id").Find(&created).Error)
+	require.Len(t, created, 3)
+	assert.Equal(t, body.Data.Items[0].ReimbursementID, created[0].ID)
+	assert.Equal(t, body.Data.Items[5].ReimbursementID, created[1].ID)
+	assert.Equal(t, "2025-06-02", created[1].ReimbursementDate.UTC().Format("2006-01-02"))
+	assert.Equal(t, body.Data.Items[6].ReimbursementID, created[2].ID)
+	for _, reimbursement := range created {
+		assert.Equal(t, uint(1), reimbursement.EmployeeID)
+		require.NotNil(t, reimbursement.CreatedBy)
+		assert.Equal(t, uint(1), *reimbursement.CreatedBy)
+	}
+	assert.Equal(t, "2025-06-05", created[2].ReimbursementDate.UTC().Format("2006-01-02"))
+	assert.Equal(t, 30500.5, created[2].Amount)
+}
+
+func TestReimbursementHandler_CreateReimbursementBatch_Rejected(t *testing.T) {
+	item := `{"amount": 120000, "description": "Client visit taxi", "date": "2025-06-02"}`
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{name: "empty", body: `[]`, wantCode: response.ErrCodeReimbursementBatchEmpty},
+		{name: "over the limit", body: "[" + item + "," + item + "," + item + "]", wantCode: response.ErrCodeReimbursementBatchTooLarge},
+		{name: "not an array", body: item, wantCode: response.ErrCodeInvalidRequestBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := submitReimbursementBatch(t, tt.body, 2)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantCode)
+
+			var count int64
+			require.NoError(t, db.Model(&model.Reimbursement{}).Count(&count).Error)
+			assert.Equal(t, int64(1), count, "nothing created")
+		})
+	}
+}
+
+// createClawback runs CreateReimbusement on the decision fixture for employee 1 as the given caller
+func createClawback(t *testing.T, handler *ReimbusementHandler, role string, userID uint, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/reimbusement/create", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("authenticated_role", role)
+	c.Set("authenticated_user_id", userID)
+
+	require.NoError(t, handler.CreateReimbusement(c))
+	return rec
+}
+
+func TestReimbursementHandler_CreateReimbusement_AdminClawback(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+	approveReimbursementToday(t, db, 100000)
+
+	rec := createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":-75000.25,"description":" May hotel paid twice "}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"clawback":true`)
+
+	var clawback model.Reimbursement
+	require.NoError(t, db.Where("amount < 0").First(&clawback).Error)
+	assert.Equal(t, uint(1), clawback.EmployeeID)
+	assert.Equal(t, -75000.25, clawback.Amount)
+	assert.Equal(t, "May hotel paid twice", clawback.Reason)
+	assert.Equal(t, model.ReimbursementApproved, clawback.Status)
+	require.NotNil(t, clawback.ApprovedBy)
+	assert.Equal(t, uint(9), *clawback.ApprovedBy)
+}
+
+func TestReimbursementHandler_CreateReimbusement_ClawbackRefused(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+
+	rec := createClawback(t, handler, "employee", 1, `{"employee_id":1,"amount":-75000,"description":"Give some back"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":0,"description":"Nothing"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), response.ErrCodeAmountInvalid)
+
+	rec = createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":-75000,"description":"  "}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Today's period already paid out
+	today := time.Now()
+	require.NoError(t, db.Create(&model.Payslip{
+		EmployeeID:     1,
+		PayPeriodStart: today.AddDate(0, 0, -15),
+		PayPeriodEnd:   today.AddDate(0, 0, 15),
+		BasicSalary:    5000000,
+		TotalAmount:    5000000,
+		ProcessedAt:    today,
+		Status:         model.PayslipStatusProcessed,
+	}).Error)
+	rec = createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":-75000,"description":"May hotel paid twice"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), response.ErrCodeReimbursementPeriodClosed)
+
+	var count int64
+	require.NoError(t, db.Model(&model.Reimbursement{}).Where("amount <= 0").Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// approveReimbursementToday stores an approved reimbursement of employee 1 dated today
+func approveReimbursementToday(t *testing.T, db *gorm.DB, amount float64) {
+	require.NoError(t, db.Create(&model.Reimbursement{
+		EmployeeID:        1,
+		ReimbursementDate: time.Now(),
+		Amount:            amount,
+		Category:          model.ReimbursementTravel,
+		Reason:            "Client visit taxi",
+		Status:            model.ReimbursementApproved,
+	}).Error)
+}
+
+func TestReimbursementHandler_CreateReimbusement_ClawbackBelowFloor(t *testing.T) {
+	db, handler := setupReimbursementDecision(t)
+	approveReimbursementToday(t, db, 50000)
+
+	// 50,000 approved this month, clawing back 75,000 takes it to -25,000 under the default floor of 0
+	rec := createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":-75000,"description":"May hotel paid twice"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), response.ErrCodeReimbursementBelowFloor)
+
+	var count int64
+	require.NoError(t, db.Model(&model.Reimbursement{}).Where("amount < 0").Count(&count).Error)
+	assert.Zero(t, count)
+
+	// Down to the floor exactly is allowed
+	rec = createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":-50000,"description":"May hotel paid twice"}`)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// A later clawback counts the earlier one, a negative floor lets it through
+	rec = createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":-10000,"description":"June taxi paid twice"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	handler.Payroll.Config.ReimbursementFloor = -10000
+	rec = createClawback(t, handler, "admin", 9, `{"employee_id":1,"amount":-10000,"description":"June taxi paid twice"}`)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = createClawback(t, handler, "admin", 9, `{"employee_id":42,"amount":-10000,"description":"Unknown"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RoleTemplateHandler handles role salary template requests.
+type RoleTemplateHandler struct {
+	Helper   helper.NewHelper
+	DB       *gorm.DB
+	Response response.Interface
+
+	BaseRepo         repository.BaseRepositoryInterface
+	RoleTemplateRepo repository.RoleTemplateRepository
+}
+
+// CreateRoleTemplate creates the salary template of a role
+func (h *RoleTemplateHandler) CreateRoleTemplate(c echo.Context) error {
+	req := request.CreateRoleTemplateRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+
+	if req.Role == "" {
+		return h.Response.SendBadRequest(c, "Role is required", nil)
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.RoleTemplateRepo.GetDB())
+
+	template, err := h.RoleTemplateRepo.CreateRoleTemplateWithAudit(req, auditDB)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to create role template", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Role template created successfully", template)
+}
+
+// GetAllRoleTemplates lists every role template
+func (h *RoleTemplateHandler) GetAllRoleTemplates(c echo.Context) error {
+	templates, err := h.RoleTemplateRepo.GetAllRoleTemplates()
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve role templates", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Role templates retrieved successfully", templates)
+}
+
+// GetRoleTemplate retrieves the template of a role
+func (h *RoleTemplateHandler) GetRoleTemplate(c echo.Context) error {
+	template, err := h.RoleTemplateRepo.GetRoleTemplateByRole(c.Param("role"))
+	if err != nil {
+		return h.Response.SendNotFound(c, "Role template not found", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Role template retrieved successfully", template)
+}
+
+// EditRoleTemplate updates the template of a role. Existing employees are not affected.
+func (h *RoleTemplateHandler) EditRoleTemplate(c echo.Context) error {
+	req := request.UpdateRoleTemplateRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.RoleTemplateRepo.GetDB())
+
+	template, err := h.RoleTemplateRepo.UpdateRoleTemplateWithAudit(c.Param("role"), req, auditDB)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to update role template", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Role template updated successfully", template)
+}
+
+// DeleteRoleTemplate deletes the template of a role
+func (h *RoleTemplateHandler) DeleteRoleTemplate(c echo.Context) error {
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.RoleTemplateRepo.GetDB())
+
+	err := h.RoleTemplateRepo.DeleteRoleTemplateWithAudit(c.Param("role"), auditDB)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to delete role template", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Role template deleted successfully", nil)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
+)
+
+// TaxBracketHandler handles income tax bracket requests.
+type TaxBracketHandler struct {
+	Helper   helper.NewHelper
+	DB       *gorm.DB
+	Response response.Interface
+
+	TaxBracketRepo repository.TaxBracketRepository
+}
+
+// GetAllTaxBrackets lists every income tax bracket
+func (h *TaxBracketHandler) GetAllTaxBrackets(c echo.Context) error {
+	brackets, err := h.TaxBracketRepo.GetAllTaxBrackets()
+	if err != nil {
+		return h.Response.SendError(c, "Failed to retrieve tax brackets", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Tax brackets retrieved successfully", brackets)
+}
+
+// ReplaceTaxBrackets replaces every income tax bracket. Existing payslips are not affected.
+func (h *TaxBracketHandler) ReplaceTaxBrackets(c echo.Context) error {
+	req := request.ReplaceTaxBracketsRequest{}
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request data", err.Error())
+	}
+
+	// Get auditable database instance
+	auditDB := helper.GetAuditableDB(c, h.TaxBracketRepo.GetDB())
+
+	brackets, err := h.TaxBracketRepo.ReplaceTaxBracketsWithAudit(req, auditDB)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to replace tax brackets", err.Error())
+	}
+	return h.Response.SendSuccess(c, "Tax brackets replaced successfully", brackets)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/gorm"
+)
+
+// TimesheetHandler handles timesheet submission and sign-off requests.
+type TimesheetHandler struct {
+	Helper   helper.NewHelper
+	DB       *gorm.DB
+	Response response.Interface
+
+	BaseRepo      repository.BaseRepositoryInterface
+	TimesheetRepo repository.TimesheetRepository
+	PayslipRepo   repository.PayslipRepository
+}
+
+// GetTimesheet assembles an employee's attendance and overtime for a period into a timesheet
+func (h *TimesheetHandler) GetTimesheet(c echo.Context) error {
+	var employeeID uint
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &employeeID); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid employee ID format", err.Error())
+	}
+
+	periodStart, err := time.Parse("2006-01-02", c.QueryParam("period_start"))
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid period start, expected YYYY-MM-DD", err.Error())
+	}
+	periodEnd, err := time.Parse("2006-01-02", c.QueryParam("period_end"))
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Invalid period end, expected YYYY-MM-DD", err.Error())
+	}
+	if periodEnd.Before(periodStart) {
+		return h.Response.SendBadRequest(c, "Period end must be after start date", nil)
+	}
+
+	// Check authorization - employees can only access their own timesheet
+	if !helper.ValidateEmployeeAccess(c, employeeID) {
+		return h.Response.SendCustomResponse(c, 403, "Access denied. You can only access your own timesheet.", nil)
+	}
+
+	timesheet, err := h.buildTimesheet(employeeID, periodStart, periodEnd)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to build timesheet", err.Error())
+	}
+
+	submitted, err := h.TimesheetRepo.GetTimesheetByEmployeeAndPeriod(employeeID, periodStart, periodEnd)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return h.Response.SendError(c, "Failed to retrieve timesheet", err.Error())
+	}
+	if submitted != nil {
+		timesheet.TimesheetID = submitted.ID
+		timesheet.Status = string(submitted.Status)
+	}
+
+	return h.Response.SendSuccess(c, "Timesheet retrieved successfully", timesheet)
+}
+
+// SubmitTimesheet locks the timesheet of a period pending manager approval
+func (h *TimesheetHandler) SubmitTimesheet(c echo.Context) error {
+	var req request.SubmitTimesheetRequest
+	if err := c.Bind(&req); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid request body", err.Error())
+	}
+
+	// Validate the request
+	if req.PeriodEnd.Before(req.PeriodStart) {
+		return h.Response.SendBadRequest(c, "Period end must be after start date", nil)
+	}
+
+	// Check authorization - employees can only submit their own timesheet
+	if !helper.ValidateEmployeeAccess(c, req.EmployeeID) {
+		return h.Response.SendCustomResponse(c, 403, "Access denied. You can only submit your own timesheet.", nil)
+	}
+
+	sheet, err := h.buildTimesheet(req.EmployeeID, req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to build timesheet", err.Error())
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.TimesheetRepo.GetDB())
+
+	timesheet, err := h.TimesheetRepo.SubmitTimesheetWithAudit(&model.Timesheet{
+		EmployeeID:     req.EmployeeID,
+		PeriodStart:    req.PeriodStart,
+		PeriodEnd:      req.PeriodEnd,
+		AttendanceDays: sheet.AttendanceDays,
+		HoursWorked:    sheet.HoursWorked,
+		OvertimeHours:  sheet.OvertimeHours,
+	}, auditDB)
+	if err != nil {
+		return h.Response.SendBadRequest(c, "Failed to submit timesheet", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Timesheet submitted successfully", timesheet)
+}
+
+// ApproveTimesheet signs off a submitted timesheet so its period can be included in payroll
+func (h *TimesheetHandler) ApproveTimesheet(c echo.Context) error {
+	var timesheetID uint
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &timesheetID); err != nil {
+		return h.Response.SendBadRequest(c, "Invalid timesheet ID format", err.Error())
+	}
+
+	timesheet, err := h.TimesheetRepo.GetTimesheetByID(timesheetID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return h.Response.SendNotFound(c, "Timesheet not found", nil)
+		}
+		return h.Response.SendError(c, "Failed to retrieve timesheet", err.Error())
+	}
+
+	if timesheet.Status != model.TimesheetSubmitted {
+		return h.Response.SendBadRequest(c, "Only submitted timesheets can be approved", nil)
+	}
+
+	approverID, _ := c.Get("user_id").(int)
+	if uint(approverID) == timesheet.EmployeeID {
+		return h.Response.SendCustomResponse(c, 403, "Access denied. You cannot approve your own timesheet.", nil)
+	}
+
+	// Get auditable DB instance
+	auditDB := helper.GetAuditableDB(c, h.TimesheetRepo.GetDB())
+
+	approved, err := h.TimesheetRepo.ApproveTimesheetWithAudit(timesheet, uint(approverID), auditDB)
+	if err != nil {
+		return h.Response.SendError(c, "Failed to approve timesheet", err.Error())
+	}
+
+	return h.Response.SendSuccess(c, "Timesheet approved successfully", approved)
+}
+
+// buildTimesheet collects the attendance and approved overtime of the period
+func (h *TimesheetHandler) buildTimesheet(employeeID uint, periodStart, periodEnd time.Time) (*res.TimesheetResponse, error) {
+	employee, err := h.PayslipRepo.GetEmployeeByID(employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	attendances, err := h.PayslipRepo.GetAttendanceForPeriod(employeeID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	overtimes, err := h.PayslipRepo.GetOvertimeForPeriod(employeeID, periodStart.Format("2006-01-02"), periodEnd.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet := &res.TimesheetResponse{
+		EmployeeID:   employee.ID,
+		EmployeeName: employee.Name,
+		PeriodStart:  periodStart,
+		PeriodEnd:    periodEnd,
+		Status:       "open",
+		Attendances:  []res.AttendanceDetail{},
+		Overtimes:    []res.TimesheetOvertime{},
+	}
+
+	for _, attendance := range attendances {
+		checkOut := ""
+		if attendance.Checkout != nil {
+			checkOut = attendance.Checkout.Format("15:04")
+		}
+		timesheet.Attendances = append(timesheet.Attendances, res.AttendanceDetail{
+			Date:        attendance.Date.Format("2006-01-02"),
+			CheckIn:     attendance.Checkin.Format("15:04"),
+			CheckOut:    checkOut,
+			HoursWorked: attendance.HoursWorked,
+			Status:      attendance.Status,
+			Open:        !attendance.IsComplete(),
+		})
+		if attendance.IsPresent() {
+			timesheet.AttendanceDays++
+		}
+		timesheet.HoursWorked += attendance.HoursWorked
+	}
+
+	for _, overtime := range overtimes {
+		timesheet.Overtimes = append(timesheet.Overtimes, res.TimesheetOvertime{
+			Date:   overtime.OvertimeDate,
+			Hours:  overtime.Hours,
+			Reason: overtime.Reason,
+		})
+		timesheet.OvertimeHours += overtime.Hours
+	}
+
+	return timesheet, nil
+}
@@ -0,0 +1,137 @@
+package helper
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yourname/payslip-system/internal/config"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/model"
+)
+
+// DetectAttendanceAnomalies flags a present attendance record against the configured thresholds,
+// a normal record yields no anomalies. Days before today without a checkout are flagged as missing checkout.
+func DetectAttendanceAnomalies(attendance model.Attendance, cfg config.AttendanceAnomalyConfig, today time.Time) []res.AttendanceAnomaly {
+	if !attendance.IsPresent() {
+		return nil
+	}
+
+	var anomalies []res.AttendanceAnomaly
+	flag := func(reason, value, threshold string) {
+		anomalies = append(anomalies, res.AttendanceAnomaly{
+			AttendanceID: attendance.ID,
+			EmployeeID:   attendance.EmployeeID,
+			EmployeeName: attendance.Employee.Name,
+			Date:         attendance.Date.Format("2006-01-02"),
+			Reason:       reason,
+			Value:        value,
+			Threshold:    threshold,
+		})
+	}
+
+	if cfg.IsLateCheckin(attendance.Checkin) {
+		flag(res.AnomalyLateCheckin, attendance.Checkin.Format("15:04"), cfg.LateCheckinLimit())
+	}
+
+	if !attendance.IsComplete() {
+		todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, attendance.Date.Location())
+		if attendance.Date.Before(todayDate) {
+			flag(res.AnomalyMissingCheckout, "", "")
+		}
+		return anomalies
+	}
+
+	if attendance.HoursWorked < cfg.MinHours {
+		flag(res.AnomalyShortDay, fmt.Sprintf("%dh", attendance.HoursWorked), fmt.Sprintf("%dh", cfg.MinHours))
+	}
+	if attendance.HoursWorked > cfg.MaxHours {
+		flag(res.AnomalyLongDay, fmt.Sprintf("%dh", attendance.HoursWorked), fmt.Sprintf("%dh", cfg.MaxHours))
+	}
+
+	return anomalies
+}
+
+// SummarizeAttendance counts the attendance of one employee over the days from start to end, both midnights in the
+// server timezone. The earliest attendance of a day stands for it, a present one checked in late by the anomaly
+// thresholds or recorded as late is a late day. Weekends, holidays and days recorded as holiday without a present
+// attendance are skipped, so they neither count as absent nor break the present streak, while a leave day breaks the
+// streak without counting as absent.
+func SummarizeAttendance(attendances []model.Attendance, start, end time.Time, holidays map[string]bool, cfg config.AttendanceAnomalyConfig) res.AttendanceStats {
+	loc := start.Location()
+	byDay := make(map[string]model.Attendance, len(attendances))
+	for _, attendance := range attendances {
+		day := attendance.Date.In(loc).Format("2006-01-02")
+		if existing, seen := byDay[day]; !seen || attendance.Checkin.Before(existing.Checkin) {
+			byDay[day] = attendance
+		}
+	}
+
+	stats := res.AttendanceStats{StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02")}
+	streak, streakStart := 0, ""
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		attendance, recorded := byDay[key]
+		dayOff := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday || holidays[key] ||
+			(recorded && attendance.Status == model.AttendanceStatusHoliday)
+		if !dayOff {
+			stats.WorkingDays++
+		}
+
+		switch {
+		case recorded && attendance.IsPresent():
+			stats.PresentDays++
+			stats.TotalHoursWorked += attendance.HoursWorked
+			if attendance.Status == model.AttendanceStatusLate || cfg.IsLateCheckin(attendance.Checkin.In(loc)) {
+				stats.LateDays++
+			}
+
+			if streak == 0 {
+				streakStart = key
+			}
+			streak++
+			if streak > stats.LongestPresentStreak {
+				first, last := streakStart, key
+				stats.LongestPresentStreak = streak
+				stats.LongestStreakStart, stats.LongestStreakEnd = &first, &last
+			}
+		case dayOff:
+			// Not a working day, the streak carries on over it
+		case recorded && attendance.Status == model.AttendanceStatusLeave:
+			streak = 0
+		default:
+			stats.AbsentDays++
+			streak = 0
+		}
+	}
+	return stats
+}
+
+// earthRadiusMeters is the mean radius of the earth used for distances between coordinates
+const earthRadiusMeters = 6371000
+
+// HaversineMeters returns the great-circle distance between two coordinates in meters
+func HaversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// NearestGeofence returns the fence whose edge is closest to the coordinates, the one they are deepest inside
+// when in several, with the distance to its center. It is nil without fences, the coordinates are inside
+// the fence when the distance is at most its radius.
+func NearestGeofence(fences []config.Geofence, lat, lng float64) (*config.Geofence, float64) {
+	var nearest *config.Geofence
+	var nearestDistance float64
+	for i := range fences {
+		distance := HaversineMeters(fences[i].Latitude, fences[i].Longitude, lat, lng)
+		if nearest == nil || distance-fences[i].RadiusMeters < nearestDistance-nearest.RadiusMeters {
+			nearest, nearestDistance = &fences[i], distance
+		}
+	}
+	return nearest, nearestDistance
+}
@@ -11,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/yourname/payslip-system/internal/model"
 )
 
 // Generate slug from string
@@ -503,6 +505,94 @@ func RoundFloat(val float64, precision uint) float64 {
 	return math.Round(val*ratio) / ratio
 }
 
+// RoundFloatHalfEven rounds to the precision with halves going to the even digit (banker's rounding)
+func RoundFloatHalfEven(val float64, precision uint) float64 {
+	ratio := math.Pow(10, float64(precision))
+	return math.RoundToEven(val*ratio) / ratio
+}
+
+// currencyPrecision holds the number of decimal places of each ISO 4217 currency code
+var currencyPrecision = map[string]uint{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"BHD": 3,
+	"KWD": 3,
+}
+
+// CurrencyPrecision returns the decimal places of a currency, two for unknown currencies
+func CurrencyPrecision(currency string) uint {
+	if precision, ok := currencyPrecision[strings.ToUpper(currency)]; ok {
+		return precision
+	}
+	return 2
+}
+
+// currencyValidator checks currency codes against the ISO 4217 list
+var currencyValidator = validator.New()
+
+// IsCurrencyCode checks if the code is an upper case ISO 4217 currency code
+func IsCurrencyCode(code string) bool {
+	return currencyValidator.Var(code, "required,iso4217") == nil
+}
+
+// RoundMoney rounds an amount to the decimal places of its currency
+func RoundMoney(amount float64, currency string) float64 {
+	return RoundFloat(amount, CurrencyPrecision(currency))
+}
+
+// MoneyDecimals returns the decimal places amounts are rounded to: the configured places when set,
+// the precision of the currency otherwise
+func MoneyDecimals(currency string, configured *uint) uint {
+	if configured != nil {
+		return *configured
+	}
+	return CurrencyPrecision(currency)
+}
+
+// ToMinorUnits converts an amount to integer minor units (e.g. cents for two decimal places), halves
+// rounded away from zero
+func ToMinorUnits(amount float64, decimals uint) int64 {
+	return int64(math.Round(amount * math.Pow(10, float64(decimals))))
+}
+
+// FromMinorUnits converts integer minor units back to an amount with the given decimal places
+func FromMinorUnits(minor int64, decimals uint) float64 {
+	return float64(minor) / math.Pow(10, float64(decimals))
+}
+
+// StorePayslipMinorUnits fills the minor unit columns of a payslip from its amounts
+func StorePayslipMinorUnits(payslip *model.Payslip, decimals uint) {
+	for _, field := range payslip.MoneyFields() {
+		*field.Minor = ToMinorUnits(*field.Amount, decimals)
+	}
+	scale := int(decimals)
+	payslip.MinorUnitScale = &scale
+}
+
+// PayslipFromMinorUnits returns the payslip with its amounts taken from the minor unit columns, as a copy,
+// or the payslip itself when it has none stored
+func PayslipFromMinorUnits(payslip *model.Payslip) *model.Payslip {
+	if payslip.MinorUnitScale == nil {
+		return payslip
+	}
+	converted := *payslip
+	for _, field := range converted.MoneyFields() {
+		*field.Amount = FromMinorUnits(*field.Minor, uint(*payslip.MinorUnitScale))
+	}
+	return &converted
+}
+
+// FormatMoney renders an amount with exactly the decimal places of its currency
+func FormatMoney(amount float64, currency string) string {
+	precision := CurrencyPrecision(currency)
+	return strconv.FormatFloat(RoundFloat(amount, precision), 'f', int(precision), 64)
+}
+
 func ConvertExcelDateString(dateStr string) (string, error) {
 	// Daftar format tanggal umum yang sering digunakan di Excel
 	formats := []string{
@@ -0,0 +1,167 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and layout in points
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	marginX      = 50.0
+	marginTop    = 60.0
+	marginBottom = 50.0
+)
+
+// textLine is a single line of text placed on a page
+type textLine struct {
+	x, y float64
+	size float64
+	bold bool
+	text string
+}
+
+// Document is a minimal text-only PDF writer (A4, Helvetica) for simple reports.
+// Lines flow top to bottom and a new page starts when the current one is full.
+type Document struct {
+	pages [][]textLine
+	y     float64
+}
+
+// New creates an empty document with a first page
+func New() *Document {
+	d := &Document{}
+	d.newPage()
+	return d
+}
+
+// Title writes a large bold line
+func (d *Document) Title(text string) {
+	d.write(marginX, 16, true, text)
+}
+
+// Heading writes a bold section heading with some space above it
+func (d *Document) Heading(text string) {
+	d.Gap()
+	d.write(marginX, 12, true, text)
+}
+
+// Text writes a regular line
+func (d *Document) Text(text string) {
+	d.write(marginX, 10, false, text)
+}
+
+// Row writes the columns of a table row spread evenly over the page width
+func (d *Document) Row(columns ...string) {
+	d.row(false, columns)
+}
+
+// HeaderRow writes a bold table row
+func (d *Document) HeaderRow(columns ...string) {
+	d.row(true, columns)
+}
+
+// Gap leaves an empty line
+func (d *Document) Gap() {
+	d.advance(10)
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4 are the catalog, page tree and fonts, each page adds a page and a content object
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+i*2))
+
+		var content bytes.Buffer
+		for _, line := range page {
+			font := "F1"
+			if line.bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, line.size, line.x, line.y, escape(line.text))
+		}
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+func (d *Document) row(bold bool, columns []string) {
+	if len(columns) == 0 {
+		return
+	}
+	width := (pageWidth - 2*marginX) / float64(len(columns))
+	d.advance(14)
+	for i, column := range columns {
+		d.place(marginX+float64(i)*width, 10, bold, column)
+	}
+}
+
+func (d *Document) write(x, size float64, bold bool, text string) {
+	d.advance(size * 1.4)
+	d.place(x, size, bold, text)
+}
+
+// advance moves down by the line height, starting a new page when the current one is full
+func (d *Document) advance(height float64) {
+	if d.y-height < marginBottom {
+		d.newPage()
+	}
+	d.y -= height
+}
+
+func (d *Document) place(x, size float64, bold bool, text string) {
+	last := len(d.pages) - 1
+	d.pages[last] = append(d.pages[last], textLine{x: x, y: d.y, size: size, bold: bold, text: text})
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, nil)
+	d.y = pageHeight - marginTop
+}
+
+// escape makes text safe inside a PDF string, characters outside ASCII are replaced
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
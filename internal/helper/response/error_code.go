@@ -0,0 +1,61 @@
+package response
+
+// Machine-readable error codes sent in the error_code field next to the human message,
+// so clients can branch on and localize errors without parsing the message text.
+const (
+	// Request shape
+	ErrCodeInvalidRequestBody = "INVALID_REQUEST_BODY"
+	ErrCodeInvalidID          = "INVALID_ID"
+	ErrCodeInvalidQuery       = "INVALID_QUERY"
+	ErrCodeValidationFailed   = "VALIDATION_FAILED"
+
+	// Periods and amounts
+	ErrCodePeriodRequired = "PERIOD_REQUIRED"
+	ErrCodePeriodInvalid  = "PERIOD_INVALID"
+	ErrCodePeriodTooLong  = "PERIOD_TOO_LONG"
+	ErrCodeAmountInvalid  = "AMOUNT_INVALID"
+
+	// Access
+	ErrCodeAccessDenied = "ACCESS_DENIED"
+	ErrCodeTokenExpired = "TOKEN_EXPIRED"
+	ErrCodeTokenRevoked = "TOKEN_REVOKED"
+	ErrCodeRateLimited  = "RATE_LIMITED"
+
+	// API keys
+	ErrCodeAPIKeyInvalid     = "API_KEY_INVALID"
+	ErrCodeAPIKeyExpired     = "API_KEY_EXPIRED"
+	ErrCodeAPIKeyScopeDenied = "API_KEY_SCOPE_DENIED"
+	ErrCodeAPIKeyNotFound    = "API_KEY_NOT_FOUND"
+
+	// Payroll
+	ErrCodeEmployeeNotFound         = "EMPLOYEE_NOT_FOUND"
+	ErrCodePayslipNotFound          = "PAYSLIP_NOT_FOUND"
+	ErrCodePayslipNotVoidable       = "PAYSLIP_NOT_VOIDABLE"
+	ErrCodeVersionConflict          = "VERSION_CONFLICT"
+	ErrCodePayrollRunNotFound       = "PAYROLL_RUN_NOT_FOUND"
+	ErrCodeEmployeeNotInRun         = "EMPLOYEE_NOT_IN_RUN"
+	ErrCodeEmployeeAlreadySucceeded = "EMPLOYEE_ALREADY_SUCCEEDED"
+	ErrCodeConfirmationRequired     = "CONFIRMATION_REQUIRED"
+	ErrCodePayrollFailed            = "PAYROLL_FAILED"
+	ErrCodeCurrencyMixed            = "CURRENCY_MIXED"
+
+	// Payslip emails
+	ErrCodeEmployeeEmailMissing = "EMPLOYEE_EMAIL_MISSING"
+	ErrCodeMailNotConfigured    = "MAIL_NOT_CONFIGURED"
+	ErrCodeEmailFailed          = "EMAIL_FAILED"
+
+	// Reimbursement
+	ErrCodeReimbursementNotFound      = "REIMBURSEMENT_NOT_FOUND"
+	ErrCodeReimbursementNotPending    = "REIMBURSEMENT_NOT_PENDING"
+	ErrCodeReimbursementPeriodClosed  = "REIMBURSEMENT_PERIOD_CLOSED"
+	ErrCodeReimbursementBelowFloor    = "REIMBURSEMENT_BELOW_FLOOR"
+	ErrCodeReceiptRequired            = "RECEIPT_REQUIRED"
+	ErrCodeReceiptTooLarge            = "RECEIPT_TOO_LARGE"
+	ErrCodeReceiptUnsupportedType     = "RECEIPT_UNSUPPORTED_TYPE"
+	ErrCodeReceiptNotFound            = "RECEIPT_NOT_FOUND"
+	ErrCodeReimbursementBatchEmpty    = "REIMBURSEMENT_BATCH_EMPTY"
+	ErrCodeReimbursementBatchTooLarge = "REIMBURSEMENT_BATCH_TOO_LARGE"
+
+	// Anything failing on the server side
+	ErrCodeInternal = "INTERNAL_ERROR"
+)
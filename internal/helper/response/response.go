@@ -2,6 +2,7 @@ package response
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -23,6 +24,8 @@ type responseFormat struct {
 	Status  string
 	Message string
 	Data    interface{}
+	// ErrorCode is the machine-readable code of an error response, omitted when empty
+	ErrorCode string
 }
 
 // Interface ...
@@ -33,12 +36,16 @@ type Interface interface {
 	SendSuccess(c echo.Context, message string, data interface{}) error
 	SendSuccessWithValidation(c echo.Context, message string, data interface{}, validation interface{}, viewNilvalid bool) error
 	SendBadRequest(c echo.Context, message string, data interface{}) error
+	SendBadRequestWithCode(c echo.Context, errorCode string, message string, data interface{}) error
 	SendError(c echo.Context, message string, data interface{}) error
+	SendErrorWithCode(c echo.Context, errorCode string, message string, data interface{}) error
 	SendErrorWithValidation(c echo.Context, message string, data interface{}, validation interface{}) error
 	SendUnauthorized(c echo.Context, message string, data interface{}) error
 	SendValidationError(c echo.Context, validationErrors validator.ValidationErrors) error
 	SendNotFound(c echo.Context, message string, data interface{}) error
+	SendNotFoundWithCode(c echo.Context, errorCode string, message string, data interface{}) error
 	SendCustomResponse(c echo.Context, httpCode int, message string, data interface{}) error
+	SendCustomResponseWithCode(c echo.Context, httpCode int, errorCode string, message string, data interface{}) error
 	SendResponsByCode(c echo.Context, code int, message string, data interface{}, err error) error
 	SendPaginationResponse(c echo.Context, items interface{}, message string, totalRecord, totalRecordPerPage, totalRecordSearch, totalPage int64, currentPage int) error
 	GetBranch() string
@@ -55,7 +62,7 @@ func NewResponse() Interface {
 // SetResponse ...
 func (r *responseHelper) SetResponse(c echo.Context, code int, status string, message string, data interface{}) responseFormat {
 
-	return responseFormat{c, code, status, message, data}
+	return responseFormat{C: c, Code: code, Status: status, Message: message, Data: data}
 }
 
 // SendResponse ...
@@ -72,6 +79,9 @@ func (r *responseHelper) SendResponse(res responseFormat) error {
 		"status":  res.Status,
 		"message": res.Message,
 	}
+	if res.ErrorCode != "" {
+		resp["error_code"] = res.ErrorCode
+	}
 	if res.Data != nil {
 		resp["data"] = res.Data
 	}
@@ -96,13 +106,14 @@ func (r *responseHelper) SendResponse(res responseFormat) error {
 	return res.C.JSON(res.Code, resp)
 }
 
+// GetBranch returns the checked out git branch, empty outside a git checkout or on a detached HEAD
 func (r *responseHelper) GetBranch() string {
 	branchName := ""
 
 	firstLineHead := ""
 	file, err := os.Open(".git/HEAD")
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("Build metadata unavailable: %v", err)
 		return ""
 	}
 	defer file.Close()
@@ -124,12 +135,16 @@ func (r *responseHelper) GetBranch() string {
 	return branchName
 }
 
+// GetHash returns the commit hash the branch points at, empty when unknown
 func (r *responseHelper) GetHash(branchName string) string {
 	hash := ""
+	if branchName == "" {
+		return hash
+	}
 
 	file, err := os.Open(".git/refs/heads/" + branchName)
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("Build metadata unavailable: %v", err)
 		return ""
 	}
 	defer file.Close()
@@ -142,10 +157,11 @@ func (r *responseHelper) GetHash(branchName string) string {
 	return hash
 }
 
+// GetUpdated returns when the git index last changed, empty outside a git checkout
 func (r *responseHelper) GetUpdated() string {
 	statinfo, err := os.Stat(".git/index")
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("Build metadata unavailable: %v", err)
 		return ""
 	}
 
@@ -187,27 +203,75 @@ func (r *responseHelper) SendBadRequest(c echo.Context, message string, data int
 	return r.SendResponse(res)
 }
 
+// SendBadRequestWithCode : Send bad request response with a machine-readable error code to consumers.
+func (r *responseHelper) SendBadRequestWithCode(c echo.Context, errorCode string, message string, data interface{}) error {
+	return r.sendWithCode(c, http.StatusBadRequest, errorCode, message, data)
+}
+
 // SendError : Send error request response to consumers.
 func (r *responseHelper) SendError(c echo.Context, message string, data interface{}) error {
 	res := r.SetResponse(c, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), message, data)
 	return r.SendResponse(res)
 }
 
+// SendErrorWithCode : Send error response with a machine-readable error code to consumers.
+func (r *responseHelper) SendErrorWithCode(c echo.Context, errorCode string, message string, data interface{}) error {
+	return r.sendWithCode(c, http.StatusInternalServerError, errorCode, message, data)
+}
+
 // SendUnauthorized : Send error request response to consumers.
 func (r *responseHelper) SendUnauthorized(c echo.Context, message string, data interface{}) error {
 	res := r.SetResponse(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), message, data)
 	return r.SendResponse(res)
 }
 
-// SendValidationError : Send validation error request response to consumers.
+// SendValidationError : Send validation error request response to consumers, with one message per failing field as data.
 func (r *responseHelper) SendValidationError(c echo.Context, validationErrors validator.ValidationErrors) error {
 	errorResponse := []string{}
+	fields := r.EmptyJSONMap()
 	for _, err := range validationErrors {
-		errorResponse = append(errorResponse, strings.Trim(fmt.Sprint(err), "[]")+".")
+		message := validationMessage(err)
+		errorResponse = append(errorResponse, message+".")
+		fields[err.Field()] = message
 	}
-	res := r.SetResponse(c, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), strings.Trim(fmt.Sprint(errorResponse), "[]"), r.EmptyJSONMap())
+	res := r.SetResponse(c, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), strings.Join(errorResponse, " "), fields)
+	res.ErrorCode = ErrCodeValidationFailed
 	return r.SendResponse(res)
 }
+
+// validationMessage describes a failed rule of a field in plain words
+func validationMessage(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", err.Field())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", err.Field(), err.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", err.Field(), err.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", err.Field(), err.Param())
+	case "not_before":
+		return fmt.Sprintf("%s must not be before %s", err.Field(), err.Param())
+	case "datetime":
+		return fmt.Sprintf("%s must be formatted as %s", err.Field(), err.Param())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", err.Field(), err.Tag())
+	}
+}
+
+// ValidationMessage describes a validation error in plain words, one sentence per failing field.
+// Other errors are returned as is.
+func ValidationMessage(err error) string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err.Error()
+	}
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		messages = append(messages, validationMessage(fieldErr)+".")
+	}
+	return strings.Join(messages, " ")
+}
 func (r *responseHelper) SendErrorWithValidation(c echo.Context, message string, data interface{}, validation interface{}) error {
 	r.Validation = validation
 	return r.SendError(c, message, data)
@@ -224,12 +288,29 @@ func (r *responseHelper) SendNotFound(c echo.Context, message string, data inter
 	return r.SendResponse(res)
 }
 
+// SendNotFoundWithCode : Send not found response with a machine-readable error code to consumers.
+func (r *responseHelper) SendNotFoundWithCode(c echo.Context, errorCode string, message string, data interface{}) error {
+	return r.sendWithCode(c, http.StatusNotFound, errorCode, message, data)
+}
+
 // SendCustomResponse ...
 func (r *responseHelper) SendCustomResponse(c echo.Context, httpCode int, message string, data interface{}) error {
 	res := r.SetResponse(c, httpCode, http.StatusText(httpCode), message, data)
 	return r.SendResponse(res)
 }
 
+// SendCustomResponseWithCode : Send a response of any HTTP code with a machine-readable error code to consumers.
+func (r *responseHelper) SendCustomResponseWithCode(c echo.Context, httpCode int, errorCode string, message string, data interface{}) error {
+	return r.sendWithCode(c, httpCode, errorCode, message, data)
+}
+
+// sendWithCode sends a response carrying errorCode next to the message, which stays as it was for older clients
+func (r *responseHelper) sendWithCode(c echo.Context, httpCode int, errorCode string, message string, data interface{}) error {
+	res := r.SetResponse(c, httpCode, http.StatusText(httpCode), message, data)
+	res.ErrorCode = errorCode
+	return r.SendResponse(res)
+}
+
 // Send Pagination Response
 type ResponsePagination struct {
 	Records            interface{} `json:"records"`
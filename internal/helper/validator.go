@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CustomValidator is the request validator of Echo, with the rules of this API registered
+type CustomValidator struct {
+	validator *validator.Validate
+}
+
+// NewValidator creates the validator used by the server, reporting fields by their json names
+func NewValidator() *CustomValidator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(jsonFieldName)
+	// Registering only fails on a malformed tag name
+	if err := validate.RegisterValidation("not_before", notBefore); err != nil {
+		panic(err)
+	}
+	return &CustomValidator{validator: validate}
+}
+
+// Validate validates structs
+func (cv *CustomValidator) Validate(i interface{}) error {
+	return cv.validator.Struct(i)
+}
+
+// jsonFieldName names a field by its json tag, so validation errors match the request body
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" || name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// notBefore checks a time field is not before the sibling time field whose json name is the param,
+// e.g. validate:"not_before=pay_period_start" on the end of a period. Zero times are left to required.
+func notBefore(fl validator.FieldLevel) bool {
+	end, ok := fl.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	parent := reflect.Indirect(fl.Parent())
+	for i := 0; i < parent.NumField(); i++ {
+		if jsonFieldName(parent.Type().Field(i)) != fl.Param() {
+			continue
+		}
+		start, ok := parent.Field(i).Interface().(time.Time)
+		if !ok {
+			return false
+		}
+		return end.IsZero() || start.IsZero() || !end.Before(start)
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/yourname/payslip-system/internal/config"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// StartEmployeePurge hard-deletes employees soft-deleted longer than the retention window,
+// once at start and then every purge interval. It does nothing when the retention is disabled.
+func StartEmployeePurge(repo repository.EmployeeRepository, cfg config.EmployeeRetentionConfig) {
+	if cfg.RetentionDays == 0 {
+		return
+	}
+
+	interval := time.Duration(cfg.PurgeIntervalHours) * time.Hour
+	if interval == 0 {
+		interval = 24 * time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			PurgeDeletedEmployees(repo, cfg, time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// PurgeDeletedEmployees runs one purge of the employees deleted more than the retention window before now
+func PurgeDeletedEmployees(repo repository.EmployeeRepository, cfg config.EmployeeRetentionConfig, now time.Time) {
+	purged, err := repo.PurgeDeletedEmployees(now.AddDate(0, 0, -cfg.RetentionDays))
+	if err != nil {
+		log.Printf("Failed to purge deleted employees: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d employees deleted more than %d days ago", purged, cfg.RetentionDays)
+	}
+}
@@ -0,0 +1,86 @@
+// Package mailer tests cover:
+// 1. Rendering a text body and an attachment that read back unchanged as MIME parts
+// 2. Line breaks in header values refused
+// 3. Sending refused while no SMTP server is configured, delivered to the recipient otherwise
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
+)
+
+func TestRender_BodyAndAttachment(t *testing.T) {
+	pdf := bytes.Repeat([]byte("%PDF-1.4 payslip "), 20)
+	data, err := Render("payroll@example.com", Message{
+		To:          "jane@example.com",
+		Subject:     "Payslip for June 2025",
+		Body:        "Hello Jane,\n\nYour payslip is attached.",
+		Attachments: []Attachment{{Filename: "payslip-1-2025-06.pdf", ContentType: "application/pdf", Data: pdf}},
+	}, time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", parsed.Header.Get("To"))
+	assert.Equal(t, "Payslip for June 2025", parsed.Header.Get("Subject"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+
+	text, err := reader.NextPart()
+	require.NoError(t, err)
+	body, err := io.ReadAll(text)
+	require.NoError(t, err)
+	// Text line breaks travel as CRLF, as MIME requires
+	assert.Equal(t, "Hello Jane,\r\n\r\nYour payslip is attached.", string(body))
+
+	attachment, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "payslip-1-2025-06.pdf", attachment.FileName())
+	assert.Equal(t, "application/pdf", attachment.Header.Get("Content-Type"))
+	encoded, err := io.ReadAll(attachment)
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(encoded)))
+	require.NoError(t, err)
+	assert.Equal(t, pdf, decoded)
+
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRender_HeaderLineBreakRefused(t *testing.T) {
+	_, err := Render("payroll@example.com", Message{To: "jane@example.com\r\nBcc: all@example.com", Subject: "Payslip"}, time.Now())
+
+	assert.Error(t, err)
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	err := NewSMTPMailer(config.MailConfig{}).Send(Message{To: "jane@example.com"})
+	assert.Equal(t, ErrNotConfigured, err)
+
+	var gotAddr string
+	var gotTo []string
+	m := &smtpMailer{
+		cfg: config.MailConfig{Host: "smtp.example.com", Port: 2525, From: "payroll@example.com"},
+		send: func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+			gotAddr, gotTo = addr, to
+			return nil
+		},
+	}
+	require.NoError(t, m.Send(Message{To: "jane@example.com", Subject: "Payslip", Body: "Attached"}))
+	assert.Equal(t, "smtp.example.com:2525", gotAddr)
+	assert.Equal(t, []string{"jane@example.com"}, gotTo)
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+// APIKeyHeader carries an API key, the alternative to a bearer token for integrations that cannot log in
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyStore looks up the keys requests are authenticated with and records their use
+type APIKeyStore interface {
+	// GetActiveAPIKeyByHash returns the unrevoked key with its employee, gorm.ErrRecordNotFound for none
+	GetActiveAPIKeyByHash(keyHash string) (*model.APIKey, error)
+	TouchAPIKey(id uint, usedAt time.Time) error
+}
+
+// apiKeyScopeRoute is the part of the API a scope opens, every method or only reads
+type apiKeyScopeRoute struct {
+	prefix string
+	write  bool
+}
+
+// apiKeyScopeRoutes maps each scope to the route paths it allows, an empty prefix allowing every path
+var apiKeyScopeRoutes = map[string]apiKeyScopeRoute{
+	model.APIKeyScopeRead:            {prefix: ""},
+	model.APIKeyScopePayslipsRead:    {prefix: "/api/v1/payroll/"},
+	model.APIKeyScopeAttendanceRead:  {prefix: "/api/v1/attendance/"},
+	model.APIKeyScopeAttendanceWrite: {prefix: "/api/v1/attendance/", write: true},
+}
+
+// HashAPIKey returns the hex SHA-256 hash API keys are stored under
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate accepts either an X-API-Key header or a bearer token. A request with an API key is checked against
+// the key alone: it must be unrevoked, unexpired, of an active employee and allowed by one of its scopes, the
+// employee's current role applying on top. Otherwise the bearer token is verified like JWTAuth does.
+// Either way HeaderMiddleware reads the authenticated employee afterwards.
+func Authenticate(res response.Interface, currentVersion TokenVersionLookup, keys APIKeyStore) echo.MiddlewareFunc {
+	jwtAuth := JWTAuth(res, currentVersion)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := jwtAuth(next)
+		return func(c echo.Context) error {
+			raw := c.Request().Header.Get(APIKeyHeader)
+			if raw == "" {
+				return withJWT(c)
+			}
+
+			key, err := keys.GetActiveAPIKeyByHash(HashAPIKey(raw))
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return res.SendCustomResponseWithCode(c, http.StatusUnauthorized, response.ErrCodeAPIKeyInvalid, "Invalid API key", nil)
+			}
+			if err != nil {
+				return res.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to verify API key", err.Error())
+			}
+			now := time.Now()
+			if key.IsExpired(now) {
+				return res.SendCustomResponseWithCode(c, http.StatusUnauthorized, response.ErrCodeAPIKeyExpired, "API key has expired", nil)
+			}
+			if !key.Employee.Active {
+				return res.SendCustomResponseWithCode(c, http.StatusUnauthorized, response.ErrCodeAPIKeyInvalid, "Invalid API key", nil)
+			}
+
+			method, path := c.Request().Method, c.Path()
+			logger := LoggerFrom(c).With("api_key_id", key.ID, "employee_id", key.EmployeeID, "method", method, "path", path)
+			if !apiKeyAllows(key.ScopeList(), method, path) {
+				logger.Warn("api key refused outside its scopes", "scopes", key.Scopes)
+				return res.SendCustomResponseWithCode(c, http.StatusForbidden, response.ErrCodeAPIKeyScopeDenied, "API key does not allow this request", nil)
+			}
+
+			logger.Info("api key used")
+			if err := keys.TouchAPIKey(key.ID, now); err != nil {
+				logger.Error("failed to record api key use", "error", err)
+			}
+
+			c.Set("api_key", key)
+			return next(c)
+		}
+	}
+}
+
+// apiKeyAllows reports whether one of the scopes allows the request to the route path
+func apiKeyAllows(scopes []string, method, path string) bool {
+	read := method == http.MethodGet || method == http.MethodHead
+	return slices.ContainsFunc(scopes, func(scope string) bool {
+		route, ok := apiKeyScopeRoutes[scope]
+		return ok && (read || route.write) && strings.HasPrefix(path, route.prefix)
+	})
+}
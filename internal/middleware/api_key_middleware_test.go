@@ -0,0 +1,141 @@
+// Package middleware contains unit tests for the API key middleware.
+//
+// Authenticate tests cover:
+// 1. A valid key passing as its employee with the employee's current role, its use recorded
+// 2. An unknown key, or one of an inactive employee, answered with 401 API_KEY_INVALID
+// 3. An expired key answered with 401 API_KEY_EXPIRED
+// 4. Requests outside the key's scopes answered with 403 API_KEY_SCOPE_DENIED
+// 5. Requests without an API key verified as bearer tokens
+//
+// apiKeyAllows tests cover:
+// 1. Reads allowed anywhere by read, writes only by a write scope under its prefix, unknown scopes allowing nothing
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+// fakeAPIKeyStore serves keys by their hash and records which were used
+type fakeAPIKeyStore struct {
+	keys    map[string]*model.APIKey
+	touched []uint
+}
+
+func (s *fakeAPIKeyStore) GetActiveAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	key, ok := s.keys[keyHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return key, nil
+}
+
+func (s *fakeAPIKeyStore) TouchAPIKey(id uint, _ time.Time) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+// apiKeyTestServer serves payroll and attendance routes behind Authenticate and HeaderMiddleware, answering with
+// the authenticated employee and role. Employee 7 is a manager, employee 8 is inactive.
+func apiKeyTestServer(store *fakeAPIKeyStore) *echo.Echo {
+	expired := time.Now().Add(-time.Hour)
+	manager := model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 7}, Role: "manager", Active: true}
+	store.keys = map[string]*model.APIKey{
+		HashAPIKey("psk_payslips"):   {DefaultAttribute: model.DefaultAttribute{ID: 1}, EmployeeID: 7, Scopes: "payslips:read", Employee: manager},
+		HashAPIKey("psk_attendance"): {DefaultAttribute: model.DefaultAttribute{ID: 2}, EmployeeID: 7, Scopes: "attendance:write", Employee: manager},
+		HashAPIKey("psk_expired"):    {DefaultAttribute: model.DefaultAttribute{ID: 3}, EmployeeID: 7, Scopes: "read", ExpiresAt: &expired, Employee: manager},
+		HashAPIKey("psk_inactive"):   {DefaultAttribute: model.DefaultAttribute{ID: 4}, EmployeeID: 8, Scopes: "read", Employee: model.Employee{Role: "employee"}},
+	}
+
+	e := echo.New()
+	group := e.Group("/api/v1")
+	group.Use(Authenticate(response.NewResponse(), func(uint) (int, error) { return 0, nil }, store))
+	group.Use(HeaderMiddleware)
+	whoami := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{"user_id": c.Get("authenticated_user_id"), "role": c.Get("authenticated_role")})
+	}
+	group.GET("/payroll/payslips", whoami)
+	group.POST("/payroll/generate", whoami)
+	group.GET("/attendance/me", whoami)
+	group.POST("/attendance/check-in", whoami)
+	return e
+}
+
+// requestWithAPIKey calls the route with the key, without the header when key is empty
+func requestWithAPIKey(e *echo.Echo, method, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		req.Header.Set(APIKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthenticate_ValidKey(t *testing.T) {
+	store := &fakeAPIKeyStore{}
+	e := apiKeyTestServer(store)
+
+	rec := requestWithAPIKey(e, http.MethodGet, "/api/v1/payroll/payslips", "psk_payslips")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"user_id":7,"role":"manager"}`, rec.Body.String())
+
+	rec = requestWithAPIKey(e, http.MethodPost, "/api/v1/attendance/check-in", "psk_attendance")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []uint{1, 2}, store.touched)
+}
+
+func TestAuthenticate_InvalidKey(t *testing.T) {
+	store := &fakeAPIKeyStore{}
+	e := apiKeyTestServer(store)
+
+	for _, key := range []string{"psk_unknown", "psk_inactive"} {
+		rec := requestWithAPIKey(e, http.MethodGet, "/api/v1/payroll/payslips", key)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, key)
+		assert.Contains(t, rec.Body.String(), `"error_code":"API_KEY_INVALID"`, key)
+	}
+	assert.Empty(t, store.touched)
+}
+
+func TestAuthenticate_ExpiredKey(t *testing.T) {
+	rec := requestWithAPIKey(apiKeyTestServer(&fakeAPIKeyStore{}), http.MethodGet, "/api/v1/payroll/payslips", "psk_expired")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"API_KEY_EXPIRED"`)
+}
+
+func TestAuthenticate_ScopeDenied(t *testing.T) {
+	store := &fakeAPIKeyStore{}
+	e := apiKeyTestServer(store)
+
+	for _, tc := range []struct{ method, path, key string }{
+		{http.MethodPost, "/api/v1/payroll/generate", "psk_payslips"},
+		{http.MethodGet, "/api/v1/attendance/me", "psk_payslips"},
+		{http.MethodGet, "/api/v1/payroll/payslips", "psk_attendance"},
+	} {
+		rec := requestWithAPIKey(e, tc.method, tc.path, tc.key)
+		assert.Equal(t, http.StatusForbidden, rec.Code, tc)
+		assert.Contains(t, rec.Body.String(), `"error_code":"API_KEY_SCOPE_DENIED"`, tc)
+	}
+	assert.Empty(t, store.touched)
+}
+
+func TestAuthenticate_WithoutKeyUsesJWT(t *testing.T) {
+	rec := requestWithAPIKey(apiKeyTestServer(&fakeAPIKeyStore{}), http.MethodGet, "/api/v1/payroll/payslips", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPIKeyAllows(t *testing.T) {
+	assert.True(t, apiKeyAllows([]string{"read"}, http.MethodGet, "/api/v1/employee/:id"))
+	assert.False(t, apiKeyAllows([]string{"read"}, http.MethodPut, "/api/v1/employee/:id"))
+	assert.True(t, apiKeyAllows([]string{"payslips:read", "attendance:read"}, http.MethodGet, "/api/v1/attendance/me"))
+	assert.False(t, apiKeyAllows([]string{"unknown"}, http.MethodGet, "/api/v1/payroll/payslips"))
+	assert.False(t, apiKeyAllows(nil, http.MethodGet, "/api/v1/payroll/payslips"))
+}
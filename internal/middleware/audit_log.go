@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// auditRecord is a snapshot of the audited columns of one record
+type auditRecord struct {
+	table  string
+	id     uint
+	values map[string]interface{}
+}
+
+// auditSnapshots returns the audited columns of the record, or of every record of a slice.
+// Columns hidden from JSON, such as password hashes and timestamps, and the optimistic lock version are left out.
+func (adb *AuditableDB) auditSnapshots(value interface{}) ([]auditRecord, error) {
+	stmt := &gorm.Statement{DB: adb.DB}
+	if err := stmt.Parse(value); err != nil {
+		return nil, err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	var elems []reflect.Value
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			elems = append(elems, reflect.Indirect(rv.Index(i)))
+		}
+	} else {
+		elems = append(elems, rv)
+	}
+
+	ctx := adb.DB.Statement.Context
+	records := make([]auditRecord, 0, len(elems))
+	for _, elem := range elems {
+		record := auditRecord{table: stmt.Schema.Table, values: map[string]interface{}{}}
+		if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil {
+			if id, zero := pk.ValueOf(ctx, elem); !zero {
+				record.id = uint(reflect.ValueOf(id).Convert(reflect.TypeOf(uint(0))).Uint())
+			}
+		}
+		for _, field := range stmt.Schema.Fields {
+			if !auditedField(field) {
+				continue
+			}
+			fieldValue, _ := field.ValueOf(ctx, elem)
+			record.values[field.DBName] = fieldValue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// auditedField checks if changes to the column are recorded in the audit log
+func auditedField(field *schema.Field) bool {
+	if field.DBName == "" || field.DBName == "deleted_at" || field.DBName == "version" || field.PrimaryKey {
+		return false
+	}
+	return field.Tag.Get("json") != "-"
+}
+
+// auditChanges compares two snapshots of a record, before is nil on create and after on delete.
+// Only the columns that differ are returned.
+func auditChanges(before, after map[string]interface{}) map[string]model.AuditChange {
+	changes := map[string]model.AuditChange{}
+	for column, value := range after {
+		old, existed := before[column]
+		if existed && sameAuditValue(old, value) {
+			continue
+		}
+		change := model.AuditChange{New: value}
+		if existed {
+			change.Old = old
+		}
+		changes[column] = change
+	}
+	if after == nil {
+		for column, value := range before {
+			changes[column] = model.AuditChange{Old: value}
+		}
+	}
+	return changes
+}
+
+// sameAuditValue compares column values by their JSON form, times by the instant they describe
+func sameAuditValue(a, b interface{}) bool {
+	if at, ok := auditTime(a); ok {
+		bt, ok := auditTime(b)
+		return ok && at.Equal(bt)
+	}
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aj) == string(bj)
+}
+
+// auditTime unwraps time and time pointer values, a nil pointer is the zero time
+func auditTime(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, true
+		}
+		return *t, true
+	}
+	return time.Time{}, false
+}
+
+// storedSnapshots loads the stored version of each record, nil for records not stored yet
+func (adb *AuditableDB) storedSnapshots(value interface{}) ([]*auditRecord, error) {
+	records, err := adb.auditSnapshots(value)
+	if err != nil {
+		return nil, err
+	}
+
+	elemType := reflect.Indirect(reflect.ValueOf(value)).Type()
+	if elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Array {
+		elemType = elemType.Elem()
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+	}
+
+	stored := make([]*auditRecord, len(records))
+	for i, record := range records {
+		if record.id == 0 {
+			continue
+		}
+		current := reflect.New(elemType).Interface()
+		err := adb.DB.Session(&gorm.Session{NewDB: true}).Unscoped().First(current, record.id).Error
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		snapshots, err := adb.auditSnapshots(current)
+		if err != nil {
+			return nil, err
+		}
+		stored[i] = &snapshots[0]
+	}
+	return stored, nil
+}
+
+// matchingSnapshots returns the audited columns of the stored records of the model's table matching the conditions
+func (adb *AuditableDB) matchingSnapshots(value interface{}, conds []interface{}) ([]auditRecord, error) {
+	elemType := reflect.Indirect(reflect.ValueOf(value)).Type()
+	matches := reflect.New(reflect.SliceOf(elemType)).Interface()
+	err := adb.DB.Session(&gorm.Session{NewDB: true}).Where(conds[0], conds[1:]...).Find(matches).Error
+	if err != nil {
+		return nil, err
+	}
+	return adb.auditSnapshots(matches)
+}
+
+// writeAuditLog stores an audit log entry for a record when its audited columns changed.
+// before is nil on create and after on delete.
+func (adb *AuditableDB) writeAuditLog(action string, record auditRecord, before, after map[string]interface{}) error {
+	changes := auditChanges(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return adb.DB.Session(&gorm.Session{NewDB: true}).Create(&model.AuditLog{
+		Table:    record.table,
+		RecordID: record.id,
+		Action:   action,
+		ActorID:  adb.UserID,
+		Changes:  string(encoded),
+	}).Error
+}
+
+// auditCreate logs the creation of the records
+func (adb *AuditableDB) auditCreate(value interface{}) error {
+	records, err := adb.auditSnapshots(value)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := adb.writeAuditLog(model.AuditActionCreate, record, nil, record.values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditSave logs the records written by Save, as an update of the stored version when there was one
+func (adb *AuditableDB) auditSave(value interface{}, stored []*auditRecord) error {
+	records, err := adb.auditSnapshots(value)
+	if err != nil {
+		return err
+	}
+	for i, record := range records {
+		action, before := model.AuditActionCreate, map[string]interface{}(nil)
+		if i < len(stored) && stored[i] != nil {
+			action, before = model.AuditActionUpdate, stored[i].values
+		}
+		if err := adb.writeAuditLog(action, record, before, record.values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditUpdate logs the difference between the stored versions before and after an update
+func (adb *AuditableDB) auditUpdate(value interface{}, before []*auditRecord) error {
+	after, err := adb.storedSnapshots(value)
+	if err != nil {
+		return err
+	}
+	for i, record := range after {
+		if record == nil || before[i] == nil {
+			continue
+		}
+		if err := adb.writeAuditLog(model.AuditActionUpdate, *record, before[i].values, record.values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditDelete logs the deletion of the records
+func (adb *AuditableDB) auditDelete(records []auditRecord) error {
+	for _, record := range records {
+		if err := adb.writeAuditLog(model.AuditActionDelete, record, record.values, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
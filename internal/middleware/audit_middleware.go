@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"reflect"
 
 	"github.com/labstack/echo/v4"
@@ -82,35 +83,133 @@ func NewAuditableDB(db *gorm.DB, userID uint) *AuditableDB {
 	}
 }
 
-// Create wraps gorm Create with audit fields
+// Create wraps gorm Create with audit fields and records the new values in the audit log
 func (adb *AuditableDB) Create(value interface{}) *gorm.DB {
 	SetAuditFields(value, "create", adb.UserID)
-	return adb.DB.Create(value)
+	result := adb.DB.Create(value)
+	if result.Error == nil {
+		if err := adb.auditCreate(value); err != nil {
+			result.AddError(err)
+		}
+	}
+	return result
 }
 
 // Save wraps gorm Save with audit fields (acts as update for existing records)
+// and records the changed values in the audit log
 func (adb *AuditableDB) Save(value interface{}) *gorm.DB {
 	SetAuditFields(value, "update", adb.UserID)
-	return adb.DB.Save(value)
+	stored, err := adb.storedSnapshots(value)
+	if err != nil {
+		return adb.failed(err)
+	}
+
+	result := adb.DB.Save(value)
+	if result.Error == nil {
+		if err := adb.auditSave(value, stored); err != nil {
+			result.AddError(err)
+		}
+	}
+	return result
 }
 
-// Updates wraps gorm Updates with updated_by field
+// ErrStaleVersion is returned when updating a record that was changed since it was read
+var ErrStaleVersion = errors.New("record was changed by someone else, reload it and try again")
+
+// SaveVersioned works like Save for a model with an int Version field, but only updates the record while
+// its stored version is still the one read, bumping it. A record changed in between is left alone and
+// ErrStaleVersion returned, where Save would overwrite it.
+func (adb *AuditableDB) SaveVersioned(value interface{}) *gorm.DB {
+	version := reflect.Indirect(reflect.ValueOf(value)).FieldByName("Version")
+	if !version.IsValid() || version.Kind() != reflect.Int {
+		return adb.failed(errors.New("model has no int Version field"))
+	}
+
+	SetAuditFields(value, "update", adb.UserID)
+	stored, err := adb.storedSnapshots(value)
+	if err != nil {
+		return adb.failed(err)
+	}
+
+	read := version.Int()
+	version.SetInt(read + 1)
+	// Selecting every field updates zero values like Save, without Save's insert when no row matched
+	result := adb.DB.Model(value).Where("version = ?", read).Select("*").Updates(value)
+	if result.Error != nil {
+		version.SetInt(read)
+		return result
+	}
+	if result.RowsAffected == 0 {
+		version.SetInt(read)
+		return adb.failed(ErrStaleVersion)
+	}
+	if err := adb.auditSave(value, stored); err != nil {
+		result.AddError(err)
+	}
+	return result
+}
+
+// Updates wraps gorm Updates with updated_by field. When the model is known, the changed
+// values are recorded in the audit log.
 func (adb *AuditableDB) Updates(value interface{}) *gorm.DB {
 	// For Updates, we need to add updated_by to the update map
 	if updateMap, ok := value.(map[string]interface{}); ok {
 		updateMap["updated_by"] = adb.UserID
 	}
-	return adb.DB.Updates(value)
+
+	target := adb.DB.Statement.Model
+	var before []*auditRecord
+	if target != nil {
+		var err error
+		if before, err = adb.storedSnapshots(target); err != nil {
+			return adb.failed(err)
+		}
+	}
+
+	result := adb.DB.Updates(value)
+	if result.Error == nil && target != nil {
+		if err := adb.auditUpdate(target, before); err != nil {
+			result.AddError(err)
+		}
+	}
+	return result
 }
 
-// Delete wraps gorm Delete with deleted_by field
+// Delete wraps gorm Delete with deleted_by field and records the deleted values in the audit log
 func (adb *AuditableDB) Delete(value interface{}, conds ...interface{}) *gorm.DB {
+	var deleted []auditRecord
+	var err error
 	// Set deleted_by before soft delete
 	if len(conds) > 0 {
+		deleted, err = adb.matchingSnapshots(value, conds)
 		// Update the deleted_by field before soft delete
 		adb.DB.Model(value).Where(conds[0], conds[1:]...).Update("deleted_by", adb.UserID)
 	} else {
 		SetAuditFields(value, "delete", adb.UserID)
+		var stored []*auditRecord
+		stored, err = adb.storedSnapshots(value)
+		for _, record := range stored {
+			if record != nil {
+				deleted = append(deleted, *record)
+			}
+		}
+	}
+	if err != nil {
+		return adb.failed(err)
 	}
-	return adb.DB.Delete(value, conds...)
+
+	result := adb.DB.Delete(value, conds...)
+	if result.Error == nil {
+		if err := adb.auditDelete(deleted); err != nil {
+			result.AddError(err)
+		}
+	}
+	return result
+}
+
+// failed returns a query result holding the error, without touching the wrapped DB
+func (adb *AuditableDB) failed(err error) *gorm.DB {
+	db := adb.DB.Session(&gorm.Session{NewDB: true})
+	db.AddError(err)
+	return db
 }
@@ -1,39 +1,65 @@
 package middleware
 
 import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
 )
 
 var JWT_SECRET = []byte("super-secret-key")
 
-// HeaderMiddleware is a middleware that adds a custom header to the response.
+// HeaderMiddleware stores the authenticated employee and role in the context, read from the API key
+// Authenticate accepted or else from the bearer token
 func HeaderMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		user := c.Get("user").(*jwt.Token)
-		claims := user.Claims.(jwt.MapClaims)
+		var userID int
+		var role string
+		if key, ok := c.Get("api_key").(*model.APIKey); ok {
+			userID, role = int(key.EmployeeID), key.Employee.Role
+		} else {
+			claims := c.Get("user").(*jwt.Token).Claims.(jwt.MapClaims)
+			userID, role = int(claims["user_id"].(float64)), claims["role"].(string)
+		}
+		c.Set("user_id", userID)
+		c.Set("role", role)
 
-		c.Set("user_id", int(claims["user_id"].(float64)))
-		c.Set("role", claims["role"].(string))
+		// Authorization checks in middleware and handlers read these
+		c.Set("authenticated_user_id", uint(userID))
+		c.Set("authenticated_role", role)
 
 		return next(c)
 	}
 }
 
-// AdminOnly ensures only admin users can access the route
-func AdminOnly(response response.Interface) echo.MiddlewareFunc {
+// RequireRole allows only the given roles to access the route, e.g. RequireRole(t.Response, "admin").
+// It reads the authenticated_role set by HeaderMiddleware, a request without one is unauthenticated.
+func RequireRole(response response.Interface, roles ...string) echo.MiddlewareFunc {
+	message := fmt.Sprintf("Access denied. Requires the %s role.", strings.Join(roles, " or "))
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			role, ok := c.Get("role").(string)
-			if !ok || role != "admin" {
-				return response.SendCustomResponse(c, 403, "Access denied. Admin privileges required.", nil)
+			role, ok := c.Get("authenticated_role").(string)
+			if !ok || role == "" {
+				return response.SendUnauthorized(c, "Authentication required", nil)
+			}
+			if !slices.Contains(roles, role) {
+				return response.SendCustomResponse(c, http.StatusForbidden, message, nil)
 			}
 			return next(c)
 		}
 	}
 }
 
+// AdminOnly ensures only admin users can access the route, kept for backward compatibility, use RequireRole
+func AdminOnly(response response.Interface) echo.MiddlewareFunc {
+	return RequireRole(response, "admin")
+}
+
 // IsAdmin is a legacy function, kept for backward compatibility
 func IsAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -55,7 +81,7 @@ func EmployeeOrAdmin(response response.Interface) echo.MiddlewareFunc {
 				return response.SendUnauthorized(c, "Authentication required", nil)
 			}
 
-			if role != "employee" && role != "admin" {
+			if role != "employee" && role != "manager" && role != "admin" {
 				return response.SendCustomResponse(c, 403, "Access denied. Employee or admin privileges required.", nil)
 			}
 
@@ -70,3 +96,29 @@ func EmployeeOrAdmin(response response.Interface) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// ManagerOrAdmin allows managers and admins to access the route
+// Handlers are expected to enforce the approval tier of the request
+func ManagerOrAdmin(response response.Interface) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, ok := c.Get("role").(string)
+			if !ok {
+				return response.SendUnauthorized(c, "Authentication required", nil)
+			}
+
+			if role != "manager" && role != "admin" {
+				return response.SendCustomResponse(c, 403, "Access denied. Manager or admin privileges required.", nil)
+			}
+
+			// Set additional context for authorization checks in handlers
+			userID, ok := c.Get("user_id").(int)
+			if ok {
+				c.Set("authenticated_user_id", uint(userID))
+			}
+			c.Set("authenticated_role", role)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package middleware contains unit tests for the role middleware.
+//
+// RequireRole tests cover:
+// 1. An employee token on an admin route denied with 403 while an admin token passes
+// 2. Any of several allowed roles passing
+// 3. A request without an authenticated role answered with 401
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/helper/response"
+)
+
+// roleTestServer serves GET /admin behind the JWT, header and RequireRole middleware like the routes do
+func roleTestServer(roles ...string) *echo.Echo {
+	e := echo.New()
+	group := e.Group("")
+	group.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey:  JWT_SECRET,
+		TokenLookup: "header:Authorization:Bearer ",
+	}))
+	group.Use(HeaderMiddleware)
+	group.Use(RequireRole(response.NewResponse(), roles...))
+	group.GET("/admin", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return e
+}
+
+// getWithRole calls GET /admin with a token issued for the role
+func getWithRole(t *testing.T, e *echo.Echo, role string) *httptest.ResponseRecorder {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 7,
+		"role":    role,
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}).SignedString(JWT_SECRET)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequireRole_AdminRoute(t *testing.T) {
+	e := roleTestServer("admin")
+
+	rec := getWithRole(t, e, "employee")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Access denied. Requires the admin role.")
+
+	rec = getWithRole(t, e, "admin")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+}
+
+func TestRequireRole_AnyAllowedRole(t *testing.T) {
+	e := roleTestServer("manager", "admin")
+
+	assert.Equal(t, http.StatusOK, getWithRole(t, e, "manager").Code)
+	assert.Equal(t, http.StatusOK, getWithRole(t, e, "admin").Code)
+	assert.Equal(t, http.StatusForbidden, getWithRole(t, e, "employee").Code)
+}
+
+func TestRequireRole_Unauthenticated(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin", nil), rec)
+
+	handler := RequireRole(response.NewResponse(), "admin")(func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	require.NoError(t, handler(c))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"github.com/yourname/payslip-system/internal/model"
+)
+
+// IdempotencyKeyHeader is the request header holding the client's idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyStore keeps the responses of requests sent with an idempotency key
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(record *model.IdempotencyRecord) (*model.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(record *model.IdempotencyRecord, statusCode int, contentType string, body []byte) error
+	ReleaseIdempotencyKey(record *model.IdempotencyRecord) error
+}
+
+// idempotencyRetryAfterSeconds is suggested to clients whose key is still in flight
+const idempotencyRetryAfterSeconds = 5
+
+// Idempotency replays the stored response when a request is retried with the same Idempotency-Key
+// header, per user, method and path, instead of running the handler again. A retry while the first
+// request is still in flight gets 409. Failed requests (5xx) are not stored so they can be retried.
+// Requests without the header run as usual.
+func Idempotency(store IdempotencyStore, ttl time.Duration, response response.Interface) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > 255 {
+				return response.SendBadRequest(c, "Idempotency-Key must be at most 255 characters", nil)
+			}
+
+			userID, _ := c.Get("user_id").(int)
+			record, reserved, err := store.ReserveIdempotencyKey(&model.IdempotencyRecord{
+				Key:       key,
+				ActorID:   uint(userID),
+				Method:    c.Request().Method,
+				Path:      c.Request().URL.Path,
+				ExpiresAt: time.Now().Add(ttl),
+			})
+			if err != nil {
+				return response.SendError(c, "Failed to check the Idempotency-Key", err.Error())
+			}
+
+			if !reserved {
+				if !record.Completed {
+					c.Response().Header().Set("Retry-After", strconv.Itoa(idempotencyRetryAfterSeconds))
+					return response.SendCustomResponse(c, http.StatusConflict, "A request with this Idempotency-Key is still in progress, retry later", nil)
+				}
+				c.Response().Header().Set("Idempotent-Replayed", "true")
+				return c.Blob(record.StatusCode, record.ContentType, []byte(record.Body))
+			}
+
+			// Keep a copy of the response written by the handler
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			err = next(c)
+			if err != nil {
+				// Let the error handler write the response, then allow a retry
+				c.Error(err)
+				err = nil
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				if releaseErr := store.ReleaseIdempotencyKey(record); releaseErr != nil {
+					c.Logger().Errorf("failed to release idempotency key: %v", releaseErr)
+				}
+				return err
+			}
+			if completeErr := store.CompleteIdempotencyKey(record, status, c.Response().Header().Get(echo.HeaderContentType), recorder.body.Bytes()); completeErr != nil {
+				// Without a stored response the key would stay in flight until it expires
+				c.Logger().Errorf("failed to store idempotent response: %v", completeErr)
+				if releaseErr := store.ReleaseIdempotencyKey(record); releaseErr != nil {
+					c.Logger().Errorf("failed to release idempotency key: %v", releaseErr)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// responseRecorder copies everything written to the response
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"gorm.io/gorm"
+)
+
+// ErrTokenRevoked is returned for a token issued before the employee's current token version,
+// e.g. before a password or role change, or for an employee that no longer exists
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// errTokenVersionUnavailable wraps a failure to read the current token version, which says nothing about the token
+var errTokenVersionUnavailable = errors.New("failed to check token version")
+
+// TokenVersionLookup returns the current token version of an employee, gorm.ErrRecordNotFound for a missing one
+type TokenVersionLookup func(employeeID uint) (int, error)
+
+// JWTAuth verifies the bearer token of the request and stores it under "user" for HeaderMiddleware.
+// Tokens must carry exp, an expired one is answered with 401 TOKEN_EXPIRED so clients know to refresh it.
+// A token whose token_version claim is below the employee's current version is answered with 401 TOKEN_REVOKED,
+// tokens issued without the claim count as version 0.
+func JWTAuth(res response.Interface, currentVersion TokenVersionLookup) echo.MiddlewareFunc {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+
+	return echojwt.WithConfig(echojwt.Config{
+		TokenLookup: "header:Authorization:Bearer ",
+		ParseTokenFunc: func(c echo.Context, auth string) (interface{}, error) {
+			token, err := parser.Parse(auth, func(*jwt.Token) (interface{}, error) {
+				return JWT_SECRET, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := checkTokenVersion(token.Claims.(jwt.MapClaims), currentVersion); err != nil {
+				return nil, err
+			}
+			return token, nil
+		},
+		ErrorHandler: func(c echo.Context, err error) error {
+			switch {
+			case errors.Is(err, jwt.ErrTokenExpired):
+				return res.SendCustomResponseWithCode(c, http.StatusUnauthorized, response.ErrCodeTokenExpired, "Token has expired", nil)
+			case errors.Is(err, ErrTokenRevoked):
+				return res.SendCustomResponseWithCode(c, http.StatusUnauthorized, response.ErrCodeTokenRevoked, "Token has been revoked, please log in again", nil)
+			case errors.Is(err, errTokenVersionUnavailable):
+				return res.SendErrorWithCode(c, response.ErrCodeInternal, "Failed to verify token", err.Error())
+			}
+
+			// Same answers as the default echojwt error handling
+			var extractionErr *echojwt.TokenExtractionError
+			if errors.As(err, &extractionErr) {
+				return echo.NewHTTPError(http.StatusBadRequest, "missing or malformed jwt").SetInternal(err)
+			}
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired jwt").SetInternal(err)
+		},
+	})
+}
+
+// checkTokenVersion compares the token_version claim of a token with the current version of its employee
+func checkTokenVersion(claims jwt.MapClaims, currentVersion TokenVersionLookup) error {
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return errors.New("token has no user_id claim")
+	}
+	tokenVersion, _ := claims["token_version"].(float64)
+
+	version, err := currentVersion(uint(userID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTokenRevoked
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errTokenVersionUnavailable, err)
+	}
+	if int(tokenVersion) < version {
+		return ErrTokenRevoked
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package middleware contains unit tests for the JWT middleware.
+//
+// JWTAuth tests cover:
+// 1. A current token passing, tokens without a token_version claim counting as version 0
+// 2. An expired token answered with 401 TOKEN_EXPIRED, a token without exp refused
+// 3. A token below the employee's current version, or of a missing employee, answered with 401 TOKEN_REVOKED
+// 4. A missing token answered with 400 as before
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/helper/response"
+	"gorm.io/gorm"
+)
+
+// jwtTestServer serves GET /me behind JWTAuth and HeaderMiddleware, employee 7 being at token version 2
+func jwtTestServer() *echo.Echo {
+	versions := map[uint]int{7: 2, 8: 0}
+	lookup := func(employeeID uint) (int, error) {
+		version, ok := versions[employeeID]
+		if !ok {
+			return 0, gorm.ErrRecordNotFound
+		}
+		return version, nil
+	}
+
+	e := echo.New()
+	group := e.Group("")
+	group.Use(JWTAuth(response.NewResponse(), lookup))
+	group.Use(HeaderMiddleware)
+	group.GET("/me", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return e
+}
+
+// getWithClaims calls GET /me with a token signed over the claims, without a token when claims is nil
+func getWithClaims(t *testing.T, e *echo.Echo, claims jwt.MapClaims) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	if claims != nil {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(JWT_SECRET)
+		require.NoError(t, err)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestJWTAuth_CurrentToken(t *testing.T) {
+	e := jwtTestServer()
+	exp := time.Now().Add(time.Minute).Unix()
+
+	rec := getWithClaims(t, e, jwt.MapClaims{"user_id": 7, "role": "employee", "exp": exp, "token_version": 2})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// Tokens issued before versions existed are version 0
+	rec = getWithClaims(t, e, jwt.MapClaims{"user_id": 8, "role": "employee", "exp": exp})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestJWTAuth_Expired(t *testing.T) {
+	e := jwtTestServer()
+
+	rec := getWithClaims(t, e, jwt.MapClaims{"user_id": 7, "role": "employee", "exp": time.Now().Add(-time.Minute).Unix(), "token_version": 2})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"TOKEN_EXPIRED"`)
+
+	// exp is required
+	rec = getWithClaims(t, e, jwt.MapClaims{"user_id": 7, "role": "employee", "token_version": 2})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "TOKEN_EXPIRED")
+}
+
+func TestJWTAuth_VersionMismatch(t *testing.T) {
+	e := jwtTestServer()
+	exp := time.Now().Add(time.Minute).Unix()
+
+	rec := getWithClaims(t, e, jwt.MapClaims{"user_id": 7, "role": "employee", "exp": exp, "token_version": 1})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"TOKEN_REVOKED"`)
+
+	rec = getWithClaims(t, e, jwt.MapClaims{"user_id": 42, "role": "employee", "exp": exp})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"TOKEN_REVOKED"`)
+}
+
+func TestJWTAuth_MissingToken(t *testing.T) {
+	rec := getWithClaims(t, jwtTestServer(), nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCheckTokenVersion_LookupFailure(t *testing.T) {
+	err := checkTokenVersion(jwt.MapClaims{"user_id": float64(7)}, func(uint) (int, error) {
+		return 0, errors.New("connection refused")
+	})
+	assert.ErrorIs(t, err, errTokenVersionUnavailable)
+	assert.False(t, errors.Is(err, ErrTokenRevoked))
+}
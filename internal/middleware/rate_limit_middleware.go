@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/helper/response"
+)
+
+// RateLimiter is an in-memory token bucket per key: each key may send up to requests requests at once,
+// refilled evenly over the window. Buckets left alone long enough to refill completely are evicted, they
+// would start over full anyway, so keys that stop sending requests do not hold memory.
+type RateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	capacity  float64
+	perSecond float64
+	idleAfter time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// tokenBucket holds the tokens of one key as of its last update
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter allows requests requests per window and key, nil when requests is 0 (no limit)
+func NewRateLimiter(requests int, window time.Duration) *RateLimiter {
+	if requests <= 0 || window <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		capacity:  float64(requests),
+		perSecond: float64(requests) / window.Seconds(),
+		idleAfter: window,
+		now:       time.Now,
+	}
+}
+
+// Allow takes a token for the key. When none is left it returns false with the wait until the next one.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.evictIdle(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.perSecond)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// Len returns the number of keys with a bucket
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// evictIdle drops, at most once per window, the buckets that have refilled completely since their last use
+func (l *RateLimiter) evictIdle(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleAfter {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updated) >= l.idleAfter {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitKey returns the key a request is limited by, an empty key is not limited
+type RateLimitKey func(c echo.Context) string
+
+// RateLimitByIP limits the requests of one client IP address
+func RateLimitByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// maxRateLimitedBodyBytes is the largest body RateLimitByJSONField reads, the auth requests it keys are far smaller
+const maxRateLimitedBodyBytes = 64 << 10
+
+// RateLimitByJSONField limits the requests naming the same value in a field of the JSON body, e.g. the
+// login name. The body is left in place for the handler, a body over maxRateLimitedBodyBytes is not keyed
+// and reaches the handler cut off.
+func RateLimitByJSONField(field string) RateLimitKey {
+	return func(c echo.Context) string {
+		if c.Request().Body == nil {
+			return ""
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxRateLimitedBodyBytes))
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return ""
+		}
+
+		var fields map[string]interface{}
+		if json.Unmarshal(body, &fields) != nil {
+			return ""
+		}
+		value, _ := fields[field].(string)
+		if value == "" {
+			return ""
+		}
+		return field + ":" + strings.ToLower(value)
+	}
+}
+
+// RateLimit answers 429 RATE_LIMITED with a Retry-After header, in seconds, once any key of the request has
+// used up its requests. Keys are counted per route. A nil limiter lets every request through.
+func RateLimit(limiter *RateLimiter, res response.Interface, keys ...RateLimitKey) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if limiter == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			for _, key := range keys {
+				value := key(c)
+				if value == "" {
+					continue
+				}
+				if allowed, wait := limiter.Allow(c.Path() + " " + value); !allowed {
+					c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+					return res.SendCustomResponseWithCode(c, http.StatusTooManyRequests, response.ErrCodeRateLimited, "Too many requests, retry later", nil)
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package middleware contains unit tests for the rate limit middleware.
+//
+// RateLimiter tests cover:
+// 1. A burst of the allowed requests, the next refused with the wait until a token is refilled
+// 2. Buckets idle for a whole window evicted, active ones kept
+//
+// RateLimit tests cover:
+// 1. 429 RATE_LIMITED with Retry-After once a key is used up, the JSON body still readable by the handler
+// 2. A nil limiter letting every request through
+// 3. A body over the read limit not keyed by its JSON field, so it is never read whole
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/helper/response"
+)
+
+// fakeClock is a settable time source for a limiter
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestRateLimiter_RefillsOverWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, time.June, 1, 9, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = clock.Now
+
+	allowed, _ := limiter.Allow("ip:1")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("ip:1")
+	assert.True(t, allowed)
+	allowed, wait := limiter.Allow("ip:1")
+	assert.False(t, allowed)
+	assert.Equal(t, 30*time.Second, wait)
+
+	// Other keys have their own bucket
+	allowed, _ = limiter.Allow("ip:2")
+	assert.True(t, allowed)
+
+	clock.now = clock.now.Add(30 * time.Second)
+	allowed, _ = limiter.Allow("ip:1")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("ip:1")
+	assert.False(t, allowed)
+}
+
+func TestRateLimiter_EvictsIdleBuckets(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, time.June, 1, 9, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = clock.Now
+
+	for i := 0; i < 100; i++ {
+		limiter.Allow(fmt.Sprintf("ip:%d", i))
+	}
+	assert.Equal(t, 100, limiter.Len())
+
+	clock.now = clock.now.Add(45 * time.Second)
+	limiter.Allow("ip:active")
+	clock.now = clock.now.Add(30 * time.Second)
+	limiter.Allow("ip:new")
+
+	// Only the buckets used within the last window are left
+	assert.Equal(t, 2, limiter.Len())
+}
+
+func TestRateLimit_Middleware(t *testing.T) {
+	e := echo.New()
+	limited := RateLimit(NewRateLimiter(1, time.Minute), response.NewResponse(), RateLimitByIP, RateLimitByJSONField("name"))
+	handler := limited(func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		require.NoError(t, err)
+		return c.String(http.StatusOK, string(body))
+	})
+
+	post := func(name string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"name":"`+name+`"}`))
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler(e.NewContext(req, rec)))
+		return rec
+	}
+
+	rec := post("Jane")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"name":"Jane"}`, rec.Body.String())
+
+	rec = post("Jane")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error_code":"RATE_LIMITED"`)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(0, time.Minute))
+
+	e := echo.New()
+	handler := RateLimit(nil, response.NewResponse(), RateLimitByIP)(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler(e.NewContext(httptest.NewRequest(http.MethodPost, "/login", nil), rec)))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+}
+
+func TestRateLimitByJSONField_OversizedBody(t *testing.T) {
+	e := echo.New()
+	body := `{"name":"Jane","padding":"` + strings.Repeat("x", maxRateLimitedBodyBytes) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	assert.Empty(t, RateLimitByJSONField("name")(c))
+	read, err := io.ReadAll(c.Request().Body)
+	require.NoError(t, err)
+	assert.Len(t, read, maxRateLimitedBodyBytes)
+
+	c = e.NewContext(httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"name":"Jane"}`)), httptest.NewRecorder())
+	assert.Equal(t, "name:jane", RateLimitByJSONField("name")(c))
+}
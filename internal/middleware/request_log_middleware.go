@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// maxRequestIDLength is the longest X-Request-ID accepted from a client or proxy, longer ones are replaced
+const maxRequestIDLength = 128
+
+// RequestID gives every request an ID, stored under "request_id" and returned in the X-Request-ID header.
+// An X-Request-ID sent by a client or proxy is kept so a request can be traced across services,
+// otherwise a new UUID is generated.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+			if requestID == "" || len(requestID) > maxRequestIDLength {
+				requestID = uuid.NewString()
+			}
+			c.Set("request_id", requestID)
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			return next(c)
+		}
+	}
+}
+
+// RequestLogger logs every request once it is answered, with its request ID, route, status, latency and the
+// authenticated employee. Handlers log through LoggerFrom so their entries carry the same request ID.
+// Runs after RequestID.
+func RequestLogger(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			requestLogger := logger.With("request_id", c.Get("request_id"))
+			c.Set("logger", requestLogger)
+
+			// Answer errors here so the logged status is the one sent
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			attrs := []any{
+				"method", c.Request().Method,
+				"route", c.Path(),
+				"uri", c.Request().RequestURI,
+				"status", c.Response().Status,
+				"latency_ms", time.Since(start).Milliseconds(),
+				"remote_ip", c.RealIP(),
+			}
+			if employeeID, ok := c.Get("authenticated_user_id").(uint); ok {
+				attrs = append(attrs, "employee_id", employeeID)
+			}
+			level := slog.LevelInfo
+			if err != nil {
+				level = slog.LevelError
+				attrs = append(attrs, "error", err.Error())
+			} else if c.Response().Status >= 500 {
+				level = slog.LevelError
+			}
+			requestLogger.Log(c.Request().Context(), level, "request", attrs...)
+			return nil
+		}
+	}
+}
+
+// LoggerFrom returns the logger of the request carrying its request ID, the default logger outside of
+// RequestLogger
+func LoggerFrom(c echo.Context) *slog.Logger {
+	if logger, ok := c.Get("logger").(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,116 @@
+// Package middleware contains unit tests for the request ID and request logging middleware.
+//
+// RequestID tests cover:
+// 1. A UUID generated and returned in X-Request-ID, a client's ID kept and an oversized one replaced
+//
+// RequestLogger tests cover:
+// 1. One JSON entry per request with its request ID, route, status, latency and authenticated employee
+// 2. Handler entries logged through LoggerFrom carrying the same request ID
+// 3. A returned error answered and logged with the status sent
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestLogServer serves GET /items/:id as employee 7 behind RequestID and RequestLogger, logging JSON to buf
+func requestLogServer(buf *bytes.Buffer) *echo.Echo {
+	e := echo.New()
+	e.Use(RequestID())
+	e.Use(RequestLogger(slog.New(slog.NewJSONHandler(buf, nil))))
+	e.GET("/items/:id", func(c echo.Context) error {
+		c.Set("authenticated_user_id", uint(7))
+		LoggerFrom(c).Info("item loaded", "item_id", c.Param("id"))
+		return c.String(http.StatusOK, "ok")
+	})
+	e.GET("/broken", func(c echo.Context) error {
+		return errors.New("database unavailable")
+	})
+	return e
+}
+
+// logEntries decodes the JSON log lines
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRequestID(t *testing.T) {
+	e := requestLogServer(&bytes.Buffer{})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	_, err := uuid.Parse(rec.Header().Get(echo.HeaderXRequestID))
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set(echo.HeaderXRequestID, "gateway-123")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, "gateway-123", rec.Header().Get(echo.HeaderXRequestID))
+
+	req = httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set(echo.HeaderXRequestID, strings.Repeat("x", maxRequestIDLength+1))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	_, err = uuid.Parse(rec.Header().Get(echo.HeaderXRequestID))
+	assert.NoError(t, err)
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	e := requestLogServer(&buf)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/42", nil))
+	requestID := rec.Header().Get(echo.HeaderXRequestID)
+
+	entries := logEntries(t, &buf)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "item loaded", entries[0]["msg"])
+	assert.Equal(t, requestID, entries[0]["request_id"])
+	assert.Equal(t, "42", entries[0]["item_id"])
+
+	assert.Equal(t, "request", entries[1]["msg"])
+	assert.Equal(t, "INFO", entries[1]["level"])
+	assert.Equal(t, requestID, entries[1]["request_id"])
+	assert.Equal(t, "/items/:id", entries[1]["route"])
+	assert.Equal(t, "/items/42", entries[1]["uri"])
+	assert.Equal(t, float64(http.StatusOK), entries[1]["status"])
+	assert.Equal(t, float64(7), entries[1]["employee_id"])
+	assert.Contains(t, entries[1], "latency_ms")
+}
+
+func TestRequestLogger_Error(t *testing.T) {
+	var buf bytes.Buffer
+	e := requestLogServer(&buf)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	entries := logEntries(t, &buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ERROR", entries[0]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), entries[0]["status"])
+	assert.Equal(t, "database unavailable", entries[0]["error"])
+	assert.NotContains(t, entries[0], "employee_id")
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Scopes an API key can be limited to
+const (
+	// APIKeyScopeRead allows any GET request the employee's role allows
+	APIKeyScopeRead = "read"
+	// APIKeyScopePayslipsRead allows GET requests under /payroll, e.g. payslips and their PDFs
+	APIKeyScopePayslipsRead = "payslips:read"
+	// APIKeyScopeAttendanceRead allows GET requests under /attendance
+	APIKeyScopeAttendanceRead = "attendance:read"
+	// APIKeyScopeAttendanceWrite allows any request under /attendance, e.g. check-ins from a badge reader
+	APIKeyScopeAttendanceWrite = "attendance:write"
+)
+
+// APIKeyScopes lists every scope an API key can be created with
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopePayslipsRead, APIKeyScopeAttendanceRead, APIKeyScopeAttendanceWrite}
+
+// APIKey represents a key integrations authenticate with instead of logging in, acting as its employee
+// within its scopes. Only the SHA-256 hash of the key is stored, the prefix tells keys apart.
+type APIKey struct {
+	DefaultAttribute
+	EmployeeID uint       `json:"employee_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null;size:100"`
+	Prefix     string     `json:"prefix" gorm:"not null;size:12"`
+	KeyHash    string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	Scopes     string     `json:"-" gorm:"not null;size:255"` // Comma separated
+	ExpiresAt  *time.Time `json:"expires_at" gorm:"default:null"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"default:null"`
+	LastUsedAt *time.Time `json:"last_used_at" gorm:"default:null"`
+
+	// Relationship
+	Employee Employee `json:"employee,omitempty" gorm:"foreignKey:EmployeeID"`
+}
+
+// TableName returns the table name for the APIKey model.
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the scopes of the key
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// IsRevoked checks if the key was revoked
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IsExpired checks if the key expired at the given time, keys without an expiry never do
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
@@ -1,9 +1,20 @@
 package model
 
 import (
+	"math"
 	"time"
 )
 
+// Attendance status values
+const (
+	AttendanceStatusPresent = "present"
+	AttendanceStatusHalfDay = "half_day"
+	AttendanceStatusLate    = "late"
+	AttendanceStatusAbsent  = "absent"
+	AttendanceStatusLeave   = "leave"
+	AttendanceStatusHoliday = "holiday"
+)
+
 // Attendance represents an attendance record for an employee.
 type Attendance struct {
 	DefaultAttribute
@@ -11,8 +22,11 @@ type Attendance struct {
 	Checkin     time.Time  `json:"checkin" gorm:"not null" validate:"required"`
 	Checkout    *time.Time `json:"checkout" gorm:"default:null"`
 	HoursWorked int        `json:"hours_worked" gorm:"not null;default:0" validate:"min=0,max=24"`
-	Status      string     `json:"status" gorm:"not null;size:20;check:status IN ('present','absent','leave','holiday')" validate:"required,oneof=present absent leave holiday"`
+	Status      string     `json:"status" gorm:"not null;size:20;check:status IN ('present','half_day','late','absent','leave','holiday')" validate:"required,oneof=present half_day late absent leave holiday"`
 	Date        time.Time  `json:"date" gorm:"not null;type:date;index" validate:"required"`
+	// Where the employee checked in from, when the client sent it
+	Latitude  *float64 `json:"latitude,omitempty" gorm:"default:null"`
+	Longitude *float64 `json:"longitude,omitempty" gorm:"default:null"`
 
 	// Relationship
 	Employee Employee `json:"employee,omitempty" gorm:"foreignKey:EmployeeID"`
@@ -23,19 +37,35 @@ func (Attendance) TableName() string {
 	return "attendances"
 }
 
-// CalculateHours calculates hours worked between checkin and checkout
+// CalculateHours calculates hours worked between checkin and checkout, rounded to the nearest hour
 func (a *Attendance) CalculateHours() {
 	if a.Checkout != nil && a.Checkout.After(a.Checkin) {
 		duration := a.Checkout.Sub(a.Checkin)
-		a.HoursWorked = int(duration.Hours())
+		a.HoursWorked = int(math.Round(duration.Hours()))
 	} else {
 		a.HoursWorked = 0
 	}
 }
 
-// IsPresent checks if the employee was present on this day
+// IsPresent checks if the employee was present on this day, late arrivals and half days included
 func (a *Attendance) IsPresent() bool {
-	return a.Status == "present"
+	switch a.Status {
+	case AttendanceStatusPresent, AttendanceStatusLate, AttendanceStatusHalfDay:
+		return true
+	}
+	return false
+}
+
+// PayWeight returns the share of a working day the record counts for in payroll. A half day
+// counts for half whatever its check-in and checkout span, an absence for nothing.
+func (a *Attendance) PayWeight() float64 {
+	switch a.Status {
+	case AttendanceStatusHalfDay:
+		return 0.5
+	case AttendanceStatusAbsent:
+		return 0
+	}
+	return 1
 }
 
 // IsComplete checks if attendance record is complete (has checkout)
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Audit log actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditLog records a change made through the auditable write path. Entries are never updated or deleted.
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	Table     string    `json:"table_name" gorm:"column:table_name;not null;size:100;index"`
+	RecordID  uint      `json:"record_id" gorm:"not null;index"`
+	Action    string    `json:"action" gorm:"not null;size:20;index;check:action IN ('create','update','delete')"`
+	ActorID   uint      `json:"actor_id" gorm:"not null;index"` // 0 for changes without an authenticated user
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+	Changes   string    `json:"-" gorm:"type:text"` // JSON object of column to AuditChange
+}
+
+// TableName returns the table name for the AuditLog model.
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditChange is the old and new value of a column, old is left out on create and new on delete
+type AuditChange struct {
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
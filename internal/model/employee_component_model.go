@@ -0,0 +1,29 @@
+package model
+
+// ComponentKind represents whether a salary component adds to or subtracts from pay
+type ComponentKind string
+
+const (
+	ComponentAllowance ComponentKind = "allowance"
+	ComponentDeduction ComponentKind = "deduction"
+)
+
+// EmployeeComponent represents a recurring allowance or deduction paid every period.
+type EmployeeComponent struct {
+	DefaultAttribute
+	EmployeeID uint          `json:"employee_id" gorm:"not null;index" validate:"required"`
+	Name       string        `json:"name" gorm:"not null;size:100" validate:"required"`
+	Kind       ComponentKind `json:"kind" gorm:"not null;size:20" validate:"required,oneof=allowance deduction"`
+	Amount     float64       `json:"amount" gorm:"not null;type:decimal(12,2)" validate:"min=0"`
+	Active     bool          `json:"active" gorm:"default:true"`
+}
+
+// TableName returns the table name for the EmployeeComponent model.
+func (EmployeeComponent) TableName() string {
+	return "employee_components"
+}
+
+// IsDeduction checks if the component is subtracted from pay
+func (ec *EmployeeComponent) IsDeduction() bool {
+	return ec.Kind == ComponentDeduction
+}
@@ -7,14 +7,37 @@ type Employee struct {
 	DefaultAttribute
 	Name     string `json:"name" gorm:"not null;size:255" validate:"required,min=2,max=255"`
 	Password string `json:"-" gorm:"not null;size:255"` // Excluded from JSON for security
-	Role     string `json:"role" gorm:"not null;size:50;check:role IN ('admin','employee')" validate:"required,oneof=admin employee"`
+	Role     string `json:"role" gorm:"not null;size:50;check:role IN ('admin','manager','employee')" validate:"required,oneof=admin manager employee"`
 	Active   bool   `json:"active" gorm:"default:true"`
+	Email    string `json:"email" gorm:"size:255"` // Where payslips are emailed, empty when unknown
+
+	// Bumped on a password or role change, access tokens issued with a lower version are refused
+	TokenVersion int `json:"-" gorm:"not null;default:0"`
+	// Bumped by every update, an update made against an older version is refused
+	Version int `json:"version" gorm:"not null;default:0"`
+
+	// Reporting line, nil for employees without a manager
+	ManagerID  *uint  `json:"manager_id" gorm:"index;default:null"`
+	Department string `json:"department" gorm:"size:100;index"` // Empty when unassigned
+
+	// Salary setup, defaulted from the role template at creation
+	StandardHours    int   `json:"standard_hours" gorm:"default:8"`
+	OvertimeEligible *bool `json:"overtime_eligible" gorm:"default:null"` // nil means eligible (legacy records)
+
+	// Contract setup used for forecasting
+	BasicSalary     float64    `json:"basic_salary" gorm:"default:0"`
+	PayGradeID      *uint      `json:"pay_grade_id" gorm:"index;default:null"` // Band the basic salary must stay within
+	Currency        string     `json:"currency" gorm:"size:3"`                 // ISO 4217 code of the salary
+	HireDate        *time.Time `json:"hire_date"`
+	TerminationDate *time.Time `json:"termination_date"`
 
 	// Relationships
-	Attendances    []Attendance    `json:"attendances,omitempty" gorm:"foreignKey:EmployeeID"`
-	Overtimes      []Overtime      `json:"overtimes,omitempty" gorm:"foreignKey:EmployeeID"`
-	Reimbursements []Reimbursement `json:"reimbursements,omitempty" gorm:"foreignKey:EmployeeID"`
-	Payslips       []Payslip       `json:"payslips,omitempty" gorm:"foreignKey:EmployeeID"`
+	PayGrade       *PayGrade           `json:"pay_grade,omitempty" gorm:"foreignKey:PayGradeID"`
+	Components     []EmployeeComponent `json:"components,omitempty" gorm:"foreignKey:EmployeeID"`
+	Attendances    []Attendance        `json:"attendances,omitempty" gorm:"foreignKey:EmployeeID"`
+	Overtimes      []Overtime          `json:"overtimes,omitempty" gorm:"foreignKey:EmployeeID"`
+	Reimbursements []Reimbursement     `json:"reimbursements,omitempty" gorm:"foreignKey:EmployeeID"`
+	Payslips       []Payslip           `json:"payslips,omitempty" gorm:"foreignKey:EmployeeID"`
 }
 
 // TableName returns the table name for the Employee model.
@@ -22,24 +45,63 @@ func (Employee) TableName() string {
 	return "employees"
 }
 
+// IsOvertimeEligible checks if overtime should be paid to the employee
+func (e *Employee) IsOvertimeEligible() bool {
+	return e.OvertimeEligible == nil || *e.OvertimeEligible
+}
+
+// HasEmail checks if payslips can be emailed to the employee
+func (e *Employee) HasEmail() bool {
+	return e.Email != ""
+}
+
+// IsEmployedBetween checks if the employee is employed at any point in the given range
+func (e *Employee) IsEmployedBetween(start, end time.Time) bool {
+	if e.HireDate != nil && e.HireDate.After(end) {
+		return false
+	}
+	if e.TerminationDate != nil && e.TerminationDate.Before(start) {
+		return false
+	}
+	return true
+}
+
+// JoinDate returns the hire date, or the record creation date for employees without one
+func (e *Employee) JoinDate() *time.Time {
+	if e.HireDate != nil {
+		return e.HireDate
+	}
+	return e.CreatedAt
+}
+
 // SafeEmployee returns employee data without sensitive information
 type SafeEmployee struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Role      string    `json:"role"`
-	Active    bool      `json:"active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	Role       string    `json:"role"`
+	Active     bool      `json:"active"`
+	Email      string    `json:"email"`
+	PayGradeID *uint     `json:"pay_grade_id"`
+	PayGrade   *PayGrade `json:"pay_grade"`
+	ManagerID  *uint     `json:"manager_id"`
+	Version    int       `json:"version"` // Sent back with an update to detect concurrent edits
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
 // ToSafe converts Employee to SafeEmployee
 func (e *Employee) ToSafe() SafeEmployee {
 	return SafeEmployee{
-		ID:        e.ID,
-		Name:      e.Name,
-		Role:      e.Role,
-		Active:    e.Active,
-		CreatedAt: *e.CreatedAt,
-		UpdatedAt: *e.UpdatedAt,
+		ID:         e.ID,
+		Name:       e.Name,
+		Role:       e.Role,
+		Active:     e.Active,
+		Email:      e.Email,
+		PayGradeID: e.PayGradeID,
+		PayGrade:   e.PayGrade,
+		ManagerID:  e.ManagerID,
+		Version:    e.Version,
+		CreatedAt:  *e.CreatedAt,
+		UpdatedAt:  *e.UpdatedAt,
 	}
 }
@@ -0,0 +1,13 @@
+package model
+
+// Holiday is a public holiday, overtime worked on it is paid at the holiday multiplier.
+type Holiday struct {
+	DefaultAttribute
+	Date string `json:"date" gorm:"not null;size:10;uniqueIndex" validate:"required,datetime=2006-01-02"` // YYYY-MM-DD, matches Overtime.OvertimeDate
+	Name string `json:"name" gorm:"not null;size:100" validate:"required"`
+}
+
+// TableName returns the table name for the Holiday model.
+func (Holiday) TableName() string {
+	return "holidays"
+}
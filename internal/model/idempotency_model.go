@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// IdempotencyRecord stores the response of a request sent with an Idempotency-Key, so a retry with the same
+// key gets the stored response instead of running the request again. A record that is not completed
+// belongs to a request still in flight.
+type IdempotencyRecord struct {
+	ID          uint      `json:"id" gorm:"primary_key"`
+	Key         string    `json:"key" gorm:"column:idempotency_key;not null;size:255;uniqueIndex:idx_idempotency_scope"`
+	ActorID     uint      `json:"actor_id" gorm:"not null;uniqueIndex:idx_idempotency_scope"`
+	Method      string    `json:"method" gorm:"not null;size:10;uniqueIndex:idx_idempotency_scope"`
+	Path        string    `json:"path" gorm:"not null;size:255;uniqueIndex:idx_idempotency_scope"`
+	Completed   bool      `json:"completed" gorm:"not null;default:false"`
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `json:"content_type" gorm:"size:100"`
+	Body        string    `json:"-" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+// TableName returns the table name for the IdempotencyRecord model.
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_records"
+}
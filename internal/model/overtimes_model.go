@@ -68,3 +68,16 @@ func (o *Overtime) CalculateHoursFromTime() {
 		o.Hours = int(duration.Hours())
 	}
 }
+
+// DurationMinutes returns the overtime length in minutes, from the start and end time when
+// both are set ("15:04" or "15:04:05"), otherwise from the whole hours
+func (o *Overtime) DurationMinutes() int {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		start, err1 := time.Parse(layout, o.StartTime)
+		end, err2 := time.Parse(layout, o.EndTime)
+		if err1 == nil && err2 == nil && end.After(start) {
+			return int(end.Sub(start).Minutes())
+		}
+	}
+	return o.Hours * 60
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// PasswordResetToken represents a single-use token an employee sets a new password with.
+// Only the SHA-256 hash of the token is stored, it stops working once used or expired.
+type PasswordResetToken struct {
+	DefaultAttribute
+	EmployeeID uint       `json:"employee_id" gorm:"not null;index"`
+	TokenHash  string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt     *time.Time `json:"used_at" gorm:"default:null"`
+
+	// Relationship
+	Employee Employee `json:"employee,omitempty" gorm:"foreignKey:EmployeeID"`
+}
+
+// TableName returns the table name for the PasswordResetToken model.
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// IsUsed checks if the token was used or replaced by a newer one
+func (p *PasswordResetToken) IsUsed() bool {
+	return p.UsedAt != nil
+}
+
+// IsExpired checks if the token expired at the given time
+func (p *PasswordResetToken) IsExpired(now time.Time) bool {
+	return !now.Before(p.ExpiresAt)
+}
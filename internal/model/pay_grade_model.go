@@ -0,0 +1,21 @@
+package model
+
+// PayGrade represents a salary band. Employees assigned to a grade must have a basic salary
+// between MinSalary and MaxSalary, DefaultSalary is paid when payroll runs without one.
+type PayGrade struct {
+	DefaultAttribute
+	Name          string  `json:"name" gorm:"not null;size:100;index" validate:"required"`
+	MinSalary     float64 `json:"min_salary" gorm:"not null;default:0"`
+	MaxSalary     float64 `json:"max_salary" gorm:"not null;default:0"`
+	DefaultSalary float64 `json:"default_salary" gorm:"not null;default:0"`
+}
+
+// TableName returns the table name for the PayGrade model.
+func (PayGrade) TableName() string {
+	return "pay_grades"
+}
+
+// Contains checks if the salary is within the band of the grade
+func (g *PayGrade) Contains(salary float64) bool {
+	return salary >= g.MinSalary && salary <= g.MaxSalary
+}
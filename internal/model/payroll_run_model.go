@@ -0,0 +1,117 @@
+package model
+
+import "time"
+
+// Payroll run result status values
+const (
+	PayrollRunResultSucceeded = "succeeded"
+	PayrollRunResultFailed    = "failed"
+)
+
+// Payslip email outcome values of a payroll run result
+const (
+	PayslipEmailSent    = "sent"
+	PayslipEmailSkipped = "skipped" // No email address or no mail server, the run is unaffected
+	PayslipEmailFailed  = "failed"
+)
+
+// PayrollRun records a payroll run for all employees with the outcome per employee,
+// so failed employees can be reprocessed later with the same parameters.
+type PayrollRun struct {
+	DefaultAttribute
+	PayPeriodStart time.Time `json:"pay_period_start" gorm:"not null"`
+	PayPeriodEnd   time.Time `json:"pay_period_end" gorm:"not null"`
+	BasicSalary    float64   `json:"basic_salary" gorm:"not null"`
+	OvertimeRate   float64   `json:"overtime_rate" gorm:"not null"`
+	// The overtime rate of each employee was derived from their basic salary, OvertimeRate is unused
+	DeriveOvertimeRate bool   `json:"derive_overtime_rate" gorm:"default:false"`
+	Currency           string `json:"currency" gorm:"size:3"`
+	ProcessedCount     int    `json:"processed_count" gorm:"default:0"`
+	ErrorCount         int    `json:"error_count" gorm:"default:0"`
+	// Timing of the run, unset for runs recorded before it was tracked
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	DurationMs int64      `json:"duration_ms" gorm:"default:0"`
+
+	// Relationships
+	Results []PayrollRunResult `json:"results,omitempty" gorm:"foreignKey:PayrollRunID"`
+}
+
+// TableName returns the table name for the PayrollRun model.
+func (PayrollRun) TableName() string {
+	return "payroll_runs"
+}
+
+// ResultForEmployee returns the result of an employee in the run, or nil
+func (r *PayrollRun) ResultForEmployee(employeeID uint) *PayrollRunResult {
+	for i := range r.Results {
+		if r.Results[i].EmployeeID == employeeID {
+			return &r.Results[i]
+		}
+	}
+	return nil
+}
+
+// RecordTiming stores when the run started and finished and how long it took
+func (r *PayrollRun) RecordTiming(startedAt, finishedAt time.Time) {
+	r.StartedAt = &startedAt
+	r.FinishedAt = &finishedAt
+	r.DurationMs = finishedAt.Sub(startedAt).Milliseconds()
+}
+
+// RecountResults refreshes the processed and error counts from the results
+func (r *PayrollRun) RecountResults() {
+	r.ProcessedCount = 0
+	r.ErrorCount = 0
+	for _, result := range r.Results {
+		if result.IsSucceeded() {
+			r.ProcessedCount++
+		} else {
+			r.ErrorCount++
+		}
+	}
+}
+
+// PayrollRunResult records the outcome of one employee in a payroll run
+type PayrollRunResult struct {
+	DefaultAttribute
+	PayrollRunID uint   `json:"payroll_run_id" gorm:"not null;index"`
+	EmployeeID   uint   `json:"employee_id" gorm:"not null;index"`
+	Status       string `json:"status" gorm:"not null;size:20"` // succeeded, failed
+	PayslipID    *uint  `json:"payslip_id"`
+	Error        string `json:"error,omitempty" gorm:"size:500"`
+	Attempts     int    `json:"attempts" gorm:"default:1"`
+	// Outcome of emailing the payslip, empty when the run did not send emails
+	EmailStatus string `json:"email_status,omitempty" gorm:"size:20"`
+	EmailError  string `json:"email_error,omitempty" gorm:"size:500"`
+}
+
+// TableName returns the table name for the PayrollRunResult model.
+func (PayrollRunResult) TableName() string {
+	return "payroll_run_results"
+}
+
+// MarkSucceeded records the payslip created for the employee
+func (r *PayrollRunResult) MarkSucceeded(payslipID uint) {
+	r.Status = PayrollRunResultSucceeded
+	r.PayslipID = &payslipID
+	r.Error = ""
+}
+
+// MarkFailed records why the employee could not be processed
+func (r *PayrollRunResult) MarkFailed(reason string) {
+	r.Status = PayrollRunResultFailed
+	r.PayslipID = nil
+	r.Error = reason
+}
+
+// RecordEmail stores the outcome of emailing the payslip, with the reason when it was not sent
+func (r *PayrollRunResult) RecordEmail(status string, reason string) {
+	r.EmailStatus = status
+	r.EmailError = reason
+}
+
+// IsSucceeded checks if a payslip was created for the employee
+func (r *PayrollRunResult) IsSucceeded() bool {
+	return r.Status == PayrollRunResultSucceeded
+}
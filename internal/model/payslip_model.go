@@ -2,23 +2,135 @@ package model
 
 import "time"
 
+// Payslip status values
+const (
+	PayslipStatusProcessed = "processed"
+	PayslipStatusPaid      = "paid"
+	PayslipStatusVoid      = "void"
+)
+
 // Payslip represents a payslip record for an employee.
 type Payslip struct {
 	DefaultAttribute
-	EmployeeID          uint      `json:"employee_id" gorm:"not null"`
-	PayPeriodStart      time.Time `json:"pay_period_start" gorm:"not null"`
-	PayPeriodEnd        time.Time `json:"pay_period_end" gorm:"not null"`
-	BasicSalary         float64   `json:"basic_salary" gorm:"not null"`
-	OvertimeHours       int       `json:"overtime_hours" gorm:"default:0"`
-	OvertimeAmount      float64   `json:"overtime_amount" gorm:"default:0"`
-	ReimbursementAmount float64   `json:"reimbursement_amount" gorm:"default:0"`
-	TotalAmount         float64   `json:"total_amount" gorm:"not null"`
-	ProcessedAt         time.Time `json:"processed_at" gorm:"not null"`
-	Status              string    `json:"status" gorm:"not null;default:'processed'"` // processed, paid
-	AttendanceDays      int       `json:"attendance_days" gorm:"default:0"`
+	// One payslip per employee and period, voided and deleted payslips aside
+	EmployeeID          uint       `json:"employee_id" gorm:"not null;uniqueIndex:idx_payslips_employee_period,where:status <> 'void' AND deleted_at IS NULL"`
+	PayPeriodStart      time.Time  `json:"pay_period_start" gorm:"not null;uniqueIndex:idx_payslips_employee_period"`
+	PayPeriodEnd        time.Time  `json:"pay_period_end" gorm:"not null;uniqueIndex:idx_payslips_employee_period"`
+	BasicSalary         float64    `json:"basic_salary" gorm:"not null"`
+	OvertimeHours       int        `json:"overtime_hours" gorm:"default:0"`
+	PaidOvertimeHours   float64    `json:"paid_overtime_hours" gorm:"default:0"` // Overtime hours after the unpaid threshold
+	OvertimeAmount      float64    `json:"overtime_amount" gorm:"default:0"`
+	OvertimeRate        float64    `json:"overtime_rate" gorm:"default:0"` // Hourly overtime rate before day multipliers
+	ReimbursementAmount float64    `json:"reimbursement_amount" gorm:"default:0"`
+	AllowanceAmount     float64    `json:"allowance_amount" gorm:"default:0"`
+	DeductionAmount     float64    `json:"deduction_amount" gorm:"default:0"`
+	DailyRate           float64    `json:"daily_rate" gorm:"default:0"`                // Basic salary per standard working day
+	AbsenceDeduction    float64    `json:"absence_deduction" gorm:"default:0"`         // Daily rate for each working day without attendance
+	LateCount           int        `json:"late_count" gorm:"default:0"`                // Check-ins after the work start time
+	LatePenaltyAmount   float64    `json:"late_penalty_amount" gorm:"default:0"`       // Docked for late check-ins when a penalty mode is enabled
+	BroughtForward      float64    `json:"brought_forward_deduction" gorm:"default:0"` // Unrecovered deduction of the previous payslip
+	CarriedForward      float64    `json:"carried_forward_deduction" gorm:"default:0"` // Deduction left for the next payslip
+	GrossAmount         float64    `json:"gross_amount" gorm:"default:0"`              // Basic, overtime, reimbursements and allowances
+	TaxAmount           float64    `json:"tax_amount" gorm:"default:0"`                // Progressive income tax on basic and overtime, less absence and late penalties
+	NetAmount           float64    `json:"net_amount" gorm:"default:0"`                // Take home pay after tax and deductions
+	TotalAmount         float64    `json:"total_amount" gorm:"not null"`               // Same as NetAmount, kept for existing clients
+	Currency            string     `json:"currency" gorm:"size:3"`                     // ISO 4217 code, amounts are rounded to its precision
+	ProcessedAt         time.Time  `json:"processed_at" gorm:"not null"`
+	Status              string     `json:"status" gorm:"not null;default:'processed'"` // processed, paid, void
+	AttendanceDays      float64    `json:"attendance_days" gorm:"default:0"`
+	ProratedDays        int        `json:"prorated_days" gorm:"default:0"`        // Working days paid for a mid-period joiner
+	PeriodWorkingDays   int        `json:"period_working_days" gorm:"default:0"`  // Working days of the whole pay period
+	OpenAttendanceDays  int        `json:"open_attendance_days" gorm:"default:0"` // Days without a checkout
+	VoidedAt            *time.Time `json:"voided_at,omitempty" gorm:"default:null"`
+	VoidReason          string     `json:"void_reason,omitempty" gorm:"size:255"`
+	// Bumped by every update, an update made against an older version is refused
+	Version int `json:"version" gorm:"not null;default:0"`
+	// Non-fatal findings of the payroll run that created the payslip, not stored
+	Warnings []string `json:"warnings,omitempty" gorm:"-"`
+	// Net pay of a full period below the configured minimum wage, found by the payroll run, not stored
+	MinimumWageShortfall *MinimumWageShortfall `json:"minimum_wage_shortfall,omitempty" gorm:"-"`
+
+	// The amounts in integer minor units (e.g. cents), stored next to them when PAYROLL_MONEY_MINOR_UNITS
+	// is enabled. MinorUnitScale is the decimal places of the minor units, nil when none are stored.
+	MinorUnitScale           *int  `json:"-" gorm:"default:null"`
+	BasicSalaryMinor         int64 `json:"-" gorm:"default:0"`
+	OvertimeAmountMinor      int64 `json:"-" gorm:"default:0"`
+	ReimbursementAmountMinor int64 `json:"-" gorm:"default:0"`
+	AllowanceAmountMinor     int64 `json:"-" gorm:"default:0"`
+	DeductionAmountMinor     int64 `json:"-" gorm:"default:0"`
+	DailyRateMinor           int64 `json:"-" gorm:"default:0"`
+	AbsenceDeductionMinor    int64 `json:"-" gorm:"default:0"`
+	LatePenaltyAmountMinor   int64 `json:"-" gorm:"default:0"`
+	BroughtForwardMinor      int64 `json:"-" gorm:"default:0"`
+	CarriedForwardMinor      int64 `json:"-" gorm:"default:0"`
+	GrossAmountMinor         int64 `json:"-" gorm:"default:0"`
+	TaxAmountMinor           int64 `json:"-" gorm:"default:0"`
+	NetAmountMinor           int64 `json:"-" gorm:"default:0"`
+	TotalAmountMinor         int64 `json:"-" gorm:"default:0"`
+}
+
+// MinimumWageShortfall is how far the net pay of a full period falls below the minimum wage
+type MinimumWageShortfall struct {
+	Floor     float64 `json:"floor"`
+	NetAmount float64 `json:"net_amount"`
+	Shortfall float64 `json:"shortfall"`
+}
+
+// MoneyField pairs an amount of a payslip with its minor unit column
+type MoneyField struct {
+	Amount *float64
+	Minor  *int64
+	Column string // Column of the minor units
+}
+
+// MoneyFields returns the amounts of the payslip with their minor unit columns
+func (p *Payslip) MoneyFields() []MoneyField {
+	return []MoneyField{
+		{&p.BasicSalary, &p.BasicSalaryMinor, "basic_salary_minor"},
+		{&p.OvertimeAmount, &p.OvertimeAmountMinor, "overtime_amount_minor"},
+		{&p.ReimbursementAmount, &p.ReimbursementAmountMinor, "reimbursement_amount_minor"},
+		{&p.AllowanceAmount, &p.AllowanceAmountMinor, "allowance_amount_minor"},
+		{&p.DeductionAmount, &p.DeductionAmountMinor, "deduction_amount_minor"},
+		{&p.DailyRate, &p.DailyRateMinor, "daily_rate_minor"},
+		{&p.AbsenceDeduction, &p.AbsenceDeductionMinor, "absence_deduction_minor"},
+		{&p.LatePenaltyAmount, &p.LatePenaltyAmountMinor, "late_penalty_amount_minor"},
+		{&p.BroughtForward, &p.BroughtForwardMinor, "brought_forward_minor"},
+		{&p.CarriedForward, &p.CarriedForwardMinor, "carried_forward_minor"},
+		{&p.GrossAmount, &p.GrossAmountMinor, "gross_amount_minor"},
+		{&p.TaxAmount, &p.TaxAmountMinor, "tax_amount_minor"},
+		{&p.NetAmount, &p.NetAmountMinor, "net_amount_minor"},
+		{&p.TotalAmount, &p.TotalAmountMinor, "total_amount_minor"},
+	}
 }
 
 // TableName returns the table name for the Payslip model.
 func (Payslip) TableName() string {
 	return "payslips"
 }
+
+// Void marks the payslip as void, keeping the record for audit
+func (p *Payslip) Void(reason string) {
+	now := time.Now()
+	p.Status = PayslipStatusVoid
+	p.VoidedAt = &now
+	p.VoidReason = reason
+}
+
+// ProrationFactor returns the share of the basic salary paid for the period,
+// 1 for payslips processed before proration was recorded
+func (p *Payslip) ProrationFactor() float64 {
+	if p.PeriodWorkingDays == 0 {
+		return 1
+	}
+	return float64(p.ProratedDays) / float64(p.PeriodWorkingDays)
+}
+
+// IsVoid checks if the payslip has been voided
+func (p *Payslip) IsVoid() bool {
+	return p.Status == PayslipStatusVoid
+}
+
+// IsPaid checks if the payslip has been paid out
+func (p *Payslip) IsPaid() bool {
+	return p.Status == PayslipStatusPaid
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// RefreshToken represents a long-lived token exchanged for new access tokens.
+// Only the SHA-256 hash of the token is stored. Each refresh rotates the token within its family,
+// so a revoked token presented again means it was stolen and the whole family is revoked.
+type RefreshToken struct {
+	DefaultAttribute
+	EmployeeID uint       `json:"employee_id" gorm:"not null;index"`
+	TokenHash  string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	FamilyID   string     `json:"family_id" gorm:"not null;size:36;index"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"default:null"`
+
+	// Relationship
+	Employee Employee `json:"employee,omitempty" gorm:"foreignKey:EmployeeID"`
+}
+
+// TableName returns the table name for the RefreshToken model.
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsRevoked checks if the token was rotated or revoked
+func (r *RefreshToken) IsRevoked() bool {
+	return r.RevokedAt != nil
+}
+
+// IsExpired checks if the token expired at the given time
+func (r *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
@@ -29,14 +29,20 @@ const (
 // Reimbursement represents a reimbursement request made by an employee.
 type Reimbursement struct {
 	DefaultAttribute
-	EmployeeID        uint                  `json:"employee_id" gorm:"not null;index" validate:"required"`
-	ReimbursementDate time.Time             `json:"reimbursement_date" gorm:"not null;type:date;index" validate:"required"`
-	Amount            float64               `json:"amount" gorm:"not null;type:decimal(12,2)" validate:"required,min=0.01,max=999999.99"`
-	Category          ReimbursementCategory `json:"category" gorm:"not null;size:50;default:'other'" validate:"required,oneof=travel meals equipment training medical other"`
-	Reason            string                `json:"reason" gorm:"not null;size:255" validate:"required,min=5,max=255"`
-	Status            ReimbursementStatus   `json:"status" gorm:"not null;default:'pending';size:50" validate:"required,oneof=pending approved rejected paid"`
-	ApprovedBy        *uint                 `json:"approved_by" gorm:"default:null"`
-	ApprovedAt        *time.Time            `json:"approved_at" gorm:"default:null"`
+	EmployeeID        uint      `json:"employee_id" gorm:"not null;index" validate:"required"`
+	ReimbursementDate time.Time `json:"reimbursement_date" gorm:"not null;type:date;index" validate:"required"`
+	// Negative for a clawback of an overpaid reimbursement, which only admins record
+	Amount     float64               `json:"amount" gorm:"not null;type:decimal(12,2)" validate:"required,min=-999999.99,max=999999.99"`
+	Category   ReimbursementCategory `json:"category" gorm:"not null;size:50;default:'other'" validate:"required,oneof=travel meals equipment training medical other"`
+	Reason     string                `json:"reason" gorm:"not null;size:255" validate:"required,min=5,max=255"`
+	Status     ReimbursementStatus   `json:"status" gorm:"not null;default:'pending';size:50" validate:"required,oneof=pending approved rejected paid"`
+	ApprovedBy *uint                 `json:"approved_by" gorm:"default:null"`
+	ApprovedAt *time.Time            `json:"approved_at" gorm:"default:null"`
+	// Amount paid when the approver accepted less than requested, nil when the requested amount was approved
+	ApprovedAmount *float64 `json:"approved_amount" gorm:"type:decimal(12,2)"`
+	// Uploaded proof of the expense, the path is only served through the receipt endpoint
+	ReceiptPath        string `json:"-" gorm:"size:500"`
+	ReceiptContentType string `json:"receipt_content_type,omitempty" gorm:"size:100"`
 	// Relationships
 	Employee Employee  `json:"employee,omitempty" gorm:"foreignKey:EmployeeID"`
 	Approver *Employee `json:"approver,omitempty" gorm:"foreignKey:ApprovedBy"`
@@ -63,6 +69,30 @@ func (r *Reimbursement) Reject(approverID uint, reason string) {
 	r.ApprovedAt = &now
 }
 
+// AttachReceipt records the stored receipt file of the reimbursement
+func (r *Reimbursement) AttachReceipt(path string, contentType string) {
+	r.ReceiptPath = path
+	r.ReceiptContentType = contentType
+}
+
+// HasReceipt checks if a receipt was uploaded for the reimbursement
+func (r *Reimbursement) HasReceipt() bool {
+	return r.ReceiptPath != ""
+}
+
+// PayableAmount returns the amount paid out: the approved amount when set, the requested amount otherwise
+func (r *Reimbursement) PayableAmount() float64 {
+	if r.ApprovedAmount != nil {
+		return *r.ApprovedAmount
+	}
+	return r.Amount
+}
+
+// IsClawback checks if the reimbursement claws back an overpaid amount instead of paying one
+func (r *Reimbursement) IsClawback() bool {
+	return r.Amount < 0
+}
+
 // MarkAsPaid marks the reimbursement as paid
 func (r *Reimbursement) MarkAsPaid() {
 	r.Status = ReimbursementPaid
@@ -0,0 +1,33 @@
+package model
+
+// RoleTemplate holds the default salary setup applied to new employees of a role.
+// Values are copied onto the employee at creation, so later template changes
+// never alter existing employees.
+type RoleTemplate struct {
+	DefaultAttribute
+	Role             string `json:"role" gorm:"not null;size:50;index" validate:"required"`
+	StandardHours    int    `json:"standard_hours" gorm:"not null;default:8" validate:"min=1,max=24"`
+	OvertimeEligible bool   `json:"overtime_eligible"`
+
+	// Relationships
+	Components []RoleTemplateComponent `json:"components" gorm:"foreignKey:RoleTemplateID"`
+}
+
+// TableName returns the table name for the RoleTemplate model.
+func (RoleTemplate) TableName() string {
+	return "role_templates"
+}
+
+// RoleTemplateComponent is a recurring allowance or deduction defined on a role template.
+type RoleTemplateComponent struct {
+	DefaultAttribute
+	RoleTemplateID uint          `json:"role_template_id" gorm:"not null;index"`
+	Name           string        `json:"name" gorm:"not null;size:100" validate:"required"`
+	Kind           ComponentKind `json:"kind" gorm:"not null;size:20" validate:"required,oneof=allowance deduction"`
+	Amount         float64       `json:"amount" gorm:"not null;type:decimal(12,2)" validate:"min=0"`
+}
+
+// TableName returns the table name for the RoleTemplateComponent model.
+func (RoleTemplateComponent) TableName() string {
+	return "role_template_components"
+}
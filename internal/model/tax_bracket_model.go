@@ -0,0 +1,26 @@
+package model
+
+// TaxBracket represents a progressive income tax bracket. Income between Floor and Ceiling
+// is taxed at Rate, a Ceiling of 0 means the bracket has no upper bound.
+type TaxBracket struct {
+	DefaultAttribute
+	Floor   float64 `json:"floor" gorm:"not null"`
+	Ceiling float64 `json:"ceiling" gorm:"default:0"`
+	Rate    float64 `json:"rate" gorm:"not null"` // Fraction of the income within the bracket, e.g. 0.05
+}
+
+// TableName returns the table name for the TaxBracket model.
+func (TaxBracket) TableName() string {
+	return "tax_brackets"
+}
+
+// TaxableWithin returns the part of the income that falls inside the bracket
+func (b *TaxBracket) TaxableWithin(income float64) float64 {
+	if income <= b.Floor {
+		return 0
+	}
+	if b.Ceiling > 0 && income > b.Ceiling {
+		return b.Ceiling - b.Floor
+	}
+	return income - b.Floor
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// TimesheetStatus represents the status of a timesheet
+type TimesheetStatus string
+
+const (
+	TimesheetSubmitted TimesheetStatus = "submitted"
+	TimesheetApproved  TimesheetStatus = "approved"
+)
+
+// Timesheet represents an employee's submitted attendance and overtime for a period.
+// The totals are a snapshot taken when the timesheet is submitted.
+type Timesheet struct {
+	DefaultAttribute
+	EmployeeID     uint            `json:"employee_id" gorm:"not null;index" validate:"required"`
+	PeriodStart    time.Time       `json:"period_start" gorm:"not null"`
+	PeriodEnd      time.Time       `json:"period_end" gorm:"not null"`
+	Status         TimesheetStatus `json:"status" gorm:"not null;default:'submitted';size:20"`
+	AttendanceDays int             `json:"attendance_days" gorm:"default:0"`
+	HoursWorked    int             `json:"hours_worked" gorm:"default:0"`
+	OvertimeHours  int             `json:"overtime_hours" gorm:"default:0"`
+	SubmittedAt    time.Time       `json:"submitted_at" gorm:"not null"`
+	ApprovedBy     *uint           `json:"approved_by" gorm:"default:null"`
+	ApprovedAt     *time.Time      `json:"approved_at" gorm:"default:null"`
+
+	// Relationships
+	Employee Employee  `json:"employee,omitempty" gorm:"foreignKey:EmployeeID"`
+	Approver *Employee `json:"approver,omitempty" gorm:"foreignKey:ApprovedBy"`
+}
+
+// TableName returns the table name for the Timesheet model.
+func (Timesheet) TableName() string {
+	return "timesheets"
+}
+
+// Approve marks the timesheet as approved
+func (t *Timesheet) Approve(approverID uint) {
+	now := time.Now()
+	t.Status = TimesheetApproved
+	t.ApprovedBy = &approverID
+	t.ApprovedAt = &now
+}
+
+// IsApproved checks if the timesheet is approved
+func (t *Timesheet) IsApproved() bool {
+	return t.Status == TimesheetApproved
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+// Fixed allowances are the allowance kind of the recurring salary components, the components payroll
+// already sums into the gross pay of every period they are active.
+type allowance struct {
+	db *gorm.DB
+}
+
+// NewAllowanceRepository creates a new instance of allowance repository.
+func NewAllowanceRepository(db *gorm.DB) *allowance {
+	return &allowance{db: db}
+}
+
+// GetDB returns the underlying GORM DB instance for audit functionality
+func (a *allowance) GetDB() *gorm.DB {
+	return a.db
+}
+
+type AllowanceRepository interface {
+	CreateAllowanceWithAudit(req request.AllowanceRequest, auditDB *middleware.AuditableDB) (*model.EmployeeComponent, error)
+	GetAllowancesByEmployee(employeeID uint) ([]model.EmployeeComponent, error)
+	GetAllowanceByID(id uint) (*model.EmployeeComponent, error)
+	UpdateAllowanceWithAudit(id uint, req request.AllowanceRequest, auditDB *middleware.AuditableDB) (*model.EmployeeComponent, error)
+	DeleteAllowanceWithAudit(id uint, auditDB *middleware.AuditableDB) error
+	GetDB() *gorm.DB
+}
+
+// CreateAllowanceWithAudit creates a fixed allowance for an existing employee, active unless requested otherwise
+func (a *allowance) CreateAllowanceWithAudit(req request.AllowanceRequest, auditDB *middleware.AuditableDB) (*model.EmployeeComponent, error) {
+	if req.EmployeeID == 0 {
+		return nil, errors.New("employee_id is required")
+	}
+	if err := a.db.Select("id").First(&model.Employee{}, req.EmployeeID).Error; err != nil {
+		return nil, err
+	}
+
+	component := model.EmployeeComponent{
+		EmployeeID: req.EmployeeID,
+		Name:       req.Name,
+		Kind:       model.ComponentAllowance,
+		Amount:     req.Amount,
+		Active:     true,
+	}
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		if err := middleware.NewAuditableDB(tx, auditDB.UserID).Create(&component).Error; err != nil {
+			return err
+		}
+		if req.Active == nil || *req.Active {
+			return nil
+		}
+		// Active defaults to true in the table, an inactive allowance is stored in a second step
+		return middleware.NewAuditableDB(tx.Model(&component), auditDB.UserID).Updates(map[string]interface{}{"active": false}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &component, nil
+}
+
+// GetAllowancesByEmployee retrieves the active and inactive allowances of an employee, sorted by name
+func (a *allowance) GetAllowancesByEmployee(employeeID uint) ([]model.EmployeeComponent, error) {
+	var components []model.EmployeeComponent
+	err := a.db.Where("employee_id = ? AND kind = ?", employeeID, model.ComponentAllowance).Order("name ASC, id ASC").Find(&components).Error
+	if err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// GetAllowanceByID retrieves an allowance by its ID, deduction components are not found
+func (a *allowance) GetAllowanceByID(id uint) (*model.EmployeeComponent, error) {
+	var component model.EmployeeComponent
+	err := a.db.Where("id = ? AND kind = ?", id, model.ComponentAllowance).First(&component).Error
+	if err != nil {
+		return nil, err
+	}
+	return &component, nil
+}
+
+// UpdateAllowanceWithAudit updates the name, amount and optionally the active state of an allowance, which
+// stays with its employee. Payslips already processed keep the amount they were computed with.
+func (a *allowance) UpdateAllowanceWithAudit(id uint, req request.AllowanceRequest, auditDB *middleware.AuditableDB) (*model.EmployeeComponent, error) {
+	component, err := a.GetAllowanceByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.EmployeeID != 0 && req.EmployeeID != component.EmployeeID {
+		return nil, fmt.Errorf("allowance %d belongs to employee %d, not %d", id, component.EmployeeID, req.EmployeeID)
+	}
+
+	component.Name = req.Name
+	component.Amount = req.Amount
+	if req.Active != nil {
+		component.Active = *req.Active
+	}
+	if err := auditDB.Save(component).Error; err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+// DeleteAllowanceWithAudit soft deletes an allowance
+func (a *allowance) DeleteAllowanceWithAudit(id uint, auditDB *middleware.AuditableDB) error {
+	if _, err := a.GetAllowanceByID(id); err != nil {
+		return err
+	}
+	return auditDB.Delete(&model.EmployeeComponent{}, "id = ?", id).Error
+}
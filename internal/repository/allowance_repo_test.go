@@ -0,0 +1,116 @@
+// Package repository contains unit tests for the allowance repository functionality.
+//
+// CreateAllowanceWithAudit tests cover:
+// 1. Active and inactive allowances created as allowance components, listed per employee by name
+// 2. Unknown and missing employees rejected
+//
+// UpdateAllowanceWithAudit and DeleteAllowanceWithAudit tests cover:
+// 1. An allowance deactivated, moving it to another employee refused, then deleted
+// 2. Deduction components not reachable as allowances
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestAllowanceRepository_CreateAllowanceWithAudit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAllowanceRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+	employee := createTestEmployee(t, db, 1, "Jane Doe")
+	inactive := false
+
+	transport, err := repo.CreateAllowanceWithAudit(request.AllowanceRequest{EmployeeID: employee.ID, Name: "Transport", Amount: 500000}, auditDB)
+	require.NoError(t, err)
+	assert.Equal(t, model.ComponentAllowance, transport.Kind)
+	assert.True(t, transport.Active)
+	require.NotNil(t, transport.CreatedBy)
+	assert.Equal(t, uint(1), *transport.CreatedBy)
+
+	housing, err := repo.CreateAllowanceWithAudit(request.AllowanceRequest{EmployeeID: employee.ID, Name: "Housing", Amount: 1500000, Active: &inactive}, auditDB)
+	require.NoError(t, err)
+	assert.False(t, housing.Active)
+
+	// The inactive state written after the create is audited like any update
+	var logs []model.AuditLog
+	require.NoError(t, db.Where("table_name = ? AND record_id = ?", "employee_components", housing.ID).Order("id").Find(&logs).Error)
+	require.Len(t, logs, 2)
+	assert.Equal(t, model.AuditActionUpdate, logs[1].Action)
+	assert.Contains(t, logs[1].Changes, `"active"`)
+
+	allowances, err := repo.GetAllowancesByEmployee(employee.ID)
+	require.NoError(t, err)
+	require.Len(t, allowances, 2)
+	assert.Equal(t, "Housing", allowances[0].Name)
+	assert.False(t, allowances[0].Active)
+	assert.Equal(t, "Transport", allowances[1].Name)
+	assert.True(t, allowances[1].Active)
+}
+
+func TestAllowanceRepository_CreateAllowanceWithAudit_Rejected(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAllowanceRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+	createTestEmployee(t, db, 1, "Jane Doe")
+
+	_, err := repo.CreateAllowanceWithAudit(request.AllowanceRequest{EmployeeID: 42, Name: "Transport", Amount: 500000}, auditDB)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	_, err = repo.CreateAllowanceWithAudit(request.AllowanceRequest{Name: "Transport", Amount: 500000}, auditDB)
+	assert.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&model.EmployeeComponent{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestAllowanceRepository_UpdateAndDelete(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAllowanceRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+	employee := createTestEmployee(t, db, 1, "Jane Doe")
+	createTestEmployee(t, db, 2, "John Doe")
+	inactive := false
+
+	allowance, err := repo.CreateAllowanceWithAudit(request.AllowanceRequest{EmployeeID: employee.ID, Name: "Transport", Amount: 500000}, auditDB)
+	require.NoError(t, err)
+
+	updated, err := repo.UpdateAllowanceWithAudit(allowance.ID, request.AllowanceRequest{Name: "Transport", Amount: 600000, Active: &inactive}, auditDB)
+	require.NoError(t, err)
+	assert.Equal(t, 600000.0, updated.Amount)
+	assert.False(t, updated.Active)
+
+	_, err = repo.UpdateAllowanceWithAudit(allowance.ID, request.AllowanceRequest{EmployeeID: 2, Name: "Transport", Amount: 600000}, auditDB)
+	assert.Error(t, err)
+	stored, err := repo.GetAllowanceByID(allowance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, employee.ID, stored.EmployeeID)
+	assert.False(t, stored.Active)
+
+	require.NoError(t, repo.DeleteAllowanceWithAudit(allowance.ID, auditDB))
+	_, err = repo.GetAllowanceByID(allowance.ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	assert.True(t, errors.Is(repo.DeleteAllowanceWithAudit(allowance.ID, auditDB), gorm.ErrRecordNotFound))
+}
+
+func TestAllowanceRepository_DeductionNotAnAllowance(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAllowanceRepository(db)
+	employee := createTestEmployee(t, db, 1, "Jane Doe")
+	deduction := model.EmployeeComponent{EmployeeID: employee.ID, Name: "Pension", Kind: model.ComponentDeduction, Amount: 100000, Active: true}
+	require.NoError(t, db.Create(&deduction).Error)
+
+	_, err := repo.GetAllowanceByID(deduction.ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	allowances, err := repo.GetAllowancesByEmployee(employee.ID)
+	require.NoError(t, err)
+	assert.Empty(t, allowances)
+}
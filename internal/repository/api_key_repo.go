@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+type apiKey struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new instance of API key repository.
+func NewAPIKeyRepository(db *gorm.DB) *apiKey {
+	return &apiKey{db: db}
+}
+
+// GetDB returns the underlying GORM DB instance
+func (r *apiKey) GetDB() *gorm.DB {
+	return r.db
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *model.APIKey) (*model.APIKey, error)
+	GetAPIKeyByID(id uint) (*model.APIKey, error)
+	GetActiveAPIKeyByHash(keyHash string) (*model.APIKey, error)
+	ListAPIKeys(employeeID uint) ([]model.APIKey, error)
+	RevokeAPIKey(id uint) error
+	TouchAPIKey(id uint, usedAt time.Time) error
+	GetDB() *gorm.DB
+}
+
+func (r *apiKey) CreateAPIKey(key *model.APIKey) (*model.APIKey, error) {
+	if err := r.db.Create(key).Error; err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKey) GetAPIKeyByID(id uint) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetActiveAPIKeyByHash retrieves the unrevoked key stored under the hash with its employee, expired keys included
+func (r *apiKey) GetActiveAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.Preload("Employee").
+		Where("key_hash = ? AND revoked_at IS NULL", keyHash).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListAPIKeys retrieves the keys of an employee, revoked ones included, newest first
+func (r *apiKey) ListAPIKeys(employeeID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	if err := r.db.Where("employee_id = ?", employeeID).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes the key, revoking an already revoked key keeps its first revocation time
+func (r *apiKey) RevokeAPIKey(id uint) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// TouchAPIKey records when the key was last used
+func (r *apiKey) TouchAPIKey(id uint, usedAt time.Time) error {
+	return r.db.Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
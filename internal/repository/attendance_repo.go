@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,17 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrNoOpenCheckin is returned when checking out without a check-in for today
+	ErrNoOpenCheckin = errors.New("no check-in record found for today")
+	// ErrAlreadyCheckedOut is returned when today's attendance already has a checkout
+	ErrAlreadyCheckedOut = errors.New("already checked out for today")
+	// ErrAlreadyCheckedIn is returned when the employee already has an attendance for the day
+	ErrAlreadyCheckedIn = errors.New("attendance already recorded for today")
+	// ErrAttendancePeriodProcessed is returned when deleting an attendance dated in a pay period that has a payslip
+	ErrAttendancePeriodProcessed = errors.New("attendance falls in an already processed pay period")
+)
+
 type attendance struct {
 	db *gorm.DB
 }
@@ -24,10 +36,17 @@ type AttendanceRepository interface {
 	CheckOutAttendancePeriod(employeID uint) (*model.Attendance, error)
 	GetTodayAttendance(employeID uint) (*model.Attendance, error)
 	UpdateOrCreateAttendance(employeID uint, date time.Time, checkin time.Time, checkout *time.Time) (*model.Attendance, error)
+	EachAttendanceInPeriod(employeeIDs []uint, startDate time.Time, endDate time.Time, fn func([]model.Attendance) error) error
+	GetEmployeeAttendanceInPeriod(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Attendance, error)
+	HasCheckinForDate(employeeID uint, date time.Time) (*model.Attendance, bool, error)
+	GetAttendanceForDate(date time.Time) ([]model.Attendance, error)
+	GetAttendanceWithOvertimes(attendanceID uint) (*model.Attendance, []model.Overtime, error)
 
 	// Audit-enabled methods
-	CheckinAttendancePeriodWithAudit(employeID uint, auditDB *middleware.AuditableDB) (*model.Attendance, error)
+	CheckinAttendancePeriodWithAudit(employeID uint, status string, latitude, longitude *float64, loc *time.Location, auditDB *middleware.AuditableDB) (*model.Attendance, error)
+	CreateAttendanceWithAudit(attendance *model.Attendance, auditDB *middleware.AuditableDB) (*model.Attendance, error)
 	CheckOutAttendancePeriodWithAudit(employeID uint, auditDB *middleware.AuditableDB) (*model.Attendance, error)
+	DeleteAttendanceWithAudit(attendanceID uint, auditDB *middleware.AuditableDB) (*model.Attendance, error)
 }
 
 func (a *attendance) CreateAttendancePeriod(employeID uint, checkin time.Time, checkout *time.Time) (*model.Attendance, error) {
@@ -211,41 +230,155 @@ func (a *attendance) UpdateOrCreateAttendance(employeID uint, date time.Time, ch
 	}
 }
 
-// CheckinAttendancePeriodWithAudit creates a check-in attendance record with audit tracking
-func (a *attendance) CheckinAttendancePeriodWithAudit(employeID uint, auditDB *middleware.AuditableDB) (*model.Attendance, error) {
-	// First, check if the employee exists
+// GetAttendanceWithOvertimes returns an attendance with the overtime entries of the same employee logged
+// for its date, whatever their status, in ID order. gorm.ErrRecordNotFound is returned for a missing attendance.
+func (a *attendance) GetAttendanceWithOvertimes(attendanceID uint) (*model.Attendance, []model.Overtime, error) {
+	var attendance model.Attendance
+	if err := a.db.First(&attendance, attendanceID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// Overtime dates are stored as YYYY-MM-DD text
+	var overtimes []model.Overtime
+	err := a.db.Where("employee_id = ? AND overtime_date = ?", attendance.EmployeeID, attendance.Date.Format("2006-01-02")).
+		Order("id").
+		Find(&overtimes).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return &attendance, overtimes, nil
+}
+
+// attendanceBatchSize is the number of attendance records loaded at a time when walking a period
+const attendanceBatchSize = 500
+
+// EachAttendanceInPeriod walks the attendance of the period in batches of ID order, with the employee loaded.
+// A nil employeeIDs covers every employee, an empty one none.
+func (a *attendance) EachAttendanceInPeriod(employeeIDs []uint, startDate time.Time, endDate time.Time, fn func([]model.Attendance) error) error {
+	if employeeIDs != nil && len(employeeIDs) == 0 {
+		return nil
+	}
+
+	query := a.db.Preload("Employee").Where("date >= ? AND date <= ?", startDate, endDate)
+	if employeeIDs != nil {
+		query = query.Where("employee_id IN ?", employeeIDs)
+	}
+
+	var batch []model.Attendance
+	return query.FindInBatches(&batch, attendanceBatchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// GetEmployeeAttendanceInPeriod returns the attendance of an employee dated between the two times, both included,
+// in date and check-in order
+func (a *attendance) GetEmployeeAttendanceInPeriod(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Attendance, error) {
+	var attendances []model.Attendance
+	err := a.db.Where("employee_id = ? AND date >= ? AND date <= ?", employeeID, startDate, endDate).
+		Order("date ASC").
+		Order("checkin ASC").
+		Find(&attendances).Error
+	if err != nil {
+		return nil, err
+	}
+	return attendances, nil
+}
+
+// CheckinAttendancePeriodWithAudit creates a check-in attendance record with the given status, the optional
+// location it was made from and audit tracking. It is dated now in loc, the server timezone, so the one
+// attendance per day is checked on the server's calendar day.
+func (a *attendance) CheckinAttendancePeriodWithAudit(employeID uint, status string, latitude, longitude *float64, loc *time.Location, auditDB *middleware.AuditableDB) (*model.Attendance, error) {
+	now := time.Now().In(loc)
+	return a.CreateAttendanceWithAudit(&model.Attendance{
+		EmployeeID: employeID,
+		Status:     status,
+		Date:       now,
+		Checkin:    now,
+		Latitude:   latitude,
+		Longitude:  longitude,
+	}, auditDB)
+}
+
+// CreateAttendanceWithAudit stores an attendance with audit tracking, one per employee per calendar day of its date
+// in the date's location. Fails with ErrAlreadyCheckedIn when the day already has one, and wraps
+// gorm.ErrRecordNotFound for a missing employee.
+func (a *attendance) CreateAttendanceWithAudit(attendance *model.Attendance, auditDB *middleware.AuditableDB) (*model.Attendance, error) {
 	var employee model.Employee
-	if err := a.db.First(&employee, employeID).Error; err != nil {
+	if err := a.db.First(&employee, attendance.EmployeeID).Error; err != nil {
 		return nil, fmt.Errorf("employee not found: %w", err)
 	}
 
-	// Check if there's already an attendance record for today
-	today := time.Now().Format("2006-01-02")
-	var existingAttendance model.Attendance
-	err := a.db.Where("employee_id = ? AND DATE(date) = ?", employeID, today).First(&existingAttendance).Error
+	_, found, err := a.HasCheckinForDate(attendance.EmployeeID, attendance.Date)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return nil, ErrAlreadyCheckedIn
+	}
 
-	if err != nil && err != gorm.ErrRecordNotFound {
+	if err := auditDB.Create(attendance).Error; err != nil {
 		return nil, err
 	}
+	return attendance, nil
+}
 
-	if err == nil {
-		// Record already exists, return error
-		return nil, fmt.Errorf("attendance already recorded for today")
+// HasCheckinForDate returns the attendance of the employee on the calendar day of date.
+// The day is taken in date's location, so pass a time in the server timezone rather than UTC.
+func (a *attendance) HasCheckinForDate(employeeID uint, date time.Time) (*model.Attendance, bool, error) {
+	dayStart, dayEnd := calendarDay(date)
+
+	// Widen the window by a day on each side, stores that keep the offset in the
+	// stored value can compare it as text, the calendar day is matched below
+	var candidates []model.Attendance
+	err := a.db.Where("employee_id = ? AND date >= ? AND date < ?", employeeID, dayStart.AddDate(0, 0, -1), dayEnd.AddDate(0, 0, 1)).
+		Order("checkin ASC").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, false, err
 	}
 
-	// Create new attendance record
-	attendance := model.Attendance{
-		EmployeeID: employeID,
-		Status:     "present",
-		Date:       time.Now(),
-		Checkin:    time.Now(),
+	for i := range candidates {
+		if onCalendarDay(candidates[i].Date, dayStart, dayEnd) {
+			return &candidates[i], true, nil
+		}
 	}
+	return nil, false, nil
+}
 
-	err = auditDB.Create(&attendance).Error
+// GetAttendanceForDate returns the attendance of active employees on the calendar day of date, with the
+// employee loaded, in employee order. The day is taken in date's location, as in HasCheckinForDate.
+func (a *attendance) GetAttendanceForDate(date time.Time) ([]model.Attendance, error) {
+	dayStart, dayEnd := calendarDay(date)
+
+	var candidates []model.Attendance
+	err := a.db.Joins("JOIN employees ON employees.id = attendances.employee_id AND employees.active = ? AND employees.deleted_at IS NULL", true).
+		Preload("Employee").
+		Where("attendances.date >= ? AND attendances.date < ?", dayStart.AddDate(0, 0, -1), dayEnd.AddDate(0, 0, 1)).
+		Order("attendances.employee_id ASC, attendances.checkin ASC").
+		Find(&candidates).Error
 	if err != nil {
 		return nil, err
 	}
-	return &attendance, nil
+
+	attendances := make([]model.Attendance, 0, len(candidates))
+	for _, candidate := range candidates {
+		if onCalendarDay(candidate.Date, dayStart, dayEnd) {
+			attendances = append(attendances, candidate)
+		}
+	}
+	return attendances, nil
+}
+
+// calendarDay returns the start of the calendar day of date in its location and the start of the next day
+func calendarDay(date time.Time) (time.Time, time.Time) {
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return dayStart, dayStart.AddDate(0, 0, 1)
+}
+
+// onCalendarDay checks if a stored attendance date falls on the day starting at dayStart
+func onCalendarDay(date time.Time, dayStart time.Time, dayEnd time.Time) bool {
+	day := date.In(dayStart.Location())
+	return !day.Before(dayStart) && day.Before(dayEnd)
 }
 
 // CheckOutAttendancePeriodWithAudit updates attendance record with checkout time and audit tracking
@@ -257,13 +390,13 @@ func (a *attendance) CheckOutAttendancePeriodWithAudit(employeID uint, auditDB *
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("no check-in record found for today")
+			return nil, ErrNoOpenCheckin
 		}
 		return nil, err
 	}
 
 	if attendance.Checkout != nil {
-		return nil, fmt.Errorf("already checked out for today")
+		return nil, ErrAlreadyCheckedOut
 	}
 
 	// Update checkout time
@@ -277,3 +410,35 @@ func (a *attendance) CheckOutAttendancePeriodWithAudit(employeID uint, auditDB *
 	}
 	return &attendance, nil
 }
+
+// DeleteAttendanceWithAudit soft deletes an attendance, recording who deleted it. An attendance dated in a pay period
+// the employee already has a non-void payslip for is kept, and ErrAttendancePeriodProcessed is returned wrapped with
+// the payslip. gorm.ErrRecordNotFound is returned for a missing attendance.
+func (a *attendance) DeleteAttendanceWithAudit(attendanceID uint, auditDB *middleware.AuditableDB) (*model.Attendance, error) {
+	var attendance model.Attendance
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&attendance, attendanceID).Error; err != nil {
+			return err
+		}
+
+		var payslips []model.Payslip
+		err := tx.Where("employee_id = ? AND pay_period_start <= ? AND pay_period_end >= ? AND status <> ?",
+			attendance.EmployeeID, attendance.Date, attendance.Date, model.PayslipStatusVoid).
+			Limit(1).
+			Find(&payslips).Error
+		if err != nil {
+			return err
+		}
+		if len(payslips) > 0 {
+			return fmt.Errorf("%w: payslip %d covers %s to %s", ErrAttendancePeriodProcessed, payslips[0].ID,
+				payslips[0].PayPeriodStart.Format("2006-01-02"), payslips[0].PayPeriodEnd.Format("2006-01-02"))
+		}
+
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+		return txAudit.Delete(&model.Attendance{}, "id = ?", attendance.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attendance, nil
+}
@@ -0,0 +1,216 @@
+// Package repository contains unit tests for the attendance repository functionality.
+//
+// HasCheckinForDate tests cover:
+// 1. The calendar day taken in the location of the given date, not in UTC
+// 2. Other employees' attendance ignored
+//
+// CheckinAttendancePeriodWithAudit tests cover:
+// 1. The check-in dated now in the server timezone, a second one on that calendar day refused
+//
+// GetAttendanceWithOvertimes tests cover:
+// 1. Overtime of the same employee and date linked whatever its status, other dates and employees left out
+// 2. A missing attendance returning gorm.ErrRecordNotFound
+//
+// GetAttendanceForDate tests cover:
+// 1. Attendance of active employees on the calendar day in the date's location, with the employee loaded
+// 2. Inactive and deleted employees and other days left out
+//
+// DeleteAttendanceWithAudit tests cover:
+// 1. Soft deletion recording the deleting user, the record then left out of payroll attendance
+// 2. Attendance in a period with a non-void payslip kept, a voided payslip not blocking
+// 3. A missing attendance returning gorm.ErrRecordNotFound
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestAttendanceRepository_HasCheckinForDate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+
+	// 20:00 UTC on June 2nd is already 03:00 on June 3rd at UTC+7
+	checkin := time.Date(2025, time.June, 2, 20, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&model.Attendance{EmployeeID: employee.ID, Date: checkin, Checkin: checkin, Status: "present"}).Error)
+
+	jakarta := time.FixedZone("UTC+7", 7*60*60)
+
+	existing, found, err := repo.HasCheckinForDate(employee.ID, time.Date(2025, time.June, 3, 12, 0, 0, 0, jakarta))
+	require.NoError(t, err)
+	assert.True(t, found)
+	require.NotNil(t, existing)
+	assert.True(t, checkin.Equal(existing.Checkin))
+
+	_, found, err = repo.HasCheckinForDate(employee.ID, time.Date(2025, time.June, 2, 12, 0, 0, 0, jakarta))
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, err = repo.HasCheckinForDate(employee.ID, time.Date(2025, time.June, 2, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestAttendanceRepository_HasCheckinForDate_OtherEmployee(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	other := createTestEmployee(t, db, 2, "Jane Doe")
+
+	day := time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&model.Attendance{EmployeeID: other.ID, Date: day, Checkin: day, Status: "present"}).Error)
+
+	existing, found, err := repo.HasCheckinForDate(1, day)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Nil(t, existing)
+}
+
+func TestAttendanceRepository_CheckinAttendancePeriodWithAudit_ServerTimezone(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+
+	// A server timezone whose calendar day differs from the UTC one right now
+	loc := time.FixedZone("UTC-13", -13*60*60)
+	if time.Now().UTC().Hour() >= 12 {
+		loc = time.FixedZone("UTC+13", 13*60*60)
+	}
+	require.NotEqual(t, time.Now().UTC().Format("2006-01-02"), time.Now().In(loc).Format("2006-01-02"))
+
+	attendance, err := repo.CheckinAttendancePeriodWithAudit(employee.ID, "present", nil, nil, loc, middleware.NewAuditableDB(db, employee.ID))
+	require.NoError(t, err)
+	assert.Equal(t, loc, attendance.Date.Location())
+	assert.Equal(t, time.Now().In(loc).Format("2006-01-02"), attendance.Date.Format("2006-01-02"))
+
+	_, err = repo.CheckinAttendancePeriodWithAudit(employee.ID, "present", nil, nil, loc, middleware.NewAuditableDB(db, employee.ID))
+	assert.ErrorIs(t, err, ErrAlreadyCheckedIn)
+}
+
+func TestAttendanceRepository_GetAttendanceWithOvertimes(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	other := createTestEmployee(t, db, 2, "Jane Doe")
+
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	attendance := &model.Attendance{EmployeeID: employee.ID, Date: day, Checkin: day.Add(9 * time.Hour), Status: "present"}
+	require.NoError(t, db.Create(attendance).Error)
+
+	overtimes := []model.Overtime{
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-02", Hours: 2, Reason: "Release support", Status: model.OvertimeApproved},
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-02", Hours: 1, Reason: "Incident follow-up", Status: model.OvertimePending},
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-03", Hours: 3, Reason: "Another day", Status: model.OvertimeApproved},
+		{EmployeeID: other.ID, OvertimeDate: "2025-06-02", Hours: 4, Reason: "Another employee", Status: model.OvertimeApproved},
+	}
+	require.NoError(t, db.Create(&overtimes).Error)
+
+	found, linked, err := repo.GetAttendanceWithOvertimes(attendance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, attendance.ID, found.ID)
+	require.Len(t, linked, 2)
+	assert.Equal(t, overtimes[0].ID, linked[0].ID)
+	assert.Equal(t, model.OvertimeApproved, linked[0].Status)
+	assert.Equal(t, overtimes[1].ID, linked[1].ID)
+	assert.Equal(t, model.OvertimePending, linked[1].Status)
+}
+
+func TestAttendanceRepository_GetAttendanceWithOvertimes_NotFound(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+
+	_, _, err := repo.GetAttendanceWithOvertimes(99)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestAttendanceRepository_GetAttendanceForDate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	createTestEmployee(t, db, 2, "Jane Doe")
+	inactive := createTestEmployee(t, db, 3, "Ian Inactive")
+	require.NoError(t, db.Model(inactive).Update("active", false).Error)
+	deleted := createTestEmployee(t, db, 4, "Dee Deleted")
+	require.NoError(t, db.Delete(deleted).Error)
+
+	jakarta := time.FixedZone("UTC+7", 7*60*60)
+	attend := func(employeeID uint, at time.Time) {
+		require.NoError(t, db.Create(&model.Attendance{EmployeeID: employeeID, Date: at, Checkin: at, Status: "present"}).Error)
+	}
+	attend(2, time.Date(2025, time.June, 3, 8, 0, 0, 0, jakarta))
+	// 20:00 UTC on June 2nd is already 03:00 on June 3rd at UTC+7
+	attend(1, time.Date(2025, time.June, 2, 20, 0, 0, 0, time.UTC))
+	attend(1, time.Date(2025, time.June, 4, 8, 0, 0, 0, jakarta))
+	attend(3, time.Date(2025, time.June, 3, 8, 0, 0, 0, jakarta))
+	attend(4, time.Date(2025, time.June, 3, 8, 0, 0, 0, jakarta))
+
+	attendances, err := repo.GetAttendanceForDate(time.Date(2025, time.June, 3, 0, 0, 0, 0, jakarta))
+	require.NoError(t, err)
+	require.Len(t, attendances, 2)
+	assert.Equal(t, uint(1), attendances[0].EmployeeID)
+	assert.Equal(t, "John Doe", attendances[0].Employee.Name)
+	assert.Equal(t, uint(2), attendances[1].EmployeeID)
+	assert.Equal(t, "Jane Doe", attendances[1].Employee.Name)
+
+	attendances, err = repo.GetAttendanceForDate(time.Date(2025, time.June, 5, 0, 0, 0, 0, jakarta))
+	require.NoError(t, err)
+	assert.Empty(t, attendances)
+}
+
+func TestAttendanceRepository_DeleteAttendanceWithAudit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	day := time.Date(2025, time.June, 5, 0, 0, 0, 0, time.UTC)
+	attendance := &model.Attendance{EmployeeID: employee.ID, Date: day, Checkin: day.Add(8 * time.Hour), Status: "present"}
+	require.NoError(t, db.Create(attendance).Error)
+
+	deleted, err := repo.DeleteAttendanceWithAudit(attendance.ID, middleware.NewAuditableDB(db, 9))
+	require.NoError(t, err)
+	assert.Equal(t, attendance.ID, deleted.ID)
+
+	var stored model.Attendance
+	require.NoError(t, db.Unscoped().First(&stored, attendance.ID).Error)
+	assert.True(t, stored.DeletedAt.Valid)
+	require.NotNil(t, stored.DeletedBy)
+	assert.Equal(t, uint(9), *stored.DeletedBy)
+
+	// Payroll no longer counts the deleted day
+	attendances, err := NewPayslipRepository(db).GetAttendanceForPeriod(employee.ID, day.AddDate(0, 0, -4), day.AddDate(0, 0, 25))
+	require.NoError(t, err)
+	assert.Empty(t, attendances)
+
+	_, err = repo.DeleteAttendanceWithAudit(attendance.ID, middleware.NewAuditableDB(db, 9))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestAttendanceRepository_DeleteAttendanceWithAudit_ProcessedPeriod(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAttendanceRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	day := time.Date(2025, time.June, 5, 0, 0, 0, 0, time.UTC)
+	attendance := &model.Attendance{EmployeeID: employee.ID, Date: day, Checkin: day.Add(8 * time.Hour), Status: "present"}
+	require.NoError(t, db.Create(attendance).Error)
+	payslip := createTestPayslip(t, db, employee.ID, time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC))
+
+	_, err := repo.DeleteAttendanceWithAudit(attendance.ID, middleware.NewAuditableDB(db, 9))
+	assert.True(t, errors.Is(err, ErrAttendancePeriodProcessed))
+	assert.Contains(t, err.Error(), "2025-06-01 to 2025-06-30")
+
+	var count int64
+	require.NoError(t, db.Model(&model.Attendance{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// Once the payslip is voided the period can be corrected
+	require.NoError(t, db.Model(payslip).Update("status", model.PayslipStatusVoid).Error)
+	_, err = repo.DeleteAttendanceWithAudit(attendance.ID, middleware.NewAuditableDB(db, 9))
+	assert.NoError(t, err)
+}
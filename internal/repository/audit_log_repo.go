@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+type auditLog struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new instance of audit log repository.
+func NewAuditLogRepository(db *gorm.DB) *auditLog {
+	return &auditLog{db: db}
+}
+
+type AuditLogRepository interface {
+	ListAuditLogs(filter request.AuditLogFilter, offset int, limit int) ([]model.AuditLog, int64, error)
+	ListRecordHistory(table string, recordID uint, filter request.AuditLogFilter, offset int, limit int) ([]model.AuditLog, int64, error)
+}
+
+// ListAuditLogs retrieves limit audit log entries matching the filter from offset, newest first,
+// together with the total number of matching entries
+func (a *auditLog) ListAuditLogs(filter request.AuditLogFilter, offset int, limit int) ([]model.AuditLog, int64, error) {
+	return a.page(a.filterQuery(filter), "DESC", offset, limit)
+}
+
+// ListRecordHistory retrieves limit audit log entries of one record matching the filter from offset,
+// oldest first so the changes read in the order they were made, together with the total number of matching entries
+func (a *auditLog) ListRecordHistory(table string, recordID uint, filter request.AuditLogFilter, offset int, limit int) ([]model.AuditLog, int64, error) {
+	filter.Table = table
+	filter.RecordID = &recordID
+	return a.page(a.filterQuery(filter), "ASC", offset, limit)
+}
+
+// filterQuery selects the audit log entries matching the filter
+func (a *auditLog) filterQuery(filter request.AuditLogFilter) *gorm.DB {
+	query := a.db.Model(&model.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Table != "" {
+		query = query.Where("table_name = ?", filter.Table)
+	}
+	if filter.RecordID != nil {
+		query = query.Where("record_id = ?", *filter.RecordID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Field != "" {
+		// Changes is a JSON object keyed by column
+		query = query.Where("changes LIKE ?", `%"`+filter.Field+`":%`)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("created_at < ?", filter.EndDate.AddDate(0, 0, 1))
+	}
+	return query
+}
+
+// page counts the entries of the query and retrieves limit of them from offset, ordered by time in the direction
+func (a *auditLog) page(query *gorm.DB, direction string, offset int, limit int) ([]model.AuditLog, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []model.AuditLog
+	err := query.Order("created_at " + direction).
+		Order("id " + direction).
+		Offset(offset).
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,166 @@
+// Package repository contains unit tests for the audit log.
+//
+// Audit trail tests cover:
+// 1. Create, update and delete through the auditable write path recorded with actor and changed columns
+// 2. Password hashes and unchanged columns left out of the changes
+//
+// ListAuditLogs tests cover:
+// 1. Actor, table, action and date range filters, newest first with the total count
+//
+// ListRecordHistory tests cover:
+// 1. Only the entries of the record, oldest first, narrowed to the changes of one column
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+)
+
+// decodeAuditChanges decodes the changes of an audit log entry
+func decodeAuditChanges(t *testing.T, log model.AuditLog) map[string]model.AuditChange {
+	var changes map[string]model.AuditChange
+	require.NoError(t, json.Unmarshal([]byte(log.Changes), &changes))
+	return changes
+}
+
+func TestAuditLog_RecordsAuditableWrites(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{
+		Name: "Jane Doe", Password: "secret", Role: "employee", Active: true, BasicSalary: 5000000,
+	}, middleware.NewAuditableDB(db, 1))
+	require.NoError(t, err)
+
+	salary := 6500000.0
+	_, err = repo.UpdateEmployeeWithAudit("1", request.UpdateEmployeeRequest{
+		Name: "Jane Doe", Password: "secret", Role: "employee", BasicSalary: &salary,
+	}, middleware.NewAuditableDB(db, 2))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteEmployeeWithAudit("1", middleware.NewAuditableDB(db, 3)))
+
+	var logs []model.AuditLog
+	require.NoError(t, db.Where("table_name = ?", "employees").Order("id").Find(&logs).Error)
+	require.Len(t, logs, 3)
+
+	created := logs[0]
+	assert.Equal(t, model.AuditActionCreate, created.Action)
+	assert.Equal(t, employee.ID, created.RecordID)
+	assert.Equal(t, uint(1), created.ActorID)
+	assert.Equal(t, model.AuditChange{New: 5000000.0}, decodeAuditChanges(t, created)["basic_salary"])
+	assert.NotContains(t, created.Changes, "password")
+
+	updated := logs[1]
+	assert.Equal(t, model.AuditActionUpdate, updated.Action)
+	assert.Equal(t, uint(2), updated.ActorID)
+	// The password is rehashed on every update, but hashes are never logged
+	assert.Equal(t, map[string]model.AuditChange{
+		"basic_salary": {Old: 5000000.0, New: 6500000.0},
+	}, decodeAuditChanges(t, updated))
+
+	deleted := logs[2]
+	assert.Equal(t, model.AuditActionDelete, deleted.Action)
+	assert.Equal(t, uint(3), deleted.ActorID)
+	assert.Equal(t, model.AuditChange{Old: "Jane Doe"}, decodeAuditChanges(t, deleted)["name"])
+}
+
+func TestAuditLog_RecordsInactiveEmployeeCreate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{
+		Name: "Jane Doe", Password: "secret", Role: "employee", Active: false,
+	}, middleware.NewAuditableDB(db, 1))
+	require.NoError(t, err)
+
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.False(t, stored.Active)
+
+	var logs []model.AuditLog
+	require.NoError(t, db.Where("table_name = ?", "employees").Order("id").Find(&logs).Error)
+	require.Len(t, logs, 2)
+	assert.Equal(t, model.AuditActionUpdate, logs[1].Action)
+	assert.Equal(t, uint(1), logs[1].ActorID)
+	assert.Equal(t, model.AuditChange{Old: true, New: false}, decodeAuditChanges(t, logs[1])["active"])
+}
+
+func TestAuditLogRepository_ListAuditLogs_Filters(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuditLogRepository(db)
+	at := func(day int) time.Time { return time.Date(2025, 6, day, 17, 0, 0, 0, time.UTC) }
+	logs := []*model.AuditLog{
+		{Table: "employees", RecordID: 1, Action: model.AuditActionCreate, ActorID: 1, CreatedAt: at(1), Changes: "{}"},
+		{Table: "employees", RecordID: 1, Action: model.AuditActionUpdate, ActorID: 2, CreatedAt: at(10), Changes: "{}"},
+		{Table: "reimbursements", RecordID: 4, Action: model.AuditActionUpdate, ActorID: 2, CreatedAt: at(20), Changes: "{}"},
+		{Table: "employees", RecordID: 2, Action: model.AuditActionUpdate, ActorID: 2, CreatedAt: at(30), Changes: "{}"},
+	}
+	for _, log := range logs {
+		require.NoError(t, db.Create(log).Error)
+	}
+
+	ids := func(result []model.AuditLog) []uint {
+		var ids []uint
+		for _, log := range result {
+			ids = append(ids, log.ID)
+		}
+		return ids
+	}
+	actor := uint(2)
+	start := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC)
+
+	result, total, err := repo.ListAuditLogs(request.AuditLogFilter{}, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, []uint{logs[3].ID, logs[2].ID}, ids(result))
+
+	result, total, err = repo.ListAuditLogs(request.AuditLogFilter{ActorID: &actor, Table: "employees", Action: model.AuditActionUpdate}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []uint{logs[3].ID, logs[1].ID}, ids(result))
+
+	result, total, err = repo.ListAuditLogs(request.AuditLogFilter{StartDate: &start, EndDate: &end}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total, "the end date includes the whole day")
+	assert.Equal(t, []uint{logs[2].ID, logs[1].ID}, ids(result))
+}
+
+func TestAuditLogRepository_ListRecordHistory(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuditLogRepository(db)
+	at := func(day int) time.Time { return time.Date(2025, 6, day, 17, 0, 0, 0, time.UTC) }
+	logs := []*model.AuditLog{
+		{Table: "employees", RecordID: 1, Action: model.AuditActionCreate, ActorID: 1, CreatedAt: at(1), Changes: `{"basic_salary":{"new":5000000},"name":{"new":"Jane"}}`},
+		{Table: "employees", RecordID: 1, Action: model.AuditActionUpdate, ActorID: 2, CreatedAt: at(10), Changes: `{"name":{"old":"Jane","new":"Jane Doe"}}`},
+		{Table: "employees", RecordID: 2, Action: model.AuditActionUpdate, ActorID: 2, CreatedAt: at(12), Changes: `{"basic_salary":{"old":4000000,"new":4500000}}`},
+		{Table: "payslips", RecordID: 1, Action: model.AuditActionUpdate, ActorID: 2, CreatedAt: at(15), Changes: `{"status":{"old":"processed","new":"paid"}}`},
+		{Table: "employees", RecordID: 1, Action: model.AuditActionUpdate, ActorID: 3, CreatedAt: at(20), Changes: `{"basic_salary":{"old":5000000,"new":6500000}}`},
+	}
+	for _, log := range logs {
+		require.NoError(t, db.Create(log).Error)
+	}
+
+	result, total, err := repo.ListRecordHistory("employees", 1, request.AuditLogFilter{}, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, result, 2)
+	assert.Equal(t, logs[0].ID, result[0].ID)
+	assert.Equal(t, logs[1].ID, result[1].ID)
+
+	result, total, err = repo.ListRecordHistory("employees", 1, request.AuditLogFilter{Field: "basic_salary"}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, result, 2)
+	assert.Equal(t, logs[0].ID, result[0].ID)
+	assert.Equal(t, logs[4].ID, result[1].ID)
+	assert.Equal(t, uint(3), result[1].ActorID)
+}
@@ -1,10 +1,16 @@
 package repository
 
 import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
 	gormrepository "github.com/yourname/payslip-system/internal/helper/gorm-repository"
 	"gorm.io/gorm"
 )
 
+// pgUniqueViolation is the postgres error code of a unique constraint violation
+const pgUniqueViolation = "23505"
+
 type baseRepository struct {
 	gormrepository.TransactionRepository
 	db *gorm.DB
@@ -27,3 +33,16 @@ func NewBaseRepository(db *gorm.DB) BaseRepositoryInterface {
 func (br *baseRepository) GetDB() *gorm.DB {
 	return br.db
 }
+
+// isUniqueViolation reports whether err was caused by a unique index, on postgres or on a dialect that
+// translates its errors (sqlite in tests)
+func isUniqueViolation(db *gorm.DB, err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgUniqueViolation
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+	}
+	return false
+}
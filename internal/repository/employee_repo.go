@@ -1,7 +1,13 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
 	"golang.org/x/crypto/bcrypt"
@@ -21,34 +27,60 @@ type EmployeeRepository interface {
 	CreateEmployee(req request.CreateEmployeeRequest) (*model.Employee, error)
 	GetAllEmployees() ([]model.Employee, error)
 	GetAllActiveEmployees() ([]model.Employee, error)
+	ListEmployees(filter request.EmployeeFilter, offset int, limit int) ([]model.Employee, int64, error)
 	UpdateEmployee(employeeID string, req request.UpdateEmployeeRequest) (*model.Employee, error)
 	DeleteEmployee(employeeID string) error
 	GetEmployeeByID(id uint) (*model.Employee, error)
-	GetEmployeeByName(name string) (*model.Employee, error)
+	GetTokenVersion(employeeID uint) (int, error)
+	GetEmployeeByName(name string, includeDeleted bool) (*model.Employee, error)
+	GetActiveEmployeeByName(name string) (*model.Employee, error)
 	CreateEmployeeWithAudit(req request.CreateEmployeeRequest, auditDB *middleware.AuditableDB) (*model.Employee, error)
 	UpdateEmployeeWithAudit(employeeID string, req request.UpdateEmployeeRequest, auditDB *middleware.AuditableDB) (*model.Employee, error)
+	UpdateProfileWithAudit(employeeID uint, req request.UpdateProfileRequest, auditDB *middleware.AuditableDB) (*model.Employee, error)
 	DeleteEmployeeWithAudit(employeeID string, auditDB *middleware.AuditableDB) error
+	SetEmployeeActiveWithAudit(employeeID uint, active bool, auditDB *middleware.AuditableDB) (*model.Employee, error)
+	GetManagedEmployees(managerID uint) ([]res.ManagedEmployee, error)
+	GetDirectReports(managerID uint) ([]res.ManagedEmployee, error)
+	AssignManagerWithAudit(employeeID uint, managerID *uint, auditDB *middleware.AuditableDB) (*model.Employee, error)
+	GetDepartmentHeadcounts() ([]res.DepartmentHeadcount, error)
+	GetDeletedEmployeeByName(name string) (*model.Employee, error)
+	PurgeDeletedEmployees(deletedBefore time.Time) (int, error)
+	ImportEmployeesWithAudit(reqs []request.CreateEmployeeRequest, auditDB *middleware.AuditableDB) ([]*model.Employee, []error, error)
+	CreatePasswordResetToken(token *model.PasswordResetToken) error
+	ResetPasswordWithToken(tokenHash string, password string, now time.Time) (*model.Employee, error)
 }
 
+// ErrEmployeeNameTaken is returned when an active employee already uses the name
+var ErrEmployeeNameTaken = errors.New("employee name already exists")
+
+// ErrPasswordResetTokenInvalid is returned when a password reset token is unknown, used or expired,
+// or its employee can no longer log in
+var ErrPasswordResetTokenInvalid = errors.New("invalid or expired password reset token")
+
+// ErrEmployeeActiveUnchanged is returned when activating an active employee or deactivating an inactive one
+var ErrEmployeeActiveUnchanged = errors.New("employee is already in the requested state")
+
+// ErrManagerNotFound is returned when assigning a manager that does not exist
+var ErrManagerNotFound = errors.New("manager not found")
+
+// ErrManagerCycle is returned when an employee would report to themselves, directly or through their reports
+var ErrManagerCycle = errors.New("employee cannot report to themselves or to one of their reports")
+
+// managedEmployeesBatchSize limits the manager IDs queried at once when walking the hierarchy
+const managedEmployeesBatchSize = 500
+
 // CreateEmployee creates a new employee record in the database.
 func (e *employee) CreateEmployee(req request.CreateEmployeeRequest) (*model.Employee, error) {
-	// Hash the password before saving
-	hashedPassword, err := hashPassword(req.Password)
+	emp, err := e.newEmployeeFromRequest(req)
 	if err != nil {
 		return nil, err
 	}
-	emp := model.Employee{
-		Name:     req.Name,
-		Password: hashedPassword,
-		Role:     req.Role,
-		Active:   req.Active,
-	}
 
-	err = e.db.Create(&emp).Error
+	err = e.db.Create(emp).Error
 	if err != nil {
 		return nil, err
 	}
-	return &emp, nil
+	return emp, nil
 }
 
 func (e *employee) GetAllEmployees() ([]model.Employee, error) {
@@ -60,6 +92,35 @@ func (e *employee) GetAllEmployees() ([]model.Employee, error) {
 	return emps, nil
 }
 
+// likeEscaper escapes the LIKE wildcards of a search term, matched with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ListEmployees retrieves limit employees matching the filter from offset in ID order, together with
+// the total number of matching employees. Deleted employees are left out.
+func (e *employee) ListEmployees(filter request.EmployeeFilter, offset int, limit int) ([]model.Employee, int64, error) {
+	query := e.db.Model(&model.Employee{})
+	if filter.Query != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(strings.ToLower(filter.Query))+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emps []model.Employee
+	if err := query.Order("id").Offset(offset).Limit(limit).Find(&emps).Error; err != nil {
+		return nil, 0, err
+	}
+	return emps, total, nil
+}
+
 func (e *employee) GetAllActiveEmployees() ([]model.Employee, error) {
 	var emps []model.Employee
 	err := e.db.Debug().Where("active = ?", true).Find(&emps).Error
@@ -69,90 +130,197 @@ func (e *employee) GetAllActiveEmployees() ([]model.Employee, error) {
 	return emps, nil
 }
 
+// UpdateEmployee updates an employee without a user to audit, with the version check of UpdateEmployeeWithAudit
 func (e *employee) UpdateEmployee(employeeID string, req request.UpdateEmployeeRequest) (*model.Employee, error) {
+	return e.UpdateEmployeeWithAudit(employeeID, req, middleware.NewAuditableDB(e.db, 0))
+}
+
+func (e *employee) DeleteEmployee(employeeID string) error {
 	var emp model.Employee
 	err := e.db.Debug().Where("id = ?", employeeID).First(&emp).Error
 	if err != nil {
-		return nil, err
+		return err
 	}
-	// Hash the password before updating
-	hashedPassword, err := hashPassword(req.Password)
+	err = e.db.Delete(&emp).Error
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return nil
+}
 
-	emp.Name = req.Name
-	emp.Password = hashedPassword
-	emp.Role = req.Role
-	emp.Active = req.Active
-	err = e.db.Save(&emp).Error
+// GetEmployeeByID retrieves an employee by their ID
+func (e *employee) GetEmployeeByID(id uint) (*model.Employee, error) {
+	var emp model.Employee
+	err := e.db.Debug().Preload("PayGrade").Where("id = ?", id).First(&emp).Error
 	if err != nil {
 		return nil, err
 	}
 	return &emp, nil
 }
 
-func (e *employee) DeleteEmployee(employeeID string) error {
+// GetTokenVersion returns the token version of an employee, access tokens issued with a lower one are no longer valid
+func (e *employee) GetTokenVersion(employeeID uint) (int, error) {
 	var emp model.Employee
-	err := e.db.Debug().Where("id = ?", employeeID).First(&emp).Error
+	err := e.db.Select("id", "token_version").Where("id = ?", employeeID).First(&emp).Error
 	if err != nil {
-		return err
+		return 0, err
 	}
-	err = e.db.Delete(&emp).Error
+	return emp.TokenVersion, nil
+}
+
+// GetEmployeeByName retrieves an employee by their name. Soft-deleted employees are excluded by
+// GORM's default scope unless includeDeleted is set, in which case an employee that is not deleted
+// still wins over a deleted one that held the name before.
+func (e *employee) GetEmployeeByName(name string, includeDeleted bool) (*model.Employee, error) {
+	query := e.db
+	if includeDeleted {
+		query = query.Unscoped().Order("deleted_at IS NOT NULL").Order("id DESC")
+	}
+
+	var emp model.Employee
+	err := query.Where("name = ?", name).First(&emp).Error
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return &emp, nil
 }
 
-// GetEmployeeByID retrieves an employee by their ID
-func (e *employee) GetEmployeeByID(id uint) (*model.Employee, error) {
+// GetActiveEmployeeByName retrieves an active, not deleted employee by their name (for login)
+func (e *employee) GetActiveEmployeeByName(name string) (*model.Employee, error) {
 	var emp model.Employee
-	err := e.db.Debug().Where("id = ?", id).First(&emp).Error
+	err := e.db.Where("name = ? AND active = ?", name, true).First(&emp).Error
 	if err != nil {
 		return nil, err
 	}
 	return &emp, nil
 }
 
-// GetEmployeeByName retrieves an employee by their name (for login)
-func (e *employee) GetEmployeeByName(name string) (*model.Employee, error) {
+// GetDeletedEmployeeByName retrieves a soft-deleted employee by name
+func (e *employee) GetDeletedEmployeeByName(name string) (*model.Employee, error) {
 	var emp model.Employee
-	err := e.db.Debug().Where("name = ?", name).First(&emp).Error
+	err := e.db.Unscoped().Where("name = ? AND deleted_at IS NOT NULL", name).First(&emp).Error
 	if err != nil {
 		return nil, err
 	}
 	return &emp, nil
 }
 
+// PurgeDeletedEmployees hard-deletes the employees soft-deleted before the cutoff, together with their
+// attendance, overtime, reimbursements, timesheets, salary components, refresh tokens, password reset tokens and
+// API keys.
+// Employees with payslips or payroll run results are kept, payroll history is never purged. Returns the number purged.
+func (e *employee) PurgeDeletedEmployees(deletedBefore time.Time) (int, error) {
+	var ids []uint
+	err := e.db.Unscoped().Model(&model.Employee{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Where("id NOT IN (?)", e.db.Unscoped().Model(&model.Payslip{}).Select("employee_id")).
+		Where("id NOT IN (?)", e.db.Unscoped().Model(&model.PayrollRunResult{}).Select("employee_id")).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err = e.db.Transaction(func(tx *gorm.DB) error {
+		// Reports of a purged manager no longer have a manager
+		if err := tx.Model(&model.Employee{}).Unscoped().Where("manager_id IN ?", ids).Update("manager_id", nil).Error; err != nil {
+			return err
+		}
+		for _, record := range []interface{}{
+			&model.Attendance{}, &model.Overtime{}, &model.Reimbursement{}, &model.Timesheet{},
+			&model.EmployeeComponent{}, &model.RefreshToken{}, &model.PasswordResetToken{}, &model.APIKey{},
+		} {
+			if err := tx.Unscoped().Where("employee_id IN ?", ids).Delete(record).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&model.Employee{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
 // CreateEmployeeWithAudit creates a new employee record with audit fields
 func (e *employee) CreateEmployeeWithAudit(req request.CreateEmployeeRequest, auditDB *middleware.AuditableDB) (*model.Employee, error) {
-	// Hash the password before saving
-	hashedPassword, err := hashPassword(req.Password)
+	emp, err := e.newEmployeeFromRequest(req)
 	if err != nil {
 		return nil, err
 	}
-	emp := model.Employee{
-		Name:     req.Name,
-		Password: hashedPassword,
-		Role:     req.Role,
-		Active:   req.Active,
-	}
 
-	err = auditDB.Create(&emp).Error
+	err = auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		if err := middleware.NewAuditableDB(tx, auditDB.UserID).Create(emp).Error; err != nil {
+			return err
+		}
+		if req.Active {
+			return nil
+		}
+		// Create skips a false Active in favour of the column default, so it is written and audited after
+		return middleware.NewAuditableDB(tx.Model(emp), auditDB.UserID).Updates(map[string]interface{}{"active": false}).Error
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &emp, nil
+	return emp, nil
 }
 
-// UpdateEmployeeWithAudit updates an employee record with audit fields
+// ImportEmployeesWithAudit creates the employees in a single transaction. Each row gets its own
+// savepoint, so a failing row is rolled back and reported without undoing the other rows.
+// The returned slices follow reqs: the created employee, or the reason the row failed.
+func (e *employee) ImportEmployeesWithAudit(reqs []request.CreateEmployeeRequest, auditDB *middleware.AuditableDB) ([]*model.Employee, []error, error) {
+	created := make([]*model.Employee, len(reqs))
+	failed := make([]error, len(reqs))
+
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txRepo := &employee{db: tx}
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		for i, req := range reqs {
+			savepoint := fmt.Sprintf("import_row_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			var count int64
+			err := tx.Model(&model.Employee{}).Where("name = ?", req.Name).Count(&count).Error
+			if err == nil && count > 0 {
+				err = ErrEmployeeNameTaken
+			}
+			if err == nil {
+				created[i], err = txRepo.CreateEmployeeWithAudit(req, txAudit)
+			}
+			if err != nil {
+				failed[i] = err
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, failed, nil
+}
+
+// UpdateEmployeeWithAudit updates an employee record with audit fields, optional fields left out of the request
+// keeping their current value. A new password or role, or deactivating the employee like SetEmployeeActiveWithAudit,
+// bumps the token version and revokes the refresh tokens, so the sessions opened before the change stop working. An update sent with a version other than the stored one,
+// or racing another update, fails with middleware.ErrStaleVersion. The manager is checked like in
+// AssignManagerWithAudit.
 func (e *employee) UpdateEmployeeWithAudit(employeeID string, req request.UpdateEmployeeRequest, auditDB *middleware.AuditableDB) (*model.Employee, error) {
 	var emp model.Employee
 	err := e.db.Debug().Where("id = ?", employeeID).First(&emp).Error
 	if err != nil {
 		return nil, err
 	}
+	if req.Version != nil && *req.Version != emp.Version {
+		return nil, middleware.ErrStaleVersion
+	}
 
 	// Hash the password before updating
 	hashedPassword, err := hashPassword(req.Password)
@@ -160,18 +328,127 @@ func (e *employee) UpdateEmployeeWithAudit(employeeID string, req request.Update
 		return nil, err
 	}
 
+	passwordChanged := bcrypt.CompareHashAndPassword([]byte(emp.Password), []byte(req.Password)) != nil
+	deactivated := req.Active != nil && emp.Active && !*req.Active
+	revokeSessions := passwordChanged || emp.Role != req.Role || deactivated
+	if revokeSessions {
+		emp.TokenVersion++
+	}
+
 	emp.Name = req.Name
 	emp.Password = hashedPassword
 	emp.Role = req.Role
-	emp.Active = req.Active
+	// Optional fields left out keep their current value
+	if req.Active != nil {
+		emp.Active = *req.Active
+	}
+	if req.Email != nil {
+		emp.Email = *req.Email
+	}
+	if req.BasicSalary != nil {
+		emp.BasicSalary = *req.BasicSalary
+	}
+	if req.Currency != nil && *req.Currency != "" {
+		emp.Currency = *req.Currency
+	}
+	if req.HireDate != nil {
+		emp.HireDate = req.HireDate
+	}
+	if req.TerminationDate != nil {
+		emp.TerminationDate = req.TerminationDate
+	}
+	if req.ManagerID != nil {
+		if err := e.checkManager(emp.ID, req.ManagerID); err != nil {
+			return nil, err
+		}
+		emp.ManagerID = req.ManagerID
+	}
+	if req.Department != nil {
+		emp.Department = *req.Department
+	}
+	if req.PayGradeID != nil {
+		emp.PayGradeID = req.PayGradeID
+	}
+
+	err = saveEmployeeVersioned(&emp, revokeSessions, auditDB)
+	if err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
+// UpdateProfileWithAudit updates the name and password an employee changes on their own record, a new password
+// bumping the token version and revoking the refresh tokens
+func (e *employee) UpdateProfileWithAudit(employeeID uint, req request.UpdateProfileRequest, auditDB *middleware.AuditableDB) (*model.Employee, error) {
+	var emp model.Employee
+	err := e.db.Where("id = ?", employeeID).First(&emp).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		emp.Name = *req.Name
+	}
+	if req.Password != nil {
+		emp.Password, err = hashPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		emp.TokenVersion++
+	}
 
-	err = auditDB.Save(&emp).Error
+	err = saveEmployeeVersioned(&emp, req.Password != nil, auditDB)
 	if err != nil {
 		return nil, err
 	}
 	return &emp, nil
 }
 
+// SetEmployeeActiveWithAudit activates or deactivates an employee without deleting it. Deactivating bumps the token
+// version and revokes the refresh tokens, so the employee's sessions stop working right away. Fails with ErrEmployeeActiveUnchanged when the
+// employee is already in that state, and gorm.ErrRecordNotFound for a missing employee.
+func (e *employee) SetEmployeeActiveWithAudit(employeeID uint, active bool, auditDB *middleware.AuditableDB) (*model.Employee, error) {
+	var emp model.Employee
+	if err := e.db.First(&emp, employeeID).Error; err != nil {
+		return nil, err
+	}
+	if emp.Active == active {
+		return nil, ErrEmployeeActiveUnchanged
+	}
+
+	emp.Active = active
+	if !active {
+		emp.TokenVersion++
+	}
+	if err := saveEmployeeVersioned(&emp, !active, auditDB); err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
+// saveEmployeeVersioned saves the employee with a version check. When revokeSessions is set, for a bumped token
+// version, the employee's refresh tokens are revoked in the same transaction, otherwise a refresh token taken
+// before the change would keep issuing access tokens with the new version.
+func saveEmployeeVersioned(emp *model.Employee, revokeSessions bool, auditDB *middleware.AuditableDB) error {
+	return auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+		if err := txAudit.SaveVersioned(emp).Error; err != nil {
+			return err
+		}
+		if !revokeSessions {
+			return nil
+		}
+		return revokeRefreshTokens(tx, emp.ID, time.Now())
+	})
+}
+
+// revokeRefreshTokens revokes the refresh tokens of the employee that are still valid
+func revokeRefreshTokens(tx *gorm.DB, employeeID uint, now time.Time) error {
+	return tx.Model(&model.RefreshToken{}).
+		Where("employee_id = ? AND revoked_at IS NULL", employeeID).
+		Update("revoked_at", now).Error
+}
+
 // DeleteEmployeeWithAudit soft deletes an employee with audit fields
 func (e *employee) DeleteEmployeeWithAudit(employeeID string, auditDB *middleware.AuditableDB) error {
 	var emp model.Employee
@@ -187,6 +464,287 @@ func (e *employee) DeleteEmployeeWithAudit(employeeID string, auditDB *middlewar
 	return nil
 }
 
+// GetManagedEmployees walks the ManagerID hierarchy below a manager one level at a time and
+// returns every direct and indirect report with its depth (1 for direct reports). Levels are
+// fetched in batches instead of a recursive CTE so it runs the same on every database, and
+// employees already visited are skipped so a cyclic reporting line cannot loop forever.
+func (e *employee) GetManagedEmployees(managerID uint) ([]res.ManagedEmployee, error) {
+	managed := []res.ManagedEmployee{}
+	visited := map[uint]bool{managerID: true}
+	level := []uint{managerID}
+
+	for depth := 1; len(level) > 0; depth++ {
+		var next []uint
+		for start := 0; start < len(level); start += managedEmployeesBatchSize {
+			end := start + managedEmployeesBatchSize
+			if end > len(level) {
+				end = len(level)
+			}
+
+			var reports []model.Employee
+			err := e.db.Where("manager_id IN ?", level[start:end]).Order("id").Find(&reports).Error
+			if err != nil {
+				return nil, err
+			}
+
+			for _, report := range reports {
+				if visited[report.ID] {
+					continue
+				}
+				visited[report.ID] = true
+				managed = append(managed, res.ManagedEmployee{
+					ID:        report.ID,
+					Name:      report.Name,
+					Role:      report.Role,
+					Active:    report.Active,
+					ManagerID: report.ManagerID,
+					Depth:     depth,
+				})
+				next = append(next, report.ID)
+			}
+		}
+		level = next
+	}
+
+	return managed, nil
+}
+
+// GetDirectReports returns the employees whose manager is managerID, at depth 1
+func (e *employee) GetDirectReports(managerID uint) ([]res.ManagedEmployee, error) {
+	var reports []model.Employee
+	if err := e.db.Where("manager_id = ?", managerID).Order("id").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	direct := make([]res.ManagedEmployee, 0, len(reports))
+	for _, report := range reports {
+		direct = append(direct, res.ManagedEmployee{
+			ID:        report.ID,
+			Name:      report.Name,
+			Role:      report.Role,
+			Active:    report.Active,
+			ManagerID: report.ManagerID,
+			Depth:     1,
+		})
+	}
+	return direct, nil
+}
+
+// AssignManagerWithAudit sets the manager of an employee, nil removing it. The manager must exist and must not
+// be the employee or report to them, directly or indirectly, otherwise ErrManagerNotFound or ErrManagerCycle
+// is returned. A missing employee is gorm.ErrRecordNotFound.
+func (e *employee) AssignManagerWithAudit(employeeID uint, managerID *uint, auditDB *middleware.AuditableDB) (*model.Employee, error) {
+	var emp model.Employee
+	if err := e.db.First(&emp, employeeID).Error; err != nil {
+		return nil, err
+	}
+	if err := e.checkManager(emp.ID, managerID); err != nil {
+		return nil, err
+	}
+
+	emp.ManagerID = managerID
+	if err := auditDB.SaveVersioned(&emp).Error; err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
+// checkManager checks managerID can manage the employee by walking up the reporting line from the manager,
+// an employee already visited ending the walk so an existing cycle cannot loop forever
+func (e *employee) checkManager(employeeID uint, managerID *uint) error {
+	if managerID == nil {
+		return nil
+	}
+	if *managerID == employeeID {
+		return ErrManagerCycle
+	}
+
+	visited := map[uint]bool{}
+	for next := managerID; next != nil && !visited[*next]; {
+		visited[*next] = true
+
+		var manager model.Employee
+		err := e.db.Select("id", "manager_id").First(&manager, *next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) && next == managerID {
+			return ErrManagerNotFound
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if manager.ManagerID != nil && *manager.ManagerID == employeeID {
+			return ErrManagerCycle
+		}
+		next = manager.ManagerID
+	}
+	return nil
+}
+
+// GetDepartmentHeadcounts lists every distinct department with its active headcount.
+// Employees without a department are grouped under "unassigned".
+func (e *employee) GetDepartmentHeadcounts() ([]res.DepartmentHeadcount, error) {
+	normalized := e.db.Model(&model.Employee{}).
+		Select("COALESCE(NULLIF(TRIM(department), ''), ?) AS department, active", res.UnassignedDepartment)
+
+	departments := []res.DepartmentHeadcount{}
+	err := e.db.Table("(?) AS employees", normalized).
+		Select("department, SUM(CASE WHEN active = ? THEN 1 ELSE 0 END) AS headcount", true).
+		Group("department").
+		Order("department").
+		Scan(&departments).Error
+	if err != nil {
+		return nil, err
+	}
+	return departments, nil
+}
+
+// newEmployeeFromRequest builds an employee from the request, using the role template
+// (if one exists) as defaults. Values given on the request take precedence.
+func (e *employee) newEmployeeFromRequest(req request.CreateEmployeeRequest) (*model.Employee, error) {
+	// Hash the password before saving
+	hashedPassword, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	emp := &model.Employee{
+		Name:            req.Name,
+		Password:        hashedPassword,
+		Role:            req.Role,
+		Active:          req.Active,
+		Email:           req.Email,
+		BasicSalary:     req.BasicSalary,
+		PayGradeID:      req.PayGradeID,
+		Currency:        req.Currency,
+		HireDate:        req.HireDate,
+		TerminationDate: req.TerminationDate,
+		ManagerID:       req.ManagerID,
+		Department:      req.Department,
+	}
+
+	var template model.RoleTemplate
+	err = e.db.Preload("Components").Where("role = ?", req.Role).First(&template).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err == nil {
+		overtimeEligible := template.OvertimeEligible
+		emp.StandardHours = template.StandardHours
+		emp.OvertimeEligible = &overtimeEligible
+		for _, component := range template.Components {
+			emp.Components = append(emp.Components, model.EmployeeComponent{
+				Name:   component.Name,
+				Kind:   component.Kind,
+				Amount: component.Amount,
+				Active: true,
+			})
+		}
+	}
+
+	// Employee specific overrides
+	if req.StandardHours != nil {
+		emp.StandardHours = *req.StandardHours
+	}
+	if req.OvertimeEligible != nil {
+		overtimeEligible := *req.OvertimeEligible
+		emp.OvertimeEligible = &overtimeEligible
+	}
+	for _, component := range req.Components {
+		emp.Components = mergeComponent(emp.Components, component)
+	}
+
+	return emp, nil
+}
+
+// mergeComponent replaces the component with the same name, or appends it when new.
+func mergeComponent(components []model.EmployeeComponent, override request.ComponentRequest) []model.EmployeeComponent {
+	for i := range components {
+		if components[i].Name == override.Name {
+			components[i].Kind = model.ComponentKind(override.Kind)
+			components[i].Amount = override.Amount
+			return components
+		}
+	}
+	return append(components, model.EmployeeComponent{
+		Name:   override.Name,
+		Kind:   model.ComponentKind(override.Kind),
+		Amount: override.Amount,
+		Active: true,
+	})
+}
+
+// CreatePasswordResetToken stores a password reset token, the unused tokens the employee requested before stop working
+func (e *employee) CreatePasswordResetToken(token *model.PasswordResetToken) error {
+	return e.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.PasswordResetToken{}).
+			Where("employee_id = ? AND used_at IS NULL", token.EmployeeID).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// ResetPasswordWithToken uses a password reset token to set the password of its employee and revokes the
+// employee's refresh tokens and access tokens, so sessions opened with the old password end. Fails with ErrPasswordResetTokenInvalid
+// when the token is unknown, used, expired at now or belongs to a deactivated or deleted employee.
+func (e *employee) ResetPasswordWithToken(tokenHash string, password string, now time.Time) (*model.Employee, error) {
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	var emp model.Employee
+	err = e.db.Transaction(func(tx *gorm.DB) error {
+		var token model.PasswordResetToken
+		if err := tx.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPasswordResetTokenInvalid
+			}
+			return err
+		}
+		if token.IsUsed() || token.IsExpired(now) {
+			return ErrPasswordResetTokenInvalid
+		}
+
+		if err := tx.Where("id = ? AND active = ?", token.EmployeeID, true).First(&emp).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPasswordResetTokenInvalid
+			}
+			return err
+		}
+
+		// Mark the token used first, a concurrent reset with the same token updates no row
+		result := tx.Model(&model.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPasswordResetTokenInvalid
+		}
+
+		// Access tokens issued before the reset stop working along with the refresh tokens
+		err := tx.Model(&emp).Updates(map[string]interface{}{
+			"password":      hashed,
+			"token_version": gorm.Expr("token_version + 1"),
+			"version":       gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return revokeRefreshTokens(tx, emp.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
 // hashPassword hashes a plain password using bcrypt.
 func hashPassword(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
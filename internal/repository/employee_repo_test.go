@@ -0,0 +1,749 @@
+// Package repository contains unit tests for the employee repository functionality.
+//
+// CreateEmployeeWithAudit tests cover:
+// 1. Role template defaults applied to a new employee
+// 2. Employee specific overrides taking precedence over the template
+// 3. Template changes not affecting existing employees
+//
+// GetEmployeeByName tests cover:
+// 1. A deleted employee with a reused name resolving to the active one, with and without deleted rows
+// 2. Login lookups skipping deleted and deactivated employees
+//
+// ImportEmployeesWithAudit tests cover:
+// 1. Failing rows rolled back to their savepoint while the other rows are kept with the importer recorded
+//
+// GetManagedEmployees tests cover:
+// 1. Every level of a multi-level hierarchy returned with its depth
+// 2. A cyclic reporting line terminating with each employee listed once
+//
+// GetDirectReports tests cover:
+// 1. Only the first level below the manager returned
+//
+// AssignManagerWithAudit tests cover:
+// 1. Assigning and removing a manager, the version bumped
+// 2. The employee themselves, a direct or indirect report and a missing manager refused without a change
+// 3. An admin update setting a cyclic manager refused the same way
+//
+// GetDepartmentHeadcounts tests cover:
+// 1. Distinct departments with active headcount, unset departments grouped as unassigned
+//
+// PurgeDeletedEmployees tests cover:
+// 1. Employees deleted before the cutoff removed with their records and API keys, recent, active and paid employees kept
+//
+// ResetPasswordWithToken tests cover:
+// 1. Deactivated employees and expired tokens rejected without using the token or changing the password
+// 2. A successful reset bumping the token version
+//
+// Token version tests cover:
+// 1. A new password or role from an admin update or the employee's profile bumping the version, other edits not
+// 2. An admin update deactivating the employee bumping the version and revoking the refresh tokens, one leaving out active keeping it
+//
+// ListEmployees tests cover:
+// 1. Case-insensitive name search with literal wildcards, role and active filters, paging and the total
+//
+// Optimistic locking tests cover:
+// 1. Two concurrent updates made on the same version, the second failing with ErrStaleVersion instead of overwriting
+// 2. Every update bumping the version, an update sent with an older version refused
+// 3. UpdateEmployee refusing an older version like UpdateEmployeeWithAudit
+//
+// Partial update tests cover:
+// 1. Optional fields left out of an admin update keeping their stored values, those sent replacing them
+package repository
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+// createTestRoleTemplate creates a role template with a transport allowance and an insurance deduction
+func createTestRoleTemplate(t testing.TB, db *gorm.DB, role string) *model.RoleTemplate {
+	repo := NewRoleTemplateRepository(db)
+	template, err := repo.CreateRoleTemplateWithAudit(request.CreateRoleTemplateRequest{
+		Role:             role,
+		StandardHours:    7,
+		OvertimeEligible: false,
+		Components: []request.ComponentRequest{
+			{Name: "transport", Kind: string(model.ComponentAllowance), Amount: 300000},
+			{Name: "insurance", Kind: string(model.ComponentDeduction), Amount: 100000},
+		},
+	}, middleware.NewAuditableDB(db, 1))
+	require.NoError(t, err)
+	return template
+}
+
+// findComponent returns the component with the given name, or nil
+func findComponent(components []model.EmployeeComponent, name string) *model.EmployeeComponent {
+	for i := range components {
+		if components[i].Name == name {
+			return &components[i]
+		}
+	}
+	return nil
+}
+
+func TestEmployeeRepository_CreateEmployeeWithAudit_AppliesRoleTemplate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	createTestRoleTemplate(t, db, "employee")
+
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{
+		Name:     "Jane Doe",
+		Password: "secret",
+		Role:     "employee",
+		Active:   true,
+	}, middleware.NewAuditableDB(db, 1))
+	require.NoError(t, err)
+
+	var stored model.Employee
+	require.NoError(t, db.Preload("Components").First(&stored, employee.ID).Error)
+	assert.Equal(t, 7, stored.StandardHours)
+	assert.False(t, stored.IsOvertimeEligible())
+	require.Len(t, stored.Components, 2)
+
+	transport := findComponent(stored.Components, "transport")
+	require.NotNil(t, transport)
+	assert.Equal(t, model.ComponentAllowance, transport.Kind)
+	assert.Equal(t, 300000.0, transport.Amount)
+	assert.True(t, transport.Active)
+
+	insurance := findComponent(stored.Components, "insurance")
+	require.NotNil(t, insurance)
+	assert.True(t, insurance.IsDeduction())
+}
+
+func TestEmployeeRepository_CreateEmployeeWithAudit_OverridesTakePrecedence(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	createTestRoleTemplate(t, db, "employee")
+
+	standardHours := 6
+	overtimeEligible := true
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{
+		Name:             "Jane Doe",
+		Password:         "secret",
+		Role:             "employee",
+		Active:           true,
+		StandardHours:    &standardHours,
+		OvertimeEligible: &overtimeEligible,
+		Components: []request.ComponentRequest{
+			{Name: "transport", Kind: string(model.ComponentAllowance), Amount: 500000},
+			{Name: "meal", Kind: string(model.ComponentAllowance), Amount: 200000},
+		},
+	}, middleware.NewAuditableDB(db, 1))
+	require.NoError(t, err)
+
+	var stored model.Employee
+	require.NoError(t, db.Preload("Components").First(&stored, employee.ID).Error)
+	assert.Equal(t, 6, stored.StandardHours)
+	assert.True(t, stored.IsOvertimeEligible())
+	require.Len(t, stored.Components, 3)
+	assert.Equal(t, 500000.0, findComponent(stored.Components, "transport").Amount)
+	assert.Equal(t, 200000.0, findComponent(stored.Components, "meal").Amount)
+	assert.Equal(t, 100000.0, findComponent(stored.Components, "insurance").Amount)
+}
+
+func TestEmployeeRepository_CreateEmployeeWithAudit_TemplateUpdateKeepsExistingEmployees(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	templateRepo := NewRoleTemplateRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+	createTestRoleTemplate(t, db, "employee")
+
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{
+		Name:     "Jane Doe",
+		Password: "secret",
+		Role:     "employee",
+		Active:   true,
+	}, auditDB)
+	require.NoError(t, err)
+
+	_, err = templateRepo.UpdateRoleTemplateWithAudit("employee", request.UpdateRoleTemplateRequest{
+		StandardHours:    8,
+		OvertimeEligible: true,
+		Components: []request.ComponentRequest{
+			{Name: "transport", Kind: string(model.ComponentAllowance), Amount: 900000},
+		},
+	}, auditDB)
+	require.NoError(t, err)
+
+	var stored model.Employee
+	require.NoError(t, db.Preload("Components").First(&stored, employee.ID).Error)
+	assert.Equal(t, 7, stored.StandardHours)
+	assert.False(t, stored.IsOvertimeEligible())
+	require.Len(t, stored.Components, 2)
+	assert.Equal(t, 300000.0, findComponent(stored.Components, "transport").Amount)
+}
+
+// createTestReport creates an employee reporting to the given manager
+func createTestReport(t testing.TB, db *gorm.DB, id uint, managerID *uint) *model.Employee {
+	emp := &model.Employee{
+		DefaultAttribute: model.DefaultAttribute{ID: id},
+		Name:             "Employee",
+		Password:         "hashed",
+		Role:             "employee",
+		Active:           true,
+		ManagerID:        managerID,
+	}
+	require.NoError(t, db.Create(emp).Error)
+	return emp
+}
+
+// managedDepths maps each managed employee to its depth
+func managedDepths(managed []res.ManagedEmployee) map[uint]int {
+	depths := make(map[uint]int)
+	for _, m := range managed {
+		depths[m.ID] = m.Depth
+	}
+	return depths
+}
+
+func TestEmployeeRepository_GetManagedEmployees_MultiLevel(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+
+	// 1 manages 2 and 3, 2 manages 4, 4 manages 5, 6 is outside the subtree
+	head := createTestReport(t, db, 1, nil)
+	lead := createTestReport(t, db, 2, &head.ID)
+	createTestReport(t, db, 3, &head.ID)
+	member := createTestReport(t, db, 4, &lead.ID)
+	createTestReport(t, db, 5, &member.ID)
+	createTestReport(t, db, 6, nil)
+
+	managed, err := repo.GetManagedEmployees(head.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int{2: 1, 3: 1, 4: 2, 5: 3}, managedDepths(managed))
+
+	managed, err = repo.GetManagedEmployees(member.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int{5: 1}, managedDepths(managed))
+
+	managed, err = repo.GetManagedEmployees(6)
+	require.NoError(t, err)
+	assert.Empty(t, managed)
+}
+
+func TestEmployeeRepository_GetManagedEmployees_CycleSafe(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+
+	// 1 -> 2 -> 3 -> 1
+	first := createTestReport(t, db, 1, nil)
+	second := createTestReport(t, db, 2, &first.ID)
+	third := createTestReport(t, db, 3, &second.ID)
+	require.NoError(t, db.Model(first).Update("manager_id", third.ID).Error)
+
+	managed, err := repo.GetManagedEmployees(first.ID)
+	require.NoError(t, err)
+	require.Len(t, managed, 2)
+	assert.Equal(t, map[uint]int{2: 1, 3: 2}, managedDepths(managed))
+}
+
+func TestEmployeeRepository_GetDirectReports(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+
+	// 1 manages 2 and 3, 2 manages 4
+	head := createTestReport(t, db, 1, nil)
+	lead := createTestReport(t, db, 2, &head.ID)
+	createTestReport(t, db, 3, &head.ID)
+	createTestReport(t, db, 4, &lead.ID)
+
+	reports, err := repo.GetDirectReports(head.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int{2: 1, 3: 1}, managedDepths(reports))
+
+	reports, err = repo.GetDirectReports(4)
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+}
+
+func TestEmployeeRepository_AssignManagerWithAudit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 9)
+
+	// 1 manages 2, 2 manages 3, 4 has no manager
+	head := createTestReport(t, db, 1, nil)
+	lead := createTestReport(t, db, 2, &head.ID)
+	member := createTestReport(t, db, 3, &lead.ID)
+	createTestReport(t, db, 4, nil)
+
+	assigned, err := repo.AssignManagerWithAudit(4, &member.ID, auditDB)
+	require.NoError(t, err)
+	require.NotNil(t, assigned.ManagerID)
+	assert.Equal(t, member.ID, *assigned.ManagerID)
+	assert.Equal(t, 1, assigned.Version)
+
+	for name, managerID := range map[string]uint{"self": 1, "direct report": 2, "indirect report": 4} {
+		_, err := repo.AssignManagerWithAudit(head.ID, &managerID, auditDB)
+		assert.ErrorIs(t, err, ErrManagerCycle, name)
+	}
+	missing := uint(42)
+	_, err = repo.AssignManagerWithAudit(head.ID, &missing, auditDB)
+	assert.ErrorIs(t, err, ErrManagerNotFound)
+
+	stored, err := repo.GetEmployeeByID(head.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.ManagerID)
+
+	removed, err := repo.AssignManagerWithAudit(4, nil, auditDB)
+	require.NoError(t, err)
+	assert.Nil(t, removed.ManagerID)
+
+	_, err = repo.UpdateEmployeeWithAudit("1", request.UpdateEmployeeRequest{
+		Name: "Employee", Password: "secret123", Role: "employee", ManagerID: &member.ID,
+	}, auditDB)
+	assert.ErrorIs(t, err, ErrManagerCycle)
+}
+
+func TestEmployeeRepository_GetDepartmentHeadcounts(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+
+	seed := []struct {
+		department string
+		active     bool
+	}{
+		{"Engineering", true},
+		{"Engineering", true},
+		{"Engineering", false},
+		{"Finance", true},
+		{"Sales", false},
+		{"", true},
+		{"  ", true},
+	}
+	for i, s := range seed {
+		emp := createTestReport(t, db, uint(i+1), nil)
+		require.NoError(t, db.Model(emp).Updates(map[string]interface{}{"department": s.department, "active": s.active}).Error)
+	}
+
+	departments, err := repo.GetDepartmentHeadcounts()
+	require.NoError(t, err)
+	assert.Equal(t, []res.DepartmentHeadcount{
+		{Department: "Engineering", Headcount: 2},
+		{Department: "Finance", Headcount: 1},
+		{Department: "Sales", Headcount: 0},
+		{Department: res.UnassignedDepartment, Headcount: 2},
+	}, departments)
+}
+
+func TestEmployeeRepository_PurgeDeletedEmployees(t *testing.T) {
+	db := setupTestDB(t)
+	// Child rows left behind would fail the purge on databases enforcing the employee foreign keys
+	require.NoError(t, db.Exec("PRAGMA foreign_keys = ON").Error)
+	repo := NewEmployeeRepository(db)
+	now := time.Now()
+
+	// 1 deleted long ago, 2 deleted recently, 3 deleted long ago with a payslip, 4 active reporting to 1
+	old := createTestReport(t, db, 1, nil)
+	recent := createTestReport(t, db, 2, nil)
+	paid := createTestReport(t, db, 3, nil)
+	createTestReport(t, db, 4, &old.ID)
+	require.NoError(t, db.Create(&model.Attendance{EmployeeID: old.ID, Checkin: now, Date: now, Status: "present"}).Error)
+	require.NoError(t, db.Create(&model.EmployeeComponent{EmployeeID: old.ID, Name: "transport", Kind: model.ComponentAllowance, Amount: 100000, Active: true}).Error)
+	require.NoError(t, db.Create(&model.APIKey{EmployeeID: old.ID, Name: "sync", Prefix: "psk_00000000", KeyHash: "hash", Scopes: model.APIKeyScopeRead}).Error)
+	require.NoError(t, db.Create(&model.Payslip{EmployeeID: paid.ID, PayPeriodStart: now, PayPeriodEnd: now, ProcessedAt: now, Status: model.PayslipStatusProcessed}).Error)
+
+	for id, deletedAt := range map[uint]time.Time{old.ID: now.AddDate(0, 0, -40), recent.ID: now.AddDate(0, 0, -5), paid.ID: now.AddDate(0, 0, -40)} {
+		require.NoError(t, db.Unscoped().Model(&model.Employee{}).Where("id = ?", id).Update("deleted_at", deletedAt).Error)
+	}
+
+	purged, err := repo.PurgeDeletedEmployees(now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	var remaining []uint
+	require.NoError(t, db.Unscoped().Model(&model.Employee{}).Order("id").Pluck("id", &remaining).Error)
+	assert.Equal(t, []uint{recent.ID, paid.ID, 4}, remaining)
+
+	var records int64
+	require.NoError(t, db.Unscoped().Model(&model.Attendance{}).Where("employee_id = ?", old.ID).Count(&records).Error)
+	assert.Zero(t, records)
+	require.NoError(t, db.Unscoped().Model(&model.EmployeeComponent{}).Where("employee_id = ?", old.ID).Count(&records).Error)
+	assert.Zero(t, records)
+	require.NoError(t, db.Unscoped().Model(&model.APIKey{}).Where("employee_id = ?", old.ID).Count(&records).Error)
+	assert.Zero(t, records)
+
+	report, err := repo.GetEmployeeByID(4)
+	require.NoError(t, err)
+	assert.Nil(t, report.ManagerID)
+
+	// Nothing left to purge
+	purged, err = repo.PurgeDeletedEmployees(now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+}
+
+func TestEmployeeRepository_ImportEmployeesWithAudit_RollsBackFailingRowsOnly(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	createTestRoleTemplate(t, db, "employee")
+
+	created, failed, err := repo.ImportEmployeesWithAudit([]request.CreateEmployeeRequest{
+		{Name: "Jane Doe", Password: "secret", Role: "employee", Active: true, BasicSalary: 5000000},
+		{Name: "John Roe", Password: "secret", Role: "intern", Active: true},
+		{Name: "Jane Doe", Password: "secret", Role: "employee", Active: true},
+		{Name: "Mary Major", Password: "secret", Role: "employee", Active: false},
+	}, middleware.NewAuditableDB(db, 7))
+	require.NoError(t, err)
+	require.Len(t, created, 4)
+	require.Len(t, failed, 4)
+
+	assert.NoError(t, failed[0])
+	assert.Error(t, failed[1], "role violates the check constraint")
+	assert.Nil(t, created[1])
+	assert.ErrorIs(t, failed[2], ErrEmployeeNameTaken)
+	assert.Nil(t, created[2])
+	assert.NoError(t, failed[3])
+
+	var stored []model.Employee
+	require.NoError(t, db.Order("id").Find(&stored).Error)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "Jane Doe", stored[0].Name)
+	assert.Equal(t, created[0].ID, stored[0].ID)
+	assert.Equal(t, "Mary Major", stored[1].Name)
+	require.NotNil(t, stored[0].CreatedBy)
+	assert.Equal(t, uint(7), *stored[0].CreatedBy)
+
+	// Template components of the failed rows are rolled back too
+	var components int64
+	require.NoError(t, db.Model(&model.EmployeeComponent{}).Count(&components).Error)
+	assert.Equal(t, int64(4), components)
+}
+
+// createNamedEmployees stores a deleted Jane Doe (1) and her active namesake (2), plus a deactivated John Roe (3)
+func createNamedEmployees(t *testing.T, db *gorm.DB) {
+	deleted := &model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 1}, Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true}
+	require.NoError(t, db.Create(deleted).Error)
+	require.NoError(t, db.Delete(deleted).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "Jane Doe", Password: "hashed", Role: "manager", Active: true}).Error)
+	require.NoError(t, db.Create(&model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 3}, Name: "John Roe", Password: "hashed", Role: "employee", Active: true}).Error)
+	require.NoError(t, db.Model(&model.Employee{}).Where("id = ?", 3).Update("active", false).Error)
+}
+
+func TestEmployeeRepository_GetEmployeeByName_ReusedNameResolvesToActive(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	createNamedEmployees(t, db)
+
+	for _, includeDeleted := range []bool{false, true} {
+		employee, err := repo.GetEmployeeByName("Jane Doe", includeDeleted)
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), employee.ID, "includeDeleted=%v", includeDeleted)
+		assert.False(t, employee.DeletedAt.Valid)
+	}
+
+	// Only the deleted employee holds the name
+	require.NoError(t, db.Delete(&model.Employee{}, 2).Error)
+	_, err := repo.GetEmployeeByName("Jane Doe", false)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	employee, err := repo.GetEmployeeByName("Jane Doe", true)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), employee.ID, "the most recently created deleted employee")
+}
+
+func TestEmployeeRepository_GetActiveEmployeeByName_SkipsDeletedAndDeactivated(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	createNamedEmployees(t, db)
+
+	employee, err := repo.GetActiveEmployeeByName("Jane Doe")
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), employee.ID)
+
+	_, err = repo.GetActiveEmployeeByName("John Roe")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, db.Delete(&model.Employee{}, 2).Error)
+	_, err = repo.GetActiveEmployeeByName("Jane Doe")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestEmployeeRepository_ResetPasswordWithToken_RejectsDeactivatedAndExpired(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	now := time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreatePasswordResetToken(&model.PasswordResetToken{EmployeeID: employee.ID, TokenHash: "hash", ExpiresAt: now.Add(time.Hour)}))
+
+	// Expired at now
+	_, err := repo.ResetPasswordWithToken("hash", "newsecret", now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrPasswordResetTokenInvalid)
+
+	// Deactivated since the token was requested
+	require.NoError(t, db.Model(employee).Update("active", false).Error)
+	_, err = repo.ResetPasswordWithToken("hash", "newsecret", now)
+	assert.ErrorIs(t, err, ErrPasswordResetTokenInvalid)
+
+	var token model.PasswordResetToken
+	require.NoError(t, db.First(&token).Error)
+	assert.False(t, token.IsUsed())
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.Equal(t, employee.Password, stored.Password)
+
+	// Active again the token still works
+	require.NoError(t, db.Model(employee).Update("active", true).Error)
+	updated, err := repo.ResetPasswordWithToken("hash", "newsecret", now)
+	require.NoError(t, err)
+	assert.NotEqual(t, employee.Password, updated.Password)
+
+	version, err := repo.GetTokenVersion(employee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+}
+
+func TestEmployeeRepository_TokenVersion(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 9)
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Active: true}, auditDB)
+	require.NoError(t, err)
+	id := strconv.FormatUint(uint64(employee.ID), 10)
+
+	assertVersion := func(want int) {
+		t.Helper()
+		version, err := repo.GetTokenVersion(employee.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, version)
+	}
+	assertVersion(0)
+
+	// Same password and role, e.g. a new department
+	sales := "Sales"
+	_, err = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Department: &sales}, auditDB)
+	require.NoError(t, err)
+	assertVersion(0)
+
+	_, err = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "manager"}, auditDB)
+	require.NoError(t, err)
+	assertVersion(1)
+
+	_, err = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "changed", Role: "manager"}, auditDB)
+	require.NoError(t, err)
+	assertVersion(2)
+
+	name, password := "Johnny Doe", "mine"
+	_, err = repo.UpdateProfileWithAudit(employee.ID, request.UpdateProfileRequest{Name: &name}, auditDB)
+	require.NoError(t, err)
+	assertVersion(2)
+	_, err = repo.UpdateProfileWithAudit(employee.ID, request.UpdateProfileRequest{Password: &password}, auditDB)
+	require.NoError(t, err)
+	assertVersion(3)
+
+	// Left out, active keeps its value; deactivating revokes the sessions like SetEmployeeActiveWithAudit
+	require.NoError(t, db.Create(&model.RefreshToken{EmployeeID: employee.ID, TokenHash: "hash", FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	updated, err := repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "Johnny Doe", Password: "mine", Role: "manager"}, auditDB)
+	require.NoError(t, err)
+	assert.True(t, updated.Active)
+	assertVersion(3)
+
+	inactive := false
+	updated, err = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "Johnny Doe", Password: "mine", Role: "manager", Active: &inactive}, auditDB)
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+	assertVersion(4)
+	var token model.RefreshToken
+	require.NoError(t, db.First(&token).Error)
+	assert.NotNil(t, token.RevokedAt)
+
+	_, err = repo.GetTokenVersion(999)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestEmployeeRepository_ListEmployees_FiltersAndPages(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	employees := []model.Employee{
+		{Name: "Jane Doe", Password: "hashed", Role: "employee", Active: true},
+		{Name: "JANET Smith", Password: "hashed", Role: "manager", Active: true},
+		{Name: "Janet_Roe", Password: "hashed", Role: "employee"},
+		{Name: "100% Jan", Password: "hashed", Role: "admin", Active: true},
+		{Name: "Jan Gone", Password: "hashed", Role: "employee", Active: true},
+	}
+	require.NoError(t, db.Create(&employees).Error)
+	require.NoError(t, db.Model(&employees[2]).Update("active", false).Error) // false is taken as unset on create
+	require.NoError(t, db.Delete(&employees[4]).Error)
+
+	names := func(filter request.EmployeeFilter, offset, limit int) ([]string, int64) {
+		t.Helper()
+		found, total, err := repo.ListEmployees(filter, offset, limit)
+		require.NoError(t, err)
+		var names []string
+		for _, employee := range found {
+			names = append(names, employee.Name)
+		}
+		return names, total
+	}
+
+	got, total := names(request.EmployeeFilter{Query: "jan"}, 0, 10)
+	assert.Equal(t, []string{"Jane Doe", "JANET Smith", "Janet_Roe", "100% Jan"}, got, "case-insensitive, deleted left out")
+	assert.Equal(t, int64(4), total)
+
+	// Wildcards in the search term match literally
+	got, _ = names(request.EmployeeFilter{Query: "t_"}, 0, 10)
+	assert.Equal(t, []string{"Janet_Roe"}, got)
+	got, _ = names(request.EmployeeFilter{Query: "%"}, 0, 10)
+	assert.Equal(t, []string{"100% Jan"}, got)
+
+	inactive := false
+	got, _ = names(request.EmployeeFilter{Role: "employee", Active: &inactive}, 0, 10)
+	assert.Equal(t, []string{"Janet_Roe"}, got)
+
+	// The total counts every match, not only the page
+	got, total = names(request.EmployeeFilter{Query: "jan"}, 1, 2)
+	assert.Equal(t, []string{"JANET Smith", "Janet_Roe"}, got)
+	assert.Equal(t, int64(4), total)
+}
+
+func TestEmployeeRepository_UpdateEmployeeWithAudit_ConcurrentUpdates(t *testing.T) {
+	db := setupTestDB(t)
+	// Every connection to :memory: opens its own database, so the updates must share one
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	repo := NewEmployeeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 9)
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Active: true}, auditDB)
+	require.NoError(t, err)
+	id := strconv.FormatUint(uint64(employee.ID), 10)
+
+	// Both admins opened the employee at version 0
+	read := employee.Version
+	departments := []string{"Sales", "Finance"}
+	errs := make([]error, len(departments))
+	var wg sync.WaitGroup
+	for i, department := range departments {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Department: &department, Version: &read}, auditDB)
+		}()
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		if err == nil {
+			assert.Equal(t, -1, winner, "only one update may succeed")
+			winner = i
+			continue
+		}
+		assert.ErrorIs(t, err, middleware.ErrStaleVersion)
+	}
+	require.NotEqual(t, -1, winner, "one update must succeed")
+
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.Equal(t, departments[winner], stored.Department)
+	assert.Equal(t, 1, stored.Version)
+}
+
+func TestEmployeeRepository_Version(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 9)
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Active: true}, auditDB)
+	require.NoError(t, err)
+	assert.Equal(t, 0, employee.Version)
+	id := strconv.FormatUint(uint64(employee.ID), 10)
+
+	// Without a version the update applies to whatever is stored
+	sales, finance := "Sales", "Finance"
+	updated, err := repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Department: &sales}, auditDB)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Version)
+
+	name := "Johnny Doe"
+	updated, err = repo.UpdateProfileWithAudit(employee.ID, request.UpdateProfileRequest{Name: &name}, auditDB)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+
+	updated, err = repo.SetEmployeeActiveWithAudit(employee.ID, false, auditDB)
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated.Version)
+
+	stale := 1
+	_, err = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Department: &finance, Version: &stale}, auditDB)
+	assert.ErrorIs(t, err, middleware.ErrStaleVersion)
+
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.Equal(t, "Johnny Doe", stored.Name)
+	assert.Equal(t, "Sales", stored.Department)
+	assert.Equal(t, 3, stored.Version)
+}
+
+func TestEmployeeRepository_UpdateEmployee_StaleVersion(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Active: true}, middleware.NewAuditableDB(db, 1))
+	require.NoError(t, err)
+	id := strconv.FormatUint(uint64(employee.ID), 10)
+
+	sales, finance := "Sales", "Finance"
+	updated, err := repo.UpdateEmployee(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Department: &sales, Version: &employee.Version})
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Version)
+
+	_, err = repo.UpdateEmployee(id, request.UpdateEmployeeRequest{Name: "John Doe", Password: "secret", Role: "employee", Department: &finance, Version: &employee.Version})
+	assert.ErrorIs(t, err, middleware.ErrStaleVersion)
+
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.Equal(t, "Sales", stored.Department)
+}
+
+func TestEmployeeRepository_UpdateEmployeeWithAudit_KeepsOmittedFields(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+
+	manager, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{Name: "Mary Manager", Password: "secret", Role: "manager", Active: true}, auditDB)
+	require.NoError(t, err)
+	hired := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	employee, err := repo.CreateEmployeeWithAudit(request.CreateEmployeeRequest{
+		Name: "John Doe", Password: "secret", Role: "employee", Active: true, Email: "john@example.com",
+		ManagerID: &manager.ID, Department: "Sales", BasicSalary: 5000000, Currency: "USD", HireDate: &hired,
+	}, auditDB)
+	require.NoError(t, err)
+	id := strconv.FormatUint(uint64(employee.ID), 10)
+
+	// Only the required fields, e.g. a rename
+	_, err = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "Johnny Doe", Password: "secret", Role: "employee"}, auditDB)
+	require.NoError(t, err)
+
+	var stored model.Employee
+	require.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.Equal(t, "Johnny Doe", stored.Name)
+	assert.Equal(t, "john@example.com", stored.Email)
+	require.NotNil(t, stored.ManagerID)
+	assert.Equal(t, manager.ID, *stored.ManagerID)
+	assert.Equal(t, "Sales", stored.Department)
+	assert.Equal(t, 5000000.0, stored.BasicSalary)
+	assert.Equal(t, "USD", stored.Currency)
+	require.NotNil(t, stored.HireDate)
+	assert.True(t, hired.Equal(*stored.HireDate))
+
+	// Fields sent replace the stored values
+	salary, department := 6000000.0, "Finance"
+	_, err = repo.UpdateEmployeeWithAudit(id, request.UpdateEmployeeRequest{Name: "Johnny Doe", Password: "secret", Role: "employee", BasicSalary: &salary, Department: &department}, auditDB)
+	require.NoError(t, err)
+	require.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.Equal(t, 6000000.0, stored.BasicSalary)
+	assert.Equal(t, "Finance", stored.Department)
+	assert.Equal(t, "john@example.com", stored.Email)
+}
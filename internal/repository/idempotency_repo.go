@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotency struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new instance of idempotency repository.
+func NewIdempotencyRepository(db *gorm.DB) *idempotency {
+	return &idempotency{db: db}
+}
+
+type IdempotencyRepository interface {
+	ReserveIdempotencyKey(record *model.IdempotencyRecord) (*model.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(record *model.IdempotencyRecord, statusCode int, contentType string, body []byte) error
+	ReleaseIdempotencyKey(record *model.IdempotencyRecord) error
+}
+
+// ReserveIdempotencyKey stores the record as in flight unless its key is already stored for the same
+// actor, method and path. It returns true with the stored record when reserved, or false with the existing
+// record otherwise. Expired records are removed first, so their keys can be reserved again.
+func (i *idempotency) ReserveIdempotencyKey(record *model.IdempotencyRecord) (*model.IdempotencyRecord, bool, error) {
+	err := i.db.Where("expires_at <= ?", time.Now()).Delete(&model.IdempotencyRecord{}).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := i.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing model.IdempotencyRecord
+	err = i.db.Where("idempotency_key = ? AND actor_id = ? AND method = ? AND path = ?", record.Key, record.ActorID, record.Method, record.Path).
+		First(&existing).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved the key
+func (i *idempotency) CompleteIdempotencyKey(record *model.IdempotencyRecord, statusCode int, contentType string, body []byte) error {
+	return i.db.Model(record).Updates(map[string]interface{}{
+		"completed":    true,
+		"status_code":  statusCode,
+		"content_type": contentType,
+		"body":         string(body),
+	}).Error
+}
+
+// ReleaseIdempotencyKey removes the record of a request that failed, so it can be retried with the same key
+func (i *idempotency) ReleaseIdempotencyKey(record *model.IdempotencyRecord) error {
+	return i.db.Delete(record).Error
+}
@@ -1,14 +1,23 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/yourname/payslip-system/internal/dto/request"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrOvertimeNotPending is returned when correcting an overtime that was already approved or rejected
+	ErrOvertimeNotPending = errors.New("only pending overtime can be corrected")
+	// ErrOvertimeDateTaken is returned when moving an overtime to a day that already has one
+	ErrOvertimeDateTaken = errors.New("overtime already recorded for that date")
+)
+
 type overtime struct {
 	db *gorm.DB
 }
@@ -26,6 +35,13 @@ func (o *overtime) GetDB() *gorm.DB {
 type OvertimeRepository interface {
 	CreateOvertimePeriod(employeeID uint, hours int, reason string) (*model.Overtime, error)
 	CreateOvertimePeriodWithAudit(employeeID uint, hours int, reason string, auditDB *middleware.AuditableDB) (*model.Overtime, error)
+	GetOvertimeByID(overtimeID uint) (*model.Overtime, error)
+	ApproveOvertimeWithAudit(overtime *model.Overtime, approverID uint, auditDB *middleware.AuditableDB) (*model.Overtime, error)
+	RejectOvertimeWithAudit(overtime *model.Overtime, approverID uint, auditDB *middleware.AuditableDB) (*model.Overtime, error)
+	SumOvertimeHoursForMonth(employeeID uint, year int, month time.Month) (int, error)
+	CreateOvertimeIfNoneForDateWithAudit(overtime *model.Overtime, auditDB *middleware.AuditableDB) (*model.Overtime, bool, error)
+	UpdatePendingOvertimeWithAudit(overtime *model.Overtime, auditDB *middleware.AuditableDB) (*model.Overtime, error)
+	ListOvertimes(filter request.OvertimeFilter, offset int, limit int) ([]model.Overtime, int64, error)
 	GetDB() *gorm.DB
 }
 
@@ -156,3 +172,139 @@ func (o *overtime) CreateOvertimePeriodWithAudit(employeeID uint, hours int, rea
 
 	return &overtimePeriod, nil
 }
+
+// GetOvertimeByID retrieves an overtime record by its ID
+func (o *overtime) GetOvertimeByID(overtimeID uint) (*model.Overtime, error) {
+	var overtime model.Overtime
+	err := o.db.Where("id = ?", overtimeID).First(&overtime).Error
+	if err != nil {
+		return nil, err
+	}
+	return &overtime, nil
+}
+
+// ApproveOvertimeWithAudit marks an overtime record as approved with audit trail
+func (o *overtime) ApproveOvertimeWithAudit(overtime *model.Overtime, approverID uint, auditDB *middleware.AuditableDB) (*model.Overtime, error) {
+	overtime.Approve(approverID)
+	err := auditDB.Save(overtime).Error
+	if err != nil {
+		return nil, err
+	}
+	return overtime, nil
+}
+
+// RejectOvertimeWithAudit marks an overtime record as rejected with audit trail
+func (o *overtime) RejectOvertimeWithAudit(overtime *model.Overtime, approverID uint, auditDB *middleware.AuditableDB) (*model.Overtime, error) {
+	overtime.Reject(approverID)
+	err := auditDB.Save(overtime).Error
+	if err != nil {
+		return nil, err
+	}
+	return overtime, nil
+}
+
+// CreateOvertimeIfNoneForDateWithAudit stores the overtime unless the employee already has one for its date,
+// whatever its status. Returns the stored overtime and true, or the existing one and false.
+func (o *overtime) CreateOvertimeIfNoneForDateWithAudit(overtime *model.Overtime, auditDB *middleware.AuditableDB) (*model.Overtime, bool, error) {
+	var existing model.Overtime
+	err := o.db.Where("employee_id = ? AND overtime_date = ?", overtime.EmployeeID, overtime.OvertimeDate).
+		Order("id").
+		First(&existing).Error
+	if err == nil {
+		return &existing, false, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, false, err
+	}
+
+	if err := auditDB.Create(overtime).Error; err != nil {
+		return nil, false, err
+	}
+	return overtime, true, nil
+}
+
+// UpdatePendingOvertimeWithAudit stores the corrected hours, reason and date of an overtime, as long as it is
+// still pending and no other overtime of the employee is recorded for the new date
+func (o *overtime) UpdatePendingOvertimeWithAudit(overtime *model.Overtime, auditDB *middleware.AuditableDB) (*model.Overtime, error) {
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		var stored model.Overtime
+		if err := tx.First(&stored, overtime.ID).Error; err != nil {
+			return err
+		}
+		if stored.Status != model.OvertimePending {
+			return ErrOvertimeNotPending
+		}
+
+		var taken int64
+		err := tx.Model(&model.Overtime{}).
+			Where("employee_id = ? AND overtime_date = ? AND id <> ?", stored.EmployeeID, overtime.OvertimeDate, stored.ID).
+			Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrOvertimeDateTaken
+		}
+
+		return txAudit.Save(overtime).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return overtime, nil
+}
+
+// SumOvertimeHoursForMonth totals the approved and pending overtime hours of an employee in a calendar month,
+// rejected overtime is not counted
+func (o *overtime) SumOvertimeHoursForMonth(employeeID uint, year int, month time.Month) (int, error) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+
+	var total int
+	err := o.db.Model(&model.Overtime{}).
+		Where("employee_id = ? AND overtime_date >= ? AND overtime_date <= ? AND status <> ?",
+			employeeID, start.Format("2006-01-02"), end.Format("2006-01-02"), model.OvertimeRejected).
+		Select("COALESCE(SUM(hours), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum overtime hours: %v", err)
+	}
+	return total, nil
+}
+
+// ListOvertimes retrieves limit overtimes matching the filter from offset, latest overtime date first,
+// together with the total number of matching overtimes. overtime_date is a YYYY-MM-DD string, so the
+// date range is compared as text, which orders the same way as the dates.
+func (o *overtime) ListOvertimes(filter request.OvertimeFilter, offset int, limit int) ([]model.Overtime, int64, error) {
+	query := o.db.Model(&model.Overtime{})
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.StartDate != "" {
+		query = query.Where("overtime_date >= ?", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		query = query.Where("overtime_date <= ?", filter.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var overtimes []model.Overtime
+	err := query.Order("overtime_date DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&overtimes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return overtimes, total, nil
+}
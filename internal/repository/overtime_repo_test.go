@@ -0,0 +1,168 @@
+// Package repository contains unit tests for the overtime repository functionality.
+//
+// SumOvertimeHoursForMonth tests cover:
+// 1. Approved and pending hours of the month summed, rejected, other month and other employee excluded
+// 2. A month without overtime returning zero
+//
+// UpdatePendingOvertimeWithAudit tests cover:
+// 1. A pending overtime corrected, refused once decided or when moved onto a day that already has overtime
+//
+// ListOvertimes tests cover:
+// 1. Date range bounds inclusive, latest date first, across a month and year boundary of the stored strings
+// 2. Employee and status filters, offset and limit against the total
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+)
+
+func TestOvertimeRepository_SumOvertimeHoursForMonth(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewOvertimeRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	other := createTestEmployee(t, db, 2, "Jane Doe")
+
+	seed := []model.Overtime{
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-01", Hours: 3, Status: model.OvertimeApproved},
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-30", Hours: 2, Status: model.OvertimePending},
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-15", Hours: 3, Status: model.OvertimeRejected},
+		{EmployeeID: employee.ID, OvertimeDate: "2025-07-01", Hours: 3, Status: model.OvertimeApproved},
+		{EmployeeID: employee.ID, OvertimeDate: "2025-05-31", Hours: 3, Status: model.OvertimePending},
+		{EmployeeID: other.ID, OvertimeDate: "2025-06-10", Hours: 3, Status: model.OvertimeApproved},
+	}
+	for i := range seed {
+		seed[i].Reason = "Release support"
+		require.NoError(t, db.Create(&seed[i]).Error)
+	}
+
+	total, err := repo.SumOvertimeHoursForMonth(employee.ID, 2025, time.June)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+}
+
+func TestOvertimeRepository_SumOvertimeHoursForMonth_NoOvertime(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewOvertimeRepository(db)
+
+	total, err := repo.SumOvertimeHoursForMonth(1, 2025, time.February)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func TestOvertimeRepository_UpdatePendingOvertimeWithAudit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewOvertimeRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	auditDB := middleware.NewAuditableDB(db, employee.ID)
+
+	pending := model.Overtime{EmployeeID: employee.ID, OvertimeDate: "2025-06-02", Hours: 2, Reason: "Release support", Status: model.OvertimePending}
+	other := model.Overtime{EmployeeID: employee.ID, OvertimeDate: "2025-06-03", Hours: 1, Reason: "Incident", Status: model.OvertimePending}
+	require.NoError(t, db.Create(&pending).Error)
+	require.NoError(t, db.Create(&other).Error)
+
+	pending.Hours = 3
+	pending.OvertimeDate = "2025-06-04"
+	_, err := repo.UpdatePendingOvertimeWithAudit(&pending, auditDB)
+	require.NoError(t, err)
+
+	var stored model.Overtime
+	require.NoError(t, db.First(&stored, pending.ID).Error)
+	assert.Equal(t, 3, stored.Hours)
+	assert.Equal(t, "2025-06-04", stored.OvertimeDate)
+
+	pending.OvertimeDate = other.OvertimeDate
+	_, err = repo.UpdatePendingOvertimeWithAudit(&pending, auditDB)
+	assert.ErrorIs(t, err, ErrOvertimeDateTaken)
+
+	require.NoError(t, db.Model(&other).Update("status", model.OvertimeApproved).Error)
+	other.Hours = 2
+	_, err = repo.UpdatePendingOvertimeWithAudit(&other, auditDB)
+	assert.ErrorIs(t, err, ErrOvertimeNotPending)
+
+	var decided model.Overtime
+	require.NoError(t, db.First(&decided, other.ID).Error)
+	assert.Equal(t, 1, decided.Hours)
+}
+
+// createListedOvertimes stores overtime of employee 1 on each date, pending unless listed in approved,
+// and returns the ids by date
+func createListedOvertimes(t *testing.T, repo OvertimeRepository, dates []string, approved ...string) map[string]uint {
+	ids := map[string]uint{}
+	for _, date := range dates {
+		status := model.OvertimePending
+		for _, approvedDate := range approved {
+			if approvedDate == date {
+				status = model.OvertimeApproved
+			}
+		}
+		overtime := model.Overtime{EmployeeID: 1, OvertimeDate: date, Hours: 2, Reason: "Release support", Status: status}
+		require.NoError(t, repo.GetDB().Create(&overtime).Error)
+		ids[date] = overtime.ID
+	}
+	return ids
+}
+
+func TestOvertimeRepository_ListOvertimes_DateRange(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewOvertimeRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	ids := createListedOvertimes(t, repo, []string{"2024-12-31", "2025-01-01", "2025-01-09", "2025-01-10", "2025-01-31", "2025-02-01", "2025-10-01"})
+
+	tests := []struct {
+		name   string
+		filter request.OvertimeFilter
+		want   []string
+	}{
+		{"both bounds inclusive", request.OvertimeFilter{StartDate: "2025-01-01", EndDate: "2025-01-31"}, []string{"2025-01-31", "2025-01-10", "2025-01-09", "2025-01-01"}},
+		{"single day", request.OvertimeFilter{StartDate: "2025-01-10", EndDate: "2025-01-10"}, []string{"2025-01-10"}},
+		{"start only, two digit month after one digit month", request.OvertimeFilter{StartDate: "2025-02-01"}, []string{"2025-10-01", "2025-02-01"}},
+		{"end only, across the year boundary", request.OvertimeFilter{EndDate: "2025-01-01"}, []string{"2025-01-01", "2024-12-31"}},
+		{"no bounds", request.OvertimeFilter{}, []string{"2025-10-01", "2025-02-01", "2025-01-31", "2025-01-10", "2025-01-09", "2025-01-01", "2024-12-31"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overtimes, total, err := repo.ListOvertimes(tt.filter, 0, 20)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.want)), total)
+
+			want := []uint{}
+			for _, date := range tt.want {
+				want = append(want, ids[date])
+			}
+			got := []uint{}
+			for _, overtime := range overtimes {
+				got = append(got, overtime.ID)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestOvertimeRepository_ListOvertimes_Filters(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewOvertimeRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	createTestEmployee(t, db, 2, "Jane Roe")
+	ids := createListedOvertimes(t, repo, []string{"2025-06-02", "2025-06-03", "2025-06-04"}, "2025-06-03")
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: 2, OvertimeDate: "2025-06-03", Hours: 1, Reason: "Incident", Status: model.OvertimeApproved}).Error)
+
+	employeeID := uint(1)
+	overtimes, total, err := repo.ListOvertimes(request.OvertimeFilter{EmployeeID: &employeeID, Status: string(model.OvertimeApproved)}, 0, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, overtimes, 1)
+	assert.Equal(t, ids["2025-06-03"], overtimes[0].ID)
+
+	overtimes, total, err = repo.ListOvertimes(request.OvertimeFilter{EmployeeID: &employeeID}, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, overtimes, 1)
+	assert.Equal(t, ids["2025-06-03"], overtimes[0].ID)
+}
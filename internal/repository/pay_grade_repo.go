@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrPayGradeInUse is returned when deleting a pay grade that employees are still assigned to
+var ErrPayGradeInUse = errors.New("pay grade is still assigned to employees")
+
+type payGrade struct {
+	db *gorm.DB
+}
+
+// NewPayGradeRepository creates a new instance of pay grade repository.
+func NewPayGradeRepository(db *gorm.DB) *payGrade {
+	return &payGrade{db: db}
+}
+
+// GetDB returns the underlying GORM DB instance for audit functionality
+func (p *payGrade) GetDB() *gorm.DB {
+	return p.db
+}
+
+type PayGradeRepository interface {
+	CreatePayGradeWithAudit(req request.PayGradeRequest, auditDB *middleware.AuditableDB) (*model.PayGrade, error)
+	GetAllPayGrades() ([]model.PayGrade, error)
+	GetPayGradeByID(id uint) (*model.PayGrade, error)
+	UpdatePayGradeWithAudit(id uint, req request.PayGradeRequest, auditDB *middleware.AuditableDB) (*model.PayGrade, error)
+	DeletePayGradeWithAudit(id uint, auditDB *middleware.AuditableDB) error
+	GetDB() *gorm.DB
+}
+
+// CreatePayGradeWithAudit creates a pay grade after checking its band
+func (p *payGrade) CreatePayGradeWithAudit(req request.PayGradeRequest, auditDB *middleware.AuditableDB) (*model.PayGrade, error) {
+	grade := model.PayGrade{
+		Name:          req.Name,
+		MinSalary:     req.MinSalary,
+		MaxSalary:     req.MaxSalary,
+		DefaultSalary: req.DefaultSalary,
+	}
+	if err := validatePayGrade(&grade); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := p.db.Model(&model.PayGrade{}).Where("name = ?", req.Name).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("pay grade %s already exists", req.Name)
+	}
+
+	if err := auditDB.Create(&grade).Error; err != nil {
+		return nil, err
+	}
+	return &grade, nil
+}
+
+// GetAllPayGrades retrieves every pay grade ordered by its minimum salary
+func (p *payGrade) GetAllPayGrades() ([]model.PayGrade, error) {
+	var grades []model.PayGrade
+	err := p.db.Order("min_salary ASC, name ASC").Find(&grades).Error
+	if err != nil {
+		return nil, err
+	}
+	return grades, nil
+}
+
+// GetPayGradeByID retrieves a pay grade by its ID
+func (p *payGrade) GetPayGradeByID(id uint) (*model.PayGrade, error) {
+	var grade model.PayGrade
+	err := p.db.Where("id = ?", id).First(&grade).Error
+	if err != nil {
+		return nil, err
+	}
+	return &grade, nil
+}
+
+// UpdatePayGradeWithAudit updates a pay grade. Salaries already assigned are not re-checked
+// against the new band, they are checked again on the next employee update.
+func (p *payGrade) UpdatePayGradeWithAudit(id uint, req request.PayGradeRequest, auditDB *middleware.AuditableDB) (*model.PayGrade, error) {
+	grade, err := p.GetPayGradeByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	grade.Name = req.Name
+	grade.MinSalary = req.MinSalary
+	grade.MaxSalary = req.MaxSalary
+	grade.DefaultSalary = req.DefaultSalary
+	if err := validatePayGrade(grade); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := p.db.Model(&model.PayGrade{}).Where("name = ? AND id <> ?", req.Name, id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("pay grade %s already exists", req.Name)
+	}
+
+	if err := auditDB.Save(grade).Error; err != nil {
+		return nil, err
+	}
+	return grade, nil
+}
+
+// DeletePayGradeWithAudit soft deletes a pay grade no employee is assigned to
+func (p *payGrade) DeletePayGradeWithAudit(id uint, auditDB *middleware.AuditableDB) error {
+	grade, err := p.GetPayGradeByID(id)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := p.db.Model(&model.Employee{}).Where("pay_grade_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPayGradeInUse
+	}
+
+	return auditDB.Delete(grade).Error
+}
+
+// validatePayGrade checks that the band is well formed and holds the default salary
+func validatePayGrade(grade *model.PayGrade) error {
+	if grade.Name == "" {
+		return errors.New("pay grade name is required")
+	}
+	if grade.MinSalary < 0 || grade.MaxSalary < grade.MinSalary {
+		return fmt.Errorf("pay grade %s must have a non-negative minimum salary not above its maximum", grade.Name)
+	}
+	if !grade.Contains(grade.DefaultSalary) {
+		return fmt.Errorf("default salary %.2f of pay grade %s must be between %.2f and %.2f",
+			grade.DefaultSalary, grade.Name, grade.MinSalary, grade.MaxSalary)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Package repository contains unit tests for the pay grade repository functionality.
+//
+// CreatePayGradeWithAudit tests cover:
+// 1. A grade created with its band and default salary
+// 2. Malformed bands, a default outside the band and duplicate names rejected
+//
+// DeletePayGradeWithAudit tests cover:
+// 1. A grade still assigned to an employee refused, an unassigned one deleted
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"gorm.io/gorm"
+)
+
+func TestPayGradeRepository_CreatePayGradeWithAudit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayGradeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+
+	grade, err := repo.CreatePayGradeWithAudit(request.PayGradeRequest{
+		Name: "G2", MinSalary: 4000000, MaxSalary: 8000000, DefaultSalary: 6000000,
+	}, auditDB)
+	require.NoError(t, err)
+
+	stored, err := repo.GetPayGradeByID(grade.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "G2", stored.Name)
+	assert.Equal(t, 6000000.0, stored.DefaultSalary)
+	require.NotNil(t, stored.CreatedBy)
+	assert.Equal(t, uint(1), *stored.CreatedBy)
+}
+
+func TestPayGradeRepository_CreatePayGradeWithAudit_Rejected(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayGradeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+
+	_, err := repo.CreatePayGradeWithAudit(request.PayGradeRequest{
+		Name: "G1", MinSalary: 3000000, MaxSalary: 5000000, DefaultSalary: 4000000,
+	}, auditDB)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     request.PayGradeRequest
+		wantErr string
+	}{
+		{
+			name:    "maximum below minimum",
+			req:     request.PayGradeRequest{Name: "G2", MinSalary: 8000000, MaxSalary: 4000000, DefaultSalary: 6000000},
+			wantErr: "pay grade G2 must have a non-negative minimum salary not above its maximum",
+		},
+		{
+			name:    "default outside the band",
+			req:     request.PayGradeRequest{Name: "G2", MinSalary: 4000000, MaxSalary: 8000000, DefaultSalary: 9000000},
+			wantErr: "default salary 9000000.00 of pay grade G2 must be between 4000000.00 and 8000000.00",
+		},
+		{
+			name:    "duplicate name",
+			req:     request.PayGradeRequest{Name: "G1", MinSalary: 3000000, MaxSalary: 5000000, DefaultSalary: 4000000},
+			wantErr: "pay grade G1 already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.CreatePayGradeWithAudit(tt.req, auditDB)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	grades, err := repo.GetAllPayGrades()
+	require.NoError(t, err)
+	assert.Len(t, grades, 1)
+}
+
+func TestPayGradeRepository_DeletePayGradeWithAudit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayGradeRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+
+	grade, err := repo.CreatePayGradeWithAudit(request.PayGradeRequest{
+		Name: "G2", MinSalary: 4000000, MaxSalary: 8000000, DefaultSalary: 6000000,
+	}, auditDB)
+	require.NoError(t, err)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	require.NoError(t, db.Model(employee).Update("pay_grade_id", grade.ID).Error)
+
+	assert.Equal(t, ErrPayGradeInUse, repo.DeletePayGradeWithAudit(grade.ID, auditDB))
+
+	require.NoError(t, db.Model(employee).Update("pay_grade_id", nil).Error)
+	require.NoError(t, repo.DeletePayGradeWithAudit(grade.ID, auditDB))
+
+	_, err = repo.GetPayGradeByID(grade.ID)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
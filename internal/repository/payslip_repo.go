@@ -1,11 +1,27 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrPayslipPaid is returned when voiding a payslip that has already been paid out
+	ErrPayslipPaid = errors.New("payslip has already been paid out")
+	// ErrPayslipVoid is returned when voiding a payslip that is already void
+	ErrPayslipVoid = errors.New("payslip is already void")
+	// ErrPayslipExists is returned when the employee already has a payslip for the period that is not void
+	ErrPayslipExists = errors.New("payslip already exists for this period")
+	// ErrPayslipOverlaps is returned when the period overlaps that of another payslip of the employee that is not void
+	ErrPayslipOverlaps = errors.New("pay period overlaps an existing payslip")
 )
 
 type payslip struct {
@@ -25,29 +41,59 @@ func (p *payslip) GetDB() *gorm.DB {
 type PayslipRepository interface {
 	CreatePayslip(payslipData *model.Payslip) (*model.Payslip, error)
 	CreatePayslipWithAudit(payslipData *model.Payslip, auditDB *middleware.AuditableDB) (*model.Payslip, error)
+	VoidPayslipWithAudit(payslipID uint, reason string, expectedVersion *int, auditDB *middleware.AuditableDB) (*model.Payslip, error)
 	GetPayslipByEmployeeAndPeriod(employeeID uint, startDate time.Time, endDate time.Time) (*model.Payslip, error)
 	GetPayslipByID(payslipID uint) (*model.Payslip, error)
 	GetPayslipsByEmployee(employeeID uint) ([]model.Payslip, error)
+	GetPayslipsByEmployeePaged(employeeID uint, offset int, limit int) ([]model.Payslip, int64, error)
 	GetPayslipsByPeriod(startDate time.Time, endDate time.Time) ([]model.Payslip, error)
+	ListPayslips(filter request.PayslipFilter, offset int, limit int) ([]model.Payslip, int64, error)
+	GetPayslipsByEmployeeForYear(employeeID uint, year int) ([]model.Payslip, error)
+	GetPayslipsByEmployeeForRange(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Payslip, error)
 	CheckPayslipExists(employeeID uint, startDate time.Time, endDate time.Time) (bool, error)
+	HasOverlappingPayslip(employeeID uint, startDate time.Time, endDate time.Time) (bool, error)
 	GetAttendanceForPeriod(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Attendance, error)
 	GetOvertimeForPeriod(employeeID uint, startDate string, endDate string) ([]model.Overtime, error)
 	GetApprovedReimbursementsForPeriod(employeeID uint, startDate, endDate time.Time) ([]model.Reimbursement, error)
+	GetPendingOvertimeForPeriod(employeeID uint, startDate string, endDate string) ([]model.Overtime, error)
+	GetHolidaysForPeriod(startDate string, endDate string) ([]model.Holiday, error)
+	GetPendingReimbursementsForPeriod(employeeID uint, startDate, endDate time.Time) ([]model.Reimbursement, error)
 	GetEmployeeByID(employeeID uint) (*model.Employee, error)
+	GetActiveComponentsForEmployee(employeeID uint) ([]model.EmployeeComponent, error)
+	GetTaxBrackets() ([]model.TaxBracket, error)
+	GetEmployeesWithComponents() ([]model.Employee, error)
+	CheckTimesheetApproved(employeeID uint, startDate time.Time, endDate time.Time) (bool, error)
+	GetCarriedForwardDeduction(employeeID uint, before time.Time) (float64, error)
+	GetPayrollTotalsForPeriod(startDate time.Time, endDate time.Time) ([]res.PayrollTotals, error)
+	GetPayPeriodsPage(page int, perPage int) ([]res.PayPeriodSummary, int64, error)
+	BulkVoidPayslipsWithAudit(startDate time.Time, endDate time.Time, employeeIDs []uint, reason string, auditDB *middleware.AuditableDB) ([]model.Payslip, int, []model.Payslip, error)
+	CreatePayrollRunWithAudit(run *model.PayrollRun, auditDB *middleware.AuditableDB) (*model.PayrollRun, error)
+	GetPayrollRunByID(runID uint) (*model.PayrollRun, error)
+	GetPayrollRunsPage(page int, perPage int) ([]model.PayrollRun, int64, error)
+	UpdatePayrollRunResultWithAudit(run *model.PayrollRun, result *model.PayrollRunResult, auditDB *middleware.AuditableDB) error
 	GetDB() *gorm.DB
 }
 
+// CreatePayslip stores a payslip. A payslip the employee already has for the period, e.g. from a concurrent
+// run that passed the same exists check, is refused by the unique index and reported as ErrPayslipExists.
 func (p *payslip) CreatePayslip(payslipData *model.Payslip) (*model.Payslip, error) {
 	err := p.db.Create(payslipData).Error
 	if err != nil {
+		if isUniqueViolation(p.db, err) {
+			return nil, ErrPayslipExists
+		}
 		return nil, err
 	}
 	return payslipData, nil
 }
 
+// CreatePayslipWithAudit stores a payslip with audit trail, reporting a duplicate period as ErrPayslipExists
 func (p *payslip) CreatePayslipWithAudit(payslipData *model.Payslip, auditDB *middleware.AuditableDB) (*model.Payslip, error) {
 	err := auditDB.Create(payslipData).Error
 	if err != nil {
+		if isUniqueViolation(auditDB.DB, err) {
+			return nil, ErrPayslipExists
+		}
 		return nil, err
 	}
 	return payslipData, nil
@@ -55,8 +101,8 @@ func (p *payslip) CreatePayslipWithAudit(payslipData *model.Payslip, auditDB *mi
 
 func (p *payslip) GetPayslipByEmployeeAndPeriod(employeeID uint, startDate time.Time, endDate time.Time) (*model.Payslip, error) {
 	var payslip model.Payslip
-	err := p.db.Where("employee_id = ? AND pay_period_start = ? AND pay_period_end = ?",
-		employeeID, startDate, endDate).First(&payslip).Error
+	err := p.db.Where("employee_id = ? AND pay_period_start = ? AND pay_period_end = ? AND status <> ?",
+		employeeID, startDate, endDate, model.PayslipStatusVoid).First(&payslip).Error
 	if err != nil {
 		return nil, err
 	}
@@ -81,25 +127,196 @@ func (p *payslip) GetPayslipsByEmployee(employeeID uint) ([]model.Payslip, error
 	return payslips, nil
 }
 
+// GetPayslipsByEmployeePaged retrieves limit payslips of an employee from offset, newest period first,
+// together with the total number of payslips of the employee
+func (p *payslip) GetPayslipsByEmployeePaged(employeeID uint, offset int, limit int) ([]model.Payslip, int64, error) {
+	var total int64
+	if err := p.db.Model(&model.Payslip{}).Where("employee_id = ?", employeeID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var payslips []model.Payslip
+	err := p.db.Where("employee_id = ?", employeeID).
+		Order("pay_period_start DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&payslips).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return payslips, total, nil
+}
+
 func (p *payslip) GetPayslipsByPeriod(startDate time.Time, endDate time.Time) ([]model.Payslip, error) {
 	var payslips []model.Payslip
-	err := p.db.Where("pay_period_start >= ? AND pay_period_end <= ?", startDate, endDate).Find(&payslips).Error
+	err := p.periodQuery(startDate, endDate).Where("status <> ?", model.PayslipStatusVoid).Find(&payslips).Error
+	if err != nil {
+		return nil, err
+	}
+	return payslips, nil
+}
+
+// payslipSortColumns whitelists the columns a payslip listing can be ordered by
+var payslipSortColumns = map[string]string{
+	request.PayslipSortEmployeeID:  "employee_id",
+	request.PayslipSortTotalAmount: "total_amount",
+}
+
+// ListPayslips retrieves limit payslips of the period matching the filter from offset, in the requested
+// order with the payslip ID breaking ties, together with the total number of matching payslips
+func (p *payslip) ListPayslips(filter request.PayslipFilter, offset int, limit int) ([]model.Payslip, int64, error) {
+	sortKey := filter.Sort
+	if sortKey == "" {
+		sortKey = request.PayslipSortEmployeeID
+	}
+	column, ok := payslipSortColumns[sortKey]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported sort column %q", filter.Sort)
+	}
+
+	query := p.periodQuery(filter.PayPeriodStart, filter.PayPeriodEnd)
+	if filter.Status == "" {
+		query = query.Where("status <> ?", model.PayslipStatusVoid)
+	} else {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var payslips []model.Payslip
+	err := query.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: filter.Descending}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: filter.Descending}).
+		Offset(offset).
+		Limit(limit).
+		Find(&payslips).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return payslips, total, nil
+}
+
+// periodQuery selects the payslips whose pay period lies within [startDate, endDate]
+func (p *payslip) periodQuery(startDate time.Time, endDate time.Time) *gorm.DB {
+	return p.db.Model(&model.Payslip{}).Where("pay_period_start >= ? AND pay_period_end <= ?", startDate, endDate)
+}
+
+// GetPayslipsByEmployeeForYear returns the non-void payslips of an employee whose pay period starts in the year
+func (p *payslip) GetPayslipsByEmployeeForYear(employeeID uint, year int) ([]model.Payslip, error) {
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return p.GetPayslipsByEmployeeForRange(employeeID, yearStart, yearStart.AddDate(1, 0, 0))
+}
+
+// GetPayslipsByEmployeeForRange retrieves the non-void payslips of an employee whose pay period starts in
+// [startDate, endDate), oldest first
+func (p *payslip) GetPayslipsByEmployeeForRange(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Payslip, error) {
+	var payslips []model.Payslip
+	err := p.db.Where("employee_id = ? AND pay_period_start >= ? AND pay_period_start < ? AND status <> ?",
+		employeeID, startDate, endDate, model.PayslipStatusVoid).
+		Order("pay_period_start ASC").Find(&payslips).Error
 	if err != nil {
 		return nil, err
 	}
 	return payslips, nil
 }
 
+// GetPayrollTotalsForPeriod aggregates the non-void payslips whose pay period starts in [startDate, endDate),
+// one row per currency as amounts in different currencies are never summed
+func (p *payslip) GetPayrollTotalsForPeriod(startDate time.Time, endDate time.Time) ([]res.PayrollTotals, error) {
+	var totals []res.PayrollTotals
+	err := p.db.Model(&model.Payslip{}).
+		Select("currency, COUNT(*) AS payslip_count, COUNT(DISTINCT employee_id) AS headcount, "+
+			"COALESCE(SUM(total_amount), 0) AS total_amount, COALESCE(SUM(overtime_amount), 0) AS overtime_amount").
+		Where("pay_period_start >= ? AND pay_period_start < ? AND status <> ?", startDate, endDate, model.PayslipStatusVoid).
+		Group("currency").
+		Order("currency").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// GetPayPeriodsPage returns a page of the distinct pay periods with payslips, newest start first, with the
+// payslip count and total payout of each, together with the total number of rows. A period paid in several
+// currencies has a row per currency, amounts in different currencies are never summed. Void payslips are left out.
+func (p *payslip) GetPayPeriodsPage(page int, perPage int) ([]res.PayPeriodSummary, int64, error) {
+	periods := p.db.Model(&model.Payslip{}).
+		Select("pay_period_start, pay_period_end, currency, COUNT(*) AS payslip_count, COALESCE(SUM(total_amount), 0) AS total_payout").
+		Where("status <> ?", model.PayslipStatusVoid).
+		Group("pay_period_start, pay_period_end, currency")
+
+	var total int64
+	if err := p.db.Table("(?) AS periods", periods).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var summaries []res.PayPeriodSummary
+	err := periods.Order("pay_period_start DESC").Order("pay_period_end DESC").Order("currency").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return summaries, total, nil
+}
+
 func (p *payslip) CheckPayslipExists(employeeID uint, startDate time.Time, endDate time.Time) (bool, error) {
+	// A voided payslip is kept for audit but no longer blocks processing the period again
+	var count int64
+	err := p.db.Model(&model.Payslip{}).Where("employee_id = ? AND pay_period_start = ? AND pay_period_end = ? AND status <> ?",
+		employeeID, startDate, endDate, model.PayslipStatusVoid).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// HasOverlappingPayslip checks whether a payslip of the employee that is not void shares a day with the period.
+// Pay periods include both their first and last day, so only adjacent periods, one starting the day after the
+// other ends, do not overlap.
+func (p *payslip) HasOverlappingPayslip(employeeID uint, startDate time.Time, endDate time.Time) (bool, error) {
+	var count int64
+	err := p.db.Model(&model.Payslip{}).Where("employee_id = ? AND pay_period_start <= ? AND pay_period_end >= ? AND status <> ?",
+		employeeID, endDate, startDate, model.PayslipStatusVoid).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CheckTimesheetApproved checks if the employee's timesheet for the period has been approved
+func (p *payslip) CheckTimesheetApproved(employeeID uint, startDate time.Time, endDate time.Time) (bool, error) {
 	var count int64
-	err := p.db.Model(&model.Payslip{}).Where("employee_id = ? AND pay_period_start = ? AND pay_period_end = ?",
-		employeeID, startDate, endDate).Count(&count).Error
+	err := p.db.Model(&model.Timesheet{}).Where("employee_id = ? AND period_start = ? AND period_end = ? AND status = ?",
+		employeeID, startDate, endDate, model.TimesheetApproved).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
+// GetCarriedForwardDeduction returns the deduction carried forward by the latest payslip of the
+// employee ending before the given date, void payslips are ignored
+func (p *payslip) GetCarriedForwardDeduction(employeeID uint, before time.Time) (float64, error) {
+	var previous model.Payslip
+	err := p.db.Where("employee_id = ? AND pay_period_end < ? AND status <> ?", employeeID, before, model.PayslipStatusVoid).
+		Order("pay_period_end DESC").First(&previous).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return previous.CarriedForward, nil
+}
+
 func (p *payslip) GetAttendanceForPeriod(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Attendance, error) {
 	var attendances []model.Attendance
 	err := p.db.Where("employee_id = ? AND date >= ? AND date <= ?",
@@ -113,13 +330,23 @@ func (p *payslip) GetAttendanceForPeriod(employeeID uint, startDate time.Time, e
 func (p *payslip) GetOvertimeForPeriod(employeeID uint, startDate string, endDate string) ([]model.Overtime, error) {
 	var overtimes []model.Overtime
 	err := p.db.Debug().Where("employee_id = ? AND overtime_date >= ? AND overtime_date <= ? AND status = ?",
-		employeeID, startDate, endDate, "approved").Find(&overtimes).Error
+		employeeID, startDate, endDate, model.OvertimeApproved).Find(&overtimes).Error
 	if err != nil {
 		return nil, err
 	}
 	return overtimes, nil
 }
 
+// GetHolidaysForPeriod returns the holidays between the two YYYY-MM-DD dates, both included
+func (p *payslip) GetHolidaysForPeriod(startDate string, endDate string) ([]model.Holiday, error) {
+	var holidays []model.Holiday
+	err := p.db.Where("date >= ? AND date <= ?", startDate, endDate).Order("date ASC").Find(&holidays).Error
+	if err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
 func (p *payslip) GetApprovedReimbursementsForPeriod(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Reimbursement, error) {
 	var reimbursements []model.Reimbursement
 	err := p.db.Where("employee_id = ? AND reimbursement_date >= ? AND reimbursement_date <= ? AND status = ?",
@@ -130,11 +357,191 @@ func (p *payslip) GetApprovedReimbursementsForPeriod(employeeID uint, startDate
 	return reimbursements, nil
 }
 
+func (p *payslip) GetPendingOvertimeForPeriod(employeeID uint, startDate string, endDate string) ([]model.Overtime, error) {
+	var overtimes []model.Overtime
+	err := p.db.Where("employee_id = ? AND overtime_date >= ? AND overtime_date <= ? AND status = ?",
+		employeeID, startDate, endDate, model.OvertimePending).Find(&overtimes).Error
+	if err != nil {
+		return nil, err
+	}
+	return overtimes, nil
+}
+
+func (p *payslip) GetPendingReimbursementsForPeriod(employeeID uint, startDate time.Time, endDate time.Time) ([]model.Reimbursement, error) {
+	var reimbursements []model.Reimbursement
+	err := p.db.Where("employee_id = ? AND reimbursement_date >= ? AND reimbursement_date <= ? AND status = ?",
+		employeeID, startDate, endDate, model.ReimbursementPending).Find(&reimbursements).Error
+	if err != nil {
+		return nil, err
+	}
+	return reimbursements, nil
+}
+
 func (p *payslip) GetEmployeeByID(employeeID uint) (*model.Employee, error) {
 	var employee model.Employee
-	err := p.db.Where("id = ?", employeeID).First(&employee).Error
+	err := p.db.Preload("PayGrade").Where("id = ?", employeeID).First(&employee).Error
 	if err != nil {
 		return nil, err
 	}
 	return &employee, nil
 }
+
+func (p *payslip) GetActiveComponentsForEmployee(employeeID uint) ([]model.EmployeeComponent, error) {
+	var components []model.EmployeeComponent
+	err := p.db.Where("employee_id = ? AND active = ?", employeeID, true).Find(&components).Error
+	if err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// GetTaxBrackets retrieves the income tax brackets ordered by floor
+func (p *payslip) GetTaxBrackets() ([]model.TaxBracket, error) {
+	var brackets []model.TaxBracket
+	err := p.db.Order("floor ASC").Find(&brackets).Error
+	if err != nil {
+		return nil, err
+	}
+	return brackets, nil
+}
+
+// GetEmployeesWithComponents retrieves all active employees with their active components and pay grade
+func (p *payslip) GetEmployeesWithComponents() ([]model.Employee, error) {
+	var employees []model.Employee
+	err := p.db.Preload("Components", "active = ?", true).Preload("PayGrade").Where("active = ?", true).Find(&employees).Error
+	if err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// BulkVoidPayslipsWithAudit voids every payslip of a period (optionally limited to the given employees)
+// in a single transaction. It returns the voided payslips, the number of already voided payslips skipped
+// and the paid payslips, which cannot be voided and are left unchanged.
+func (p *payslip) BulkVoidPayslipsWithAudit(startDate time.Time, endDate time.Time, employeeIDs []uint, reason string, auditDB *middleware.AuditableDB) ([]model.Payslip, int, []model.Payslip, error) {
+	var voided, paid []model.Payslip
+	skipped := 0
+
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		query := tx.Where("pay_period_start >= ? AND pay_period_end <= ?", startDate, endDate)
+		if len(employeeIDs) > 0 {
+			query = query.Where("employee_id IN ?", employeeIDs)
+		}
+
+		var payslips []model.Payslip
+		if err := query.Order("id ASC").Find(&payslips).Error; err != nil {
+			return err
+		}
+
+		for i := range payslips {
+			payslip := &payslips[i]
+			if payslip.IsVoid() {
+				skipped++
+				continue
+			}
+			if payslip.IsPaid() {
+				paid = append(paid, *payslip)
+				continue
+			}
+
+			payslip.Void(reason)
+			if err := txAudit.SaveVersioned(payslip).Error; err != nil {
+				return err
+			}
+			voided = append(voided, *payslip)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return voided, skipped, paid, nil
+}
+
+// VoidPayslipWithAudit marks a single payslip void, keeping the record. Paid out and already void
+// payslips are refused with ErrPayslipPaid and ErrPayslipVoid, a missing one with gorm.ErrRecordNotFound.
+// With an expected version, a payslip updated since it was read fails with middleware.ErrStaleVersion.
+func (p *payslip) VoidPayslipWithAudit(payslipID uint, reason string, expectedVersion *int, auditDB *middleware.AuditableDB) (*model.Payslip, error) {
+	var payslip model.Payslip
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		if err := tx.First(&payslip, payslipID).Error; err != nil {
+			return err
+		}
+		if payslip.IsVoid() {
+			return ErrPayslipVoid
+		}
+		if payslip.IsPaid() {
+			return ErrPayslipPaid
+		}
+		if expectedVersion != nil && *expectedVersion != payslip.Version {
+			return middleware.ErrStaleVersion
+		}
+
+		payslip.Void(reason)
+		return txAudit.SaveVersioned(&payslip).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &payslip, nil
+}
+
+// CreatePayrollRunWithAudit stores a payroll run together with its per employee results
+func (p *payslip) CreatePayrollRunWithAudit(run *model.PayrollRun, auditDB *middleware.AuditableDB) (*model.PayrollRun, error) {
+	err := auditDB.Create(run).Error
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// GetPayrollRunByID retrieves a payroll run with its results
+func (p *payslip) GetPayrollRunByID(runID uint) (*model.PayrollRun, error) {
+	var run model.PayrollRun
+	err := p.db.Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("id = ?", runID).First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetPayrollRunsPage retrieves a page of payroll runs without their results, newest first,
+// together with the total number of runs
+func (p *payslip) GetPayrollRunsPage(page int, perPage int) ([]model.PayrollRun, int64, error) {
+	var total int64
+	if err := p.db.Model(&model.PayrollRun{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []model.PayrollRun
+	err := p.db.Order("created_at DESC").Order("id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&runs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// UpdatePayrollRunResultWithAudit saves a reprocessed result and the run counts in a single transaction
+func (p *payslip) UpdatePayrollRunResultWithAudit(run *model.PayrollRun, result *model.PayrollRunResult, auditDB *middleware.AuditableDB) error {
+	return auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		if err := txAudit.Save(result).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.PayrollRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+			"processed_count": run.ProcessedCount,
+			"error_count":     run.ErrorCount,
+			"updated_by":      auditDB.UserID,
+		}).Error
+	})
+}
@@ -6,6 +6,8 @@
 // 1. Valid payslip creation
 // 2. Database errors during creation
 // 3. Edge cases (nil payslip, zero values)
+// 4. Concurrent creations for one period storing a single payslip, the other refused as ErrPayslipExists
+// 5. A voided payslip not blocking a new one for the period
 //
 // CreatePayslipWithAudit tests cover:
 // 1. Valid payslip creation with audit
@@ -30,6 +32,9 @@
 // 3. Ordering verification (DESC by pay_period_start)
 // 4. Database errors
 //
+// GetPayslipsByEmployeePaged tests cover:
+// 1. Pages in DESC pay_period_start order with the employee's total count, other employees excluded
+//
 // GetPayslipsByPeriod tests cover:
 // 1. Valid retrieval by date range
 // 2. Empty results for period
@@ -41,6 +46,11 @@
 // 2. Non-existing payslip detection
 // 3. Database errors
 // 4. Edge cases
+// 5. Voided payslips being ignored
+//
+// HasOverlappingPayslip tests cover:
+// 1. The overlap matrix against a June payslip: equal, containing, contained, partial, boundary day and adjacent periods
+// 2. Voided payslips and other employees' payslips being ignored
 //
 // GetAttendanceForPeriod tests cover:
 // 1. Valid attendance retrieval
@@ -66,16 +76,42 @@
 // 3. Database errors
 // 4. Edge cases
 //
+// BulkVoidPayslipsWithAudit tests cover:
+// 1. Voiding every payslip of a period
+// 2. Skipping already voided payslips
+// 3. Refusing to void paid payslips
+// 4. Limiting the void to selected employees
+//
+// ListPayslips tests cover:
+// 1. Status and employee filters, void payslips left out unless requested
+// 2. Sorting by total amount in both orders and pagination with the total count
+// 3. Sort columns outside the whitelist rejected
+//
+// GetPayPeriodsPage tests cover:
+// 1. Distinct periods newest first with their payslip count and payout, void payslips left out
+// 2. Pages with the total number of periods
+// 3. A period paid in two currencies listed once per currency, amounts never summed across them
+//
+// VoidPayslipWithAudit tests cover:
+// 1. Voiding a single payslip while keeping the record
+// 2. Refusing paid and already voided payslips
+// 3. Missing payslips
+// 4. A void sent with an older version refused, the current version accepted and bumped
+//
 // All tests use an in-memory SQLite database for fast, isolated execution.
 
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
 	"gorm.io/driver/sqlite"
@@ -93,10 +129,23 @@ func setupTestDB(t testing.TB) *gorm.DB {
 	// Auto migrate all models
 	err = db.AutoMigrate(
 		&model.Payslip{},
+		&model.PayGrade{},
 		&model.Employee{},
 		&model.Attendance{},
 		&model.Overtime{},
 		&model.Reimbursement{},
+		&model.EmployeeComponent{},
+		&model.Timesheet{},
+		&model.PayrollRun{},
+		&model.PayrollRunResult{},
+		&model.TaxBracket{},
+		&model.Holiday{},
+		&model.RoleTemplate{},
+		&model.RoleTemplateComponent{},
+		&model.RefreshToken{},
+		&model.PasswordResetToken{},
+		&model.APIKey{},
+		&model.AuditLog{},
 	)
 	require.NoError(t, err)
 
@@ -181,6 +230,82 @@ func TestPayslipRepository_CreatePayslip_NilPayslip(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+func TestPayslipRepository_CreatePayslip_ConcurrentDuplicate(t *testing.T) {
+	db := setupTestDB(t)
+	// Every connection to :memory: opens its own database, so the goroutines share one
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	repo := NewPayslipRepository(db)
+
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	// Both calls passed an exists check before either stored its payslip
+	errs := make(chan error, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.CreatePayslip(&model.Payslip{
+				EmployeeID:     employee.ID,
+				PayPeriodStart: startDate,
+				PayPeriodEnd:   endDate,
+				BasicSalary:    5000000.0,
+				TotalAmount:    5500000.0,
+				ProcessedAt:    time.Now(),
+				Status:         "processed",
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded, refused int
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrPayslipExists):
+			refused++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, refused)
+
+	var count int64
+	require.NoError(t, db.Model(&model.Payslip{}).Where("employee_id = ?", employee.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestPayslipRepository_CreatePayslip_AfterVoided(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	voided := createTestPayslip(t, db, employee.ID, startDate, endDate)
+	require.NoError(t, db.Model(voided).Update("status", model.PayslipStatusVoid).Error)
+
+	result, err := repo.CreatePayslip(&model.Payslip{
+		EmployeeID:     employee.ID,
+		PayPeriodStart: startDate,
+		PayPeriodEnd:   endDate,
+		BasicSalary:    5000000.0,
+		TotalAmount:    5500000.0,
+		ProcessedAt:    time.Now(),
+		Status:         "processed",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, voided.ID, result.ID)
+}
+
 // Tests for CreatePayslipWithAudit function
 
 func TestPayslipRepository_CreatePayslipWithAudit_ValidPayslip(t *testing.T) {
@@ -331,6 +456,33 @@ func TestPayslipRepository_GetPayslipsByEmployee_ValidEmployee(t *testing.T) {
 	assert.Equal(t, payslip1.ID, results[1].ID) // May payslip should be second
 }
 
+func TestPayslipRepository_GetPayslipsByEmployeePaged(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	other := createTestEmployee(t, db, 2, "Jane Doe")
+
+	// Five months of payslips, created out of order
+	for _, month := range []time.Month{time.March, time.January, time.May, time.February, time.April} {
+		start := time.Date(2025, month, 1, 0, 0, 0, 0, time.UTC)
+		createTestPayslip(t, db, employee.ID, start, start.AddDate(0, 1, -1))
+	}
+	createTestPayslip(t, db, other.ID, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC))
+
+	results, total, err := repo.GetPayslipsByEmployeePaged(employee.ID, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	require.Len(t, results, 2)
+	assert.Equal(t, time.May, results[0].PayPeriodStart.Month())
+	assert.Equal(t, time.April, results[1].PayPeriodStart.Month())
+
+	results, total, err = repo.GetPayslipsByEmployeePaged(employee.ID, 4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	require.Len(t, results, 1)
+	assert.Equal(t, time.January, results[0].PayPeriodStart.Month())
+}
+
 func TestPayslipRepository_GetPayslipsByEmployee_NoPayslips(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewPayslipRepository(db)
@@ -447,6 +599,80 @@ func TestPayslipRepository_CheckPayslipExists_NotExists(t *testing.T) {
 	assert.False(t, exists)
 }
 
+func TestPayslipRepository_CheckPayslipExists_IgnoresVoided(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	payslip := createTestPayslip(t, db, employee.ID, startDate, endDate)
+	require.NoError(t, db.Model(payslip).Update("status", model.PayslipStatusVoid).Error)
+
+	exists, err := repo.CheckPayslipExists(employee.ID, startDate, endDate)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = repo.GetPayslipByEmployeeAndPeriod(employee.ID, startDate, endDate)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+// Tests for HasOverlappingPayslip function
+
+func TestPayslipRepository_HasOverlappingPayslip_Matrix(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	createTestPayslip(t, db, employee.ID, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC))
+
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		start, end time.Time
+		overlaps   bool
+	}{
+		{"same period", day(6, 1), day(6, 30), true},
+		{"containing period", day(5, 15), day(7, 15), true},
+		{"contained period", day(6, 10), day(6, 20), true},
+		{"overlapping the end", day(6, 15), day(7, 15), true},
+		{"overlapping the start", day(5, 15), day(6, 15), true},
+		{"single day inside", day(6, 10), day(6, 10), true},
+		{"starting on the last day", day(6, 30), day(7, 30), true},
+		{"ending on the first day", day(5, 1), day(6, 1), true},
+		{"single last day", day(6, 30), day(6, 30), true},
+		{"single first day", day(6, 1), day(6, 1), true},
+		{"single day after", day(7, 1), day(7, 1), false},
+		{"single day before", day(5, 31), day(5, 31), false},
+		{"next month", day(7, 1), day(7, 31), false},
+		{"previous month", day(5, 1), day(5, 31), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlaps, err := repo.HasOverlappingPayslip(employee.ID, tt.start, tt.end)
+			require.NoError(t, err)
+			assert.Equal(t, tt.overlaps, overlaps)
+		})
+	}
+}
+
+func TestPayslipRepository_HasOverlappingPayslip_IgnoresVoidedAndOtherEmployees(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	other := createTestEmployee(t, db, 2, "Jane Doe")
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	payslip := createTestPayslip(t, db, employee.ID, startDate, endDate)
+	require.NoError(t, db.Model(payslip).Update("status", model.PayslipStatusVoid).Error)
+	createTestPayslip(t, db, other.ID, startDate, endDate)
+
+	overlaps, err := repo.HasOverlappingPayslip(employee.ID, startDate.AddDate(0, 0, 14), endDate.AddDate(0, 0, 15))
+	assert.NoError(t, err)
+	assert.False(t, overlaps)
+}
+
 // Tests for GetAttendanceForPeriod function
 
 func TestPayslipRepository_GetAttendanceForPeriod_ValidData(t *testing.T) {
@@ -550,8 +776,16 @@ func TestPayslipRepository_GetOvertimeForPeriod_ValidData(t *testing.T) {
 		Reason:       "Extra work",
 		Status:       model.OvertimeApproved,
 	}
+	// Rejected status (should never be included)
+	overtime5 := &model.Overtime{
+		EmployeeID:   employee.ID,
+		OvertimeDate: "2025-06-20",
+		Hours:        3,
+		Reason:       "Unrequested work",
+		Status:       model.OvertimeRejected,
+	}
 
-	err := db.Create([]*model.Overtime{overtime1, overtime2, overtime3, overtime4}).Error
+	err := db.Create([]*model.Overtime{overtime1, overtime2, overtime3, overtime4, overtime5}).Error
 	require.NoError(t, err)
 
 	// Execute
@@ -691,6 +925,268 @@ func TestPayslipRepository_GetEmployeeByID_ZeroID(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+// Tests for BulkVoidPayslipsWithAudit function
+
+func TestPayslipRepository_BulkVoidPayslipsWithAudit_VoidsPeriod(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	employee1 := createTestEmployee(t, db, 1, "John Doe")
+	employee2 := createTestEmployee(t, db, 2, "Jane Smith")
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	createTestPayslip(t, db, employee1.ID, startDate, endDate)
+	createTestPayslip(t, db, employee2.ID, startDate, endDate)
+
+	// Payslip of another period must stay untouched
+	other := createTestPayslip(t, db, employee1.ID,
+		time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC))
+
+	auditDB := middleware.NewAuditableDB(db, 99)
+	voidedPayslips, skipped, paid, err := repo.BulkVoidPayslipsWithAudit(startDate, endDate, nil, "wrong overtime rate", auditDB)
+
+	require.NoError(t, err)
+	assert.Len(t, voidedPayslips, 2)
+	assert.Equal(t, 0, skipped)
+	assert.Empty(t, paid)
+
+	// Voided payslips are no longer returned by default
+	payslips, err := repo.GetPayslipsByPeriod(startDate, endDate)
+	assert.NoError(t, err)
+	assert.Empty(t, payslips)
+
+	// The records are kept for audit
+	var voided []model.Payslip
+	require.NoError(t, db.Where("status = ?", model.PayslipStatusVoid).Find(&voided).Error)
+	assert.Len(t, voided, 2)
+	for _, payslip := range voided {
+		assert.NotNil(t, payslip.VoidedAt)
+		assert.Equal(t, "wrong overtime rate", payslip.VoidReason)
+		require.NotNil(t, payslip.UpdatedBy)
+		assert.Equal(t, uint(99), *payslip.UpdatedBy)
+	}
+
+	untouched, err := repo.GetPayslipByID(other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.PayslipStatusProcessed, untouched.Status)
+}
+
+func TestPayslipRepository_BulkVoidPayslipsWithAudit_SkipsVoidedAndRejectsPaid(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	createTestEmployee(t, db, 1, "John Doe")
+	createTestEmployee(t, db, 2, "Jane Smith")
+	createTestEmployee(t, db, 3, "Bob Wilson")
+	createTestPayslip(t, db, 1, startDate, endDate)
+	voided := createTestPayslip(t, db, 2, startDate, endDate)
+	paid := createTestPayslip(t, db, 3, startDate, endDate)
+	require.NoError(t, db.Model(voided).Update("status", model.PayslipStatusVoid).Error)
+	require.NoError(t, db.Model(paid).Update("status", model.PayslipStatusPaid).Error)
+
+	auditDB := middleware.NewAuditableDB(db, 99)
+	voidedPayslips, skipped, refused, err := repo.BulkVoidPayslipsWithAudit(startDate, endDate, nil, "rerun", auditDB)
+
+	require.NoError(t, err)
+	require.Len(t, voidedPayslips, 1)
+	assert.Equal(t, model.PayslipStatusVoid, voidedPayslips[0].Status)
+	assert.Equal(t, 1, skipped)
+	require.Len(t, refused, 1)
+	assert.Equal(t, paid.ID, refused[0].ID)
+
+	payslips, err := repo.GetPayslipsByPeriod(startDate, endDate)
+	assert.NoError(t, err)
+	require.Len(t, payslips, 1)
+	assert.Equal(t, paid.ID, payslips[0].ID)
+}
+
+func TestPayslipRepository_BulkVoidPayslipsWithAudit_FilteredEmployees(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	createTestEmployee(t, db, 1, "John Doe")
+	createTestEmployee(t, db, 2, "Jane Smith")
+	createTestPayslip(t, db, 1, startDate, endDate)
+	kept := createTestPayslip(t, db, 2, startDate, endDate)
+
+	auditDB := middleware.NewAuditableDB(db, 99)
+	voided, _, _, err := repo.BulkVoidPayslipsWithAudit(startDate, endDate, []uint{1}, "rerun", auditDB)
+
+	require.NoError(t, err)
+	assert.Len(t, voided, 1)
+
+	payslips, err := repo.GetPayslipsByPeriod(startDate, endDate)
+	assert.NoError(t, err)
+	require.Len(t, payslips, 1)
+	assert.Equal(t, kept.ID, payslips[0].ID)
+}
+
+// Tests for ListPayslips function
+
+// createListedPayslips stores June 2025 payslips of employees 1 to 3 with totals 3M, 1M and 2M,
+// the payslip of employee 3 voided, and a July payslip of employee 1
+func createListedPayslips(t *testing.T, db *gorm.DB) {
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	for id, total := range map[uint]float64{1: 3000000, 2: 1000000, 3: 2000000} {
+		createTestEmployee(t, db, id, fmt.Sprintf("Employee %d", id))
+		payslip := createTestPayslip(t, db, id, startDate, endDate)
+		require.NoError(t, db.Model(payslip).Update("total_amount", total).Error)
+		if id == 3 {
+			require.NoError(t, db.Model(payslip).Update("status", model.PayslipStatusVoid).Error)
+		}
+	}
+	createTestPayslip(t, db, 1, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC))
+}
+
+func TestPayslipRepository_ListPayslips_Filters(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+	createListedPayslips(t, db)
+
+	june := request.PayslipFilter{
+		PayPeriodStart: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	payslips, total, err := repo.ListPayslips(june, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, payslips, 2)
+	assert.Equal(t, uint(1), payslips[0].EmployeeID)
+	assert.Equal(t, uint(2), payslips[1].EmployeeID)
+
+	voided := june
+	voided.Status = model.PayslipStatusVoid
+	payslips, total, err = repo.ListPayslips(voided, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, uint(3), payslips[0].EmployeeID)
+
+	employeeID := uint(2)
+	single := june
+	single.EmployeeID = &employeeID
+	payslips, total, err = repo.ListPayslips(single, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, uint(2), payslips[0].EmployeeID)
+}
+
+func TestPayslipRepository_ListPayslips_SortAndPage(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+	createListedPayslips(t, db)
+
+	filter := request.PayslipFilter{
+		PayPeriodStart: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+		Status:         model.PayslipStatusProcessed,
+		Sort:           request.PayslipSortTotalAmount,
+	}
+
+	payslips, total, err := repo.ListPayslips(filter, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, payslips, 1)
+	assert.Equal(t, 1000000.0, payslips[0].TotalAmount)
+
+	filter.Descending = true
+	payslips, _, err = repo.ListPayslips(filter, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, payslips, 1)
+	assert.Equal(t, 1000000.0, payslips[0].TotalAmount)
+
+	filter.Sort = "total_amount; DROP TABLE payslips"
+	_, _, err = repo.ListPayslips(filter, 0, 10)
+	assert.EqualError(t, err, `unsupported sort column "total_amount; DROP TABLE payslips"`)
+}
+
+// Tests for VoidPayslipWithAudit function
+
+func TestPayslipRepository_VoidPayslipWithAudit_KeepsRecord(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	employee := createTestEmployee(t, db, 1, "John Doe")
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	payslip := createTestPayslip(t, db, employee.ID, startDate, endDate)
+
+	auditDB := middleware.NewAuditableDB(db, 99)
+	voided, err := repo.VoidPayslipWithAudit(payslip.ID, "wrong overtime rate", nil, auditDB)
+
+	require.NoError(t, err)
+	assert.Equal(t, model.PayslipStatusVoid, voided.Status)
+
+	stored, err := repo.GetPayslipByID(payslip.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsVoid())
+	assert.NotNil(t, stored.VoidedAt)
+	assert.Equal(t, "wrong overtime rate", stored.VoidReason)
+	require.NotNil(t, stored.UpdatedBy)
+	assert.Equal(t, uint(99), *stored.UpdatedBy)
+}
+
+func TestPayslipRepository_VoidPayslipWithAudit_Rejected(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+
+	startDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	createTestEmployee(t, db, 1, "John Doe")
+	createTestEmployee(t, db, 2, "Jane Smith")
+	paid := createTestPayslip(t, db, 1, startDate, endDate)
+	voided := createTestPayslip(t, db, 2, startDate, endDate)
+	require.NoError(t, db.Model(paid).Update("status", model.PayslipStatusPaid).Error)
+	require.NoError(t, db.Model(voided).Update("status", model.PayslipStatusVoid).Error)
+
+	auditDB := middleware.NewAuditableDB(db, 99)
+
+	_, err := repo.VoidPayslipWithAudit(paid.ID, "", nil, auditDB)
+	assert.Equal(t, ErrPayslipPaid, err)
+
+	_, err = repo.VoidPayslipWithAudit(voided.ID, "", nil, auditDB)
+	assert.Equal(t, ErrPayslipVoid, err)
+
+	_, err = repo.VoidPayslipWithAudit(999, "", nil, auditDB)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	stored, err := repo.GetPayslipByID(paid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PayslipStatusPaid, stored.Status)
+}
+
+func TestPayslipRepository_VoidPayslipWithAudit_Version(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	payslip := createTestPayslip(t, db, 1, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, db.Model(payslip).Update("version", 2).Error)
+	auditDB := middleware.NewAuditableDB(db, 99)
+
+	stale := 1
+	_, err := repo.VoidPayslipWithAudit(payslip.ID, "", &stale, auditDB)
+	assert.ErrorIs(t, err, middleware.ErrStaleVersion)
+	stored, err := repo.GetPayslipByID(payslip.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.IsVoid())
+
+	current := 2
+	voided, err := repo.VoidPayslipWithAudit(payslip.ID, "", &current, auditDB)
+	require.NoError(t, err)
+	assert.Equal(t, 3, voided.Version)
+	stored, err = repo.GetPayslipByID(payslip.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsVoid())
+	assert.Equal(t, 3, stored.Version)
+}
+
 // Tests for GetDB function
 
 func TestPayslipRepository_GetDB(t *testing.T) {
@@ -798,3 +1294,73 @@ func BenchmarkPayslipRepository_GetPayslipsByEmployee(b *testing.B) {
 		_, _ = repo.GetPayslipsByEmployee(employee.ID)
 	}
 }
+
+func TestPayslipRepository_GetPayPeriodsPage(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+	createListedPayslips(t, db)
+	createTestEmployee(t, db, 4, "Employee 4")
+	createTestPayslip(t, db, 4, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC))
+
+	periods, total, err := repo.GetPayPeriodsPage(1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, periods, 3)
+
+	assert.True(t, periods[0].PayPeriodStart.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, periods[0].PayPeriodEnd.Equal(time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, periods[0].PayslipCount)
+	assert.Equal(t, 5500000.0, periods[0].TotalPayout)
+
+	// The voided payslip of employee 3 is not counted
+	assert.True(t, periods[1].PayPeriodStart.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 2, periods[1].PayslipCount)
+	assert.Equal(t, 4000000.0, periods[1].TotalPayout)
+
+	assert.True(t, periods[2].PayPeriodStart.Equal(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, periods[2].PayslipCount)
+}
+
+func TestPayslipRepository_GetPayPeriodsPage_PerCurrency(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+	start, end := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	for i, payslip := range []struct {
+		currency string
+		amount   float64
+	}{{"IDR", 5000000}, {"USD", 3000}, {"IDR", 4000000}} {
+		employee := createTestEmployee(t, db, uint(i+1), fmt.Sprintf("Employee %d", i+1))
+		require.NoError(t, db.Create(&model.Payslip{
+			EmployeeID: employee.ID, PayPeriodStart: start, PayPeriodEnd: end, TotalAmount: payslip.amount,
+			Currency: payslip.currency, ProcessedAt: time.Now(), Status: model.PayslipStatusProcessed,
+		}).Error)
+	}
+
+	periods, total, err := repo.GetPayPeriodsPage(1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, periods, 2)
+	assert.Equal(t, "IDR", periods[0].Currency)
+	assert.Equal(t, 2, periods[0].PayslipCount)
+	assert.Equal(t, 9000000.0, periods[0].TotalPayout)
+	assert.Equal(t, "USD", periods[1].Currency)
+	assert.Equal(t, 1, periods[1].PayslipCount)
+	assert.Equal(t, 3000.0, periods[1].TotalPayout)
+}
+
+func TestPayslipRepository_GetPayPeriodsPage_Pages(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPayslipRepository(db)
+	createListedPayslips(t, db)
+
+	periods, total, err := repo.GetPayPeriodsPage(2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, periods, 1)
+	assert.True(t, periods[0].PayPeriodStart.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)))
+
+	periods, total, err = repo.GetPayPeriodsPage(3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Empty(t, periods)
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenRevoked is returned when rotating a token that was already rotated or revoked
+var ErrRefreshTokenRevoked = errors.New("refresh token already revoked")
+
+type refreshToken struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new instance of refresh token repository.
+func NewRefreshTokenRepository(db *gorm.DB) *refreshToken {
+	return &refreshToken{db: db}
+}
+
+// GetDB returns the underlying GORM DB instance
+func (r *refreshToken) GetDB() *gorm.DB {
+	return r.db
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(token *model.RefreshToken) (*model.RefreshToken, error)
+	GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error)
+	RotateRefreshToken(current *model.RefreshToken, next *model.RefreshToken) (*model.RefreshToken, error)
+	RevokeRefreshTokenFamily(familyID string) error
+	GetDB() *gorm.DB
+}
+
+func (r *refreshToken) CreateRefreshToken(token *model.RefreshToken) (*model.RefreshToken, error) {
+	if err := r.db.Create(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *refreshToken) GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes the current token and stores its replacement in one transaction.
+// It fails with ErrRefreshTokenRevoked when a concurrent request already rotated the current token.
+func (r *refreshToken) RotateRefreshToken(current *model.RefreshToken, next *model.RefreshToken) (*model.RefreshToken, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenRevoked
+		}
+
+		next.FamilyID = current.FamilyID
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// RevokeRefreshTokenFamily revokes every token still active in the family
+func (r *refreshToken) RevokeRefreshTokenFamily(familyID string) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/yourname/payslip-system/internal/dto/request"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
 
@@ -28,6 +29,16 @@ func (r *reimbusement) GetDB() *gorm.DB {
 type ReimbusementRepository interface {
 	CreateReimbusement(employeeID uint, amount float64, description string) (*model.Reimbursement, error)
 	CreateReimbusementWithAudit(employeeID uint, amount float64, description string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error)
+	CreatePossibleDuplicateReimbusementWithAudit(employeeID uint, amount float64, description string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error)
+	CreateClawbackWithAudit(employeeID uint, amount float64, reason string, adminID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error)
+	CreateReimbursementBatchWithAudit(employeeID uint, drafts []model.Reimbursement, auditDB *middleware.AuditableDB) ([]*model.Reimbursement, []error, error)
+	GetReimbursementByID(reimbursementID uint) (*model.Reimbursement, error)
+	FindPotentialDuplicateReimbursement(employeeID uint, amount float64, date time.Time, windowDays int, amountTolerance float64) (*model.Reimbursement, error)
+	ApproveReimbursementWithAudit(reimbursement *model.Reimbursement, approverID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error)
+	RejectReimbursementWithAudit(reimbursement *model.Reimbursement, approverID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error)
+	GetProcessedPayslipCoveringDate(employeeID uint, date time.Time) (*model.Payslip, error)
+	AttachReceiptWithAudit(reimbursement *model.Reimbursement, path string, contentType string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error)
+	ListReimbursements(filter request.ReimbursementFilter, offset int, limit int) ([]model.Reimbursement, int64, error)
 	GetDB() *gorm.DB
 }
 
@@ -71,7 +82,18 @@ func (r *reimbusement) CreateReimbusement(employeeID uint, amount float64, descr
 
 // CreateReimbusementWithAudit creates a new reimbusement record with audit trail
 func (r *reimbusement) CreateReimbusementWithAudit(employeeID uint, amount float64, description string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
-	timeNow := time.Now()
+	return r.createReimbusementWithAudit(employeeID, amount, description, time.Now(), true, auditDB)
+}
+
+// CreatePossibleDuplicateReimbusementWithAudit creates a reimbusement the caller found to be a possible duplicate.
+// It is not held to one claim per day, the submitter is warned about the duplicate instead.
+func (r *reimbusement) CreatePossibleDuplicateReimbusementWithAudit(employeeID uint, amount float64, description string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	return r.createReimbusementWithAudit(employeeID, amount, description, time.Now(), false, auditDB)
+}
+
+// createReimbusementWithAudit creates a reimbusement dated timeNow with audit trail, refused when oncePerDay is
+// set and the employee already claimed one that day
+func (r *reimbusement) createReimbusementWithAudit(employeeID uint, amount float64, description string, timeNow time.Time, oncePerDay bool, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
 	// Check if the employee exists
 	var employee model.Employee
 	if err := r.db.First(&employee, employeeID).Error; err != nil {
@@ -82,13 +104,15 @@ func (r *reimbusement) CreateReimbusementWithAudit(employeeID uint, amount float
 	}
 
 	//check if employee already claim reimbusement
-	var existingReimbusement model.Reimbursement
-	err := r.db.Where("employee_id = ? AND DATE(reimbursement_date) = ?", employee.ID, timeNow.Format("2006-01-02")).Find(&existingReimbusement).Error
-	if err != nil {
-		return nil, fmt.Errorf("reimbusement for employee with ID %d already exists for today", employeeID)
-	}
-	if existingReimbusement.ID != 0 {
-		return nil, fmt.Errorf("reimbusement for employee with name %s already claim for today", employee.Name)
+	if oncePerDay {
+		var existingReimbusement model.Reimbursement
+		err := r.db.Where("employee_id = ? AND DATE(reimbursement_date) = ?", employee.ID, timeNow.Format("2006-01-02")).Find(&existingReimbusement).Error
+		if err != nil {
+			return nil, fmt.Errorf("reimbusement for employee with ID %d already exists for today", employeeID)
+		}
+		if existingReimbusement.ID != 0 {
+			return nil, fmt.Errorf("reimbusement for employee with name %s already claim for today", employee.Name)
+		}
 	}
 
 	// Create the reimbusement record with audit fields
@@ -98,10 +122,200 @@ func (r *reimbusement) CreateReimbusementWithAudit(employeeID uint, amount float
 		Reason:            description,
 		ReimbursementDate: timeNow,
 	}
-	err = auditDB.Create(&reimbusementRecord).Error
+	err := auditDB.Create(&reimbusementRecord).Error
 	if err != nil {
 		return nil, err
 	}
 
 	return &reimbusementRecord, nil
 }
+
+// CreateClawbackWithAudit records a negative reimbursement dated today that claws back an overpaid amount,
+// approved by the admin who recorded it. Unlike claims, several clawbacks may be recorded on one day.
+func (r *reimbusement) CreateClawbackWithAudit(employeeID uint, amount float64, reason string, adminID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	var employee model.Employee
+	if err := r.db.First(&employee, employeeID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("employee with ID %d not found", employeeID)
+		}
+		return nil, err
+	}
+
+	clawback := model.Reimbursement{
+		EmployeeID:        employeeID,
+		Amount:            amount,
+		Reason:            reason,
+		ReimbursementDate: time.Now(),
+	}
+	clawback.Approve(adminID)
+	if err := auditDB.Create(&clawback).Error; err != nil {
+		return nil, err
+	}
+	return &clawback, nil
+}
+
+// CreateReimbursementBatchWithAudit creates the amount, reason and date of each draft under the employee in one
+// transaction, with the same checks as a single reimbursement. The one claim per day rule only refuses days
+// claimed before the batch, drafts of the same batch may share a day. A failing draft is rolled back to its
+// savepoint and reported at its index in the errors without aborting the others.
+func (r *reimbusement) CreateReimbursementBatchWithAudit(employeeID uint, drafts []model.Reimbursement, auditDB *middleware.AuditableDB) ([]*model.Reimbursement, []error, error) {
+	created := make([]*model.Reimbursement, len(drafts))
+	failed := make([]error, len(drafts))
+
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txRepo := &reimbusement{db: tx}
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		// Looked up before any draft is created, so the drafts do not count against each other
+		claimed := make(map[string]bool)
+		for _, draft := range drafts {
+			day := draft.ReimbursementDate.Format("2006-01-02")
+			if _, checked := claimed[day]; checked {
+				continue
+			}
+			var count int64
+			err := tx.Model(&model.Reimbursement{}).Where("employee_id = ? AND DATE(reimbursement_date) = ?", employeeID, day).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			claimed[day] = count > 0
+		}
+
+		for i, draft := range drafts {
+			if day := draft.ReimbursementDate.Format("2006-01-02"); claimed[day] {
+				failed[i] = fmt.Errorf("reimbusement for employee with ID %d already claimed for %s", employeeID, day)
+				continue
+			}
+
+			savepoint := fmt.Sprintf("reimbursement_item_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			created[i], failed[i] = txRepo.createReimbusementWithAudit(employeeID, draft.Amount, draft.Reason, draft.ReimbursementDate, false, txAudit)
+			if failed[i] != nil {
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, failed, nil
+}
+
+// GetReimbursementByID retrieves a reimbursement by its ID
+func (r *reimbusement) GetReimbursementByID(reimbursementID uint) (*model.Reimbursement, error) {
+	var reimbursement model.Reimbursement
+	err := r.db.Where("id = ?", reimbursementID).First(&reimbursement).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reimbursement, nil
+}
+
+// ApproveReimbursementWithAudit marks a reimbursement as approved with audit trail
+func (r *reimbusement) ApproveReimbursementWithAudit(reimbursement *model.Reimbursement, approverID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	reimbursement.Approve(approverID)
+	err := auditDB.Save(reimbursement).Error
+	if err != nil {
+		return nil, err
+	}
+	return reimbursement, nil
+}
+
+// RejectReimbursementWithAudit marks a reimbursement as rejected with audit trail
+func (r *reimbusement) RejectReimbursementWithAudit(reimbursement *model.Reimbursement, approverID uint, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	reimbursement.Reject(approverID, "")
+	err := auditDB.Save(reimbursement).Error
+	if err != nil {
+		return nil, err
+	}
+	return reimbursement, nil
+}
+
+// AttachReceiptWithAudit stores the receipt file path and content type of a reimbursement with audit trail
+func (r *reimbusement) AttachReceiptWithAudit(reimbursement *model.Reimbursement, path string, contentType string, auditDB *middleware.AuditableDB) (*model.Reimbursement, error) {
+	reimbursement.AttachReceipt(path, contentType)
+	err := auditDB.Save(reimbursement).Error
+	if err != nil {
+		return nil, err
+	}
+	return reimbursement, nil
+}
+
+// GetProcessedPayslipCoveringDate finds the non-void payslip of the employee whose pay period contains
+// the date. It returns nil when the period has not been processed yet.
+func (r *reimbusement) GetProcessedPayslipCoveringDate(employeeID uint, date time.Time) (*model.Payslip, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var payslip model.Payslip
+	err := r.db.Where("employee_id = ? AND pay_period_start <= ? AND pay_period_end >= ? AND status <> ?",
+		employeeID, day, day, model.PayslipStatusVoid).
+		Order("pay_period_start DESC").
+		First(&payslip).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payslip, nil
+}
+
+// FindPotentialDuplicateReimbursement finds a non-rejected reimbursement of the employee with a similar
+// amount dated within windowDays of the given date. It returns nil when there is no match.
+func (r *reimbusement) FindPotentialDuplicateReimbursement(employeeID uint, amount float64, date time.Time, windowDays int, amountTolerance float64) (*model.Reimbursement, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	from := day.AddDate(0, 0, -windowDays)
+	to := day.AddDate(0, 0, windowDays+1)
+
+	var duplicate model.Reimbursement
+	err := r.db.Where("employee_id = ? AND reimbursement_date >= ? AND reimbursement_date < ? AND amount >= ? AND amount <= ? AND status <> ?",
+		employeeID, from, to, amount-amountTolerance, amount+amountTolerance, model.ReimbursementRejected).
+		Order("reimbursement_date DESC").First(&duplicate).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &duplicate, nil
+}
+
+// ListReimbursements retrieves limit reimbursements matching the filter from offset, newest first,
+// together with the total number of matching reimbursements
+func (r *reimbusement) ListReimbursements(filter request.ReimbursementFilter, offset int, limit int) ([]model.Reimbursement, int64, error) {
+	query := r.db.Model(&model.Reimbursement{})
+	if filter.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *filter.EmployeeID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("reimbursement_date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("reimbursement_date < ?", filter.EndDate.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reimbursements []model.Reimbursement
+	err := query.Order("reimbursement_date DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&reimbursements).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reimbursements, total, nil
+}
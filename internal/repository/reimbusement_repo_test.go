@@ -0,0 +1,136 @@
+// Package repository contains unit tests for the reimbursement repository functionality.
+//
+// FindPotentialDuplicateReimbursement tests cover:
+// 1. Same-amount submission on the same date matched
+// 2. Different amount, other employee and out of window submissions not matched
+// 3. Amount tolerance and rejected reimbursements
+//
+// ListReimbursements tests cover:
+// 1. Employee, status and inclusive date range filters combined, newest first with the total count
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+// createTestReimbursement creates a reimbursement record on the given date
+func createTestReimbursement(t testing.TB, db *gorm.DB, employeeID uint, amount float64, date time.Time, status model.ReimbursementStatus) *model.Reimbursement {
+	reimbursement := &model.Reimbursement{
+		EmployeeID:        employeeID,
+		ReimbursementDate: date,
+		Amount:            amount,
+		Category:          model.ReimbursementTravel,
+		Reason:            "Taxi to client office",
+		Status:            status,
+	}
+	require.NoError(t, db.Create(reimbursement).Error)
+	return reimbursement
+}
+
+func TestReimbursementRepository_FindPotentialDuplicateReimbursement_SameDateSameAmount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewReimbusementRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	date := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	existing := createTestReimbursement(t, db, 1, 150000, date, model.ReimbursementPending)
+
+	duplicate, err := repo.FindPotentialDuplicateReimbursement(1, 150000, date.Add(5*time.Hour), 0, 0)
+
+	require.NoError(t, err)
+	require.NotNil(t, duplicate)
+	assert.Equal(t, existing.ID, duplicate.ID)
+}
+
+func TestReimbursementRepository_FindPotentialDuplicateReimbursement_NotMatched(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewReimbusementRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	createTestEmployee(t, db, 2, "Jane Doe")
+	date := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	createTestReimbursement(t, db, 1, 150000, date, model.ReimbursementPending)
+
+	tests := []struct {
+		name       string
+		employeeID uint
+		amount     float64
+		date       time.Time
+	}{
+		{name: "different amount", employeeID: 1, amount: 175000, date: date},
+		{name: "other employee", employeeID: 2, amount: 150000, date: date},
+		{name: "outside window", employeeID: 1, amount: 150000, date: date.AddDate(0, 0, 4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duplicate, err := repo.FindPotentialDuplicateReimbursement(tt.employeeID, tt.amount, tt.date, 3, 0)
+
+			require.NoError(t, err)
+			assert.Nil(t, duplicate)
+		})
+	}
+}
+
+func TestReimbursementRepository_FindPotentialDuplicateReimbursement_ToleranceAndRejected(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewReimbusementRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	date := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	createTestReimbursement(t, db, 1, 150000, date, model.ReimbursementRejected)
+	within := createTestReimbursement(t, db, 1, 149000, date.AddDate(0, 0, -2), model.ReimbursementApproved)
+
+	duplicate, err := repo.FindPotentialDuplicateReimbursement(1, 150000, date, 3, 1000)
+
+	require.NoError(t, err)
+	require.NotNil(t, duplicate)
+	assert.Equal(t, within.ID, duplicate.ID)
+}
+
+func TestReimbursementRepository_ListReimbursements_Filters(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewReimbusementRepository(db)
+	createTestEmployee(t, db, 1, "John Doe")
+	createTestEmployee(t, db, 2, "Jane Doe")
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 15, 30, 0, 0, time.UTC) }
+	may := createTestReimbursement(t, db, 1, 100000, time.Date(2025, 5, 31, 9, 0, 0, 0, time.UTC), model.ReimbursementPending)
+	first := createTestReimbursement(t, db, 1, 110000, day(1), model.ReimbursementPending)
+	approved := createTestReimbursement(t, db, 1, 120000, day(15), model.ReimbursementApproved)
+	last := createTestReimbursement(t, db, 1, 130000, day(30), model.ReimbursementPending)
+	createTestReimbursement(t, db, 2, 140000, day(15), model.ReimbursementPending)
+
+	ids := func(reimbursements []model.Reimbursement) []uint {
+		var result []uint
+		for _, reimbursement := range reimbursements {
+			result = append(result, reimbursement.ID)
+		}
+		return result
+	}
+	employeeID := uint(1)
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	reimbursements, total, err := repo.ListReimbursements(request.ReimbursementFilter{EmployeeID: &employeeID}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, []uint{last.ID, approved.ID, first.ID, may.ID}, ids(reimbursements))
+
+	reimbursements, total, err = repo.ListReimbursements(request.ReimbursementFilter{EmployeeID: &employeeID, StartDate: &start, EndDate: &end}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total, "the end date includes the whole day")
+	assert.Equal(t, []uint{last.ID, approved.ID, first.ID}, ids(reimbursements))
+
+	reimbursements, total, err = repo.ListReimbursements(request.ReimbursementFilter{EmployeeID: &employeeID, Status: string(model.ReimbursementPending), StartDate: &start}, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []uint{first.ID}, ids(reimbursements))
+
+	_, total, err = repo.ListReimbursements(request.ReimbursementFilter{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+type roleTemplate struct {
+	db *gorm.DB
+}
+
+// NewRoleTemplateRepository creates a new instance of role template repository.
+func NewRoleTemplateRepository(db *gorm.DB) *roleTemplate {
+	return &roleTemplate{db: db}
+}
+
+// GetDB returns the underlying GORM DB instance for audit functionality
+func (r *roleTemplate) GetDB() *gorm.DB {
+	return r.db
+}
+
+type RoleTemplateRepository interface {
+	CreateRoleTemplateWithAudit(req request.CreateRoleTemplateRequest, auditDB *middleware.AuditableDB) (*model.RoleTemplate, error)
+	GetAllRoleTemplates() ([]model.RoleTemplate, error)
+	GetRoleTemplateByRole(role string) (*model.RoleTemplate, error)
+	UpdateRoleTemplateWithAudit(role string, req request.UpdateRoleTemplateRequest, auditDB *middleware.AuditableDB) (*model.RoleTemplate, error)
+	DeleteRoleTemplateWithAudit(role string, auditDB *middleware.AuditableDB) error
+	GetDB() *gorm.DB
+}
+
+// CreateRoleTemplateWithAudit creates a role template with its components
+func (r *roleTemplate) CreateRoleTemplateWithAudit(req request.CreateRoleTemplateRequest, auditDB *middleware.AuditableDB) (*model.RoleTemplate, error) {
+	var existing model.RoleTemplate
+	err := r.db.Where("role = ?", req.Role).First(&existing).Error
+	if err == nil {
+		return nil, fmt.Errorf("template for role %s already exists", req.Role)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	template := model.RoleTemplate{
+		Role:             req.Role,
+		StandardHours:    req.StandardHours,
+		OvertimeEligible: req.OvertimeEligible,
+		Components:       toTemplateComponents(req.Components),
+	}
+
+	err = auditDB.Create(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetAllRoleTemplates retrieves every role template with its components
+func (r *roleTemplate) GetAllRoleTemplates() ([]model.RoleTemplate, error) {
+	var templates []model.RoleTemplate
+	err := r.db.Preload("Components").Order("role ASC").Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetRoleTemplateByRole retrieves the template of a role with its components
+func (r *roleTemplate) GetRoleTemplateByRole(role string) (*model.RoleTemplate, error) {
+	var template model.RoleTemplate
+	err := r.db.Preload("Components").Where("role = ?", role).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// UpdateRoleTemplateWithAudit updates a role template and replaces its components.
+// Employees created from the template keep their own copy of the values.
+func (r *roleTemplate) UpdateRoleTemplateWithAudit(role string, req request.UpdateRoleTemplateRequest, auditDB *middleware.AuditableDB) (*model.RoleTemplate, error) {
+	var template model.RoleTemplate
+	err := r.db.Where("role = ?", role).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		if err := tx.Where("role_template_id = ?", template.ID).Delete(&model.RoleTemplateComponent{}).Error; err != nil {
+			return err
+		}
+
+		template.StandardHours = req.StandardHours
+		template.OvertimeEligible = req.OvertimeEligible
+		template.Components = toTemplateComponents(req.Components)
+		return txAudit.Save(&template).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// DeleteRoleTemplateWithAudit soft deletes a role template with audit fields
+func (r *roleTemplate) DeleteRoleTemplateWithAudit(role string, auditDB *middleware.AuditableDB) error {
+	var template model.RoleTemplate
+	err := r.db.Where("role = ?", role).First(&template).Error
+	if err != nil {
+		return err
+	}
+
+	return auditDB.Delete(&template).Error
+}
+
+// toTemplateComponents converts request components to template components
+func toTemplateComponents(components []request.ComponentRequest) []model.RoleTemplateComponent {
+	var result []model.RoleTemplateComponent
+	for _, component := range components {
+		result = append(result, model.RoleTemplateComponent{
+			Name:   component.Name,
+			Kind:   model.ComponentKind(component.Kind),
+			Amount: component.Amount,
+		})
+	}
+	return result
+}
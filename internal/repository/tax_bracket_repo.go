@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+type taxBracket struct {
+	db *gorm.DB
+}
+
+// NewTaxBracketRepository creates a new instance of tax bracket repository.
+func NewTaxBracketRepository(db *gorm.DB) *taxBracket {
+	return &taxBracket{db: db}
+}
+
+// GetDB returns the underlying GORM DB instance for audit functionality
+func (t *taxBracket) GetDB() *gorm.DB {
+	return t.db
+}
+
+type TaxBracketRepository interface {
+	GetAllTaxBrackets() ([]model.TaxBracket, error)
+	ReplaceTaxBracketsWithAudit(req request.ReplaceTaxBracketsRequest, auditDB *middleware.AuditableDB) ([]model.TaxBracket, error)
+	GetDB() *gorm.DB
+}
+
+// GetAllTaxBrackets retrieves every tax bracket ordered by floor
+func (t *taxBracket) GetAllTaxBrackets() ([]model.TaxBracket, error) {
+	var brackets []model.TaxBracket
+	err := t.db.Order("floor ASC").Find(&brackets).Error
+	if err != nil {
+		return nil, err
+	}
+	return brackets, nil
+}
+
+// ReplaceTaxBracketsWithAudit replaces the whole bracket table in a single transaction.
+// Brackets must not overlap and only the highest one may be unbounded.
+func (t *taxBracket) ReplaceTaxBracketsWithAudit(req request.ReplaceTaxBracketsRequest, auditDB *middleware.AuditableDB) ([]model.TaxBracket, error) {
+	brackets := make([]model.TaxBracket, 0, len(req.Brackets))
+	for _, bracket := range req.Brackets {
+		brackets = append(brackets, model.TaxBracket{
+			Floor:   bracket.Floor,
+			Ceiling: bracket.Ceiling,
+			Rate:    bracket.Rate,
+		})
+	}
+	sort.Slice(brackets, func(i, j int) bool { return brackets[i].Floor < brackets[j].Floor })
+
+	for i, bracket := range brackets {
+		if bracket.Floor < 0 || bracket.Rate < 0 || bracket.Rate > 1 {
+			return nil, fmt.Errorf("bracket from %.2f must have a non-negative floor and a rate between 0 and 1", bracket.Floor)
+		}
+		if bracket.Ceiling != 0 && bracket.Ceiling <= bracket.Floor {
+			return nil, fmt.Errorf("bracket from %.2f must have a ceiling above its floor", bracket.Floor)
+		}
+		if i > 0 {
+			previous := brackets[i-1]
+			if previous.Ceiling == 0 || bracket.Floor < previous.Ceiling {
+				return nil, fmt.Errorf("bracket from %.2f overlaps the bracket from %.2f", bracket.Floor, previous.Floor)
+			}
+		}
+	}
+
+	err := auditDB.DB.Transaction(func(tx *gorm.DB) error {
+		txAudit := middleware.NewAuditableDB(tx, auditDB.UserID)
+
+		if err := txAudit.Delete(&model.TaxBracket{}, "1 = 1").Error; err != nil {
+			return err
+		}
+		for i := range brackets {
+			if err := txAudit.Create(&brackets[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return brackets, nil
+}
@@ -0,0 +1,66 @@
+// Package repository contains unit tests for the tax bracket repository functionality.
+//
+// ReplaceTaxBracketsWithAudit tests cover:
+// 1. The previous brackets replaced by the new set, ordered by floor
+// 2. Overlapping brackets rejected without touching the stored ones
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/middleware"
+)
+
+func TestTaxBracketRepository_ReplaceTaxBracketsWithAudit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaxBracketRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+
+	_, err := repo.ReplaceTaxBracketsWithAudit(request.ReplaceTaxBracketsRequest{
+		Brackets: []request.TaxBracketRequest{{Floor: 0, Rate: 0.1}},
+	}, auditDB)
+	require.NoError(t, err)
+
+	_, err = repo.ReplaceTaxBracketsWithAudit(request.ReplaceTaxBracketsRequest{
+		Brackets: []request.TaxBracketRequest{
+			{Floor: 5000000, Rate: 0.15},
+			{Floor: 1000000, Ceiling: 5000000, Rate: 0.05},
+		},
+	}, auditDB)
+	require.NoError(t, err)
+
+	brackets, err := repo.GetAllTaxBrackets()
+	require.NoError(t, err)
+	require.Len(t, brackets, 2)
+	assert.Equal(t, 1000000.0, brackets[0].Floor)
+	assert.Equal(t, 0.05, brackets[0].Rate)
+	assert.Equal(t, 5000000.0, brackets[1].Floor)
+	assert.Equal(t, 0.0, brackets[1].Ceiling)
+}
+
+func TestTaxBracketRepository_ReplaceTaxBracketsWithAudit_RejectsOverlap(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaxBracketRepository(db)
+	auditDB := middleware.NewAuditableDB(db, 1)
+
+	_, err := repo.ReplaceTaxBracketsWithAudit(request.ReplaceTaxBracketsRequest{
+		Brackets: []request.TaxBracketRequest{{Floor: 0, Rate: 0.1}},
+	}, auditDB)
+	require.NoError(t, err)
+
+	_, err = repo.ReplaceTaxBracketsWithAudit(request.ReplaceTaxBracketsRequest{
+		Brackets: []request.TaxBracketRequest{
+			{Floor: 0, Ceiling: 5000000, Rate: 0.05},
+			{Floor: 4000000, Rate: 0.15},
+		},
+	}, auditDB)
+	assert.EqualError(t, err, "bracket from 4000000.00 overlaps the bracket from 0.00")
+
+	brackets, err := repo.GetAllTaxBrackets()
+	require.NoError(t, err)
+	require.Len(t, brackets, 1)
+	assert.Equal(t, 0.1, brackets[0].Rate)
+}
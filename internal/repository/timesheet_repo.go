@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"gorm.io/gorm"
+)
+
+type timesheet struct {
+	db *gorm.DB
+}
+
+// NewTimesheetRepository creates a new instance of timesheet repository.
+func NewTimesheetRepository(db *gorm.DB) *timesheet {
+	return &timesheet{db: db}
+}
+
+// GetDB returns the underlying GORM DB instance for audit functionality
+func (t *timesheet) GetDB() *gorm.DB {
+	return t.db
+}
+
+type TimesheetRepository interface {
+	SubmitTimesheetWithAudit(timesheetData *model.Timesheet, auditDB *middleware.AuditableDB) (*model.Timesheet, error)
+	GetTimesheetByID(timesheetID uint) (*model.Timesheet, error)
+	GetTimesheetByEmployeeAndPeriod(employeeID uint, startDate time.Time, endDate time.Time) (*model.Timesheet, error)
+	ApproveTimesheetWithAudit(timesheetData *model.Timesheet, approverID uint, auditDB *middleware.AuditableDB) (*model.Timesheet, error)
+	GetDB() *gorm.DB
+}
+
+// SubmitTimesheetWithAudit stores a submitted timesheet, locking the period for the employee
+func (t *timesheet) SubmitTimesheetWithAudit(timesheetData *model.Timesheet, auditDB *middleware.AuditableDB) (*model.Timesheet, error) {
+	var count int64
+	err := t.db.Model(&model.Timesheet{}).Where("employee_id = ? AND period_start = ? AND period_end = ?",
+		timesheetData.EmployeeID, timesheetData.PeriodStart, timesheetData.PeriodEnd).Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("timesheet already submitted for this period")
+	}
+
+	timesheetData.Status = model.TimesheetSubmitted
+	timesheetData.SubmittedAt = time.Now()
+	err = auditDB.Create(timesheetData).Error
+	if err != nil {
+		return nil, err
+	}
+	return timesheetData, nil
+}
+
+// GetTimesheetByID retrieves a timesheet by its ID
+func (t *timesheet) GetTimesheetByID(timesheetID uint) (*model.Timesheet, error) {
+	var timesheet model.Timesheet
+	err := t.db.Where("id = ?", timesheetID).First(&timesheet).Error
+	if err != nil {
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+// GetTimesheetByEmployeeAndPeriod retrieves the timesheet an employee submitted for a period
+func (t *timesheet) GetTimesheetByEmployeeAndPeriod(employeeID uint, startDate time.Time, endDate time.Time) (*model.Timesheet, error) {
+	var timesheet model.Timesheet
+	err := t.db.Where("employee_id = ? AND period_start = ? AND period_end = ?",
+		employeeID, startDate, endDate).First(&timesheet).Error
+	if err != nil {
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+// ApproveTimesheetWithAudit marks a timesheet as approved with audit trail
+func (t *timesheet) ApproveTimesheetWithAudit(timesheetData *model.Timesheet, approverID uint, auditDB *middleware.AuditableDB) (*model.Timesheet, error) {
+	timesheetData.Approve(approverID)
+	err := auditDB.Save(timesheetData).Error
+	if err != nil {
+		return nil, err
+	}
+	return timesheetData, nil
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// AllowanceRoutes initializes the routes for the fixed allowances of employees
+func (t *NewRoute) AllowanceRoutes(c *echo.Group) {
+	// Add JWT middleware to protect all allowance routes
+	c.Use(t.authenticate())
+	c.Use(mymiddleware.HeaderMiddleware)
+	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware after JWT validation
+
+	h := handler.AllowanceHandler{
+		Helper:        t.Helper,
+		Response:      t.Response,
+		AllowanceRepo: repository.NewAllowanceRepository(t.DB),
+	}
+
+	// Admin-only routes
+	adminGroup := c.Group("")
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
+	adminGroup.POST("/create", h.CreateAllowance)
+	adminGroup.GET("/get-all", h.GetAllowances)
+	adminGroup.GET("/:id", h.GetAllowance)
+	adminGroup.PUT("/edit/:id", h.EditAllowance)
+	adminGroup.DELETE("/delete/:id", h.DeleteAllowance)
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// APIKeyRoutes sets up the routes employees and admins manage API keys with
+func (t *NewRoute) APIKeyRoutes(c *echo.Group) {
+	c.Use(t.jwtAuth())
+	c.Use(mymiddleware.HeaderMiddleware)
+	c.Use(mymiddleware.EmployeeOrAdmin(t.Response))
+
+	h := handler.APIKeyHandler{
+		Helper:       t.Helper,
+		Response:     t.Response,
+		APIKeyRepo:   repository.NewAPIKeyRepository(t.DB),
+		EmployeeRepo: repository.NewEmployeeRepository(t.DB),
+	}
+
+	// Employees manage their own keys, admins anyone's
+	c.POST("", h.CreateAPIKey)
+	c.GET("", h.ListAPIKeys)
+	c.DELETE("/:id", h.RevokeAPIKey)
+}
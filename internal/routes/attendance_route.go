@@ -1,8 +1,8 @@
 package routes
 
 import (
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/handler"
 	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/repository"
@@ -10,10 +10,7 @@ import (
 
 func (t *NewRoute) AttendanceRoutes(c *echo.Group) {
 	// Add JWT middleware to protect all attendance routes
-	c.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  mymiddleware.JWT_SECRET,
-		TokenLookup: "header:Authorization:Bearer ",
-	}))
+	c.Use(t.authenticate())
 	c.Use(mymiddleware.HeaderMiddleware)
 	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware
 
@@ -22,6 +19,11 @@ func (t *NewRoute) AttendanceRoutes(c *echo.Group) {
 		Response:       t.Response,
 		BaseRepo:       repository.NewBaseRepository(t.DB),
 		AttendanceRepo: repository.NewAttendanceRepository(t.DB),
+		EmployeeRepo:   repository.NewEmployeeRepository(t.DB),
+		OvertimeRepo:   repository.NewOvertimeRepository(t.DB),
+		Anomaly:        config.LoadAttendanceAnomalyConfig(),
+		Overtime:       config.LoadOvertimeConfig(),
+		Geofence:       config.LoadGeofenceConfig(),
 	}
 
 	// Employee or Admin routes (employees can manage their own attendance)
@@ -29,4 +31,26 @@ func (t *NewRoute) AttendanceRoutes(c *echo.Group) {
 	employeeGroup.Use(mymiddleware.EmployeeOrAdmin(t.Response))
 	employeeGroup.POST("/check-in", h.CheckinAttendancePeriod)
 	employeeGroup.POST("/check-out", h.CheckOutAttendancePeriod)
+	// Checkout of the authenticated employee, no body needed
+	employeeGroup.POST("/checkout", h.CheckOutAttendance)
+
+	// Flagged attendance of a period as CSV, scoped to the caller's role
+	employeeGroup.GET("/anomalies/csv", h.ExportAttendanceAnomaliesCSV)
+
+	// Present, late and absent employees of a date, managers see their reporting subtree
+	managerGroup := c.Group("")
+	managerGroup.Use(mymiddleware.ManagerOrAdmin(t.Response))
+	managerGroup.GET("/summary", h.GetDailyAttendanceSummary)
+
+	// Attendance of a past day recorded by an admin
+	adminGroup := c.Group("")
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
+	adminGroup.POST("/backfill", h.BackfillAttendance)
+	// Attendance rebuilt from the in and out events of a biometric device export
+	adminGroup.POST("/import", h.ImportAttendance)
+	// Mistaken attendance removed, refused once its pay period has a payslip
+	adminGroup.DELETE("/:id", h.DeleteAttendance)
+
+	// One attendance with the overtime logged for its date
+	employeeGroup.GET("/:id", h.GetAttendance)
 }
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// AuditRoutes initializes the routes for reviewing the audit trail
+func (t *NewRoute) AuditRoutes(c *echo.Group) {
+	// Add JWT middleware to protect all audit routes
+	c.Use(t.authenticate())
+	c.Use(mymiddleware.HeaderMiddleware)
+
+	h := handler.AuditHandler{
+		Helper:       t.Helper,
+		Response:     t.Response,
+		AuditLogRepo: repository.NewAuditLogRepository(t.DB),
+	}
+
+	// Admin-only routes
+	adminGroup := c.Group("")
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
+	adminGroup.GET("", h.ListAuditLogs)
+
+	// Change history of a single record, oldest first
+	adminGroup.GET("/:table/:id", h.GetRecordHistory)
+}
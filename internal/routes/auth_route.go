@@ -1,9 +1,10 @@
 package routes
 
 import (
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/handler"
+	"github.com/yourname/payslip-system/internal/mailer"
 	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/repository"
 )
@@ -12,22 +13,36 @@ import (
 func (nr *NewRoute) AuthRoutes(group *echo.Group) {
 	// Initialize repositories
 	employeeRepo := repository.NewEmployeeRepository(nr.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(nr.DB)
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(employeeRepo, nr.Response)
+	authConfig := config.LoadAuthConfig()
+	authHandler := handler.NewAuthHandler(employeeRepo, refreshTokenRepo, authConfig, nr.Response, mailer.NewSMTPMailer(config.LoadMailConfig()))
+
+	// Brute-force targets are limited per client IP and per login name
+	rateLimit := mymiddleware.RateLimit(
+		mymiddleware.NewRateLimiter(authConfig.RateLimitRequests, authConfig.RateLimitWindow),
+		nr.Response,
+		mymiddleware.RateLimitByIP,
+		mymiddleware.RateLimitByJSONField("name"),
+	)
 
 	// Public routes (no authentication required)
-	group.POST("/login", authHandler.Login)
+	group.POST("/login", authHandler.Login, rateLimit)
+
+	// Refresh token routes, the refresh token in the body authenticates the request
+	group.POST("/refresh", authHandler.RefreshToken)
+	group.POST("/logout", authHandler.Logout)
+
+	// Password reset, the emailed single-use token authenticates the reset
+	group.POST("/forgot-password", authHandler.ForgotPassword, rateLimit)
+	group.POST("/reset-password", authHandler.ResetPassword, rateLimit)
 
 	// Protected routes (authentication required)
 	protected := group.Group("")
-	protected.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  mymiddleware.JWT_SECRET,
-		TokenLookup: "header:Authorization:Bearer ",
-	}))
+	protected.Use(nr.jwtAuth())
 	protected.Use(mymiddleware.HeaderMiddleware)
 
-	// Profile and token management routes
+	// Profile routes
 	protected.GET("/profile", authHandler.GetProfile)
-	protected.POST("/refresh", authHandler.RefreshToken)
 }
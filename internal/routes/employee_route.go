@@ -1,8 +1,8 @@
 package routes
 
 import (
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/handler"
 	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/repository"
@@ -11,30 +11,50 @@ import (
 // EmployeeRoutes initializes the routes for employee management
 func (t *NewRoute) EmployeeRoutes(c *echo.Group) {
 	// Add JWT middleware to protect all employee routes
-	c.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  mymiddleware.JWT_SECRET,
-		TokenLookup: "header:Authorization:Bearer ",
-	}))
+	c.Use(t.authenticate())
 	c.Use(mymiddleware.HeaderMiddleware)
 	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware after JWT validation
 
 	h := handler.EmployeeHandler{
-		Helper:       t.Helper,
-		Response:     t.Response,
-		BaseRepo:     repository.NewBaseRepository(t.DB),
-		EmployeeRepo: repository.NewEmployeeRepository(t.DB),
+		Helper:         t.Helper,
+		Response:       t.Response,
+		BaseRepo:       repository.NewBaseRepository(t.DB),
+		EmployeeRepo:   repository.NewEmployeeRepository(t.DB),
+		PayGradeRepo:   repository.NewPayGradeRepository(t.DB),
+		AttendanceRepo: repository.NewAttendanceRepository(t.DB),
+		PayslipRepo:    repository.NewPayslipRepository(t.DB),
+		Retention:      config.LoadEmployeeRetentionConfig(),
+		BaseCurrency:   config.LoadPayrollConfig().Currency,
+		Anomaly:        config.LoadAttendanceAnomalyConfig(),
 	}
 
 	// Admin-only routes
 	adminGroup := c.Group("")
-	adminGroup.Use(mymiddleware.AdminOnly(t.Response))
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
 	adminGroup.POST("/create", h.CreateEmployee)
+	adminGroup.POST("/import", h.ImportEmployees)
 	adminGroup.GET("/get-all-employee", h.GetAllEmployees)
+	// Paginated search by name, role and active flag
+	adminGroup.GET("", h.ListEmployees)
 	adminGroup.PUT("/edit/:id", h.EditEmployee)
 	adminGroup.DELETE("/delete/:id", h.DeleteEmployee)
+	// Deactivated employees keep their record but cannot log in and are left out of payroll runs
+	adminGroup.POST("/:id/deactivate", h.DeactivateEmployee)
+	adminGroup.POST("/:id/activate", h.ActivateEmployee)
+	// Sets or removes the manager, refusing reporting cycles
+	adminGroup.PUT("/:id/manager", h.AssignManager)
 
 	// Employee or Admin routes (employees can view their own data)
 	employeeGroup := c.Group("")
 	employeeGroup.Use(mymiddleware.EmployeeOrAdmin(t.Response))
 	employeeGroup.GET("/profile/:id", h.GetEmployeeByID) // Use existing method
+	// Present, absent and late days with the longest present streak of a date range
+	employeeGroup.GET("/:id/attendance-stats", h.GetAttendanceStats)
+
+	// Manager or Admin routes (managers can view their own reporting subtree)
+	managerGroup := c.Group("")
+	managerGroup.Use(mymiddleware.ManagerOrAdmin(t.Response))
+	managerGroup.GET("/:id/managed", h.GetManagedEmployees)
+	managerGroup.GET("/:id/reports", h.GetDirectReports)
+	managerGroup.GET("/departments", h.GetDepartments)
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+)
+
+// HealthRoutes registers the public liveness and readiness checks for load balancers
+func (t *NewRoute) HealthRoutes(e *echo.Echo) {
+	h := handler.HealthHandler{
+		Response: t.Response,
+		DB:       t.DB,
+		Build:    handler.NewBuildInfo(t.Response),
+	}
+
+	// Up while the process runs
+	e.GET("/health", h.Health)
+	// Up while the database answers
+	e.GET("/ready", h.Ready)
+}
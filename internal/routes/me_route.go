@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// MeRoutes initializes the self-service routes of the authenticated employee
+func (t *NewRoute) MeRoutes(c *echo.Group) {
+	c.Use(t.authenticate())
+	c.Use(mymiddleware.HeaderMiddleware)
+	c.Use(mymiddleware.AuditMiddleware())
+	c.Use(mymiddleware.EmployeeOrAdmin(t.Response))
+
+	h := handler.EmployeeHandler{
+		Helper:       t.Helper,
+		Response:     t.Response,
+		BaseRepo:     repository.NewBaseRepository(t.DB),
+		EmployeeRepo: repository.NewEmployeeRepository(t.DB),
+		Retention:    config.LoadEmployeeRetentionConfig(),
+	}
+
+	c.GET("", h.GetMe)
+	c.PATCH("", h.UpdateMe)
+}
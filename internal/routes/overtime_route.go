@@ -1,8 +1,8 @@
 package routes
 
 import (
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/handler"
 	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/repository"
@@ -10,22 +10,36 @@ import (
 
 func (t *NewRoute) OvertimeRoutes(c *echo.Group) {
 	// Add JWT middleware to protect all overtime routes
-	c.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  mymiddleware.JWT_SECRET,
-		TokenLookup: "header:Authorization:Bearer ",
-	}))
+	c.Use(t.authenticate())
 	c.Use(mymiddleware.HeaderMiddleware)
 	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware
 
+	approval := config.LoadApprovalConfig()
 	h := handler.OvertimeHandler{
 		Helper:       t.Helper,
 		Response:     t.Response,
 		BaseRepo:     repository.NewBaseRepository(t.DB),
 		OvertimeRepo: repository.NewOvertimeRepository(t.DB),
+		Approval:     approval.Overtime,
+		Limits:       config.LoadOvertimeConfig(),
+
+		DirectManagerOnly: approval.DirectManagerOnly,
+		EmployeeRepo:      repository.NewEmployeeRepository(t.DB),
 	}
 
-	// Employee or Admin routes (employees can create their own overtime)
+	// Employee or Admin routes (employees can create their own overtime and correct it while pending)
 	employeeGroup := c.Group("")
 	employeeGroup.Use(mymiddleware.EmployeeOrAdmin(t.Response))
 	employeeGroup.POST("/create", h.CreateOvertime)
+	employeeGroup.PATCH("/:id", h.UpdateOvertime)
+	// Admins list any employee, everyone else only their own overtime
+	employeeGroup.GET("", h.ListOvertimes)
+
+	// Manager or Admin routes, the handler enforces the approval tier for the amount
+	approverGroup := c.Group("")
+	approverGroup.Use(mymiddleware.ManagerOrAdmin(t.Response))
+	approverGroup.POST("/:id/approve", h.ApproveOvertime)
+	approverGroup.POST("/:id/reject", h.RejectOvertime)
+	// Kept for existing clients
+	approverGroup.PUT("/approve/:id", h.ApproveOvertime)
 }
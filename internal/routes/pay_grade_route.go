@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// PayGradeRoutes initializes the routes for pay grades
+func (t *NewRoute) PayGradeRoutes(c *echo.Group) {
+	// Add JWT middleware to protect all pay grade routes
+	c.Use(t.authenticate())
+	c.Use(mymiddleware.HeaderMiddleware)
+	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware after JWT validation
+
+	h := handler.PayGradeHandler{
+		Helper:       t.Helper,
+		Response:     t.Response,
+		PayGradeRepo: repository.NewPayGradeRepository(t.DB),
+	}
+
+	// Admin-only routes
+	adminGroup := c.Group("")
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
+	adminGroup.POST("/create", h.CreatePayGrade)
+	adminGroup.GET("/get-all", h.GetAllPayGrades)
+	adminGroup.GET("/:id", h.GetPayGrade)
+	adminGroup.PUT("/edit/:id", h.EditPayGrade)
+	adminGroup.DELETE("/delete/:id", h.DeletePayGrade)
+}
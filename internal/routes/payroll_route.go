@@ -1,8 +1,8 @@
 package routes
 
 import (
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/handler"
 	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/repository"
@@ -12,10 +12,7 @@ import (
 // PayrollRoutes sets up the payroll-related routes
 func (t *NewRoute) PayrollRoutes(c *echo.Group) {
 	// Add JWT middleware to protect all payroll routes
-	c.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  mymiddleware.JWT_SECRET,
-		TokenLookup: "header:Authorization:Bearer ",
-	}))
+	c.Use(t.authenticate())
 	c.Use(mymiddleware.HeaderMiddleware)
 	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware
 
@@ -28,19 +25,71 @@ func (t *NewRoute) PayrollRoutes(c *echo.Group) {
 		t.Response,
 	)
 
+	// Retried payroll runs sent with the same Idempotency-Key replay the first response
+	idempotent := mymiddleware.Idempotency(repository.NewIdempotencyRepository(t.DB), config.LoadIdempotencyConfig().KeyTTL, t.Response)
+
 	// Admin-only payroll management routes
 	adminGroup := c.Group("")
-	adminGroup.Use(mymiddleware.AdminOnly(t.Response))
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
 
 	// Run payroll for all employees (Admin only)
-	adminGroup.POST("/run", h.RunPayrollForAllEmployees)
+	adminGroup.POST("/run", h.RunPayrollForAllEmployees, idempotent)
 
 	// Run payroll for specific employee (Admin only)
-	adminGroup.POST("/run/employee", h.RunPayrollForEmployee)
+	adminGroup.POST("/run/employee", h.RunPayrollForEmployee, idempotent)
+
+	// Retry a failed employee of a stored payroll run (Admin only)
+	adminGroup.POST("/run/:run_id/employee/:employee_id/reprocess", h.ReprocessEmployeeInRun, idempotent)
+
+	// Dry run of an employee's payroll for a period, nothing is stored (Admin only)
+	adminGroup.GET("/preview", h.PreviewPayroll)
+
+	// Processed pay periods with payslip counts and payouts, newest first (Admin only)
+	adminGroup.GET("/periods", h.GetPayPeriods)
 
 	// Get payroll summary for admin overview (Admin only)
 	adminGroup.POST("/summary", h.GetPayrollSummary)
 
+	// Compare the payroll summaries of two periods (Admin only)
+	adminGroup.POST("/compare", h.ComparePayrollPeriods)
+
+	// Export the payroll summary as CSV for spreadsheets (Admin only)
+	adminGroup.GET("/summary/csv", h.GetPayrollSummaryCSV)
+	adminGroup.POST("/summary/csv", h.GetPayrollSummaryCSV)
+	// Export the payroll summary as an Excel workbook with summary and detail sheets (Admin only)
+	adminGroup.GET("/summary.xlsx", h.GetPayrollSummaryXLSX)
+
+	// Project payroll cost for the coming months (Admin only)
+	adminGroup.POST("/forecast", h.GetPayrollForecast)
+
+	// Processing statistics of stored payroll runs, newest first (Admin only)
+	adminGroup.GET("/runs/metrics", h.GetPayrollRunMetrics)
+
+	// Executive dashboard KPIs (Admin only)
+	adminGroup.GET("/kpis", h.GetPayrollKPIs)
+
+	// Paginated payslips of a period with filters and sorting (Admin only)
+	adminGroup.GET("/payslips", h.GetPayslips)
+
+	// Void all payslips of a period in one go (Admin only)
+	adminGroup.POST("/payslip/void", h.BulkVoidPayslips)
+
+	// Void a single payslip so its period can be processed again (Admin only)
+	adminGroup.POST("/payslip/:payslip_id/void", h.VoidPayslip)
+
+	// Email a payslip to its employee with the PDF attached (Admin only)
+	adminGroup.POST("/payslip/:payslip_id/email", h.EmailPayslip)
+
+	// Approvals that fed a payslip, for disputes (Admin only)
+	adminGroup.GET("/payslip/:payslip_id/approvals", h.GetPayslipApprovalChain)
+
+	// Manager and Admin routes, managers are scoped to their reporting subtree
+	managerGroup := c.Group("")
+	managerGroup.Use(mymiddleware.RequireRole(t.Response, "admin", "manager"))
+
+	// Flag approved overtime without attendance (Manager/Admin)
+	managerGroup.POST("/overtime-consistency", h.GetOvertimeConsistencyReport)
+
 	// Employee and Admin accessible routes
 	employeeGroup := c.Group("")
 	employeeGroup.Use(mymiddleware.EmployeeOrAdmin(t.Response))
@@ -48,6 +97,27 @@ func (t *NewRoute) PayrollRoutes(c *echo.Group) {
 	// Get list of payslips for an employee (Employee can access own, Admin can access any)
 	employeeGroup.GET("/employee/:id/payslips", h.GetPayslipsByEmployee)
 
+	// Estimate the pay of an in-progress period (Employee can access own, Admin can access any)
+	employeeGroup.POST("/employee/:id/projected-pay", h.GetProjectedPay)
+
+	// Year-end tax summary with a monthly tax table (Employee can access own, Admin can access any)
+	employeeGroup.GET("/employee/:id/tax-summary", h.GetTaxSummary)
+
+	// Year-to-date earnings totals (Employee can access own, Admin can access any)
+	employeeGroup.GET("/employee/:id/ytd", h.GetYTDEarnings)
+
+	// Payslip totals of a date range (Employee can access own, Admin can access any)
+	employeeGroup.GET("/employee/:id/summary", h.GetEmployeePayslipSummary)
+
 	// Get detailed payslip with full breakdown (Employee can access own, Admin can access any)
 	employeeGroup.GET("/payslip/:payslip_id/details", h.GetDetailedPayslip)
+
+	// Deductions of a payslip with their basis (Employee can access own, Admin can access any)
+	employeeGroup.GET("/payslip/:payslip_id/deductions", h.GetDeductionBreakdown)
+
+	// Download the payslip as a PDF (Employee can access own, Admin can access any)
+	employeeGroup.GET("/payslip/:payslip_id/pdf", h.GetPayslipPDF)
+
+	// Download the detailed payslip as a versioned JSON file (Employee can access own, Admin can access any)
+	employeeGroup.GET("/payslip/:payslip_id/export.json", h.ExportPayslipJSON)
 }
@@ -1,31 +1,54 @@
 package routes
 
 import (
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/handler"
 	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/repository"
+	"github.com/yourname/payslip-system/internal/usecases"
 )
 
 func (t *NewRoute) ReimbusementRoutes(c *echo.Group) {
 	// Add JWT middleware to protect all reimbursement routes
-	c.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  mymiddleware.JWT_SECRET,
-		TokenLookup: "header:Authorization:Bearer ",
-	}))
+	c.Use(t.authenticate())
 	c.Use(mymiddleware.HeaderMiddleware)
 	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware
 
+	approval := config.LoadApprovalConfig()
 	h := handler.ReimbusementHandler{
 		Helper:           t.Helper,
 		Response:         t.Response,
 		BaseRepo:         repository.NewBaseRepository(t.DB),
 		ReimbusementRepo: repository.NewReimbusementRepository(t.DB),
+		Approval:         approval.Reimbursement,
+		DuplicateCheck:   config.LoadDuplicateCheckConfig(),
+		Receipts:         config.LoadReceiptStorageConfig(),
+		Batch:            config.LoadReimbursementBatchConfig(),
+		Payroll:          usecases.NewPayrollUsecase(repository.NewPayslipRepository(t.DB), repository.NewEmployeeRepository(t.DB)),
+
+		DirectManagerOnly: approval.DirectManagerOnly,
+		EmployeeRepo:      repository.NewEmployeeRepository(t.DB),
 	}
 
 	// Employee or Admin routes (employees can create their own reimbursements)
 	employeeGroup := c.Group("")
 	employeeGroup.Use(mymiddleware.EmployeeOrAdmin(t.Response))
 	employeeGroup.POST("/create", h.CreateReimbusement)
+	// Several reimbursements at once under the authenticated employee, failing items reported one by one
+	employeeGroup.POST("/batch", h.CreateReimbursementBatch)
+	// Admins list any employee, everyone else only their own reimbursements
+	employeeGroup.GET("", h.ListReimbursements)
+	// Owner or admin uploads, owner, managers and admins can view and download
+	employeeGroup.GET("/:id", h.GetReimbursement)
+	employeeGroup.POST("/:id/receipt", h.UploadReceipt)
+	employeeGroup.GET("/:id/receipt", h.DownloadReceipt)
+
+	// Manager or Admin routes, the handler enforces the approval tier for the amount
+	approverGroup := c.Group("")
+	approverGroup.Use(mymiddleware.ManagerOrAdmin(t.Response))
+	approverGroup.POST("/:id/approve", h.ApproveReimbursement)
+	approverGroup.POST("/:id/reject", h.RejectReimbursement)
+	// Kept for existing clients
+	approverGroup.PUT("/approve/:id", h.ApproveReimbursement)
 }
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// RoleTemplateRoutes initializes the routes for role salary templates
+func (t *NewRoute) RoleTemplateRoutes(c *echo.Group) {
+	// Add JWT middleware to protect all role template routes
+	c.Use(t.authenticate())
+	c.Use(mymiddleware.HeaderMiddleware)
+	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware after JWT validation
+
+	h := handler.RoleTemplateHandler{
+		Helper:           t.Helper,
+		Response:         t.Response,
+		BaseRepo:         repository.NewBaseRepository(t.DB),
+		RoleTemplateRepo: repository.NewRoleTemplateRepository(t.DB),
+	}
+
+	// Admin-only routes
+	adminGroup := c.Group("")
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
+	adminGroup.POST("/create", h.CreateRoleTemplate)
+	adminGroup.GET("/get-all", h.GetAllRoleTemplates)
+	adminGroup.GET("/:role", h.GetRoleTemplate)
+	adminGroup.PUT("/edit/:role", h.EditRoleTemplate)
+	adminGroup.DELETE("/delete/:role", h.DeleteRoleTemplate)
+}
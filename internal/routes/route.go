@@ -1,12 +1,13 @@
 package routes
 
 import (
-	"net/http"
-
 	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/database"
 	"github.com/yourname/payslip-system/internal/helper"
 	responseHelper "github.com/yourname/payslip-system/internal/helper/response"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
 	"gorm.io/gorm"
 )
 
@@ -18,13 +19,19 @@ type NewRoute struct {
 	DB       *gorm.DB
 }
 
-func SetupRoutes(e *echo.Echo) {
-	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{
-			"message": "Server is up and running",
-		})
-	})
+// jwtAuth is the bearer token check of the protected route groups, refusing expired tokens and tokens
+// issued before the employee's current token version
+func (t *NewRoute) jwtAuth() echo.MiddlewareFunc {
+	return mymiddleware.JWTAuth(t.Response, repository.NewEmployeeRepository(t.DB).GetTokenVersion)
+}
+
+// authenticate is the check of the protected route groups integrations can reach, accepting an X-API-Key
+// within its scopes instead of a bearer token
+func (t *NewRoute) authenticate() echo.MiddlewareFunc {
+	return mymiddleware.Authenticate(t.Response, repository.NewEmployeeRepository(t.DB).GetTokenVersion, repository.NewAPIKeyRepository(t.DB))
+}
 
+func SetupRoutes(e *echo.Echo) {
 	api := e.Group("/api/v1")
 
 	// Initialize NewRoute
@@ -32,10 +39,13 @@ func SetupRoutes(e *echo.Echo) {
 	newRoute := &NewRoute{
 		Echo:     e,
 		Response: responseHelper.NewResponse(),
-		Helper:   helper.NewHelper{},
+		Helper:   helper.NewHelper{TimeLocation: config.LoadTimeLocation()},
 		DB:       database.DB,
 	}
 
+	// Health and readiness checks (public)
+	newRoute.HealthRoutes(e)
+
 	// Authentication Routes (public)
 	authGroup := api.Group("/auth")
 	newRoute.AuthRoutes(authGroup)
@@ -43,6 +53,15 @@ func SetupRoutes(e *echo.Echo) {
 	// Employee Routes (protected)
 	employeeGroup := api.Group("/employee")
 	newRoute.EmployeeRoutes(employeeGroup)
+
+	// API Key Routes (protected, bearer token only so a key cannot mint or revoke keys)
+	apiKeyGroup := api.Group("/api-key")
+	newRoute.APIKeyRoutes(apiKeyGroup)
+
+	// Self-service Routes (protected)
+	meGroup := api.Group("/me")
+	newRoute.MeRoutes(meGroup)
+
 	attendanceGroup := api.Group("/attendance")
 	// Register Attendance Routes
 	newRoute.AttendanceRoutes(attendanceGroup)
@@ -56,7 +75,31 @@ func SetupRoutes(e *echo.Echo) {
 	reimbusementGroup := api.Group("/reimbusement")
 	newRoute.ReimbusementRoutes(reimbusementGroup)
 
+	// Role Template Routes
+	roleTemplateGroup := api.Group("/role-template")
+	newRoute.RoleTemplateRoutes(roleTemplateGroup)
+
+	// Pay Grade Routes
+	payGradeGroup := api.Group("/pay-grade")
+	newRoute.PayGradeRoutes(payGradeGroup)
+
+	// Allowance Routes
+	allowanceGroup := api.Group("/allowance")
+	newRoute.AllowanceRoutes(allowanceGroup)
+
+	// Tax Bracket Routes
+	taxBracketGroup := api.Group("/tax-bracket")
+	newRoute.TaxBracketRoutes(taxBracketGroup)
+
+	// Timesheet Routes
+	timesheetGroup := api.Group("/timesheet")
+	newRoute.TimesheetRoutes(timesheetGroup)
+
 	// Payroll Routes
 	payrollGroup := api.Group("/payroll")
 	newRoute.PayrollRoutes(payrollGroup)
+
+	// Audit Routes
+	auditGroup := api.Group("/audit")
+	newRoute.AuditRoutes(auditGroup)
 }
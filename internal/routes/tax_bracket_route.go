@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// TaxBracketRoutes initializes the routes for income tax brackets
+func (t *NewRoute) TaxBracketRoutes(c *echo.Group) {
+	// Add JWT middleware to protect all tax bracket routes
+	c.Use(t.authenticate())
+	c.Use(mymiddleware.HeaderMiddleware)
+	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware after JWT validation
+
+	h := handler.TaxBracketHandler{
+		Helper:         t.Helper,
+		Response:       t.Response,
+		TaxBracketRepo: repository.NewTaxBracketRepository(t.DB),
+	}
+
+	// Admin-only routes
+	adminGroup := c.Group("")
+	adminGroup.Use(mymiddleware.RequireRole(t.Response, "admin"))
+	adminGroup.GET("/get-all", h.GetAllTaxBrackets)
+	adminGroup.PUT("/replace", h.ReplaceTaxBrackets)
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yourname/payslip-system/internal/handler"
+	mymiddleware "github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/repository"
+)
+
+// TimesheetRoutes initializes the routes for timesheet submission and sign-off
+func (t *NewRoute) TimesheetRoutes(c *echo.Group) {
+	// Add JWT middleware to protect all timesheet routes
+	c.Use(t.authenticate())
+	c.Use(mymiddleware.HeaderMiddleware)
+	c.Use(mymiddleware.AuditMiddleware()) // Add audit middleware
+
+	h := handler.TimesheetHandler{
+		Helper:        t.Helper,
+		Response:      t.Response,
+		BaseRepo:      repository.NewBaseRepository(t.DB),
+		TimesheetRepo: repository.NewTimesheetRepository(t.DB),
+		PayslipRepo:   repository.NewPayslipRepository(t.DB),
+	}
+
+	// Employee or Admin routes (employees can view and submit their own timesheet)
+	employeeGroup := c.Group("")
+	employeeGroup.Use(mymiddleware.EmployeeOrAdmin(t.Response))
+	employeeGroup.GET("/employee/:id", h.GetTimesheet)
+	employeeGroup.POST("/submit", h.SubmitTimesheet)
+
+	// Manager or Admin routes
+	approverGroup := c.Group("")
+	approverGroup.Use(mymiddleware.ManagerOrAdmin(t.Response))
+	approverGroup.PUT("/approve/:id", h.ApproveTimesheet)
+}
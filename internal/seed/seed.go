@@ -1,24 +1,36 @@
 package seed
 
 import (
+	"log"
 	"math/rand"
 	"time"
 
 	"github.com/bxcodec/faker/v3"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/model"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-func Run(db *gorm.DB) error {
-	rand.Seed(time.Now().UnixNano())
+// Run seeds an empty database with an admin (Admin / admin123) and cfg.Employees employees (password123),
+// plus a month of attendance, overtime and reimbursements when cfg.WithActivity is set.
+// A database that already has an admin is left untouched, so running it again is harmless.
+func Run(db *gorm.DB, cfg config.SeedConfig) error {
+	var admins int64
+	if err := db.Model(&model.Employee{}).Where("role = ?", "admin").Count(&admins).Error; err != nil {
+		return err
+	}
+	if admins > 0 {
+		log.Printf("Seeding skipped, an admin already exists")
+		return nil
+	}
 
-	//create 100 employees
-	for i := 0; i < 100; i++ {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
-		if err != nil {
-			return err
-		}
+	// Every seeded employee shares one password, hash it once
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < cfg.Employees; i++ {
 		employee := model.Employee{
 			Name:     faker.Name(),
 			Password: string(hashedPassword),
@@ -46,21 +58,24 @@ func Run(db *gorm.DB) error {
 		return err
 	}
 
-	// Generate random attendance for one month (current month)
-	// if err := generateRandomAttendance(db); err != nil {
-	// 	return err
-	// }
+	if cfg.WithActivity {
+		// Generate random attendance for one month (current month)
+		if err := generateRandomAttendance(db); err != nil {
+			return err
+		}
 
-	// Generate random overtime records
-	// if err := generateRandomOvertimes(db); err != nil {
-	// 	return err
-	// }
+		// Generate random overtime records
+		if err := generateRandomOvertimes(db); err != nil {
+			return err
+		}
 
-	// Generate random reimbursement records
-	// if err := generateRandomReimbursements(db); err != nil {
-	// 	return err
-	// }
+		// Generate random reimbursement records
+		if err := generateRandomReimbursements(db); err != nil {
+			return err
+		}
+	}
 
+	log.Printf("Seeded %d employees and the admin", cfg.Employees)
 	return nil
 }
 
@@ -209,24 +224,7 @@ func generateRandomOvertimes(db *gorm.DB) error {
 		overtimeDays := minOvertimeDays + rand.Intn(maxOvertimeDays-minOvertimeDays+1)
 
 		// Generate random overtime dates (can be workdays or holidays/weekends)
-		var selectedDates []time.Time
-		for len(selectedDates) < overtimeDays {
-			randomDay := 1 + rand.Intn(endOfMonth.Day())
-			randomDate := time.Date(now.Year(), now.Month(), randomDay, 0, 0, 0, 0, now.Location())
-
-			// Check if date already selected
-			dateExists := false
-			for _, existing := range selectedDates {
-				if existing.Equal(randomDate) {
-					dateExists = true
-					break
-				}
-			}
-
-			if !dateExists && !randomDate.After(now) {
-				selectedDates = append(selectedDates, randomDate)
-			}
-		}
+		selectedDates := randomElapsedDays(now, overtimeDays)
 
 		// Create overtime records for selected dates
 		for _, overtimeDate := range selectedDates {
@@ -327,10 +325,8 @@ func generateRandomReimbursements(db *gorm.DB) error {
 		return err
 	}
 
-	// Get current month start and end dates
+	// Reimbursements are dated in the current month up to today
 	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, -1)
 
 	// Predefined reimbursement data by category
 	reimbursementData := map[model.ReimbursementCategory]struct {
@@ -426,24 +422,7 @@ func generateRandomReimbursements(db *gorm.DB) error {
 		reimbursementCount := 1 + rand.Intn(maxReimbursements)
 
 		// Generate random reimbursement dates
-		var selectedDates []time.Time
-		for len(selectedDates) < reimbursementCount {
-			randomDay := 1 + rand.Intn(endOfMonth.Day())
-			randomDate := time.Date(now.Year(), now.Month(), randomDay, 0, 0, 0, 0, now.Location())
-
-			// Check if date already selected
-			dateExists := false
-			for _, existing := range selectedDates {
-				if existing.Equal(randomDate) {
-					dateExists = true
-					break
-				}
-			}
-
-			if !dateExists && !randomDate.After(now) {
-				selectedDates = append(selectedDates, randomDate)
-			}
-		}
+		selectedDates := randomElapsedDays(now, reimbursementCount)
 
 		// Create reimbursement records for selected dates
 		for _, reimbursementDate := range selectedDates {
@@ -522,7 +501,6 @@ func generateRandomReimbursements(db *gorm.DB) error {
 				ApprovedBy:        approvedBy,
 				ApprovedAt:        approvedAt,
 			}
-
 			if err := db.Create(&reimbursement).Error; err != nil {
 				return err
 			}
@@ -531,3 +509,18 @@ func generateRandomReimbursements(db *gorm.DB) error {
 
 	return nil
 }
+
+// randomElapsedDays picks up to count distinct days of now's month, from the 1st to today, at midnight.
+// Early in the month fewer days have elapsed than asked for, so fewer are returned.
+func randomElapsedDays(now time.Time, count int) []time.Time {
+	elapsed := now.Day()
+	if count > elapsed {
+		count = elapsed
+	}
+
+	days := make([]time.Time, 0, count)
+	for _, offset := range rand.Perm(elapsed)[:count] {
+		days = append(days, time.Date(now.Year(), now.Month(), offset+1, 0, 0, 0, 0, now.Location()))
+	}
+	return days
+}
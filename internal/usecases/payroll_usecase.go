@@ -1,141 +1,550 @@
 package usecases
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/xuri/excelize/v2"
+	"github.com/yourname/payslip-system/internal/config"
 	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/helper/pdf"
+	"github.com/yourname/payslip-system/internal/mailer"
 	"github.com/yourname/payslip-system/internal/middleware"
 	"github.com/yourname/payslip-system/internal/model"
 	"github.com/yourname/payslip-system/internal/repository"
+	"github.com/yourname/payslip-system/internal/webhook"
+	"gorm.io/gorm"
 )
 
 type PayrollUsecase struct {
 	payslipRepo  repository.PayslipRepository
 	employeeRepo repository.EmployeeRepository
+
+	// Config holds the payroll calculation policies, loaded from the environment
+	Config config.PayrollConfig
+	// Mailer emails payslips to employees, through the SMTP server of the environment
+	Mailer mailer.Mailer
+	// Webhooks tells integrations about finished payroll runs, through the endpoint of the environment
+	Webhooks webhook.Notifier
 }
 
+// ErrEmployeeNoEmail is returned when emailing a payslip to an employee without an email address
+var ErrEmployeeNoEmail = errors.New("employee has no email address")
+
+// ErrOvertimeRateNotDerivable is returned when a derived overtime rate would divide by zero working hours
+var ErrOvertimeRateNotDerivable = errors.New("overtime rate cannot be derived")
+
+// ErrReimbursementBelowFloor is returned when clawbacks take the reimbursement total of a payslip below the configured floor
+var ErrReimbursementBelowFloor = errors.New("reimbursement total is below the floor")
+
+// ErrMixedCurrencies is returned when payslips in more than one currency would be summed into one total
+var ErrMixedCurrencies = errors.New("payslips are in more than one currency")
+
+// ErrEmployeeNotManaged is returned when a manager asks for an employee outside their reporting subtree
+var ErrEmployeeNotManaged = errors.New("employee is not managed by the caller")
+
+// ErrBelowMinimumWage is returned when the minimum wage is enforced and the net pay of a full period is below it
+var ErrBelowMinimumWage = errors.New("net pay is below the minimum wage")
+
 func NewPayrollUsecase(payslipRepo repository.PayslipRepository, employeeRepo repository.EmployeeRepository) *PayrollUsecase {
+	webhookConfig := config.LoadWebhookConfig()
 	return &PayrollUsecase{
 		payslipRepo:  payslipRepo,
 		employeeRepo: employeeRepo,
+		Config:       config.LoadPayrollConfig(),
+		Mailer:       mailer.NewSMTPMailer(config.LoadMailConfig()),
+		Webhooks:     webhook.NewNotifier(webhookConfig, webhook.NewEnvEndpoints(webhookConfig)),
 	}
 }
 
 // ProcessEmployeePayroll handles the payroll calculation for a single employee
 func (uc *PayrollUsecase) ProcessEmployeePayroll(employeeID uint, req request.PayrollRequest) (*model.Payslip, error) {
+	payslip, err := uc.calculatePayslip(employeeID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.payslipRepo.CreatePayslip(payslip)
+}
+
+// ProcessEmployeePayrollWithAudit handles the payroll calculation for a single employee with audit trail
+func (uc *PayrollUsecase) ProcessEmployeePayrollWithAudit(employeeID uint, req request.PayrollRequest, auditDB *middleware.AuditableDB) (*model.Payslip, error) {
+	payslip, err := uc.calculatePayslip(employeeID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create payslip with audit trail
+	return uc.payslipRepo.CreatePayslipWithAudit(payslip, auditDB)
+}
+
+// calculatePayslip gathers the period records of an employee and computes the (unsaved) payslip
+func (uc *PayrollUsecase) calculatePayslip(employeeID uint, req request.PayrollRequest) (*model.Payslip, error) {
 	// Check if payslip already exists for this period
 	exists, err := uc.payslipRepo.CheckPayslipExists(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing payslip: %v", err)
 	}
 	if exists {
-		return nil, fmt.Errorf("payslip already exists for this period")
+		return nil, repository.ErrPayslipExists
+	}
+
+	// A different period sharing days with an existing payslip would pay those days twice
+	overlaps, err := uc.payslipRepo.HasOverlappingPayslip(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check overlapping payslips: %v", err)
+	}
+	if overlaps {
+		return nil, repository.ErrPayslipOverlaps
+	}
+
+	payslip, _, err := uc.calculatePeriodPayslip(employeeID, req)
+	return payslip, err
+}
+
+// calculatePeriodPayslip runs the payroll calculation of an employee for the period, whether or not a
+// payslip already exists for it, and returns the (unsaved) payslip with the records it was computed from
+func (uc *PayrollUsecase) calculatePeriodPayslip(employeeID uint, req request.PayrollRequest) (*model.Payslip, *payslipInputs, error) {
+	// Check the timesheet sign-off when required
+	if uc.Config.RequireApprovedTimesheet {
+		approved, err := uc.payslipRepo.CheckTimesheetApproved(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check timesheet: %v", err)
+		}
+		if !approved {
+			return nil, nil, fmt.Errorf("approved timesheet required for this period")
+		}
+	}
+
+	inputs, err := uc.loadPayslipInputs(employeeID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payslip, err := uc.computePayslip(employeeID, req, inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payslip, inputs, nil
+}
+
+// PreviewEmployeePayroll is a dry run of the payroll of an employee for the period: the full calculation
+// runs but nothing is stored, and an existing payslip for the period does not stop it. The result is the
+// detailed payslip response marked with preview, along with the warnings a run would report.
+func (uc *PayrollUsecase) PreviewEmployeePayroll(employeeID uint, req request.PayrollRequest) (*res.PayrollPreviewResponse, error) {
+	payslip, inputs, err := uc.calculatePeriodPayslip(employeeID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := uc.BuildDetailedPayslipResponse(payslip, inputs.employee, inputs.attendances, inputs.overtimes, inputs.reimbursements)
+	detail.Allowances = uc.BuildAllowanceBreakdown(payslip, inputs.components)
+	return &res.PayrollPreviewResponse{
+		DetailedPayslipResponse: detail,
+		Preview:                 true,
+		Warnings:                payslip.Warnings,
+		MinimumWageShortfall:    payslip.MinimumWageShortfall,
+	}, nil
+}
+
+// payslipInputs are the records of a period a payslip is computed from
+type payslipInputs struct {
+	employee       *model.Employee
+	attendances    []model.Attendance
+	overtimes      []model.Overtime
+	reimbursements []model.Reimbursement
+	components     []model.EmployeeComponent
+	brackets       []model.TaxBracket
+	holidays       map[string]bool
+	broughtForward float64
+}
+
+// loadPayslipInputs gathers the salary setup and the approved records of an employee for the period
+func (uc *PayrollUsecase) loadPayslipInputs(employeeID uint, req request.PayrollRequest) (*payslipInputs, error) {
+	// Get employee salary setup
+	employee, err := uc.payslipRepo.GetEmployeeByID(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employee: %v", err)
 	}
 
+	// The records are queried over the whole days of the period in the server timezone
+	period := uc.Config.PeriodRange(req.PayPeriodStart, req.PayPeriodEnd)
+
 	// Get attendance records for the period
-	attendances, err := uc.payslipRepo.GetAttendanceForPeriod(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
+	attendances, err := uc.payslipRepo.GetAttendanceForPeriod(employeeID, period.Start, period.End)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attendance records: %v", err)
 	}
 
 	// Get overtime records for the period
-	dateStart := req.PayPeriodStart.Format("2006-01-02")
-	dateEnd := req.PayPeriodEnd.Format("2006-01-02")
-	overtimes, err := uc.payslipRepo.GetOvertimeForPeriod(employeeID, dateStart, dateEnd)
+	overtimes, err := uc.payslipRepo.GetOvertimeForPeriod(employeeID, period.StartDate, period.EndDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get overtime records: %v", err)
 	}
 
+	// Get the holidays of the period, overtime on them is paid at the holiday multiplier
+	holidays, err := uc.payslipRepo.GetHolidaysForPeriod(period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holidays: %v", err)
+	}
+
 	// Get approved reimbursements for the period
-	reimbursements, err := uc.payslipRepo.GetApprovedReimbursementsForPeriod(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
+	reimbursements, err := uc.payslipRepo.GetApprovedReimbursementsForPeriod(employeeID, period.Start, period.End)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reimbursement records: %v", err)
 	}
 
-	// Calculate totals
-	attendanceDays := len(attendances)
-	totalOvertimeHours := uc.calculateTotalOvertimeHours(overtimes)
-	totalReimbursementAmount := uc.calculateTotalReimbursementAmount(reimbursements)
+	// Get recurring allowances and deductions
+	components, err := uc.payslipRepo.GetActiveComponentsForEmployee(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get salary components: %v", err)
+	}
 
-	// Calculate amounts
-	overtimeAmount := float64(totalOvertimeHours) * req.OvertimeRate
-	totalAmount := req.BasicSalary + overtimeAmount + totalReimbursementAmount
+	// Get the income tax brackets
+	brackets, err := uc.payslipRepo.GetTaxBrackets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax brackets: %v", err)
+	}
 
-	// Create payslip
-	payslip := &model.Payslip{
-		EmployeeID:          employeeID,
-		PayPeriodStart:      req.PayPeriodStart,
-		PayPeriodEnd:        req.PayPeriodEnd,
-		BasicSalary:         req.BasicSalary,
-		OvertimeHours:       totalOvertimeHours,
-		OvertimeAmount:      overtimeAmount,
-		ReimbursementAmount: totalReimbursementAmount,
-		TotalAmount:         totalAmount,
-		ProcessedAt:         time.Now(),
-		Status:              "processed",
-		AttendanceDays:      attendanceDays,
+	// Get the deduction the previous payslip could not recover
+	broughtForward, err := uc.payslipRepo.GetCarriedForwardDeduction(employeeID, req.PayPeriodStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get carried forward deduction: %v", err)
 	}
 
-	return uc.payslipRepo.CreatePayslip(payslip)
+	return &payslipInputs{
+		employee:       employee,
+		attendances:    attendances,
+		overtimes:      overtimes,
+		reimbursements: reimbursements,
+		components:     components,
+		brackets:       brackets,
+		holidays:       holidayDates(holidays),
+		broughtForward: broughtForward,
+	}, nil
 }
 
-// ProcessEmployeePayrollWithAudit handles the payroll calculation for a single employee with audit trail
-func (uc *PayrollUsecase) ProcessEmployeePayrollWithAudit(employeeID uint, req request.PayrollRequest, auditDB *middleware.AuditableDB) (*model.Payslip, error) {
-	// Check if payslip already exists for this period
-	exists, err := uc.payslipRepo.CheckPayslipExists(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
+// computePayslip computes the (unsaved) payslip from the period records
+func (uc *PayrollUsecase) computePayslip(employeeID uint, req request.PayrollRequest, inputs *payslipInputs) (*model.Payslip, error) {
+	employee := inputs.employee
+	broughtForward := inputs.broughtForward
+
+	// Calculate totals
+	attendanceDays, openAttendanceDays := uc.CountAttendanceDays(inputs.attendances)
+	totalOvertimeHours := uc.calculateTotalOvertimeHours(inputs.overtimes)
+	paidOvertimeHours := uc.calculatePaidOvertimeHours(inputs.overtimes)
+	allowanceAmount, deductionAmount := uc.ResolveComponents(inputs.components)
+
+	// Calculate amounts, each rounded to the precision of the payslip currency
+	currency, err := uc.PayslipCurrency(employee, req.Currency)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing payslip: %v", err)
+		return nil, err
 	}
-	if exists {
-		return nil, fmt.Errorf("payslip already exists for this period")
+	proratedDays, periodWorkingDays, prorationFactor := uc.CalculateProration(employee, req.PayPeriodStart, req.PayPeriodEnd)
+	fullBasicSalary := uc.ResolveBasicSalary(employee, req.BasicSalary)
+	basicSalary := uc.RoundMoney(fullBasicSalary*prorationFactor, currency)
+	dailyRate := uc.RoundMoney(uc.DailyRate(fullBasicSalary), currency)
+	absenceDeduction := uc.RoundMoney(uc.CalculateAbsenceDeduction(dailyRate, attendanceDays, prorationFactor, basicSalary), currency)
+	lateCount, lateMinutes := uc.CountLateArrivals(inputs.attendances)
+	latePenalty := uc.RoundMoney(uc.CalculateLatePenalty(lateCount, lateMinutes, basicSalary-absenceDeduction), currency)
+	overtimeRate, overtimeAmount := 0.0, 0.0
+	if employee.IsOvertimeEligible() {
+		overtimeRate, err = uc.ResolveOvertimeRate(employee, fullBasicSalary, req)
+		if err != nil {
+			return nil, err
+		}
+		overtimeAmount = uc.calculateOvertimeAmount(inputs.overtimes, inputs.holidays, overtimeRate, currency)
+	}
+	totalReimbursementAmount := uc.calculateTotalReimbursementAmount(inputs.reimbursements, currency)
+	if err := uc.CheckReimbursementFloor(totalReimbursementAmount, currency); err != nil {
+		return nil, err
 	}
+	allowanceAmount = uc.RoundMoney(allowanceAmount, currency)
+	deductionAmount = uc.RoundMoney(deductionAmount, currency)
+	grossAmount := uc.AddMoney(currency, basicSalary, overtimeAmount, totalReimbursementAmount, allowanceAmount)
+	taxableIncome := uc.AddMoney(currency, basicSalary, overtimeAmount, -absenceDeduction, -latePenalty)
+	taxAmount := uc.RoundMoney(uc.CalculateDeductions(taxableIncome, inputs.brackets), currency)
+	totalAmount := uc.AddMoney(currency, grossAmount, -taxAmount, -deductionAmount, -absenceDeduction, -latePenalty, -broughtForward)
 
-	// Get attendance records for the period
-	attendances, err := uc.payslipRepo.GetAttendanceForPeriod(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
+	totalAmount, carriedForward, err := uc.ApplyNegativeNetPolicy(totalAmount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get attendance records: %v", err)
+		return nil, err
+	}
+	shortfall, err := uc.CheckMinimumWage(totalAmount, prorationFactor, currency)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get overtime records for the period
-	dateStart := req.PayPeriodStart.Format("2006-01-02")
-	dateEnd := req.PayPeriodEnd.Format("2006-01-02")
-	overtimes, err := uc.payslipRepo.GetOvertimeForPeriod(employeeID, dateStart, dateEnd)
+	payslip := &model.Payslip{
+		EmployeeID:           employeeID,
+		PayPeriodStart:       req.PayPeriodStart,
+		PayPeriodEnd:         req.PayPeriodEnd,
+		BasicSalary:          basicSalary,
+		OvertimeHours:        totalOvertimeHours,
+		PaidOvertimeHours:    paidOvertimeHours,
+		OvertimeAmount:       overtimeAmount,
+		OvertimeRate:         overtimeRate,
+		ReimbursementAmount:  totalReimbursementAmount,
+		AllowanceAmount:      allowanceAmount,
+		DeductionAmount:      deductionAmount,
+		DailyRate:            dailyRate,
+		AbsenceDeduction:     absenceDeduction,
+		LateCount:            lateCount,
+		LatePenaltyAmount:    latePenalty,
+		BroughtForward:       broughtForward,
+		CarriedForward:       carriedForward,
+		GrossAmount:          grossAmount,
+		TaxAmount:            taxAmount,
+		NetAmount:            totalAmount,
+		TotalAmount:          totalAmount,
+		Currency:             currency,
+		ProcessedAt:          time.Now(),
+		Status:               model.PayslipStatusProcessed,
+		AttendanceDays:       attendanceDays,
+		OpenAttendanceDays:   openAttendanceDays,
+		ProratedDays:         proratedDays,
+		PeriodWorkingDays:    periodWorkingDays,
+		MinimumWageShortfall: shortfall,
+	}
+	if uc.Config.MoneyMinorUnits {
+		helper.StorePayslipMinorUnits(payslip, uc.moneyDecimals(currency))
+	}
+	payslip.Warnings = payslipWarnings(payslip, employee.StandardHours)
+	return payslip, nil
+}
+
+// payslipWarnings flags suspicious but valid figures of a computed payslip, e.g. a salary paid without
+// any attendance. The payslip is still processed, the warnings are for a reviewer. The overtime is checked
+// against the attendance days at the employee's standard hours, not at all when those are unset.
+func payslipWarnings(payslip *model.Payslip, standardHours int) []string {
+	var warnings []string
+	if payslip.AttendanceDays == 0 && payslip.BasicSalary > 0 {
+		warnings = append(warnings, fmt.Sprintf("no attendance recorded but a basic salary of %.2f is paid", payslip.BasicSalary))
+	}
+	if shortfall := payslip.MinimumWageShortfall; shortfall != nil {
+		warnings = append(warnings, fmt.Sprintf("net pay of %.2f is %.2f below the minimum wage of %.2f", shortfall.NetAmount, shortfall.Shortfall, shortfall.Floor))
+	}
+	if attendanceHours := payslip.AttendanceDays * float64(standardHours); standardHours > 0 && float64(payslip.OvertimeHours) > attendanceHours {
+		warnings = append(warnings, fmt.Sprintf("%d overtime hours exceed the %g hours of %g attendance days", payslip.OvertimeHours, attendanceHours, payslip.AttendanceDays))
+	}
+	return warnings
+}
+
+// ResolveBasicSalary returns the basic salary of a full period: the requested salary when given,
+// the default salary of the employee's pay grade otherwise (0 for employees without a grade).
+func (uc *PayrollUsecase) ResolveBasicSalary(employee *model.Employee, requested float64) float64 {
+	if requested > 0 || employee.PayGrade == nil {
+		return requested
+	}
+	return employee.PayGrade.DefaultSalary
+}
+
+// DailyRate returns the basic salary of one standard working day, 0 when the working days per period
+// are not configured
+func (uc *PayrollUsecase) DailyRate(basicSalary float64) float64 {
+	if uc.Config.WorkingDaysPerPeriod <= 0 {
+		return 0
+	}
+	return basicSalary / float64(uc.Config.WorkingDaysPerPeriod)
+}
+
+// ResolveOvertimeRate returns the hourly overtime rate of the employee: the requested rate, or with
+// DeriveOvertimeRate the hourly rate of the full basic salary over the standard working days and hours of
+// the employee, scaled by the overtime rate multiplier. A rate cannot be derived without working days or
+// standard hours.
+func (uc *PayrollUsecase) ResolveOvertimeRate(employee *model.Employee, basicSalary float64, req request.PayrollRequest) (float64, error) {
+	if !req.DeriveOvertimeRate {
+		return req.OvertimeRate, nil
+	}
+	if uc.Config.WorkingDaysPerPeriod <= 0 {
+		return 0, fmt.Errorf("%w: working days per period are not configured", ErrOvertimeRateNotDerivable)
+	}
+	if employee.StandardHours <= 0 {
+		return 0, fmt.Errorf("%w: employee %d has no standard hours", ErrOvertimeRateNotDerivable, employee.ID)
+	}
+	periodHours := float64(uc.Config.WorkingDaysPerPeriod * employee.StandardHours)
+	return basicSalary / periodHours * uc.Config.OvertimeRateMultiplier, nil
+}
+
+// CalculateAbsenceDeduction returns the daily rate for each standard working day without attendance when
+// absence deductions are enabled. A mid-period joiner is only expected for the prorated share of the days
+// and the deduction never exceeds the (prorated) basic salary.
+func (uc *PayrollUsecase) CalculateAbsenceDeduction(dailyRate, attendanceDays, prorationFactor, basicSalary float64) float64 {
+	if !uc.Config.AbsenceDeduction || dailyRate == 0 {
+		return 0
+	}
+	absentDays := float64(uc.Config.WorkingDaysPerPeriod)*prorationFactor - attendanceDays
+	if absentDays <= 0 {
+		return 0
+	}
+	return min(absentDays*dailyRate, basicSalary)
+}
+
+// CountLateArrivals counts the attended days checked in after the work start time and grace period in the server
+// timezone, or recorded as late, with the total minutes late counted from the work start. How long the employee
+// worked that day does not matter.
+func (uc *PayrollUsecase) CountLateArrivals(attendances []model.Attendance) (int, int) {
+	count, minutes := 0, 0
+	for _, attendance := range attendances {
+		if !attendance.IsPresent() {
+			continue
+		}
+		if attendance.Checkin.After(uc.Config.LateAfter(attendance.Checkin)) {
+			count++
+			minutes += int(math.Ceil(attendance.Checkin.Sub(uc.Config.WorkStart(attendance.Checkin)).Minutes()))
+		} else if attendance.Status == model.AttendanceStatusLate {
+			count++
+		}
+	}
+	return count, minutes
+}
+
+// CalculateLatePenalty returns the amount docked for late arrivals under the configured penalty mode,
+// never more than the basic salary left after absence deductions
+func (uc *PayrollUsecase) CalculateLatePenalty(lateCount, lateMinutes int, remainingBasicSalary float64) float64 {
+	var penalty float64
+	switch uc.Config.LatePenaltyMode {
+	case config.LatePenaltyPerIncident:
+		penalty = float64(lateCount) * uc.Config.LatePenaltyAmount
+	case config.LatePenaltyPerMinute:
+		penalty = float64(lateMinutes) * uc.Config.LatePenaltyAmount
+	default:
+		return 0
+	}
+	return max(min(penalty, remainingBasicSalary), 0)
+}
+
+// PayslipCurrency resolves the currency of an employee's payslip. The employee's salary currency wins,
+// the requested currency is used for employees without one and the base currency when neither is set.
+// A requested currency other than the employee's is refused, the amounts would be mixed.
+func (uc *PayrollUsecase) PayslipCurrency(employee *model.Employee, requested string) (string, error) {
+	requested = strings.ToUpper(requested)
+	if employee.Currency == "" {
+		if requested == "" {
+			return uc.Config.Currency, nil
+		}
+		return requested, nil
+	}
+	if requested != "" && requested != employee.Currency {
+		return "", fmt.Errorf("payroll currency %s does not match the employee currency %s", requested, employee.Currency)
+	}
+	return employee.Currency, nil
+}
+
+// RoundMoney rounds an amount by the configured money rounding, to the configured decimal places or else
+// those of the currency. Amounts are rounded at every computation step so no float residue builds up.
+func (uc *PayrollUsecase) RoundMoney(amount float64, currency string) float64 {
+	decimals := uc.moneyDecimals(currency)
+	if uc.Config.MoneyRounding == config.MoneyRoundHalfEven {
+		return helper.RoundFloatHalfEven(amount, decimals)
+	}
+	return helper.RoundFloat(amount, decimals)
+}
+
+// AddMoney sums amounts in integer minor units of the currency, each amount rounded first, so the sum is
+// exact (0.1 + 0.2 is 0.3). Subtract by passing a negative amount.
+func (uc *PayrollUsecase) AddMoney(currency string, amounts ...float64) float64 {
+	decimals := uc.moneyDecimals(currency)
+	var total int64
+	for _, amount := range amounts {
+		total += helper.ToMinorUnits(uc.RoundMoney(amount, currency), decimals)
+	}
+	return helper.FromMinorUnits(total, decimals)
+}
+
+// moneyDecimals returns the decimal places amounts of the currency are rounded to
+func (uc *PayrollUsecase) moneyDecimals(currency string) uint {
+	return helper.MoneyDecimals(currency, uc.Config.MoneyDecimals)
+}
+
+// GetProjectedPay estimates the pay of an in-progress period from the approved records, nothing is stored.
+// With IncludePending the pending overtime and reimbursements are listed separately and a best-case
+// "if approved" estimate is added next to the approved-only one.
+func (uc *PayrollUsecase) GetProjectedPay(employeeID uint, req request.ProjectedPayRequest) (*res.ProjectedPayResponse, error) {
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart: req.PayPeriodStart,
+		PayPeriodEnd:   req.PayPeriodEnd,
+		BasicSalary:    req.BasicSalary,
+		OvertimeRate:   req.OvertimeRate,
+		Currency:       req.Currency,
+	}
+
+	inputs, err := uc.loadPayslipInputs(employeeID, payrollReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get overtime records: %v", err)
+		return nil, err
 	}
 
-	// Get approved reimbursements for the period
-	reimbursements, err := uc.payslipRepo.GetApprovedReimbursementsForPeriod(employeeID, req.PayPeriodStart, req.PayPeriodEnd)
+	approved, err := uc.computePayslip(employeeID, payrollReq, inputs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get reimbursement records: %v", err)
+		return nil, err
 	}
 
-	// Calculate totals
-	attendanceDays := len(attendances)
-	totalOvertimeHours := uc.calculateTotalOvertimeHours(overtimes)
-	totalReimbursementAmount := uc.calculateTotalReimbursementAmount(reimbursements)
+	projection := &res.ProjectedPayResponse{
+		EmployeeID:     employeeID,
+		PayPeriodStart: req.PayPeriodStart,
+		PayPeriodEnd:   req.PayPeriodEnd,
+		Currency:       approved.Currency,
+		ApprovedOnly:   projectedPayFigures(approved),
+		IncludePending: req.IncludePending,
+	}
+	if !req.IncludePending {
+		return projection, nil
+	}
 
-	// Calculate amounts
-	overtimeAmount := float64(totalOvertimeHours) * req.OvertimeRate
-	totalAmount := req.BasicSalary + overtimeAmount + totalReimbursementAmount
+	// Get the records still awaiting approval
+	period := uc.Config.PeriodRange(req.PayPeriodStart, req.PayPeriodEnd)
+	pendingOvertimes, err := uc.payslipRepo.GetPendingOvertimeForPeriod(employeeID, period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending overtime records: %v", err)
+	}
+	pendingReimbursements, err := uc.payslipRepo.GetPendingReimbursementsForPeriod(employeeID, period.Start, period.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending reimbursement records: %v", err)
+	}
 
-	// Create payslip with audit trail
-	payslip := &model.Payslip{
-		EmployeeID:          employeeID,
-		PayPeriodStart:      req.PayPeriodStart,
-		PayPeriodEnd:        req.PayPeriodEnd,
-		BasicSalary:         req.BasicSalary,
-		OvertimeHours:       totalOvertimeHours,
-		OvertimeAmount:      overtimeAmount,
-		ReimbursementAmount: totalReimbursementAmount,
-		TotalAmount:         totalAmount,
-		ProcessedAt:         time.Now(),
-		Status:              "processed",
-		AttendanceDays:      attendanceDays,
+	// Recompute as if every pending item was approved
+	withPending := *inputs
+	withPending.overtimes = append(append([]model.Overtime{}, inputs.overtimes...), pendingOvertimes...)
+	withPending.reimbursements = append(append([]model.Reimbursement{}, inputs.reimbursements...), pendingReimbursements...)
+	ifApproved, err := uc.computePayslip(employeeID, payrollReq, &withPending)
+	if err != nil {
+		return nil, err
 	}
 
-	return uc.payslipRepo.CreatePayslipWithAudit(payslip, auditDB)
+	figures := projectedPayFigures(ifApproved)
+	projection.IfApproved = &figures
+	projection.Pending = &res.PendingProjectionItems{
+		OvertimeCount:       len(pendingOvertimes),
+		OvertimeHours:       uc.calculateTotalOvertimeHours(pendingOvertimes),
+		ReimbursementCount:  len(pendingReimbursements),
+		ReimbursementAmount: uc.calculateTotalReimbursementAmount(pendingReimbursements, approved.Currency),
+	}
+	projection.Note = "if_approved is a best-case estimate assuming every pending item is approved, actual payroll only includes approved items"
+
+	return projection, nil
+}
+
+// projectedPayFigures picks the amounts of a computed payslip shown in a projection
+func projectedPayFigures(payslip *model.Payslip) res.ProjectedPayFigures {
+	return res.ProjectedPayFigures{
+		PaidOvertimeHours:   payslip.PaidOvertimeHours,
+		OvertimeAmount:      payslip.OvertimeAmount,
+		ReimbursementAmount: payslip.ReimbursementAmount,
+		GrossAmount:         payslip.GrossAmount,
+		TaxAmount:           payslip.TaxAmount,
+		NetAmount:           payslip.NetAmount,
+	}
 }
 
 // ProcessAllEmployeesPayroll processes payroll for all active employees
@@ -149,197 +558,1678 @@ func (uc *PayrollUsecase) ProcessAllEmployeesPayroll(req request.PayrollRequest)
 	var processedPayslips []model.Payslip
 	var errors []string
 
-	for _, employee := range employees {
-		payslip, err := uc.ProcessEmployeePayroll(employee.ID, req)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("Employee %d: %s", employee.ID, err.Error()))
+	outcomes := uc.processEmployees(employees, func(employeeID uint) (*model.Payslip, error) {
+		return uc.ProcessEmployeePayroll(employeeID, req)
+	})
+	for _, outcome := range outcomes {
+		if outcome.err != nil {
+			errors = append(errors, fmt.Sprintf("Employee %d: %s", outcome.employeeID, outcome.err.Error()))
 			continue
 		}
-		processedPayslips = append(processedPayslips, *payslip)
+		processedPayslips = append(processedPayslips, *outcome.payslip)
 	}
 
 	return processedPayslips, errors
 }
 
+// employeePayrollOutcome is the result of processing the payroll of one employee
+type employeePayrollOutcome struct {
+	employeeID uint
+	payslip    *model.Payslip
+	err        error
+}
+
+// processEmployees runs process for every employee on a pool of Config.Workers goroutines.
+// The outcomes come back in employee ID order, whichever worker finished first.
+func (uc *PayrollUsecase) processEmployees(employees []model.Employee, process func(employeeID uint) (*model.Payslip, error)) []employeePayrollOutcome {
+	sort.Slice(employees, func(i, j int) bool { return employees[i].ID < employees[j].ID })
+	outcomes := make([]employeePayrollOutcome, len(employees))
+
+	workers := min(max(uc.Config.Workers, 1), len(employees))
+	if workers <= 1 {
+		for i, employee := range employees {
+			payslip, err := process(employee.ID)
+			outcomes[i] = employeePayrollOutcome{employeeID: employee.ID, payslip: payslip, err: err}
+		}
+		return outcomes
+	}
+
+	type indexedOutcome struct {
+		index   int
+		outcome employeePayrollOutcome
+	}
+	jobs := make(chan int)
+	results := make(chan indexedOutcome)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				payslip, err := process(employees[i].ID)
+				results <- indexedOutcome{index: i, outcome: employeePayrollOutcome{employeeID: employees[i].ID, payslip: payslip, err: err}}
+			}
+		}()
+	}
+	go func() {
+		for i := range employees {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Only this goroutine writes the outcomes
+	for result := range results {
+		outcomes[result.index] = result.outcome
+	}
+	return outcomes
+}
+
 // ProcessAllEmployeesPayrollWithAudit processes payroll for all active employees with audit trail
 func (uc *PayrollUsecase) ProcessAllEmployeesPayrollWithAudit(req request.PayrollRequest, auditDB *middleware.AuditableDB) ([]model.Payslip, []string) {
+	_, processedPayslips, errors := uc.ProcessPayrollRunWithAudit(req, auditDB)
+	return processedPayslips, errors
+}
+
+// PayrollCompletedEvent summarizes a finished payroll run for webhooks, the run is nil when storing it failed
+func (uc *PayrollUsecase) PayrollCompletedEvent(req request.PayrollRequest, run *model.PayrollRun, payslips []model.Payslip, errors []string) res.PayrollCompletedEvent {
+	event := res.PayrollCompletedEvent{
+		PayPeriodStart: req.PayPeriodStart.Format("2006-01-02"),
+		PayPeriodEnd:   req.PayPeriodEnd.Format("2006-01-02"),
+		ProcessedCount: len(payslips),
+		ErrorCount:     len(errors),
+		NetTotals:      map[string]float64{},
+		Errors:         append([]string{}, errors...),
+	}
+	if run != nil {
+		event.RunID = &run.ID
+	}
+	for _, payslip := range payslips {
+		event.NetTotals[payslip.Currency] = uc.RoundMoney(event.NetTotals[payslip.Currency]+payslip.NetAmount, payslip.Currency)
+	}
+	return event
+}
+
+// ProcessPayrollRunWithAudit processes payroll for all active employees and stores the run
+// with the outcome per employee, so failed employees can be reprocessed later
+func (uc *PayrollUsecase) ProcessPayrollRunWithAudit(req request.PayrollRequest, auditDB *middleware.AuditableDB) (*model.PayrollRun, []model.Payslip, []string) {
 	// Get all active employees
 	employees, err := uc.employeeRepo.GetAllActiveEmployees()
 	if err != nil {
-		return nil, []string{fmt.Sprintf("Failed to get employees: %v", err)}
+		return nil, nil, []string{fmt.Sprintf("Failed to get employees: %v", err)}
 	}
 
+	startedAt := time.Now()
+	run := &model.PayrollRun{
+		PayPeriodStart:     req.PayPeriodStart,
+		PayPeriodEnd:       req.PayPeriodEnd,
+		BasicSalary:        req.BasicSalary,
+		OvertimeRate:       req.OvertimeRate,
+		DeriveOvertimeRate: req.DeriveOvertimeRate,
+		Currency:           req.Currency,
+	}
 	var processedPayslips []model.Payslip
 	var errors []string
 
-	for _, employee := range employees {
-		payslip, err := uc.ProcessEmployeePayrollWithAudit(employee.ID, req, auditDB)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("Employee %d: %s", employee.ID, err.Error()))
+	// Each employee writes through its own session, the statements of concurrent workers stay apart
+	outcomes := uc.processEmployees(employees, func(employeeID uint) (*model.Payslip, error) {
+		session := middleware.NewAuditableDB(auditDB.Session(&gorm.Session{}), auditDB.UserID)
+		return uc.ProcessEmployeePayrollWithAudit(employeeID, req, session)
+	})
+	for _, outcome := range outcomes {
+		result := model.PayrollRunResult{EmployeeID: outcome.employeeID, Attempts: 1}
+		if outcome.err != nil {
+			result.MarkFailed(outcome.err.Error())
+			run.Results = append(run.Results, result)
+			errors = append(errors, fmt.Sprintf("Employee %d: %s", outcome.employeeID, outcome.err.Error()))
 			continue
 		}
-		processedPayslips = append(processedPayslips, *payslip)
+		result.MarkSucceeded(outcome.payslip.ID)
+		run.Results = append(run.Results, result)
+		processedPayslips = append(processedPayslips, *outcome.payslip)
 	}
+	run.RecountResults()
+	run.RecordTiming(startedAt, time.Now())
 
-	return processedPayslips, errors
+	if _, err := uc.payslipRepo.CreatePayrollRunWithAudit(run, auditDB); err != nil {
+		return nil, processedPayslips, append(errors, fmt.Sprintf("Failed to store payroll run: %v", err))
+	}
+
+	return run, processedPayslips, errors
 }
 
-// BuildDetailedPayslipResponse constructs the detailed payslip response
-func (uc *PayrollUsecase) BuildDetailedPayslipResponse(payslip *model.Payslip, employee *model.Employee, attendances []model.Attendance, overtimes []model.Overtime, reimbursements []model.Reimbursement) map[string]interface{} {
-	// Build attendance breakdown
-	attendanceBreakdown := uc.buildAttendanceBreakdown(attendances)
+// GetPayrollRunMetrics returns the processing statistics of a page of stored payroll runs,
+// newest first, together with the total number of runs
+func (uc *PayrollUsecase) GetPayrollRunMetrics(page int, perPage int) ([]res.PayrollRunMetric, int64, error) {
+	runs, total, err := uc.payslipRepo.GetPayrollRunsPage(page, perPage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get payroll runs: %v", err)
+	}
 
-	// Build overtime breakdown with calculated amounts
-	overtimeBreakdown := uc.buildOvertimeBreakdown(overtimes, payslip)
+	metrics := make([]res.PayrollRunMetric, 0, len(runs))
+	for _, run := range runs {
+		metrics = append(metrics, res.PayrollRunMetric{
+			RunID:              run.ID,
+			PayPeriodStart:     run.PayPeriodStart,
+			PayPeriodEnd:       run.PayPeriodEnd,
+			EmployeesProcessed: run.ProcessedCount + run.ErrorCount,
+			SucceededCount:     run.ProcessedCount,
+			ErrorCount:         run.ErrorCount,
+			DurationMs:         run.DurationMs,
+			StartedAt:          run.StartedAt,
+			FinishedAt:         run.FinishedAt,
+			CreatedAt:          run.CreatedAt,
+		})
+	}
+	return metrics, total, nil
+}
 
-	// Build reimbursement breakdown
-	reimbursementBreakdown := uc.buildReimbursementBreakdown(reimbursements)
+// ReprocessEmployeeInRunWithAudit retries a failed employee of a run with the run's parameters
+// and updates the stored result. Succeeded employees are refused, and the payslip exists check
+// keeps a retry from creating a second payslip for the period.
+func (uc *PayrollUsecase) ReprocessEmployeeInRunWithAudit(run *model.PayrollRun, employeeID uint, auditDB *middleware.AuditableDB) (*model.PayrollRunResult, error) {
+	result := run.ResultForEmployee(employeeID)
+	if result == nil {
+		return nil, fmt.Errorf("employee %d is not part of payroll run %d", employeeID, run.ID)
+	}
+	if result.IsSucceeded() {
+		return nil, fmt.Errorf("employee %d already succeeded in payroll run %d", employeeID, run.ID)
+	}
 
-	// Build summary
-	summary := map[string]interface{}{
-		"basic_salary":          payslip.BasicSalary,
-		"total_attendance_days": payslip.AttendanceDays,
-		"total_overtime_hours":  payslip.OvertimeHours,
-		"overtime_amount":       payslip.OvertimeAmount,
-		"reimbursement_amount":  payslip.ReimbursementAmount,
-		"total_take_home_pay":   payslip.TotalAmount,
+	req := request.PayrollRequest{
+		PayPeriodStart:     run.PayPeriodStart,
+		PayPeriodEnd:       run.PayPeriodEnd,
+		BasicSalary:        run.BasicSalary,
+		OvertimeRate:       run.OvertimeRate,
+		DeriveOvertimeRate: run.DeriveOvertimeRate,
+		Currency:           run.Currency,
 	}
 
-	return map[string]interface{}{
-		"payslip_id":              payslip.ID,
-		"employee_id":             payslip.EmployeeID,
-		"employee_name":           employee.Name,
-		"pay_period_start":        payslip.PayPeriodStart,
-		"pay_period_end":          payslip.PayPeriodEnd,
-		"processed_at":            payslip.ProcessedAt,
-		"status":                  payslip.Status,
-		"summary":                 summary,
-		"attendance_breakdown":    attendanceBreakdown,
-		"overtime_breakdown":      overtimeBreakdown,
-		"reimbursement_breakdown": reimbursementBreakdown,
+	result.Attempts++
+	payslip, processErr := uc.ProcessEmployeePayrollWithAudit(employeeID, req, auditDB)
+	if processErr != nil {
+		result.MarkFailed(processErr.Error())
+	} else {
+		result.MarkSucceeded(payslip.ID)
 	}
-}
+	run.RecountResults()
 
-// BuildPayrollSummary constructs the payroll summary response
-func (uc *PayrollUsecase) BuildPayrollSummary(payslips []model.Payslip) map[string]interface{} {
-	var employeeSummaries []map[string]interface{}
-	var totalTakeHomePay float64
-	var totalBasicSalary float64
-	var totalOvertimeAmount float64
-	var totalReimbursementAmount float64
-	var totalAttendanceDays int
-	var totalOvertimeHours int
-
-	// Group payslips by employee to get employee totals
-	employeePayslips := make(map[uint][]model.Payslip)
-	employeeNames := make(map[uint]string)
+	if err := uc.payslipRepo.UpdatePayrollRunResultWithAudit(run, result, auditDB); err != nil {
+		return nil, fmt.Errorf("failed to update payroll run: %v", err)
+	}
+	if processErr != nil {
+		return result, processErr
+	}
+	return result, nil
+}
 
-	for _, payslip := range payslips {
-		employeePayslips[payslip.EmployeeID] = append(employeePayslips[payslip.EmployeeID], payslip)
+// BuildOvertimeConsistencyReport flags approved overtime entries whose date has no present
+// attendance for the same employee. Consistent entries are left out of the report. A managerID limits
+// the report to the manager and their reporting subtree, 0 checks every employee.
+func (uc *PayrollUsecase) BuildOvertimeConsistencyReport(req request.OvertimeConsistencyRequest, managerID uint) (*res.OvertimeConsistencyReport, error) {
+	var scope map[uint]bool
+	if managerID != 0 {
+		managed, err := uc.employeeRepo.GetManagedEmployees(managerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed employees: %v", err)
+		}
+		scope = map[uint]bool{managerID: true}
+		for _, employee := range managed {
+			scope[employee.ID] = true
+		}
+		if req.EmployeeID != 0 && !scope[req.EmployeeID] {
+			return nil, ErrEmployeeNotManaged
+		}
+	}
 
-		// Get employee name if we don't have it yet
-		if _, exists := employeeNames[payslip.EmployeeID]; !exists {
-			employee, err := uc.payslipRepo.GetEmployeeByID(payslip.EmployeeID)
-			if err == nil {
-				employeeNames[payslip.EmployeeID] = employee.Name
-			} else {
-				employeeNames[payslip.EmployeeID] = "Unknown Employee"
-			}
+	var employees []model.Employee
+	if req.EmployeeID != 0 {
+		employee, err := uc.payslipRepo.GetEmployeeByID(req.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get employee: %v", err)
+		}
+		employees = append(employees, *employee)
+	} else {
+		allEmployees, err := uc.employeeRepo.GetAllEmployees()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get employees: %v", err)
 		}
+		employees = allEmployees
 	}
 
-	// Calculate totals for each employee
-	for employeeID, empPayslips := range employeePayslips {
-		employeeSummary := uc.calculateEmployeeSummary(employeeID, empPayslips, employeeNames[employeeID])
-		employeeSummaries = append(employeeSummaries, employeeSummary)
+	report := &res.OvertimeConsistencyReport{
+		PeriodStart: req.PeriodStart,
+		PeriodEnd:   req.PeriodEnd,
+		Flagged:     []res.OvertimeInconsistency{},
+	}
 
-		// Add to overall totals
-		totalTakeHomePay += employeeSummary["total_take_home_pay"].(float64)
-		totalBasicSalary += employeeSummary["total_basic_salary"].(float64)
-		totalOvertimeAmount += employeeSummary["total_overtime_amount"].(float64)
-		totalReimbursementAmount += employeeSummary["total_reimbursement"].(float64)
-		totalAttendanceDays += employeeSummary["total_attendance_days"].(int)
-		totalOvertimeHours += employeeSummary["total_overtime_hours"].(int)
-	}
-
-	// Calculate averages
-	summaryTotals := uc.calculateSummaryTotals(
-		employeeSummaries,
-		payslips,
-		totalTakeHomePay,
-		totalBasicSalary,
-		totalOvertimeAmount,
-		totalReimbursementAmount,
-		totalAttendanceDays,
-		totalOvertimeHours,
-	)
+	period := uc.Config.PeriodRange(req.PeriodStart, req.PeriodEnd)
+	for _, employee := range employees {
+		if scope != nil && !scope[employee.ID] {
+			continue
+		}
+		overtimes, err := uc.payslipRepo.GetOvertimeForPeriod(employee.ID, period.StartDate, period.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get overtime records: %v", err)
+		}
+		if len(overtimes) == 0 {
+			continue
+		}
 
-	return map[string]interface{}{
-		"summary_totals":     summaryTotals,
-		"employee_summaries": employeeSummaries,
-	}
-}
+		attendances, err := uc.payslipRepo.GetAttendanceForPeriod(employee.ID, period.Start, period.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attendance records: %v", err)
+		}
 
-// Helper functions for calculations and data building
+		presentDates := make(map[string]bool)
+		for _, attendance := range attendances {
+			if attendance.IsPresent() {
+				presentDates[attendance.Date.In(uc.Config.TimeLocation()).Format("2006-01-02")] = true
+			}
+		}
 
-func (uc *PayrollUsecase) calculateTotalOvertimeHours(overtimes []model.Overtime) int {
-	totalOvertimeHours := 0
-	for _, overtime := range overtimes {
-		totalOvertimeHours += overtime.Hours
+		for _, overtime := range overtimes {
+			report.CheckedCount++
+			if presentDates[overtime.OvertimeDate] {
+				continue
+			}
+			report.Flagged = append(report.Flagged, res.OvertimeInconsistency{
+				OvertimeID:   overtime.ID,
+				EmployeeID:   employee.ID,
+				EmployeeName: employee.Name,
+				OvertimeDate: overtime.OvertimeDate,
+				Hours:        overtime.Hours,
+				Reason:       overtime.Reason,
+			})
+		}
 	}
-	return totalOvertimeHours
-}
+	report.FlaggedCount = len(report.Flagged)
 
-func (uc *PayrollUsecase) calculateTotalReimbursementAmount(reimbursements []model.Reimbursement) float64 {
-	totalReimbursementAmount := 0.0
-	for _, reimbursement := range reimbursements {
-		totalReimbursementAmount += reimbursement.Amount
-	}
-	return totalReimbursementAmount
+	return report, nil
 }
 
-func (uc *PayrollUsecase) buildAttendanceBreakdown(attendances []model.Attendance) []map[string]interface{} {
-	var attendanceBreakdown []map[string]interface{}
-	for _, attendance := range attendances {
-		attendanceBreakdown = append(attendanceBreakdown, map[string]interface{}{
-			"date":         attendance.Date,
-			"check_in":     attendance.Checkin,
-			"check_out":    attendance.Checkout,
-			"hours_worked": attendance.HoursWorked,
-			"status":       attendance.Status,
-		})
-	}
-	return attendanceBreakdown
-}
+// BuildPayrollKPIs computes the dashboard KPIs of a month per currency, comparing the total cost with the
+// previous month. The change is left empty when the previous month has no payroll in the currency.
+func (uc *PayrollUsecase) BuildPayrollKPIs(month time.Time) (*res.PayrollKPIs, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	nextMonthStart := monthStart.AddDate(0, 1, 0)
+	previousMonthStart := monthStart.AddDate(0, -1, 0)
 
-func (uc *PayrollUsecase) buildOvertimeBreakdown(overtimes []model.Overtime, payslip *model.Payslip) []map[string]interface{} {
-	var overtimeBreakdown []map[string]interface{}
-	overtimeRate := 0.0
-	if payslip.OvertimeHours > 0 {
-		overtimeRate = payslip.OvertimeAmount / float64(payslip.OvertimeHours)
+	current, err := uc.payslipRepo.GetPayrollTotalsForPeriod(monthStart, nextMonthStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payroll totals: %v", err)
+	}
+	previous, err := uc.payslipRepo.GetPayrollTotalsForPeriod(previousMonthStart, monthStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous payroll totals: %v", err)
 	}
+	currentTotals := uc.payrollTotalsByCurrency(current)
+	previousTotals := uc.payrollTotalsByCurrency(previous)
 
-	for _, overtime := range overtimes {
-		amount := float64(overtime.Hours) * overtimeRate
-		overtimeBreakdown = append(overtimeBreakdown, map[string]interface{}{
-			"date":   overtime.OvertimeDate,
-			"hours":  overtime.Hours,
-			"rate":   overtimeRate,
-			"amount": amount,
-			"reason": overtime.Reason,
-		})
+	var currencies []string
+	for currency := range currentTotals {
+		currencies = append(currencies, currency)
 	}
-	return overtimeBreakdown
+	for currency := range previousTotals {
+		if _, exists := currentTotals[currency]; !exists {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+
+	kpis := &res.PayrollKPIs{
+		Month:      monthStart.Format("2006-01"),
+		Currencies: make([]res.PayrollCurrencyKPIs, 0, len(currencies)),
+	}
+	for _, currency := range currencies {
+		now, before := currentTotals[currency], previousTotals[currency]
+		currencyKPIs := res.PayrollCurrencyKPIs{
+			Currency:          currency,
+			TotalCost:         uc.RoundMoney(now.TotalAmount, currency),
+			Headcount:         now.Headcount,
+			PreviousTotalCost: uc.RoundMoney(before.TotalAmount, currency),
+		}
+		if now.PayslipCount > 0 {
+			currencyKPIs.AverageNet = uc.RoundMoney(now.TotalAmount/float64(now.PayslipCount), currency)
+		}
+		if now.TotalAmount > 0 {
+			currencyKPIs.OvertimePercent = now.OvertimeAmount / now.TotalAmount * 100
+		}
+		if before.TotalAmount > 0 {
+			change := (now.TotalAmount - before.TotalAmount) / before.TotalAmount * 100
+			currencyKPIs.MonthOverMonthChange = &change
+		}
+		kpis.Currencies = append(kpis.Currencies, currencyKPIs)
+	}
+
+	return kpis, nil
+}
+
+// payrollTotalsByCurrency keys the totals by currency, counting payslips without one in the base currency
+func (uc *PayrollUsecase) payrollTotalsByCurrency(totals []res.PayrollTotals) map[string]res.PayrollTotals {
+	byCurrency := make(map[string]res.PayrollTotals, len(totals))
+	for _, total := range totals {
+		currency := total.Currency
+		if currency == "" {
+			currency = uc.Config.Currency
+		}
+		merged := byCurrency[currency]
+		merged.Currency = currency
+		merged.PayslipCount += total.PayslipCount
+		merged.Headcount += total.Headcount
+		merged.TotalAmount += total.TotalAmount
+		merged.OvertimeAmount += total.OvertimeAmount
+		byCurrency[currency] = merged
+	}
+	return byCurrency
+}
+
+// BuildDeductionBreakdown lists each deduction of a payslip with its basis, summing to the gross minus the net pay.
+// The recurring deductions are itemized per component while the employee's active components still add up to the
+// recorded amount, otherwise they are shown as a single line.
+func (uc *PayrollUsecase) BuildDeductionBreakdown(payslip *model.Payslip) (*res.DeductionBreakdown, error) {
+	currency := payslip.Currency
+	if currency == "" {
+		currency = uc.Config.Currency
+	}
+
+	breakdown := &res.DeductionBreakdown{
+		PayslipID:   payslip.ID,
+		EmployeeID:  payslip.EmployeeID,
+		Currency:    currency,
+		GrossAmount: payslip.GrossAmount,
+		NetAmount:   payslip.NetAmount,
+		Deductions:  []res.DeductionItem{},
+	}
+	add := func(item res.DeductionItem) {
+		if item.Amount == 0 {
+			return
+		}
+		breakdown.Deductions = append(breakdown.Deductions, item)
+		breakdown.TotalDeducted = uc.AddMoney(currency, breakdown.TotalDeducted, item.Amount)
+	}
+
+	add(res.DeductionItem{
+		Type:   res.DeductionIncomeTax,
+		Amount: payslip.TaxAmount,
+		Basis:  fmt.Sprintf("Progressive income tax on a taxable income of %s (basic salary and overtime, less the absence deduction and late penalty)", helper.FormatMoney(uc.AddMoney(currency, payslip.BasicSalary, payslip.OvertimeAmount, -payslip.AbsenceDeduction, -payslip.LatePenaltyAmount), currency)),
+	})
+	add(res.DeductionItem{
+		Type:   res.DeductionAbsence,
+		Amount: payslip.AbsenceDeduction,
+		Basis:  fmt.Sprintf("Daily rate of %s for each standard working day without attendance", helper.FormatMoney(payslip.DailyRate, currency)),
+	})
+	add(res.DeductionItem{
+		Type:   res.DeductionLatePenalty,
+		Amount: payslip.LatePenaltyAmount,
+		Basis:  fmt.Sprintf("Penalty for %d late check-ins", payslip.LateCount),
+	})
+
+	if payslip.DeductionAmount != 0 {
+		components, err := uc.payslipRepo.GetActiveComponentsForEmployee(payslip.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get salary components: %v", err)
+		}
+
+		var deductions []model.EmployeeComponent
+		for _, component := range components {
+			if component.IsDeduction() {
+				deductions = append(deductions, component)
+			}
+		}
+
+		if _, total := uc.ResolveComponents(deductions); uc.RoundMoney(total, currency) == payslip.DeductionAmount {
+			for _, component := range deductions {
+				add(res.DeductionItem{
+					Type:   res.DeductionRecurring,
+					Name:   component.Name,
+					Amount: uc.RoundMoney(component.Amount, currency),
+					Basis:  "Recurring deduction component",
+				})
+			}
+		} else {
+			add(res.DeductionItem{
+				Type:   res.DeductionRecurringTotal,
+				Amount: payslip.DeductionAmount,
+				Basis:  "Recurring deduction components active when the payslip was processed",
+			})
+		}
+	}
+
+	add(res.DeductionItem{
+		Type:   res.DeductionBroughtForward,
+		Amount: payslip.BroughtForward,
+		Basis:  "Deduction the previous payslip could not recover",
+	})
+	add(res.DeductionItem{
+		Type:   res.DeductionCarriedForward,
+		Amount: -payslip.CarriedForward,
+		Basis:  "Deduction above the gross pay, deferred to the next payslip",
+	})
+
+	return breakdown, nil
+}
+
+// BuildTaxSummary aggregates the gross, tax withheld and net of an employee's non-void payslips for a year,
+// with a row for every month. Payslips count towards the month their pay period starts in. Payslips in more
+// than one currency cannot be summed and fail with ErrMixedCurrencies.
+func (uc *PayrollUsecase) BuildTaxSummary(employee *model.Employee, year int) (*res.TaxSummary, error) {
+	payslips, err := uc.payslipRepo.GetPayslipsByEmployeeForYear(employee.ID, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payslips: %v", err)
+	}
+
+	totals, err := uc.payslipTotals(employee, payslips)
+	if err != nil {
+		return nil, err
+	}
+	currency := totals.Currency
+
+	summary := &res.TaxSummary{
+		EmployeeID:       employee.ID,
+		EmployeeName:     employee.Name,
+		Year:             year,
+		Currency:         currency,
+		PayslipCount:     totals.PayslipCount,
+		TotalGross:       totals.GrossAmount,
+		TotalTaxWithheld: totals.TaxAmount,
+		Months:           make([]res.TaxSummaryMonth, 12),
+	}
+	for i := range summary.Months {
+		summary.Months[i].Month = time.Date(year, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC).Format("2006-01")
+	}
+
+	for _, payslip := range payslips {
+		month := &summary.Months[payslip.PayPeriodStart.Month()-1]
+		month.PayslipCount++
+		month.GrossAmount = uc.RoundMoney(month.GrossAmount+payslip.GrossAmount, currency)
+		month.TaxWithheld = uc.RoundMoney(month.TaxWithheld+payslip.TaxAmount, currency)
+		month.NetAmount = uc.RoundMoney(month.NetAmount+payslip.NetAmount, currency)
+
+		summary.TotalNet = uc.RoundMoney(summary.TotalNet+payslip.NetAmount, currency)
+	}
+
+	return summary, nil
+}
+
+// BuildYTDEarnings totals the non-void payslips of an employee whose pay period starts in the year,
+// with the same per-employee totals as the payroll summary plus the gross and tax amounts
+func (uc *PayrollUsecase) BuildYTDEarnings(employee *model.Employee, year int) (*res.YTDEarnings, error) {
+	payslips, err := uc.payslipRepo.GetPayslipsByEmployeeForYear(employee.ID, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payslips: %v", err)
+	}
+
+	totals, err := uc.payslipTotals(employee, payslips)
+	if err != nil {
+		return nil, err
+	}
+	return &res.YTDEarnings{
+		EmployeeID:    employee.ID,
+		EmployeeName:  employee.Name,
+		Year:          year,
+		PayslipTotals: totals,
+	}, nil
+}
+
+// BuildEmployeePayslipSummary sums the non-void payslips of an employee whose pay period starts from start to
+// end, both days included. A range without payslips is summed to zero.
+func (uc *PayrollUsecase) BuildEmployeePayslipSummary(employee *model.Employee, start, end time.Time) (*res.EmployeePayslipSummary, error) {
+	payslips, err := uc.payslipRepo.GetPayslipsByEmployeeForRange(employee.ID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payslips: %v", err)
+	}
+
+	totals, err := uc.payslipTotals(employee, payslips)
+	if err != nil {
+		return nil, err
+	}
+	return &res.EmployeePayslipSummary{
+		EmployeeID:    employee.ID,
+		EmployeeName:  employee.Name,
+		StartDate:     start.Format("2006-01-02"),
+		EndDate:       end.Format("2006-01-02"),
+		PayslipTotals: totals,
+	}, nil
+}
+
+// payslipTotals sums the payslips of an employee with calculateEmployeeSummary, rounded in the currency of the
+// payslips or the base currency without payslips. Payslips in more than one currency, e.g. across a change of
+// the employee's currency, cannot be summed and fail with ErrMixedCurrencies.
+func (uc *PayrollUsecase) payslipTotals(employee *model.Employee, payslips []model.Payslip) (res.PayslipTotals, error) {
+	var currencies []string
+	for _, payslip := range payslips {
+		currency := payslip.Currency
+		if currency == "" {
+			currency = uc.Config.Currency
+		}
+		if !slices.Contains(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(currencies) > 1 {
+		return res.PayslipTotals{}, fmt.Errorf("%w: %s", ErrMixedCurrencies, strings.Join(currencies, ", "))
+	}
+	currency := uc.Config.Currency
+	if len(currencies) == 1 {
+		currency = currencies[0]
+	}
+
+	totals := uc.calculateEmployeeSummary(employee.ID, payslips, employee.Name)
+	return res.PayslipTotals{
+		Currency:            currency,
+		PayslipCount:        totals["payslip_count"].(int),
+		GrossAmount:         uc.RoundMoney(totals["total_gross_amount"].(float64), currency),
+		TaxAmount:           uc.RoundMoney(totals["total_tax_amount"].(float64), currency),
+		NetAmount:           uc.RoundMoney(totals["total_take_home_pay"].(float64), currency),
+		BasicSalary:         uc.RoundMoney(totals["total_basic_salary"].(float64), currency),
+		OvertimeAmount:      uc.RoundMoney(totals["total_overtime_amount"].(float64), currency),
+		OvertimeHours:       totals["total_overtime_hours"].(int),
+		ReimbursementAmount: uc.RoundMoney(totals["total_reimbursement"].(float64), currency),
+		AttendanceDays:      totals["total_attendance_days"].(float64),
+	}, nil
+}
+
+// BuildPayslipApprovalChain lists the approved overtime and reimbursements of the payslip period
+// with the approver of each item. Items approved after the payslip was processed were not paid in it
+// and are left out.
+func (uc *PayrollUsecase) BuildPayslipApprovalChain(payslip *model.Payslip) (*res.PayslipApprovalChain, error) {
+	period := uc.Config.PeriodRange(payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	overtimes, err := uc.payslipRepo.GetOvertimeForPeriod(payslip.EmployeeID, period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overtime records: %v", err)
+	}
+
+	reimbursements, err := uc.payslipRepo.GetApprovedReimbursementsForPeriod(payslip.EmployeeID, period.Start, period.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reimbursement records: %v", err)
+	}
+
+	chain := &res.PayslipApprovalChain{
+		PayslipID:      payslip.ID,
+		EmployeeID:     payslip.EmployeeID,
+		PayPeriodStart: payslip.PayPeriodStart,
+		PayPeriodEnd:   payslip.PayPeriodEnd,
+		Overtimes:      []res.ApprovalChainItem{},
+		Reimbursements: []res.ApprovalChainItem{},
+	}
+
+	approverNames := make(map[uint]string)
+	for _, overtime := range overtimes {
+		if approvedAfter(overtime.ApprovedAt, payslip.ProcessedAt) {
+			continue
+		}
+		chain.Overtimes = append(chain.Overtimes, res.ApprovalChainItem{
+			Type:         "overtime",
+			ItemID:       overtime.ID,
+			Date:         overtime.OvertimeDate,
+			Hours:        overtime.Hours,
+			Reason:       overtime.Reason,
+			ApprovedBy:   overtime.ApprovedBy,
+			ApproverName: uc.approverName(overtime.ApprovedBy, approverNames),
+			ApprovedAt:   overtime.ApprovedAt,
+		})
+	}
+	for _, reimbursement := range reimbursements {
+		if approvedAfter(reimbursement.ApprovedAt, payslip.ProcessedAt) {
+			continue
+		}
+		chain.Reimbursements = append(chain.Reimbursements, res.ApprovalChainItem{
+			Type:         "reimbursement",
+			ItemID:       reimbursement.ID,
+			Date:         reimbursement.ReimbursementDate.In(uc.Config.TimeLocation()).Format("2006-01-02"),
+			Amount:       reimbursement.PayableAmount(),
+			Reason:       reimbursement.Reason,
+			ApprovedBy:   reimbursement.ApprovedBy,
+			ApproverName: uc.approverName(reimbursement.ApprovedBy, approverNames),
+			ApprovedAt:   reimbursement.ApprovedAt,
+		})
+	}
+	chain.ApprovalCount = len(chain.Overtimes) + len(chain.Reimbursements)
+
+	return chain, nil
+}
+
+// approvedAfter reports whether an item was approved after the time, an item without an approval time
+// predates its tracking and is not
+func approvedAfter(approvedAt *time.Time, t time.Time) bool {
+	return approvedAt != nil && approvedAt.After(t)
+}
+
+// approverName resolves the name of an approver, caching lookups across the chain
+func (uc *PayrollUsecase) approverName(approverID *uint, names map[uint]string) string {
+	if approverID == nil {
+		return ""
+	}
+	if name, exists := names[*approverID]; exists {
+		return name
+	}
+
+	name := "Unknown Employee"
+	if approver, err := uc.payslipRepo.GetEmployeeByID(*approverID); err == nil {
+		name = approver.Name
+	}
+	names[*approverID] = name
+	return name
+}
+
+// BuildDetailedPayslipResponse constructs the detailed payslip response, its allowances left to the caller
+func (uc *PayrollUsecase) BuildDetailedPayslipResponse(payslip *model.Payslip, employee *model.Employee, attendances []model.Attendance, overtimes []model.Overtime, reimbursements []model.Reimbursement) res.DetailedPayslipResponse {
+	// Payslips stored in minor units are formatted back from them
+	payslip = helper.PayslipFromMinorUnits(payslip)
+
+	// Build deduction breakdown
+	deductionBreakdown := []res.PayslipDeductionLine{
+		{Type: "income_tax", Amount: payslip.TaxAmount},
+		{Type: "components", Amount: payslip.DeductionAmount},
+		{Type: "brought_forward", Amount: payslip.BroughtForward},
+	}
+	if payslip.AbsenceDeduction != 0 {
+		deductionBreakdown = append(deductionBreakdown, res.PayslipDeductionLine{Type: "absence", Amount: payslip.AbsenceDeduction})
+	}
+	if payslip.LatePenaltyAmount != 0 {
+		deductionBreakdown = append(deductionBreakdown, res.PayslipDeductionLine{Type: "late_penalty", Amount: payslip.LatePenaltyAmount})
+	}
+
+	// Build summary
+	money := func(amount float64) string {
+		return helper.FormatMoney(amount, payslip.Currency)
+	}
+	summary := res.PayslipSummary{
+		BasicSalary:         payslip.BasicSalary,
+		DailyRate:           payslip.DailyRate,
+		ProratedDays:        payslip.ProratedDays,
+		ProrationFactor:     payslip.ProrationFactor(),
+		TotalAttendanceDays: payslip.AttendanceDays,
+		OpenAttendanceDays:  payslip.OpenAttendanceDays,
+		TotalOvertimeHours:  payslip.OvertimeHours,
+		OvertimeRate:        payslip.OvertimeRate,
+		OvertimeAmount:      payslip.OvertimeAmount,
+		ReimbursementAmount: payslip.ReimbursementAmount,
+		AllowanceAmount:     payslip.AllowanceAmount,
+		DeductionAmount:     payslip.DeductionAmount,
+		AbsenceDeduction:    payslip.AbsenceDeduction,
+		LateCount:           payslip.LateCount,
+		LatePenaltyAmount:   payslip.LatePenaltyAmount,
+		GrossAmount:         payslip.GrossAmount,
+		TaxAmount:           payslip.TaxAmount,
+		NetAmount:           payslip.NetAmount,
+		BroughtForward:      payslip.BroughtForward,
+		CarriedForward:      payslip.CarriedForward,
+		TotalTakeHomePay:    payslip.TotalAmount,
+		Currency:            payslip.Currency,
+		Display: res.PayslipSummaryDisplay{
+			BasicSalary:         money(payslip.BasicSalary),
+			DailyRate:           money(payslip.DailyRate),
+			OvertimeAmount:      money(payslip.OvertimeAmount),
+			ReimbursementAmount: money(payslip.ReimbursementAmount),
+			AllowanceAmount:     money(payslip.AllowanceAmount),
+			DeductionAmount:     money(payslip.DeductionAmount),
+			AbsenceDeduction:    money(payslip.AbsenceDeduction),
+			LatePenaltyAmount:   money(payslip.LatePenaltyAmount),
+			GrossAmount:         money(payslip.GrossAmount),
+			TaxAmount:           money(payslip.TaxAmount),
+			NetAmount:           money(payslip.NetAmount),
+			BroughtForward:      money(payslip.BroughtForward),
+			CarriedForward:      money(payslip.CarriedForward),
+			TotalTakeHomePay:    money(payslip.TotalAmount),
+		},
+	}
+
+	return res.DetailedPayslipResponse{
+		PayslipID:              payslip.ID,
+		EmployeeID:             payslip.EmployeeID,
+		EmployeeName:           employee.Name,
+		PayPeriodStart:         payslip.PayPeriodStart,
+		PayPeriodEnd:           payslip.PayPeriodEnd,
+		ProcessedAt:            payslip.ProcessedAt,
+		Status:                 payslip.Status,
+		Currency:               payslip.Currency,
+		Summary:                summary,
+		AttendanceBreakdown:    uc.buildAttendanceBreakdown(attendances),
+		OvertimeBreakdown:      uc.buildOvertimeBreakdown(overtimes, payslip),
+		ReimbursementBreakdown: uc.buildReimbursementBreakdown(reimbursements),
+		DeductionBreakdown:     deductionBreakdown,
+	}
+}
+
+// BuildAllowanceBreakdown lists the fixed allowances paid on a payslip, given the allowance components
+// active when it was processed. An allowance counts for a period when it is active at the time payroll
+// runs, in full, whenever in the period it was (de)activated. When the components no longer add up to
+// the stored allowance amount, because one changed since, their total is listed as one line.
+func (uc *PayrollUsecase) BuildAllowanceBreakdown(payslip *model.Payslip, components []model.EmployeeComponent) []res.PayslipAllowanceLine {
+	allowances := []res.PayslipAllowanceLine{}
+	var active []model.EmployeeComponent
+	for _, component := range components {
+		if !component.IsDeduction() && component.Active {
+			active = append(active, component)
+		}
+	}
+
+	if total, _ := uc.ResolveComponents(active); uc.RoundMoney(total, payslip.Currency) == payslip.AllowanceAmount {
+		for _, component := range active {
+			allowances = append(allowances, res.PayslipAllowanceLine{
+				Name:   component.Name,
+				Amount: uc.RoundMoney(component.Amount, payslip.Currency),
+			})
+		}
+		return allowances
+	}
+	return append(allowances, res.PayslipAllowanceLine{
+		Name:   "Allowances active when the payslip was processed",
+		Amount: payslip.AllowanceAmount,
+	})
+}
+
+// BuildPayslipPDF renders a detailed payslip response (see BuildDetailedPayslipResponse) as a PDF
+func (uc *PayrollUsecase) BuildPayslipPDF(detail res.DetailedPayslipResponse) []byte {
+	summary := detail.Summary
+	currency := summary.Currency
+	money := func(amount float64) string {
+		return helper.FormatMoney(amount, currency)
+	}
+
+	doc := pdf.New()
+	doc.Title("Payslip")
+	doc.Text(fmt.Sprintf("Employee: %s (ID %v)", detail.EmployeeName, detail.EmployeeID))
+	doc.Text(fmt.Sprintf("Period: %s to %s", pdfValue(detail.PayPeriodStart), pdfValue(detail.PayPeriodEnd)))
+	doc.Text(fmt.Sprintf("Payslip ID: %v    Status: %v    Currency: %s", detail.PayslipID, detail.Status, currency))
+
+	doc.Heading("Attendance")
+	doc.HeaderRow("Date", "Check in", "Check out", "Hours", "Status")
+	for _, attendance := range detail.AttendanceBreakdown {
+		doc.Row(pdfValue(attendance.Date), pdfTime(attendance.CheckIn), pdfTime(attendance.CheckOut),
+			pdfValue(attendance.HoursWorked), pdfValue(attendance.Status))
+	}
+	doc.Text(fmt.Sprintf("Attendance days: %v", summary.TotalAttendanceDays))
+
+	doc.Heading("Overtime")
+	doc.HeaderRow("Date", "Hours", "Paid hours", "Amount", "Reason")
+	for _, overtime := range detail.OvertimeBreakdown {
+		doc.Row(pdfValue(overtime.Date), pdfValue(overtime.Hours), pdfValue(overtime.PaidHours),
+			money(overtime.Amount), pdfValue(overtime.Reason))
+	}
+
+	doc.Heading("Reimbursements")
+	doc.HeaderRow("Date", "Amount", "Status", "Reason")
+	for _, reimbursement := range detail.ReimbursementBreakdown {
+		doc.Row(pdfValue(reimbursement.Date), money(reimbursement.Amount),
+			pdfValue(reimbursement.Status), pdfValue(reimbursement.Reason))
+	}
+
+	doc.Heading("Totals")
+	totals := []struct {
+		label  string
+		amount float64
+	}{
+		{"Basic salary", summary.BasicSalary},
+		{"Overtime", summary.OvertimeAmount},
+		{"Reimbursements", summary.ReimbursementAmount},
+		{"Allowances", summary.AllowanceAmount},
+		{"Gross pay", summary.GrossAmount},
+		{"Income tax", summary.TaxAmount},
+		{"Deductions", summary.DeductionAmount},
+		{"Brought forward", summary.BroughtForward},
+		{"Take home pay", summary.TotalTakeHomePay},
+	}
+	for _, total := range totals {
+		doc.Row(total.label, money(total.amount))
+	}
+
+	return doc.Bytes()
+}
+
+// PayslipPDFFilename names the PDF of a payslip, the same whether downloaded or emailed
+func PayslipPDFFilename(payslip *model.Payslip) string {
+	return fmt.Sprintf("payslip-%d-%s.pdf", payslip.ID, payslip.PayPeriodStart.Format("2006-01"))
+}
+
+// PayslipJSONFilename names the JSON export of a payslip, matching the name of its PDF
+func PayslipJSONFilename(payslip *model.Payslip) string {
+	return fmt.Sprintf("payslip-%d-%s.json", payslip.ID, payslip.PayPeriodStart.Format("2006-01"))
+}
+
+// EmailPayslip sends the detailed payslip (see BuildDetailedPayslipResponse) to the employee as a PDF attachment
+func (uc *PayrollUsecase) EmailPayslip(employee *model.Employee, payslip *model.Payslip, detail res.DetailedPayslipResponse) error {
+	if !employee.HasEmail() {
+		return ErrEmployeeNoEmail
+	}
+
+	period := uc.Config.PeriodRange(payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	return uc.Mailer.Send(mailer.Message{
+		To:      employee.Email,
+		Subject: fmt.Sprintf("Payslip for %s", period.Start.Format("January 2006")),
+		Body: fmt.Sprintf("Hello %s,\n\nYour payslip for the period %s is attached.\nTake home pay: %s\n",
+			employee.Name, period.StartDate+" to "+period.EndDate, helper.FormatMoney(payslip.TotalAmount, payslip.Currency)),
+		Attachments: []mailer.Attachment{{
+			Filename:    PayslipPDFFilename(payslip),
+			ContentType: "application/pdf",
+			Data:        uc.BuildPayslipPDF(detail),
+		}},
+	})
+}
+
+// pdfValue formats a breakdown value for the payslip PDF
+func pdfValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case time.Time:
+		return v.Format("2006-01-02")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// pdfTime formats a check in or check out time for the payslip PDF
+func pdfTime(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format("15:04")
+	case *time.Time:
+		if v != nil {
+			return v.Format("15:04")
+		}
+	}
+	return "-"
+}
+
+// BuildPayrollSummary constructs the payroll summary response. Amounts are never added up across
+// currencies: employees are summarised per currency and the totals are grouped by currency.
+func (uc *PayrollUsecase) BuildPayrollSummary(payslips []model.Payslip) map[string]interface{} {
+	type summaryKey struct {
+		employeeID uint
+		currency   string
+	}
+	var keys []summaryKey
+	employeeSummaries := []map[string]interface{}{}
+	currencyPayslips := make(map[string][]model.Payslip)
+	currencySummaries := make(map[string][]map[string]interface{})
+
+	// Group payslips by employee and currency to get employee totals
+	groupedPayslips := make(map[summaryKey][]model.Payslip)
+	employeeNames := make(map[uint]string)
+
+	for _, payslip := range payslips {
+		currency := payslip.Currency
+		if currency == "" {
+			currency = uc.Config.Currency
+		}
+		key := summaryKey{employeeID: payslip.EmployeeID, currency: currency}
+		if _, exists := groupedPayslips[key]; !exists {
+			keys = append(keys, key)
+		}
+		groupedPayslips[key] = append(groupedPayslips[key], payslip)
+		currencyPayslips[currency] = append(currencyPayslips[currency], payslip)
+
+		// Get employee name if we don't have it yet
+		if _, exists := employeeNames[payslip.EmployeeID]; !exists {
+			employee, err := uc.payslipRepo.GetEmployeeByID(payslip.EmployeeID)
+			if err == nil {
+				employeeNames[payslip.EmployeeID] = employee.Name
+			} else {
+				employeeNames[payslip.EmployeeID] = "Unknown Employee"
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].employeeID != keys[j].employeeID {
+			return keys[i].employeeID < keys[j].employeeID
+		}
+		return keys[i].currency < keys[j].currency
+	})
+
+	// Calculate totals for each employee
+	for _, key := range keys {
+		employeeSummary := uc.calculateEmployeeSummary(key.employeeID, groupedPayslips[key], employeeNames[key.employeeID])
+		employeeSummary["currency"] = key.currency
+		employeeSummaries = append(employeeSummaries, employeeSummary)
+		currencySummaries[key.currency] = append(currencySummaries[key.currency], employeeSummary)
+	}
+
+	// Calculate totals and averages per currency
+	summaryTotals := make(map[string]interface{}, len(currencySummaries))
+	for currency, summaries := range currencySummaries {
+		var totalTakeHomePay float64
+		var totalBasicSalary float64
+		var totalOvertimeAmount float64
+		var totalReimbursementAmount float64
+		var totalAttendanceDays float64
+		var totalOvertimeHours int
+		for _, employeeSummary := range summaries {
+			totalTakeHomePay += employeeSummary["total_take_home_pay"].(float64)
+			totalBasicSalary += employeeSummary["total_basic_salary"].(float64)
+			totalOvertimeAmount += employeeSummary["total_overtime_amount"].(float64)
+			totalReimbursementAmount += employeeSummary["total_reimbursement"].(float64)
+			totalAttendanceDays += employeeSummary["total_attendance_days"].(float64)
+			totalOvertimeHours += employeeSummary["total_overtime_hours"].(int)
+		}
+
+		summaryTotals[currency] = uc.calculateSummaryTotals(
+			summaries,
+			currencyPayslips[currency],
+			totalTakeHomePay,
+			totalBasicSalary,
+			totalOvertimeAmount,
+			totalReimbursementAmount,
+			totalAttendanceDays,
+			totalOvertimeHours,
+		)
+	}
+
+	return map[string]interface{}{
+		"summary_totals":     summaryTotals,
+		"employee_summaries": employeeSummaries,
+	}
+}
+
+// ComparePayrollSummaries compares the totals of two summaries built by BuildPayrollSummary, per currency.
+// A currency paid in only one of the periods is compared against zero figures.
+func (uc *PayrollUsecase) ComparePayrollSummaries(previous, current map[string]interface{}) []res.PayrollCurrencyComparison {
+	previousTotals, _ := previous["summary_totals"].(map[string]interface{})
+	currentTotals, _ := current["summary_totals"].(map[string]interface{})
+
+	var currencies []string
+	for currency := range previousTotals {
+		currencies = append(currencies, currency)
+	}
+	for currency := range currentTotals {
+		if _, exists := previousTotals[currency]; !exists {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+
+	comparisons := make([]res.PayrollCurrencyComparison, 0, len(currencies))
+	for _, currency := range currencies {
+		before, _ := previousTotals[currency].(map[string]interface{})
+		after, _ := currentTotals[currency].(map[string]interface{})
+		money := func(key string) res.PayrollComparisonMetric {
+			metric := compareMetric(summaryFigure(before, key), summaryFigure(after, key))
+			metric.Delta = uc.RoundMoney(metric.Delta, currency)
+			return metric
+		}
+
+		comparisons = append(comparisons, res.PayrollCurrencyComparison{
+			Currency:       currency,
+			Headcount:      compareMetric(summaryFigure(before, "total_employees"), summaryFigure(after, "total_employees")),
+			TotalPayout:    money("total_take_home_pay"),
+			AveragePayout:  money("average_take_home_pay"),
+			OvertimeAmount: money("total_overtime_amount"),
+			OvertimeHours:  compareMetric(summaryFigure(before, "total_overtime_hours"), summaryFigure(after, "total_overtime_hours")),
+		})
+	}
+	return comparisons
+}
+
+// summaryFigure reads a number of the summary totals of a currency, zero when the currency or figure is missing
+func summaryFigure(totals map[string]interface{}, key string) float64 {
+	switch v := totals[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// compareMetric computes the change between two figures, leaving the percentage empty when there is
+// nothing to compare against
+func compareMetric(previous, current float64) res.PayrollComparisonMetric {
+	metric := res.PayrollComparisonMetric{
+		Previous: previous,
+		Current:  current,
+		Delta:    current - previous,
+	}
+	if previous != 0 {
+		change := metric.Delta / previous * 100
+		metric.PercentChange = &change
+	}
+	return metric
+}
+
+// payrollSummaryCSVFlushRows is the number of rows written between flushes of the CSV export
+const payrollSummaryCSVFlushRows = 100
+
+// WritePayrollSummaryCSV writes the payslips as CSV, one row per payslip after a header row.
+// Rows are flushed in batches so a large export streams to the writer instead of being built in memory.
+func (uc *PayrollUsecase) WritePayrollSummaryCSV(w io.Writer, payslips []model.Payslip) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"employee_id", "employee_name", "basic_salary", "overtime_amount", "reimbursement_amount", "total_amount", "status"}); err != nil {
+		return err
+	}
+
+	employeeNames := make(map[uint]string)
+	for i, payslip := range payslips {
+		// Get employee name if we don't have it yet
+		if _, exists := employeeNames[payslip.EmployeeID]; !exists {
+			employee, err := uc.payslipRepo.GetEmployeeByID(payslip.EmployeeID)
+			if err == nil {
+				employeeNames[payslip.EmployeeID] = employee.Name
+			} else {
+				employeeNames[payslip.EmployeeID] = "Unknown Employee"
+			}
+		}
+
+		precision := int(helper.CurrencyPrecision(payslip.Currency))
+		err := writer.Write([]string{
+			strconv.FormatUint(uint64(payslip.EmployeeID), 10),
+			employeeNames[payslip.EmployeeID],
+			strconv.FormatFloat(payslip.BasicSalary, 'f', precision, 64),
+			strconv.FormatFloat(payslip.OvertimeAmount, 'f', precision, 64),
+			strconv.FormatFloat(payslip.ReimbursementAmount, 'f', precision, 64),
+			strconv.FormatFloat(payslip.TotalAmount, 'f', precision, 64),
+			payslip.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		if (i+1)%payrollSummaryCSVFlushRows == 0 {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Sheet names of the payroll summary workbook
+const (
+	payrollSummarySheet = "Summary"
+	payrollDetailSheet  = "Payslips"
+)
+
+// BuildPayrollSummaryXLSX builds a workbook of the payroll summary: a summary sheet with the totals and averages
+// per currency from BuildPayrollSummary and a detail sheet with one row per payslip, amounts formatted in their
+// currency and the header rows frozen. Rows are written through stream writers, which spill large sheets to a
+// temporary file instead of keeping them in memory, so the caller must Close the workbook once written.
+func (uc *PayrollUsecase) BuildPayrollSummaryXLSX(payslips []model.Payslip) (*excelize.File, error) {
+	summary := uc.BuildPayrollSummary(payslips)
+
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", payrollSummarySheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.NewSheet(payrollDetailSheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	workbook := &payrollWorkbook{file: file, currencyStyles: make(map[string]int)}
+	if err := workbook.writeSummarySheet(summary); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	employeeNames := make(map[uint]string)
+	for _, employeeSummary := range summary["employee_summaries"].([]map[string]interface{}) {
+		employeeNames[employeeSummary["employee_id"].(uint)] = employeeSummary["employee_name"].(string)
+	}
+	if err := workbook.writeDetailSheet(payslips, employeeNames, uc.Config.Currency); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// payrollWorkbook writes the sheets of the payroll summary workbook, sharing the styles between them
+type payrollWorkbook struct {
+	file           *excelize.File
+	headerStyle    int
+	currencyStyles map[string]int
+}
+
+// header starts a sheet with a bold header row frozen above the rows that follow
+func (w *payrollWorkbook) header(sheet string, columns []string) (*excelize.StreamWriter, error) {
+	if w.headerStyle == 0 {
+		style, err := w.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		if err != nil {
+			return nil, err
+		}
+		w.headerStyle = style
+	}
+
+	stream, err := w.file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+	if err := stream.SetColWidth(1, len(columns), 18); err != nil {
+		return nil, err
+	}
+
+	row := make([]interface{}, len(columns))
+	for i, column := range columns {
+		row[i] = excelize.Cell{StyleID: w.headerStyle, Value: column}
+	}
+	return stream, stream.SetRow("A1", row)
+}
+
+// money is an amount cell formatted with the decimal places and code of its currency
+func (w *payrollWorkbook) money(amount float64, currency string) (excelize.Cell, error) {
+	style, ok := w.currencyStyles[currency]
+	if !ok {
+		format := "#,##0"
+		if precision := helper.CurrencyPrecision(currency); precision > 0 {
+			format += "." + strings.Repeat("0", int(precision))
+		}
+		format += ` "` + currency + `"`
+
+		var err error
+		if style, err = w.file.NewStyle(&excelize.Style{CustomNumFmt: &format}); err != nil {
+			return excelize.Cell{}, err
+		}
+		w.currencyStyles[currency] = style
+	}
+	return excelize.Cell{StyleID: style, Value: amount}, nil
+}
+
+// writeSummarySheet writes one row of totals and averages per currency, in currency order
+func (w *payrollWorkbook) writeSummarySheet(summary map[string]interface{}) error {
+	stream, err := w.header(payrollSummarySheet, []string{
+		"Currency", "Employees", "Payslips", "Total Take Home Pay", "Total Basic Salary", "Total Overtime Amount",
+		"Total Reimbursement", "Total Attendance Days", "Total Overtime Hours", "Average Take Home Pay",
+		"Average Basic Salary", "Average Overtime Amount", "Average Reimbursement",
+	})
+	if err != nil {
+		return err
+	}
+
+	summaryTotals := summary["summary_totals"].(map[string]interface{})
+	currencies := make([]string, 0, len(summaryTotals))
+	for currency := range summaryTotals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	for i, currency := range currencies {
+		totals := summaryTotals[currency].(map[string]interface{})
+		row := []interface{}{currency, totals["total_employees"], totals["total_payslips"]}
+		for _, key := range []string{"total_take_home_pay", "total_basic_salary", "total_overtime_amount", "total_reimbursement_amount"} {
+			cell, err := w.money(totals[key].(float64), currency)
+			if err != nil {
+				return err
+			}
+			row = append(row, cell)
+		}
+		row = append(row, totals["total_attendance_days"], totals["total_overtime_hours"])
+		for _, key := range []string{"average_take_home_pay", "average_basic_salary", "average_overtime_amount", "average_reimbursement"} {
+			cell, err := w.money(totals[key].(float64), currency)
+			if err != nil {
+				return err
+			}
+			row = append(row, cell)
+		}
+
+		if err := stream.SetRow(fmt.Sprintf("A%d", i+2), row); err != nil {
+			return err
+		}
+	}
+	return stream.Flush()
+}
+
+// writeDetailSheet writes one row per payslip, in the order given
+func (w *payrollWorkbook) writeDetailSheet(payslips []model.Payslip, employeeNames map[uint]string, baseCurrency string) error {
+	stream, err := w.header(payrollDetailSheet, []string{
+		"Payslip ID", "Employee ID", "Employee Name", "Period Start", "Period End", "Currency", "Basic Salary",
+		"Overtime Amount", "Reimbursement Amount", "Gross Amount", "Tax Amount", "Net Amount", "Total Amount", "Status",
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, payslip := range payslips {
+		currency := payslip.Currency
+		if currency == "" {
+			currency = baseCurrency
+		}
+
+		row := []interface{}{
+			payslip.ID,
+			payslip.EmployeeID,
+			employeeNames[payslip.EmployeeID],
+			payslip.PayPeriodStart.Format("2006-01-02"),
+			payslip.PayPeriodEnd.Format("2006-01-02"),
+			currency,
+		}
+		for _, amount := range []float64{payslip.BasicSalary, payslip.OvertimeAmount, payslip.ReimbursementAmount, payslip.GrossAmount, payslip.TaxAmount, payslip.NetAmount, payslip.TotalAmount} {
+			cell, err := w.money(amount, currency)
+			if err != nil {
+				return err
+			}
+			row = append(row, cell)
+		}
+		row = append(row, payslip.Status)
+
+		if err := stream.SetRow(fmt.Sprintf("A%d", i+2), row); err != nil {
+			return err
+		}
+	}
+	return stream.Flush()
+}
+
+// Helper functions for calculations and data building
+
+func (uc *PayrollUsecase) calculateTotalOvertimeHours(overtimes []model.Overtime) int {
+	totalOvertimeHours := 0
+	for _, overtime := range overtimes {
+		totalOvertimeHours += overtime.Hours
+	}
+	return totalOvertimeHours
+}
+
+// calculatePaidOvertimeHours sums the paid part of every overtime entry
+func (uc *PayrollUsecase) calculatePaidOvertimeHours(overtimes []model.Overtime) float64 {
+	paidMinutes := 0
+	for _, overtime := range overtimes {
+		paid, _ := uc.SplitOvertimeMinutes(overtime)
+		paidMinutes += paid
+	}
+	return float64(paidMinutes) / 60
+}
+
+// calculateOvertimePayHours sums the paid hours of every overtime entry scaled by its day multiplier,
+// the overtime amount divided by them gives back the overtime rate
+func (uc *PayrollUsecase) calculateOvertimePayHours(overtimes []model.Overtime, holidays map[string]bool) float64 {
+	payHours := 0.0
+	for _, overtime := range overtimes {
+		paid, _ := uc.SplitOvertimeMinutes(overtime)
+		payHours += float64(paid) / 60 * uc.OvertimeMultiplier(overtime, holidays)
+	}
+	return payHours
+}
+
+// calculateOvertimeAmount prices every overtime entry at the rate scaled by its day multiplier and sums
+// the rounded amounts in minor units, so they add up to the lines of the overtime breakdown
+func (uc *PayrollUsecase) calculateOvertimeAmount(overtimes []model.Overtime, holidays map[string]bool, rate float64, currency string) float64 {
+	amount := 0.0
+	for _, overtime := range overtimes {
+		paid, _ := uc.SplitOvertimeMinutes(overtime)
+		entry := uc.RoundMoney(float64(paid)/60*rate*uc.OvertimeMultiplier(overtime, holidays), currency)
+		amount = uc.AddMoney(currency, amount, entry)
+	}
+	return amount
+}
+
+// OvertimeMultiplier returns the rate multiplier of the day the overtime was worked on: the holiday
+// multiplier on a holiday, the weekend multiplier on a Saturday or Sunday, otherwise 1.
+// A holiday on a weekend gets the higher of the two, they are never stacked.
+func (uc *PayrollUsecase) OvertimeMultiplier(overtime model.Overtime, holidays map[string]bool) float64 {
+	multiplier := 1.0
+	if day, err := time.Parse("2006-01-02", overtime.OvertimeDate); err == nil {
+		if weekday := day.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			multiplier = math.Max(multiplier, uc.Config.WeekendOvertimeMultiplier)
+		}
+	}
+	if holidays[overtime.OvertimeDate] {
+		multiplier = math.Max(multiplier, uc.Config.HolidayOvertimeMultiplier)
+	}
+	return multiplier
+}
+
+// holidayDates indexes the holidays by their YYYY-MM-DD date
+func holidayDates(holidays []model.Holiday) map[string]bool {
+	dates := make(map[string]bool, len(holidays))
+	for _, holiday := range holidays {
+		dates[holiday.Date] = true
+	}
+	return dates
 }
 
-func (uc *PayrollUsecase) buildReimbursementBreakdown(reimbursements []model.Reimbursement) []map[string]interface{} {
-	var reimbursementBreakdown []map[string]interface{}
+// SplitOvertimeMinutes splits an overtime entry into its paid and unpaid minutes,
+// the first UnpaidOvertimeMinutes of each entry are unpaid
+func (uc *PayrollUsecase) SplitOvertimeMinutes(overtime model.Overtime) (int, int) {
+	if uc.Config.UnpaidOvertimeMinutes <= 0 {
+		return overtime.Hours * 60, 0
+	}
+
+	minutes := overtime.DurationMinutes()
+	unpaid := uc.Config.UnpaidOvertimeMinutes
+	if unpaid > minutes {
+		unpaid = minutes
+	}
+	return minutes - unpaid, unpaid
+}
+
+// CheckReimbursementFloor refuses a reimbursement total, clawbacks included, below the configured floor
+// with ErrReimbursementBelowFloor
+func (uc *PayrollUsecase) CheckReimbursementFloor(total float64, currency string) error {
+	floor := uc.RoundMoney(uc.Config.ReimbursementFloor, currency)
+	if total >= floor {
+		return nil
+	}
+	return fmt.Errorf("%w of %s, clawbacks take it to %s", ErrReimbursementBelowFloor,
+		helper.FormatMoney(floor, currency), helper.FormatMoney(total, currency))
+}
+
+// EmployeeCurrency returns the currency the employee is paid in, which their reimbursements are in too,
+// the configured payroll currency when the employee has none
+func (uc *PayrollUsecase) EmployeeCurrency(employeeID uint) (string, error) {
+	employee, err := uc.payslipRepo.GetEmployeeByID(employeeID)
+	if err != nil {
+		return "", err
+	}
+	return uc.PayslipCurrency(employee, "")
+}
+
+// CheckClawbackFloor rounds a clawback in the employee's currency and refuses it with ErrReimbursementBelowFloor
+// when it would take the approved reimbursements of the calendar month of now, the pay period it is deducted in,
+// below the floor. It returns the rounded amount to record.
+func (uc *PayrollUsecase) CheckClawbackFloor(employeeID uint, amount float64, now time.Time) (float64, error) {
+	currency, err := uc.EmployeeCurrency(employeeID)
+	if err != nil {
+		return 0, err
+	}
+	amount = uc.RoundMoney(amount, currency)
+
+	now = now.In(uc.Config.TimeLocation())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	period := uc.Config.PeriodRange(monthStart, monthStart.AddDate(0, 1, -1))
+	reimbursements, err := uc.payslipRepo.GetApprovedReimbursementsForPeriod(employeeID, period.Start, period.End)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reimbursement records: %v", err)
+	}
+	total := uc.AddMoney(currency, uc.calculateTotalReimbursementAmount(reimbursements, currency), amount)
+	if err := uc.CheckReimbursementFloor(total, currency); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+// calculateTotalReimbursementAmount sums the payable amounts of the reimbursements in minor units,
+// clawbacks reducing it with their negative amounts
+func (uc *PayrollUsecase) calculateTotalReimbursementAmount(reimbursements []model.Reimbursement, currency string) float64 {
+	totalReimbursementAmount := 0.0
+	for _, reimbursement := range reimbursements {
+		totalReimbursementAmount = uc.AddMoney(currency, totalReimbursementAmount, reimbursement.PayableAmount())
+	}
+	return totalReimbursementAmount
+}
+
+// BuildPayrollForecast projects the monthly headcount and cost from the employees' salary setup, per currency
+// the employees are paid in. Employees count towards every month they are employed in, based on their hire and
+// termination dates, with their basic salary or else the default salary of their pay grade, as payroll pays.
+func (uc *PayrollUsecase) BuildPayrollForecast(employees []model.Employee, startMonth time.Time, months int) res.PayrollForecastResponse {
+	monthStart := time.Date(startMonth.Year(), startMonth.Month(), 1, 0, 0, 0, 0, startMonth.Location())
+
+	byCurrency := make(map[string][]model.Employee)
+	var currencies []string
+	for _, employee := range employees {
+		currency, _ := uc.PayslipCurrency(&employee, "")
+		if _, exists := byCurrency[currency]; !exists {
+			currencies = append(currencies, currency)
+		}
+		byCurrency[currency] = append(byCurrency[currency], employee)
+	}
+	sort.Strings(currencies)
+
+	forecast := res.PayrollForecastResponse{
+		StartMonth: monthStart.Format("2006-01"),
+		Months:     months,
+		Currencies: make([]res.PayrollCurrencyForecast, 0, len(currencies)),
+	}
+
+	for _, currency := range currencies {
+		currencyForecast := res.PayrollCurrencyForecast{
+			Currency: currency,
+			Forecast: make([]res.PayrollForecastMonth, 0, months),
+		}
+		for i := 0; i < months; i++ {
+			start := monthStart.AddDate(0, i, 0)
+			end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+			month := res.PayrollForecastMonth{Month: start.Format("2006-01")}
+			for _, employee := range byCurrency[currency] {
+				if !employee.IsEmployedBetween(start, end) {
+					continue
+				}
+				allowanceAmount, deductionAmount := uc.ResolveComponents(employee.Components)
+
+				month.Headcount++
+				month.BasicSalary = uc.AddMoney(currency, month.BasicSalary, uc.ResolveBasicSalary(&employee, employee.BasicSalary))
+				month.AllowanceAmount = uc.AddMoney(currency, month.AllowanceAmount, allowanceAmount)
+				month.DeductionAmount = uc.AddMoney(currency, month.DeductionAmount, deductionAmount)
+			}
+			month.TotalCost = uc.AddMoney(currency, month.BasicSalary, month.AllowanceAmount, -month.DeductionAmount)
+
+			currencyForecast.Forecast = append(currencyForecast.Forecast, month)
+			currencyForecast.TotalCost = uc.AddMoney(currency, currencyForecast.TotalCost, month.TotalCost)
+		}
+		forecast.Currencies = append(forecast.Currencies, currencyForecast)
+	}
+
+	return forecast
+}
+
+// CountAttendanceDays counts the payable attendance days, weighting each record by its status
+// and applying the open checkout policy to days without a checkout. It also returns the number
+// of open days, absences left out.
+func (uc *PayrollUsecase) CountAttendanceDays(attendances []model.Attendance) (float64, int) {
+	attendanceDays := 0.0
+	openAttendanceDays := 0
+	for _, attendance := range attendances {
+		attendanceDays += uc.AttendanceWeight(attendance)
+		if !attendance.IsComplete() && attendance.PayWeight() > 0 {
+			openAttendanceDays++
+		}
+	}
+	return attendanceDays, openAttendanceDays
+}
+
+// AttendanceWeight returns the share of a working day an attendance is paid for: the weight of
+// its status, scaled by the open checkout policy when it has no checkout
+func (uc *PayrollUsecase) AttendanceWeight(attendance model.Attendance) float64 {
+	weight := attendance.PayWeight()
+	if attendance.IsComplete() {
+		return weight
+	}
+
+	switch uc.Config.OpenCheckoutPolicy {
+	case config.OpenCheckoutHalfDay:
+		return weight * 0.5
+	case config.OpenCheckoutExclude:
+		return 0
+	default:
+		return weight
+	}
+}
+
+// CalculateProration compares the employee's join date with the pay period and returns the
+// working days employed, the working days of the period and the share of salary to pay.
+// Joining after the period end pays nothing.
+func (uc *PayrollUsecase) CalculateProration(employee *model.Employee, periodStart, periodEnd time.Time) (int, int, float64) {
+	periodWorkingDays := countWorkingDays(periodStart, periodEnd)
+	joinDate := employee.JoinDate()
+	if joinDate == nil || !dateOnly(*joinDate).After(dateOnly(periodStart)) || periodWorkingDays == 0 {
+		return periodWorkingDays, periodWorkingDays, 1
+	}
+	if dateOnly(*joinDate).After(dateOnly(periodEnd)) {
+		return 0, periodWorkingDays, 0
+	}
+
+	proratedDays := countWorkingDays(*joinDate, periodEnd)
+	return proratedDays, periodWorkingDays, float64(proratedDays) / float64(periodWorkingDays)
+}
+
+// countWorkingDays counts the weekdays between two dates, both inclusive
+func countWorkingDays(start, end time.Time) int {
+	days := 0
+	for day := dateOnly(start); !day.After(dateOnly(end)); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// dateOnly drops the time of day so dates compare by calendar day
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// CalculateDeductions returns the progressive income tax on the taxable income, each bracket
+// taxing only the part of the income inside it. Income below the lowest bracket is not taxed.
+func (uc *PayrollUsecase) CalculateDeductions(taxableIncome float64, brackets []model.TaxBracket) float64 {
+	tax := 0.0
+	for _, bracket := range brackets {
+		tax += bracket.TaxableWithin(taxableIncome) * bracket.Rate
+	}
+	return tax
+}
+
+// ApplyNegativeNetPolicy handles a negative net pay according to the configured policy.
+// It returns the net pay to record and the deduction to carry to the next payslip.
+func (uc *PayrollUsecase) ApplyNegativeNetPolicy(netAmount float64) (float64, float64, error) {
+	if netAmount >= 0 {
+		return netAmount, 0, nil
+	}
+
+	switch uc.Config.NegativeNetPolicy {
+	case config.NegativeNetAllow:
+		return netAmount, 0, nil
+	case config.NegativeNetReject:
+		return 0, 0, fmt.Errorf("deductions exceed gross pay by %.2f", -netAmount)
+	default:
+		return 0, -netAmount, nil
+	}
+}
+
+// CheckMinimumWage compares the net pay of a full period with the configured minimum wage, returning the
+// shortfall when it falls below. Partial periods of joiners and leavers, payslips in another currency than
+// the base currency and a minimum wage of 0 are not checked. When the minimum wage is enforced a shortfall
+// is returned as ErrBelowMinimumWage instead.
+func (uc *PayrollUsecase) CheckMinimumWage(netAmount, prorationFactor float64, currency string) (*model.MinimumWageShortfall, error) {
+	if uc.Config.MinimumWage <= 0 || prorationFactor < 1 || currency != uc.Config.Currency {
+		return nil, nil
+	}
+	floor := uc.RoundMoney(uc.Config.MinimumWage, currency)
+	if netAmount >= floor {
+		return nil, nil
+	}
+
+	shortfall := &model.MinimumWageShortfall{
+		Floor:     floor,
+		NetAmount: netAmount,
+		Shortfall: uc.AddMoney(currency, floor, -netAmount),
+	}
+	if uc.Config.MinimumWageMode == config.MinimumWageEnforce {
+		return nil, fmt.Errorf("%w of %s, short by %s with a net pay of %s", ErrBelowMinimumWage,
+			helper.FormatMoney(floor, currency), helper.FormatMoney(shortfall.Shortfall, currency), helper.FormatMoney(netAmount, currency))
+	}
+	return shortfall, nil
+}
+
+// ResolveComponents sums recurring components into their allowance and deduction totals
+func (uc *PayrollUsecase) ResolveComponents(components []model.EmployeeComponent) (float64, float64) {
+	allowanceAmount := 0.0
+	deductionAmount := 0.0
+	for _, component := range components {
+		if component.IsDeduction() {
+			deductionAmount += component.Amount
+		} else {
+			allowanceAmount += component.Amount
+		}
+	}
+	return allowanceAmount, deductionAmount
+}
+
+func (uc *PayrollUsecase) buildAttendanceBreakdown(attendances []model.Attendance) []res.PayslipAttendanceDetail {
+	var attendanceBreakdown []res.PayslipAttendanceDetail
+	for _, attendance := range attendances {
+		attendanceBreakdown = append(attendanceBreakdown, res.PayslipAttendanceDetail{
+			Date:        attendance.Date,
+			CheckIn:     attendance.Checkin,
+			CheckOut:    attendance.Checkout,
+			HoursWorked: attendance.HoursWorked,
+			Status:      attendance.Status,
+			Open:        !attendance.IsComplete(),
+			Weight:      uc.AttendanceWeight(attendance),
+		})
+	}
+	return attendanceBreakdown
+}
+
+func (uc *PayrollUsecase) buildOvertimeBreakdown(overtimes []model.Overtime, payslip *model.Payslip) []res.OvertimeDetail {
+	var overtimeBreakdown []res.OvertimeDetail
+	holidays := uc.periodHolidays(payslip, len(overtimes))
+
+	// Payslips processed before the paid hours were stored paid every overtime hour
+	payHours := uc.calculateOvertimePayHours(overtimes, holidays)
+	if payslip.PaidOvertimeHours == 0 && payslip.OvertimeAmount > 0 {
+		payHours = 0
+		for _, overtime := range overtimes {
+			payHours += float64(overtime.Hours) * uc.OvertimeMultiplier(overtime, holidays)
+		}
+	}
+	// Payslips processed before the rate was stored only have the amount to work it out from
+	overtimeRate := payslip.OvertimeRate
+	if overtimeRate == 0 && payHours > 0 {
+		overtimeRate = payslip.OvertimeAmount / payHours
+	}
+
+	for _, overtime := range overtimes {
+		paidMinutes, unpaidMinutes := uc.SplitOvertimeMinutes(overtime)
+		multiplier := uc.OvertimeMultiplier(overtime, holidays)
+		amount := uc.RoundMoney(float64(paidMinutes)/60*overtimeRate*multiplier, payslip.Currency)
+		overtimeBreakdown = append(overtimeBreakdown, res.OvertimeDetail{
+			Date:        overtime.OvertimeDate,
+			Hours:       overtime.Hours,
+			PaidHours:   float64(paidMinutes) / 60,
+			UnpaidHours: float64(unpaidMinutes) / 60,
+			Rate:        overtimeRate,
+			Multiplier:  multiplier,
+			Amount:      amount,
+			Reason:      overtime.Reason,
+		})
+	}
+	return overtimeBreakdown
+}
+
+// periodHolidays loads the holidays of the payslip period for the overtime breakdown. The breakdown
+// cannot fail, so a failed lookup prices every line without the holiday multiplier.
+func (uc *PayrollUsecase) periodHolidays(payslip *model.Payslip, overtimeCount int) map[string]bool {
+	if overtimeCount == 0 {
+		return nil
+	}
+	period := uc.Config.PeriodRange(payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	holidays, err := uc.payslipRepo.GetHolidaysForPeriod(period.StartDate, period.EndDate)
+	if err != nil {
+		return nil
+	}
+	return holidayDates(holidays)
+}
+
+// buildReimbursementBreakdown lists each reimbursement with the amount paid, next to the requested amount and
+// the approved amount of a partial approval. Clawbacks are listed with the type clawback and their negative amount.
+func (uc *PayrollUsecase) buildReimbursementBreakdown(reimbursements []model.Reimbursement) []res.ReimbursementDetail {
+	var reimbursementBreakdown []res.ReimbursementDetail
 	for _, reimbursement := range reimbursements {
-		reimbursementBreakdown = append(reimbursementBreakdown, map[string]interface{}{
-			"date":   reimbursement.ReimbursementDate,
-			"amount": reimbursement.Amount,
-			"reason": reimbursement.Reason,
-			"status": reimbursement.Status,
+		itemType := "reimbursement"
+		if reimbursement.IsClawback() {
+			itemType = "clawback"
+		}
+		reimbursementBreakdown = append(reimbursementBreakdown, res.ReimbursementDetail{
+			Type:            itemType,
+			Date:            reimbursement.ReimbursementDate,
+			Amount:          reimbursement.PayableAmount(),
+			RequestedAmount: reimbursement.Amount,
+			ApprovedAmount:  reimbursement.ApprovedAmount,
+			Reason:          reimbursement.Reason,
+			Status:          string(reimbursement.Status),
 		})
 	}
 	return reimbursementBreakdown
@@ -347,15 +2237,19 @@ func (uc *PayrollUsecase) buildReimbursementBreakdown(reimbursements []model.Rei
 
 func (uc *PayrollUsecase) calculateEmployeeSummary(employeeID uint, empPayslips []model.Payslip, employeeName string) map[string]interface{} {
 	var empTotalTakeHome float64
+	var empTotalGross float64
+	var empTotalTax float64
 	var empTotalBasic float64
 	var empTotalOvertime float64
 	var empTotalReimbursement float64
-	var empTotalAttendanceDays int
+	var empTotalAttendanceDays float64
 	var empTotalOvertimeHours int
 	var payslipCount int
 
 	for _, payslip := range empPayslips {
 		empTotalTakeHome += payslip.TotalAmount
+		empTotalGross += payslip.GrossAmount
+		empTotalTax += payslip.TaxAmount
 		empTotalBasic += payslip.BasicSalary
 		empTotalOvertime += payslip.OvertimeAmount
 		empTotalReimbursement += payslip.ReimbursementAmount
@@ -369,6 +2263,8 @@ func (uc *PayrollUsecase) calculateEmployeeSummary(employeeID uint, empPayslips
 		"employee_name":         employeeName,
 		"payslip_count":         payslipCount,
 		"total_take_home_pay":   empTotalTakeHome,
+		"total_gross_amount":    empTotalGross,
+		"total_tax_amount":      empTotalTax,
 		"total_basic_salary":    empTotalBasic,
 		"total_overtime_amount": empTotalOvertime,
 		"total_reimbursement":   empTotalReimbursement,
@@ -377,7 +2273,7 @@ func (uc *PayrollUsecase) calculateEmployeeSummary(employeeID uint, empPayslips
 	}
 }
 
-func (uc *PayrollUsecase) calculateSummaryTotals(employeeSummaries []map[string]interface{}, payslips []model.Payslip, totalTakeHomePay, totalBasicSalary, totalOvertimeAmount, totalReimbursementAmount float64, totalAttendanceDays, totalOvertimeHours int) map[string]interface{} {
+func (uc *PayrollUsecase) calculateSummaryTotals(employeeSummaries []map[string]interface{}, payslips []model.Payslip, totalTakeHomePay, totalBasicSalary, totalOvertimeAmount, totalReimbursementAmount, totalAttendanceDays float64, totalOvertimeHours int) map[string]interface{} {
 	employeeCount := len(employeeSummaries)
 	avgTakeHomePay := 0.0
 	avgBasicSalary := 0.0
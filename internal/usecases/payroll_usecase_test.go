@@ -0,0 +1,2730 @@
+// Package usecases contains unit tests for the payroll usecase functionality.
+//
+// BuildPayrollForecast tests cover:
+// 1. Monthly cost of active employees with recurring components
+// 2. Scheduled terminations dropping a later month's cost
+// 3. Future hires joining from their hire month
+// 4. Employees in different currencies forecast apart, grade default salaries used like payroll
+//
+// ProcessEmployeePayroll tests cover:
+// 1. Each open checkout policy over a period containing one open day
+// 2. Payroll blocked without an approved timesheet when required
+// 3. Unpaid overtime threshold applied per entry and shown in the breakdown
+// 4. Amounts rounded and rendered with the precision of the payslip currency
+// 5. Each negative net policy with deductions exceeding the gross pay
+// 6. A clamped deduction carried forward into the next payslip
+// 7. Progressive income tax taking gross pay down to net pay
+// 8. Basic salary prorated for a mid-period joiner and zero when joining after the period
+// 9. Weekend and holiday overtime multipliers, the higher one only for a holiday on a weekend
+// 10. The employee's salary currency used, and a mismatching requested currency refused
+// 11. Attendance days weighted by status, a half day with a full day's span counting for half
+// 12. The pay grade's default salary paid when the request has no basic salary
+// 13. Daily rate from the standard working days, absences deducted only when enabled, zero working days disabling both
+// 14. No sub-cent residue accumulated over 100 reimbursement and overtime line items
+// 15. Boundary days of a period given in UTC taken whole in a non-UTC server timezone
+// 16. Warnings for a salary paid without attendance and overtime beyond the attendance hours at the employee's standard hours, the payslip still stored
+// 17. Late check-ins counted in every mode and docked per incident or per minute, full hours worked or not
+// 18. Overtime rate derived from the basic salary on request and shown in the breakdown, refused without working hours
+// 19. Fixed allowances active at run time added to the gross and listed, one deactivated before the run left out
+// 20. Amounts stored in minor units when enabled, detailed payslips formatted back from them
+// 21. A partially approved reimbursement paid at its approved amount, the breakdown showing both amounts
+// 22. Net pay of a full period below the minimum wage warned or failed when enforced, mid-period joiners not checked
+// 23. A period overlapping an existing payslip, even by its boundary day, refused as ErrPayslipOverlaps, an adjacent period processed
+//
+// CountLateArrivals tests cover:
+// 1. A check-in exactly at the end of the grace period on time, one second later late by the minutes since the work start
+// 2. The payslip's late count agreeing with the late days of the attendance stats at the boundary, under one shared rule
+//
+// AddMoney tests cover:
+// 1. Sums in minor units exact where float sums drift, e.g. 0.1 + 0.2, and subtraction by negative amounts
+//
+// BackfillMinorUnits tests cover:
+// 1. Payslips without minor units filled at their currency's decimal places
+//
+// RoundMoney tests cover:
+// 1. Halves rounded up or to even by the configured rule, to the configured or the currency's decimal places
+//
+// BuildPayrollSummary tests cover:
+// 1. Totals grouped per currency and employees summarised once per currency
+//
+// BuildPayrollSummaryXLSX tests cover:
+// 1. One summary row per currency in currency order, amounts formatted to each currency's decimal places, base currency for payslips without one
+//
+// ComparePayrollSummaries tests cover:
+// 1. Deltas and percentage changes per currency, a currency paid in one period only compared against zero
+//
+// GetProjectedPay tests cover:
+// 1. Approved-only and pending-inclusive figures side by side, differing when pending items exist
+// 2. Pending items left out unless requested
+//
+// BuildDetailedPayslipResponse tests cover:
+// 1. JSON output identical to the map it replaced, breakdowns null without records and no allowance an empty list
+//
+// BuildDeductionBreakdown tests cover:
+// 1. Income tax, each recurring deduction and the brought forward deduction summing to gross minus net
+// 2. A clamped payslip listing the deferred deduction as negative
+// 3. A payslip without deductions returning an empty list and zero total
+// 4. The income tax basis showing the taxable income after the absence deduction and late penalty (late penalty tests)
+//
+// CalculateDeductions tests cover:
+// 1. Income below the lowest bracket, inside one bracket and across brackets
+//
+// BuildOvertimeConsistencyReport tests cover:
+// 1. Overtime on a day without present attendance flagged
+// 2. Overtime on a present day left out
+// 3. Managers limited to their reporting subtree, other employees refused
+//
+// ProcessPayrollRunWithAudit tests cover:
+// 1. Payslips, errors and run results in employee ID order whatever the worker count, with the payslips audited
+// 2. Benchmark of sequential and parallel processing against a repository with per-query latency
+//
+// ReprocessEmployeeInRunWithAudit tests cover:
+// 1. A failed employee succeeding on reprocess and the stored run result updated
+// 2. An already succeeded employee refused without a duplicate payslip
+//
+// BuildPayslipApprovalChain tests cover:
+// 1. Exactly the approved items paid in the payslip listed with their approvers, items approved after it left out
+//
+// BuildPayrollKPIs tests cover:
+// 1. KPI values against a seeded two-month dataset
+// 2. First-ever month reporting the change as not applicable
+// 3. Payslips in different currencies reported apart, never summed
+//
+// BuildTaxSummary tests cover:
+// 1. Totals and monthly rows matching the year's payslips, void and other-year payslips excluded
+// 2. A year without payslips reporting zeros for every month
+//
+// BuildYTDEarnings tests cover:
+// 1. Gross, tax, net, overtime, reimbursement and attendance totals of the year's payslips, void and other-year payslips excluded
+// 2. A year without payslips reporting zeros
+//
+// BuildEmployeePayslipSummary tests cover:
+// 1. Payslips starting on the first and last day of the range summed, earlier, later and void payslips excluded, an empty range zeroed
+// 2. A range with payslips in more than one currency refused with ErrMixedCurrencies, as are the YTD earnings and tax summary
+//
+// Reimbursement clawback tests cover:
+// 1. A clawback reducing the reimbursement total, gross and net pay, listed as a clawback in the breakdown
+// 2. A reimbursement total below the floor failing the payslip with ErrReimbursementBelowFloor, a negative floor letting it through
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
+	"github.com/yourname/payslip-system/internal/database"
+	"github.com/yourname/payslip-system/internal/dto/request"
+	"github.com/yourname/payslip-system/internal/dto/res"
+	"github.com/yourname/payslip-system/internal/helper"
+	"github.com/yourname/payslip-system/internal/middleware"
+	"github.com/yourname/payslip-system/internal/model"
+	"github.com/yourname/payslip-system/internal/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB creates an in-memory SQLite database for testing
+func setupTestDB(t testing.TB) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(
+		&model.Payslip{},
+		&model.PayGrade{},
+		&model.Employee{},
+		&model.Attendance{},
+		&model.Overtime{},
+		&model.Reimbursement{},
+		&model.EmployeeComponent{},
+		&model.Timesheet{},
+		&model.PayrollRun{},
+		&model.PayrollRunResult{},
+		&model.TaxBracket{},
+		&model.Holiday{},
+		&model.AuditLog{},
+	)
+	require.NoError(t, err)
+
+	// Every connection to :memory: opens its own database, so the payroll workers must share one
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	return db
+}
+
+// setupTestUsecase creates a payroll usecase backed by the given database
+func setupTestUsecase(db *gorm.DB) *PayrollUsecase {
+	uc := NewPayrollUsecase(repository.NewPayslipRepository(db), repository.NewEmployeeRepository(db))
+	uc.Config.Location = time.UTC
+	return uc
+}
+
+// createTestEmployee creates a test employee record hired well before the test periods
+func createTestEmployee(t testing.TB, db *gorm.DB, id uint) *model.Employee {
+	employee := &model.Employee{
+		DefaultAttribute: model.DefaultAttribute{ID: id},
+		Name:             "John Doe",
+		Password:         "hashed",
+		Role:             "employee",
+		Active:           true,
+		HireDate:         date(2020, time.January, 1),
+	}
+	require.NoError(t, db.Create(employee).Error)
+	return employee
+}
+
+// createTestAttendance creates a present attendance record, open when checkout is false
+func createTestAttendance(t testing.TB, db *gorm.DB, employeeID uint, day time.Time, checkout bool) {
+	attendance := &model.Attendance{
+		EmployeeID: employeeID,
+		Checkin:    day.Add(9 * time.Hour),
+		Status:     "present",
+		Date:       day,
+	}
+	if checkout {
+		checkoutAt := day.Add(17 * time.Hour)
+		attendance.Checkout = &checkoutAt
+		attendance.CalculateHours()
+	}
+	require.NoError(t, db.Create(attendance).Error)
+}
+
+// createTestOvertime creates an approved overtime record
+func createTestOvertime(t testing.TB, db *gorm.DB, employeeID uint, day string) *model.Overtime {
+	overtime := &model.Overtime{
+		EmployeeID:   employeeID,
+		OvertimeDate: day,
+		Hours:        2,
+		Reason:       "Release preparation",
+		Status:       model.OvertimeApproved,
+	}
+	require.NoError(t, db.Create(overtime).Error)
+	return overtime
+}
+
+// createTestPayslip creates a payslip for the month with the given net and overtime amounts
+func createTestPayslip(t testing.TB, db *gorm.DB, employeeID uint, month time.Month, totalAmount, overtimeAmount float64, status string) {
+	payslip := &model.Payslip{
+		EmployeeID:     employeeID,
+		PayPeriodStart: *date(2025, month, 1),
+		PayPeriodEnd:   date(2025, month, 1).AddDate(0, 1, -1),
+		BasicSalary:    totalAmount - overtimeAmount,
+		OvertimeAmount: overtimeAmount,
+		TotalAmount:    totalAmount,
+		ProcessedAt:    time.Now(),
+		Status:         status,
+	}
+	require.NoError(t, db.Create(payslip).Error)
+}
+
+// newForecastEmployee creates an employee with a salary and one allowance and deduction
+func newForecastEmployee(id uint, basicSalary float64, hireDate, terminationDate *time.Time) model.Employee {
+	return model.Employee{
+		DefaultAttribute: model.DefaultAttribute{ID: id},
+		Name:             "Employee",
+		Role:             "employee",
+		Active:           true,
+		BasicSalary:      basicSalary,
+		HireDate:         hireDate,
+		TerminationDate:  terminationDate,
+		Components: []model.EmployeeComponent{
+			{EmployeeID: id, Name: "transport", Kind: model.ComponentAllowance, Amount: 500000, Active: true},
+			{EmployeeID: id, Name: "insurance", Kind: model.ComponentDeduction, Amount: 100000, Active: true},
+		},
+	}
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestPayrollUsecase_BuildPayrollForecast_ActiveEmployees(t *testing.T) {
+	uc := NewPayrollUsecase(nil, nil)
+	employees := []model.Employee{
+		newForecastEmployee(1, 5000000, nil, nil),
+		newForecastEmployee(2, 7000000, nil, nil),
+	}
+
+	forecast := uc.BuildPayrollForecast(employees, *date(2025, time.March, 15), 2)
+
+	assert.Equal(t, "2025-03", forecast.StartMonth)
+	require.Len(t, forecast.Currencies, 1)
+	currencyForecast := forecast.Currencies[0]
+	assert.Equal(t, uc.Config.Currency, currencyForecast.Currency)
+	require.Len(t, currencyForecast.Forecast, 2)
+	for _, month := range currencyForecast.Forecast {
+		assert.Equal(t, 2, month.Headcount)
+		assert.Equal(t, 12000000.0, month.BasicSalary)
+		assert.Equal(t, 1000000.0, month.AllowanceAmount)
+		assert.Equal(t, 200000.0, month.DeductionAmount)
+		assert.Equal(t, 12800000.0, month.TotalCost)
+	}
+	assert.Equal(t, "2025-04", currencyForecast.Forecast[1].Month)
+	assert.Equal(t, 25600000.0, currencyForecast.TotalCost)
+}
+
+func TestPayrollUsecase_BuildPayrollForecast_TerminationReducesLaterMonth(t *testing.T) {
+	uc := NewPayrollUsecase(nil, nil)
+	employees := []model.Employee{
+		newForecastEmployee(1, 5000000, nil, nil),
+		newForecastEmployee(2, 7000000, nil, date(2025, time.April, 20)),
+	}
+
+	forecast := uc.BuildPayrollForecast(employees, *date(2025, time.March, 1), 3)
+
+	require.Len(t, forecast.Currencies, 1)
+	months := forecast.Currencies[0].Forecast
+	require.Len(t, months, 3)
+	// Still employed in March and (part of) April
+	assert.Equal(t, 2, months[0].Headcount)
+	assert.Equal(t, 2, months[1].Headcount)
+	assert.Equal(t, 12800000.0, months[1].TotalCost)
+	// Gone from May onwards
+	assert.Equal(t, "2025-05", months[2].Month)
+	assert.Equal(t, 1, months[2].Headcount)
+	assert.Equal(t, 5400000.0, months[2].TotalCost)
+	assert.Less(t, months[2].TotalCost, months[1].TotalCost)
+}
+
+func TestPayrollUsecase_BuildPayrollForecast_FutureHire(t *testing.T) {
+	uc := NewPayrollUsecase(nil, nil)
+	employees := []model.Employee{
+		newForecastEmployee(1, 5000000, nil, nil),
+		newForecastEmployee(2, 7000000, date(2025, time.April, 10), nil),
+	}
+
+	forecast := uc.BuildPayrollForecast(employees, *date(2025, time.March, 1), 2)
+
+	require.Len(t, forecast.Currencies, 1)
+	months := forecast.Currencies[0].Forecast
+	require.Len(t, months, 2)
+	assert.Equal(t, 1, months[0].Headcount)
+	assert.Equal(t, 5400000.0, months[0].TotalCost)
+	assert.Equal(t, 2, months[1].Headcount)
+	assert.Equal(t, 12800000.0, months[1].TotalCost)
+}
+
+func TestPayrollUsecase_BuildPayrollForecast_PerCurrencyWithGradeSalary(t *testing.T) {
+	uc := NewPayrollUsecase(nil, nil)
+	uc.Config.Currency = "IDR"
+	graded := newForecastEmployee(1, 0, nil, nil)
+	graded.PayGrade = &model.PayGrade{Name: "Junior", MinSalary: 4000000, MaxSalary: 6000000, DefaultSalary: 4500000}
+	dollars := newForecastEmployee(2, 3000, nil, nil)
+	dollars.Currency = "USD"
+	dollars.Components = nil
+
+	forecast := uc.BuildPayrollForecast([]model.Employee{graded, dollars}, *date(2025, time.March, 1), 1)
+
+	require.Len(t, forecast.Currencies, 2)
+	// Without a salary of their own the employee is forecast at their grade's default salary, as payroll pays
+	idr := forecast.Currencies[0]
+	assert.Equal(t, "IDR", idr.Currency)
+	assert.Equal(t, 4500000.0, idr.Forecast[0].BasicSalary)
+	assert.Equal(t, 4900000.0, idr.TotalCost)
+	// The USD salary is never added to the IDR cost
+	usd := forecast.Currencies[1]
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, 1, usd.Forecast[0].Headcount)
+	assert.Equal(t, 3000.0, usd.TotalCost)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_OpenCheckoutPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         config.OpenCheckoutPolicy
+		attendanceDays float64
+	}{
+		{name: "present counts a full day", policy: config.OpenCheckoutPresent, attendanceDays: 3},
+		{name: "half day counts half a day", policy: config.OpenCheckoutHalfDay, attendanceDays: 2.5},
+		{name: "exclude does not count the day", policy: config.OpenCheckoutExclude, attendanceDays: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			uc := setupTestUsecase(db)
+			uc.Config.OpenCheckoutPolicy = tt.policy
+
+			employee := createTestEmployee(t, db, 1)
+			createTestAttendance(t, db, employee.ID, *date(2025, time.June, 2), true)
+			createTestAttendance(t, db, employee.ID, *date(2025, time.June, 3), true)
+			createTestAttendance(t, db, employee.ID, *date(2025, time.June, 4), false)
+
+			payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+				PayPeriodStart: *date(2025, time.June, 1),
+				PayPeriodEnd:   *date(2025, time.June, 30),
+				BasicSalary:    5000000,
+				OvertimeRate:   50000,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.attendanceDays, payslip.AttendanceDays)
+			assert.Equal(t, 1, payslip.OpenAttendanceDays)
+		})
+	}
+}
+
+func TestPayrollUsecase_NewPayrollUsecase_DefaultOpenCheckoutPolicy(t *testing.T) {
+	t.Setenv("PAYROLL_OPEN_CHECKOUT_POLICY", "")
+
+	uc := NewPayrollUsecase(nil, nil)
+
+	assert.Equal(t, config.OpenCheckoutPresent, uc.Config.OpenCheckoutPolicy)
+}
+
+func TestPayrollUsecase_BuildOvertimeConsistencyReport(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+
+	employee := createTestEmployee(t, db, 1)
+	createTestAttendance(t, db, employee.ID, *date(2025, time.June, 2), true)
+	createTestOvertime(t, db, employee.ID, "2025-06-02")
+	suspicious := createTestOvertime(t, db, employee.ID, "2025-06-07")
+
+	report, err := uc.BuildOvertimeConsistencyReport(request.OvertimeConsistencyRequest{
+		PeriodStart: *date(2025, time.June, 1),
+		PeriodEnd:   *date(2025, time.June, 30),
+	}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.CheckedCount)
+	assert.Equal(t, 1, report.FlaggedCount)
+	require.Len(t, report.Flagged, 1)
+	assert.Equal(t, suspicious.ID, report.Flagged[0].OvertimeID)
+	assert.Equal(t, "2025-06-07", report.Flagged[0].OvertimeDate)
+	assert.Equal(t, "John Doe", report.Flagged[0].EmployeeName)
+}
+
+func TestPayrollUsecase_BuildOvertimeConsistencyReport_AbsentDayFlagged(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+
+	employee := createTestEmployee(t, db, 1)
+	require.NoError(t, db.Create(&model.Attendance{
+		EmployeeID: employee.ID,
+		Checkin:    *date(2025, time.June, 3),
+		Status:     "absent",
+		Date:       *date(2025, time.June, 3),
+	}).Error)
+	createTestOvertime(t, db, employee.ID, "2025-06-03")
+
+	report, err := uc.BuildOvertimeConsistencyReport(request.OvertimeConsistencyRequest{
+		PeriodStart: *date(2025, time.June, 1),
+		PeriodEnd:   *date(2025, time.June, 30),
+		EmployeeID:  employee.ID,
+	}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.FlaggedCount)
+}
+
+func TestPayrollUsecase_BuildOvertimeConsistencyReport_ManagerScope(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+
+	manager := createTestEmployee(t, db, 1)
+	report := createTestEmployee(t, db, 2)
+	require.NoError(t, db.Model(report).Update("manager_id", manager.ID).Error)
+	other := createTestEmployee(t, db, 3)
+	reported := createTestOvertime(t, db, report.ID, "2025-06-07")
+	createTestOvertime(t, db, other.ID, "2025-06-07")
+	req := request.OvertimeConsistencyRequest{
+		PeriodStart: *date(2025, time.June, 1),
+		PeriodEnd:   *date(2025, time.June, 30),
+	}
+
+	scoped, err := uc.BuildOvertimeConsistencyReport(req, manager.ID)
+	require.NoError(t, err)
+	require.Len(t, scoped.Flagged, 1)
+	assert.Equal(t, reported.ID, scoped.Flagged[0].OvertimeID)
+
+	// An employee outside the subtree is refused
+	req.EmployeeID = other.ID
+	_, err = uc.BuildOvertimeConsistencyReport(req, manager.ID)
+	assert.ErrorIs(t, err, ErrEmployeeNotManaged)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_RequireApprovedTimesheet(t *testing.T) {
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	}
+
+	t.Run("blocked without a timesheet", func(t *testing.T) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.RequireApprovedTimesheet = true
+		employee := createTestEmployee(t, db, 1)
+
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, payrollReq)
+
+		assert.Nil(t, payslip)
+		assert.EqualError(t, err, "approved timesheet required for this period")
+	})
+
+	t.Run("blocked with a submitted timesheet", func(t *testing.T) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.RequireApprovedTimesheet = true
+		employee := createTestEmployee(t, db, 1)
+
+		_, err := repository.NewTimesheetRepository(db).SubmitTimesheetWithAudit(&model.Timesheet{
+			EmployeeID:  employee.ID,
+			PeriodStart: payrollReq.PayPeriodStart,
+			PeriodEnd:   payrollReq.PayPeriodEnd,
+		}, middleware.NewAuditableDB(db, employee.ID))
+		require.NoError(t, err)
+
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, payrollReq)
+
+		assert.Nil(t, payslip)
+		assert.EqualError(t, err, "approved timesheet required for this period")
+	})
+
+	t.Run("allowed with an approved timesheet", func(t *testing.T) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.RequireApprovedTimesheet = true
+		employee := createTestEmployee(t, db, 1)
+		timesheetRepo := repository.NewTimesheetRepository(db)
+
+		timesheet, err := timesheetRepo.SubmitTimesheetWithAudit(&model.Timesheet{
+			EmployeeID:  employee.ID,
+			PeriodStart: payrollReq.PayPeriodStart,
+			PeriodEnd:   payrollReq.PayPeriodEnd,
+		}, middleware.NewAuditableDB(db, employee.ID))
+		require.NoError(t, err)
+		_, err = timesheetRepo.ApproveTimesheetWithAudit(timesheet, 99, middleware.NewAuditableDB(db, 99))
+		require.NoError(t, err)
+
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, payrollReq)
+
+		require.NoError(t, err)
+		assert.Equal(t, employee.ID, payslip.EmployeeID)
+	})
+
+	t.Run("allowed without a timesheet when not required", func(t *testing.T) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.RequireApprovedTimesheet = false
+		employee := createTestEmployee(t, db, 1)
+
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, payrollReq)
+
+		require.NoError(t, err)
+		assert.NotNil(t, payslip)
+	})
+}
+
+func TestPayrollUsecase_BuildPayrollKPIs(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	createTestEmployee(t, db, 1)
+	createTestEmployee(t, db, 2)
+
+	// May
+	createTestPayslip(t, db, 1, time.May, 5000000, 0, model.PayslipStatusPaid)
+	createTestPayslip(t, db, 2, time.May, 3000000, 0, model.PayslipStatusPaid)
+	// June, with a voided payslip that must be ignored
+	createTestPayslip(t, db, 1, time.June, 6000000, 1000000, model.PayslipStatusProcessed)
+	createTestPayslip(t, db, 2, time.June, 4000000, 0, model.PayslipStatusProcessed)
+	createTestPayslip(t, db, 2, time.June, 9000000, 0, model.PayslipStatusVoid)
+
+	kpis, err := uc.BuildPayrollKPIs(*date(2025, time.June, 15))
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-06", kpis.Month)
+	require.Len(t, kpis.Currencies, 1)
+	idr := kpis.Currencies[0]
+	assert.Equal(t, uc.Config.Currency, idr.Currency)
+	assert.Equal(t, 10000000.0, idr.TotalCost)
+	assert.Equal(t, 2, idr.Headcount)
+	assert.Equal(t, 5000000.0, idr.AverageNet)
+	assert.InDelta(t, 10.0, idr.OvertimePercent, 0.0001)
+	assert.Equal(t, 8000000.0, idr.PreviousTotalCost)
+	require.NotNil(t, idr.MonthOverMonthChange)
+	assert.InDelta(t, 25.0, *idr.MonthOverMonthChange, 0.0001)
+}
+
+func TestPayrollUsecase_BuildPayrollKPIs_PerCurrency(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.Currency = "IDR"
+	createTestEmployee(t, db, 1)
+	createTestEmployee(t, db, 2)
+
+	createTestPayslip(t, db, 1, time.June, 6000000, 0, model.PayslipStatusProcessed)
+	require.NoError(t, db.Create(&model.Payslip{
+		EmployeeID:     2,
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		Currency:       "USD",
+		BasicSalary:    4000,
+		OvertimeAmount: 1000,
+		TotalAmount:    5000,
+		ProcessedAt:    time.Now(),
+		Status:         model.PayslipStatusProcessed,
+	}).Error)
+
+	kpis, err := uc.BuildPayrollKPIs(*date(2025, time.June, 15))
+	require.NoError(t, err)
+
+	// The USD payslip is never added to the IDR one
+	require.Len(t, kpis.Currencies, 2)
+	assert.Equal(t, "IDR", kpis.Currencies[0].Currency)
+	assert.Equal(t, 6000000.0, kpis.Currencies[0].TotalCost)
+	assert.Equal(t, 1, kpis.Currencies[0].Headcount)
+	assert.Equal(t, "USD", kpis.Currencies[1].Currency)
+	assert.Equal(t, 5000.0, kpis.Currencies[1].TotalCost)
+	assert.Equal(t, 5000.0, kpis.Currencies[1].AverageNet)
+	assert.InDelta(t, 20.0, kpis.Currencies[1].OvertimePercent, 0.0001)
+	assert.Nil(t, kpis.Currencies[1].MonthOverMonthChange)
+}
+
+func TestPayrollUsecase_BuildPayrollKPIs_FirstMonth(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	createTestEmployee(t, db, 1)
+	createTestPayslip(t, db, 1, time.May, 5000000, 500000, model.PayslipStatusProcessed)
+
+	kpis, err := uc.BuildPayrollKPIs(*date(2025, time.May, 1))
+	require.NoError(t, err)
+
+	require.Len(t, kpis.Currencies, 1)
+	assert.Equal(t, 5000000.0, kpis.Currencies[0].TotalCost)
+	assert.Equal(t, 0.0, kpis.Currencies[0].PreviousTotalCost)
+	assert.Nil(t, kpis.Currencies[0].MonthOverMonthChange)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_OverlappingPeriod(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	period := func(start, end *time.Time) request.PayrollRequest {
+		return request.PayrollRequest{PayPeriodStart: *start, PayPeriodEnd: *end, BasicSalary: 5000000, OvertimeRate: 50000}
+	}
+	_, err := uc.ProcessEmployeePayroll(employee.ID, period(date(2025, time.June, 1), date(2025, time.June, 30)))
+	require.NoError(t, err)
+
+	// The exact period is still reported as already processed
+	_, err = uc.ProcessEmployeePayroll(employee.ID, period(date(2025, time.June, 1), date(2025, time.June, 30)))
+	assert.ErrorIs(t, err, repository.ErrPayslipExists)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, period(date(2025, time.June, 15), date(2025, time.July, 15)))
+	assert.Nil(t, payslip)
+	assert.ErrorIs(t, err, repository.ErrPayslipOverlaps)
+
+	// Periods include their last day, so one starting on it overlaps while the next day is adjacent
+	_, err = uc.ProcessEmployeePayroll(employee.ID, period(date(2025, time.June, 30), date(2025, time.July, 29)))
+	assert.ErrorIs(t, err, repository.ErrPayslipOverlaps)
+
+	payslip, err = uc.ProcessEmployeePayroll(employee.ID, period(date(2025, time.July, 1), date(2025, time.July, 31)))
+	require.NoError(t, err)
+	assert.NotNil(t, payslip)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_UnpaidOvertimeThreshold(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.UnpaidOvertimeMinutes = 30
+	employee := createTestEmployee(t, db, 1)
+
+	overtimes := []*model.Overtime{
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-02", StartTime: "17:00", EndTime: "17:45", Reason: "Release preparation", Status: model.OvertimeApproved},
+		{EmployeeID: employee.ID, OvertimeDate: "2025-06-03", StartTime: "17:00", EndTime: "17:20", Reason: "Quick hotfix", Status: model.OvertimeApproved},
+	}
+	for _, overtime := range overtimes {
+		require.NoError(t, db.Create(overtime).Error)
+	}
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   60000,
+	})
+	require.NoError(t, err)
+
+	// Only 15 minutes of the 45 minute entry are paid, the 20 minute entry pays nothing
+	assert.Equal(t, 0.25, payslip.PaidOvertimeHours)
+	assert.Equal(t, 15000.0, payslip.OvertimeAmount)
+	assert.Equal(t, 5015000.0, payslip.TotalAmount)
+
+	detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, []model.Overtime{*overtimes[0], *overtimes[1]}, nil)
+	breakdown := detail.OvertimeBreakdown
+	require.Len(t, breakdown, 2)
+	assert.Equal(t, 0.25, breakdown[0].PaidHours)
+	assert.Equal(t, 0.5, breakdown[0].UnpaidHours)
+	assert.Equal(t, 15000.0, breakdown[0].Amount)
+	assert.Equal(t, 0.0, breakdown[1].PaidHours)
+	assert.InDelta(t, 20.0/60, breakdown[1].UnpaidHours, 0.0001)
+	assert.Equal(t, 0.0, breakdown[1].Amount)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_DerivedOvertimeRate(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.WorkingDaysPerPeriod = 22
+	uc.Config.OvertimeRateMultiplier = 1.5
+	employee := createTestEmployee(t, db, 1)
+	overtime := createTestOvertime(t, db, employee.ID, "2025-06-02")
+
+	req := request.PayrollRequest{
+		PayPeriodStart:     *date(2025, time.June, 1),
+		PayPeriodEnd:       *date(2025, time.June, 30),
+		BasicSalary:        4400000,
+		OvertimeRate:       60000,
+		DeriveOvertimeRate: true,
+	}
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, req)
+	require.NoError(t, err)
+
+	// 4,400,000 over 22 days of 8 hours is 25,000 an hour, times 1.5; the requested rate is ignored
+	assert.Equal(t, 37500.0, payslip.OvertimeRate)
+	assert.Equal(t, 75000.0, payslip.OvertimeAmount)
+
+	detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, []model.Overtime{*overtime}, nil)
+	breakdown := detail.OvertimeBreakdown
+	require.Len(t, breakdown, 1)
+	assert.Equal(t, 37500.0, breakdown[0].Rate)
+	assert.Equal(t, 75000.0, breakdown[0].Amount)
+	assert.Equal(t, 37500.0, detail.Summary.OvertimeRate)
+
+	t.Run("explicit rate without the flag", func(t *testing.T) {
+		req := req
+		req.DeriveOvertimeRate = false
+		rate, err := uc.ResolveOvertimeRate(employee, req.BasicSalary, req)
+		require.NoError(t, err)
+		assert.Equal(t, 60000.0, rate)
+	})
+
+	t.Run("no standard hours", func(t *testing.T) {
+		rate, err := uc.ResolveOvertimeRate(&model.Employee{StandardHours: 0}, req.BasicSalary, req)
+		assert.ErrorIs(t, err, ErrOvertimeRateNotDerivable)
+		assert.Zero(t, rate)
+	})
+
+	t.Run("no working days", func(t *testing.T) {
+		uc := setupTestUsecase(db)
+		uc.Config.WorkingDaysPerPeriod = 0
+		other := createTestEmployee(t, db, 2)
+		_, err := uc.ProcessEmployeePayroll(other.ID, req)
+		assert.ErrorIs(t, err, ErrOvertimeRateNotDerivable)
+	})
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_Allowances(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	transport := model.EmployeeComponent{EmployeeID: employee.ID, Name: "Transport", Kind: model.ComponentAllowance, Amount: 500000, Active: true}
+	housing := model.EmployeeComponent{EmployeeID: employee.ID, Name: "Housing", Kind: model.ComponentAllowance, Amount: 1500000, Active: true}
+	require.NoError(t, db.Create(&transport).Error)
+	require.NoError(t, db.Create(&housing).Error)
+	// Deactivated during the period, before payroll runs
+	require.NoError(t, db.Model(&housing).Update("active", false).Error)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 500000.0, payslip.AllowanceAmount)
+	assert.Equal(t, 5500000.0, payslip.GrossAmount)
+
+	components := []model.EmployeeComponent{transport}
+	assert.Equal(t, []res.PayslipAllowanceLine{{Name: "Transport", Amount: 500000.0}}, uc.BuildAllowanceBreakdown(payslip, components))
+
+	// Changed after the payslip was processed, the stored amount is listed as one line
+	components[0].Amount = 600000
+	breakdown := uc.BuildAllowanceBreakdown(payslip, components)
+	require.Len(t, breakdown, 1)
+	assert.Equal(t, 500000.0, breakdown[0].Amount)
+	assert.Equal(t, "Allowances active when the payslip was processed", breakdown[0].Name)
+}
+
+func TestPayrollUsecase_SplitOvertimeMinutes_Disabled(t *testing.T) {
+	uc := NewPayrollUsecase(nil, nil)
+	uc.Config.UnpaidOvertimeMinutes = 0
+
+	paid, unpaid := uc.SplitOvertimeMinutes(model.Overtime{Hours: 2, StartTime: "17:00", EndTime: "19:30"})
+
+	assert.Equal(t, 120, paid)
+	assert.Equal(t, 0, unpaid)
+}
+
+func TestPayrollUsecase_BuildPayslipApprovalChain(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	manager := &model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 2}, Name: "Mary Manager", Password: "hashed", Role: "manager", Active: true}
+	admin := &model.Employee{DefaultAttribute: model.DefaultAttribute{ID: 3}, Name: "Alice Admin", Password: "hashed", Role: "admin", Active: true}
+	require.NoError(t, db.Create(manager).Error)
+	require.NoError(t, db.Create(admin).Error)
+
+	approvedOvertime := &model.Overtime{EmployeeID: employee.ID, OvertimeDate: "2025-06-10", Hours: 2, Reason: "Release preparation"}
+	approvedOvertime.Approve(manager.ID)
+	pendingOvertime := &model.Overtime{EmployeeID: employee.ID, OvertimeDate: "2025-06-11", Hours: 3, Reason: "Not approved yet", Status: model.OvertimePending}
+	laterOvertime := &model.Overtime{EmployeeID: employee.ID, OvertimeDate: "2025-07-01", Hours: 1, Reason: "Next period work"}
+	laterOvertime.Approve(manager.ID)
+	for _, overtime := range []*model.Overtime{approvedOvertime, pendingOvertime, laterOvertime} {
+		require.NoError(t, db.Create(overtime).Error)
+	}
+
+	approvedReimbursement := &model.Reimbursement{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 12), Amount: 250000, Category: model.ReimbursementTravel, Reason: "Client visit taxi"}
+	approvedReimbursement.Approve(admin.ID)
+	rejectedReimbursement := &model.Reimbursement{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 13), Amount: 900000, Category: model.ReimbursementOther, Reason: "Personal purchase"}
+	rejectedReimbursement.Reject(admin.ID, "not business related")
+	for _, reimbursement := range []*model.Reimbursement{approvedReimbursement, rejectedReimbursement} {
+		require.NoError(t, db.Create(reimbursement).Error)
+	}
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, payslip.OvertimeHours)
+	require.Equal(t, 250000.0, payslip.ReimbursementAmount)
+
+	// Approved in the period once the payslip was processed, so not paid in it
+	approvedLater := payslip.ProcessedAt.Add(time.Minute)
+	require.NoError(t, db.Model(pendingOvertime).Updates(map[string]interface{}{"status": model.OvertimeApproved, "approved_by": manager.ID, "approved_at": approvedLater}).Error)
+	lateReimbursement := &model.Reimbursement{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 20), Amount: 100000, Category: model.ReimbursementTravel, Reason: "Approved after payroll"}
+	lateReimbursement.Approve(admin.ID)
+	lateReimbursement.ApprovedAt = &approvedLater
+	require.NoError(t, db.Create(lateReimbursement).Error)
+
+	chain, err := uc.BuildPayslipApprovalChain(payslip)
+	require.NoError(t, err)
+
+	assert.Equal(t, payslip.ID, chain.PayslipID)
+	assert.Equal(t, 2, chain.ApprovalCount)
+
+	require.Len(t, chain.Overtimes, 1)
+	assert.Equal(t, approvedOvertime.ID, chain.Overtimes[0].ItemID)
+	assert.Equal(t, 2, chain.Overtimes[0].Hours)
+	require.NotNil(t, chain.Overtimes[0].ApprovedBy)
+	assert.Equal(t, manager.ID, *chain.Overtimes[0].ApprovedBy)
+	assert.Equal(t, "Mary Manager", chain.Overtimes[0].ApproverName)
+	assert.NotNil(t, chain.Overtimes[0].ApprovedAt)
+
+	require.Len(t, chain.Reimbursements, 1)
+	assert.Equal(t, approvedReimbursement.ID, chain.Reimbursements[0].ItemID)
+	assert.Equal(t, "2025-06-12", chain.Reimbursements[0].Date)
+	assert.Equal(t, 250000.0, chain.Reimbursements[0].Amount)
+	require.NotNil(t, chain.Reimbursements[0].ApprovedBy)
+	assert.Equal(t, admin.ID, *chain.Reimbursements[0].ApprovedBy)
+	assert.Equal(t, "Alice Admin", chain.Reimbursements[0].ApproverName)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_OvertimeDayMultipliers(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.WeekendOvertimeMultiplier = 1.5
+	uc.Config.HolidayOvertimeMultiplier = 2
+	employee := createTestEmployee(t, db, 1)
+	require.NoError(t, db.Create(&[]model.Holiday{{Date: "2025-06-01", Name: "Pancasila Day"}, {Date: "2025-06-05", Name: "Eid al-Adha"}}).Error)
+
+	overtimes := []model.Overtime{
+		*createTestOvertime(t, db, employee.ID, "2025-06-04"), // Wednesday
+		*createTestOvertime(t, db, employee.ID, "2025-06-07"), // Saturday
+		*createTestOvertime(t, db, employee.ID, "2025-06-05"), // holiday on a Thursday
+		*createTestOvertime(t, db, employee.ID, "2025-06-01"), // holiday on a Sunday
+	}
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   10000,
+	})
+	require.NoError(t, err)
+
+	// 2 hours each: 20,000 + 30,000 + 40,000 + 40,000
+	assert.Equal(t, 8.0, payslip.PaidOvertimeHours)
+	assert.Equal(t, 130000.0, payslip.OvertimeAmount)
+
+	detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, overtimes, nil)
+	breakdown := detail.OvertimeBreakdown
+	require.Len(t, breakdown, 4)
+	wantMultipliers := []float64{1, 1.5, 2, 2}
+	wantAmounts := []float64{20000, 30000, 40000, 40000}
+	for i, line := range breakdown {
+		assert.Equal(t, 10000.0, line.Rate, overtimes[i].OvertimeDate)
+		assert.Equal(t, wantMultipliers[i], line.Multiplier, overtimes[i].OvertimeDate)
+		assert.Equal(t, wantAmounts[i], line.Amount, overtimes[i].OvertimeDate)
+	}
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_CurrencyPrecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		currency     string
+		basicSalary  float64
+		overtimeRate float64
+		wantOvertime float64
+		wantTotal    float64
+		wantDisplay  string
+	}{
+		{name: "JPY has no decimals", currency: "JPY", basicSalary: 300000.4, overtimeRate: 1000.4, wantOvertime: 2001, wantTotal: 302001, wantDisplay: "302001"},
+		{name: "USD keeps cents", currency: "usd", basicSalary: 3000.25, overtimeRate: 10.125, wantOvertime: 20.25, wantTotal: 3020.5, wantDisplay: "3020.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			uc := setupTestUsecase(db)
+			employee := createTestEmployee(t, db, 1)
+			createTestOvertime(t, db, employee.ID, "2025-06-06")
+
+			payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+				PayPeriodStart: *date(2025, time.June, 1),
+				PayPeriodEnd:   *date(2025, time.June, 30),
+				BasicSalary:    tt.basicSalary,
+				OvertimeRate:   tt.overtimeRate,
+				Currency:       tt.currency,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, strings.ToUpper(tt.currency), payslip.Currency)
+			assert.Equal(t, tt.wantOvertime, payslip.OvertimeAmount)
+			assert.Equal(t, tt.wantTotal, payslip.TotalAmount)
+
+			detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil)
+			assert.Equal(t, tt.wantDisplay, detail.Summary.Display.TotalTakeHomePay)
+		})
+	}
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_NoSubCentResidue(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	// Summed as raw floats the amounts drift off the cent, e.g. 100 times 0.10 gives 9.99999999999998
+	amounts := []float64{100.50, 250.75, 375.25, 0.10}
+	var reimbursements []model.Reimbursement
+	for i := 0; i < 100; i++ {
+		reimbursements = append(reimbursements, model.Reimbursement{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 12), Amount: amounts[i%len(amounts)], Category: model.ReimbursementOther, Reason: "Line item", Status: model.ReimbursementApproved})
+	}
+	require.NoError(t, db.Create(&reimbursements).Error)
+	for day := 2; day <= 27; day++ {
+		createTestOvertime(t, db, employee.ID, fmt.Sprintf("2025-06-%02d", day))
+	}
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    3000,
+		OvertimeRate:   10.1,
+		Currency:       "USD",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 18165.0, payslip.ReimbursementAmount)
+	for name, amount := range map[string]float64{
+		"overtime":      payslip.OvertimeAmount,
+		"reimbursement": payslip.ReimbursementAmount,
+		"tax":           payslip.TaxAmount,
+		"total":         payslip.TotalAmount,
+	} {
+		// The shortest representation has at most two decimals, no residue is left
+		_, decimals, _ := strings.Cut(strconv.FormatFloat(amount, 'f', -1, 64), ".")
+		assert.LessOrEqual(t, len(decimals), 2, "%s amount %v", name, amount)
+		assert.Equal(t, uc.RoundMoney(amount, "USD"), amount, name)
+	}
+}
+
+func TestPayrollUsecase_AddMoney(t *testing.T) {
+	uc := &PayrollUsecase{}
+	// Go folds constant expressions exactly, the drift only shows at run time
+	tenth, fifth := 0.1, 0.2
+	require.NotEqual(t, 0.3, tenth+fifth)
+
+	tests := []struct {
+		name     string
+		currency string
+		amounts  []float64
+		want     float64
+	}{
+		{name: "tenths", currency: "USD", amounts: []float64{0.1, 0.2}, want: 0.3},
+		{name: "ten dimes", currency: "USD", amounts: []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1}, want: 1},
+		{name: "subtraction", currency: "USD", amounts: []float64{0.3, -0.1}, want: 0.2},
+		{name: "amounts rounded first", currency: "USD", amounts: []float64{0.004, 0.004}, want: 0},
+		{name: "currency without decimals", currency: "JPY", amounts: []float64{100.4, 0.4}, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, uc.AddMoney(tt.currency, tt.amounts...))
+		})
+	}
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_MinorUnits(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	reimbursements := []model.Reimbursement{
+		{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 12), Amount: 0.1, Category: model.ReimbursementOther, Reason: "Stamp", Status: model.ReimbursementApproved},
+		{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 13), Amount: 0.2, Category: model.ReimbursementOther, Reason: "Envelope", Status: model.ReimbursementApproved},
+	}
+	require.NoError(t, db.Create(&reimbursements).Error)
+
+	req := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    1000.1,
+		Currency:       "USD",
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, req)
+		require.NoError(t, err)
+		assert.Equal(t, 0.3, payslip.ReimbursementAmount)
+		assert.Equal(t, 1000.4, payslip.GrossAmount)
+		assert.Nil(t, payslip.MinorUnitScale)
+		assert.Zero(t, payslip.GrossAmountMinor)
+		require.NoError(t, db.Unscoped().Delete(payslip).Error)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		uc.Config.MoneyMinorUnits = true
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, req)
+		require.NoError(t, err)
+
+		var stored model.Payslip
+		require.NoError(t, db.First(&stored, payslip.ID).Error)
+		require.NotNil(t, stored.MinorUnitScale)
+		assert.Equal(t, 2, *stored.MinorUnitScale)
+		assert.Equal(t, int64(30), stored.ReimbursementAmountMinor)
+		assert.Equal(t, int64(100040), stored.GrossAmountMinor)
+		assert.Equal(t, int64(100040), stored.TotalAmountMinor)
+
+		// The detailed payslip is read from the minor units, not the decimal columns
+		require.NoError(t, db.Model(&stored).UpdateColumn("gross_amount", 1000.4000001).Error)
+		require.NoError(t, db.First(&stored, payslip.ID).Error)
+		summary := uc.BuildDetailedPayslipResponse(&stored, employee, nil, nil, nil).Summary
+		assert.Equal(t, 0.3, summary.ReimbursementAmount)
+		assert.Equal(t, 1000.4, summary.GrossAmount)
+		assert.Equal(t, "1000.40", summary.Display.GrossAmount)
+		assert.Equal(t, 1000.4000001, stored.GrossAmount, "the stored payslip is left as it is")
+	})
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_PartialReimbursementApproval(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	approvedAmount := 100000.0
+	reimbursements := []model.Reimbursement{
+		{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 12), Amount: 250000, ApprovedAmount: &approvedAmount, Category: model.ReimbursementTravel, Reason: "Client visit taxi", Status: model.ReimbursementApproved},
+		{EmployeeID: employee.ID, ReimbursementDate: *date(2025, time.June, 13), Amount: 50000, Category: model.ReimbursementMeals, Reason: "Team lunch", Status: model.ReimbursementApproved},
+	}
+	require.NoError(t, db.Create(&reimbursements).Error)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 150000.0, payslip.ReimbursementAmount)
+
+	stored, err := repository.NewPayslipRepository(db).GetApprovedReimbursementsForPeriod(employee.ID, payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	require.NoError(t, err)
+	breakdown := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, stored).ReimbursementBreakdown
+	require.Len(t, breakdown, 2)
+	assert.Equal(t, 100000.0, breakdown[0].Amount)
+	assert.Equal(t, 250000.0, breakdown[0].RequestedAmount)
+	assert.Equal(t, &approvedAmount, breakdown[0].ApprovedAmount)
+	assert.Equal(t, 50000.0, breakdown[1].Amount)
+	assert.Equal(t, 50000.0, breakdown[1].RequestedAmount)
+	assert.Nil(t, breakdown[1].ApprovedAmount)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_MinimumWage(t *testing.T) {
+	june := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    3000000,
+	}
+
+	tests := []struct {
+		name          string
+		mode          config.MinimumWageMode
+		hireDate      *time.Time
+		currency      string
+		wantShortfall float64
+		wantErr       bool
+	}{
+		{name: "warned", mode: config.MinimumWageWarn, wantShortfall: 500000},
+		{name: "enforced", mode: config.MinimumWageEnforce, wantErr: true},
+		{name: "mid-period joiner", mode: config.MinimumWageEnforce, hireDate: date(2025, time.June, 16)},
+		{name: "other currency", mode: config.MinimumWageEnforce, currency: "USD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			uc := setupTestUsecase(db)
+			uc.Config.Currency = "IDR"
+			uc.Config.MinimumWage = 3500000
+			uc.Config.MinimumWageMode = tt.mode
+			employee := createTestEmployee(t, db, 1)
+			if tt.hireDate != nil {
+				require.NoError(t, db.Model(employee).Update("hire_date", tt.hireDate).Error)
+			}
+			req := june
+			req.Currency = tt.currency
+
+			payslip, err := uc.ProcessEmployeePayroll(employee.ID, req)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrBelowMinimumWage)
+				assert.Contains(t, err.Error(), "net pay is below the minimum wage of 3500000.00, short by 500000.00 with a net pay of 3000000.00")
+				exists, err := repository.NewPayslipRepository(db).CheckPayslipExists(employee.ID, req.PayPeriodStart, req.PayPeriodEnd)
+				require.NoError(t, err)
+				assert.False(t, exists)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantShortfall == 0 {
+				assert.Nil(t, payslip.MinimumWageShortfall)
+				return
+			}
+			require.NotNil(t, payslip.MinimumWageShortfall)
+			assert.Equal(t, model.MinimumWageShortfall{Floor: 3500000, NetAmount: 3000000, Shortfall: tt.wantShortfall}, *payslip.MinimumWageShortfall)
+			assert.Contains(t, payslip.Warnings, "net pay of 3000000.00 is 500000.00 below the minimum wage of 3500000.00")
+		})
+	}
+
+	// Net pay at the floor is not flagged
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.Currency = "IDR"
+	uc.Config.MinimumWage = 3000000
+	uc.Config.MinimumWageMode = config.MinimumWageEnforce
+	employee := createTestEmployee(t, db, 1)
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, june)
+	require.NoError(t, err)
+	assert.Nil(t, payslip.MinimumWageShortfall)
+}
+
+func TestBackfillMinorUnits(t *testing.T) {
+	db := setupTestDB(t)
+	employee := createTestEmployee(t, db, 1)
+
+	usd := &model.Payslip{EmployeeID: employee.ID, PayPeriodStart: *date(2025, time.May, 1), PayPeriodEnd: *date(2025, time.May, 31), BasicSalary: 1000.1, GrossAmount: 1000.3, TotalAmount: 1000.3, NetAmount: 1000.3, Currency: "USD", ProcessedAt: time.Now(), Status: model.PayslipStatusProcessed}
+	jpy := &model.Payslip{EmployeeID: employee.ID, PayPeriodStart: *date(2025, time.June, 1), PayPeriodEnd: *date(2025, time.June, 30), BasicSalary: 250000, GrossAmount: 250000, TotalAmount: 250000, NetAmount: 250000, Currency: "JPY", ProcessedAt: time.Now(), Status: model.PayslipStatusProcessed}
+	require.NoError(t, db.Create(usd).Error)
+	require.NoError(t, db.Create(jpy).Error)
+
+	require.NoError(t, database.BackfillMinorUnits(db, func(currency string) uint {
+		return helper.CurrencyPrecision(currency)
+	}))
+
+	require.NoError(t, db.First(usd, usd.ID).Error)
+	require.NotNil(t, usd.MinorUnitScale)
+	assert.Equal(t, 2, *usd.MinorUnitScale)
+	assert.Equal(t, int64(100010), usd.BasicSalaryMinor)
+	assert.Equal(t, int64(100030), usd.TotalAmountMinor)
+
+	require.NoError(t, db.First(jpy, jpy.ID).Error)
+	require.NotNil(t, jpy.MinorUnitScale)
+	assert.Equal(t, 0, *jpy.MinorUnitScale)
+	assert.Equal(t, int64(250000), jpy.TotalAmountMinor)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_ServerTimezoneBoundaryDays(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.Location = wib
+	employee := createTestEmployee(t, db, 1)
+
+	// Overtime on the day before the period and on its last day, attendance and a reimbursement on the last day
+	createTestOvertime(t, db, employee.ID, "2025-05-31")
+	createTestOvertime(t, db, employee.ID, "2025-06-30")
+	createTestAttendance(t, db, employee.ID, time.Date(2025, time.June, 30, 0, 0, 0, 0, wib), true)
+	require.NoError(t, db.Create(&model.Reimbursement{EmployeeID: employee.ID, ReimbursementDate: time.Date(2025, time.June, 30, 15, 0, 0, 0, wib), Amount: 100000, Category: model.ReimbursementTravel, Reason: "Client visit taxi", Status: model.ReimbursementApproved}).Error)
+
+	// June in WIB, as a database in UTC returns it: the boundaries fall on May 31 and June 29 in UTC
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, wib).UTC(),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, wib).UTC(),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, payslip.OvertimeHours, "only the overtime of June 30")
+	assert.Equal(t, 1.0, payslip.AttendanceDays)
+	assert.Equal(t, 100000.0, payslip.ReimbursementAmount)
+
+	chain, err := uc.BuildPayslipApprovalChain(payslip)
+	require.NoError(t, err)
+	require.Len(t, chain.Overtimes, 1)
+	assert.Equal(t, "2025-06-30", chain.Overtimes[0].Date)
+	require.Len(t, chain.Reimbursements, 1)
+	assert.Equal(t, "2025-06-30", chain.Reimbursements[0].Date)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_Warnings(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	june := request.PayrollRequest{
+		PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	}
+
+	// No attendance at all
+	absent := createTestEmployee(t, db, 1)
+	// One attendance day and 10 overtime hours
+	busy := createTestEmployee(t, db, 2)
+	createTestAttendance(t, db, busy.ID, time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC), true)
+	for _, day := range []string{"2025-06-02", "2025-06-03", "2025-06-04", "2025-06-05", "2025-06-06"} {
+		createTestOvertime(t, db, busy.ID, day)
+	}
+	// One attendance day and 2 overtime hours
+	regular := createTestEmployee(t, db, 3)
+	createTestAttendance(t, db, regular.ID, time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC), true)
+	createTestOvertime(t, db, regular.ID, "2025-06-02")
+	// One attendance day of a 4 hour standard day and 6 overtime hours
+	partTime := createTestEmployee(t, db, 4)
+	require.NoError(t, db.Model(partTime).Update("standard_hours", 4).Error)
+	createTestAttendance(t, db, partTime.ID, time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC), true)
+	for _, day := range []string{"2025-06-02", "2025-06-03", "2025-06-04"} {
+		createTestOvertime(t, db, partTime.ID, day)
+	}
+
+	payslip, err := uc.ProcessEmployeePayroll(absent.ID, june)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"no attendance recorded but a basic salary of 5000000.00 is paid"}, payslip.Warnings)
+	assert.NotZero(t, payslip.ID)
+
+	payslip, err = uc.ProcessEmployeePayroll(busy.ID, june)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10 overtime hours exceed the 8 hours of 1 attendance days"}, payslip.Warnings)
+
+	payslip, err = uc.ProcessEmployeePayroll(regular.ID, june)
+	require.NoError(t, err)
+	assert.Empty(t, payslip.Warnings)
+
+	payslip, err = uc.ProcessEmployeePayroll(partTime.ID, june)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"6 overtime hours exceed the 4 hours of 1 attendance days"}, payslip.Warnings)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_LatePenalty(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        config.LatePenaltyMode
+		amount      float64
+		wantPenalty float64
+	}{
+		{name: "off", mode: config.LatePenaltyOff, amount: 50000, wantPenalty: 0},
+		{name: "per incident", mode: config.LatePenaltyPerIncident, amount: 50000, wantPenalty: 100000},
+		{name: "per minute", mode: config.LatePenaltyPerMinute, amount: 1000, wantPenalty: 105000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			uc := setupTestUsecase(db)
+			uc.Config.Late.WorkStartMinutes = 9 * 60
+			uc.Config.LatePenaltyMode = tt.mode
+			uc.Config.LatePenaltyAmount = tt.amount
+			employee := createTestEmployee(t, db, 1)
+			createTestTaxBrackets(t, db)
+
+			// On time, 30 minutes late while still working 9 hours, and 75 minutes late
+			for _, checkin := range []struct {
+				day          int
+				hour, minute int
+			}{{2, 9, 0}, {3, 9, 30}, {4, 10, 15}} {
+				at := time.Date(2025, time.June, checkin.day, checkin.hour, checkin.minute, 0, 0, time.UTC)
+				checkout := at.Add(9 * time.Hour)
+				attendance := &model.Attendance{EmployeeID: employee.ID, Checkin: at, Checkout: &checkout, Status: model.AttendanceStatusPresent, Date: time.Date(2025, time.June, checkin.day, 0, 0, 0, 0, time.UTC)}
+				attendance.CalculateHours()
+				require.NoError(t, db.Create(attendance).Error)
+			}
+
+			payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+				PayPeriodStart: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+				PayPeriodEnd:   time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+				BasicSalary:    5000000,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, 2, payslip.LateCount)
+			assert.Equal(t, tt.wantPenalty, payslip.LatePenaltyAmount)
+			assert.Equal(t, payslip.GrossAmount-payslip.TaxAmount-tt.wantPenalty, payslip.NetAmount)
+
+			breakdown, err := uc.BuildDeductionBreakdown(payslip)
+			require.NoError(t, err)
+			assert.Equal(t, payslip.GrossAmount-payslip.NetAmount, breakdown.TotalDeducted)
+			// The taxable income shown is the one taxed, after the absence deduction and late penalty
+			taxable := helper.FormatMoney(payslip.BasicSalary+payslip.OvertimeAmount-payslip.AbsenceDeduction-payslip.LatePenaltyAmount, payslip.Currency)
+			require.Equal(t, res.DeductionIncomeTax, breakdown.Deductions[0].Type)
+			assert.Contains(t, breakdown.Deductions[0].Basis, "taxable income of "+taxable+" (basic salary and overtime, less the absence deduction and late penalty)")
+
+			summary := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil).Summary
+			assert.Equal(t, 2, summary.LateCount)
+			assert.Equal(t, tt.wantPenalty, summary.LatePenaltyAmount)
+		})
+	}
+}
+
+func TestPayrollUsecase_CountLateArrivals_GracePeriod(t *testing.T) {
+	uc := setupTestUsecase(setupTestDB(t))
+	uc.Config.Location = time.UTC
+	uc.Config.Late.WorkStartMinutes = 9 * 60
+
+	attend := func(hour, minute, second int) model.Attendance {
+		return model.Attendance{Checkin: time.Date(2025, time.June, 2, hour, minute, second, 0, time.UTC), Status: model.AttendanceStatusPresent}
+	}
+
+	tests := []struct {
+		name        string
+		grace       int
+		attendance  model.Attendance
+		wantCount   int
+		wantMinutes int
+	}{
+		{name: "no grace, at the start", grace: 0, attendance: attend(9, 0, 0), wantCount: 0, wantMinutes: 0},
+		{name: "no grace, one second over", grace: 0, attendance: attend(9, 0, 1), wantCount: 1, wantMinutes: 1},
+		{name: "at the grace limit", grace: 5, attendance: attend(9, 5, 0), wantCount: 0, wantMinutes: 0},
+		{name: "one second over the grace limit", grace: 5, attendance: attend(9, 5, 1), wantCount: 1, wantMinutes: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.Config.Late.GraceMinutes = tt.grace
+			count, minutes := uc.CountLateArrivals([]model.Attendance{tt.attendance})
+			assert.Equal(t, tt.wantCount, count)
+			assert.Equal(t, tt.wantMinutes, minutes)
+		})
+	}
+}
+
+func TestPayrollUsecase_CountLateArrivals_AgreesWithAttendanceStats(t *testing.T) {
+	t.Setenv("PAYROLL_WORK_START_TIME", "09:00")
+	t.Setenv("ATTENDANCE_LATE_GRACE_MINUTES", "5")
+	uc := setupTestUsecase(setupTestDB(t))
+	uc.Config.Location = time.UTC
+	uc.Config.Late = config.LoadLateCheckinPolicy()
+	anomaly := config.LoadAttendanceAnomalyConfig()
+
+	// At the start, at the end of the grace period, one second over it, and an hour late
+	var attendances []model.Attendance
+	for day, checkin := range [][3]int{{9, 0, 0}, {9, 5, 0}, {9, 5, 1}, {10, 0, 0}} {
+		date := time.Date(2025, time.June, 2+day, 0, 0, 0, 0, time.UTC)
+		attendances = append(attendances, model.Attendance{
+			Date:    date,
+			Checkin: date.Add(time.Duration(checkin[0])*time.Hour + time.Duration(checkin[1])*time.Minute + time.Duration(checkin[2])*time.Second),
+			Status:  model.AttendanceStatusPresent,
+		})
+	}
+
+	lateCount, _ := uc.CountLateArrivals(attendances)
+	stats := helper.SummarizeAttendance(attendances, time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, time.June, 5, 0, 0, 0, 0, time.UTC), nil, anomaly)
+	assert.Equal(t, 2, lateCount)
+	assert.Equal(t, lateCount, stats.LateDays)
+}
+
+func TestPayrollUsecase_RoundMoney(t *testing.T) {
+	zero, four := uint(0), uint(4)
+	tests := []struct {
+		name     string
+		rounding config.MoneyRounding
+		decimals *uint
+		amount   float64
+		currency string
+		want     float64
+	}{
+		{name: "half up to cents", rounding: config.MoneyRoundHalfUp, amount: 0.125, currency: "USD", want: 0.13},
+		{name: "half even to cents", rounding: config.MoneyRoundHalfEven, amount: 0.125, currency: "USD", want: 0.12},
+		{name: "half even rounds up to an even digit", rounding: config.MoneyRoundHalfEven, amount: 0.375, currency: "USD", want: 0.38},
+		{name: "unset rounding rounds half up", amount: 2.5, currency: "JPY", want: 3},
+		{name: "half even without decimals", rounding: config.MoneyRoundHalfEven, amount: 2.5, currency: "JPY", want: 2},
+		{name: "configured decimals over the currency", rounding: config.MoneyRoundHalfUp, decimals: &zero, amount: 1500.5, currency: "IDR", want: 1501},
+		{name: "more decimals than the currency", rounding: config.MoneyRoundHalfUp, decimals: &four, amount: 1.23456, currency: "USD", want: 1.2346},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &PayrollUsecase{Config: config.PayrollConfig{MoneyRounding: tt.rounding, MoneyDecimals: tt.decimals}}
+			assert.Equal(t, tt.want, uc.RoundMoney(tt.amount, tt.currency))
+		})
+	}
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_AttendanceStatusWeights(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.OpenCheckoutPolicy = config.OpenCheckoutHalfDay
+	employee := createTestEmployee(t, db, 1)
+
+	// Every record but the open one spans a full day from 09:00 to 17:00
+	statuses := []struct {
+		status     string
+		checkout   bool
+		wantWeight float64
+	}{
+		{status: model.AttendanceStatusPresent, checkout: true, wantWeight: 1},
+		{status: model.AttendanceStatusLate, checkout: true, wantWeight: 1},
+		{status: model.AttendanceStatusHalfDay, checkout: true, wantWeight: 0.5},
+		{status: model.AttendanceStatusAbsent, checkout: true, wantWeight: 0},
+		{status: model.AttendanceStatusHalfDay, checkout: false, wantWeight: 0.25},
+	}
+	for i, s := range statuses {
+		day := *date(2025, time.June, 2+i)
+		attendance := &model.Attendance{EmployeeID: employee.ID, Checkin: day.Add(9 * time.Hour), Status: s.status, Date: day}
+		if s.checkout {
+			checkout := day.Add(17 * time.Hour)
+			attendance.Checkout = &checkout
+			attendance.CalculateHours()
+		}
+		require.NoError(t, db.Create(attendance).Error)
+	}
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2.75, payslip.AttendanceDays)
+	assert.Equal(t, 1, payslip.OpenAttendanceDays)
+
+	attendances, err := repository.NewPayslipRepository(db).GetAttendanceForPeriod(employee.ID, payslip.PayPeriodStart, payslip.PayPeriodEnd)
+	require.NoError(t, err)
+	detail := uc.BuildDetailedPayslipResponse(payslip, employee, attendances, nil, nil)
+	breakdown := detail.AttendanceBreakdown
+	require.Len(t, breakdown, len(statuses))
+	for i, s := range statuses {
+		assert.Equal(t, s.status, breakdown[i].Status)
+		assert.Equal(t, s.wantWeight, breakdown[i].Weight, s.status)
+	}
+	assert.Equal(t, 8, breakdown[2].HoursWorked)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_EmployeeCurrency(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	require.NoError(t, db.Model(employee).Update("currency", "USD").Error)
+
+	req := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    3000.255,
+		OvertimeRate:   10,
+	}
+
+	// A mismatching requested currency is refused
+	req.Currency = "IDR"
+	_, err := uc.ProcessEmployeePayroll(employee.ID, req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match the employee currency USD")
+
+	// Without one the employee's currency is used instead of the base currency
+	req.Currency = ""
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, req)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", payslip.Currency)
+	assert.Equal(t, 3000.26, payslip.BasicSalary)
+
+	detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil)
+	assert.Equal(t, "USD", detail.Currency)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_PayGradeDefaultSalary(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	grade := &model.PayGrade{Name: "G2", MinSalary: 4000000, MaxSalary: 8000000, DefaultSalary: 6000000}
+	require.NoError(t, db.Create(grade).Error)
+	graded := createTestEmployee(t, db, 1)
+	require.NoError(t, db.Model(graded).Update("pay_grade_id", grade.ID).Error)
+	ungraded := createTestEmployee(t, db, 2)
+
+	req := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		OvertimeRate:   10,
+	}
+
+	payslip, err := uc.ProcessEmployeePayroll(graded.ID, req)
+	require.NoError(t, err)
+	assert.Equal(t, 6000000.0, payslip.BasicSalary)
+
+	payslip, err = uc.ProcessEmployeePayroll(ungraded.ID, req)
+	require.NoError(t, err)
+	assert.Zero(t, payslip.BasicSalary)
+
+	// An explicit basic salary still wins over the grade's default
+	req.PayPeriodStart = *date(2025, time.July, 1)
+	req.PayPeriodEnd = *date(2025, time.July, 31)
+	req.BasicSalary = 5000000
+	payslip, err = uc.ProcessEmployeePayroll(graded.ID, req)
+	require.NoError(t, err)
+	assert.Equal(t, 5000000.0, payslip.BasicSalary)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_AbsenceDeduction(t *testing.T) {
+	tests := []struct {
+		name             string
+		workingDays      int
+		absenceDeduction bool
+		wantDailyRate    float64
+		wantDeduction    float64
+	}{
+		{name: "disabled", workingDays: 20, wantDailyRate: 200000},
+		{name: "two absent days deducted", workingDays: 20, absenceDeduction: true, wantDailyRate: 200000, wantDeduction: 400000},
+		{name: "zero working days", workingDays: 0, absenceDeduction: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			uc := setupTestUsecase(db)
+			uc.Config.WorkingDaysPerPeriod = tt.workingDays
+			uc.Config.AbsenceDeduction = tt.absenceDeduction
+			employee := createTestEmployee(t, db, 1)
+
+			// 18 attended weekdays in June 2025
+			attended := 0
+			for day := *date(2025, time.June, 1); attended < 18; day = day.AddDate(0, 0, 1) {
+				if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+					createTestAttendance(t, db, employee.ID, day, true)
+					attended++
+				}
+			}
+
+			payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+				PayPeriodStart: *date(2025, time.June, 1),
+				PayPeriodEnd:   *date(2025, time.June, 30),
+				BasicSalary:    4000000,
+				OvertimeRate:   10,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDailyRate, payslip.DailyRate)
+			assert.Equal(t, tt.wantDeduction, payslip.AbsenceDeduction)
+			assert.Equal(t, 4000000-tt.wantDeduction, payslip.NetAmount)
+
+			summary := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil).Summary
+			assert.Equal(t, tt.wantDailyRate, summary.DailyRate)
+		})
+	}
+}
+
+func TestPayrollUsecase_BuildPayrollSummary_GroupsTotalsByCurrency(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	for id := uint(1); id <= 3; id++ {
+		createTestEmployee(t, db, id)
+	}
+
+	payslips := []model.Payslip{
+		{EmployeeID: 1, Currency: "IDR", BasicSalary: 5000000, TotalAmount: 4500000},
+		{EmployeeID: 2, Currency: "USD", BasicSalary: 3000, TotalAmount: 2700},
+		{EmployeeID: 3, Currency: "USD", BasicSalary: 1000, TotalAmount: 900},
+		{EmployeeID: 3, BasicSalary: 2000000, TotalAmount: 1800000}, // Stored before currencies, counts as the base currency
+	}
+
+	summary := uc.BuildPayrollSummary(payslips)
+
+	totals := summary["summary_totals"].(map[string]interface{})
+	require.Len(t, totals, 2)
+	idr := totals["IDR"].(map[string]interface{})
+	assert.Equal(t, 2, idr["total_employees"])
+	assert.Equal(t, 2, idr["total_payslips"])
+	assert.Equal(t, 6300000.0, idr["total_take_home_pay"])
+	usd := totals["USD"].(map[string]interface{})
+	assert.Equal(t, 2, usd["total_employees"])
+	assert.Equal(t, 3600.0, usd["total_take_home_pay"])
+	assert.Equal(t, 2000.0, usd["average_basic_salary"])
+
+	// Employee 3 is summarised once per currency
+	employees := summary["employee_summaries"].([]map[string]interface{})
+	require.Len(t, employees, 4)
+	assert.Equal(t, uint(3), employees[2]["employee_id"])
+	assert.Equal(t, "IDR", employees[2]["currency"])
+	assert.Equal(t, 1800000.0, employees[2]["total_take_home_pay"])
+	assert.Equal(t, "USD", employees[3]["currency"])
+	assert.Equal(t, 900.0, employees[3]["total_take_home_pay"])
+}
+
+func TestPayrollUsecase_BuildPayrollSummaryXLSX_Currencies(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	for id := uint(1); id <= 3; id++ {
+		createTestEmployee(t, db, id)
+	}
+
+	workbook, err := uc.BuildPayrollSummaryXLSX([]model.Payslip{
+		{EmployeeID: 1, Currency: "JPY", BasicSalary: 300000, TotalAmount: 280000},
+		{EmployeeID: 2, Currency: "IDR", BasicSalary: 5000000, TotalAmount: 4500000.5},
+		{EmployeeID: 3, BasicSalary: 2000000, TotalAmount: 1800000},
+	})
+	require.NoError(t, err)
+	defer workbook.Close()
+
+	summary, err := workbook.GetRows("Summary")
+	require.NoError(t, err)
+	require.Len(t, summary, 3)
+	assert.Equal(t, []string{"IDR", "2", "2", "6,300,000.50 IDR"}, summary[1][:4])
+	assert.Equal(t, []string{"JPY", "1", "1", "280,000 JPY"}, summary[2][:4])
+
+	details, err := workbook.GetRows("Payslips")
+	require.NoError(t, err)
+	require.Len(t, details, 4)
+	assert.Equal(t, "JPY", details[1][5])
+	assert.Equal(t, "IDR", details[3][5])
+	assert.Equal(t, "2,000,000.00 IDR", details[3][6])
+}
+
+func TestPayrollUsecase_ComparePayrollSummaries(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	for id := uint(1); id <= 3; id++ {
+		createTestEmployee(t, db, id)
+	}
+
+	previous := uc.BuildPayrollSummary([]model.Payslip{
+		{EmployeeID: 1, Currency: "IDR", TotalAmount: 4000000, OvertimeAmount: 200000, OvertimeHours: 4},
+		{EmployeeID: 2, Currency: "IDR", TotalAmount: 6000000},
+	})
+	current := uc.BuildPayrollSummary([]model.Payslip{
+		{EmployeeID: 1, Currency: "IDR", TotalAmount: 4500000, OvertimeAmount: 300000, OvertimeHours: 6},
+		{EmployeeID: 2, Currency: "IDR", TotalAmount: 6000000},
+		{EmployeeID: 3, Currency: "IDR", TotalAmount: 4500000},
+		{EmployeeID: 3, Currency: "USD", TotalAmount: 900},
+	})
+
+	comparisons := uc.ComparePayrollSummaries(previous, current)
+	require.Len(t, comparisons, 2)
+
+	idr := comparisons[0]
+	assert.Equal(t, "IDR", idr.Currency)
+	assert.Equal(t, 2.0, idr.Headcount.Previous)
+	assert.Equal(t, 3.0, idr.Headcount.Current)
+	require.NotNil(t, idr.Headcount.PercentChange)
+	assert.InDelta(t, 50.0, *idr.Headcount.PercentChange, 1e-9)
+	assert.Equal(t, 5000000.0, idr.TotalPayout.Delta)
+	require.NotNil(t, idr.TotalPayout.PercentChange)
+	assert.InDelta(t, 50.0, *idr.TotalPayout.PercentChange, 1e-9)
+	assert.Equal(t, 0.0, idr.AveragePayout.Delta)
+	assert.Equal(t, 100000.0, idr.OvertimeAmount.Delta)
+	assert.Equal(t, 2.0, idr.OvertimeHours.Delta)
+
+	// Nothing was paid in USD before, so there is no percentage to report
+	usd := comparisons[1]
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, 0.0, usd.TotalPayout.Previous)
+	assert.Equal(t, 900.0, usd.TotalPayout.Delta)
+	assert.Nil(t, usd.TotalPayout.PercentChange)
+	assert.Nil(t, usd.Headcount.PercentChange)
+}
+
+// approveTestTimesheet submits and approves a timesheet of the employee for the period
+func approveTestTimesheet(t testing.TB, db *gorm.DB, employeeID uint, start, end time.Time) {
+	timesheetRepo := repository.NewTimesheetRepository(db)
+	timesheet, err := timesheetRepo.SubmitTimesheetWithAudit(&model.Timesheet{
+		EmployeeID:  employeeID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}, middleware.NewAuditableDB(db, employeeID))
+	require.NoError(t, err)
+	_, err = timesheetRepo.ApproveTimesheetWithAudit(timesheet, 99, middleware.NewAuditableDB(db, 99))
+	require.NoError(t, err)
+}
+
+func TestPayrollUsecase_ReprocessEmployeeInRunWithAudit(t *testing.T) {
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	}
+
+	// Employee 2 fails the first run because its timesheet is not approved yet
+	setupRun := func(t *testing.T) (*gorm.DB, *PayrollUsecase, *middleware.AuditableDB, *model.PayrollRun) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.RequireApprovedTimesheet = true
+		auditDB := middleware.NewAuditableDB(db, 99)
+		createTestEmployee(t, db, 1)
+		createTestEmployee(t, db, 2)
+		approveTestTimesheet(t, db, 1, payrollReq.PayPeriodStart, payrollReq.PayPeriodEnd)
+
+		run, payslips, errors := uc.ProcessPayrollRunWithAudit(payrollReq, auditDB)
+		require.NotNil(t, run)
+		require.Len(t, payslips, 1)
+		require.Len(t, errors, 1)
+		assert.Equal(t, 1, run.ProcessedCount)
+		assert.Equal(t, 1, run.ErrorCount)
+		return db, uc, auditDB, run
+	}
+
+	countPayslips := func(t *testing.T, db *gorm.DB, employeeID uint) int64 {
+		var count int64
+		require.NoError(t, db.Model(&model.Payslip{}).Where("employee_id = ?", employeeID).Count(&count).Error)
+		return count
+	}
+
+	t.Run("failed employee succeeds on reprocess", func(t *testing.T) {
+		db, uc, auditDB, run := setupRun(t)
+		approveTestTimesheet(t, db, 2, payrollReq.PayPeriodStart, payrollReq.PayPeriodEnd)
+
+		stored, err := uc.payslipRepo.GetPayrollRunByID(run.ID)
+		require.NoError(t, err)
+		result, err := uc.ReprocessEmployeeInRunWithAudit(stored, 2, auditDB)
+		require.NoError(t, err)
+		assert.True(t, result.IsSucceeded())
+		assert.Equal(t, 2, result.Attempts)
+		assert.Empty(t, result.Error)
+		require.NotNil(t, result.PayslipID)
+
+		reloaded, err := uc.payslipRepo.GetPayrollRunByID(run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, reloaded.ProcessedCount)
+		assert.Equal(t, 0, reloaded.ErrorCount)
+		assert.True(t, reloaded.ResultForEmployee(2).IsSucceeded())
+		assert.Equal(t, *result.PayslipID, *reloaded.ResultForEmployee(2).PayslipID)
+		assert.Equal(t, int64(1), countPayslips(t, db, 2))
+	})
+
+	t.Run("succeeded employee is not reprocessed", func(t *testing.T) {
+		db, uc, auditDB, run := setupRun(t)
+
+		stored, err := uc.payslipRepo.GetPayrollRunByID(run.ID)
+		require.NoError(t, err)
+		result, err := uc.ReprocessEmployeeInRunWithAudit(stored, 1, auditDB)
+		assert.Nil(t, result)
+		assert.EqualError(t, err, fmt.Sprintf("employee 1 already succeeded in payroll run %d", run.ID))
+		assert.Equal(t, int64(1), countPayslips(t, db, 1))
+	})
+}
+
+func TestPayrollUsecase_ProcessPayrollRunWithAudit_Workers(t *testing.T) {
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	}
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			db := setupTestDB(t)
+			uc := setupTestUsecase(db)
+			uc.Config.RequireApprovedTimesheet = true
+			uc.Config.Workers = workers
+
+			// Created out of order, the even employees fail without an approved timesheet
+			for _, id := range []uint{7, 3, 10, 1, 8, 5, 2, 9, 4, 6} {
+				createTestEmployee(t, db, id)
+				if id%2 == 1 {
+					approveTestTimesheet(t, db, id, payrollReq.PayPeriodStart, payrollReq.PayPeriodEnd)
+				}
+			}
+
+			run, payslips, errors := uc.ProcessPayrollRunWithAudit(payrollReq, middleware.NewAuditableDB(db, 99))
+			require.NotNil(t, run)
+
+			var payslipEmployees []uint
+			for _, payslip := range payslips {
+				payslipEmployees = append(payslipEmployees, payslip.EmployeeID)
+			}
+			assert.Equal(t, []uint{1, 3, 5, 7, 9}, payslipEmployees)
+			var wantErrors []string
+			for _, id := range []uint{2, 4, 6, 8, 10} {
+				wantErrors = append(wantErrors, fmt.Sprintf("Employee %d: approved timesheet required for this period", id))
+			}
+			assert.Equal(t, wantErrors, errors)
+
+			assert.Equal(t, 5, run.ProcessedCount)
+			assert.Equal(t, 5, run.ErrorCount)
+			for i, result := range run.Results {
+				assert.Equal(t, uint(i+1), result.EmployeeID)
+			}
+			var audited int64
+			require.NoError(t, db.Model(&model.AuditLog{}).Where("table_name = ? AND actor_id = ?", "payslips", 99).Count(&audited).Error)
+			assert.Equal(t, int64(5), audited)
+		})
+	}
+}
+
+// slowPayslipRepository adds latency to every period record query, like a remote database would
+type slowPayslipRepository struct {
+	repository.PayslipRepository
+	latency time.Duration
+}
+
+func (r *slowPayslipRepository) GetAttendanceForPeriod(employeeID uint, startDate, endDate time.Time) ([]model.Attendance, error) {
+	time.Sleep(r.latency)
+	return r.PayslipRepository.GetAttendanceForPeriod(employeeID, startDate, endDate)
+}
+
+func (r *slowPayslipRepository) GetOvertimeForPeriod(employeeID uint, startDate, endDate string) ([]model.Overtime, error) {
+	time.Sleep(r.latency)
+	return r.PayslipRepository.GetOvertimeForPeriod(employeeID, startDate, endDate)
+}
+
+func (r *slowPayslipRepository) GetApprovedReimbursementsForPeriod(employeeID uint, startDate, endDate time.Time) ([]model.Reimbursement, error) {
+	time.Sleep(r.latency)
+	return r.PayslipRepository.GetApprovedReimbursementsForPeriod(employeeID, startDate, endDate)
+}
+
+// BenchmarkPayrollUsecase_ProcessAllEmployeesPayroll compares processing 100 employees one by one
+// and on a pool of 8 workers, with 1ms of latency per period record query
+func BenchmarkPayrollUsecase_ProcessAllEmployeesPayroll(b *testing.B) {
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	}
+
+	for _, workers := range []int{1, 8} {
+		name := "sequential"
+		if workers > 1 {
+			name = fmt.Sprintf("parallel_%d_workers", workers)
+		}
+		b.Run(name, func(b *testing.B) {
+			db := setupTestDB(b)
+			for id := uint(1); id <= 100; id++ {
+				createTestEmployee(b, db, id)
+			}
+			repo := &slowPayslipRepository{PayslipRepository: repository.NewPayslipRepository(db), latency: time.Millisecond}
+			uc := NewPayrollUsecase(repo, repository.NewEmployeeRepository(db))
+			uc.Config.Workers = workers
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Start from an empty period, payslips already processed are refused
+				b.StopTimer()
+				require.NoError(b, db.Where("1 = 1").Delete(&model.Payslip{}).Error)
+				b.StartTimer()
+
+				payslips, errors := uc.ProcessAllEmployeesPayroll(payrollReq)
+				if len(errors) > 0 || len(payslips) != 100 {
+					b.Fatalf("processed %d payslips with errors %v", len(payslips), errors)
+				}
+			}
+		})
+	}
+}
+
+// createTestDeduction creates an active recurring deduction for the employee
+func createTestDeduction(t testing.TB, db *gorm.DB, employeeID uint, amount float64) {
+	component := &model.EmployeeComponent{
+		EmployeeID: employeeID,
+		Name:       "loan repayment",
+		Kind:       model.ComponentDeduction,
+		Amount:     amount,
+		Active:     true,
+	}
+	require.NoError(t, db.Create(component).Error)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_NegativeNetPolicy(t *testing.T) {
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    1000000,
+		OvertimeRate:   50000,
+	}
+
+	t.Run("clamp pays zero and carries the rest forward", func(t *testing.T) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.NegativeNetPolicy = config.NegativeNetClamp
+		employee := createTestEmployee(t, db, 1)
+		createTestDeduction(t, db, employee.ID, 1500000)
+
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, payrollReq)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, payslip.TotalAmount)
+		assert.Equal(t, 500000.0, payslip.CarriedForward)
+
+		detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil)
+		summary := detail.Summary
+		assert.Equal(t, 500000.0, summary.CarriedForward)
+	})
+
+	t.Run("reject refuses the payslip", func(t *testing.T) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.NegativeNetPolicy = config.NegativeNetReject
+		employee := createTestEmployee(t, db, 1)
+		createTestDeduction(t, db, employee.ID, 1500000)
+
+		payslips, errors := uc.ProcessAllEmployeesPayroll(payrollReq)
+
+		assert.Empty(t, payslips)
+		assert.Equal(t, []string{"Employee 1: deductions exceed gross pay by 500000.00"}, errors)
+		var count int64
+		require.NoError(t, db.Model(&model.Payslip{}).Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("allow keeps the negative net pay", func(t *testing.T) {
+		db := setupTestDB(t)
+		uc := setupTestUsecase(db)
+		uc.Config.NegativeNetPolicy = config.NegativeNetAllow
+		employee := createTestEmployee(t, db, 1)
+		createTestDeduction(t, db, employee.ID, 1500000)
+
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, payrollReq)
+
+		require.NoError(t, err)
+		assert.Equal(t, -500000.0, payslip.TotalAmount)
+		assert.Equal(t, 0.0, payslip.CarriedForward)
+	})
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_CarryForwardDeduction(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.NegativeNetPolicy = config.NegativeNetClamp
+	employee := createTestEmployee(t, db, 1)
+	createTestDeduction(t, db, employee.ID, 1500000)
+
+	june, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    1000000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 500000.0, june.CarriedForward)
+
+	july, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.July, 1),
+		PayPeriodEnd:   *date(2025, time.July, 31),
+		BasicSalary:    3000000,
+	})
+	require.NoError(t, err)
+
+	// 3,000,000 - 1,500,000 recurring - 500,000 brought forward from June
+	assert.Equal(t, 500000.0, july.BroughtForward)
+	assert.Equal(t, 1000000.0, july.TotalAmount)
+	assert.Equal(t, 0.0, july.CarriedForward)
+}
+
+// createTestTaxBrackets creates 5% up to 5,000,000 and 15% above, untaxed below 1,000,000
+func createTestTaxBrackets(t testing.TB, db *gorm.DB) []model.TaxBracket {
+	brackets := []model.TaxBracket{
+		{Floor: 1000000, Ceiling: 5000000, Rate: 0.05},
+		{Floor: 5000000, Rate: 0.15},
+	}
+	require.NoError(t, db.Create(&brackets).Error)
+	return brackets
+}
+
+func TestPayrollUsecase_CalculateDeductions(t *testing.T) {
+	uc := NewPayrollUsecase(nil, nil)
+	brackets := []model.TaxBracket{
+		{Floor: 1000000, Ceiling: 5000000, Rate: 0.05},
+		{Floor: 5000000, Rate: 0.15},
+	}
+
+	tests := []struct {
+		name   string
+		income float64
+		want   float64
+	}{
+		{name: "below the lowest bracket", income: 800000, want: 0},
+		{name: "zero income", income: 0, want: 0},
+		{name: "inside the first bracket", income: 3000000, want: 100000},
+		{name: "across both brackets", income: 7000000, want: 200000 + 300000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, uc.CalculateDeductions(tt.income, brackets), 0.0001)
+		})
+	}
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_GrossToNet(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	createTestOvertime(t, db, employee.ID, "2025-06-06")
+	createTestDeduction(t, db, employee.ID, 100000)
+	createTestTaxBrackets(t, db)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    6000000,
+		OvertimeRate:   500000,
+	})
+	require.NoError(t, err)
+
+	// Taxable 7,000,000: 5% of 4,000,000 plus 15% of 2,000,000
+	assert.Equal(t, 7000000.0, payslip.GrossAmount)
+	assert.Equal(t, 500000.0, payslip.TaxAmount)
+	assert.Equal(t, 6400000.0, payslip.NetAmount)
+	assert.Equal(t, payslip.NetAmount, payslip.TotalAmount)
+
+	detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil)
+	breakdown := detail.DeductionBreakdown
+	require.Len(t, breakdown, 3)
+	assert.Equal(t, "income_tax", breakdown[0].Type)
+	assert.Equal(t, 500000.0, breakdown[0].Amount)
+	assert.Equal(t, 100000.0, breakdown[1].Amount)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_Proration(t *testing.T) {
+	// June 2025 has 21 working days
+	payrollReq := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    2100000,
+	}
+
+	tests := []struct {
+		name         string
+		hireDate     *time.Time
+		wantDays     int
+		wantFactor   float64
+		wantBasicPay float64
+	}{
+		{name: "hired before the period", hireDate: date(2025, time.May, 15), wantDays: 21, wantFactor: 1, wantBasicPay: 2100000},
+		{name: "joined mid-period", hireDate: date(2025, time.June, 16), wantDays: 11, wantFactor: 11.0 / 21, wantBasicPay: 1100000},
+		{name: "joined after the period", hireDate: date(2025, time.July, 1), wantDays: 0, wantFactor: 0, wantBasicPay: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			uc := setupTestUsecase(db)
+			employee := createTestEmployee(t, db, 1)
+			require.NoError(t, db.Model(employee).Update("hire_date", tt.hireDate).Error)
+			employee.HireDate = tt.hireDate
+
+			payslip, err := uc.ProcessEmployeePayroll(employee.ID, payrollReq)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantDays, payslip.ProratedDays)
+			assert.Equal(t, 21, payslip.PeriodWorkingDays)
+			assert.Equal(t, tt.wantBasicPay, payslip.BasicSalary)
+			assert.Equal(t, tt.wantBasicPay, payslip.TotalAmount)
+
+			detail := uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil)
+			summary := detail.Summary
+			assert.Equal(t, tt.wantDays, summary.ProratedDays)
+			assert.InDelta(t, tt.wantFactor, summary.ProrationFactor, 0.0001)
+		})
+	}
+}
+
+func TestPayrollUsecase_CalculateProration_CreatedAtFallback(t *testing.T) {
+	uc := NewPayrollUsecase(nil, nil)
+	createdAt := time.Date(2025, time.June, 16, 10, 30, 0, 0, time.UTC)
+	employee := &model.Employee{DefaultAttribute: model.DefaultAttribute{CreatedAt: &createdAt}}
+
+	days, periodDays, factor := uc.CalculateProration(employee, *date(2025, time.June, 1), *date(2025, time.June, 30))
+
+	assert.Equal(t, 11, days)
+	assert.Equal(t, 21, periodDays)
+	assert.InDelta(t, 11.0/21, factor, 0.0001)
+}
+
+// createTestProjectionItems creates approved and pending overtime and reimbursements in June 2025
+func createTestProjectionItems(t testing.TB, db *gorm.DB, employeeID uint) {
+	createTestOvertime(t, db, employeeID, "2025-06-10")
+	require.NoError(t, db.Create(&model.Overtime{EmployeeID: employeeID, OvertimeDate: "2025-06-11", Hours: 3, Reason: "Incident follow-up", Status: model.OvertimePending}).Error)
+	require.NoError(t, db.Create(&model.Reimbursement{EmployeeID: employeeID, ReimbursementDate: *date(2025, time.June, 12), Amount: 100000, Category: model.ReimbursementMeals, Reason: "Team lunch", Status: model.ReimbursementApproved}).Error)
+	require.NoError(t, db.Create(&model.Reimbursement{EmployeeID: employeeID, ReimbursementDate: *date(2025, time.June, 13), Amount: 250000, Category: model.ReimbursementTravel, Reason: "Client visit taxi", Status: model.ReimbursementPending}).Error)
+}
+
+func TestPayrollUsecase_GetProjectedPay_IncludePending(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	createTestProjectionItems(t, db, employee.ID)
+
+	projection, err := uc.GetProjectedPay(employee.ID, request.ProjectedPayRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+		IncludePending: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, res.ProjectedPayFigures{
+		PaidOvertimeHours:   2,
+		OvertimeAmount:      100000,
+		ReimbursementAmount: 100000,
+		GrossAmount:         5200000,
+		NetAmount:           5200000,
+	}, projection.ApprovedOnly)
+
+	require.NotNil(t, projection.IfApproved)
+	assert.Equal(t, res.ProjectedPayFigures{
+		PaidOvertimeHours:   5,
+		OvertimeAmount:      250000,
+		ReimbursementAmount: 350000,
+		GrossAmount:         5600000,
+		NetAmount:           5600000,
+	}, *projection.IfApproved)
+	assert.NotEqual(t, projection.ApprovedOnly.NetAmount, projection.IfApproved.NetAmount)
+
+	require.NotNil(t, projection.Pending)
+	assert.Equal(t, res.PendingProjectionItems{OvertimeCount: 1, OvertimeHours: 3, ReimbursementCount: 1, ReimbursementAmount: 250000}, *projection.Pending)
+	assert.NotEmpty(t, projection.Note)
+
+	// Projections never store a payslip
+	var count int64
+	require.NoError(t, db.Model(&model.Payslip{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestPayrollUsecase_GetProjectedPay_ApprovedOnlyByDefault(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	createTestProjectionItems(t, db, employee.ID)
+
+	projection, err := uc.GetProjectedPay(employee.ID, request.ProjectedPayRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5200000.0, projection.ApprovedOnly.NetAmount)
+	assert.False(t, projection.IncludePending)
+	assert.Nil(t, projection.IfApproved)
+	assert.Nil(t, projection.Pending)
+
+	// Actual payroll matches the approved-only figures
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+		OvertimeRate:   50000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, projection.ApprovedOnly.NetAmount, payslip.TotalAmount)
+}
+
+// detailedPayslipFixture is a payslip of employee 1 with every breakdown, deduction and an allowance filled in
+func detailedPayslipFixture(t *testing.T) (*PayrollUsecase, *model.Payslip, *model.Employee, []model.Attendance, []model.Overtime, []model.Reimbursement, []model.EmployeeComponent) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	day := *date(2025, time.June, 2)
+	checkout := day.Add(17 * time.Hour)
+	attendances := []model.Attendance{
+		{EmployeeID: 1, Date: day, Checkin: day.Add(9 * time.Hour), Checkout: &checkout, HoursWorked: 8, Status: "present"},
+		{EmployeeID: 1, Date: day.AddDate(0, 0, 1), Checkin: day.AddDate(0, 0, 1).Add(9*time.Hour + 20*time.Minute), Status: "late"},
+	}
+	overtimes := []model.Overtime{{EmployeeID: 1, OvertimeDate: "2025-06-02", Hours: 2, Reason: "Release preparation", Status: model.OvertimeApproved}}
+	approved := 80000.0
+	reimbursements := []model.Reimbursement{
+		{EmployeeID: 1, ReimbursementDate: day, Amount: 100000, ApprovedAmount: &approved, Reason: "Client dinner", Status: model.ReimbursementApproved},
+		{EmployeeID: 1, ReimbursementDate: day.AddDate(0, 0, 2), Amount: -30000, Reason: "Overpaid taxi fare", Status: model.ReimbursementApproved},
+	}
+	components := []model.EmployeeComponent{{EmployeeID: 1, Name: "Transport", Kind: model.ComponentAllowance, Amount: 250000, Active: true}}
+
+	payslip := &model.Payslip{
+		DefaultAttribute:    model.DefaultAttribute{ID: 7},
+		EmployeeID:          1,
+		PayPeriodStart:      *date(2025, time.June, 1),
+		PayPeriodEnd:        *date(2025, time.June, 30),
+		ProcessedAt:         time.Date(2025, time.July, 1, 8, 30, 0, 0, time.UTC),
+		Status:              model.PayslipStatusProcessed,
+		Currency:            "IDR",
+		BasicSalary:         5000000,
+		DailyRate:           238095.24,
+		ProratedDays:        21,
+		PeriodWorkingDays:   21,
+		AttendanceDays:      2,
+		OvertimeHours:       2,
+		PaidOvertimeHours:   2,
+		OvertimeRate:        50000,
+		OvertimeAmount:      100000,
+		ReimbursementAmount: 50000,
+		AllowanceAmount:     250000,
+		DeductionAmount:     100000,
+		AbsenceDeduction:    4523809.52,
+		LateCount:           1,
+		LatePenaltyAmount:   25000,
+		GrossAmount:         5400000,
+		TaxAmount:           270000,
+		NetAmount:           481190.48,
+		BroughtForward:      10000,
+		CarriedForward:      0,
+		TotalAmount:         471190.48,
+	}
+	return uc, payslip, employee, attendances, overtimes, reimbursements, components
+}
+
+// detailedPayslipJSON is the detailed payslip of detailedPayslipFixture as the map it was built as before
+// BuildDetailedPayslipResponse returned a struct
+const detailedPayslipJSON = `{
+  "allowances": [
+    {
+      "amount": 250000,
+      "name": "Transport"
+    }
+  ],
+  "attendance_breakdown": [
+    {
+      "check_in": "2025-06-02T09:00:00Z",
+      "check_out": "2025-06-02T17:00:00Z",
+      "date": "2025-06-02T00:00:00Z",
+      "hours_worked": 8,
+      "open": false,
+      "status": "present",
+      "weight": 1
+    },
+    {
+      "check_in": "2025-06-03T09:20:00Z",
+      "check_out": null,
+      "date": "2025-06-03T00:00:00Z",
+      "hours_worked": 0,
+      "open": true,
+      "status": "late",
+      "weight": 1
+    }
+  ],
+  "currency": "IDR",
+  "deduction_breakdown": [
+    {
+      "amount": 270000,
+      "type": "income_tax"
+    },
+    {
+      "amount": 100000,
+      "type": "components"
+    },
+    {
+      "amount": 10000,
+      "type": "brought_forward"
+    },
+    {
+      "amount": 4523809.52,
+      "type": "absence"
+    },
+    {
+      "amount": 25000,
+      "type": "late_penalty"
+    }
+  ],
+  "employee_id": 1,
+  "employee_name": "John Doe",
+  "overtime_breakdown": [
+    {
+      "amount": 100000,
+      "date": "2025-06-02",
+      "hours": 2,
+      "multiplier": 1,
+      "paid_hours": 2,
+      "rate": 50000,
+      "reason": "Release preparation",
+      "unpaid_hours": 0
+    }
+  ],
+  "pay_period_end": "2025-06-30T00:00:00Z",
+  "pay_period_start": "2025-06-01T00:00:00Z",
+  "payslip_id": 7,
+  "processed_at": "2025-07-01T08:30:00Z",
+  "reimbursement_breakdown": [
+    {
+      "amount": 80000,
+      "approved_amount": 80000,
+      "date": "2025-06-02T00:00:00Z",
+      "reason": "Client dinner",
+      "requested_amount": 100000,
+      "status": "approved",
+      "type": "reimbursement"
+    },
+    {
+      "amount": -30000,
+      "approved_amount": null,
+      "date": "2025-06-04T00:00:00Z",
+      "reason": "Overpaid taxi fare",
+      "requested_amount": -30000,
+      "status": "approved",
+      "type": "clawback"
+    }
+  ],
+  "status": "processed",
+  "summary": {
+    "absence_deduction": 4523809.52,
+    "allowance_amount": 250000,
+    "basic_salary": 5000000,
+    "brought_forward": 10000,
+    "carried_forward": 0,
+    "currency": "IDR",
+    "daily_rate": 238095.24,
+    "deduction_amount": 100000,
+    "display": {
+      "absence_deduction": "4523809.52",
+      "allowance_amount": "250000.00",
+      "basic_salary": "5000000.00",
+      "brought_forward": "10000.00",
+      "carried_forward": "0.00",
+      "daily_rate": "238095.24",
+      "deduction_amount": "100000.00",
+      "gross_amount": "5400000.00",
+      "late_penalty_amount": "25000.00",
+      "net_amount": "481190.48",
+      "overtime_amount": "100000.00",
+      "reimbursement_amount": "50000.00",
+      "tax_amount": "270000.00",
+      "total_take_home_pay": "471190.48"
+    },
+    "gross_amount": 5400000,
+    "late_count": 1,
+    "late_penalty_amount": 25000,
+    "net_amount": 481190.48,
+    "open_attendance_days": 0,
+    "overtime_amount": 100000,
+    "overtime_rate": 50000,
+    "prorated_days": 21,
+    "proration_factor": 1,
+    "reimbursement_amount": 50000,
+    "tax_amount": 270000,
+    "total_attendance_days": 2,
+    "total_overtime_hours": 2,
+    "total_take_home_pay": 471190.48
+  }
+}`
+
+func TestPayrollUsecase_BuildDetailedPayslipResponse_JSON(t *testing.T) {
+	uc, payslip, employee, attendances, overtimes, reimbursements, components := detailedPayslipFixture(t)
+
+	detail := uc.BuildDetailedPayslipResponse(payslip, employee, attendances, overtimes, reimbursements)
+	detail.Allowances = uc.BuildAllowanceBreakdown(payslip, components)
+	body, err := json.Marshal(detail)
+	require.NoError(t, err)
+	assert.JSONEq(t, detailedPayslipJSON, string(body))
+
+	// Without records the breakdowns stay null, and no allowance is an empty list
+	detail = uc.BuildDetailedPayslipResponse(payslip, employee, nil, nil, nil)
+	detail.Allowances = uc.BuildAllowanceBreakdown(&model.Payslip{}, nil)
+	body, err = json.Marshal(detail)
+	require.NoError(t, err)
+
+	var got, want map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &got))
+	require.NoError(t, json.Unmarshal([]byte(detailedPayslipJSON), &want))
+	for _, key := range []string{"attendance_breakdown", "overtime_breakdown", "reimbursement_breakdown"} {
+		want[key] = nil
+	}
+	want["allowances"] = []interface{}{}
+	assert.Equal(t, want, got)
+}
+
+// deductionTypes maps each deduction of a breakdown to its amount, keyed by type and name
+func deductionTypes(breakdown *res.DeductionBreakdown) map[string]float64 {
+	amounts := make(map[string]float64)
+	for _, item := range breakdown.Deductions {
+		amounts[item.Type+":"+item.Name] = item.Amount
+	}
+	return amounts
+}
+
+func TestPayrollUsecase_BuildDeductionBreakdown_SumsToGrossMinusNet(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	createTestTaxBrackets(t, db)
+	createTestDeduction(t, db, employee.ID, 300000)
+	require.NoError(t, db.Create(&model.EmployeeComponent{EmployeeID: employee.ID, Name: "health insurance", Kind: model.ComponentDeduction, Amount: 100000, Active: true}).Error)
+	require.NoError(t, db.Create(&model.EmployeeComponent{EmployeeID: employee.ID, Name: "transport", Kind: model.ComponentAllowance, Amount: 200000, Active: true}).Error)
+
+	// May left 50,000 unrecovered
+	createTestPayslip(t, db, employee.ID, time.May, 0, 0, model.PayslipStatusProcessed)
+	require.NoError(t, db.Model(&model.Payslip{}).Where("employee_id = ?", employee.ID).Update("carried_forward", 50000).Error)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    6000000,
+	})
+	require.NoError(t, err)
+
+	breakdown, err := uc.BuildDeductionBreakdown(payslip)
+	require.NoError(t, err)
+
+	// Tax: 5% of 4,000,000 plus 15% of 1,000,000
+	assert.Equal(t, map[string]float64{
+		"income_tax:":                          350000,
+		"recurring_deduction:loan repayment":   300000,
+		"recurring_deduction:health insurance": 100000,
+		"brought_forward:":                     50000,
+	}, deductionTypes(breakdown))
+	assert.Equal(t, 800000.0, breakdown.TotalDeducted)
+	assert.Equal(t, payslip.GrossAmount-payslip.NetAmount, breakdown.TotalDeducted)
+	for _, item := range breakdown.Deductions {
+		assert.NotEmpty(t, item.Basis)
+	}
+}
+
+func TestPayrollUsecase_BuildDeductionBreakdown_CarriedForward(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	createTestDeduction(t, db, employee.ID, 300000)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    100000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0.0, payslip.NetAmount)
+
+	breakdown, err := uc.BuildDeductionBreakdown(payslip)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]float64{
+		"recurring_deduction:loan repayment": 300000,
+		"carried_forward:":                   -200000,
+	}, deductionTypes(breakdown))
+	assert.Equal(t, payslip.GrossAmount-payslip.NetAmount, breakdown.TotalDeducted)
+}
+
+func TestPayrollUsecase_BuildDeductionBreakdown_NoDeductions(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+	})
+	require.NoError(t, err)
+
+	breakdown, err := uc.BuildDeductionBreakdown(payslip)
+	require.NoError(t, err)
+
+	assert.NotNil(t, breakdown.Deductions)
+	assert.Empty(t, breakdown.Deductions)
+	assert.Zero(t, breakdown.TotalDeducted)
+}
+
+func TestPayrollUsecase_BuildTaxSummary_MatchesPayslips(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	createTestTaxBrackets(t, db)
+
+	var payslips []*model.Payslip
+	for month, salary := range map[time.Month]float64{time.January: 4000000, time.March: 6000000, time.December: 8000000} {
+		payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+			PayPeriodStart: *date(2025, month, 1),
+			PayPeriodEnd:   date(2025, month, 1).AddDate(0, 1, -1),
+			BasicSalary:    salary,
+		})
+		require.NoError(t, err)
+		payslips = append(payslips, payslip)
+	}
+
+	// Neither a voided payslip nor another year counts
+	voided, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    9000000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(voided).Update("status", model.PayslipStatusVoid).Error)
+	_, err = uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2024, time.December, 1),
+		PayPeriodEnd:   *date(2024, time.December, 31),
+		BasicSalary:    9000000,
+	})
+	require.NoError(t, err)
+
+	summary, err := uc.BuildTaxSummary(employee, 2025)
+	require.NoError(t, err)
+
+	var gross, tax, net float64
+	for _, payslip := range payslips {
+		gross += payslip.GrossAmount
+		tax += payslip.TaxAmount
+		net += payslip.NetAmount
+
+		month := summary.Months[payslip.PayPeriodStart.Month()-1]
+		assert.Equal(t, 1, month.PayslipCount)
+		assert.Equal(t, payslip.TaxAmount, month.TaxWithheld)
+		assert.Equal(t, payslip.GrossAmount, month.GrossAmount)
+	}
+	assert.Equal(t, 3, summary.PayslipCount)
+	assert.Equal(t, gross, summary.TotalGross)
+	assert.Equal(t, tax, summary.TotalTaxWithheld)
+	assert.Equal(t, net, summary.TotalNet)
+
+	// 150,000 on 4M, 350,000 on 6M and 650,000 on 8M
+	assert.Equal(t, 1150000.0, summary.TotalTaxWithheld)
+	require.Len(t, summary.Months, 12)
+	assert.Equal(t, "2025-06", summary.Months[5].Month)
+	assert.Zero(t, summary.Months[5].PayslipCount)
+}
+
+func TestPayrollUsecase_BuildTaxSummary_NoPayslips(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	summary, err := uc.BuildTaxSummary(employee, 2025)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2025, summary.Year)
+	assert.Equal(t, "IDR", summary.Currency)
+	assert.Zero(t, summary.PayslipCount)
+	assert.Zero(t, summary.TotalGross)
+	assert.Zero(t, summary.TotalTaxWithheld)
+	assert.Zero(t, summary.TotalNet)
+	require.Len(t, summary.Months, 12)
+	for _, month := range summary.Months {
+		assert.Zero(t, month.PayslipCount)
+		assert.Zero(t, month.TaxWithheld)
+	}
+}
+
+func TestPayrollUsecase_BuildYTDEarnings_SumsYearPayslips(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	createPayslip := func(start time.Time, status string, gross, tax, overtime float64, overtimeHours int, reimbursement, days float64) {
+		require.NoError(t, db.Create(&model.Payslip{
+			EmployeeID:          employee.ID,
+			PayPeriodStart:      start,
+			PayPeriodEnd:        start.AddDate(0, 1, -1),
+			BasicSalary:         gross - overtime - reimbursement,
+			OvertimeAmount:      overtime,
+			OvertimeHours:       overtimeHours,
+			ReimbursementAmount: reimbursement,
+			GrossAmount:         gross,
+			TaxAmount:           tax,
+			NetAmount:           gross - tax,
+			TotalAmount:         gross - tax,
+			AttendanceDays:      days,
+			Currency:            "IDR",
+			ProcessedAt:         time.Now(),
+			Status:              status,
+		}).Error)
+	}
+	createPayslip(*date(2025, time.January, 1), model.PayslipStatusPaid, 5500000, 250000, 300000, 6, 200000, 21)
+	createPayslip(*date(2025, time.February, 1), model.PayslipStatusProcessed, 6000000, 300000, 0, 0, 0, 19.5)
+	// Neither a voided payslip nor another year counts
+	createPayslip(*date(2025, time.March, 1), model.PayslipStatusVoid, 9000000, 900000, 0, 0, 0, 22)
+	createPayslip(*date(2024, time.December, 1), model.PayslipStatusPaid, 9000000, 900000, 0, 0, 0, 22)
+
+	earnings, err := uc.BuildYTDEarnings(employee, 2025)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2025, earnings.Year)
+	assert.Equal(t, "IDR", earnings.Currency)
+	assert.Equal(t, 2, earnings.PayslipCount)
+	assert.Equal(t, 11500000.0, earnings.GrossAmount)
+	assert.Equal(t, 550000.0, earnings.TaxAmount)
+	assert.Equal(t, 10950000.0, earnings.NetAmount)
+	assert.Equal(t, 11000000.0, earnings.BasicSalary)
+	assert.Equal(t, 300000.0, earnings.OvertimeAmount)
+	assert.Equal(t, 6, earnings.OvertimeHours)
+	assert.Equal(t, 200000.0, earnings.ReimbursementAmount)
+	assert.Equal(t, 40.5, earnings.AttendanceDays)
+}
+
+func TestPayrollUsecase_BuildYTDEarnings_NoPayslips(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	earnings, err := uc.BuildYTDEarnings(employee, 2025)
+	require.NoError(t, err)
+
+	assert.Equal(t, employee.ID, earnings.EmployeeID)
+	assert.Equal(t, "IDR", earnings.Currency)
+	assert.Zero(t, earnings.PayslipCount)
+	assert.Zero(t, earnings.GrossAmount)
+	assert.Zero(t, earnings.TaxAmount)
+	assert.Zero(t, earnings.NetAmount)
+	assert.Zero(t, earnings.OvertimeAmount)
+	assert.Zero(t, earnings.ReimbursementAmount)
+	assert.Zero(t, earnings.AttendanceDays)
+}
+
+func TestPayrollUsecase_BuildEmployeePayslipSummary_Range(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	for _, payslip := range []struct {
+		start  *time.Time
+		status string
+		net    float64
+	}{
+		{date(2025, time.March, 1), model.PayslipStatusPaid, 4000000},
+		{date(2025, time.April, 1), model.PayslipStatusPaid, 5000000},
+		{date(2025, time.May, 1), model.PayslipStatusVoid, 9000000},
+		{date(2025, time.June, 1), model.PayslipStatusProcessed, 5500000},
+		{date(2025, time.July, 1), model.PayslipStatusProcessed, 6000000},
+	} {
+		require.NoError(t, db.Create(&model.Payslip{
+			EmployeeID:     employee.ID,
+			PayPeriodStart: *payslip.start,
+			PayPeriodEnd:   payslip.start.AddDate(0, 1, -1),
+			BasicSalary:    payslip.net,
+			GrossAmount:    payslip.net,
+			NetAmount:      payslip.net,
+			TotalAmount:    payslip.net,
+			AttendanceDays: 20,
+			Currency:       "IDR",
+			ProcessedAt:    time.Now(),
+			Status:         payslip.status,
+		}).Error)
+	}
+
+	summary, err := uc.BuildEmployeePayslipSummary(employee, *date(2025, time.April, 1), *date(2025, time.June, 1))
+	require.NoError(t, err)
+	assert.Equal(t, "2025-04-01", summary.StartDate)
+	assert.Equal(t, "2025-06-01", summary.EndDate)
+	assert.Equal(t, 2, summary.PayslipCount)
+	assert.Equal(t, 10500000.0, summary.NetAmount)
+	assert.Equal(t, 10500000.0, summary.BasicSalary)
+	assert.Equal(t, 40.0, summary.AttendanceDays)
+
+	summary, err = uc.BuildEmployeePayslipSummary(employee, *date(2024, time.January, 1), *date(2024, time.December, 31))
+	require.NoError(t, err)
+	assert.Equal(t, employee.ID, summary.EmployeeID)
+	assert.Equal(t, "IDR", summary.Currency)
+	assert.Zero(t, summary.PayslipCount)
+	assert.Zero(t, summary.NetAmount)
+}
+
+func TestPayrollUsecase_BuildEmployeePayslipSummary_MixedCurrencies(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+
+	// The employee moved from IDR to USD in June
+	for _, payslip := range []struct {
+		start    *time.Time
+		net      float64
+		currency string
+	}{
+		{date(2025, time.May, 1), 5000000, "IDR"},
+		{date(2025, time.June, 1), 350, "USD"},
+	} {
+		require.NoError(t, db.Create(&model.Payslip{
+			EmployeeID:     employee.ID,
+			PayPeriodStart: *payslip.start,
+			PayPeriodEnd:   payslip.start.AddDate(0, 1, -1),
+			NetAmount:      payslip.net,
+			TotalAmount:    payslip.net,
+			Currency:       payslip.currency,
+			ProcessedAt:    time.Now(),
+			Status:         model.PayslipStatusProcessed,
+		}).Error)
+	}
+
+	_, err := uc.BuildEmployeePayslipSummary(employee, *date(2025, time.May, 1), *date(2025, time.June, 30))
+	assert.ErrorIs(t, err, ErrMixedCurrencies)
+	assert.ErrorContains(t, err, "IDR, USD")
+	_, err = uc.BuildYTDEarnings(employee, 2025)
+	assert.ErrorIs(t, err, ErrMixedCurrencies)
+	_, err = uc.BuildTaxSummary(employee, 2025)
+	assert.ErrorIs(t, err, ErrMixedCurrencies)
+
+	// A range within one currency is summed in it
+	summary, err := uc.BuildEmployeePayslipSummary(employee, *date(2025, time.June, 1), *date(2025, time.June, 30))
+	require.NoError(t, err)
+	assert.Equal(t, "USD", summary.Currency)
+	assert.Equal(t, 350.0, summary.NetAmount)
+}
+
+// createTestClawbackPeriod stores a June 2025 reimbursement of 200,000 and a clawback of 300,000 for the employee
+func createTestClawbackPeriod(t *testing.T, db *gorm.DB, employeeID uint) {
+	claim := &model.Reimbursement{EmployeeID: employeeID, ReimbursementDate: *date(2025, time.June, 12), Amount: 200000, Category: model.ReimbursementTravel, Reason: "Client visit taxi"}
+	claim.Approve(9)
+	clawback := &model.Reimbursement{EmployeeID: employeeID, ReimbursementDate: *date(2025, time.June, 20), Amount: -300000, Category: model.ReimbursementOther, Reason: "May hotel paid twice"}
+	clawback.Approve(9)
+	for _, reimbursement := range []*model.Reimbursement{claim, clawback} {
+		require.NoError(t, db.Create(reimbursement).Error)
+	}
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_ClawbackReducesPay(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	uc.Config.ReimbursementFloor = -500000
+	employee := createTestEmployee(t, db, 1)
+	createTestClawbackPeriod(t, db, employee.ID)
+
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, -100000.0, payslip.ReimbursementAmount)
+	assert.Equal(t, 4900000.0, payslip.GrossAmount)
+	assert.Equal(t, 4900000.0, payslip.NetAmount, "no tax brackets, so the net pay is the gross pay")
+
+	var reimbursements []model.Reimbursement
+	require.NoError(t, db.Order("id").Find(&reimbursements).Error)
+	breakdown := uc.buildReimbursementBreakdown(reimbursements)
+	require.Len(t, breakdown, 2)
+	assert.Equal(t, "reimbursement", breakdown[0].Type)
+	assert.Equal(t, "clawback", breakdown[1].Type)
+	assert.Equal(t, -300000.0, breakdown[1].Amount)
+}
+
+func TestPayrollUsecase_ProcessEmployeePayroll_ReimbursementFloor(t *testing.T) {
+	db := setupTestDB(t)
+	uc := setupTestUsecase(db)
+	employee := createTestEmployee(t, db, 1)
+	createTestClawbackPeriod(t, db, employee.ID)
+	req := request.PayrollRequest{
+		PayPeriodStart: *date(2025, time.June, 1),
+		PayPeriodEnd:   *date(2025, time.June, 30),
+		BasicSalary:    5000000,
+	}
+
+	// The default floor of 0 only lets clawbacks offset the period's reimbursements
+	_, err := uc.ProcessEmployeePayroll(employee.ID, req)
+	require.ErrorIs(t, err, ErrReimbursementBelowFloor)
+
+	uc.Config.ReimbursementFloor = -100000
+	payslip, err := uc.ProcessEmployeePayroll(employee.ID, req)
+	require.NoError(t, err)
+	assert.Equal(t, -100000.0, payslip.ReimbursementAmount)
+}
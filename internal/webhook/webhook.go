@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yourname/payslip-system/internal/config"
+)
+
+// Event types sent to webhook endpoints
+const (
+	EventPayrollCompleted = "payroll.completed"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body as sha256=<hex>
+const SignatureHeader = "X-Webhook-Signature"
+
+// Endpoint is a URL events are posted to, signed with its secret
+type Endpoint struct {
+	URL    string
+	Secret string
+}
+
+// EndpointSource lists the endpoints subscribed to an event. The environment provides a single endpoint,
+// a store of per-tenant registrations can take its place without changing the notifier.
+type EndpointSource interface {
+	Endpoints(event string) ([]Endpoint, error)
+}
+
+// envEndpoints is the endpoint of the environment, subscribed to every event
+type envEndpoints struct {
+	cfg config.WebhookConfig
+}
+
+// NewEnvEndpoints returns the configured endpoint, none while no URL is set
+func NewEnvEndpoints(cfg config.WebhookConfig) EndpointSource {
+	return envEndpoints{cfg: cfg}
+}
+
+// Endpoints returns the configured endpoint for every event
+func (e envEndpoints) Endpoints(string) ([]Endpoint, error) {
+	if !e.cfg.Enabled() {
+		return nil, nil
+	}
+	return []Endpoint{{URL: e.cfg.URL, Secret: e.cfg.Secret}}, nil
+}
+
+// Payload is the JSON body of a webhook request
+type Payload struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Notifier posts events to the subscribed endpoints
+type Notifier interface {
+	Notify(event string, data interface{}) error
+}
+
+type httpNotifier struct {
+	endpoints   EndpointSource
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	// sleep waits between attempts, time.Sleep outside of tests
+	sleep func(time.Duration)
+}
+
+// NewNotifier creates a notifier posting to the endpoints with the retry policy of the config
+func NewNotifier(cfg config.WebhookConfig, endpoints EndpointSource) Notifier {
+	return &httpNotifier{
+		endpoints:   endpoints,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		maxAttempts: max(cfg.MaxAttempts, 1),
+		backoff:     cfg.Backoff,
+		sleep:       time.Sleep,
+	}
+}
+
+// Notify posts the event to every subscribed endpoint, retrying failed deliveries with a doubling backoff.
+// Every endpoint is tried, the first delivery error is returned.
+func (n *httpNotifier) Notify(event string, data interface{}) error {
+	endpoints, err := n.endpoints.Endpoints(event)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %v", err)
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(Payload{Event: event, OccurredAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, endpoint := range endpoints {
+		if err := n.deliver(endpoint, event, body); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// deliver posts the body until the endpoint accepts it or the attempts run out.
+// Network errors, 429 and 5xx answers are retried, any other answer is final.
+func (n *httpNotifier) deliver(endpoint Endpoint, event string, body []byte) error {
+	wait := n.backoff
+	var err error
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		if attempt > 1 {
+			n.sleep(wait)
+			wait *= 2
+		}
+
+		var retry bool
+		retry, err = n.post(endpoint, event, body)
+		if err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to deliver %s webhook to %s: %v", event, endpoint.URL, err)
+	}
+	return nil
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying
+func (n *httpNotifier) post(endpoint Endpoint, event string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event)
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// Drained so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("endpoint answered %s", resp.Status)
+}
+
+// Sign returns the signature of a body as sent in SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a SignatureHeader value against the body in constant time, for receivers of the webhook
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
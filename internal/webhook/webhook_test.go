@@ -0,0 +1,100 @@
+// Package webhook tests cover:
+// 1. The payload posted with a signature the receiver verifies, a wrong secret or altered body failing
+// 2. 5xx answers retried with a doubling backoff until the endpoint accepts the payload
+// 3. 4xx answers given up on at once, retries stopping at the configured attempts
+// 4. Nothing sent while no webhook URL is configured
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/payslip-system/internal/config"
+)
+
+// testNotifier posts to the URL without sleeping, recording the waits between attempts
+func testNotifier(url string, maxAttempts int) (*httpNotifier, *[]time.Duration) {
+	cfg := config.WebhookConfig{URL: url, Secret: "s3cret", MaxAttempts: maxAttempts, Backoff: 100 * time.Millisecond, Timeout: time.Second}
+	notifier := NewNotifier(cfg, NewEnvEndpoints(cfg)).(*httpNotifier)
+	waits := &[]time.Duration{}
+	notifier.sleep = func(d time.Duration) { *waits = append(*waits, d) }
+	return notifier, waits
+}
+
+func TestNotify_SignedPayload(t *testing.T) {
+	var body []byte
+	var signature, event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		event = r.Header.Get("X-Webhook-Event")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, _ := testNotifier(server.URL, 3)
+	require.NoError(t, notifier.Notify(EventPayrollCompleted, map[string]int{"processed_count": 2}))
+
+	assert.Equal(t, EventPayrollCompleted, event)
+	assert.True(t, Verify("s3cret", body, signature))
+	assert.False(t, Verify("other", body, signature))
+	assert.False(t, Verify("s3cret", append(body, ' '), signature))
+
+	var payload struct {
+		Event string         `json:"event"`
+		Data  map[string]int `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, EventPayrollCompleted, payload.Event)
+	assert.Equal(t, 2, payload.Data["processed_count"])
+}
+
+func TestNotify_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier, waits := testNotifier(server.URL, 3)
+	require.NoError(t, notifier.Notify(EventPayrollCompleted, nil))
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits)
+}
+
+func TestNotify_GivesUp(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusBadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier, _ := testNotifier(server.URL, 4)
+	err := notifier.Notify(EventPayrollCompleted, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request")
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	status = http.StatusInternalServerError
+	require.Error(t, notifier.Notify(EventPayrollCompleted, nil))
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestNotify_NotConfigured(t *testing.T) {
+	notifier, _ := testNotifier("", 3)
+	assert.NoError(t, notifier.Notify(EventPayrollCompleted, nil))
+}